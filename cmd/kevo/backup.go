@@ -0,0 +1,212 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/KevoDB/kevo/pkg/backup"
+	"github.com/KevoDB/kevo/pkg/engine"
+)
+
+const backupUsage = `backup <subcommand> [arguments]
+
+Subcommands:
+  create DB_PATH BACKUP_DIR               - Back up a database that isn't open elsewhere
+  list BACKUP_DIR                         - List the backups
+  verify BACKUP_DIR [ID]                  - Check that backups can be restored
+  purge -keep N BACKUP_DIR                - Delete all but the newest N backups
+  restore [-until-seq N] BACKUP_DIR PATH  - Restore a database to PATH, up to
+                                            sequence number N or everything archived
+`
+
+// runBackup runs the backup command
+func runBackup(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: kevo %s", backupUsage)
+		return flag.ErrHelp
+	}
+
+	switch args[0] {
+	case "create":
+		return backupCreate(args[1:])
+	case "list":
+		return backupList(args[1:])
+	case "verify":
+		return backupVerify(args[1:])
+	case "purge":
+		return backupPurge(args[1:])
+	case "restore":
+		return backupRestore(args[1:])
+	case "-h", "-help", "--help", "help":
+		fmt.Fprintf(os.Stderr, "Usage: kevo %s", backupUsage)
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("unknown backup subcommand %q", args[0])
+	}
+}
+
+func backupCreate(args []string) error {
+	fs := newFlagSet("backup create", "backup create DB_PATH BACKUP_DIR")
+	if err := parseArgs(fs, args, 2); err != nil {
+		return err
+	}
+
+	eng, err := engine.NewEngineFacade(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer eng.Close()
+
+	backups, err := backup.Open(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	info, err := backups.Create(eng)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created backup %d at sequence %d (%d SSTables, %d bytes)\n",
+		info.ID, info.Sequence, countFiles(info), info.Size())
+	return nil
+}
+
+func backupList(args []string) error {
+	fs := newFlagSet("backup list", "backup list BACKUP_DIR")
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	backups, err := openBackupDir(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	list, err := backups.List()
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		fmt.Println("No backups")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCREATED\tSEQUENCE\tFAMILIES\tSSTABLES\tSIZE")
+	for _, info := range list {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%d\t%d\n", info.ID, info.CreatedAt.Format(time.RFC3339),
+			info.Sequence, len(info.Families), countFiles(info), info.Size())
+	}
+	return w.Flush()
+}
+
+func backupVerify(args []string) error {
+	fs := newFlagSet("backup verify", "backup verify BACKUP_DIR [ID]")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 && fs.NArg() != 2 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	backups, err := openBackupDir(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var ids []uint64
+	if fs.NArg() == 2 {
+		id, err := strconv.ParseUint(fs.Arg(1), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid backup ID %q", fs.Arg(1))
+		}
+		ids = append(ids, id)
+	} else {
+		list, err := backups.List()
+		if err != nil {
+			return err
+		}
+		for _, info := range list {
+			ids = append(ids, info.ID)
+		}
+	}
+
+	failed := 0
+	for _, id := range ids {
+		if err := backups.Verify(id); err != nil {
+			fmt.Printf("Backup %d: FAILED\n  %s\n", id, err)
+			failed++
+			continue
+		}
+		fmt.Printf("Backup %d: OK\n", id)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d backups failed verification", failed, len(ids))
+	}
+	return nil
+}
+
+func backupPurge(args []string) error {
+	fs := newFlagSet("backup purge", "backup purge -keep N BACKUP_DIR")
+	keep := fs.Int("keep", 0, "Number of newest backups to keep")
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	backups, err := openBackupDir(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	purged, err := backups.Purge(*keep)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Purged %d backups\n", purged)
+	return nil
+}
+
+func backupRestore(args []string) error {
+	fs := newFlagSet("backup restore", "backup restore [-until-seq N] BACKUP_DIR PATH")
+	untilSeq := fs.Uint64("until-seq", 0, "Last sequence number to restore, 0 for everything archived")
+	if err := parseArgs(fs, args, 2); err != nil {
+		return err
+	}
+
+	backups, err := openBackupDir(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	seqNum, err := backups.Restore(fs.Arg(1), *untilSeq)
+	if errors.Is(err, backup.ErrTargetExists) {
+		return fmt.Errorf("%s already exists, restore into a new directory", fs.Arg(1))
+	} else if err != nil {
+		return err
+	}
+
+	fmt.Printf("Restored %s up to sequence %d\n", fs.Arg(1), seqNum)
+	return nil
+}
+
+// openBackupDir opens an existing backup directory
+func openBackupDir(dir string) (*backup.Engine, error) {
+	if _, err := os.Stat(filepath.Join(dir, "meta")); err != nil {
+		return nil, fmt.Errorf("%s is not a backup directory", dir)
+	}
+	return backup.Open(dir)
+}
+
+// countFiles returns the number of SSTables in a backup
+func countFiles(info *backup.Info) int {
+	n := 0
+	for _, family := range info.Families {
+		n += len(family.Files)
+	}
+	return n
+}
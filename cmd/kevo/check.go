@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/KevoDB/kevo/pkg/engine"
+	"github.com/KevoDB/kevo/pkg/repair"
+	"github.com/KevoDB/kevo/pkg/wal"
+)
+
+const checkUsage = `check [-json] DB_PATH
+
+Opens every SSTable and WAL file of a database that isn't open elsewhere and
+checks their footers, block checksums and key order, that the levels above L0
+don't overlap, and that the WAL carries on from the sequence numbers in the
+SSTables. Run 'kevo repair' to set aside the files that can't be read.`
+
+const repairUsage = `repair DB_PATH
+
+Moves the SSTable and WAL files of a database that can't be read to its ` + repair.LostDirName + `/
+directory, cuts WAL files back to their last readable entry, rebuilds the file
+set from the files left and checks that the database opens.`
+
+type checkResult struct {
+	SSTables int      `json:"sstables"`
+	WALFiles int      `json:"wal_files"`
+	Problems []string `json:"problems"`
+}
+
+func runCheck(args []string) error {
+	fs := newFlagSet("check", checkUsage)
+	asJSON := fs.Bool("json", false, "Print JSON")
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	report, err := repair.Check(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		result := checkResult{SSTables: report.SSTables, WALFiles: report.WALFiles, Problems: []string{}}
+		for _, problem := range report.Problems {
+			result.Problems = append(result.Problems, problem.Error())
+		}
+		if err := printJSON(result); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("Checked %d SSTables and %d WAL files\n", report.SSTables, report.WALFiles)
+		for _, problem := range report.Problems {
+			fmt.Printf("  %s\n", problem)
+		}
+	}
+	if len(report.Problems) > 0 {
+		return fmt.Errorf("%d problems found", len(report.Problems))
+	}
+	return nil
+}
+
+func runRepair(args []string) error {
+	fs := newFlagSet("repair", repairUsage)
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+	dir := fs.Arg(0)
+
+	repaired, err := repair.Repair(dir)
+	if err != nil {
+		return err
+	}
+	for _, path := range repaired.Quarantined {
+		fmt.Printf("Set aside %s\n", path)
+	}
+	for _, path := range repaired.Truncated {
+		fmt.Printf("Truncated %s\n", path)
+	}
+	for _, path := range repaired.Rebuilt {
+		fmt.Printf("Rebuilt the file set of %s\n", path)
+	}
+	if len(repaired.Quarantined)+len(repaired.Truncated)+len(repaired.Rebuilt) == 0 {
+		fmt.Println("Nothing to repair")
+	}
+
+	wal.DisableRecoveryLogs = true
+	eng, err := engine.NewEngineFacade(dir)
+	if err != nil {
+		return fmt.Errorf("repaired database doesn't open: %w", err)
+	}
+	if err := eng.Close(); err != nil {
+		return err
+	}
+
+	// Overlapping levels and lost WAL entries are reported but left as they are
+	report, err := repair.Check(dir)
+	if err != nil {
+		return err
+	}
+	if len(report.Problems) > 0 {
+		for _, problem := range report.Problems {
+			fmt.Printf("  %s\n", problem)
+		}
+		return fmt.Errorf("database opens, but %d problems remain", len(report.Problems))
+	}
+	fmt.Printf("Database opens cleanly\n")
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// command is a tool run as `kevo <name> [arguments]` instead of the
+// interactive shell or the server
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+// commands are the tools kevo runs, by name
+var commands = map[string]command{
+	"backup": {"Create, list, verify, purge and restore backups", runBackup},
+	"check":  {"Check the files of a database for corruption", runCheck},
+	"export": {"Write keys and values as JSON lines or CSV", runExport},
+	"import": {"Load keys and values written by export", runImport},
+	"repair": {"Set aside unreadable files and rebuild the file set", runRepair},
+	"sst":    {"Inspect and verify SSTable files", runSST},
+	"wal":    {"Inspect WAL files and replay them into a new database", runWAL},
+}
+
+// runCommand runs the command named by the first argument and exits, or
+// returns if the arguments don't start with a command name
+func runCommand(args []string) {
+	if len(args) == 0 {
+		return
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return
+	}
+
+	if err := cmd.run(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// printCommands lists the commands for the usage message
+func printCommands(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(w, "\nCommands:\n")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-23s - %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintf(w, "Run 'kevo <command> -h' for the usage of a command\n")
+}
+
+// newFlagSet creates the flag set of a command, which prints its usage and
+// options when parsing fails
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: kevo %s\n", usage)
+		hasFlags := false
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintf(fs.Output(), "\nOptions:\n")
+			fs.PrintDefaults()
+		}
+	}
+	return fs
+}
+
+// parseArgs parses the flags of a command and checks it got n arguments
+func parseArgs(fs *flag.FlagSet, args []string, n int) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != n {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	return nil
+}
@@ -0,0 +1,424 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"github.com/KevoDB/kevo/pkg/client"
+	"github.com/KevoDB/kevo/pkg/engine"
+)
+
+const exportUsage = `export [options] DB_PATH
+       kevo export -server ADDRESS [options]
+
+Writes the live keys and values of a database that isn't open elsewhere, or of
+a running server, as JSON lines ({"key": ..., "value": ...}) or as CSV with a
+key,value header. Keys and values are written with the chosen encoding, base64
+by default, so binary data survives the round trip through 'kevo import'.`
+
+// Encodings of keys and values in exported records
+const (
+	encodingBase64 = "base64"
+	encodingHex    = "hex"
+	encodingText   = "text"
+)
+
+// Formats of exported records
+const (
+	formatJSONL = "jsonl"
+	formatCSV   = "csv"
+)
+
+func runExport(args []string) error {
+	fs := newFlagSet("export", exportUsage)
+	server := fs.String("server", "", "Address of a running server to export from instead of DB_PATH")
+	prefix := fs.String("prefix", "", "Only export keys with this prefix")
+	start := fs.String("start", "", "First key to export")
+	end := fs.String("end", "", "Export keys before this one")
+	format := fs.String("format", formatJSONL, "Record format: jsonl or csv")
+	encoding := fs.String("encoding", encodingBase64, "Encoding of keys and values: base64, hex or text")
+	output := fs.String("o", "", "File to write to instead of standard output")
+	if err := parseTargetArgs(fs, args, server, 0); err != nil {
+		return err
+	}
+	if err := checkEncoding(*encoding); err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	} else {
+		// Keep what the engine prints out of the records
+		os.Stdout = os.Stderr
+		defer func() { os.Stdout = out }()
+	}
+	buffered := bufio.NewWriterSize(out, 1<<20)
+	w, err := newRecordWriter(buffered, *format, *encoding)
+	if err != nil {
+		return err
+	}
+
+	startKey, endKey := scanBounds([]byte(*prefix), []byte(*start), []byte(*end))
+	var count int
+	if *server != "" {
+		count, err = exportServer(*server, startKey, endKey, w)
+	} else {
+		count, err = exportDB(fs.Arg(0), startKey, endKey, w)
+	}
+	if err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+	if *output != "" {
+		if err := out.Sync(); err != nil {
+			return err
+		}
+		fmt.Printf("Exported %d records to %s\n", count, *output)
+	}
+	return nil
+}
+
+// exportDB writes the keys in [start, end) of the database at path to w, as
+// seen by a read-only transaction
+func exportDB(path string, start, end []byte, w recordWriter) (int, error) {
+	eng, err := openExistingDB(path)
+	if err != nil {
+		return 0, err
+	}
+	defer eng.Close()
+
+	tx, err := eng.BeginTransaction(true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	count := 0
+	iter := tx.NewRangeIterator(start, end)
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		if iter.IsTombstone() {
+			continue
+		}
+		if err := w.Write(iter.Key(), iter.Value()); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// exportServer writes the keys in [start, end) of a running server to w. The
+// server scans them in a single read-only transaction.
+func exportServer(address string, start, end []byte, w recordWriter) (int, error) {
+	c, err := connectServer(address)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+
+	scanner, err := c.Scan(context.Background(), client.ScanOptions{StartKey: start, EndKey: end})
+	if err != nil {
+		return 0, err
+	}
+	defer scanner.Close()
+
+	count := 0
+	for scanner.Next() {
+		if err := w.Write(scanner.Key(), scanner.Value()); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := scanner.Error(); err != nil {
+		return count, err
+	}
+	return count, nil
+}
+
+// openExistingDB opens the database at path, which must already exist
+func openExistingDB(path string) (*engine.EngineFacade, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("no database at %s", path)
+	}
+	eng, err := engine.NewEngineFacade(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return eng, nil
+}
+
+// connectServer connects a client to the server at address
+func connectServer(address string) (*client.Client, error) {
+	options := client.DefaultClientOptions()
+	options.Endpoint = address
+	c, err := client.NewClient(options)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Connect(context.Background()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// parseTargetArgs parses the flags of a command that takes a database path
+// unless it's given a server address, followed by up to optional arguments
+func parseTargetArgs(fs *flag.FlagSet, args []string, server *string, optional int) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	required := 1
+	if *server != "" {
+		required = 0
+	}
+	if fs.NArg() < required || fs.NArg() > required+optional {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	return nil
+}
+
+// scanBounds returns the range [start, end) covering the keys that have
+// prefix and fall in [start, end), where empty bounds are unbounded
+func scanBounds(prefix, start, end []byte) ([]byte, []byte) {
+	if len(start) == 0 {
+		start = nil
+	}
+	if len(end) == 0 {
+		end = nil
+	}
+	if len(prefix) == 0 {
+		return start, end
+	}
+	if string(prefix) > string(start) {
+		start = prefix
+	}
+	if prefixEnd := prefixSuccessor(prefix); prefixEnd != nil && (len(end) == 0 || string(prefixEnd) < string(end)) {
+		end = prefixEnd
+	}
+	return start, end
+}
+
+// prefixSuccessor returns the smallest key greater than every key with
+// prefix, or nil if there is none because the prefix is all 0xff bytes
+func prefixSuccessor(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xff {
+			successor := append([]byte(nil), prefix[:i+1]...)
+			successor[i]++
+			return successor
+		}
+	}
+	return nil
+}
+
+// checkEncoding returns an error for unknown key and value encodings
+func checkEncoding(encoding string) error {
+	switch encoding {
+	case encodingBase64, encodingHex, encodingText:
+		return nil
+	default:
+		return fmt.Errorf("unknown encoding %q, expected base64, hex or text", encoding)
+	}
+}
+
+// encodeBytes encodes a key or value for a record
+func encodeBytes(encoding string, data []byte) (string, error) {
+	switch encoding {
+	case encodingHex:
+		return hex.EncodeToString(data), nil
+	case encodingText:
+		if !utf8.Valid(data) {
+			return "", fmt.Errorf("%q is not valid UTF-8, export with -encoding base64 or hex", data)
+		}
+		return string(data), nil
+	default:
+		return base64.StdEncoding.EncodeToString(data), nil
+	}
+}
+
+// decodeBytes decodes a key or value of a record
+func decodeBytes(encoding, s string) ([]byte, error) {
+	switch encoding {
+	case encodingHex:
+		return hex.DecodeString(s)
+	case encodingText:
+		return []byte(s), nil
+	default:
+		return base64.StdEncoding.DecodeString(s)
+	}
+}
+
+// record is a key-value pair as written in JSON lines
+type record struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// recordWriter writes exported key-value pairs
+type recordWriter interface {
+	Write(key, value []byte) error
+	Flush() error
+}
+
+// newRecordWriter creates a writer of records in the given format
+func newRecordWriter(w io.Writer, format, encoding string) (recordWriter, error) {
+	switch format {
+	case formatJSONL:
+		return &jsonlWriter{enc: json.NewEncoder(w), encoding: encoding}, nil
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"key", "value"}); err != nil {
+			return nil, err
+		}
+		return &csvWriter{w: cw, encoding: encoding}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected jsonl or csv", format)
+	}
+}
+
+// jsonlWriter writes one JSON object per line
+type jsonlWriter struct {
+	enc      *json.Encoder
+	encoding string
+}
+
+func (w *jsonlWriter) Write(key, value []byte) error {
+	var r record
+	var err error
+	if r.Key, err = encodeBytes(w.encoding, key); err != nil {
+		return err
+	}
+	if r.Value, err = encodeBytes(w.encoding, value); err != nil {
+		return err
+	}
+	return w.enc.Encode(r)
+}
+
+func (w *jsonlWriter) Flush() error {
+	return nil
+}
+
+// csvWriter writes a key,value row per record after the header
+type csvWriter struct {
+	w        *csv.Writer
+	encoding string
+}
+
+func (w *csvWriter) Write(key, value []byte) error {
+	k, err := encodeBytes(w.encoding, key)
+	if err != nil {
+		return err
+	}
+	v, err := encodeBytes(w.encoding, value)
+	if err != nil {
+		return err
+	}
+	return w.w.Write([]string{k, v})
+}
+
+func (w *csvWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// recordReader reads imported key-value pairs, returning io.EOF after the
+// last one
+type recordReader interface {
+	Read() (key, value []byte, err error)
+}
+
+// newRecordReader creates a reader of records in the given format
+func newRecordReader(r io.Reader, format, encoding string) (recordReader, error) {
+	switch format {
+	case formatJSONL:
+		return &jsonlReader{dec: json.NewDecoder(r), encoding: encoding}, nil
+	case formatCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = 2
+		cr.ReuseRecord = true
+		header, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return &csvReader{r: cr, encoding: encoding}, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if header[0] != "key" || header[1] != "value" {
+			return nil, fmt.Errorf("expected a key,value header, got %q", header)
+		}
+		return &csvReader{r: cr, encoding: encoding}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected jsonl or csv", format)
+	}
+}
+
+// jsonlReader reads one JSON object per line
+type jsonlReader struct {
+	dec      *json.Decoder
+	encoding string
+	line     int
+}
+
+func (r *jsonlReader) Read() ([]byte, []byte, error) {
+	var rec record
+	if err := r.dec.Decode(&rec); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, io.EOF
+		}
+		return nil, nil, fmt.Errorf("line %d: %w", r.line+1, err)
+	}
+	r.line++
+	return decodeRecord(r.encoding, rec.Key, rec.Value, r.line)
+}
+
+// csvReader reads key,value rows
+type csvReader struct {
+	r        *csv.Reader
+	encoding string
+}
+
+func (r *csvReader) Read() ([]byte, []byte, error) {
+	row, err := r.r.Read()
+	if err != nil {
+		return nil, nil, err
+	}
+	line, _ := r.r.FieldPos(0)
+	return decodeRecord(r.encoding, row[0], row[1], line)
+}
+
+// decodeRecord decodes the key and value of the record on the given line
+func decodeRecord(encoding, k, v string, line int) ([]byte, []byte, error) {
+	key, err := decodeBytes(encoding, k)
+	if err != nil {
+		return nil, nil, fmt.Errorf("line %d: invalid %s key: %w", line, encoding, err)
+	}
+	if len(key) == 0 {
+		return nil, nil, fmt.Errorf("line %d: empty key", line)
+	}
+	value, err := decodeBytes(encoding, v)
+	if err != nil {
+		return nil, nil, fmt.Errorf("line %d: invalid %s value: %w", line, encoding, err)
+	}
+	return key, value, nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/KevoDB/kevo/pkg/engine"
+)
+
+func TestExportImport(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source")
+
+	eng, err := engine.NewEngineFacade(source)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	data := map[string][]byte{
+		"bin-\x00\xff": {0x00, 0x01, 0xfe, 0xff},
+		"bin-\xff":     {},
+		"text-a":       []byte("comma, \"quote\"\nnewline"),
+		"text-b":       []byte("plain"),
+		"z":            []byte("last"),
+	}
+	for key, value := range data {
+		if err := eng.Put([]byte(key), value); err != nil {
+			t.Fatalf("Failed to put: %v", err)
+		}
+	}
+	if err := eng.Put([]byte("deleted"), []byte("value")); err != nil {
+		t.Fatalf("Failed to put: %v", err)
+	}
+	if err := eng.Delete([]byte("deleted")); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if err := eng.Close(); err != nil {
+		t.Fatalf("Failed to close engine: %v", err)
+	}
+
+	all := []string{"bin-\x00\xff", "bin-\xff", "text-a", "text-b", "z"}
+	tests := []struct {
+		name     string
+		format   []string
+		filter   []string
+		expected []string
+	}{
+		{"jsonl base64", nil, nil, all},
+		{"csv hex", []string{"-format", "csv", "-encoding", "hex"}, nil, all},
+		{"csv text", []string{"-format", "csv", "-encoding", "text"}, []string{"-prefix", "text-"}, []string{"text-a", "text-b"}},
+		{"prefix", nil, []string{"-prefix", "bin-"}, []string{"bin-\x00\xff", "bin-\xff"}},
+		{"prefix and range", nil, []string{"-prefix", "text-", "-start", "text-b", "-end", "zz"}, []string{"text-b"}},
+		{"range", nil, []string{"-start", "text-", "-end", "z"}, []string{"text-a", "text-b"}},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(dir, fmt.Sprintf("export-%d", i))
+			args := append(append([]string{"-o", file}, tc.format...), tc.filter...)
+			if err := runExport(append(args, source)); err != nil {
+				t.Fatalf("Failed to export: %v", err)
+			}
+
+			target := filepath.Join(dir, fmt.Sprintf("target-%d", i))
+			args = append([]string{"-batch-size", "2"}, tc.format...)
+			if err := runImport(append(args, target, file)); err != nil {
+				t.Fatalf("Failed to import: %v", err)
+			}
+
+			imported, err := engine.NewEngineFacade(target)
+			if err != nil {
+				t.Fatalf("Failed to open imported database: %v", err)
+			}
+			defer imported.Close()
+
+			iter, err := imported.GetIterator()
+			if err != nil {
+				t.Fatalf("Failed to get iterator: %v", err)
+			}
+			var keys []string
+			for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+				if iter.IsTombstone() {
+					continue
+				}
+				keys = append(keys, string(iter.Key()))
+				if !bytes.Equal(iter.Value(), data[string(iter.Key())]) {
+					t.Errorf("Expected %q = %q, got %q", iter.Key(), data[string(iter.Key())], iter.Value())
+				}
+			}
+			if fmt.Sprint(keys) != fmt.Sprint(tc.expected) {
+				t.Errorf("Expected keys %q, got %q", tc.expected, keys)
+			}
+		})
+	}
+
+	// Binary data can't be exported as text
+	if err := runExport([]string{"-o", filepath.Join(dir, "binary"), "-encoding", "text", source}); err == nil {
+		t.Error("Expected an error exporting binary keys as text")
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/KevoDB/kevo/pkg/client"
+	"github.com/KevoDB/kevo/pkg/engine"
+	"github.com/KevoDB/kevo/pkg/wal"
+)
+
+const importUsage = `import [options] DB_PATH [FILE]
+       kevo import -server ADDRESS [options] [FILE]
+
+Writes the records of FILE, or of standard input, as exported by 'kevo export'
+to a database that isn't open elsewhere, created if needed, or to a running
+server. Records are written in batches, each applied atomically; a failure
+leaves the batches before it written.`
+
+// importBatchBytes caps the keys and values in a batch, which keeps batches
+// sent to a server well under the maximum message size
+const importBatchBytes = 4 << 20
+
+func runImport(args []string) error {
+	fs := newFlagSet("import", importUsage)
+	server := fs.String("server", "", "Address of a running server to import into instead of DB_PATH")
+	format := fs.String("format", formatJSONL, "Record format: jsonl or csv")
+	encoding := fs.String("encoding", encodingBase64, "Encoding of keys and values: base64, hex or text")
+	batchSize := fs.Int("batch-size", 10000, "Maximum number of records in a batch")
+	if err := parseTargetArgs(fs, args, server, 1); err != nil {
+		return err
+	}
+	if err := checkEncoding(*encoding); err != nil {
+		return err
+	}
+	if *batchSize <= 0 {
+		return fmt.Errorf("batch size must be positive")
+	}
+
+	// The file comes after the database path unless importing into a server
+	input := fs.Arg(1)
+	if *server != "" {
+		input = fs.Arg(0)
+	}
+	in := os.Stdin
+	if input != "" && input != "-" {
+		f, err := os.Open(input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	r, err := newRecordReader(bufio.NewReaderSize(in, 1<<20), *format, *encoding)
+	if err != nil {
+		return err
+	}
+
+	var write func(batch []kv) error
+	if *server != "" {
+		c, err := connectServer(*server)
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		write = func(batch []kv) error {
+			return writeServerBatch(c, batch)
+		}
+	} else {
+		eng, err := engine.NewEngineFacade(fs.Arg(0))
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer eng.Close()
+		write = func(batch []kv) error {
+			entries := make([]*wal.Entry, len(batch))
+			for i, pair := range batch {
+				entries[i] = &wal.Entry{Type: wal.OpTypePut, Key: pair.key, Value: pair.value}
+			}
+			return eng.ApplyBatch(entries)
+		}
+	}
+
+	count, err := importRecords(r, *batchSize, write)
+	if err != nil {
+		return fmt.Errorf("imported %d records before failing: %w", count, err)
+	}
+	fmt.Printf("Imported %d records\n", count)
+	return nil
+}
+
+// kv is a key-value pair waiting to be written
+type kv struct {
+	key   []byte
+	value []byte
+}
+
+// importRecords reads all records of r and passes them to write in batches of
+// up to batchSize records, returning the number written
+func importRecords(r recordReader, batchSize int, write func(batch []kv) error) (int, error) {
+	count := 0
+	batch := make([]kv, 0, batchSize)
+	bytes := 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := write(batch); err != nil {
+			return err
+		}
+		count += len(batch)
+		batch = batch[:0]
+		bytes = 0
+		return nil
+	}
+
+	for {
+		key, value, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return count, err
+		}
+		batch = append(batch, kv{key, value})
+		bytes += len(key) + len(value)
+		if len(batch) >= batchSize || bytes >= importBatchBytes {
+			if err := flush(); err != nil {
+				return count, err
+			}
+		}
+	}
+	return count, flush()
+}
+
+// writeServerBatch writes a batch of records to a server atomically
+func writeServerBatch(c *client.Client, batch []kv) error {
+	ops := make([]client.BatchOperation, len(batch))
+	for i, pair := range batch {
+		ops[i] = client.BatchOperation{Type: "put", Key: pair.key, Value: pair.value}
+	}
+	ok, err := c.BatchWrite(context.Background(), ops, false)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("server rejected the batch")
+	}
+	return nil
+}
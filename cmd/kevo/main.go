@@ -33,6 +33,8 @@ var completer = readline.NewPrefixCompleter(
 	readline.PcItem(".exit"),
 	readline.PcItem(".stats"),
 	readline.PcItem(".flush"),
+	readline.PcItem(".checkpoint"),
+	readline.PcItem(".ingest"),
 	readline.PcItem("BEGIN",
 		readline.PcItem("TRANSACTION"),
 		readline.PcItem("READONLY"),
@@ -53,6 +55,7 @@ Kevo (kevo) - A lightweight, minimalist, storage engine.
 
 Usage:
   kevo [options] [database_path]  - Start with an optional database path
+  kevo <command> [arguments]      - Run a tool such as backup, see kevo -h
 
 Options:
   -server                 - Run in server mode, exposing a gRPC API
@@ -66,6 +69,8 @@ Commands (interactive mode only):
   .exit                   - Exit the program
   .stats                  - Show database statistics
   .flush                  - Force flush memtables to disk
+  .checkpoint PATH        - Write a consistent copy of the database to PATH
+  .ingest PATH...         - Add SSTable files written with sstable.SSTFileWriter
 
   BEGIN [TRANSACTION]     - Begin a transaction (default: read-write)
   BEGIN READONLY          - Begin a read-only transaction
@@ -102,6 +107,9 @@ type Config struct {
 }
 
 func main() {
+	// Tools such as backup run instead of the shell or server
+	runCommand(os.Args[1:])
+
 	// Parse command line arguments and get configuration
 	config := parseFlags()
 
@@ -140,11 +148,13 @@ func parseFlags() Config {
 	// Define custom usage message
 	flag.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), "Kevo - A lightweight key-value storage engine\n\n")
-		fmt.Fprintf(flag.CommandLine.Output(), "Usage: kevo [options] [database_path]\n\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: kevo [options] [database_path]\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "       kevo <command> [arguments]\n\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "By default, kevo runs in interactive mode with a command-line interface.\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "If -server flag is provided, kevo runs as a server exposing a gRPC API.\n\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "Options:\n")
 		flag.PrintDefaults()
+		printCommands(flag.CommandLine.Output())
 		fmt.Fprintf(flag.CommandLine.Output(), "\nInteractive mode commands (when not using -server):\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  PUT key value           - Store a key-value pair\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  GET key                 - Retrieve a value by key\n")
@@ -611,6 +621,40 @@ func runInteractive(eng *engine.EngineFacade, dbPath string) {
 					fmt.Println("Memtables flushed to disk")
 				}
 
+			case ".checkpoint":
+				if eng == nil {
+					fmt.Println("No database open")
+					continue
+				}
+				if len(parts) < 2 {
+					fmt.Println("Error: Missing path argument")
+					continue
+				}
+
+				seqNum, err := eng.CreateCheckpoint(parts[1])
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error creating checkpoint: %s\n", err)
+				} else {
+					fmt.Printf("Checkpoint created at %s (sequence %d)\n", parts[1], seqNum)
+				}
+
+			case ".ingest":
+				if eng == nil {
+					fmt.Println("No database open")
+					continue
+				}
+				if len(parts) < 2 {
+					fmt.Println("Error: Missing path argument")
+					continue
+				}
+
+				seqNum, err := eng.IngestFiles(parts[1:])
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error ingesting files: %s\n", err)
+				} else {
+					fmt.Printf("Ingested %d files (sequence %d)\n", len(parts)-1, seqNum)
+				}
+
 			default:
 				fmt.Printf("Unknown command: %s\n", cmd)
 			}
@@ -0,0 +1,437 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/KevoDB/kevo/pkg/sstable"
+	"github.com/KevoDB/kevo/pkg/sstable/footer"
+)
+
+const sstUsage = `sst <subcommand> [-json] [arguments]
+
+Subcommands:
+  dump [-start KEY] [-limit N] FILE  - Print the entries and range tombstones
+  index FILE                         - List the data blocks from the index
+  footer FILE                        - Decode the footer
+  bloom FILE                         - Report the bloom filter of each block
+  verify FILE...                     - Check every block checksum and the key order
+
+With -json, output is JSON, one entry per line for dump. Keys and values are
+base64 encoded.
+`
+
+func runSST(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: kevo %s", sstUsage)
+		return flag.ErrHelp
+	}
+
+	switch args[0] {
+	case "dump":
+		return sstDump(args[1:])
+	case "index":
+		return sstIndex(args[1:])
+	case "footer":
+		return sstFooter(args[1:])
+	case "bloom":
+		return sstBloom(args[1:])
+	case "verify":
+		return sstVerify(args[1:])
+	case "-h", "-help", "--help", "help":
+		fmt.Fprintf(os.Stderr, "Usage: kevo %s", sstUsage)
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("unknown sst subcommand %q", args[0])
+	}
+}
+
+// sstEntry is an entry of an SSTable as dumped
+type sstEntry struct {
+	Key      []byte `json:"key"`
+	Sequence uint64 `json:"sequence"`
+	Kind     string `json:"kind"`
+	ExpireAt uint64 `json:"expire_at,omitempty"`
+	Value    []byte `json:"value,omitempty"`
+}
+
+// sstRangeTombstone is a range tombstone of an SSTable as dumped
+type sstRangeTombstone struct {
+	Start    []byte `json:"start"`
+	End      []byte `json:"end"`
+	Sequence uint64 `json:"sequence"`
+	Kind     string `json:"kind"`
+}
+
+func sstDump(args []string) error {
+	fs := newFlagSet("sst dump", "sst dump [-json] [-start KEY] [-limit N] FILE")
+	asJSON := fs.Bool("json", false, "Print JSON lines")
+	start := fs.String("start", "", "First key to print")
+	limit := fs.Int("limit", 0, "Maximum number of entries to print, 0 for all")
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	reader, err := sstable.OpenReader(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	enc := json.NewEncoder(os.Stdout)
+	for _, t := range reader.RangeTombstones() {
+		if *asJSON {
+			if err := enc.Encode(sstRangeTombstone{t.Start, t.End, t.SeqNum, "delete_range"}); err != nil {
+				return err
+			}
+			continue
+		}
+		fmt.Printf("[%q, %q) seq=%d delete_range\n", t.Start, t.End, t.SeqNum)
+	}
+
+	iter := reader.NewIteratorWithOptions(sstable.ReadOptions{})
+	if *start != "" {
+		iter.Seek([]byte(*start))
+	} else {
+		iter.SeekToFirst()
+	}
+	for count := 0; iter.Valid() && (*limit == 0 || count < *limit); count++ {
+		entry := sstEntry{
+			Key:      iter.Key(),
+			Sequence: iter.SequenceNumber(),
+			Kind:     "put",
+			ExpireAt: iter.ExpireAt(),
+			Value:    iter.Value(),
+		}
+		if iter.IsTombstone() {
+			entry.Kind = "delete"
+		} else if iter.IsMergeOperand() {
+			entry.Kind = "merge"
+		}
+
+		if *asJSON {
+			if err := enc.Encode(entry); err != nil {
+				return err
+			}
+		} else {
+			fmt.Printf("%q seq=%d %s", entry.Key, entry.Sequence, entry.Kind)
+			if entry.ExpireAt != 0 {
+				fmt.Printf(" expire_at=%s", time.Unix(0, int64(entry.ExpireAt)).Format(time.RFC3339Nano))
+			}
+			if entry.Kind != "delete" {
+				fmt.Printf(" value=%q", entry.Value)
+			}
+			fmt.Println()
+		}
+		iter.Next()
+	}
+	return iter.Error()
+}
+
+// sstBlock is an index entry of an SSTable
+type sstBlock struct {
+	Offset   uint64 `json:"offset"`
+	Size     uint32 `json:"size"`
+	FirstKey []byte `json:"first_key"`
+}
+
+func sstIndex(args []string) error {
+	fs := newFlagSet("sst index", "sst index [-json] FILE")
+	asJSON := fs.Bool("json", false, "Print JSON")
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	reader, err := sstable.OpenReader(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	locators, err := reader.IndexEntries()
+	if err != nil {
+		return err
+	}
+	blocks := make([]sstBlock, len(locators))
+	for i, locator := range locators {
+		blocks[i] = sstBlock{locator.Offset, locator.Size, locator.Key}
+	}
+	if *asJSON {
+		return printJSON(blocks)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BLOCK\tOFFSET\tSIZE\tFIRST KEY")
+	for i, b := range blocks {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%q\n", i, b.Offset, b.Size, b.FirstKey)
+	}
+	return w.Flush()
+}
+
+// sstFooterInfo is the footer of an SSTable as printed
+type sstFooterInfo struct {
+	Version            uint32   `json:"version"`
+	Features           []string `json:"features"`
+	Timestamp          int64    `json:"timestamp"`
+	FileSize           int64    `json:"file_size"`
+	NumEntries         uint32   `json:"num_entries"`
+	IndexOffset        uint64   `json:"index_offset"`
+	IndexSize          uint32   `json:"index_size"`
+	BloomFilterOffset  uint64   `json:"bloom_filter_offset"`
+	BloomFilterSize    uint32   `json:"bloom_filter_size"`
+	RangeTombstoneSize uint32   `json:"range_tombstone_size"`
+	Checksum           uint64   `json:"checksum"`
+}
+
+func sstFooter(args []string) error {
+	fs := newFlagSet("sst footer", "sst footer [-json] FILE")
+	asJSON := fs.Bool("json", false, "Print JSON")
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	// Only the footer is read, so it can be looked at when the rest of the
+	// file doesn't open
+	ft, size, err := readFooter(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	info := sstFooterInfo{
+		Version:            ft.Version,
+		Features:           formatFeatures(ft.Version),
+		Timestamp:          ft.Timestamp,
+		FileSize:           size,
+		NumEntries:         ft.NumEntries,
+		IndexOffset:        ft.IndexOffset,
+		IndexSize:          ft.IndexSize,
+		BloomFilterOffset:  ft.BloomFilterOffset,
+		BloomFilterSize:    ft.BloomFilterSize,
+		RangeTombstoneSize: ft.RangeTombstoneSize,
+		Checksum:           ft.Checksum,
+	}
+	if *asJSON {
+		return printJSON(info)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if len(info.Features) > 0 {
+		fmt.Fprintf(w, "Version:\t%d (%s)\n", ft.Version, strings.Join(info.Features, ", "))
+	} else {
+		fmt.Fprintf(w, "Version:\t%d\n", ft.Version)
+	}
+	fmt.Fprintf(w, "Created:\t%s\n", time.Unix(0, ft.Timestamp).Format(time.RFC3339Nano))
+	fmt.Fprintf(w, "File size:\t%d\n", size)
+	fmt.Fprintf(w, "Entries:\t%d\n", ft.NumEntries)
+	fmt.Fprintf(w, "Index:\toffset %d, size %d\n", ft.IndexOffset, ft.IndexSize)
+	if ft.Version >= 2 {
+		fmt.Fprintf(w, "Bloom filters:\toffset %d, size %d\n", ft.BloomFilterOffset, ft.BloomFilterSize)
+	}
+	if ft.Version >= 3 {
+		fmt.Fprintf(w, "Range tombstones:\tsize %d\n", ft.RangeTombstoneSize)
+	}
+	fmt.Fprintf(w, "Checksum:\t%#016x\n", ft.Checksum)
+	return w.Flush()
+}
+
+// readFooter decodes the footer of the SSTable at path and returns it with
+// the size of the file
+func readFooter(path string) (*footer.Footer, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	if stat.Size() < footer.FooterSize {
+		return nil, 0, fmt.Errorf("file too small to be valid SSTable: %d bytes", stat.Size())
+	}
+	data := make([]byte, footer.FooterSize)
+	if _, err := f.ReadAt(data, stat.Size()-footer.FooterSize); err != nil && !errors.Is(err, io.EOF) {
+		return nil, 0, err
+	}
+	ft, err := footer.Decode(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	return ft, stat.Size(), nil
+}
+
+// formatFeatures lists what a version of the file format adds to the first
+func formatFeatures(version uint32) []string {
+	features := []string{}
+	if version >= 2 {
+		features = append(features, "bloom filters")
+	}
+	if version >= 3 {
+		features = append(features, "range tombstones")
+	}
+	if version >= footer.VersionBlockCompression {
+		features = append(features, "block compression")
+	}
+	if version > footer.CurrentVersion {
+		features = append(features, "unknown")
+	}
+	return features
+}
+
+// sstBloomInfo is the bloom filter summary of an SSTable
+type sstBloomInfo struct {
+	Filters      []sstable.BloomFilterStats `json:"filters"`
+	Blocks       int                        `json:"blocks"`
+	Bytes        uint32                     `json:"bytes"`
+	Keys         uint64                     `json:"keys"`
+	BitsPerKey   float64                    `json:"bits_per_key"`
+	EstimatedFPR float64                    `json:"estimated_fpr"`
+}
+
+func sstBloom(args []string) error {
+	fs := newFlagSet("sst bloom", "sst bloom [-json] FILE")
+	asJSON := fs.Bool("json", false, "Print JSON")
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	reader, err := sstable.OpenReader(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	locators, err := reader.IndexEntries()
+	if err != nil {
+		return err
+	}
+	info := sstBloomInfo{
+		Filters: reader.BloomFilterStats(),
+		Blocks:  len(locators),
+		Bytes:   reader.Footer().BloomFilterSize,
+	}
+
+	// The FPR of a lookup is that of the one filter it checks, so the file's
+	// is the average over keys
+	var bits uint64
+	for _, filter := range info.Filters {
+		bits += filter.Bits
+		info.Keys += filter.Keys
+		info.EstimatedFPR += filter.EstimatedFPR * float64(filter.Keys)
+	}
+	if info.Keys > 0 {
+		info.BitsPerKey = float64(bits) / float64(info.Keys)
+		info.EstimatedFPR /= float64(info.Keys)
+	}
+	if *asJSON {
+		return printJSON(info)
+	}
+
+	if len(info.Filters) == 0 {
+		fmt.Println("No bloom filters")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BLOCK OFFSET\tBITS\tHASHES\tKEYS\tESTIMATED FPR")
+	for _, filter := range info.Filters {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%.4f%%\n", filter.BlockOffset, filter.Bits,
+			filter.HashFunctions, filter.Keys, filter.EstimatedFPR*100)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\n%d filters for %d blocks, %d bytes, %d keys, %.1f bits per key, estimated FPR %.4f%%\n",
+		len(info.Filters), info.Blocks, info.Bytes, info.Keys, info.BitsPerKey, info.EstimatedFPR*100)
+	return nil
+}
+
+// sstVerifyResult is the outcome of verifying an SSTable
+type sstVerifyResult struct {
+	File     string   `json:"file"`
+	OK       bool     `json:"ok"`
+	Version  uint32   `json:"version,omitempty"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+func sstVerify(args []string) error {
+	fs := newFlagSet("sst verify", "sst verify [-json] FILE...")
+	asJSON := fs.Bool("json", false, "Print JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	results := make([]sstVerifyResult, 0, fs.NArg())
+	failed := 0
+	for _, path := range fs.Args() {
+		result := verifySSTable(path)
+		if !result.OK {
+			failed++
+		}
+		results = append(results, result)
+
+		if *asJSON {
+			continue
+		}
+		if result.OK {
+			fmt.Printf("%s: OK\n", path)
+			continue
+		}
+		fmt.Printf("%s: FAILED\n", path)
+		for _, problem := range result.Problems {
+			fmt.Printf("  %s\n", problem)
+		}
+	}
+	if *asJSON {
+		if err := printJSON(results); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d SSTables failed verification", failed, len(results))
+	}
+	return nil
+}
+
+// verifySSTable opens and verifies the SSTable at path
+func verifySSTable(path string) sstVerifyResult {
+	result := sstVerifyResult{File: path}
+	reader, err := sstable.OpenReader(path)
+	if err != nil {
+		result.Problems = []string{err.Error()}
+		return result
+	}
+	defer reader.Close()
+
+	result.Version = reader.Footer().Version
+	if err := reader.Verify(); err != nil {
+		var joined interface{ Unwrap() []error }
+		if errors.As(err, &joined) {
+			for _, problem := range joined.Unwrap() {
+				result.Problems = append(result.Problems, problem.Error())
+			}
+		} else {
+			result.Problems = []string{err.Error()}
+		}
+		return result
+	}
+	result.OK = true
+	return result
+}
+
+// printJSON prints v as indented JSON
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,465 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/KevoDB/kevo/pkg/config"
+	"github.com/KevoDB/kevo/pkg/engine"
+	"github.com/KevoDB/kevo/pkg/wal"
+)
+
+const walUsage = `wal <subcommand> [arguments]
+
+Subcommands:
+  dump [-json] [-from-seq N] PATH            - Print every entry and the records it's made of
+  stats [-json] [-truncate] PATH             - Report the sequence numbers in each file and
+                                               the gaps between them
+  replay [-until-seq N] -into DIR [-truncate] PATH
+                                             - Apply the entries up to sequence number N to a
+                                               new database at DIR
+
+PATH is a WAL file, a WAL directory or a database directory. A file whose end
+can't be read is reported, and with -truncate cut back to its last readable
+entry.
+`
+
+// runWAL runs the wal command
+func runWAL(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: kevo %s", walUsage)
+		return flag.ErrHelp
+	}
+
+	switch args[0] {
+	case "dump":
+		return walDump(args[1:])
+	case "stats":
+		return walStats(args[1:])
+	case "replay":
+		return walReplay(args[1:])
+	case "-h", "-help", "--help", "help":
+		fmt.Fprintf(os.Stderr, "Usage: kevo %s", walUsage)
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("unknown wal subcommand %q", args[0])
+	}
+}
+
+// walFilesAt returns the WAL files at path, which is a WAL file, a directory
+// of them or a database directory
+func walFilesAt(path string) ([]string, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !stat.IsDir() {
+		return []string{path}, nil
+	}
+
+	files, err := wal.FindWALFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	dir := path
+	if len(files) == 0 {
+		dir = filepath.Join(path, "wal")
+		if files, err = wal.FindWALFiles(dir); err != nil {
+			return nil, err
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no WAL files in %s", dir)
+	}
+	return files, nil
+}
+
+// walScan is what reading a WAL file found
+type walScan struct {
+	info    wal.WALFileInfo
+	entries int
+	// End of the last entry that could be read
+	valid int64
+	// Error that stopped reading before the end of the file
+	err error
+}
+
+// scanWALFile reads the entries of a WAL file, passing each to fn with the
+// records it was read from
+func scanWALFile(path string, fn func(*wal.Entry, []wal.RecordInfo) error) (*walScan, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := wal.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	scan := &walScan{info: wal.WALFileInfo{Path: path, Size: stat.Size(), CreatedAt: stat.ModTime()}}
+	for {
+		entry, err := reader.ReadEntry()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			scan.err = err
+			break
+		}
+
+		if scan.entries == 0 || entry.SequenceNumber < scan.info.MinSeq {
+			scan.info.MinSeq = entry.SequenceNumber
+		}
+		scan.info.MaxSeq = max(scan.info.MaxSeq, entry.SequenceNumber)
+		scan.entries++
+		if fn != nil {
+			if err := fn(entry, reader.Records()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	scan.valid = reader.Offset()
+	return scan, nil
+}
+
+// checkTail reports a WAL file whose end can't be read, and cuts it back to
+// its last readable entry if truncate is set. It returns whether the file
+// still has a corrupt tail.
+func checkTail(scan *walScan, truncate bool) (bool, error) {
+	if scan.err == nil {
+		return false, nil
+	}
+
+	fmt.Fprintf(os.Stderr, "%s: %d bytes from offset %d can't be read: %v\n",
+		scan.info.Path, scan.info.Size-scan.valid, scan.valid, scan.err)
+	if !truncate {
+		return true, nil
+	}
+	if err := os.Truncate(scan.info.Path, scan.valid); err != nil {
+		return true, fmt.Errorf("failed to truncate %s: %w", scan.info.Path, err)
+	}
+	fmt.Fprintf(os.Stderr, "%s: truncated to %d bytes\n", scan.info.Path, scan.valid)
+	return false, nil
+}
+
+// corruptTailError is the error of a command that left corrupt tails in n
+// of the files it read
+func corruptTailError(n, files int) error {
+	if n == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d WAL files have a corrupt tail", n, files)
+}
+
+// opTypeNames name the operation types of WAL entries
+var opTypeNames = map[uint8]string{
+	wal.OpTypePut:         "put",
+	wal.OpTypeDelete:      "delete",
+	wal.OpTypeMerge:       "merge",
+	wal.OpTypeDeleteRange: "delete_range",
+	wal.OpTypePutWithTTL:  "put_ttl",
+}
+
+// recordTypeNames name the types of physical WAL records
+var recordTypeNames = map[uint8]string{
+	wal.RecordTypeFull:   "full",
+	wal.RecordTypeFirst:  "first",
+	wal.RecordTypeMiddle: "middle",
+	wal.RecordTypeLast:   "last",
+}
+
+// walRecord is a physical record of a dumped entry
+type walRecord struct {
+	Offset int64  `json:"offset"`
+	Type   string `json:"type"`
+	Length int    `json:"length"`
+}
+
+// walEntry is an entry of a WAL file as dumped
+type walEntry struct {
+	File      string      `json:"file"`
+	Sequence  uint64      `json:"sequence"`
+	Op        string      `json:"op"`
+	Family    uint32      `json:"family,omitempty"`
+	Key       []byte      `json:"key"`
+	ValueSize int         `json:"value_size"`
+	Records   []walRecord `json:"records"`
+}
+
+func walDump(args []string) error {
+	fs := newFlagSet("wal dump", "wal dump [-json] [-from-seq N] PATH")
+	asJSON := fs.Bool("json", false, "Print JSON lines")
+	fromSeq := fs.Uint64("from-seq", 0, "First sequence number to print")
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	files, err := walFilesAt(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	corrupt := 0
+	for _, path := range files {
+		if !*asJSON && len(files) > 1 {
+			fmt.Printf("# %s\n", path)
+		}
+		scan, err := scanWALFile(path, func(e *wal.Entry, records []wal.RecordInfo) error {
+			if e.SequenceNumber < *fromSeq {
+				return nil
+			}
+			entry := walEntry{
+				File:      path,
+				Sequence:  e.SequenceNumber,
+				Op:        opTypeNames[e.Type],
+				Family:    e.Family,
+				Key:       e.Key,
+				ValueSize: len(e.Value),
+			}
+			for _, r := range records {
+				entry.Records = append(entry.Records, walRecord{r.Offset, recordTypeNames[r.Type], r.Length})
+			}
+			if *asJSON {
+				return enc.Encode(entry)
+			}
+
+			types := make([]string, len(entry.Records))
+			for i, r := range entry.Records {
+				types[i] = r.Type
+			}
+			fmt.Printf("seq=%d %s", entry.Sequence, entry.Op)
+			if entry.Family != wal.DefaultFamily {
+				fmt.Printf(" family=%d", entry.Family)
+			}
+			fmt.Printf(" %q", entry.Key)
+			if e.Type != wal.OpTypeDelete {
+				fmt.Printf(" value_size=%d", entry.ValueSize)
+			}
+			fmt.Printf(" offset=%d records=%s\n", records[0].Offset, strings.Join(types, ","))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if bad, _ := checkTail(scan, false); bad {
+			corrupt++
+		}
+	}
+	return corruptTailError(corrupt, len(files))
+}
+
+// walFileStats is the summary of a WAL file
+type walFileStats struct {
+	File      string    `json:"file"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+	Entries   int       `json:"entries"`
+	MinSeq    uint64    `json:"min_seq"`
+	MaxSeq    uint64    `json:"max_seq"`
+	ValidSize int64     `json:"valid_size"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// walGap is a range of sequence numbers that no WAL entry has
+type walGap struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+}
+
+func walStats(args []string) error {
+	fs := newFlagSet("wal stats", "wal stats [-json] [-truncate] PATH")
+	asJSON := fs.Bool("json", false, "Print JSON")
+	truncate := fs.Bool("truncate", false, "Cut files back to their last readable entry")
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	files, err := walFilesAt(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	// Entries of a batch share a sequence number, so only numbers skipped
+	// between entries are gaps
+	var stats []walFileStats
+	gaps := []walGap{}
+	var lastSeq uint64
+	corrupt := 0
+	for _, path := range files {
+		scan, err := scanWALFile(path, func(e *wal.Entry, _ []wal.RecordInfo) error {
+			if lastSeq != 0 && e.SequenceNumber > lastSeq+1 {
+				gaps = append(gaps, walGap{lastSeq + 1, e.SequenceNumber - 1})
+			}
+			lastSeq = max(lastSeq, e.SequenceNumber)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		s := walFileStats{
+			File:      path,
+			Size:      scan.info.Size,
+			CreatedAt: scan.info.CreatedAt,
+			Entries:   scan.entries,
+			MinSeq:    scan.info.MinSeq,
+			MaxSeq:    scan.info.MaxSeq,
+			ValidSize: scan.valid,
+		}
+		if scan.err != nil {
+			s.Error = scan.err.Error()
+		}
+		stats = append(stats, s)
+
+		bad, err := checkTail(scan, *truncate)
+		if err != nil {
+			return err
+		}
+		if bad {
+			corrupt++
+		}
+	}
+
+	if *asJSON {
+		if err := printJSON(struct {
+			Files []walFileStats `json:"files"`
+			Gaps  []walGap       `json:"gaps"`
+		}{stats, gaps}); err != nil {
+			return err
+		}
+		return corruptTailError(corrupt, len(files))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tSIZE\tENTRIES\tMIN SEQ\tMAX SEQ\tSTATUS")
+	for _, s := range stats {
+		status := "ok"
+		if s.Error != "" {
+			status = fmt.Sprintf("unreadable from offset %d", s.ValidSize)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%s\n", filepath.Base(s.File), s.Size, s.Entries, s.MinSeq, s.MaxSeq, status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for _, gap := range gaps {
+		if gap.From == gap.To {
+			fmt.Printf("Sequence number %d is missing\n", gap.From)
+		} else {
+			fmt.Printf("Sequence numbers %d to %d are missing\n", gap.From, gap.To)
+		}
+	}
+	return corruptTailError(corrupt, len(files))
+}
+
+func walReplay(args []string) error {
+	fs := newFlagSet("wal replay", "wal replay [-until-seq N] -into DIR [-truncate] PATH")
+	untilSeq := fs.Uint64("until-seq", 0, "Last sequence number to replay, 0 for everything")
+	into := fs.String("into", "", "Directory of the new database")
+	truncate := fs.Bool("truncate", false, "Cut files back to their last readable entry")
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+	if *into == "" {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if _, err := os.Stat(*into); err == nil {
+		return fmt.Errorf("%s already exists, replay into a new directory", *into)
+	}
+	last := *untilSeq
+	if last == 0 {
+		last = math.MaxUint64
+	}
+
+	files, err := walFilesAt(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	// The entries are copied into the WAL of the new database, which applies
+	// them with their own sequence numbers when it's opened
+	cfg := config.NewDefaultConfig(*into)
+	if err := os.MkdirAll(cfg.WALDir, 0755); err != nil {
+		return err
+	}
+	wal.DisableRecoveryLogs = true
+
+	var replayed, otherFamilies, copiedFiles int
+	var firstSeq, lastSeq uint64
+	corrupt := 0
+	for _, path := range files {
+		var wanted, families int
+		scan, err := scanWALFile(path, func(e *wal.Entry, _ []wal.RecordInfo) error {
+			if e.SequenceNumber > last {
+				return nil
+			}
+			if firstSeq == 0 || e.SequenceNumber < firstSeq {
+				firstSeq = e.SequenceNumber
+			}
+			lastSeq = max(lastSeq, e.SequenceNumber)
+			wanted++
+			if e.Family != wal.DefaultFamily {
+				families++
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		bad, err := checkTail(scan, *truncate)
+		if err != nil {
+			return err
+		}
+		if bad {
+			corrupt++
+		}
+		if wanted == 0 {
+			continue
+		}
+
+		copied, err := wal.CopyFile(path, filepath.Join(cfg.WALDir, filepath.Base(path)), last)
+		if err != nil {
+			return fmt.Errorf("failed to copy %s: %w", path, err)
+		}
+		replayed += copied
+		otherFamilies += families
+		copiedFiles++
+	}
+	if replayed == 0 {
+		os.RemoveAll(*into)
+		return errors.Join(fmt.Errorf("no entries to replay"), corruptTailError(corrupt, len(files)))
+	}
+
+	if err := cfg.SaveManifest(*into); err != nil {
+		return err
+	}
+	eng, err := engine.NewEngineFacade(*into)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	if err := eng.FlushImMemTables(); err != nil {
+		eng.Close()
+		return fmt.Errorf("failed to flush replayed entries: %w", err)
+	}
+	if err := eng.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Replayed %d entries from %d WAL files into %s, sequence numbers %d to %d\n",
+		replayed, copiedFiles, *into, firstSeq, lastSeq)
+	if otherFamilies > 0 {
+		fmt.Printf("%d entries of column families the new database doesn't have weren't applied\n", otherFamilies)
+	}
+	return corruptTailError(corrupt, len(files))
+}
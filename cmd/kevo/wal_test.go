@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KevoDB/kevo/pkg/engine"
+	"github.com/KevoDB/kevo/pkg/wal"
+)
+
+func TestWALReplay(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source")
+
+	eng, err := engine.NewEngineFacade(source)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := eng.Put([]byte(fmt.Sprintf("key-%d", i)), []byte("value")); err != nil {
+			t.Fatalf("Failed to put: %v", err)
+		}
+	}
+	if err := eng.Close(); err != nil {
+		t.Fatalf("Failed to close engine: %v", err)
+	}
+
+	// Leave a torn record at the end of the WAL
+	files, err := wal.FindWALFiles(filepath.Join(source, "wal"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Expected one WAL file, got %v (%v)", files, err)
+	}
+	info, err := os.Stat(files[0])
+	if err != nil {
+		t.Fatalf("Failed to stat WAL file: %v", err)
+	}
+	f, err := os.OpenFile(files[0], os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open WAL file: %v", err)
+	}
+	f.Write([]byte{1, 2, 3, 4, 50, 0, wal.RecordTypeFull, 1})
+	f.Close()
+
+	if err := walStats([]string{source}); err == nil {
+		t.Error("Expected stats to report the corrupt tail")
+	}
+
+	target := filepath.Join(dir, "target")
+	if err := walReplay([]string{"-until-seq", "4", "-into", target, "-truncate", source}); err != nil {
+		t.Fatalf("Failed to replay: %v", err)
+	}
+	if stat, err := os.Stat(files[0]); err != nil || stat.Size() != info.Size() {
+		t.Errorf("Expected the WAL to be truncated to %d bytes, got %v (%v)", info.Size(), stat.Size(), err)
+	}
+	if err := walStats([]string{source}); err != nil {
+		t.Errorf("Expected a readable WAL after truncating, got %v", err)
+	}
+
+	replayed, err := engine.NewEngineFacade(target)
+	if err != nil {
+		t.Fatalf("Failed to open replayed database: %v", err)
+	}
+	defer replayed.Close()
+	for i := 0; i < 10; i++ {
+		_, err := replayed.Get([]byte(fmt.Sprintf("key-%d", i)))
+		if i < 4 && err != nil {
+			t.Errorf("Expected key-%d to be replayed, got %v", i, err)
+		} else if i >= 4 && err == nil {
+			t.Errorf("Expected key-%d after sequence number 4 to be left out", i)
+		}
+	}
+
+	if err := walReplay([]string{"-into", target, source}); err == nil {
+		t.Error("Expected replaying into an existing directory to fail")
+	}
+}
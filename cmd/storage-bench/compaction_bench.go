@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/KevoDB/kevo/pkg/config"
 	"github.com/KevoDB/kevo/pkg/engine"
 )
 
@@ -18,6 +19,8 @@ type CompactionBenchmarkOptions struct {
 	ValueSize     int
 	WriteInterval time.Duration
 	TotalDuration time.Duration
+	// Subcompactions a compaction may run in parallel, 0 for the default
+	CompactionThreads int
 }
 
 // CompactionBenchmarkResult contains the results of a compaction benchmark
@@ -31,6 +34,9 @@ type CompactionBenchmarkResult struct {
 	MemoryUsage          uint64  // Peak memory usage
 	SSTableCount         int     // Number of SSTables created
 	CompactionCount      int     // Number of compactions performed
+	// Time to compact all the data written, once background compaction is done
+	FullCompactionDuration   time.Duration
+	FullCompactionThroughput float64 // MB/s
 }
 
 // RunCompactionBenchmark runs a benchmark focused on compaction performance
@@ -45,6 +51,15 @@ func RunCompactionBenchmark(opts CompactionBenchmarkOptions) (*CompactionBenchma
 		return nil, fmt.Errorf("failed to create benchmark directory: %v", err)
 	}
 
+	// Save the configuration the engine is opened with
+	if opts.CompactionThreads > 0 {
+		cfg := config.NewDefaultConfig(dataDir)
+		cfg.CompactionThreads = opts.CompactionThreads
+		if err := cfg.SaveManifest(dataDir); err != nil {
+			return nil, fmt.Errorf("failed to save configuration: %v", err)
+		}
+	}
+
 	// Create the engine
 	e, err := engine.NewEngine(dataDir)
 	if err != nil {
@@ -140,6 +155,23 @@ func RunCompactionBenchmark(opts CompactionBenchmarkOptions) (*CompactionBenchma
 	fmt.Println("Waiting for compactions to complete...")
 	time.Sleep(5 * time.Second)
 
+	// Compact everything that was written, which splits the work between
+	// parallel subcompactions
+	fmt.Println("Compacting all data...")
+	if err := e.FlushImMemTables(); err != nil {
+		fmt.Fprintf(os.Stderr, "Flush error: %v\n", err)
+	}
+	compactStart := time.Now()
+	startKey := []byte(fmt.Sprintf("compaction-key-%010d", 0))
+	endKey := []byte(fmt.Sprintf("compaction-key-%010d", keyCounter))
+	if err := e.CompactRange(startKey, endKey); err != nil {
+		fmt.Fprintf(os.Stderr, "Compaction error: %v\n", err)
+	} else {
+		result.FullCompactionDuration = time.Since(compactStart)
+		writtenBytes := float64(keyCounter) * float64(opts.ValueSize)
+		result.FullCompactionThroughput = writtenBytes / result.FullCompactionDuration.Seconds() / (1024 * 1024)
+	}
+
 	// Stop metrics collection
 	close(stopChan)
 	wg.Wait()
@@ -196,6 +228,11 @@ func RunCompactionBenchmark(opts CompactionBenchmarkOptions) (*CompactionBenchma
 		fmt.Println("  Compaction Duration: Unknown (no compaction metrics available)")
 	}
 
+	if result.FullCompactionDuration > 0 {
+		fmt.Printf("  Full Compaction Duration: %.2f seconds\n", result.FullCompactionDuration.Seconds())
+		fmt.Printf("  Full Compaction Throughput: %.2f MB/s\n", result.FullCompactionThroughput)
+	}
+
 	return result, nil
 }
 
@@ -215,16 +252,17 @@ func RunCompactionBenchmarkWithDefaults(dataDir string) error {
 }
 
 // CustomCompactionBenchmark allows running a compaction benchmark from the command line
-func CustomCompactionBenchmark(numKeys, valueSize int, duration time.Duration) error {
+func CustomCompactionBenchmark(numKeys, valueSize int, duration time.Duration, compactionThreads int) error {
 	// Create a dedicated directory for this benchmark
 	dataDir := filepath.Join(*dataDir, fmt.Sprintf("compaction-bench-%d", time.Now().Unix()))
 
 	opts := CompactionBenchmarkOptions{
-		DataDir:       dataDir,
-		NumKeys:       numKeys,
-		ValueSize:     valueSize,
-		WriteInterval: 5 * time.Second,
-		TotalDuration: duration,
+		DataDir:           dataDir,
+		NumKeys:           numKeys,
+		ValueSize:         valueSize,
+		WriteInterval:     5 * time.Second,
+		TotalDuration:     duration,
+		CompactionThreads: compactionThreads,
 	}
 
 	// Run the benchmark
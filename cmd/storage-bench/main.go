@@ -21,17 +21,18 @@ const (
 
 var (
 	// Command line flags
-	benchmarkType = flag.String("type", "all", "Type of benchmark to run (write, random-write, sequential-write, read, random-read, scan, range-scan, mixed, tune, compaction, or all)")
-	duration      = flag.Duration("duration", 10*time.Second, "Duration to run the benchmark")
-	numKeys       = flag.Int("keys", defaultKeyCount, "Number of keys to use")
-	valueSize     = flag.Int("value-size", defaultValueSize, "Size of values in bytes")
-	dataDir       = flag.String("data-dir", "./benchmark-data", "Directory to store benchmark data")
-	sequential    = flag.Bool("sequential", false, "Use sequential keys instead of random")
-	scanSize      = flag.Int("scan-size", 100, "Number of entries to scan in range scan benchmarks")
-	cpuProfile    = flag.String("cpu-profile", "", "Write CPU profile to file")
-	memProfile    = flag.String("mem-profile", "", "Write memory profile to file")
-	resultsFile   = flag.String("results", "", "File to write results to (in addition to stdout)")
-	tuneParams    = flag.Bool("tune", false, "Run configuration tuning benchmarks")
+	benchmarkType     = flag.String("type", "all", "Type of benchmark to run (write, random-write, sequential-write, read, random-read, scan, range-scan, mixed, tune, compaction, or all)")
+	duration          = flag.Duration("duration", 10*time.Second, "Duration to run the benchmark")
+	numKeys           = flag.Int("keys", defaultKeyCount, "Number of keys to use")
+	valueSize         = flag.Int("value-size", defaultValueSize, "Size of values in bytes")
+	dataDir           = flag.String("data-dir", "./benchmark-data", "Directory to store benchmark data")
+	sequential        = flag.Bool("sequential", false, "Use sequential keys instead of random")
+	scanSize          = flag.Int("scan-size", 100, "Number of entries to scan in range scan benchmarks")
+	cpuProfile        = flag.String("cpu-profile", "", "Write CPU profile to file")
+	memProfile        = flag.String("mem-profile", "", "Write memory profile to file")
+	resultsFile       = flag.String("results", "", "File to write results to (in addition to stdout)")
+	tuneParams        = flag.Bool("tune", false, "Run configuration tuning benchmarks")
+	compactionThreads = flag.Int("compaction-threads", 0, "Subcompactions a compaction may run in parallel (0 for the default)")
 )
 
 func main() {
@@ -131,7 +132,7 @@ func main() {
 			results = append(results, result)
 		case "compaction":
 			fmt.Println("Running compaction benchmark...")
-			if err := CustomCompactionBenchmark(*numKeys, *valueSize, *duration); err != nil {
+			if err := CustomCompactionBenchmark(*numKeys, *valueSize, *duration, *compactionThreads); err != nil {
 				fmt.Fprintf(os.Stderr, "Compaction benchmark failed: %v\n", err)
 				continue
 			}
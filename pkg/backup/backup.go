@@ -0,0 +1,806 @@
+// Package backup keeps incremental backups of a database in a directory of
+// their own. Each backup is a checkpoint of the database: its SSTables are
+// stored once no matter how many backups share them, and its WAL files are
+// archived alongside the ones retired by WAL retention. Restoring replays the
+// archived WAL on top of the SSTables of a backup, so a database can be
+// brought back to the latest archived state or to any sequence number since
+// the oldest backup kept.
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KevoDB/kevo/pkg/common/fsutil"
+	"github.com/KevoDB/kevo/pkg/config"
+	"github.com/KevoDB/kevo/pkg/sstable"
+	"github.com/KevoDB/kevo/pkg/versionlog"
+	"github.com/KevoDB/kevo/pkg/wal"
+)
+
+const (
+	// Subdirectories of a backup directory
+	sstDirName  = "sst"
+	walDirName  = "wal"
+	metaDirName = "meta"
+	tmpDirName  = "tmp"
+
+	// metaFileFormat is the name of the file describing a backup: id.json
+	metaFileFormat = "%06d.json"
+)
+
+// Common errors
+var (
+	ErrNotFound     = errors.New("backup not found")
+	ErrNoBackup     = errors.New("no backup to restore from")
+	ErrTargetExists = errors.New("restore target already exists")
+)
+
+// Checkpointer creates checkpoints of a database, see
+// engine.EngineFacade.CreateCheckpoint
+type Checkpointer interface {
+	CreateCheckpoint(dir string) (uint64, error)
+}
+
+// File is an SSTable file of a backup
+type File struct {
+	versionlog.FileMeta
+
+	// Name of the file in the sst directory of the backups, which it may
+	// share with other backups
+	Stored string `json:"stored"`
+
+	// Checksum of the file contents
+	CRC uint32 `json:"crc"`
+}
+
+// Family is the SSTable directory of a column family in a backup
+type Family struct {
+	// Directory relative to the SSTable directory of the database
+	Dir string `json:"dir"`
+
+	// Counters of the version the files were taken from
+	LastSequence   uint64 `json:"last_sequence"`
+	LogNumber      uint64 `json:"log_number"`
+	NextFileNumber uint64 `json:"next_file_number"`
+
+	Files []File `json:"files"`
+}
+
+// Info describes a backup
+type Info struct {
+	ID        uint64    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Last sequence number the backup holds
+	Sequence uint64 `json:"sequence"`
+
+	Families []Family `json:"families"`
+
+	// Configuration of the database when the backup was created
+	Config json.RawMessage `json:"config"`
+}
+
+// walStart returns the sequence number after which the WAL is needed to
+// restore the backup: the SSTables of every family hold all of its writes up
+// to there
+func (i *Info) walStart() uint64 {
+	start := i.Sequence
+	for _, family := range i.Families {
+		start = min(start, family.LastSequence)
+	}
+	return start
+}
+
+// sstEnd returns the highest sequence number written to the SSTables of the
+// backup, including the ones given to ingested files. It can't be restored to
+// an earlier sequence number.
+func (i *Info) sstEnd() uint64 {
+	var end uint64
+	for _, family := range i.Families {
+		end = max(end, family.LastSequence)
+		for _, file := range family.Files {
+			end = max(end, file.GlobalSequence)
+		}
+	}
+	return end
+}
+
+// ingested returns the sequence numbers given to ingested SSTables of the
+// backup, which the WAL has no entries for
+func (i *Info) ingested() map[uint64]bool {
+	seqNums := make(map[uint64]bool)
+	for _, family := range i.Families {
+		for _, file := range family.Files {
+			if file.GlobalSequence != 0 {
+				seqNums[file.GlobalSequence] = true
+			}
+		}
+	}
+	return seqNums
+}
+
+// Size returns the total size of the SSTables of the backup
+func (i *Info) Size() int64 {
+	var size int64
+	for _, family := range i.Families {
+		for _, file := range family.Files {
+			size += file.Size
+		}
+	}
+	return size
+}
+
+// Engine manages the backups in a directory. It is safe for concurrent use,
+// but a directory must not be used by several engines at once.
+type Engine struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// Open opens the backup directory dir, creating it if it doesn't exist
+func Open(dir string) (*Engine, error) {
+	for _, name := range []string{sstDirName, walDirName, metaDirName, tmpDirName} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create backup directory: %w", err)
+		}
+	}
+	return &Engine{dir: dir}, nil
+}
+
+// Dir returns the backup directory
+func (e *Engine) Dir() string {
+	return e.dir
+}
+
+// Create backs up the database behind db, which keeps serving reads and
+// writes meanwhile. Only the SSTables that no earlier backup has are stored,
+// and the WAL files of the database are archived along with the ones WAL
+// retention moved to its archive directory.
+func (e *Engine) Create(db Checkpointer) (*Info, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	backups, err := e.list()
+	if err != nil {
+		return nil, err
+	}
+	id := uint64(1)
+	if len(backups) > 0 {
+		id = backups[len(backups)-1].ID + 1
+	}
+
+	// The checkpoint is staged in the backup directory, so that its files
+	// can be moved into place rather than copied
+	staging := filepath.Join(e.dir, tmpDirName, fmt.Sprintf("%06d", id))
+	if err := os.RemoveAll(staging); err != nil {
+		return nil, fmt.Errorf("failed to clear staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	seqNum, err := db.CreateCheckpoint(staging)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(staging, config.DefaultManifestFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint manifest: %w", err)
+	}
+	cfg := config.NewDefaultConfig(staging)
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", config.ErrInvalidManifest, err)
+	}
+
+	info := &Info{
+		ID:        id,
+		CreatedAt: time.Now(),
+		Sequence:  seqNum,
+		Config:    data,
+	}
+
+	stored := storedFiles(backups)
+	err = filepath.WalkDir(cfg.SSTDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || !versionlog.Exists(path) {
+			return err
+		}
+		rel, err := filepath.Rel(cfg.SSTDir, path)
+		if err != nil {
+			return err
+		}
+		family, err := e.storeFamily(path, filepath.ToSlash(rel), stored)
+		if err != nil {
+			return err
+		}
+		info.Families = append(info.Families, family)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store SSTables: %w", err)
+	}
+	if err := fsutil.SyncDir(filepath.Join(e.dir, sstDirName)); err != nil {
+		return nil, err
+	}
+
+	// Retired WAL files go first, they are older than the live ones
+	walFiles, err := findWALFiles(cfg.WALArchiveDir)
+	if err != nil {
+		return nil, err
+	}
+	checkpointed, err := findWALFiles(cfg.WALDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range append(walFiles, checkpointed...) {
+		if err := e.archiveWAL(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := fsutil.SyncDir(filepath.Join(e.dir, walDirName)); err != nil {
+		return nil, err
+	}
+
+	if err := e.writeInfo(info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// storedFiles indexes the SSTables of backups by family directory, name and
+// size. SSTables are never modified, so a file matching all three is already
+// stored.
+func storedFiles(backups []*Info) map[string]File {
+	stored := make(map[string]File)
+	for _, info := range backups {
+		for _, family := range info.Families {
+			for _, file := range family.Files {
+				stored[storedKey(family.Dir, file.FileMeta)] = file
+			}
+		}
+	}
+	return stored
+}
+
+// storedKey returns the key of a file in the index of stored files
+func storedKey(dir string, file versionlog.FileMeta) string {
+	return fmt.Sprintf("%s/%s/%d", dir, file.Name, file.Size)
+}
+
+// storeFamily moves the SSTables of a checkpointed family directory that
+// aren't stored yet into the backup directory
+func (e *Engine) storeFamily(dir, rel string, stored map[string]File) (Family, error) {
+	log, err := versionlog.Open(dir)
+	if err != nil {
+		return Family{}, err
+	}
+	version := log.Current()
+	if err := log.Close(); err != nil {
+		return Family{}, err
+	}
+
+	family := Family{
+		Dir:            rel,
+		LastSequence:   version.LastSequence,
+		LogNumber:      version.LogNumber,
+		NextFileNumber: version.NextFileNumber,
+	}
+
+	for _, meta := range version.Files() {
+		if file, ok := stored[storedKey(rel, meta)]; ok {
+			family.Files = append(family.Files, file)
+			continue
+		}
+
+		path := filepath.Join(dir, meta.Name)
+		crc, err := fileCRC(path)
+		if err != nil {
+			return Family{}, err
+		}
+
+		// The checksum tells apart files of the same name in different families
+		file := File{
+			FileMeta: meta,
+			Stored:   fmt.Sprintf("%s_%08x.sst", strings.TrimSuffix(meta.Name, ".sst"), crc),
+			CRC:      crc,
+		}
+		dst := filepath.Join(e.dir, sstDirName, file.Stored)
+		if _, err := os.Stat(dst); os.IsNotExist(err) {
+			if err := os.Rename(path, dst); err != nil {
+				return Family{}, fmt.Errorf("failed to store SSTable %s: %w", meta.Name, err)
+			}
+		}
+
+		stored[storedKey(rel, meta)] = file
+		family.Files = append(family.Files, file)
+	}
+
+	return family, nil
+}
+
+// archiveWAL copies a WAL file into the backup directory, unless the copy
+// already there holds as many entries. A WAL file that was still being
+// written to when an earlier backup archived it is replaced.
+func (e *Engine) archiveWAL(path string) error {
+	dst := filepath.Join(e.dir, walDirName, filepath.Base(path))
+	if archived, err := os.Stat(dst); err == nil {
+		src, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("failed to stat WAL file: %w", err)
+		}
+		if src.Size() == archived.Size() {
+			return nil
+		}
+		_, srcMax, _ := sequenceRange(path)
+		_, archivedMax, _ := sequenceRange(dst)
+		if srcMax <= archivedMax {
+			return nil
+		}
+	}
+
+	tmpPath := dst + ".tmp"
+	os.Remove(tmpPath)
+	if err := fsutil.LinkFile(path, tmpPath); err != nil {
+		return fmt.Errorf("failed to archive WAL file: %w", err)
+	}
+	if err := os.Rename(tmpPath, dst); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to archive WAL file: %w", err)
+	}
+	return nil
+}
+
+// List returns the backups, oldest first
+func (e *Engine) List() ([]*Info, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.list()
+}
+
+// list returns the backups, oldest first. The caller must hold the lock.
+func (e *Engine) list() ([]*Info, error) {
+	paths, err := filepath.Glob(filepath.Join(e.dir, metaDirName, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	backups := make([]*Info, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup: %w", err)
+		}
+		info := &Info{}
+		if err := json.Unmarshal(data, info); err != nil {
+			return nil, fmt.Errorf("failed to decode backup %s: %w", filepath.Base(path), err)
+		}
+		backups = append(backups, info)
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].ID < backups[j].ID })
+	return backups, nil
+}
+
+// Get returns the backup with the given ID
+func (e *Engine) Get(id uint64) (*Info, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.get(id)
+}
+
+// get returns the backup with the given ID. The caller must hold the lock.
+func (e *Engine) get(id uint64) (*Info, error) {
+	data, err := os.ReadFile(filepath.Join(e.dir, metaDirName, fmt.Sprintf(metaFileFormat, id)))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %d", ErrNotFound, id)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	info := &Info{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("failed to decode backup %d: %w", id, err)
+	}
+	return info, nil
+}
+
+// writeInfo durably writes the file describing a backup
+func (e *Engine) writeInfo(info *Info) error {
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode backup: %w", err)
+	}
+
+	dir := filepath.Join(e.dir, metaDirName)
+	path := filepath.Join(dir, fmt.Sprintf(metaFileFormat, info.ID))
+	tmpPath := path + ".tmp"
+	if err := fsutil.WriteFileSync(tmpPath, data); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	return fsutil.SyncDir(dir)
+}
+
+// Verify checks that a backup can be restored: its SSTables are intact and
+// the archived WAL holds every write from its SSTables up to its sequence
+// number. All the problems found are returned.
+func (e *Engine) Verify(id uint64) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	info, err := e.get(id)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, family := range info.Families {
+		for _, file := range family.Files {
+			if err := e.verifyFile(file); err != nil {
+				errs = append(errs, fmt.Errorf("SSTable %s of %q: %w", file.Name, family.Dir, err))
+			}
+		}
+	}
+
+	last, _, err := e.scanWAL(info.walStart(), info.Sequence, info.ingested())
+	if err != nil {
+		errs = append(errs, err)
+	} else if last < info.Sequence {
+		errs = append(errs, fmt.Errorf("archived WAL stops at sequence %d, backup needs %d", last, info.Sequence))
+	}
+
+	return errors.Join(errs...)
+}
+
+// verifyFile checks a stored SSTable against its checksum and that it opens
+func (e *Engine) verifyFile(file File) error {
+	path := filepath.Join(e.dir, sstDirName, file.Stored)
+	stat, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if stat.Size() != file.Size {
+		return fmt.Errorf("size is %d, expected %d", stat.Size(), file.Size)
+	}
+
+	crc, err := fileCRC(path)
+	if err != nil {
+		return err
+	}
+	if crc != file.CRC {
+		return fmt.Errorf("checksum is %08x, expected %08x", crc, file.CRC)
+	}
+
+	reader, err := sstable.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	return reader.Close()
+}
+
+// Purge deletes all but the newest keep backups, along with the SSTables and
+// WAL files only they needed, and returns how many it deleted. Restoring to a
+// sequence number before the oldest backup kept is no longer possible.
+func (e *Engine) Purge(keep int) (int, error) {
+	if keep < 1 {
+		return 0, fmt.Errorf("at least one backup must be kept")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	backups, err := e.list()
+	if err != nil {
+		return 0, err
+	}
+	if len(backups) <= keep {
+		return 0, nil
+	}
+
+	purged := backups[:len(backups)-keep]
+	kept := backups[len(backups)-keep:]
+	for _, info := range purged {
+		path := filepath.Join(e.dir, metaDirName, fmt.Sprintf(metaFileFormat, info.ID))
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return 0, fmt.Errorf("failed to delete backup %d: %w", info.ID, err)
+		}
+	}
+	if err := fsutil.SyncDir(filepath.Join(e.dir, metaDirName)); err != nil {
+		return 0, err
+	}
+
+	// Collect the SSTables no kept backup refers to
+	referenced := make(map[string]bool)
+	for _, info := range kept {
+		for _, family := range info.Families {
+			for _, file := range family.Files {
+				referenced[file.Stored] = true
+			}
+		}
+	}
+	entries, err := os.ReadDir(filepath.Join(e.dir, sstDirName))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list stored SSTables: %w", err)
+	}
+	for _, entry := range entries {
+		if !referenced[entry.Name()] {
+			if err := os.Remove(filepath.Join(e.dir, sstDirName, entry.Name())); err != nil {
+				return 0, fmt.Errorf("failed to delete SSTable: %w", err)
+			}
+		}
+	}
+
+	// The WAL files holding only writes already in the SSTables of the oldest
+	// kept backup are no longer needed
+	start := kept[0].walStart()
+	walFiles, err := findWALFiles(filepath.Join(e.dir, walDirName))
+	if err != nil {
+		return 0, err
+	}
+	for _, path := range walFiles {
+		_, maxSeq, err := sequenceRange(path)
+		if err == nil && maxSeq <= start {
+			if err := os.Remove(path); err != nil {
+				return 0, fmt.Errorf("failed to delete WAL file: %w", err)
+			}
+		}
+	}
+
+	// Left over by backups interrupted while being created
+	if err := os.RemoveAll(filepath.Join(e.dir, tmpDirName)); err != nil {
+		return 0, fmt.Errorf("failed to clear staging directory: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Join(e.dir, tmpDirName), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	return len(purged), nil
+}
+
+// Restore writes a database to target, which must not exist yet, holding
+// every write up to untilSeq, or every archived write if untilSeq is 0. It
+// starts from the newest backup whose SSTables hold nothing past untilSeq and
+// replays the archived WAL from there, and returns the last sequence number
+// restored. The database can then be opened with engine.NewEngineFacade.
+func (e *Engine) Restore(target string, untilSeq uint64) (uint64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, err := os.Stat(target); err == nil {
+		return 0, fmt.Errorf("%w: %s", ErrTargetExists, target)
+	} else if !os.IsNotExist(err) {
+		return 0, fmt.Errorf("failed to check restore target: %w", err)
+	}
+
+	backups, err := e.list()
+	if err != nil {
+		return 0, err
+	}
+	if len(backups) == 0 {
+		return 0, ErrNoBackup
+	}
+
+	var base *Info
+	for i := len(backups) - 1; i >= 0; i-- {
+		if untilSeq == 0 || backups[i].sstEnd() <= untilSeq {
+			base = backups[i]
+			break
+		}
+	}
+	if base == nil {
+		return 0, fmt.Errorf("%w: the oldest backup is past sequence %d", ErrNoBackup, untilSeq)
+	}
+
+	last, segments, err := e.scanWAL(base.walStart(), untilSeq, base.ingested())
+	if err != nil {
+		return 0, err
+	}
+	if untilSeq != 0 && last < untilSeq {
+		return 0, fmt.Errorf("archived WAL stops at sequence %d before %d", last, untilSeq)
+	}
+	if last < base.sstEnd() {
+		return 0, fmt.Errorf("archived WAL stops at sequence %d, backup %d needs %d", last, base.ID, base.sstEnd())
+	}
+
+	// Column families are never dropped, so the newest configuration knows
+	// every family the WAL has writes for
+	if err := e.restore(target, base, backups[len(backups)-1], segments, last); err != nil {
+		os.RemoveAll(target)
+		return 0, fmt.Errorf("failed to restore backup %d: %w", base.ID, err)
+	}
+	return last, nil
+}
+
+// restore writes the SSTables of base and the WAL segments up to lastSeq to
+// target, with the configuration of latest
+func (e *Engine) restore(target string, base, latest *Info, segments []string, lastSeq uint64) error {
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(target, config.DefaultManifestFileName), latest.Config, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	cfg, err := config.LoadConfigFromManifest(target)
+	if err != nil {
+		return err
+	}
+	cfg.WALDir = filepath.Join(target, "wal")
+	cfg.SSTDir = filepath.Join(target, "sst")
+
+	// The restored database mustn't retire its WAL into the original's archive
+	cfg.WALArchiveDir = ""
+
+	for _, family := range base.Families {
+		dir := filepath.Join(cfg.SSTDir, filepath.FromSlash(family.Dir))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+
+		edit := versionlog.Edit{
+			LastSequence:   family.LastSequence,
+			LogNumber:      family.LogNumber,
+			NextFileNumber: family.NextFileNumber,
+		}
+		for _, file := range family.Files {
+			if err := fsutil.LinkFile(filepath.Join(e.dir, sstDirName, file.Stored), filepath.Join(dir, file.Name)); err != nil {
+				return fmt.Errorf("failed to restore SSTable %s: %w", file.Name, err)
+			}
+			edit.Added = append(edit.Added, file.FileMeta)
+		}
+
+		log, err := versionlog.Open(dir)
+		if err != nil {
+			return err
+		}
+		if err := log.Apply(edit); err != nil {
+			log.Close()
+			return err
+		}
+		if err := log.Close(); err != nil {
+			return err
+		}
+		if err := fsutil.SyncDir(dir); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(cfg.WALDir, 0755); err != nil {
+		return err
+	}
+	for _, path := range segments {
+		if _, err := wal.CopyFile(path, filepath.Join(cfg.WALDir, filepath.Base(path)), lastSeq); err != nil {
+			return fmt.Errorf("failed to restore WAL file: %w", err)
+		}
+	}
+	if err := fsutil.SyncDir(cfg.WALDir); err != nil {
+		return err
+	}
+
+	return cfg.SaveManifest(target)
+}
+
+// scanWAL reads the archived WAL for the writes after sequence number start,
+// up to untilSeq if it isn't 0. It returns the last sequence number reached
+// without a gap, and the WAL files holding the writes up to it. The sequence
+// numbers of ingested files don't count as gaps.
+func (e *Engine) scanWAL(start, untilSeq uint64, ingested map[uint64]bool) (uint64, []string, error) {
+	walFiles, err := findWALFiles(filepath.Join(e.dir, walDirName))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	last := start
+	skipIngested := func() {
+		for ingested[last+1] && (untilSeq == 0 || last+1 <= untilSeq) {
+			last++
+		}
+	}
+	var segments []string
+	for _, path := range walFiles {
+		reader, err := wal.OpenReader(path)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		needed, done := false, false
+		for {
+			entry, err := reader.ReadEntry()
+			if err != nil {
+				// A WAL file archived while being written to ends in a torn entry
+				break
+			}
+
+			// Entries of a batch share a sequence number
+			seqNum := entry.SequenceNumber
+			if seqNum <= last {
+				continue
+			}
+			skipIngested()
+			if seqNum != last+1 || (untilSeq != 0 && seqNum > untilSeq) {
+				done = true
+				break
+			}
+			last = seqNum
+			needed = true
+		}
+		reader.Close()
+
+		if needed {
+			segments = append(segments, path)
+		}
+		if done {
+			break
+		}
+	}
+	skipIngested()
+
+	return last, segments, nil
+}
+
+// sequenceRange returns the lowest and highest sequence numbers in a WAL file
+func sequenceRange(path string) (uint64, uint64, error) {
+	reader, err := wal.OpenReader(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer reader.Close()
+
+	var minSeq, maxSeq uint64
+	found := false
+	for {
+		entry, err := reader.ReadEntry()
+		if err != nil {
+			break
+		}
+		if !found || entry.SequenceNumber < minSeq {
+			minSeq = entry.SequenceNumber
+		}
+		maxSeq = max(maxSeq, entry.SequenceNumber)
+		found = true
+	}
+
+	if !found {
+		return 0, 0, fmt.Errorf("no entries in WAL file %s", path)
+	}
+	return minSeq, maxSeq, nil
+}
+
+// findWALFiles lists the WAL files of dir, which may be empty or missing
+func findWALFiles(dir string) ([]string, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return wal.FindWALFiles(dir)
+}
+
+// fileCRC returns the checksum of a file's contents
+func fileCRC(path string) (uint32, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	hash := crc32.NewIEEE()
+	if _, err := io.Copy(hash, file); err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hash.Sum32(), nil
+}
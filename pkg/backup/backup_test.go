@@ -0,0 +1,218 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KevoDB/kevo/pkg/engine"
+	"github.com/KevoDB/kevo/pkg/sstable"
+)
+
+func putRange(t *testing.T, eng *engine.EngineFacade, from, to int) {
+	t.Helper()
+	for i := from; i < to; i++ {
+		if err := eng.Put([]byte(fmt.Sprintf("key-%03d", i)), []byte(fmt.Sprintf("value-%03d", i))); err != nil {
+			t.Fatalf("Failed to put: %v", err)
+		}
+	}
+}
+
+// countKeys returns how many of the keys from 0 up to n a database has,
+// failing if they aren't a prefix
+func countKeys(t *testing.T, dir string, n int) int {
+	t.Helper()
+	eng, err := engine.NewEngineFacade(dir)
+	if err != nil {
+		t.Fatalf("Failed to open restored database: %v", err)
+	}
+	defer eng.Close()
+
+	count := 0
+	for i := 0; i < n; i++ {
+		value, err := eng.Get([]byte(fmt.Sprintf("key-%03d", i)))
+		if err != nil {
+			continue
+		}
+		if i != count || string(value) != fmt.Sprintf("value-%03d", i) {
+			t.Fatalf("Unexpected key-%03d = %q after %d keys", i, value, count)
+		}
+		count++
+	}
+	return count
+}
+
+func TestBackupRestore(t *testing.T) {
+	dir := t.TempDir()
+
+	eng, err := engine.NewEngineFacade(filepath.Join(dir, "db"))
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	backups, err := Open(filepath.Join(dir, "backups"))
+	if err != nil {
+		t.Fatalf("Failed to open backup directory: %v", err)
+	}
+
+	// Keys 0-99, half of them flushed
+	putRange(t, eng, 0, 50)
+	if err := eng.FlushImMemTables(); err != nil {
+		t.Fatalf("Failed to flush memtables: %v", err)
+	}
+	putRange(t, eng, 50, 100)
+	first, err := backups.Create(eng)
+	if err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+	if first.ID != 1 || first.Sequence < 100 || len(first.Families) != 1 {
+		t.Fatalf("Unexpected backup %+v", first)
+	}
+
+	// Keys 100-199, all flushed. Only the new SSTable is stored.
+	putRange(t, eng, 100, 200)
+	if err := eng.FlushImMemTables(); err != nil {
+		t.Fatalf("Failed to flush memtables: %v", err)
+	}
+	second, err := backups.Create(eng)
+	if err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+	if second.ID != 2 || second.Sequence != first.Sequence+100 {
+		t.Fatalf("Unexpected backup %+v", second)
+	}
+	stored, _ := filepath.Glob(filepath.Join(backups.Dir(), sstDirName, "*.sst"))
+	if len(stored) != len(second.Families[0].Files) {
+		t.Errorf("Expected %d stored SSTables, got %d", len(second.Families[0].Files), len(stored))
+	}
+
+	list, err := backups.List()
+	if err != nil || len(list) != 2 {
+		t.Fatalf("Expected 2 backups, got %d (%v)", len(list), err)
+	}
+	for _, info := range list {
+		if err := backups.Verify(info.ID); err != nil {
+			t.Errorf("Failed to verify backup %d: %v", info.ID, err)
+		}
+	}
+
+	// Latest restores everything archived
+	latest := filepath.Join(dir, "latest")
+	seqNum, err := backups.Restore(latest, 0)
+	if err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	if seqNum != second.Sequence {
+		t.Errorf("Expected to restore to sequence %d, got %d", second.Sequence, seqNum)
+	}
+	if n := countKeys(t, latest, 300); n != 200 {
+		t.Errorf("Expected 200 keys restored, got %d", n)
+	}
+	if _, err := backups.Restore(latest, 0); !errors.Is(err, ErrTargetExists) {
+		t.Errorf("Expected ErrTargetExists, got %v", err)
+	}
+
+	// Point in time replays the WAL on top of the first backup
+	pointInTime := filepath.Join(dir, "point-in-time")
+	seqNum, err = backups.Restore(pointInTime, first.Sequence+10)
+	if err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	if seqNum != first.Sequence+10 {
+		t.Errorf("Expected to restore to sequence %d, got %d", first.Sequence+10, seqNum)
+	}
+	if n := countKeys(t, pointInTime, 300); n != 110 {
+		t.Errorf("Expected 110 keys restored, got %d", n)
+	}
+
+	// A corrupted SSTable fails verification
+	victim := filepath.Join(backups.Dir(), sstDirName, first.Families[0].Files[0].Stored)
+	data, err := os.ReadFile(victim)
+	if err != nil {
+		t.Fatalf("Failed to read SSTable: %v", err)
+	}
+	data[0] ^= 0xff
+	if err := os.WriteFile(victim, data, 0644); err != nil {
+		t.Fatalf("Failed to write SSTable: %v", err)
+	}
+	if err := backups.Verify(first.ID); err == nil {
+		t.Error("Expected verifying a corrupted backup to fail")
+	}
+	data[0] ^= 0xff
+	if err := os.WriteFile(victim, data, 0644); err != nil {
+		t.Fatalf("Failed to write SSTable: %v", err)
+	}
+
+	// Purging the first backup drops what only it needed
+	purged, err := backups.Purge(1)
+	if err != nil || purged != 1 {
+		t.Fatalf("Expected 1 backup purged, got %d (%v)", purged, err)
+	}
+	if _, err := backups.Get(first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if err := backups.Verify(second.ID); err != nil {
+		t.Errorf("Failed to verify backup %d after purge: %v", second.ID, err)
+	}
+	if _, err := backups.Restore(filepath.Join(dir, "too-early"), first.Sequence+10); !errors.Is(err, ErrNoBackup) {
+		t.Errorf("Expected ErrNoBackup, got %v", err)
+	}
+}
+
+func TestBackupIngested(t *testing.T) {
+	dir := t.TempDir()
+
+	eng, err := engine.NewEngineFacade(filepath.Join(dir, "db"))
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	backups, err := Open(filepath.Join(dir, "backups"))
+	if err != nil {
+		t.Fatalf("Failed to open backup directory: %v", err)
+	}
+
+	// The WAL has a gap where the file was ingested
+	putRange(t, eng, 0, 10)
+	path := filepath.Join(dir, "ingest.sst")
+	writer, err := sstable.NewSSTFileWriter(path)
+	if err != nil {
+		t.Fatalf("Failed to create file writer: %v", err)
+	}
+	for i := 10; i < 20; i++ {
+		if err := writer.Put([]byte(fmt.Sprintf("key-%03d", i)), []byte(fmt.Sprintf("value-%03d", i))); err != nil {
+			t.Fatalf("Failed to put: %v", err)
+		}
+	}
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish file: %v", err)
+	}
+	if _, err := eng.IngestFiles([]string{path}); err != nil {
+		t.Fatalf("Failed to ingest: %v", err)
+	}
+	putRange(t, eng, 20, 30)
+
+	info, err := backups.Create(eng)
+	if err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+	if err := backups.Verify(info.ID); err != nil {
+		t.Errorf("Failed to verify backup: %v", err)
+	}
+
+	restored := filepath.Join(dir, "restored")
+	seqNum, err := backups.Restore(restored, 0)
+	if err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	if seqNum != info.Sequence {
+		t.Errorf("Expected to restore to sequence %d, got %d", info.Sequence, seqNum)
+	}
+	if n := countKeys(t, restored, 100); n != 30 {
+		t.Errorf("Expected 30 keys restored, got %d", n)
+	}
+}
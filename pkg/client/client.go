@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	_ "github.com/KevoDB/kevo/pkg/grpc/transport" // Registers the "grpc" transport
 	"github.com/KevoDB/kevo/pkg/transport"
 	"google.golang.org/grpc/keepalive"
 )
@@ -430,6 +431,20 @@ func (c *Client) Get(ctx context.Context, key []byte) ([]byte, bool, error) {
 // Put stores a key-value pair
 // If connected to a replica, it will automatically route the write to the primary
 func (c *Client) Put(ctx context.Context, key, value []byte, sync bool) (bool, error) {
+	return c.put(ctx, key, value, 0, sync)
+}
+
+// PutWithTTL stores a key-value pair that expires after ttl
+// If connected to a replica, it will automatically route the write to the primary
+func (c *Client) PutWithTTL(ctx context.Context, key, value []byte, ttl time.Duration, sync bool) (bool, error) {
+	if ttl.Milliseconds() <= 0 {
+		return false, errors.New("ttl must be at least one millisecond")
+	}
+	return c.put(ctx, key, value, ttl.Milliseconds(), sync)
+}
+
+// put stores a key-value pair that expires after ttlMs milliseconds, or never if ttlMs is 0
+func (c *Client) put(ctx context.Context, key, value []byte, ttlMs int64, sync bool) (bool, error) {
 	if !c.IsConnected() {
 		return false, errors.New("not connected to server")
 	}
@@ -445,10 +460,12 @@ func (c *Client) Put(ctx context.Context, key, value []byte, sync bool) (bool, e
 		Key   []byte `json:"key"`
 		Value []byte `json:"value"`
 		Sync  bool   `json:"sync"`
+		TTLMs int64  `json:"ttl_ms,omitempty"`
 	}{
 		Key:   key,
 		Value: value,
 		Sync:  sync,
+		TTLMs: ttlMs,
 	}
 
 	reqData, err := json.Marshal(req)
@@ -587,11 +604,43 @@ func (c *Client) Delete(ctx context.Context, key []byte, sync bool) (bool, error
 	return deleteResp.Success, nil
 }
 
+// DeleteRange removes every key in [startKey, endKey)
+// If connected to a replica, it will automatically route the delete to the primary
+func (c *Client) DeleteRange(ctx context.Context, startKey, endKey []byte, sync bool) (bool, error) {
+	req := struct {
+		StartKey []byte `json:"start_key"`
+		EndKey   []byte `json:"end_key"`
+		Sync     bool   `json:"sync"`
+	}{
+		StartKey: startKey,
+		EndKey:   endKey,
+		Sync:     sync,
+	}
+
+	var deleteResp struct {
+		Success bool `json:"success"`
+	}
+	if err := c.send(ctx, transport.TypeDeleteRange, req, &deleteResp, true); err != nil {
+		return false, err
+	}
+	return deleteResp.Success, nil
+}
+
 // BatchOperation represents a single operation in a batch
 type BatchOperation struct {
-	Type  string // "put" or "delete"
-	Key   []byte
-	Value []byte // only used for "put" operations
+	Type   string // "put", "delete" or "delete_range"
+	Key    []byte // start of the range for "delete_range" operations
+	Value  []byte // only used for "put" operations
+	EndKey []byte // only used for "delete_range" operations
+
+	// Precondition on Key checked before the batch is applied, if any
+	Precondition *Precondition
+}
+
+// Precondition is a condition on the current value of a key
+type Precondition struct {
+	Type  string // "equals", "absent" or "exists"
+	Value []byte // only used for "equals" preconditions
 }
 
 // BatchWrite performs multiple operations in a single atomic batch
@@ -608,27 +657,35 @@ func (c *Client) BatchWrite(ctx context.Context, operations []BatchOperation, sy
 		c.primaryConn != nil
 	c.connMutex.RUnlock()
 
+	type precondition struct {
+		Type  string `json:"type"`
+		Value []byte `json:"value,omitempty"`
+	}
+	type operation struct {
+		Type         string        `json:"type"`
+		Key          []byte        `json:"key"`
+		Value        []byte        `json:"value"`
+		EndKey       []byte        `json:"end_key,omitempty"`
+		Precondition *precondition `json:"precondition,omitempty"`
+	}
 	req := struct {
-		Operations []struct {
-			Type  string `json:"type"`
-			Key   []byte `json:"key"`
-			Value []byte `json:"value"`
-		} `json:"operations"`
-		Sync bool `json:"sync"`
+		Operations []operation `json:"operations"`
+		Sync       bool        `json:"sync"`
 	}{
 		Sync: sync,
 	}
 
 	for _, op := range operations {
-		req.Operations = append(req.Operations, struct {
-			Type  string `json:"type"`
-			Key   []byte `json:"key"`
-			Value []byte `json:"value"`
-		}{
-			Type:  op.Type,
-			Key:   op.Key,
-			Value: op.Value,
-		})
+		o := operation{
+			Type:   op.Type,
+			Key:    op.Key,
+			Value:  op.Value,
+			EndKey: op.EndKey,
+		}
+		if op.Precondition != nil {
+			o.Precondition = &precondition{Type: op.Precondition.Type, Value: op.Precondition.Value}
+		}
+		req.Operations = append(req.Operations, o)
 	}
 
 	reqData, err := json.Marshal(req)
@@ -762,6 +819,103 @@ func (c *Client) Compact(ctx context.Context, force bool) (bool, error) {
 	return compactResp.Success, nil
 }
 
+// Checkpoint writes a consistent copy of the database to dir on the server,
+// which must not exist yet. It returns the last sequence number in the copy.
+func (c *Client) Checkpoint(ctx context.Context, dir string) (uint64, error) {
+	req := struct {
+		Dir string `json:"dir"`
+	}{
+		Dir: dir,
+	}
+
+	var checkpointResp struct {
+		Success  bool   `json:"success"`
+		Sequence uint64 `json:"sequence"`
+	}
+	if err := c.send(ctx, transport.TypeCheckpoint, req, &checkpointResp, false); err != nil {
+		return 0, err
+	}
+	if !checkpointResp.Success {
+		return 0, errors.New("checkpoint failed")
+	}
+	return checkpointResp.Sequence, nil
+}
+
+// IngestSSTables adds SSTable files on the server to a column family, or to
+// the default one if columnFamily is empty. It returns the sequence number
+// given to the ingested entries.
+func (c *Client) IngestSSTables(ctx context.Context, paths []string, columnFamily string) (uint64, error) {
+	req := struct {
+		Paths        []string `json:"paths"`
+		ColumnFamily string   `json:"column_family,omitempty"`
+	}{
+		Paths:        paths,
+		ColumnFamily: columnFamily,
+	}
+
+	var ingestResp struct {
+		Success  bool   `json:"success"`
+		Sequence uint64 `json:"sequence"`
+	}
+	if err := c.send(ctx, transport.TypeIngestSSTables, req, &ingestResp, true); err != nil {
+		return 0, err
+	}
+	if !ingestResp.Success {
+		return 0, errors.New("ingestion failed")
+	}
+	return ingestResp.Sequence, nil
+}
+
+// send sends a request and unmarshals its response into resp. Writes are
+// routed to the primary when connected to a replica.
+func (c *Client) send(ctx context.Context, requestType string, req, resp any, write bool) error {
+	if !c.IsConnected() {
+		return errors.New("not connected to server")
+	}
+
+	reqData, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.options.RequestTimeout)
+	defer cancel()
+
+	primaryConn := func() transport.Client {
+		c.connMutex.RLock()
+		defer c.connMutex.RUnlock()
+		if write && c.nodeInfo != nil && c.nodeInfo.Role == "replica" {
+			return c.primaryConn
+		}
+		return nil
+	}
+
+	var response transport.Response
+	var sendErr error
+	if conn := primaryConn(); conn != nil {
+		response, sendErr = conn.Send(timeoutCtx, transport.NewRequest(requestType, reqData))
+	} else {
+		response, sendErr = c.client.Send(timeoutCtx, transport.NewRequest(requestType, reqData))
+
+		// A replica rejects writes, retry on the primary once it's known
+		if write && sendErr != nil && c.nodeInfo == nil {
+			if discoverErr := c.discoverTopology(ctx); discoverErr == nil {
+				if conn := primaryConn(); conn != nil {
+					response, sendErr = conn.Send(timeoutCtx, transport.NewRequest(requestType, reqData))
+				}
+			}
+		}
+	}
+	if sendErr != nil {
+		return fmt.Errorf("failed to send request: %w", sendErr)
+	}
+
+	if err := json.Unmarshal(response.Payload(), resp); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
 // Stats contains database statistics
 type Stats struct {
 	KeyCount           int64
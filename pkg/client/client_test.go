@@ -384,6 +384,99 @@ func TestClientCompact(t *testing.T) {
 	}
 }
 
+func TestClientDeleteRange(t *testing.T) {
+	options := DefaultClientOptions()
+	options.TransportType = "mock"
+
+	client, err := NewClient(options)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	mock := client.client.(*mockClient)
+	mock.connected = true
+
+	ctx := context.Background()
+
+	mock.setResponse(transport.TypeDeleteRange, []byte(`{"success": true}`))
+	success, err := client.DeleteRange(ctx, []byte("a"), []byte("b"), false)
+	if err != nil || !success {
+		t.Errorf("Expected a successful delete range, got %v, %v", success, err)
+	}
+
+	mock.setError(transport.TypeDeleteRange, errors.New("delete range error"))
+	if _, err := client.DeleteRange(ctx, []byte("a"), []byte("b"), false); err == nil {
+		t.Error("Expected delete range error, got nil")
+	}
+}
+
+func TestClientConditionalWrites(t *testing.T) {
+	options := DefaultClientOptions()
+	options.TransportType = "mock"
+
+	client, err := NewClient(options)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	mock := client.client.(*mockClient)
+	mock.connected = true
+
+	ctx := context.Background()
+
+	// A failed compare-and-swap returns the current value
+	mock.setResponse(transport.TypeCompareAndSwap, []byte(`{"current_value": "MQ==", "found": true}`))
+	result, err := client.CompareAndSwap(ctx, []byte("key"), []byte("0"), []byte("2"), false)
+	if err != nil {
+		t.Fatalf("Expected a compare-and-swap result, got error: %v", err)
+	}
+	if result.Succeeded || !result.Found || string(result.CurrentValue) != "1" {
+		t.Errorf("Expected a failed compare-and-swap with value 1, got %+v", result)
+	}
+
+	mock.setResponse(transport.TypePutIfAbsent, []byte(`{"succeeded": true, "current_value": "MQ==", "found": true}`))
+	if result, err := client.PutIfAbsent(ctx, []byte("key"), []byte("1"), false); err != nil || !result.Succeeded {
+		t.Errorf("Expected a successful put-if-absent, got %+v, %v", result, err)
+	}
+
+	mock.setResponse(transport.TypeDeleteIfEquals, []byte(`{"succeeded": true}`))
+	if result, err := client.DeleteIfEquals(ctx, []byte("key"), []byte("1"), false); err != nil || !result.Succeeded || result.Found {
+		t.Errorf("Expected a successful delete-if-equals, got %+v, %v", result, err)
+	}
+
+	mock.setError(transport.TypeDeleteIfEquals, errors.New("conditional write error"))
+	if _, err := client.DeleteIfEquals(ctx, []byte("key"), []byte("1"), false); err == nil {
+		t.Error("Expected conditional write error, got nil")
+	}
+}
+
+func TestClientCheckpointAndIngest(t *testing.T) {
+	options := DefaultClientOptions()
+	options.TransportType = "mock"
+
+	client, err := NewClient(options)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	mock := client.client.(*mockClient)
+	mock.connected = true
+
+	ctx := context.Background()
+
+	mock.setResponse(transport.TypeCheckpoint, []byte(`{"success": true, "sequence": 42}`))
+	if seqNum, err := client.Checkpoint(ctx, "/backups/checkpoint"); err != nil || seqNum != 42 {
+		t.Errorf("Expected a checkpoint at sequence 42, got %d, %v", seqNum, err)
+	}
+
+	mock.setResponse(transport.TypeIngestSSTables, []byte(`{"success": true, "sequence": 43}`))
+	if seqNum, err := client.IngestSSTables(ctx, []string{"/data/load.sst"}, ""); err != nil || seqNum != 43 {
+		t.Errorf("Expected ingestion at sequence 43, got %d, %v", seqNum, err)
+	}
+
+	mock.setResponse(transport.TypeIngestSSTables, []byte(`{"success": false}`))
+	if _, err := client.IngestSSTables(ctx, []string{"/data/load.sst"}, ""); err == nil {
+		t.Error("Expected an error for failed ingestion, got nil")
+	}
+}
+
 func TestClientPutDeletePutSequence(t *testing.T) {
 	// Create a client with the mock transport
 	options := DefaultClientOptions()
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+
+	"github.com/KevoDB/kevo/pkg/transport"
+)
+
+// ConditionalWriteResult is the outcome of a conditional write
+type ConditionalWriteResult struct {
+	// Succeeded is true if the condition held and the write was applied
+	Succeeded bool
+
+	// CurrentValue and Found describe the key after the call, so a failed
+	// compare-and-swap returns the value to retry with
+	CurrentValue []byte
+	Found        bool
+}
+
+// conditionalWriteResponse is the response to every conditional write
+type conditionalWriteResponse struct {
+	Succeeded    bool   `json:"succeeded"`
+	CurrentValue []byte `json:"current_value"`
+	Found        bool   `json:"found"`
+}
+
+// CompareAndSwap sets key to newValue if its current value is expectedValue
+// If connected to a replica, it will automatically route the write to the primary
+func (c *Client) CompareAndSwap(ctx context.Context, key, expectedValue, newValue []byte, sync bool) (*ConditionalWriteResult, error) {
+	req := struct {
+		Key           []byte `json:"key"`
+		ExpectedValue []byte `json:"expected_value"`
+		NewValue      []byte `json:"new_value"`
+		Sync          bool   `json:"sync"`
+	}{
+		Key:           key,
+		ExpectedValue: expectedValue,
+		NewValue:      newValue,
+		Sync:          sync,
+	}
+	return c.conditionalWrite(ctx, transport.TypeCompareAndSwap, req)
+}
+
+// PutIfAbsent stores a key-value pair if the key doesn't exist
+// If connected to a replica, it will automatically route the write to the primary
+func (c *Client) PutIfAbsent(ctx context.Context, key, value []byte, sync bool) (*ConditionalWriteResult, error) {
+	req := struct {
+		Key   []byte `json:"key"`
+		Value []byte `json:"value"`
+		Sync  bool   `json:"sync"`
+	}{
+		Key:   key,
+		Value: value,
+		Sync:  sync,
+	}
+	return c.conditionalWrite(ctx, transport.TypePutIfAbsent, req)
+}
+
+// DeleteIfEquals removes a key if its current value is expectedValue
+// If connected to a replica, it will automatically route the delete to the primary
+func (c *Client) DeleteIfEquals(ctx context.Context, key, expectedValue []byte, sync bool) (*ConditionalWriteResult, error) {
+	req := struct {
+		Key           []byte `json:"key"`
+		ExpectedValue []byte `json:"expected_value"`
+		Sync          bool   `json:"sync"`
+	}{
+		Key:           key,
+		ExpectedValue: expectedValue,
+		Sync:          sync,
+	}
+	return c.conditionalWrite(ctx, transport.TypeDeleteIfEquals, req)
+}
+
+// conditionalWrite sends a conditional write and returns its outcome
+func (c *Client) conditionalWrite(ctx context.Context, requestType string, req any) (*ConditionalWriteResult, error) {
+	var resp conditionalWriteResponse
+	if err := c.send(ctx, requestType, req, &resp, true); err != nil {
+		return nil, err
+	}
+	return &ConditionalWriteResult{
+		Succeeded:    resp.Succeeded,
+		CurrentValue: resp.CurrentValue,
+		Found:        resp.Found,
+	}, nil
+}
@@ -22,6 +22,8 @@ type ScanOptions struct {
 	EndKey []byte
 	// Limit sets the maximum number of key-value pairs to return
 	Limit int32
+	// Reverse returns keys from the last to the first
+	Reverse bool
 }
 
 // KeyValue represents a key-value pair from a scan
@@ -80,12 +82,14 @@ func (c *Client) Scan(ctx context.Context, options ScanOptions) (Scanner, error)
 		StartKey []byte `json:"start_key"`
 		EndKey   []byte `json:"end_key"`
 		Limit    int32  `json:"limit"`
+		Reverse  bool   `json:"reverse"`
 	}{
 		Prefix:   options.Prefix,
 		Suffix:   options.Suffix,
 		StartKey: options.StartKey,
 		EndKey:   options.EndKey,
 		Limit:    options.Limit,
+		Reverse:  options.Reverse,
 	}
 
 	reqData, err := json.Marshal(req)
@@ -214,6 +218,7 @@ func (tx *Transaction) Scan(ctx context.Context, options ScanOptions) (Scanner,
 		StartKey      []byte `json:"start_key"`
 		EndKey        []byte `json:"end_key"`
 		Limit         int32  `json:"limit"`
+		Reverse       bool   `json:"reverse"`
 	}{
 		TransactionID: tx.id,
 		Prefix:        options.Prefix,
@@ -221,6 +226,7 @@ func (tx *Transaction) Scan(ctx context.Context, options ScanOptions) (Scanner,
 		StartKey:      options.StartKey,
 		EndKey:        options.EndKey,
 		Limit:         options.Limit,
+		Reverse:       options.Reverse,
 	}
 
 	reqData, err := json.Marshal(req)
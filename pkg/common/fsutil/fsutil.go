@@ -0,0 +1,100 @@
+// Package fsutil creates, links and moves files durably.
+package fsutil
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// CopyFile copies src to a new file at dst and syncs it. A failed copy leaves
+// nothing at dst.
+func CopyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return nil
+}
+
+// LinkFile hard links src to dst, or copies it if they are on different file
+// systems
+func LinkFile(src, dst string) error {
+	err := os.Link(src, dst)
+	if err == nil || os.IsNotExist(err) {
+		return err
+	}
+	return CopyFile(src, dst)
+}
+
+// MoveFile renames src to dst, or copies it and removes src if they are on
+// different file systems. The copy is renamed into place once it's complete,
+// so dst never holds part of the file.
+func MoveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	// A copy left over from an earlier attempt is replaced
+	tmpPath := dst + ".tmp"
+	os.Remove(tmpPath)
+	if err := CopyFile(src, tmpPath); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, dst); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Remove(src)
+}
+
+// WriteFileSync writes data to a file, replacing its contents, and syncs it
+func WriteFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to sync %s: %w", path, err)
+	}
+	return file.Close()
+}
+
+// SyncDir syncs a directory, making the files created or renamed in it durable
+func SyncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open directory %s: %w", dir, err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("failed to sync directory %s: %w", dir, err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLinkAndCopyFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	if err := WriteFileSync(src, []byte("data")); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	for name, fn := range map[string]func(src, dst string) error{"link": LinkFile, "copy": CopyFile} {
+		dst := filepath.Join(dir, name)
+		if err := fn(src, dst); err != nil {
+			t.Fatalf("Failed to %s file: %v", name, err)
+		}
+		if data, err := os.ReadFile(dst); err != nil || string(data) != "data" {
+			t.Errorf("Expected the %s to hold the data, got %q, %v", name, data, err)
+		}
+
+		// Existing files are never overwritten
+		if err := fn(src, dst); !os.IsExist(err) {
+			t.Errorf("Expected an error for an existing %s, got %v", name, err)
+		}
+	}
+
+	// A missing source leaves nothing behind
+	missing := filepath.Join(dir, "missing-copy")
+	if err := CopyFile(filepath.Join(dir, "missing"), missing); !os.IsNotExist(err) {
+		t.Errorf("Expected a not-exist error, got %v", err)
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("Expected no file at %s, got %v", missing, err)
+	}
+
+	if err := SyncDir(dir); err != nil {
+		t.Errorf("Failed to sync directory: %v", err)
+	}
+}
+
+func TestMoveFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	if err := WriteFileSync(src, []byte("data")); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if err := MoveFile(src, dst); err != nil {
+		t.Fatalf("Failed to move file: %v", err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("Expected the source to be gone, got %v", err)
+	}
+	if data, err := os.ReadFile(dst); err != nil || string(data) != "data" {
+		t.Errorf("Expected the moved file to hold the data, got %q, %v", data, err)
+	}
+}
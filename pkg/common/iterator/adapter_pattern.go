@@ -50,6 +50,10 @@ package iterator
 //     return a.source.Next()
 // }
 //
+// func (a *ExampleAdapter) Prev() bool {
+//     return a.source.Prev()
+// }
+//
 // func (a *ExampleAdapter) Key() []byte {
 //     if !a.Valid() {
 //         return nil
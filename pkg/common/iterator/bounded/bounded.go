@@ -71,32 +71,8 @@ func (b *BoundedIterator) SeekToFirst() {
 // SeekToLast positions at the last key in the bounded range
 func (b *BoundedIterator) SeekToLast() {
 	if b.end != nil {
-		// If we have an end bound, seek to it
-		// The current implementation might not be efficient for finding the last
-		// key before the end bound, but it works for now
-		b.Iterator.Seek(b.end)
-
-		// If we landed exactly at the end bound, back up one
-		if b.Iterator.Valid() && bytes.Equal(b.Iterator.Key(), b.end) {
-			// We need to back up because end is exclusive
-			// This is inefficient but correct
-			b.Iterator.SeekToFirst()
-
-			// Scan to find the last key before the end bound
-			var lastKey []byte
-			for b.Iterator.Valid() && bytes.Compare(b.Iterator.Key(), b.end) < 0 {
-				lastKey = b.Iterator.Key()
-				b.Iterator.Next()
-			}
-
-			if lastKey != nil {
-				b.Iterator.Seek(lastKey)
-			} else {
-				// No keys before the end bound
-				b.Iterator.SeekToFirst()
-				// This will be marked invalid by checkBounds
-			}
-		}
+		// The end bound is exclusive, so position at the last key before it
+		iterator.SeekBefore(b.Iterator, b.end)
 	} else {
 		// No end bound, seek to the last key
 		b.Iterator.SeekToLast()
@@ -140,6 +116,22 @@ func (b *BoundedIterator) Next() bool {
 	return b.checkBounds()
 }
 
+// Prev moves back to the previous key within bounds
+func (b *BoundedIterator) Prev() bool {
+	// First check if we're already before the start boundary
+	if !b.checkBounds() {
+		return false
+	}
+
+	// Then try to move back
+	if !b.Iterator.Prev() {
+		return false
+	}
+
+	// Check if the new position is within bounds
+	return b.checkBounds()
+}
+
 // Valid returns true if the iterator is positioned at a valid entry within bounds
 func (b *BoundedIterator) Valid() bool {
 	return b.Iterator.Valid() && b.checkBounds()
@@ -70,6 +70,15 @@ func (m *mockIterator) Next() bool {
 	return false
 }
 
+func (m *mockIterator) Prev() bool {
+	if m.index > 0 {
+		m.index--
+		return true
+	}
+	m.index = -1
+	return false
+}
+
 func (m *mockIterator) Key() []byte {
 	if m.index >= 0 && m.index < len(m.keys) {
 		return []byte(m.keys[m.index])
@@ -300,3 +309,41 @@ func TestBoundedIterator_SetBounds(t *testing.T) {
 		t.Errorf("Expected key 'd', got '%s'", string(boundedIter.Key()))
 	}
 }
+
+func TestBoundedIterator_Prev(t *testing.T) {
+	// Create a mock iterator with some data
+	mockIter := newMockIterator(map[string]string{
+		"a": "1",
+		"b": "2",
+		"c": "3",
+		"d": "4",
+		"e": "5",
+	})
+
+	// Create bounded iterator with bounds b to d (inclusive b, exclusive d)
+	boundedIter := NewBoundedIterator(mockIter, []byte("b"), []byte("d"))
+
+	// SeekToLast lands on the last key before the end bound
+	boundedIter.SeekToLast()
+	if !boundedIter.Valid() || string(boundedIter.Key()) != "c" {
+		t.Fatalf("Expected SeekToLast to land on 'c', got '%s'", string(boundedIter.Key()))
+	}
+
+	// Prev stops at the start bound
+	if !boundedIter.Prev() || string(boundedIter.Key()) != "b" {
+		t.Errorf("Expected Prev to land on 'b', got '%s'", string(boundedIter.Key()))
+	}
+	if boundedIter.Prev() {
+		t.Errorf("Expected Prev to stop at the start bound, got '%s'", string(boundedIter.Key()))
+	}
+	if boundedIter.Valid() {
+		t.Error("Expected iterator to be invalid after moving before the start bound")
+	}
+
+	// An end bound past every key behaves like no end bound
+	boundedIter.SetBounds([]byte("b"), []byte("z"))
+	boundedIter.SeekToLast()
+	if !boundedIter.Valid() || string(boundedIter.Key()) != "e" {
+		t.Errorf("Expected SeekToLast to land on 'e', got '%s'", string(boundedIter.Key()))
+	}
+}
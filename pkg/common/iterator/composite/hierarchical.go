@@ -164,6 +164,19 @@ func (h *HierarchicalIterator) Next() bool {
 	return h.findNextUniqueKey(currentKey)
 }
 
+// Prev moves the iterator back to the previous key
+func (h *HierarchicalIterator) Prev() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.valid {
+		return false
+	}
+
+	// Find the previous unique key before the current key
+	return h.findPrevUniqueKey(h.key)
+}
+
 // Key returns the current key
 func (h *HierarchicalIterator) Key() []byte {
 	h.mu.RLock()
@@ -288,3 +301,47 @@ func (h *HierarchicalIterator) findNextUniqueKey(prevKey []byte) bool {
 
 	return false
 }
+
+// findPrevUniqueKey finds the largest key before nextKey and positions the
+// sources that contain it at its newest version
+// Returns true if a valid key was found
+func (h *HierarchicalIterator) findPrevUniqueKey(nextKey []byte) bool {
+	var bestKey []byte
+	h.valid = false
+
+	// First pass: move all iterators before nextKey and find the largest key
+	for _, iter := range h.iterators {
+		if !iterator.SeekBefore(iter, nextKey) {
+			continue
+		}
+
+		if bestKey == nil || bytes.Compare(iter.Key(), bestKey) > 0 {
+			bestKey = append(bestKey[:0], iter.Key()...)
+		}
+	}
+
+	if bestKey == nil {
+		return false
+	}
+
+	// Second pass: sources may have landed on an older version of the key, so
+	// seek them back to its newest version and take the value from the newest source
+	var bestValue []byte
+	found := false
+	for _, iter := range h.iterators {
+		if !iter.Valid() || !bytes.Equal(iter.Key(), bestKey) {
+			continue
+		}
+
+		iter.Seek(bestKey)
+		if !found {
+			bestValue = iter.Value()
+			found = true
+		}
+	}
+
+	h.key = bestKey
+	h.value = bestValue
+	h.valid = true
+	return true
+}
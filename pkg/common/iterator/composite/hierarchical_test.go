@@ -88,6 +88,15 @@ func (m *mockIterator) Next() bool {
 	return false
 }
 
+func (m *mockIterator) Prev() bool {
+	if m.index > 0 {
+		m.index--
+		return true
+	}
+	m.index = -1
+	return false
+}
+
 func (m *mockIterator) Key() []byte {
 	if m.index >= 0 && m.index < len(m.pairs) {
 		return m.pairs[m.index].key
@@ -330,3 +339,56 @@ func TestHierarchicalIterator_CompositeInterface(t *testing.T) {
 		t.Error("Source iterators don't match the original iterators")
 	}
 }
+
+func TestHierarchicalIterator_Prev(t *testing.T) {
+	// Create mock iterators with an overlapping key
+	iter1 := newMockIterator(map[string]string{
+		"a": "v1a",
+		"c": "v1c",
+		"e": "v1e",
+	}, "")
+
+	iter2 := newMockIterator(map[string]string{
+		"b": "v2b",
+		"c": "v2c",
+		"f": "v2f",
+	}, "")
+
+	// Create hierarchical iterator with iter1 being newer than iter2
+	hierIter := NewHierarchicalIterator([]iterator.Iterator{iter1, iter2})
+
+	// Iterating backwards visits each key once with the newest value
+	expected := []struct{ key, value string }{
+		{"f", "v2f"},
+		{"e", "v1e"},
+		{"c", "v1c"},
+		{"b", "v2b"},
+		{"a", "v1a"},
+	}
+
+	i := 0
+	for hierIter.SeekToLast(); hierIter.Valid(); hierIter.Prev() {
+		if i >= len(expected) {
+			t.Fatalf("Unexpected extra key '%s'", string(hierIter.Key()))
+		}
+		if string(hierIter.Key()) != expected[i].key || string(hierIter.Value()) != expected[i].value {
+			t.Errorf("Position %d: expected %s=%s, got %s=%s",
+				i, expected[i].key, expected[i].value, hierIter.Key(), hierIter.Value())
+		}
+		i++
+	}
+	if i != len(expected) {
+		t.Errorf("Expected %d keys, got %d", len(expected), i)
+	}
+
+	// Changing direction returns to the previous key
+	if !hierIter.Seek([]byte("d")) || string(hierIter.Key()) != "e" {
+		t.Fatalf("Expected Seek(d) to land on 'e', got '%s'", string(hierIter.Key()))
+	}
+	if !hierIter.Prev() || string(hierIter.Key()) != "c" {
+		t.Errorf("Expected Prev to land on 'c', got '%s'", string(hierIter.Key()))
+	}
+	if !hierIter.Next() || string(hierIter.Key()) != "e" {
+		t.Errorf("Expected Next to land on 'e', got '%s'", string(hierIter.Key()))
+	}
+}
@@ -34,6 +34,16 @@ func (fi *FilteredIterator) Next() bool {
 	return false
 }
 
+// Prev moves back to the previous key that passes the filter
+func (fi *FilteredIterator) Prev() bool {
+	for fi.iter.Prev() {
+		if fi.keyFilter(fi.iter.Key()) {
+			return true
+		}
+	}
+	return false
+}
+
 // Key returns the current key
 func (fi *FilteredIterator) Key() []byte {
 	return fi.iter.Key()
@@ -66,34 +76,11 @@ func (fi *FilteredIterator) SeekToFirst() {
 
 // SeekToLast positions at the last key that passes the filter
 func (fi *FilteredIterator) SeekToLast() {
-	// This is a simplistic implementation that may not be efficient
-	// For a production-quality implementation, we might want a more
-	// sophisticated approach
 	fi.iter.SeekToLast()
 
-	// If we're at a valid position but it doesn't pass the filter,
-	// we need to find the last key that does
+	// Move back to the last key that passes the filter
 	if fi.iter.Valid() && !fi.keyFilter(fi.iter.Key()) {
-		// Inefficient but correct - scan from beginning to find last valid key
-		var lastValidKey []byte
-		fi.iter.SeekToFirst()
-
-		for fi.iter.Valid() {
-			if fi.keyFilter(fi.iter.Key()) {
-				lastValidKey = make([]byte, len(fi.iter.Key()))
-				copy(lastValidKey, fi.iter.Key())
-			}
-			fi.iter.Next()
-		}
-
-		// If we found a valid key, seek to it
-		if lastValidKey != nil {
-			fi.iter.Seek(lastValidKey)
-		} else {
-			// No valid keys found
-			fi.iter.SeekToFirst()
-			// This will be invalid after the filter is applied
-		}
+		fi.Prev()
 	}
 }
 
@@ -68,6 +68,16 @@ func (mi *MockIterator) Next() bool {
 	return false
 }
 
+// Prev moves to the previous entry
+func (mi *MockIterator) Prev() bool {
+	if mi.currentIdx > 0 {
+		mi.currentIdx--
+		return true
+	}
+	mi.currentIdx = -1
+	return false
+}
+
 // Key returns the current key
 func (mi *MockIterator) Key() []byte {
 	if mi.Valid() {
@@ -245,3 +255,28 @@ func TestSuffixIterator(t *testing.T) {
 		t.Errorf("Expected key 'key3_suffix', got '%s'", string(suffixIter.Key()))
 	}
 }
+
+func TestPrefixIterator_Prev(t *testing.T) {
+	entries := []MockEntry{
+		{Key: []byte("apple1"), Value: []byte("val1")},
+		{Key: []byte("apple3"), Value: []byte("val3")},
+		{Key: []byte("banana2"), Value: []byte("val2")},
+		{Key: []byte("cherry4"), Value: []byte("val4")},
+	}
+
+	baseIter := NewMockIterator(entries)
+	prefixIter := NewPrefixIterator(baseIter, []byte("apple"))
+
+	// SeekToLast skips the trailing keys without the prefix
+	prefixIter.SeekToLast()
+	if !prefixIter.Valid() || string(prefixIter.Key()) != "apple3" {
+		t.Fatalf("Expected SeekToLast to land on 'apple3', got '%s'", string(prefixIter.Key()))
+	}
+
+	if !prefixIter.Prev() || string(prefixIter.Key()) != "apple1" {
+		t.Errorf("Expected Prev to land on 'apple1', got '%s'", string(prefixIter.Key()))
+	}
+	if prefixIter.Prev() {
+		t.Errorf("Expected Prev to fail before the first key, got '%s'", string(prefixIter.Key()))
+	}
+}
@@ -16,6 +16,9 @@ type Iterator interface {
 	// Next advances the iterator to the next key
 	Next() bool
 
+	// Prev moves the iterator back to the previous key
+	Prev() bool
+
 	// Key returns the current key
 	Key() []byte
 
@@ -29,3 +32,14 @@ type Iterator interface {
 	// This is used during compaction to distinguish between a regular nil value and a tombstone
 	IsTombstone() bool
 }
+
+// SeekBefore positions iter at the last key < target, returning false if there
+// is none. Iterators that yield several versions of a key land on the oldest
+// version, so callers that want the newest one seek back to the key.
+func SeekBefore(iter Iterator, target []byte) bool {
+	if iter.Seek(target) {
+		return iter.Prev()
+	}
+	iter.SeekToLast()
+	return iter.Valid()
+}
@@ -0,0 +1,231 @@
+// Package merge defines merge operators and how merge operands are combined
+// with the other versions of a key.
+package merge
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/KevoDB/kevo/pkg/common/snapshot"
+	"github.com/KevoDB/kevo/pkg/common/ttl"
+)
+
+// ErrNoOperator is returned when merge operands are written or read without a merge operator
+var ErrNoOperator = errors.New("no merge operator configured")
+
+// Operator combines the operands written with Merge into a value. Operands are
+// stored as-is and only combined when the key is read or compacted.
+type Operator interface {
+	// Name identifies the operator
+	Name() string
+
+	// FullMerge applies operands, ordered oldest first, to the existing value of a
+	// key. existing is nil if the key has no value or was deleted.
+	FullMerge(key, existing []byte, operands [][]byte) ([]byte, error)
+
+	// PartialMerge combines operands, ordered oldest first, into a single operand
+	// without knowing the existing value. It returns false if that isn't possible.
+	PartialMerge(key []byte, operands [][]byte) ([]byte, bool)
+}
+
+// Kind identifies what a version of a key holds
+type Kind uint8
+
+const (
+	// KindValue is a regular value
+	KindValue Kind = iota
+
+	// KindDeletion is a deletion marker
+	KindDeletion
+
+	// KindOperand is a merge operand
+	KindOperand
+)
+
+// Version is a single version of a key
+type Version struct {
+	SeqNum   uint64
+	Kind     Kind
+	Value    []byte
+	ExpireAt uint64 // Expiry time of a value in Unix nanoseconds, 0 if it never expires
+}
+
+// SortVersions orders versions gathered from several sources newest first.
+// The writes of a batch share a sequence number and keep the order their
+// source gives them, newest first.
+func SortVersions(versions []Version) []Version {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].SeqNum > versions[j].SeqNum
+	})
+	return versions
+}
+
+// DropDuplicates removes the versions in versions[from:], read from a single
+// source, whose sequence number was already read from another source. A write
+// can be seen in more than one source while it is being flushed. The writes of
+// a batch share a sequence number but are read from the same source, so they
+// are all kept.
+func DropDuplicates(versions []Version, from int) []Version {
+	if from == 0 {
+		return versions
+	}
+
+	result := versions[:from]
+	for _, v := range versions[from:] {
+		seen := false
+		for _, prev := range versions[:from] {
+			if prev.SeqNum == v.SeqNum {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// InsertDeletion adds a deletion written at seqNum to versions ordered newest
+// first. Range deletions are folded into the versions of a key this way. The
+// deletion hides versions older than seqNum; versions written by the same batch
+// stay visible.
+func InsertDeletion(versions []Version, seqNum uint64) []Version {
+	i := sort.Search(len(versions), func(i int) bool {
+		return versions[i].SeqNum < seqNum
+	})
+
+	versions = append(versions, Version{})
+	copy(versions[i+1:], versions[i:])
+	versions[i] = Version{SeqNum: seqNum, Kind: KindDeletion}
+	return versions
+}
+
+// Expire replaces the values in versions, ordered newest first, that have
+// expired at now with deletions. Merge operands written on top of an expiring
+// value expire with it, so they are dropped too.
+func Expire(versions []Version, now time.Time) []Version {
+	result := versions[:0]
+	operands := 0
+	for _, v := range versions {
+		switch {
+		case v.Kind == KindOperand:
+			operands++
+		case v.Kind == KindValue && ttl.Expired(v.ExpireAt, now):
+			result = result[:len(result)-operands]
+			v = Version{SeqNum: v.SeqNum, Kind: KindDeletion}
+			operands = 0
+		default:
+			operands = 0
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+// Resolve computes the value of a key from its versions, ordered newest first.
+// Versions older than the first value or deletion are ignored. It returns false
+// if the key doesn't exist.
+func Resolve(op Operator, key []byte, versions []Version) ([]byte, bool, error) {
+	// Collect operands until the version they apply to
+	i := 0
+	for i < len(versions) && versions[i].Kind == KindOperand {
+		i++
+	}
+
+	var existing []byte
+	hasValue := i < len(versions) && versions[i].Kind == KindValue
+	if hasValue {
+		existing = versions[i].Value
+	}
+
+	if i == 0 {
+		return existing, hasValue, nil
+	}
+
+	if op == nil {
+		return nil, false, ErrNoOperator
+	}
+
+	value, err := op.FullMerge(key, existing, oldestFirst(versions[:i]))
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Collapse reduces the versions of a key, ordered newest first, to the ones reads
+// can still observe. Versions are grouped by the live snapshots (sorted ascending)
+// that can see them. Within a group only the newest version is kept, with merge
+// operands folded into it when the operator allows.
+func Collapse(op Operator, key []byte, versions []Version, live []uint64) []Version {
+	var result []Version
+
+	for start := 0; start < len(versions); {
+		// A new group starts wherever a snapshot sits between two versions
+		end := start + 1
+		for end < len(versions) && !snapshot.IsRequired(live, versions[end].SeqNum, versions[end-1].SeqNum) {
+			end++
+		}
+
+		result = append(result, collapseGroup(op, key, versions[start:end])...)
+		start = end
+	}
+
+	return result
+}
+
+// collapseGroup reduces versions visible to the same snapshots
+func collapseGroup(op Operator, key []byte, group []Version) []Version {
+	// Operands newest first, up to the value or deletion they apply to
+	i := 0
+	for i < len(group) && group[i].Kind == KindOperand {
+		i++
+	}
+
+	// The newest version hides everything older
+	if i == 0 {
+		return group[:1]
+	}
+
+	if i < len(group) {
+		// Anything older than the base is hidden by it
+		if op == nil {
+			return group[:i+1]
+		}
+
+		var existing []byte
+		var expireAt uint64
+		if group[i].Kind == KindValue {
+			existing = group[i].Value
+			expireAt = group[i].ExpireAt
+		}
+
+		value, err := op.FullMerge(key, existing, oldestFirst(group[:i]))
+		if err != nil {
+			// Keep the operands so reads report the error
+			return group[:i+1]
+		}
+		// The merged value expires along with the value it was merged into
+		return []Version{{SeqNum: group[0].SeqNum, Kind: KindValue, Value: value, ExpireAt: expireAt}}
+	}
+
+	// Only operands, combine them if the operator allows
+	if op == nil || len(group) == 1 {
+		return group
+	}
+	if operand, ok := op.PartialMerge(key, oldestFirst(group)); ok {
+		return []Version{{SeqNum: group[0].SeqNum, Kind: KindOperand, Value: operand}}
+	}
+	return group
+}
+
+// oldestFirst returns the values of the versions in reverse order
+func oldestFirst(versions []Version) [][]byte {
+	operands := make([][]byte, len(versions))
+	for i, v := range versions {
+		operands[len(versions)-1-i] = v.Value
+	}
+	return operands
+}
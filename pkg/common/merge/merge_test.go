@@ -0,0 +1,257 @@
+package merge
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResolve(t *testing.T) {
+	op := CounterOperator{}
+	key := []byte("counter")
+
+	tests := []struct {
+		name     string
+		versions []Version
+		value    string
+		found    bool
+	}{
+		{"no versions", nil, "", false},
+		{"value", []Version{{SeqNum: 1, Kind: KindValue, Value: []byte("5")}}, "5", true},
+		{"deletion", []Version{{SeqNum: 1, Kind: KindDeletion}}, "", false},
+		{"operands only", []Version{
+			{SeqNum: 2, Kind: KindOperand, Value: []byte("3")},
+			{SeqNum: 1, Kind: KindOperand, Value: []byte("4")},
+		}, "7", true},
+		{"operands on a value", []Version{
+			{SeqNum: 3, Kind: KindOperand, Value: []byte("1")},
+			{SeqNum: 2, Kind: KindValue, Value: []byte("10")},
+			{SeqNum: 1, Kind: KindOperand, Value: []byte("100")},
+		}, "11", true},
+		{"operands on a deletion", []Version{
+			{SeqNum: 3, Kind: KindOperand, Value: []byte("2")},
+			{SeqNum: 2, Kind: KindDeletion},
+			{SeqNum: 1, Kind: KindValue, Value: []byte("10")},
+		}, "2", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, found, err := Resolve(op, key, tt.versions)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if found != tt.found || string(value) != tt.value {
+				t.Errorf("Expected (%q, %v), got (%q, %v)", tt.value, tt.found, value, found)
+			}
+		})
+	}
+}
+
+func TestSortVersions(t *testing.T) {
+	// Two sources, the second holding a batch with two operands for the key
+	versions := []Version{
+		{SeqNum: 2, Kind: KindOperand, Value: []byte("a")},
+		{SeqNum: 5, Kind: KindOperand, Value: []byte("b")},
+		{SeqNum: 5, Kind: KindValue, Value: []byte("c")},
+		{SeqNum: 1, Kind: KindValue, Value: []byte("d")},
+	}
+
+	versions = SortVersions(versions)
+	want := []string{"b", "c", "a", "d"}
+	if len(versions) != len(want) {
+		t.Fatalf("Expected %d versions, got %+v", len(want), versions)
+	}
+	for i, v := range versions {
+		if string(v.Value) != want[i] {
+			t.Errorf("Version %d: expected %s, got %s", i, want[i], v.Value)
+		}
+	}
+}
+
+func TestDropDuplicates(t *testing.T) {
+	// A batch read from a MemTable
+	versions := []Version{
+		{SeqNum: 4, Kind: KindOperand, Value: []byte("1")},
+		{SeqNum: 4, Kind: KindOperand, Value: []byte("1")},
+	}
+
+	// Versions from a single source are all kept
+	versions = DropDuplicates(versions, 0)
+	if len(versions) != 2 {
+		t.Fatalf("Expected both operands of the batch, got %+v", versions)
+	}
+
+	// The same batch flushed to an SSTable, on top of an older value
+	from := len(versions)
+	versions = append(versions,
+		Version{SeqNum: 4, Kind: KindOperand, Value: []byte("1")},
+		Version{SeqNum: 4, Kind: KindOperand, Value: []byte("1")},
+		Version{SeqNum: 2, Kind: KindValue, Value: []byte("10")},
+	)
+	versions = DropDuplicates(versions, from)
+
+	value, found, err := Resolve(CounterOperator{}, []byte("key"), versions)
+	if err != nil || !found || string(value) != "12" {
+		t.Errorf("Expected 12, got (%q, %v, %v)", value, found, err)
+	}
+}
+
+func TestInsertDeletion(t *testing.T) {
+	versions := []Version{
+		{SeqNum: 5, Kind: KindValue, Value: []byte("new")},
+		{SeqNum: 3, Kind: KindValue, Value: []byte("batch")},
+		{SeqNum: 1, Kind: KindValue, Value: []byte("old")},
+	}
+
+	// Versions written at the deletion's sequence number stay in front of it
+	versions = InsertDeletion(versions, 3)
+	want := []uint64{5, 3, 3, 1}
+	if len(versions) != len(want) {
+		t.Fatalf("Expected %d versions, got %d", len(want), len(versions))
+	}
+	for i, v := range versions {
+		if v.SeqNum != want[i] {
+			t.Errorf("Version %d: expected seq %d, got %d", i, want[i], v.SeqNum)
+		}
+	}
+	if versions[2].Kind != KindDeletion || versions[1].Kind != KindValue {
+		t.Errorf("Expected the deletion after the value written at the same sequence number")
+	}
+
+	// A deletion newer than every version hides the key
+	value, found, err := Resolve(nil, []byte("key"), InsertDeletion(versions, 9))
+	if err != nil || found {
+		t.Errorf("Expected key to be deleted, got (%q, %v, %v)", value, found, err)
+	}
+}
+
+func TestExpire(t *testing.T) {
+	now := time.Unix(100, 0)
+	past := uint64(time.Unix(50, 0).UnixNano())
+	future := uint64(time.Unix(150, 0).UnixNano())
+
+	versions := Expire([]Version{
+		{SeqNum: 6, Kind: KindOperand, Value: []byte("1")},
+		{SeqNum: 5, Kind: KindValue, Value: []byte("10"), ExpireAt: future},
+		{SeqNum: 4, Kind: KindOperand, Value: []byte("2")},
+		{SeqNum: 3, Kind: KindValue, Value: []byte("20"), ExpireAt: past},
+		{SeqNum: 2, Kind: KindOperand, Value: []byte("3")},
+		{SeqNum: 1, Kind: KindValue, Value: []byte("30")},
+	}, now)
+
+	// The operand written on the expired value expires with it
+	want := []struct {
+		seqNum uint64
+		kind   Kind
+	}{
+		{6, KindOperand},
+		{5, KindValue},
+		{3, KindDeletion},
+		{2, KindOperand},
+		{1, KindValue},
+	}
+	if len(versions) != len(want) {
+		t.Fatalf("Expected %d versions, got %d", len(want), len(versions))
+	}
+	for i, v := range versions {
+		if v.SeqNum != want[i].seqNum || v.Kind != want[i].kind {
+			t.Errorf("Version %d: expected %d@%d, got %d@%d", i, want[i].kind, want[i].seqNum, v.Kind, v.SeqNum)
+		}
+	}
+
+	// Once the newer value expires too the key reads as deleted
+	value, found, err := Resolve(CounterOperator{}, []byte("key"), Expire(versions, time.Unix(200, 0)))
+	if err != nil || found {
+		t.Errorf("Expected key to be expired, got (%q, %v, %v)", value, found, err)
+	}
+}
+
+func TestResolveWithoutOperator(t *testing.T) {
+	versions := []Version{{SeqNum: 1, Kind: KindOperand, Value: []byte("1")}}
+	if _, _, err := Resolve(nil, []byte("key"), versions); !errors.Is(err, ErrNoOperator) {
+		t.Errorf("Expected ErrNoOperator, got %v", err)
+	}
+
+	// Without operands no operator is needed
+	versions = []Version{{SeqNum: 1, Kind: KindValue, Value: []byte("v")}}
+	if value, found, err := Resolve(nil, []byte("key"), versions); err != nil || !found || string(value) != "v" {
+		t.Errorf("Expected plain value, got (%q, %v, %v)", value, found, err)
+	}
+}
+
+func TestCollapse(t *testing.T) {
+	op := CounterOperator{}
+	key := []byte("counter")
+
+	versions := []Version{
+		{SeqNum: 6, Kind: KindOperand, Value: []byte("1")},
+		{SeqNum: 5, Kind: KindOperand, Value: []byte("2")},
+		{SeqNum: 4, Kind: KindValue, Value: []byte("10")},
+		{SeqNum: 3, Kind: KindOperand, Value: []byte("20")},
+		{SeqNum: 2, Kind: KindOperand, Value: []byte("30")},
+		{SeqNum: 1, Kind: KindValue, Value: []byte("40")},
+	}
+
+	// Without snapshots everything folds into a single value
+	result := Collapse(op, key, versions, nil)
+	if len(result) != 1 || result[0].Kind != KindValue || string(result[0].Value) != "13" || result[0].SeqNum != 6 {
+		t.Fatalf("Expected a single value 13@6, got %+v", result)
+	}
+
+	// A snapshot at 5 must still see 12, a snapshot at 2 must still see 70
+	result = Collapse(op, key, versions, []uint64{2, 5})
+	expected := []Version{
+		{SeqNum: 6, Kind: KindOperand, Value: []byte("1")},
+		{SeqNum: 5, Kind: KindValue, Value: []byte("12")},
+		{SeqNum: 2, Kind: KindValue, Value: []byte("70")},
+	}
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d versions, got %+v", len(expected), result)
+	}
+	for i := range expected {
+		if result[i].SeqNum != expected[i].SeqNum || result[i].Kind != expected[i].Kind || string(result[i].Value) != string(expected[i].Value) {
+			t.Errorf("Version %d: expected %+v, got %+v", i, expected[i], result[i])
+		}
+	}
+
+	// Operands with no base are combined when the operator allows it
+	operands := []Version{
+		{SeqNum: 2, Kind: KindOperand, Value: []byte("3")},
+		{SeqNum: 1, Kind: KindOperand, Value: []byte("4")},
+	}
+	result = Collapse(op, key, operands, nil)
+	if len(result) != 1 || result[0].Kind != KindOperand || string(result[0].Value) != "7" {
+		t.Errorf("Expected a single operand 7, got %+v", result)
+	}
+
+	// Without an operator operands are kept as they are
+	result = Collapse(nil, key, versions[:3], nil)
+	if len(result) != 3 {
+		t.Errorf("Expected operands and their base to be kept, got %+v", result)
+	}
+}
+
+func TestAppendOperator(t *testing.T) {
+	op := AppendOperator{Separator: []byte(",")}
+
+	value, err := op.FullMerge([]byte("key"), []byte("a"), [][]byte{[]byte("b"), []byte("c")})
+	if err != nil || string(value) != "a,b,c" {
+		t.Errorf("Expected a,b,c, got %q (err: %v)", value, err)
+	}
+
+	value, err = op.FullMerge([]byte("key"), nil, [][]byte{[]byte("b")})
+	if err != nil || string(value) != "b" {
+		t.Errorf("Expected b, got %q (err: %v)", value, err)
+	}
+}
+
+func TestCounterOperatorInvalidValue(t *testing.T) {
+	op := CounterOperator{}
+	if _, err := op.FullMerge([]byte("key"), []byte("abc"), [][]byte{[]byte("1")}); err == nil {
+		t.Error("Expected error for a non-numeric value")
+	}
+	if _, ok := op.PartialMerge([]byte("key"), [][]byte{[]byte("x")}); ok {
+		t.Error("Expected partial merge of a non-numeric operand to fail")
+	}
+}
@@ -0,0 +1,71 @@
+package merge
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// AppendOperator appends operands to the existing value, separated by Separator
+type AppendOperator struct {
+	Separator []byte
+}
+
+// Name identifies the operator
+func (o AppendOperator) Name() string {
+	return "append"
+}
+
+// FullMerge appends the operands to the existing value
+func (o AppendOperator) FullMerge(key, existing []byte, operands [][]byte) ([]byte, error) {
+	result := append([]byte(nil), existing...)
+	for i, operand := range operands {
+		if existing != nil || i > 0 {
+			result = append(result, o.Separator...)
+		}
+		result = append(result, operand...)
+	}
+	return result, nil
+}
+
+// PartialMerge joins the operands into a single operand
+func (o AppendOperator) PartialMerge(key []byte, operands [][]byte) ([]byte, bool) {
+	result, _ := o.FullMerge(key, nil, operands)
+	return result, true
+}
+
+// CounterOperator adds operands to the existing value. Values and operands are
+// signed 64-bit integers in decimal notation, a missing value counts as zero.
+type CounterOperator struct{}
+
+// Name identifies the operator
+func (CounterOperator) Name() string {
+	return "counter"
+}
+
+// FullMerge adds the operands to the existing value
+func (CounterOperator) FullMerge(key, existing []byte, operands [][]byte) ([]byte, error) {
+	var total int64
+	if existing != nil {
+		value, err := strconv.ParseInt(string(existing), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid counter value for key %q: %w", key, err)
+		}
+		total = value
+	}
+
+	for _, operand := range operands {
+		delta, err := strconv.ParseInt(string(operand), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid counter operand for key %q: %w", key, err)
+		}
+		total += delta
+	}
+
+	return []byte(strconv.FormatInt(total, 10)), nil
+}
+
+// PartialMerge adds the operands together
+func (o CounterOperator) PartialMerge(key []byte, operands [][]byte) ([]byte, bool) {
+	result, err := o.FullMerge(key, nil, operands)
+	return result, err == nil
+}
@@ -0,0 +1,68 @@
+// Package rangedel describes range deletions and the versions of keys they hide.
+package rangedel
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrInvalidRange is returned when a range deletion has an empty start key or
+// a start key that isn't smaller than its end key
+var ErrInvalidRange = errors.New("invalid range: start key must be non-empty and smaller than end key")
+
+// Tombstone deletes every version of the keys in [Start, End) written before SeqNum
+type Tombstone struct {
+	Start  []byte
+	End    []byte
+	SeqNum uint64
+}
+
+// Validate checks that [start, end) is a valid range to delete
+func Validate(start, end []byte) error {
+	if len(start) == 0 || bytes.Compare(start, end) >= 0 {
+		return ErrInvalidRange
+	}
+	return nil
+}
+
+// Contains reports whether key is inside the range of the tombstone
+func (t Tombstone) Contains(key []byte) bool {
+	return bytes.Compare(key, t.Start) >= 0 && bytes.Compare(key, t.End) < 0
+}
+
+// Overlaps reports whether the tombstone overlaps [start, end). Nil bounds are unbounded.
+func (t Tombstone) Overlaps(start, end []byte) bool {
+	if end != nil && bytes.Compare(t.Start, end) >= 0 {
+		return false
+	}
+	return start == nil || bytes.Compare(start, t.End) < 0
+}
+
+// Covers reports whether the tombstone deletes the version of key written at seqNum
+func (t Tombstone) Covers(key []byte, seqNum uint64) bool {
+	return seqNum < t.SeqNum && t.Contains(key)
+}
+
+// MaxCoveringSeq returns the highest sequence number of the tombstones that
+// contain key and are visible at snapshotSeq, or 0 if there are none. Versions
+// of the key older than the result are deleted.
+func MaxCoveringSeq(tombstones []Tombstone, key []byte, snapshotSeq uint64) uint64 {
+	var maxSeqNum uint64
+	for _, t := range tombstones {
+		if t.SeqNum > maxSeqNum && t.SeqNum <= snapshotSeq && t.Contains(key) {
+			maxSeqNum = t.SeqNum
+		}
+	}
+	return maxSeqNum
+}
+
+// Visible returns the tombstones written at or before snapshotSeq
+func Visible(tombstones []Tombstone, snapshotSeq uint64) []Tombstone {
+	var result []Tombstone
+	for _, t := range tombstones {
+		if t.SeqNum <= snapshotSeq {
+			result = append(result, t)
+		}
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package rangedel
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		start, end string
+		valid      bool
+	}{
+		{"a", "b", true},
+		{"a", "a", false},
+		{"b", "a", false},
+		{"", "b", false},
+	}
+
+	for _, tt := range tests {
+		err := Validate([]byte(tt.start), []byte(tt.end))
+		if tt.valid && err != nil {
+			t.Errorf("[%q, %q): unexpected error %v", tt.start, tt.end, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidRange) {
+			t.Errorf("[%q, %q): expected ErrInvalidRange, got %v", tt.start, tt.end, err)
+		}
+	}
+}
+
+func TestTombstone(t *testing.T) {
+	ts := Tombstone{Start: []byte("b"), End: []byte("d"), SeqNum: 10}
+
+	for key, want := range map[string]bool{"a": false, "b": true, "c": true, "cz": true, "d": false} {
+		if got := ts.Contains([]byte(key)); got != want {
+			t.Errorf("Contains(%q): expected %v, got %v", key, want, got)
+		}
+	}
+
+	if !ts.Covers([]byte("c"), 9) || ts.Covers([]byte("c"), 10) || ts.Covers([]byte("e"), 1) {
+		t.Error("Expected only older versions of keys in the range to be covered")
+	}
+
+	overlaps := []struct {
+		start, end []byte
+		want       bool
+	}{
+		{nil, nil, true},
+		{[]byte("a"), []byte("b"), false},
+		{[]byte("a"), []byte("c"), true},
+		{[]byte("c"), nil, true},
+		{[]byte("d"), nil, false},
+	}
+	for _, tt := range overlaps {
+		if got := ts.Overlaps(tt.start, tt.end); got != tt.want {
+			t.Errorf("Overlaps(%q, %q): expected %v, got %v", tt.start, tt.end, tt.want, got)
+		}
+	}
+}
+
+func TestMaxCoveringSeq(t *testing.T) {
+	tombstones := []Tombstone{
+		{Start: []byte("a"), End: []byte("m"), SeqNum: 5},
+		{Start: []byte("f"), End: []byte("z"), SeqNum: 8},
+	}
+
+	tests := []struct {
+		key         string
+		snapshotSeq uint64
+		want        uint64
+	}{
+		{"b", 100, 5},
+		{"g", 100, 8},
+		{"g", 7, 5},
+		{"g", 4, 0},
+		{"zz", 100, 0},
+	}
+
+	for _, tt := range tests {
+		if got := MaxCoveringSeq(tombstones, []byte(tt.key), tt.snapshotSeq); got != tt.want {
+			t.Errorf("MaxCoveringSeq(%q, %d): expected %d, got %d", tt.key, tt.snapshotSeq, tt.want, got)
+		}
+	}
+
+	if visible := Visible(tombstones, 6); len(visible) != 1 || visible[0].SeqNum != 5 {
+		t.Errorf("Expected only the tombstone at 5 to be visible, got %+v", visible)
+	}
+}
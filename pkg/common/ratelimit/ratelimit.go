@@ -0,0 +1,240 @@
+// Package ratelimit limits the rate at which background work writes to disk,
+// so that flushes and compactions don't starve foreground reads and writes.
+package ratelimit
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Priority orders the requests waiting for the same limiter
+type Priority int
+
+const (
+	// Low priority requests, such as compaction writes, only proceed while
+	// no high priority request is waiting
+	Low Priority = iota
+	// High priority requests, such as MemTable flushes
+	High
+)
+
+const (
+	// burstDuration is how long unused tokens accumulate for
+	burstDuration = 100 * time.Millisecond
+
+	// tuneInterval is how often auto tuning adjusts the rate
+	tuneInterval = time.Second
+
+	// minRateFraction bounds how far auto tuning lowers the rate, as a divisor
+	// of the configured rate
+	minRateFraction = 16
+
+	// latencyBackoffFactor is how many times above its usual level foreground
+	// latency has to rise for auto tuning to halve the rate
+	latencyBackoffFactor = 2
+)
+
+// Limiter is a token bucket limiting the bytes per second written by the
+// requests sharing it. With auto tuning, the rate is halved whenever the
+// foreground latency reported with RecordLatency rises well above its usual
+// level, and raised back to the configured rate once it recovers. A nil
+// *Limiter or a rate of zero doesn't limit anything.
+type Limiter struct {
+	mu sync.Mutex
+
+	// Configured rate and the rate currently applied, in bytes per second
+	rate      int64
+	effective int64
+
+	// Bytes that can be written without waiting, negative when borrowed
+	tokens float64
+	last   time.Time
+
+	// Number of high priority requests waiting for tokens
+	highWaiting int
+
+	// Closed when the rate changes, to wake up waiting requests
+	rateChanged chan struct{}
+
+	// Foreground latency reported since the last tuning, and its usual level
+	autoTune     bool
+	latencySum   atomic.Int64
+	latencyCount atomic.Int64
+	baseline     float64
+	lastTune     time.Time
+
+	// Clock, replaced in tests
+	now func() time.Time
+}
+
+// NewLimiter creates a limiter allowing bytesPerSec bytes per second, zero for
+// no limit, that tunes its rate to the foreground latency if autoTune is set
+func NewLimiter(bytesPerSec int64, autoTune bool) *Limiter {
+	l := &Limiter{
+		autoTune: autoTune,
+		now:      time.Now,
+	}
+	l.SetRate(bytesPerSec)
+	return l
+}
+
+// SetRate changes the limit to bytesPerSec bytes per second, zero for no limit.
+// Auto tuning starts over from the new rate.
+func (l *Limiter) SetRate(bytesPerSec int64) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rate = max(bytesPerSec, 0)
+	l.effective = l.rate
+	l.tokens = min(l.tokens, l.burst())
+	l.last = l.now()
+
+	if l.rateChanged != nil {
+		close(l.rateChanged)
+	}
+	l.rateChanged = make(chan struct{})
+}
+
+// Rate returns the configured limit in bytes per second, zero for no limit
+func (l *Limiter) Rate() int64 {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.rate
+}
+
+// EffectiveRate returns the limit currently applied in bytes per second, which
+// auto tuning may have lowered below the configured rate
+func (l *Limiter) EffectiveRate() int64 {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.effective
+}
+
+// Wait blocks until n bytes may be written. Requests larger than the tokens
+// available borrow the difference, which makes the following requests wait.
+func (l *Limiter) Wait(n int, priority Priority) {
+	if l == nil || n <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	waiting := false
+	for l.effective > 0 {
+		now := l.now()
+		l.refill(now)
+		l.tune(now)
+
+		if l.tokens > 0 && (priority == High || l.highWaiting == 0) {
+			l.tokens -= float64(n)
+			break
+		}
+
+		if priority == High && !waiting {
+			l.highWaiting++
+			waiting = true
+		}
+
+		// Sleep until the borrowed tokens are paid back, or briefly while
+		// high priority requests go first
+		wait := time.Millisecond
+		if l.tokens <= 0 {
+			wait = max(wait, time.Duration(-l.tokens/float64(l.effective)*float64(time.Second)))
+		}
+		rateChanged := l.rateChanged
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-rateChanged:
+			timer.Stop()
+		}
+
+		l.mu.Lock()
+	}
+
+	if waiting {
+		l.highWaiting--
+	}
+}
+
+// RecordLatency reports the latency of a foreground operation to auto tuning
+func (l *Limiter) RecordLatency(d time.Duration) {
+	if l == nil || !l.autoTune {
+		return
+	}
+
+	l.latencySum.Add(int64(d))
+	l.latencyCount.Add(1)
+}
+
+// burst returns the most tokens that can accumulate. The caller must hold the lock.
+func (l *Limiter) burst() float64 {
+	return float64(l.effective) * burstDuration.Seconds()
+}
+
+// refill adds the tokens earned since the last refill. The caller must hold the lock.
+func (l *Limiter) refill(now time.Time) {
+	elapsed := now.Sub(l.last)
+	l.last = now
+	if elapsed > 0 {
+		l.tokens = min(l.tokens+elapsed.Seconds()*float64(l.effective), l.burst())
+	}
+}
+
+// tune adjusts the effective rate to the foreground latency reported since the
+// last tuning. The caller must hold the lock.
+func (l *Limiter) tune(now time.Time) {
+	if !l.autoTune {
+		return
+	}
+	if l.lastTune.IsZero() {
+		l.lastTune = now
+		return
+	}
+	if now.Sub(l.lastTune) < tuneInterval {
+		return
+	}
+	l.lastTune = now
+
+	sum := l.latencySum.Swap(0)
+	count := l.latencyCount.Swap(0)
+
+	step := max(l.rate/8, 1)
+	if count == 0 {
+		// Nothing to slow down
+		l.effective = min(l.effective+step, l.rate)
+		return
+	}
+
+	latency := float64(sum) / float64(count)
+	if l.baseline == 0 {
+		l.baseline = latency
+	}
+
+	if latency > l.baseline*latencyBackoffFactor {
+		l.effective = max(l.effective/2, max(l.rate/minRateFraction, 1))
+		// Follow lasting changes of the workload, slowly
+		l.baseline = l.baseline*0.99 + latency*0.01
+	} else {
+		l.effective = min(l.effective+step, l.rate)
+		l.baseline = l.baseline*0.9 + latency*0.1
+	}
+}
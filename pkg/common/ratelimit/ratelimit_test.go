@@ -0,0 +1,137 @@
+package ratelimit
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLimiterRate(t *testing.T) {
+	const rate = 1 << 20 // 1MB/s
+	l := NewLimiter(rate, false)
+
+	// 300KB take at least the time to earn what a full burst doesn't cover
+	start := time.Now()
+	for i := 0; i < 75; i++ {
+		l.Wait(4096, Low)
+	}
+	elapsed := time.Since(start)
+
+	// (300KB - 100KB of burst) / 1MB/s
+	minElapsed := 180 * time.Millisecond
+	if elapsed < minElapsed {
+		t.Errorf("Expected writing 300KB at 1MB/s to take at least %v, took %v", minElapsed, elapsed)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("Writing 300KB at 1MB/s took %v", elapsed)
+	}
+}
+
+func TestLimiterUnlimited(t *testing.T) {
+	var nilLimiter *Limiter
+	nilLimiter.Wait(1<<30, High)
+	nilLimiter.RecordLatency(time.Second)
+
+	l := NewLimiter(0, true)
+	start := time.Now()
+	l.Wait(1<<30, Low)
+	if time.Since(start) > 100*time.Millisecond {
+		t.Errorf("Expected a zero rate not to limit writes")
+	}
+
+	// The limit can be lifted while writes are waiting
+	l.SetRate(1024)
+	l.Wait(1<<20, Low)
+	done := make(chan struct{})
+	go func() {
+		l.Wait(1024, Low)
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	l.SetRate(0)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the write to proceed once the limit was lifted")
+	}
+}
+
+func TestLimiterPriority(t *testing.T) {
+	l := NewLimiter(1<<20, false)
+
+	// Borrow 100ms worth of tokens, then queue a low and a high priority write
+	l.Wait(100*1024, Low)
+
+	var mu sync.Mutex
+	var order []Priority
+	var wg sync.WaitGroup
+	for _, priority := range []Priority{Low, High} {
+		wg.Add(1)
+		go func(priority Priority) {
+			defer wg.Done()
+			l.Wait(1024, priority)
+			mu.Lock()
+			order = append(order, priority)
+			mu.Unlock()
+		}(priority)
+		time.Sleep(10 * time.Millisecond)
+	}
+	wg.Wait()
+
+	if len(order) != 2 || order[0] != High {
+		t.Errorf("Expected the high priority write to go first, got %v", order)
+	}
+}
+
+func TestLimiterAutoTune(t *testing.T) {
+	const rate = 1 << 20
+	l := NewLimiter(rate, true)
+
+	now := time.Now()
+	l.now = func() time.Time { return now }
+
+	// tick reports foreground latency and lets a second pass
+	tick := func(latency time.Duration) int64 {
+		for i := 0; i < 10; i++ {
+			l.RecordLatency(latency)
+		}
+		now = now.Add(tuneInterval)
+		l.mu.Lock()
+		l.tune(now)
+		l.mu.Unlock()
+		return l.EffectiveRate()
+	}
+
+	tick(0)
+	if got := tick(time.Millisecond); got != rate {
+		t.Fatalf("Expected the full rate at usual latency, got %d", got)
+	}
+
+	// Latency spikes halve the rate down to its floor
+	if got := tick(10 * time.Millisecond); got != rate/2 {
+		t.Errorf("Expected the rate to be halved, got %d", got)
+	}
+	for i := 0; i < 10; i++ {
+		tick(10 * time.Millisecond)
+	}
+	if got := l.EffectiveRate(); got != rate/minRateFraction {
+		t.Errorf("Expected the rate to bottom out at %d, got %d", rate/minRateFraction, got)
+	}
+
+	// It recovers gradually once latency is back to normal
+	if got := tick(time.Millisecond); got <= rate/minRateFraction || got >= rate {
+		t.Errorf("Expected the rate to start recovering, got %d", got)
+	}
+	for i := 0; i < 10; i++ {
+		tick(time.Millisecond)
+	}
+	if got := l.EffectiveRate(); got != rate {
+		t.Errorf("Expected the full rate after recovering, got %d", got)
+	}
+
+	// Changing the rate resets tuning
+	l.SetRate(2 * rate)
+	if got := l.EffectiveRate(); got != 2*rate {
+		t.Errorf("Expected the new rate, got %d", got)
+	}
+}
@@ -0,0 +1,73 @@
+// Package snapshot tracks the sequence numbers pinned by open read snapshots.
+package snapshot
+
+import (
+	"sort"
+	"sync"
+)
+
+// Tracker keeps a reference count of live snapshots by sequence number
+type Tracker struct {
+	refs map[uint64]int
+	mu   sync.RWMutex
+}
+
+// NewTracker creates a new snapshot tracker
+func NewTracker() *Tracker {
+	return &Tracker{
+		refs: make(map[uint64]int),
+	}
+}
+
+// Acquire pins the given sequence number
+func (t *Tracker) Acquire(seqNum uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.refs[seqNum]++
+}
+
+// Release unpins the given sequence number
+func (t *Tracker) Release(seqNum uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.refs[seqNum] <= 1 {
+		delete(t.refs, seqNum)
+		return
+	}
+	t.refs[seqNum]--
+}
+
+// Count returns the number of distinct pinned sequence numbers
+func (t *Tracker) Count() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return len(t.refs)
+}
+
+// LiveSnapshots returns the pinned sequence numbers in ascending order
+func (t *Tracker) LiveSnapshots() []uint64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	seqs := make([]uint64, 0, len(t.refs))
+	for seq := range t.refs {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	return seqs
+}
+
+// IsRequired reports whether a version written at seqNum must be kept even though
+// a newer version of the same key was written at newerSeqNum. This is the case when
+// some live snapshot (sorted ascending) was taken in between the two writes.
+func IsRequired(live []uint64, seqNum, newerSeqNum uint64) bool {
+	// Find the first snapshot that can see this version
+	i := sort.Search(len(live), func(i int) bool { return live[i] >= seqNum })
+
+	// The version is needed if that snapshot can't see the newer one
+	return i < len(live) && live[i] < newerSeqNum
+}
@@ -0,0 +1,49 @@
+// Package ttl describes keys that expire a fixed time after they are written.
+package ttl
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// ErrInvalidTTL is returned when a TTL is zero or negative
+var ErrInvalidTTL = errors.New("invalid ttl: must be positive")
+
+// ErrInvalidValue is returned when an encoded expiring value is too short
+var ErrInvalidValue = errors.New("invalid expiring value: missing expiry time")
+
+// expireAtSize is the size of the expiry time prefixed to encoded values
+const expireAtSize = 8
+
+// ExpireAt returns the expiry time of a key written at writeTime with the given
+// TTL, in nanoseconds since the Unix epoch. The expiry is absolute so that every
+// node agrees on it, whatever its own clock says when it applies the write.
+func ExpireAt(writeTime time.Time, ttl time.Duration) (uint64, error) {
+	if ttl <= 0 {
+		return 0, ErrInvalidTTL
+	}
+	return uint64(writeTime.Add(ttl).UnixNano()), nil
+}
+
+// Expired reports whether a value with the given expiry time has expired at now.
+// An expiry time of zero means the value never expires.
+func Expired(expireAt uint64, now time.Time) bool {
+	return expireAt != 0 && uint64(now.UnixNano()) >= expireAt
+}
+
+// Encode prefixes value with its expiry time, as stored in the WAL
+func Encode(expireAt uint64, value []byte) []byte {
+	data := make([]byte, expireAtSize+len(value))
+	binary.LittleEndian.PutUint64(data, expireAt)
+	copy(data[expireAtSize:], value)
+	return data
+}
+
+// Decode splits data produced by Encode into the expiry time and the value
+func Decode(data []byte) (uint64, []byte, error) {
+	if len(data) < expireAtSize {
+		return 0, nil, ErrInvalidValue
+	}
+	return binary.LittleEndian.Uint64(data), data[expireAtSize:], nil
+}
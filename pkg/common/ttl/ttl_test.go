@@ -0,0 +1,52 @@
+package ttl
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExpireAt(t *testing.T) {
+	writeTime := time.Unix(100, 0)
+
+	expireAt, err := ExpireAt(writeTime, time.Second)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expireAt != uint64(time.Unix(101, 0).UnixNano()) {
+		t.Errorf("Expected expiry one second after the write, got %d", expireAt)
+	}
+
+	if Expired(expireAt, writeTime) || Expired(expireAt, time.Unix(100, 999)) {
+		t.Error("Expected value to be live before its expiry time")
+	}
+	if !Expired(expireAt, time.Unix(101, 0)) || !Expired(expireAt, time.Unix(200, 0)) {
+		t.Error("Expected value to be expired from its expiry time on")
+	}
+	if Expired(0, time.Unix(200, 0)) {
+		t.Error("Expected a zero expiry time to never expire")
+	}
+
+	for _, ttl := range []time.Duration{0, -time.Second} {
+		if _, err := ExpireAt(writeTime, ttl); !errors.Is(err, ErrInvalidTTL) {
+			t.Errorf("ttl %v: expected ErrInvalidTTL, got %v", ttl, err)
+		}
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for _, value := range [][]byte{[]byte("value"), {}} {
+		expireAt, decoded, err := Decode(Encode(42, value))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if expireAt != 42 || !bytes.Equal(decoded, value) {
+			t.Errorf("Expected (42, %q), got (%d, %q)", value, expireAt, decoded)
+		}
+	}
+
+	if _, _, err := Decode([]byte("short")); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue, got %v", err)
+	}
+}
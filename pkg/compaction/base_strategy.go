@@ -9,6 +9,7 @@ import (
 
 	"github.com/KevoDB/kevo/pkg/config"
 	"github.com/KevoDB/kevo/pkg/sstable"
+	"github.com/KevoDB/kevo/pkg/versionlog"
 )
 
 // BaseCompactionStrategy provides common functionality for compaction strategies
@@ -21,6 +22,9 @@ type BaseCompactionStrategy struct {
 
 	// File information by level
 	levels map[int][]*SSTableInfo
+
+	// Log of the live files, nil to scan the SSTable directory
+	versions *versionlog.Log
 }
 
 // NewBaseCompactionStrategy creates a new base compaction strategy
@@ -32,11 +36,45 @@ func NewBaseCompactionStrategy(cfg *config.Config, sstableDir string) *BaseCompa
 	}
 }
 
-// LoadSSTables scans the SSTable directory and loads metadata for all files
+// SetVersionLog sets the version log the live files are loaded from. Without
+// one, they are found by scanning the SSTable directory.
+func (s *BaseCompactionStrategy) SetVersionLog(versions *versionlog.Log) {
+	s.versions = versions
+}
+
+// LoadSSTables loads metadata for all live SSTable files
 func (s *BaseCompactionStrategy) LoadSSTables() error {
 	// Clear existing data
 	s.levels = make(map[int][]*SSTableInfo)
 
+	if version := s.versions.Current(); version != nil {
+		for _, file := range version.Files() {
+			path := filepath.Join(s.sstableDir, file.Name)
+			reader, err := sstable.OpenReaderWithOptions(path, sstable.ReaderOptions{
+				GlobalSequence: file.GlobalSequence,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to open SSTable %s: %w", path, err)
+			}
+			s.addSSTable(file, reader)
+		}
+	} else if err := s.scanSSTables(); err != nil {
+		return err
+	}
+
+	// Sort files within each level by sequence number
+	for level, files := range s.levels {
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].Sequence < files[j].Sequence
+		})
+		s.levels[level] = files
+	}
+
+	return nil
+}
+
+// scanSSTables loads the SSTable files found in the SSTable directory
+func (s *BaseCompactionStrategy) scanSSTables() error {
 	// Read all files from the SSTable directory
 	entries, err := os.ReadDir(s.sstableDir)
 	if err != nil {
@@ -52,75 +90,48 @@ func (s *BaseCompactionStrategy) LoadSSTables() error {
 			continue // Skip directories and non-SSTable files
 		}
 
-		// Parse filename to extract level, sequence, and timestamp
-		// Filename format: level_sequence_timestamp.sst
-		var level int
-		var sequence uint64
-		var timestamp int64
-
-		if n, err := fmt.Sscanf(entry.Name(), "%d_%06d_%020d.sst",
-			&level, &sequence, &timestamp); n != 3 || err != nil {
-			// Skip files that don't match our naming pattern
+		// Skip files that don't match our naming pattern
+		if _, _, _, ok := versionlog.ParseFileName(entry.Name()); !ok {
 			continue
 		}
 
-		// Get file info for size
-		fi, err := entry.Info()
-		if err != nil {
-			return fmt.Errorf("failed to get file info for %s: %w", entry.Name(), err)
-		}
-
-		// Open the file to extract key range information
 		path := filepath.Join(s.sstableDir, entry.Name())
 		reader, err := sstable.OpenReader(path)
 		if err != nil {
 			return fmt.Errorf("failed to open SSTable %s: %w", path, err)
 		}
 
-		// Create iterator to get first and last keys
-		iter := reader.NewIterator()
-		var firstKey, lastKey []byte
-
-		// Get first key
-		iter.SeekToFirst()
-		if iter.Valid() {
-			firstKey = append([]byte{}, iter.Key()...)
-		}
-
-		// Get last key
-		iter.SeekToLast()
-		if iter.Valid() {
-			lastKey = append([]byte{}, iter.Key()...)
-		}
-
-		// Create SSTable info
-		info := &SSTableInfo{
-			Path:      path,
-			Level:     level,
-			Sequence:  sequence,
-			Timestamp: timestamp,
-			Size:      fi.Size(),
-			KeyCount:  reader.GetKeyCount(),
-			FirstKey:  firstKey,
-			LastKey:   lastKey,
-			Reader:    reader,
+		file, err := versionlog.Describe(reader)
+		if err != nil {
+			reader.Close()
+			return err
 		}
-
-		// Add to appropriate level
-		s.levels[level] = append(s.levels[level], info)
-	}
-
-	// Sort files within each level by sequence number
-	for level, files := range s.levels {
-		sort.Slice(files, func(i, j int) bool {
-			return files[i].Sequence < files[j].Sequence
-		})
-		s.levels[level] = files
+		s.addSSTable(file, reader)
 	}
 
 	return nil
 }
 
+// addSSTable adds an open SSTable file to its level
+func (s *BaseCompactionStrategy) addSSTable(file versionlog.FileMeta, reader *sstable.Reader) {
+	// Filename format: level_sequence_timestamp.sst
+	_, _, timestamp, _ := versionlog.ParseFileName(file.Name)
+
+	info := &SSTableInfo{
+		Path:      reader.FilePath(),
+		Level:     file.Level,
+		Sequence:  file.Sequence,
+		Timestamp: timestamp,
+		CreatedAt: reader.Timestamp(),
+		Size:      file.Size,
+		KeyCount:  reader.GetKeyCount(),
+		FirstKey:  file.Smallest,
+		LastKey:   file.Largest,
+		Reader:    reader,
+	}
+	s.levels[file.Level] = append(s.levels[file.Level], info)
+}
+
 // Close closes all open SSTable readers
 func (s *BaseCompactionStrategy) Close() error {
 	var lastErr error
@@ -21,6 +21,10 @@ type SSTableInfo struct {
 	// Timestamp when the file was created
 	Timestamp int64
 
+	// Creation time recorded in the file footer, in Unix nanoseconds. Files
+	// written by compaction may carry the time of the data they hold instead.
+	CreatedAt int64
+
 	// Approximate size of the file in bytes
 	Size int64
 
@@ -73,4 +77,14 @@ type CompactionTask struct {
 
 	// Output file path template
 	OutputPathTemplate string
+
+	// Set when levels below the target may hold older versions of the input
+	// keys, in which case tombstones must be kept whatever the target level
+	KeepTombstones bool
+
+	// Set when the input files are deleted without writing any output
+	Drop bool
+
+	// Creation time to record in the output files, zero for the current time
+	OutputTimestamp int64
 }
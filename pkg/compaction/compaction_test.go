@@ -3,21 +3,33 @@ package compaction
 import (
 	"bytes"
 	"fmt"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/KevoDB/kevo/pkg/common/merge"
 	"github.com/KevoDB/kevo/pkg/config"
 	"github.com/KevoDB/kevo/pkg/sstable"
+	"github.com/KevoDB/kevo/pkg/versionlog"
 )
 
 func createTestSSTable(t *testing.T, dir string, level, seq int, timestamp int64, keyValues map[string]string) string {
+	return createTestSSTableCreatedAt(t, dir, level, seq, timestamp, 0, keyValues)
+}
+
+// createTestSSTableCreatedAt creates a test SSTable recording createdAt as its
+// creation time, or the current time if it's zero
+func createTestSSTableCreatedAt(t *testing.T, dir string, level, seq int, timestamp, createdAt int64, keyValues map[string]string) string {
 	filename := fmt.Sprintf("%d_%06d_%020d.sst", level, seq, timestamp)
 	path := filepath.Join(dir, filename)
 
-	writer, err := sstable.NewWriter(path)
+	options := sstable.DefaultWriterOptions()
+	options.Timestamp = createdAt
+	writer, err := sstable.NewWriterWithOptions(path, options)
 	if err != nil {
 		t.Fatalf("Failed to create SSTable writer: %v", err)
 	}
@@ -417,3 +429,837 @@ func TestCompactionManager(t *testing.T) {
 		t.Fatalf("Failed to stop compaction manager: %v", err)
 	}
 }
+
+// staticSnapshots is a SnapshotProvider with a fixed set of snapshots
+type staticSnapshots []uint64
+
+func (s staticSnapshots) LiveSnapshots() []uint64 {
+	return s
+}
+
+func TestCompactFilesPreservesSnapshotVersions(t *testing.T) {
+	sstDir, cfg, cleanup := setupCompactionTest(t)
+	defer cleanup()
+
+	type entry struct {
+		key    string
+		value  []byte
+		seqNum uint64
+	}
+
+	// Write versions of the same key across two L0 files
+	writeVersions := func(seq int, entries []entry) {
+		path := filepath.Join(sstDir, fmt.Sprintf("%d_%06d_%020d.sst", 0, seq, time.Now().UnixNano()))
+		writer, err := sstable.NewWriter(path)
+		if err != nil {
+			t.Fatalf("Failed to create SSTable writer: %v", err)
+		}
+		for _, e := range entries {
+			if err := writer.AddWithSequence([]byte(e.key), e.value, e.seqNum); err != nil {
+				t.Fatalf("Failed to add entry: %v", err)
+			}
+		}
+		if err := writer.Finish(); err != nil {
+			t.Fatalf("Failed to finish SSTable: %v", err)
+		}
+	}
+
+	writeVersions(1, []entry{
+		{"a", []byte("a1"), 1},
+		{"b", []byte("b2"), 2},
+	})
+	writeVersions(2, []entry{
+		{"a", []byte("a3"), 3},
+		{"a", []byte("a2"), 2}, // Shadowed without any snapshot in between
+		{"b", nil, 5},          // Deleted after the snapshot at 4
+	})
+
+	strategy := NewBaseCompactionStrategy(cfg, sstDir)
+	if err := strategy.LoadSSTables(); err != nil {
+		t.Fatalf("Failed to load SSTables: %v", err)
+	}
+
+	// A snapshot at 1 still needs a1, a snapshot at 4 still needs b2
+	executor := NewCompactionExecutor(cfg, sstDir, NewTombstoneTracker(24*time.Hour))
+	executor.SetSnapshotProvider(staticSnapshots{1, 4})
+
+	task := &CompactionTask{
+		InputFiles:         map[int][]*SSTableInfo{0: strategy.levels[0]},
+		TargetLevel:        1,
+		OutputPathTemplate: filepath.Join(sstDir, "%d_%06d_%020d.sst"),
+	}
+
+	outputFiles, err := executor.CompactFiles(task)
+	if err != nil {
+		t.Fatalf("Failed to compact files: %v", err)
+	}
+	if len(outputFiles) != 1 {
+		t.Fatalf("Expected 1 output file, got %d", len(outputFiles))
+	}
+
+	reader, err := sstable.OpenReader(outputFiles[0])
+	if err != nil {
+		t.Fatalf("Failed to open output SSTable: %v", err)
+	}
+	defer reader.Close()
+
+	expected := []entry{
+		{"a", []byte("a3"), 3},
+		{"a", []byte("a1"), 1},
+		{"b", nil, 5},
+		{"b", []byte("b2"), 2},
+	}
+
+	iter := reader.NewIterator()
+	i := 0
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		if i >= len(expected) {
+			t.Fatalf("Unexpected extra entry %s@%d", iter.Key(), iter.SequenceNumber())
+		}
+		want := expected[i]
+		if string(iter.Key()) != want.key || iter.SequenceNumber() != want.seqNum {
+			t.Errorf("Entry %d: expected %s@%d, got %s@%d", i, want.key, want.seqNum, iter.Key(), iter.SequenceNumber())
+		}
+		if want.value == nil && !iter.IsTombstone() {
+			t.Errorf("Entry %d: expected a tombstone", i)
+		} else if want.value != nil && !bytes.Equal(iter.Value(), want.value) {
+			t.Errorf("Entry %d: expected value %s, got %s", i, want.value, iter.Value())
+		}
+		i++
+	}
+	if i != len(expected) {
+		t.Errorf("Expected %d entries, got %d", len(expected), i)
+	}
+}
+
+func TestCompactFilesCollapsesMergeOperands(t *testing.T) {
+	sstDir, cfg, cleanup := setupCompactionTest(t)
+	defer cleanup()
+
+	// The value of a is written to the older file, the operands to the newer one
+	older := filepath.Join(sstDir, fmt.Sprintf("%d_%06d_%020d.sst", 0, 1, time.Now().UnixNano()))
+	writer, err := sstable.NewWriter(older)
+	if err != nil {
+		t.Fatalf("Failed to create SSTable writer: %v", err)
+	}
+	if err := writer.AddWithSequence([]byte("a"), []byte("10"), 1); err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish SSTable: %v", err)
+	}
+
+	newer := filepath.Join(sstDir, fmt.Sprintf("%d_%06d_%020d.sst", 0, 2, time.Now().UnixNano()))
+	writer, err = sstable.NewWriter(newer)
+	if err != nil {
+		t.Fatalf("Failed to create SSTable writer: %v", err)
+	}
+	for _, e := range []struct {
+		key     string
+		operand string
+		seqNum  uint64
+	}{
+		{"a", "5", 3},
+		{"a", "2", 2},
+		{"b", "7", 4}, // No base value in the compacted files
+	} {
+		if err := writer.AddMergeWithSequence([]byte(e.key), []byte(e.operand), e.seqNum); err != nil {
+			t.Fatalf("Failed to add merge operand: %v", err)
+		}
+	}
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish SSTable: %v", err)
+	}
+
+	strategy := NewBaseCompactionStrategy(cfg, sstDir)
+	if err := strategy.LoadSSTables(); err != nil {
+		t.Fatalf("Failed to load SSTables: %v", err)
+	}
+
+	executor := NewCompactionExecutor(cfg, sstDir, NewTombstoneTracker(24*time.Hour))
+	executor.SetMergeOperator(merge.CounterOperator{})
+
+	task := &CompactionTask{
+		InputFiles:         map[int][]*SSTableInfo{0: strategy.levels[0]},
+		TargetLevel:        1,
+		OutputPathTemplate: filepath.Join(sstDir, "%d_%06d_%020d.sst"),
+	}
+
+	outputFiles, err := executor.CompactFiles(task)
+	if err != nil {
+		t.Fatalf("Failed to compact files: %v", err)
+	}
+	if len(outputFiles) != 1 {
+		t.Fatalf("Expected 1 output file, got %d", len(outputFiles))
+	}
+
+	reader, err := sstable.OpenReader(outputFiles[0])
+	if err != nil {
+		t.Fatalf("Failed to open output SSTable: %v", err)
+	}
+	defer reader.Close()
+
+	expected := []struct {
+		key     string
+		value   string
+		seqNum  uint64
+		operand bool
+	}{
+		{"a", "17", 3, false},
+		{"b", "7", 4, true},
+	}
+
+	iter := reader.NewIterator()
+	i := 0
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		if i >= len(expected) {
+			t.Fatalf("Unexpected extra entry %s@%d", iter.Key(), iter.SequenceNumber())
+		}
+		want := expected[i]
+		if string(iter.Key()) != want.key || string(iter.Value()) != want.value || iter.SequenceNumber() != want.seqNum {
+			t.Errorf("Entry %d: expected %s=%s@%d, got %s=%s@%d", i, want.key, want.value, want.seqNum,
+				iter.Key(), iter.Value(), iter.SequenceNumber())
+		}
+		if iter.IsMergeOperand() != want.operand {
+			t.Errorf("Entry %d: expected merge operand %v, got %v", i, want.operand, iter.IsMergeOperand())
+		}
+		i++
+	}
+	if i != len(expected) {
+		t.Errorf("Expected %d entries, got %d", len(expected), i)
+	}
+}
+
+func TestCompactFilesDropsDuplicateWrites(t *testing.T) {
+	sstDir, cfg, cleanup := setupCompactionTest(t)
+	defer cleanup()
+
+	// The operand written at 3 was flushed to both files, on top of the value
+	// of a in the older one
+	for i, entries := range [][]struct {
+		value   string
+		seqNum  uint64
+		operand bool
+	}{
+		{{"5", 3, true}, {"10", 1, false}},
+		{{"5", 3, true}},
+	} {
+		path := filepath.Join(sstDir, fmt.Sprintf("%d_%06d_%020d.sst", 0, i+1, time.Now().UnixNano()))
+		writer, err := sstable.NewWriter(path)
+		if err != nil {
+			t.Fatalf("Failed to create SSTable writer: %v", err)
+		}
+		for _, e := range entries {
+			if e.operand {
+				err = writer.AddMergeWithSequence([]byte("a"), []byte(e.value), e.seqNum)
+			} else {
+				err = writer.AddWithSequence([]byte("a"), []byte(e.value), e.seqNum)
+			}
+			if err != nil {
+				t.Fatalf("Failed to add entry: %v", err)
+			}
+		}
+		if err := writer.Finish(); err != nil {
+			t.Fatalf("Failed to finish SSTable: %v", err)
+		}
+	}
+
+	strategy := NewBaseCompactionStrategy(cfg, sstDir)
+	if err := strategy.LoadSSTables(); err != nil {
+		t.Fatalf("Failed to load SSTables: %v", err)
+	}
+
+	executor := NewCompactionExecutor(cfg, sstDir, NewTombstoneTracker(24*time.Hour))
+	executor.SetMergeOperator(merge.CounterOperator{})
+
+	task := &CompactionTask{
+		InputFiles:         map[int][]*SSTableInfo{0: strategy.levels[0]},
+		TargetLevel:        1,
+		OutputPathTemplate: filepath.Join(sstDir, "%d_%06d_%020d.sst"),
+	}
+
+	outputFiles, err := executor.CompactFiles(task)
+	if err != nil {
+		t.Fatalf("Failed to compact files: %v", err)
+	}
+	if len(outputFiles) != 1 {
+		t.Fatalf("Expected 1 output file, got %d", len(outputFiles))
+	}
+
+	reader, err := sstable.OpenReader(outputFiles[0])
+	if err != nil {
+		t.Fatalf("Failed to open output SSTable: %v", err)
+	}
+	defer reader.Close()
+
+	// The operand is only applied once
+	value, err := reader.Get([]byte("a"))
+	if err != nil {
+		t.Fatalf("Failed to get a: %v", err)
+	}
+	if string(value) != "15" {
+		t.Errorf("Expected a=15, got %s", value)
+	}
+}
+
+func TestCompactFilesAppliesRangeTombstones(t *testing.T) {
+	sstDir, cfg, cleanup := setupCompactionTest(t)
+	defer cleanup()
+	cfg.MaxLevelWithTombstones = 0
+
+	older := filepath.Join(sstDir, fmt.Sprintf("%d_%06d_%020d.sst", 0, 1, time.Now().UnixNano()))
+	writer, err := sstable.NewWriter(older)
+	if err != nil {
+		t.Fatalf("Failed to create SSTable writer: %v", err)
+	}
+	for i, key := range []string{"a", "b", "c", "d"} {
+		if err := writer.AddWithSequence([]byte(key), []byte(key+"1"), uint64(i+1)); err != nil {
+			t.Fatalf("Failed to add entry: %v", err)
+		}
+	}
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish SSTable: %v", err)
+	}
+
+	newer := filepath.Join(sstDir, fmt.Sprintf("%d_%06d_%020d.sst", 0, 2, time.Now().UnixNano()))
+	writer, err = sstable.NewWriter(newer)
+	if err != nil {
+		t.Fatalf("Failed to create SSTable writer: %v", err)
+	}
+	if err := writer.AddWithSequence([]byte("c"), []byte("c2"), 6); err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+	if err := writer.AddRangeTombstone([]byte("b"), []byte("d"), 5); err != nil {
+		t.Fatalf("Failed to add range tombstone: %v", err)
+	}
+	// Covers nothing, so it can be dropped
+	if err := writer.AddRangeTombstone([]byte("x"), []byte("z"), 7); err != nil {
+		t.Fatalf("Failed to add range tombstone: %v", err)
+	}
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish SSTable: %v", err)
+	}
+
+	strategy := NewBaseCompactionStrategy(cfg, sstDir)
+	if err := strategy.LoadSSTables(); err != nil {
+		t.Fatalf("Failed to load SSTables: %v", err)
+	}
+
+	// The snapshot at 3 still sees the versions the range tombstone deletes
+	executor := NewCompactionExecutor(cfg, sstDir, nil)
+	executor.SetSnapshotProvider(staticSnapshots{3})
+
+	task := &CompactionTask{
+		InputFiles:         map[int][]*SSTableInfo{0: strategy.levels[0]},
+		TargetLevel:        1,
+		OutputPathTemplate: filepath.Join(sstDir, "%d_%06d_%020d.sst"),
+	}
+
+	outputFiles, err := executor.CompactFiles(task)
+	if err != nil {
+		t.Fatalf("Failed to compact files: %v", err)
+	}
+	if len(outputFiles) != 1 {
+		t.Fatalf("Expected 1 output file, got %d", len(outputFiles))
+	}
+
+	reader, err := sstable.OpenReader(outputFiles[0])
+	if err != nil {
+		t.Fatalf("Failed to open output SSTable: %v", err)
+	}
+	defer reader.Close()
+
+	expected := []struct {
+		key    string
+		value  string
+		seqNum uint64
+	}{
+		{"a", "a1", 1},
+		{"b", "b1", 2},
+		{"c", "c2", 6},
+		{"c", "c1", 3},
+		{"d", "d1", 4},
+	}
+
+	iter := reader.NewIterator()
+	i := 0
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		if i >= len(expected) {
+			t.Fatalf("Unexpected extra entry %s@%d", iter.Key(), iter.SequenceNumber())
+		}
+		want := expected[i]
+		if string(iter.Key()) != want.key || string(iter.Value()) != want.value || iter.SequenceNumber() != want.seqNum {
+			t.Errorf("Entry %d: expected %s=%s@%d, got %s=%s@%d", i, want.key, want.value, want.seqNum,
+				iter.Key(), iter.Value(), iter.SequenceNumber())
+		}
+		i++
+	}
+	if i != len(expected) {
+		t.Errorf("Expected %d entries, got %d", len(expected), i)
+	}
+
+	tombstones := reader.RangeTombstones()
+	if len(tombstones) != 1 || string(tombstones[0].Start) != "b" || string(tombstones[0].End) != "d" || tombstones[0].SeqNum != 5 {
+		t.Errorf("Expected only the range tombstone [b, d)@5 to be kept, got %+v", tombstones)
+	}
+
+	// Without the snapshot the deleted versions and the range tombstone are dropped
+	executor.SetSnapshotProvider(staticSnapshots{})
+	outputFiles, err = executor.CompactFiles(task)
+	if err != nil {
+		t.Fatalf("Failed to compact files: %v", err)
+	}
+	if len(outputFiles) != 1 {
+		t.Fatalf("Expected 1 output file, got %d", len(outputFiles))
+	}
+
+	reader, err = sstable.OpenReader(outputFiles[0])
+	if err != nil {
+		t.Fatalf("Failed to open output SSTable: %v", err)
+	}
+	defer reader.Close()
+
+	var keys []string
+	iter = reader.NewIterator()
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		keys = append(keys, fmt.Sprintf("%s@%d", iter.Key(), iter.SequenceNumber()))
+	}
+	if fmt.Sprint(keys) != "[a@1 c@6 d@4]" {
+		t.Errorf("Expected entries [a@1 c@6 d@4], got %v", keys)
+	}
+	if len(reader.RangeTombstones()) != 0 {
+		t.Errorf("Expected no range tombstones, got %+v", reader.RangeTombstones())
+	}
+}
+
+func TestCompactFilesDropsExpiredValues(t *testing.T) {
+	sstDir, cfg, cleanup := setupCompactionTest(t)
+	defer cleanup()
+	cfg.MaxLevelWithTombstones = 0
+
+	past := uint64(time.Now().Add(-time.Minute).UnixNano())
+	future := uint64(time.Now().Add(time.Hour).UnixNano())
+
+	path := filepath.Join(sstDir, fmt.Sprintf("%d_%06d_%020d.sst", 0, 1, time.Now().UnixNano()))
+	writer, err := sstable.NewWriter(path)
+	if err != nil {
+		t.Fatalf("Failed to create SSTable writer: %v", err)
+	}
+	if err := writer.AddExpiringWithSequence([]byte("a"), []byte("a2"), 4, past); err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+	if err := writer.AddWithSequence([]byte("a"), []byte("a1"), 1); err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+	if err := writer.AddExpiringWithSequence([]byte("b"), []byte("b1"), 2, future); err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+	if err := writer.AddWithSequence([]byte("c"), []byte("c1"), 3); err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish SSTable: %v", err)
+	}
+
+	strategy := NewBaseCompactionStrategy(cfg, sstDir)
+	if err := strategy.LoadSSTables(); err != nil {
+		t.Fatalf("Failed to load SSTables: %v", err)
+	}
+
+	executor := NewCompactionExecutor(cfg, sstDir, nil)
+	task := &CompactionTask{
+		InputFiles:         map[int][]*SSTableInfo{0: strategy.levels[0]},
+		TargetLevel:        1,
+		OutputPathTemplate: filepath.Join(sstDir, "%d_%06d_%020d.sst"),
+	}
+
+	outputFiles, err := executor.CompactFiles(task)
+	if err != nil {
+		t.Fatalf("Failed to compact files: %v", err)
+	}
+	if len(outputFiles) != 1 {
+		t.Fatalf("Expected 1 output file, got %d", len(outputFiles))
+	}
+
+	reader, err := sstable.OpenReader(outputFiles[0])
+	if err != nil {
+		t.Fatalf("Failed to open output SSTable: %v", err)
+	}
+	defer reader.Close()
+
+	// The expired value and the version it shadowed are gone, the live
+	// expiring value keeps its expiry time
+	var entries []string
+	iter := reader.NewIterator()
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		entries = append(entries, fmt.Sprintf("%s@%d", iter.Key(), iter.SequenceNumber()))
+		if string(iter.Key()) == "b" && iter.ExpireAt() != future {
+			t.Errorf("Expected b to expire at %d, got %d", future, iter.ExpireAt())
+		}
+	}
+	if fmt.Sprint(entries) != "[b@2 c@3]" {
+		t.Errorf("Expected entries [b@2 c@3], got %v", entries)
+	}
+}
+
+func TestCompactFilesAppliesCompactionFilter(t *testing.T) {
+	sstDir, cfg, cleanup := setupCompactionTest(t)
+	defer cleanup()
+
+	path := filepath.Join(sstDir, fmt.Sprintf("%d_%06d_%020d.sst", 0, 1, time.Now().UnixNano()))
+	writer, err := sstable.NewWriter(path)
+	if err != nil {
+		t.Fatalf("Failed to create SSTable writer: %v", err)
+	}
+	for _, e := range []struct {
+		key, value string
+		seqNum     uint64
+	}{
+		{"tenant1:a", "a2", 3},
+		{"tenant1:a", "a1", 1},
+		{"tenant2:b", `{"v":1,"deprecated":true}`, 4},
+		{"tenant2:c", "c1", 2},
+	} {
+		if err := writer.AddWithSequence([]byte(e.key), []byte(e.value), e.seqNum); err != nil {
+			t.Fatalf("Failed to add entry: %v", err)
+		}
+	}
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish SSTable: %v", err)
+	}
+
+	strategy := NewBaseCompactionStrategy(cfg, sstDir)
+	if err := strategy.LoadSSTables(); err != nil {
+		t.Fatalf("Failed to load SSTables: %v", err)
+	}
+
+	// Drop the keys of tenant1 and tenant2:c, strip the deprecated field
+	var levels []int
+	executor := NewCompactionExecutor(cfg, sstDir, nil)
+	executor.SetCompactionFilter(CompactionFilterFunc(func(level int, key, value []byte) (FilterDecision, []byte) {
+		levels = append(levels, level)
+		switch {
+		case bytes.HasPrefix(key, []byte("tenant1:")), string(key) == "tenant2:c":
+			return FilterDrop, nil
+		case bytes.Contains(value, []byte(`,"deprecated":true`)):
+			return FilterChangeValue, bytes.Replace(value, []byte(`,"deprecated":true`), nil, 1)
+		default:
+			return FilterKeep, nil
+		}
+	}))
+
+	// A snapshot at 2 can still read a1 and c1
+	executor.SetSnapshotProvider(staticSnapshots{2})
+
+	task := &CompactionTask{
+		InputFiles:         map[int][]*SSTableInfo{0: strategy.levels[0]},
+		TargetLevel:        1,
+		OutputPathTemplate: filepath.Join(sstDir, "%d_%06d_%020d.sst"),
+	}
+
+	outputFiles, err := executor.CompactFiles(task)
+	if err != nil {
+		t.Fatalf("Failed to compact files: %v", err)
+	}
+	if len(outputFiles) != 1 {
+		t.Fatalf("Expected 1 output file, got %d", len(outputFiles))
+	}
+
+	reader, err := sstable.OpenReader(outputFiles[0])
+	if err != nil {
+		t.Fatalf("Failed to open output SSTable: %v", err)
+	}
+	defer reader.Close()
+
+	// The dropped value becomes a deletion hiding a1 from new reads, while
+	// the values the snapshot can read are left alone
+	var entries []string
+	iter := reader.NewIterator()
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		value := string(iter.Value())
+		if iter.IsTombstone() {
+			value = "<deleted>"
+		}
+		entries = append(entries, fmt.Sprintf("%s@%d=%s", iter.Key(), iter.SequenceNumber(), value))
+	}
+	expected := `[tenant1:a@3=<deleted> tenant1:a@1=a1 tenant2:b@4={"v":1} tenant2:c@2=c1]`
+	if fmt.Sprint(entries) != expected {
+		t.Errorf("Expected entries %s, got %v", expected, entries)
+	}
+
+	if fmt.Sprint(levels) != "[1 1]" {
+		t.Errorf("Expected the filter to be called twice for level 1, got %v", levels)
+	}
+
+	last, total := executor.FilterStats()
+	if last != (CompactionFilterStats{Dropped: 1, Changed: 1}) || total != last {
+		t.Errorf("Expected 1 value dropped and 1 changed, got %+v and %+v", last, total)
+	}
+}
+
+func TestCompactFilesFilterDropsOlderVersions(t *testing.T) {
+	tests := []struct {
+		name      string
+		snapshots staticSnapshots
+		expected  string
+	}{
+		// Nothing is left for the lower levels to hide
+		{"NoSnapshots", nil, "[]"},
+		// Only the version the snapshot reads stays under the deletion
+		{"Snapshot", staticSnapshots{3}, "[k@5=<deleted> k@3=v3]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sstDir, cfg, cleanup := setupCompactionTest(t)
+			defer cleanup()
+
+			path := filepath.Join(sstDir, fmt.Sprintf("%d_%06d_%020d.sst", 0, 1, time.Now().UnixNano()))
+			writer, err := sstable.NewWriter(path)
+			if err != nil {
+				t.Fatalf("Failed to create SSTable writer: %v", err)
+			}
+			for _, seqNum := range []uint64{5, 4, 3, 1} {
+				if err := writer.AddWithSequence([]byte("k"), []byte(fmt.Sprintf("v%d", seqNum)), seqNum); err != nil {
+					t.Fatalf("Failed to add entry: %v", err)
+				}
+			}
+			if err := writer.Finish(); err != nil {
+				t.Fatalf("Failed to finish SSTable: %v", err)
+			}
+
+			strategy := NewBaseCompactionStrategy(cfg, sstDir)
+			if err := strategy.LoadSSTables(); err != nil {
+				t.Fatalf("Failed to load SSTables: %v", err)
+			}
+
+			executor := NewCompactionExecutor(cfg, sstDir, nil)
+			executor.SetCompactionFilter(CompactionFilterFunc(func(level int, key, value []byte) (FilterDecision, []byte) {
+				return FilterDrop, nil
+			}))
+			executor.SetSnapshotProvider(tt.snapshots)
+
+			task := &CompactionTask{
+				InputFiles:         map[int][]*SSTableInfo{0: strategy.levels[0]},
+				TargetLevel:        cfg.MaxLevelWithTombstones + 1,
+				OutputPathTemplate: filepath.Join(sstDir, "%d_%06d_%020d.sst"),
+			}
+
+			outputFiles, err := executor.CompactFiles(task)
+			if err != nil {
+				t.Fatalf("Failed to compact files: %v", err)
+			}
+
+			var entries []string
+			for _, file := range outputFiles {
+				reader, err := sstable.OpenReader(file)
+				if err != nil {
+					t.Fatalf("Failed to open output SSTable: %v", err)
+				}
+				iter := reader.NewIterator()
+				for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+					value := string(iter.Value())
+					if iter.IsTombstone() {
+						value = "<deleted>"
+					}
+					entries = append(entries, fmt.Sprintf("%s@%d=%s", iter.Key(), iter.SequenceNumber(), value))
+				}
+				reader.Close()
+			}
+			if fmt.Sprint(entries) != tt.expected {
+				t.Errorf("Expected entries %s, got %v", tt.expected, entries)
+			}
+		})
+	}
+}
+
+func TestCompactFilesSubcompactions(t *testing.T) {
+	sstDir, cfg, cleanup := setupCompactionTest(t)
+	defer cleanup()
+
+	// About 2MB of old versions, half of which are overwritten by newer ones.
+	// Values are random so that they don't compress.
+	rng := rand.New(rand.NewSource(1))
+	value := func(prefix string) []byte {
+		v := make([]byte, 100)
+		rng.Read(v)
+		return append([]byte(prefix), v...)
+	}
+	older := filepath.Join(sstDir, fmt.Sprintf("%d_%06d_%020d.sst", 0, 1, time.Now().UnixNano()))
+	writer, err := sstable.NewWriter(older)
+	if err != nil {
+		t.Fatalf("Failed to create SSTable writer: %v", err)
+	}
+	for i := 0; i < 16000; i++ {
+		key := []byte(fmt.Sprintf("key-%05d", i))
+		if err := writer.AddWithSequence(key, value("old-"), uint64(i+1)); err != nil {
+			t.Fatalf("Failed to add entry: %v", err)
+		}
+	}
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish SSTable: %v", err)
+	}
+
+	newer := filepath.Join(sstDir, fmt.Sprintf("%d_%06d_%020d.sst", 0, 2, time.Now().UnixNano()))
+	writer, err = sstable.NewWriter(newer)
+	if err != nil {
+		t.Fatalf("Failed to create SSTable writer: %v", err)
+	}
+	for i := 0; i < 16000; i += 2 {
+		key := []byte(fmt.Sprintf("key-%05d", i))
+		if err := writer.AddWithSequence(key, value("new-"), uint64(40000+i)); err != nil {
+			t.Fatalf("Failed to add entry: %v", err)
+		}
+	}
+	// Spans several subcompactions
+	if err := writer.AddRangeTombstone([]byte("key-01000"), []byte("key-07000"), 30000); err != nil {
+		t.Fatalf("Failed to add range tombstone: %v", err)
+	}
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish SSTable: %v", err)
+	}
+
+	strategy := NewBaseCompactionStrategy(cfg, sstDir)
+	if err := strategy.LoadSSTables(); err != nil {
+		t.Fatalf("Failed to load SSTables: %v", err)
+	}
+	defer strategy.Close()
+
+	executor := NewCompactionExecutor(cfg, sstDir, nil)
+	task := &CompactionTask{
+		InputFiles:         map[int][]*SSTableInfo{0: strategy.levels[0]},
+		TargetLevel:        1,
+		OutputPathTemplate: filepath.Join(sstDir, "%d_%06d_%020d.sst"),
+	}
+
+	// compact returns the entries and range tombstones of the outputs, checking
+	// that the outputs don't overlap
+	compact := func() ([]string, []string, int) {
+		outputFiles, err := executor.CompactFiles(task)
+		if err != nil {
+			t.Fatalf("Failed to compact files: %v", err)
+		}
+
+		var entries, tombstones []string
+		var lastKey []byte
+		for _, path := range outputFiles {
+			reader, err := sstable.OpenReader(path)
+			if err != nil {
+				t.Fatalf("Failed to open output SSTable: %v", err)
+			}
+			if first, _ := reader.KeyRange(); lastKey != nil && bytes.Compare(first, lastKey) <= 0 {
+				t.Errorf("Output %s starts at %s, before the end of the previous output %s", path, first, lastKey)
+			}
+			iter := reader.NewIterator()
+			for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+				entries = append(entries, fmt.Sprintf("%s@%d=%.4s", iter.Key(), iter.SequenceNumber(), iter.Value()))
+			}
+			_, lastKey = reader.KeyRange()
+			for _, ts := range reader.RangeTombstones() {
+				tombstones = append(tombstones, fmt.Sprintf("[%s, %s)@%d", ts.Start, ts.End, ts.SeqNum))
+			}
+			reader.Close()
+			os.Remove(path)
+		}
+		return entries, tombstones, len(outputFiles)
+	}
+
+	cfg.SSTableMaxSize = 256 * 1024
+	cfg.CompactionThreads = 1
+	serialEntries, serialTombstones, serialOutputs := compact()
+
+	cfg.CompactionThreads = 4
+	if bounds := executor.subcompactionBounds([]*sstable.Reader{strategy.levels[0][0].Reader, strategy.levels[0][1].Reader}); len(bounds) != 3 {
+		t.Fatalf("Expected the task to be split in 4, got bounds %q", bounds)
+	}
+	parallelEntries, parallelTombstones, parallelOutputs := compact()
+
+	// Subcompactions write the same entries, in more files. Only the newest
+	// version of each key is kept, and the odd keys in the range are deleted.
+	if len(serialEntries) != 16000-3000 {
+		t.Errorf("Expected %d entries, got %d", 16000-3000, len(serialEntries))
+	}
+	if fmt.Sprint(parallelEntries) != fmt.Sprint(serialEntries) {
+		t.Errorf("Expected subcompactions to write the same entries")
+	}
+	if parallelOutputs < serialOutputs || parallelOutputs < 4 {
+		t.Errorf("Expected at least %d outputs, got %d", max(serialOutputs, 4), parallelOutputs)
+	}
+
+	// The range tombstone is split between the subcompactions it spans
+	if fmt.Sprint(serialTombstones) != "[[key-01000, key-07000)@30000]" {
+		t.Errorf("Expected the range tombstone in one piece, got %v", serialTombstones)
+	}
+	if len(parallelTombstones) < 2 ||
+		!strings.HasPrefix(parallelTombstones[0], "[key-01000, ") ||
+		!strings.HasSuffix(parallelTombstones[len(parallelTombstones)-1], ", key-07000)@30000") {
+		t.Errorf("Expected the range tombstone to be split, got %v", parallelTombstones)
+	}
+}
+
+func TestCompactionCommitsToVersionLog(t *testing.T) {
+	sstDir, cfg, cleanup := setupCompactionTest(t)
+	defer cleanup()
+
+	versions, err := versionlog.Open(sstDir)
+	if err != nil {
+		t.Fatalf("Failed to open version log: %v", err)
+	}
+	defer versions.Close()
+
+	// Three committed files in L0, all merged by the tiered strategy
+	cfg.MaxMemTables = 3
+	timestamp := time.Now().UnixNano()
+	var inputs []string
+	for i, key := range []string{"a", "b", "c"} {
+		path := createTestSSTable(t, sstDir, 0, i+1, timestamp+int64(i), map[string]string{key: "value"})
+		reader, err := sstable.OpenReader(path)
+		if err != nil {
+			t.Fatalf("Failed to open SSTable: %v", err)
+		}
+		file, err := versionlog.Describe(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatalf("Failed to describe SSTable: %v", err)
+		}
+		file.LargestSequence = uint64(i + 10)
+		if err := versions.Apply(versionlog.Edit{Added: []versionlog.FileMeta{file}}); err != nil {
+			t.Fatalf("Failed to commit SSTable: %v", err)
+		}
+		inputs = append(inputs, path)
+	}
+
+	// Files that aren't in the version log aren't compacted
+	stray := createTestSSTable(t, sstDir, 0, 4, timestamp+3, map[string]string{"d": "value"})
+
+	coordinator := NewCompactionCoordinator(cfg, sstDir, CompactionCoordinatorOptions{VersionLog: versions})
+	if err := coordinator.TriggerCompaction(); err != nil {
+		t.Fatalf("Failed to trigger compaction: %v", err)
+	}
+	defer coordinator.strategy.Close()
+
+	// The outputs replaced the inputs in the version and on disk
+	files := versions.Current().Files()
+	if len(files) == 0 {
+		t.Fatal("Expected compaction outputs in the version")
+	}
+	for _, file := range files {
+		if file.Level != 1 {
+			t.Errorf("Expected only L1 outputs, got %s in L%d", file.Name, file.Level)
+		}
+		if !bytes.Equal(file.Smallest, []byte("a")) || !bytes.Equal(file.Largest, []byte("c")) {
+			t.Errorf("Expected the output to span [a, c], got [%s, %s]", file.Smallest, file.Largest)
+		}
+		if file.LargestSequence != 12 {
+			t.Errorf("Expected the output to keep the largest sequence of the inputs, got %d", file.LargestSequence)
+		}
+	}
+	for _, path := range inputs {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected input %s to be deleted, got %v", filepath.Base(path), err)
+		}
+	}
+	if _, err := os.Stat(stray); err != nil {
+		t.Errorf("Expected the stray file to be left alone, got %v", err)
+	}
+}
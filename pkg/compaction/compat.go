@@ -18,8 +18,8 @@ func NewCompactionManager(cfg *config.Config, sstableDir string) *DefaultCompact
 	// Create compaction executor
 	executor := NewCompactionExecutor(cfg, sstableDir, tombstones)
 
-	// Create tiered compaction strategy
-	strategy := NewTieredCompactionStrategy(cfg, sstableDir, executor)
+	// Create the configured compaction strategy
+	strategy := NewCompactionStrategy(cfg, sstableDir, executor)
 
 	// Return the new coordinator
 	return NewCompactionCoordinator(cfg, sstableDir, CompactionCoordinatorOptions{
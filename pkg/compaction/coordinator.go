@@ -2,10 +2,14 @@ package compaction
 
 import (
 	"fmt"
+	"path/filepath"
 	"sync"
 	"time"
 
+	"github.com/KevoDB/kevo/pkg/common/merge"
+	"github.com/KevoDB/kevo/pkg/common/ratelimit"
 	"github.com/KevoDB/kevo/pkg/config"
+	"github.com/KevoDB/kevo/pkg/versionlog"
 )
 
 // CompactionCoordinatorOptions holds configuration options for the coordinator
@@ -22,6 +26,26 @@ type CompactionCoordinatorOptions struct {
 	// Tombstone manager
 	TombstoneManager TombstoneManager
 
+	// Provider of live snapshots whose versions must survive compaction
+	Snapshots SnapshotProvider
+
+	// Merge operator used to combine merge operands
+	MergeOperator merge.Operator
+
+	// Application filter run on the values being compacted
+	CompactionFilter CompactionFilter
+
+	// Limiter the outputs are written within
+	RateLimiter *ratelimit.Limiter
+
+	// Log of the live files compactions are committed to. Without one, the
+	// files are found by scanning the SSTable directory.
+	VersionLog *versionlog.Log
+
+	// Storage reloaded once files are dropped or compacted by range, so its
+	// reads stop seeing them and their space is freed
+	Reloader SSTableReloader
+
 	// Compaction interval in seconds
 	CompactionInterval int64
 }
@@ -46,6 +70,12 @@ type DefaultCompactionCoordinator struct {
 	// Tombstone manager
 	tombstoneManager TombstoneManager
 
+	// Log of the live files, nil for none
+	versions *versionlog.Log
+
+	// Storage reloaded after files are removed, nil for none
+	reloader SSTableReloader
+
 	// Next sequence number for SSTable files
 	nextSeq uint64
 
@@ -77,8 +107,44 @@ func NewCompactionCoordinator(cfg *config.Config, sstableDir string, options Com
 		options.Executor = NewCompactionExecutor(cfg, sstableDir, options.TombstoneManager)
 	}
 
+	if options.Snapshots != nil {
+		if executor, ok := options.Executor.(interface{ SetSnapshotProvider(SnapshotProvider) }); ok {
+			executor.SetSnapshotProvider(options.Snapshots)
+		}
+	}
+
+	if options.MergeOperator != nil {
+		if executor, ok := options.Executor.(interface{ SetMergeOperator(merge.Operator) }); ok {
+			executor.SetMergeOperator(options.MergeOperator)
+		}
+	}
+
+	if options.CompactionFilter != nil {
+		if executor, ok := options.Executor.(interface{ SetCompactionFilter(CompactionFilter) }); ok {
+			executor.SetCompactionFilter(options.CompactionFilter)
+		}
+	}
+
+	if options.RateLimiter != nil {
+		if executor, ok := options.Executor.(interface{ SetRateLimiter(*ratelimit.Limiter) }); ok {
+			executor.SetRateLimiter(options.RateLimiter)
+		}
+	}
+
+	if options.VersionLog != nil {
+		if executor, ok := options.Executor.(interface{ SetVersionLog(*versionlog.Log) }); ok {
+			executor.SetVersionLog(options.VersionLog)
+		}
+	}
+
 	if options.Strategy == nil {
-		options.Strategy = NewTieredCompactionStrategy(cfg, sstableDir, options.Executor)
+		options.Strategy = NewCompactionStrategy(cfg, sstableDir, options.Executor)
+	}
+
+	if options.VersionLog != nil {
+		if strategy, ok := options.Strategy.(interface{ SetVersionLog(*versionlog.Log) }); ok {
+			strategy.SetVersionLog(options.VersionLog)
+		}
 	}
 
 	if options.CompactionInterval <= 0 {
@@ -92,6 +158,8 @@ func NewCompactionCoordinator(cfg *config.Config, sstableDir string, options Com
 		executor:              options.Executor,
 		fileTracker:           options.FileTracker,
 		tombstoneManager:      options.TombstoneManager,
+		versions:              options.VersionLog,
+		reloader:              options.Reloader,
 		nextSeq:               1,
 		stopCh:                make(chan struct{}),
 		lastCompactionOutputs: make([]string, 0),
@@ -99,6 +167,21 @@ func NewCompactionCoordinator(cfg *config.Config, sstableDir string, options Com
 	}
 }
 
+// NewCompactionStrategy creates the compaction strategy selected by the
+// CompactionStyle of the configuration, tiered by default
+func NewCompactionStrategy(cfg *config.Config, sstableDir string, executor CompactionExecutor) CompactionStrategy {
+	switch cfg.CompactionStyle {
+	case config.CompactionStyleLeveled:
+		return NewLeveledCompactionStrategy(cfg, sstableDir, executor)
+	case config.CompactionStyleFIFO:
+		return NewFIFOCompactionStrategy(cfg, sstableDir, executor)
+	case config.CompactionStyleTimeWindow:
+		return NewTimeWindowCompactionStrategy(cfg, sstableDir, executor)
+	default:
+		return NewTieredCompactionStrategy(cfg, sstableDir, executor)
+	}
+}
+
 // Start begins background compaction
 func (c *DefaultCompactionCoordinator) Start() error {
 	c.compactingMu.Lock()
@@ -234,8 +317,13 @@ func (c *DefaultCompactionCoordinator) runCompactionCycle() error {
 		}
 	}
 
-	// Perform compaction
-	outputFiles, err := c.executor.CompactFiles(task)
+	// Perform compaction, files that are only dropped need no merging
+	var outputFiles []string
+	if !task.Drop {
+		outputFiles, err = c.executor.CompactFiles(task)
+	} else if err = c.commitDrop(task); err == nil {
+		err = c.reloadSSTables()
+	}
 
 	// Unmark files as pending
 	for _, files := range task.InputFiles {
@@ -268,6 +356,18 @@ func (c *DefaultCompactionCoordinator) runCompactionCycle() error {
 	return c.fileTracker.CleanupObsoleteFiles()
 }
 
+// commitDrop records in the version log that the input files of a task are
+// removed
+func (c *DefaultCompactionCoordinator) commitDrop(task *CompactionTask) error {
+	var edit versionlog.Edit
+	for _, files := range task.InputFiles {
+		for _, file := range files {
+			edit.Removed = append(edit.Removed, filepath.Base(file.Path))
+		}
+	}
+	return c.versions.Apply(edit)
+}
+
 // TriggerCompaction forces a compaction cycle
 func (c *DefaultCompactionCoordinator) TriggerCompaction() error {
 	c.compactingMu.Lock()
@@ -287,7 +387,30 @@ func (c *DefaultCompactionCoordinator) CompactRange(minKey, maxKey []byte) error
 	}
 
 	// Delegate to the strategy for actual compaction
-	return c.strategy.CompactRange(minKey, maxKey)
+	if err := c.strategy.CompactRange(minKey, maxKey); err != nil {
+		return err
+	}
+	return c.reloadSSTables()
+}
+
+// reloadSSTables has the storage reload its files after some were removed
+func (c *DefaultCompactionCoordinator) reloadSSTables() error {
+	if c.reloader == nil {
+		return nil
+	}
+	if err := c.reloader.ReloadSSTables(); err != nil {
+		return fmt.Errorf("failed to reload SSTables: %w", err)
+	}
+	return nil
+}
+
+// RunExclusive calls fn while no compaction is running, for changes to the
+// live files that compactions mustn't interleave with
+func (c *DefaultCompactionCoordinator) RunExclusive(fn func() error) error {
+	c.compactingMu.Lock()
+	defer c.compactingMu.Unlock()
+
+	return fn()
 }
 
 // GetCompactionStats returns statistics about the compaction state
@@ -305,5 +428,16 @@ func (c *DefaultCompactionCoordinator) GetCompactionStats() map[string]interface
 		stats["last_outputs"] = c.lastCompactionOutputs
 	}
 
+	// Include what the compaction filter did
+	if executor, ok := c.executor.(interface {
+		FilterStats() (CompactionFilterStats, CompactionFilterStats)
+	}); ok {
+		last, total := executor.FilterStats()
+		stats["last_filter_dropped"] = last.Dropped
+		stats["last_filter_changed"] = last.Changed
+		stats["filter_dropped"] = total.Dropped
+		stats["filter_changed"] = total.Changed
+	}
+
 	return stats
 }
@@ -4,12 +4,19 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/KevoDB/kevo/pkg/common/iterator"
-	"github.com/KevoDB/kevo/pkg/common/iterator/composite"
+	"github.com/KevoDB/kevo/pkg/common/merge"
+	"github.com/KevoDB/kevo/pkg/common/rangedel"
+	"github.com/KevoDB/kevo/pkg/common/ratelimit"
+	"github.com/KevoDB/kevo/pkg/common/snapshot"
 	"github.com/KevoDB/kevo/pkg/config"
 	"github.com/KevoDB/kevo/pkg/sstable"
+	"github.com/KevoDB/kevo/pkg/versionlog"
 )
 
 // DefaultCompactionExecutor handles the actual compaction process
@@ -22,6 +29,26 @@ type DefaultCompactionExecutor struct {
 
 	// Tombstone manager for tracking deletions
 	tombstoneManager TombstoneManager
+
+	// Live snapshots whose versions must be preserved
+	snapshots SnapshotProvider
+
+	// Merge operator used to combine merge operands
+	mergeOperator merge.Operator
+
+	// Application filter run on the values being compacted
+	filter CompactionFilter
+
+	// Limits the write rate of the outputs, nil for no limit
+	rateLimiter *ratelimit.Limiter
+
+	// Log the outputs and removed inputs are committed to, nil for none
+	versions *versionlog.Log
+
+	// Values the filter dropped or changed, in the last compaction and in total
+	filterStatsMu   sync.Mutex
+	lastFilterStats CompactionFilterStats
+	filterStats     CompactionFilterStats
 }
 
 // NewCompactionExecutor creates a new compaction executor
@@ -33,30 +60,271 @@ func NewCompactionExecutor(cfg *config.Config, sstableDir string, tombstoneManag
 	}
 }
 
-// CompactFiles performs the actual compaction of the input files
+// SetSnapshotProvider sets the source of live snapshots to respect during compaction
+func (e *DefaultCompactionExecutor) SetSnapshotProvider(snapshots SnapshotProvider) {
+	e.snapshots = snapshots
+}
+
+// SetMergeOperator sets the merge operator used to combine merge operands during compaction
+func (e *DefaultCompactionExecutor) SetMergeOperator(op merge.Operator) {
+	e.mergeOperator = op
+}
+
+// SetCompactionFilter sets the filter run on the values being compacted
+func (e *DefaultCompactionExecutor) SetCompactionFilter(filter CompactionFilter) {
+	e.filter = filter
+}
+
+// SetRateLimiter sets the limiter the outputs are written within. Compactions
+// wait for it with low priority, behind flushes.
+func (e *DefaultCompactionExecutor) SetRateLimiter(limiter *ratelimit.Limiter) {
+	e.rateLimiter = limiter
+}
+
+// SetVersionLog sets the version log compactions are committed to. The outputs
+// of a compaction replace its inputs in a single edit, before the inputs are
+// deleted.
+func (e *DefaultCompactionExecutor) SetVersionLog(versions *versionlog.Log) {
+	e.versions = versions
+}
+
+// FilterStats returns the number of values the compaction filter dropped or
+// changed in the last compaction and since the executor was created
+func (e *DefaultCompactionExecutor) FilterStats() (last, total CompactionFilterStats) {
+	e.filterStatsMu.Lock()
+	defer e.filterStatsMu.Unlock()
+
+	return e.lastFilterStats, e.filterStats
+}
+
+// minSubcompactionBlocks is the fewest data blocks of the inputs, of about
+// sstable.IndexKeyInterval bytes each before compression, a subcompaction merges
+const minSubcompactionBlocks = 8
+
+// subcompaction merges the part of a compaction task in [start, end). Nil
+// bounds are unbounded.
+type subcompaction struct {
+	start, end []byte
+
+	// Range tombstones of the inputs, clipped to the range
+	tombstones []rangedel.Tombstone
+
+	// Results
+	outputs     []string
+	filterStats CompactionFilterStats
+	err         error
+}
+
+// CompactFiles performs the actual compaction of the input files. Large tasks
+// are split into key ranges merged in parallel by up to CompactionThreads
+// subcompactions. Their outputs are returned together, in
+// key order, or removed if any of them fails.
 func (e *DefaultCompactionExecutor) CompactFiles(task *CompactionTask) ([]string, error) {
-	// Create a merged iterator over all input files
-	var iterators []iterator.Iterator
+	// Readers of all input files, newest first
+	var readers []*sstable.Reader
+	var tombstones []rangedel.Tombstone
 
-	// Add iterators from both levels
+	// Add readers from both levels, newest files first
 	for level := 0; level <= task.TargetLevel; level++ {
-		for _, file := range task.InputFiles[level] {
-			// We need an iterator that preserves delete markers
-			if file.Reader != nil {
-				iterators = append(iterators, file.Reader.NewIterator())
+		files := task.InputFiles[level]
+		for i := len(files) - 1; i >= 0; i-- {
+			if files[i].Reader != nil {
+				readers = append(readers, files[i].Reader)
+				tombstones = append(tombstones, files[i].Reader.RangeTombstones()...)
 			}
 		}
 	}
+	tombstones = uniqueTombstones(tombstones)
+
+	// Older versions are only kept while an open snapshot can still see them
+	var liveSnapshots []uint64
+	if e.snapshots != nil {
+		liveSnapshots = e.snapshots.LiveSnapshots()
+	}
+
+	// Split the key range at the boundaries, each range tombstone being
+	// written by the subcompactions it overlaps
+	bounds := e.subcompactionBounds(readers)
+	subs := make([]*subcompaction, len(bounds)+1)
+	for i := range subs {
+		sub := &subcompaction{}
+		if i > 0 {
+			sub.start = bounds[i-1]
+		}
+		if i < len(bounds) {
+			sub.end = bounds[i]
+		}
+		sub.tombstones = clipTombstones(tombstones, sub.start, sub.end)
+		subs[i] = sub
+	}
 
-	// Create hierarchical merged iterator
-	mergedIter := composite.NewHierarchicalIterator(iterators)
+	// Output files are numbered in the order they are created
+	var outputSeq atomic.Uint64
+	if len(subs) == 1 {
+		e.runSubcompaction(task, readers, liveSnapshots, subs[0], &outputSeq)
+	} else {
+		var wg sync.WaitGroup
+		for _, sub := range subs {
+			wg.Add(1)
+			go func(sub *subcompaction) {
+				defer wg.Done()
+				e.runSubcompaction(task, readers, liveSnapshots, sub, &outputSeq)
+			}(sub)
+		}
+		wg.Wait()
+	}
 
-	// Track keys to skip duplicate entries (for tombstones)
-	var lastKey []byte
 	var outputFiles []string
+	var filterStats CompactionFilterStats
+	var err error
+	for _, sub := range subs {
+		outputFiles = append(outputFiles, sub.outputs...)
+		filterStats.Dropped += sub.filterStats.Dropped
+		filterStats.Changed += sub.filterStats.Changed
+		if err == nil {
+			err = sub.err
+		}
+	}
+
+	// The outputs replace the inputs all at once or not at all
+	if err == nil {
+		err = e.commitOutputs(task, outputFiles)
+	}
+
+	// A failed compaction leaves nothing behind
+	if err != nil {
+		for _, path := range outputFiles {
+			os.Remove(path)
+		}
+		return nil, err
+	}
+
+	e.filterStatsMu.Lock()
+	e.lastFilterStats = filterStats
+	e.filterStats.Dropped += filterStats.Dropped
+	e.filterStats.Changed += filterStats.Changed
+	e.filterStatsMu.Unlock()
+
+	return outputFiles, nil
+}
+
+// commitOutputs records in the version log that the outputs of a task replace
+// its inputs
+func (e *DefaultCompactionExecutor) commitOutputs(task *CompactionTask, outputFiles []string) error {
+	if e.versions == nil {
+		return nil
+	}
+
+	// Outputs hold no sequence number higher than the inputs do, which is
+	// unknown if it's unknown for any input
+	var edit versionlog.Edit
+	var largestSeq uint64
+	known := true
+	for _, files := range task.InputFiles {
+		for _, file := range files {
+			name := filepath.Base(file.Path)
+			edit.Removed = append(edit.Removed, name)
+			if input, ok := e.versions.File(name); ok && input.LargestSequence != 0 {
+				largestSeq = max(largestSeq, input.LargestSequence)
+			} else {
+				known = false
+			}
+		}
+	}
+	if !known {
+		largestSeq = 0
+	}
+
+	for _, path := range outputFiles {
+		reader, err := sstable.OpenReader(path)
+		if err != nil {
+			return fmt.Errorf("failed to open compaction output %s: %w", path, err)
+		}
+		file, err := versionlog.Describe(reader)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+		file.LargestSequence = largestSeq
+		edit.Added = append(edit.Added, file)
+	}
+
+	if err := e.versions.Apply(edit); err != nil {
+		return fmt.Errorf("failed to commit compaction: %w", err)
+	}
+	return nil
+}
+
+// subcompactionBounds returns the keys a task is split at, into up to
+// CompactionThreads ranges holding about the same number of data blocks of the
+// inputs. Ranges start at the first key of a block, and hold at least
+// minSubcompactionBlocks blocks so that small tasks aren't split.
+func (e *DefaultCompactionExecutor) subcompactionBounds(readers []*sstable.Reader) [][]byte {
+	if e.cfg.CompactionThreads <= 1 {
+		return nil
+	}
+
+	var keys [][]byte
+	for _, reader := range readers {
+		keys = append(keys, reader.BlockStartKeys()...)
+	}
+	slices.SortFunc(keys, bytes.Compare)
+	keys = slices.CompactFunc(keys, bytes.Equal)
+
+	n := min(e.cfg.CompactionThreads, len(keys)/minSubcompactionBlocks)
+	if n <= 1 {
+		return nil
+	}
+
+	// The smallest key starts the first range
+	bounds := make([][]byte, 0, n-1)
+	for i := 1; i < n; i++ {
+		bounds = append(bounds, keys[i*len(keys)/n])
+	}
+	return bounds
+}
+
+// clipTombstones returns the parts of the range tombstones inside [start, end).
+// Nil bounds are unbounded.
+func clipTombstones(tombstones []rangedel.Tombstone, start, end []byte) []rangedel.Tombstone {
+	var result []rangedel.Tombstone
+	for _, t := range tombstones {
+		if !t.Overlaps(start, end) {
+			continue
+		}
+		if start != nil && bytes.Compare(t.Start, start) < 0 {
+			t.Start = start
+		}
+		if end != nil && bytes.Compare(t.End, end) > 0 {
+			t.End = end
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+// runSubcompaction merges the versions of the keys in the range of sub into
+// new output files, numbered from outputSeq
+func (e *DefaultCompactionExecutor) runSubcompaction(task *CompactionTask, readers []*sstable.Reader,
+	liveSnapshots []uint64, sub *subcompaction, outputSeq *atomic.Uint64) {
+	// Create a merged iterator over all input files
+	iterators := make([]*sstable.Iterator, len(readers))
+	for i, reader := range readers {
+		// We need an iterator that preserves delete markers
+		iterators[i] = reader.NewIterator()
+	}
+	tombstones := sub.tombstones
+
+	// Range tombstones are kept in lower levels, and in any level while they
+	// still hide a version that is written to the output
+	keepAllTombstones := task.TargetLevel <= e.cfg.MaxLevelWithTombstones || task.KeepTombstones
+	tombstoneNeeded := make([]bool, len(tombstones))
+
+	// Merge all versions of each key, newest first
+	mergedIter := newVersionMergeIterator(iterators)
+
 	var currentWriter *sstable.Writer
 	var currentOutputPath string
-	var outputFileSequence uint64 = 1
 	var entriesInCurrentFile int
 
 	// Function to create a new output file
@@ -65,17 +333,21 @@ func (e *DefaultCompactionExecutor) CompactFiles(task *CompactionTask) ([]string
 			if err := currentWriter.Finish(); err != nil {
 				return fmt.Errorf("failed to finish SSTable: %w", err)
 			}
-			outputFiles = append(outputFiles, currentOutputPath)
+			sub.outputs = append(sub.outputs, currentOutputPath)
+			currentWriter = nil
 		}
 
 		// Create a new output file
 		timestamp := time.Now().UnixNano()
 		currentOutputPath = fmt.Sprintf(task.OutputPathTemplate,
-			task.TargetLevel, outputFileSequence, timestamp)
-		outputFileSequence++
+			task.TargetLevel, outputSeq.Add(1), timestamp)
 
 		var err error
-		currentWriter, err = sstable.NewWriter(currentOutputPath)
+		options := sstable.WriterOptionsFromConfig(e.cfg)
+		options.Timestamp = task.OutputTimestamp
+		options.RateLimiter = e.rateLimiter
+		options.IOPriority = ratelimit.Low
+		currentWriter, err = sstable.NewWriterWithOptions(currentOutputPath, options)
 		if err != nil {
 			return fmt.Errorf("failed to create SSTable writer: %w", err)
 		}
@@ -84,6 +356,13 @@ func (e *DefaultCompactionExecutor) CompactFiles(task *CompactionTask) ([]string
 		return nil
 	}
 
+	// The file being written is discarded if the subcompaction fails
+	defer func() {
+		if sub.err != nil && currentWriter != nil {
+			currentWriter.Abort()
+		}
+	}()
+
 	// Create a tombstone filter if we have a tombstone manager
 	var tombstoneFilter *BasicTombstoneFilter
 	if e.tombstoneManager != nil {
@@ -94,80 +373,241 @@ func (e *DefaultCompactionExecutor) CompactFiles(task *CompactionTask) ([]string
 		)
 	}
 
+	// Values whose TTL has passed are dropped like deleted ones
+	expiredFilter := NewExpiredValueFilter()
+
 	// Create the first output file
-	if err := createNewOutputFile(); err != nil {
-		return nil, err
+	if sub.err = createNewOutputFile(); sub.err != nil {
+		return
 	}
 
-	// Iterate through all keys in sorted order
-	mergedIter.SeekToFirst()
-	for mergedIter.Valid() {
-		key := mergedIter.Key()
-		value := mergedIter.Value()
+	// Versions of the current key, newest first
+	var key []byte
+	var versions []merge.Version
 
-		// Skip duplicates (we've already included the newest version)
-		if lastKey != nil && bytes.Equal(key, lastKey) {
-			mergedIter.Next()
-			continue
+	// Function to write the versions of the current key that are still needed
+	writeKey := func() error {
+		versions = expiredFilter.Filter(versions)
+
+		// Range tombstones covering the key act as deletions of it
+		var covering []uint64
+		for _, t := range tombstones {
+			if t.Contains(key) {
+				covering = append(covering, t.SeqNum)
+				versions = merge.InsertDeletion(versions, t.SeqNum)
+			}
 		}
 
-		// Determine if we should keep this entry
-		// If we have a tombstone filter, use it, otherwise use the default logic
-		var shouldKeep bool
-		isTombstone := mergedIter.IsTombstone()
+		// Only the versions a read can still observe are kept, with merge
+		// operands folded into them where possible
+		kept := merge.Collapse(e.mergeOperator, key, versions, liveSnapshots)
 
-		if tombstoneFilter != nil && isTombstone {
-			// Use the tombstone filter for tombstones
-			shouldKeep = tombstoneFilter.ShouldKeep(key, nil)
-		} else {
-			// Default logic - always keep non-tombstones, and keep tombstones in lower levels
-			shouldKeep = !isTombstone || task.TargetLevel <= e.cfg.MaxLevelWithTombstones
+		// The range tombstones themselves are written separately
+		if len(covering) > 0 {
+			kept = withoutRangeDeletions(kept, covering)
+			for i, t := range tombstones {
+				for _, v := range kept {
+					if t.Covers(key, v.SeqNum) {
+						tombstoneNeeded[i] = true
+						break
+					}
+				}
+			}
+		}
+		if len(kept) == 0 {
+			return nil
 		}
 
-		if shouldKeep {
-			var err error
+		// The compaction filter sees the newest value, unless a snapshot can
+		// still read it. A dropped value becomes a deletion so that the
+		// versions below it stay hidden.
+		if e.filter != nil && kept[0].Kind == merge.KindValue &&
+			(len(liveSnapshots) == 0 || liveSnapshots[len(liveSnapshots)-1] < kept[0].SeqNum) {
+			switch decision, value := e.filter.Filter(task.TargetLevel, key, kept[0].Value); decision {
+			case FilterDrop:
+				kept[0] = merge.Version{SeqNum: kept[0].SeqNum, Kind: merge.KindDeletion}
+				kept = snapshotVersions(kept, liveSnapshots)
+				sub.filterStats.Dropped++
+			case FilterChangeValue:
+				kept[0].Value = value
+				sub.filterStats.Changed++
+			}
+		}
 
-			// Use the explicit AddTombstone method if this is a tombstone
-			if isTombstone {
-				err = currentWriter.AddTombstone(key)
+		// A tombstone can only be dropped if no older version depends on it
+		if len(kept) == 1 && kept[0].Kind == merge.KindDeletion {
+			// If we have a tombstone filter, use it, otherwise keep tombstones in lower levels
+			var shouldKeep bool
+			if task.KeepTombstones {
+				shouldKeep = true
+			} else if tombstoneFilter != nil {
+				shouldKeep = tombstoneFilter.ShouldKeep(key, nil)
 			} else {
-				err = currentWriter.Add(key, value)
+				shouldKeep = task.TargetLevel <= e.cfg.MaxLevelWithTombstones
+			}
+			if !shouldKeep {
+				return nil
 			}
+		}
 
+		// If the current file is big enough, start a new one. This only happens
+		// on a key change so that all versions of a key stay in the same file.
+		if int64(currentWriter.DataSize()) >= e.cfg.SSTableMaxSize {
+			if err := createNewOutputFile(); err != nil {
+				return err
+			}
+		}
+
+		for _, v := range kept {
+			var err error
+			switch v.Kind {
+			case merge.KindOperand:
+				err = currentWriter.AddMergeWithSequence(key, v.Value, v.SeqNum)
+			case merge.KindDeletion:
+				// A nil value writes a tombstone
+				err = currentWriter.AddWithSequence(key, nil, v.SeqNum)
+			default:
+				if v.ExpireAt != 0 {
+					err = currentWriter.AddExpiringWithSequence(key, v.Value, v.SeqNum, v.ExpireAt)
+				} else {
+					err = currentWriter.AddWithSequence(key, v.Value, v.SeqNum)
+				}
+			}
 			if err != nil {
-				return nil, fmt.Errorf("failed to add entry to SSTable: %w", err)
+				return fmt.Errorf("failed to add entry to SSTable: %w", err)
 			}
 			entriesInCurrentFile++
 		}
 
-		// If the current file is big enough, start a new one
-		if int64(entriesInCurrentFile) >= e.cfg.SSTableMaxSize {
-			if err := createNewOutputFile(); err != nil {
-				return nil, err
+		return nil
+	}
+
+	// Iterate through all versions in the range in sorted order, one key at a time
+	if sub.start != nil {
+		mergedIter.Seek(sub.start)
+	} else {
+		mergedIter.SeekToFirst()
+	}
+	for ; mergedIter.Valid(); mergedIter.Next() {
+		if sub.end != nil && bytes.Compare(mergedIter.Key(), sub.end) >= 0 {
+			break
+		}
+
+		if key != nil && !bytes.Equal(mergedIter.Key(), key) {
+			if sub.err = writeKey(); sub.err != nil {
+				return
 			}
+			versions = versions[:0]
+		}
+
+		kind := merge.KindValue
+		if mergedIter.IsTombstone() {
+			kind = merge.KindDeletion
+		} else if mergedIter.IsMergeOperand() {
+			kind = merge.KindOperand
 		}
 
-		// Remember this key to skip duplicates
-		lastKey = append(lastKey[:0], key...)
-		mergedIter.Next()
+		key = append(key[:0], mergedIter.Key()...)
+		versions = append(versions, merge.Version{
+			SeqNum:   mergedIter.SequenceNumber(),
+			Kind:     kind,
+			Value:    mergedIter.Value(),
+			ExpireAt: mergedIter.ExpireAt(),
+		})
+	}
+
+	if key != nil {
+		if sub.err = writeKey(); sub.err != nil {
+			return
+		}
+	}
+
+	// Range tombstones that are still needed go to the last output file of the
+	// subcompaction
+	for i, t := range tombstones {
+		if !keepAllTombstones && !tombstoneNeeded[i] {
+			continue
+		}
+		if err := currentWriter.AddRangeTombstone(t.Start, t.End, t.SeqNum); err != nil {
+			sub.err = fmt.Errorf("failed to add range tombstone to SSTable: %w", err)
+			return
+		}
+		entriesInCurrentFile++
 	}
 
 	// Finish the last output file
-	if currentWriter != nil && entriesInCurrentFile > 0 {
-		if err := currentWriter.Finish(); err != nil {
-			return nil, fmt.Errorf("failed to finish SSTable: %w", err)
+	if entriesInCurrentFile > 0 {
+		if sub.err = currentWriter.Finish(); sub.err != nil {
+			sub.err = fmt.Errorf("failed to finish SSTable: %w", sub.err)
+			return
 		}
-		outputFiles = append(outputFiles, currentOutputPath)
-	} else if currentWriter != nil {
+		sub.outputs = append(sub.outputs, currentOutputPath)
+	} else {
 		// No entries were written, abort the file
 		currentWriter.Abort()
 	}
+	currentWriter = nil
+}
+
+// uniqueTombstones removes duplicate range tombstones, which appear when the
+// same write has been flushed more than once
+func uniqueTombstones(tombstones []rangedel.Tombstone) []rangedel.Tombstone {
+	type tombstoneKey struct {
+		start, end string
+		seqNum     uint64
+	}
 
-	return outputFiles, nil
+	seen := make(map[tombstoneKey]bool, len(tombstones))
+	result := tombstones[:0]
+	for _, t := range tombstones {
+		k := tombstoneKey{string(t.Start), string(t.End), t.SeqNum}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		result = append(result, t)
+	}
+	return result
+}
+
+// snapshotVersions keeps the newest of versions, ordered newest first, and
+// the older ones a live snapshot can still read
+func snapshotVersions(versions []merge.Version, liveSnapshots []uint64) []merge.Version {
+	result := versions[:1]
+	newerSeq := versions[0].SeqNum
+	for _, v := range versions[1:] {
+		if snapshot.IsRequired(liveSnapshots, v.SeqNum, newerSeq) {
+			result = append(result, v)
+		}
+		newerSeq = v.SeqNum
+	}
+	return result
 }
 
-// DeleteCompactedFiles removes the input files that were successfully compacted
+// withoutRangeDeletions drops the deletions standing in for range tombstones
+// at the given sequence numbers
+func withoutRangeDeletions(versions []merge.Version, covering []uint64) []merge.Version {
+	result := make([]merge.Version, 0, len(versions))
+	for _, v := range versions {
+		if v.Kind == merge.KindDeletion && slices.Contains(covering, v.SeqNum) {
+			continue
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+// DeleteCompactedFiles removes the input files that were successfully compacted,
+// once they are removed from the version log
 func (e *DefaultCompactionExecutor) DeleteCompactedFiles(filePaths []string) error {
+	names := make([]string, len(filePaths))
+	for i, path := range filePaths {
+		names[i] = filepath.Base(path)
+	}
+	if err := e.versions.Apply(versionlog.Edit{Removed: names}); err != nil {
+		return fmt.Errorf("failed to commit removal of compacted files: %w", err)
+	}
+
 	for _, path := range filePaths {
 		if err := os.Remove(path); err != nil {
 			return fmt.Errorf("failed to delete compacted file %s: %w", path, err)
@@ -0,0 +1,118 @@
+package compaction
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/KevoDB/kevo/pkg/config"
+)
+
+// FIFOCompactionStrategy implements a first-in, first-out compaction strategy
+// for data that is never updated, such as time series. Files are never
+// merged. Once the data outgrows CompactionMaxSize or CompactionMaxAge the
+// oldest files are deleted whole, which costs no rewriting at all.
+type FIFOCompactionStrategy struct {
+	*BaseCompactionStrategy
+
+	// Executor for deleting files
+	executor CompactionExecutor
+}
+
+// NewFIFOCompactionStrategy creates a new FIFO compaction strategy
+func NewFIFOCompactionStrategy(cfg *config.Config, sstableDir string, executor CompactionExecutor) *FIFOCompactionStrategy {
+	return &FIFOCompactionStrategy{
+		BaseCompactionStrategy: NewBaseCompactionStrategy(cfg, sstableDir),
+		executor:               executor,
+	}
+}
+
+// SelectCompaction selects the files past the retention limits for deletion
+func (s *FIFOCompactionStrategy) SelectCompaction() (*CompactionTask, error) {
+	return s.dropTask(expiredFiles(s.cfg, s.levels, time.Now())), nil
+}
+
+// CompactRange deletes the files past the retention limits. FIFO compaction
+// never merges files, so there is nothing else to do for a key range.
+func (s *FIFOCompactionStrategy) CompactRange(minKey, maxKey []byte) error {
+	return s.dropFiles(s.executor, expiredFiles(s.cfg, s.levels, time.Now()))
+}
+
+// expiredFiles returns the files past the retention limits of cfg, oldest
+// first: those created more than CompactionMaxAge ago, and then the oldest
+// of the others until the rest fit in CompactionMaxSize
+func expiredFiles(cfg *config.Config, levels map[int][]*SSTableInfo, now time.Time) []*SSTableInfo {
+	var files []*SSTableInfo
+	var totalSize int64
+	for _, levelFiles := range levels {
+		files = append(files, levelFiles...)
+		for _, file := range levelFiles {
+			totalSize += file.Size
+		}
+	}
+
+	// Oldest first. Files created at the same time are ordered like reads
+	// order them, deeper levels and lower sequence numbers holding older data.
+	sort.Slice(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		if a.CreatedAt != b.CreatedAt {
+			return a.CreatedAt < b.CreatedAt
+		}
+		if a.Level != b.Level {
+			return a.Level > b.Level
+		}
+		return a.Sequence < b.Sequence
+	})
+
+	var expired []*SSTableInfo
+	for _, file := range files {
+		tooOld := cfg.CompactionMaxAge > 0 &&
+			now.Sub(time.Unix(0, file.CreatedAt)) > time.Duration(cfg.CompactionMaxAge)*time.Second
+		tooBig := cfg.CompactionMaxSize > 0 && totalSize > cfg.CompactionMaxSize
+		if !tooOld && !tooBig {
+			break
+		}
+		expired = append(expired, file)
+		totalSize -= file.Size
+	}
+	return expired
+}
+
+// dropTask creates a task deleting files, or returns nil if there are none
+func (s *BaseCompactionStrategy) dropTask(files []*SSTableInfo) *CompactionTask {
+	if len(files) == 0 {
+		return nil
+	}
+
+	task := &CompactionTask{
+		InputFiles:         make(map[int][]*SSTableInfo),
+		OutputPathTemplate: filepath.Join(s.sstableDir, "%d_%06d_%020d.sst"),
+		Drop:               true,
+	}
+	for _, file := range files {
+		task.InputFiles[file.Level] = append(task.InputFiles[file.Level], file)
+		task.TargetLevel = max(task.TargetLevel, file.Level)
+	}
+	return task
+}
+
+// dropFiles deletes files and reloads the remaining ones
+func (s *BaseCompactionStrategy) dropFiles(executor CompactionExecutor, files []*SSTableInfo) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.Path
+	}
+	if err := executor.DeleteCompactedFiles(paths); err != nil {
+		return fmt.Errorf("failed to delete expired files: %w", err)
+	}
+
+	if err := s.LoadSSTables(); err != nil {
+		return fmt.Errorf("failed to reload SSTables: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package compaction
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/KevoDB/kevo/pkg/config"
+)
+
+// createAgedSSTables creates an L0 file for each age, oldest first, each
+// holding the same amount of data
+func createAgedSSTables(t *testing.T, dir string, ages ...time.Duration) []string {
+	now := time.Now()
+	var paths []string
+	for i, age := range ages {
+		data := make(map[string]string)
+		for j := 0; j < 10; j++ {
+			data[fmt.Sprintf("metric-%d-%d", i, j)] = "sample"
+		}
+		createdAt := now.Add(-age).UnixNano()
+		paths = append(paths, createTestSSTableCreatedAt(t, dir, 0, i+1, createdAt, createdAt, data))
+	}
+	return paths
+}
+
+func TestFIFOSelectCompaction(t *testing.T) {
+	sstDir, cfg, cleanup := setupCompactionTest(t)
+	defer cleanup()
+	cfg.CompactionStyle = config.CompactionStyleFIFO
+
+	paths := createAgedSSTables(t, sstDir, 3*time.Hour, 2*time.Hour, time.Hour, time.Minute)
+
+	strategy := NewFIFOCompactionStrategy(cfg, sstDir, NewCompactionExecutor(cfg, sstDir, nil))
+	defer strategy.Close()
+	if err := strategy.LoadSSTables(); err != nil {
+		t.Fatalf("Failed to load SSTables: %v", err)
+	}
+
+	expectDropped := func(name string, expected []string) {
+		t.Helper()
+		task, err := strategy.SelectCompaction()
+		if err != nil {
+			t.Fatalf("%s: failed to select compaction: %v", name, err)
+		}
+		if len(expected) == 0 {
+			if task != nil {
+				t.Errorf("%s: expected no compaction, got %v", name, task.InputFiles)
+			}
+			return
+		}
+		if task == nil {
+			t.Fatalf("%s: expected files to be dropped, got nil", name)
+		}
+		if !task.Drop {
+			t.Errorf("%s: expected a drop task", name)
+		}
+		if len(task.InputFiles[0]) != len(expected) {
+			t.Fatalf("%s: expected %d files, got %d", name, len(expected), len(task.InputFiles[0]))
+		}
+		for i, path := range expected {
+			if task.InputFiles[0][i].Path != path {
+				t.Errorf("%s: expected file %d to be %s, got %s", name, i, path, task.InputFiles[0][i].Path)
+			}
+		}
+	}
+
+	expectDropped("no limits", nil)
+
+	cfg.CompactionMaxAge = int64((90 * time.Minute).Seconds())
+	expectDropped("max age", paths[:2])
+
+	// Keep the newest files that fit in the size limit
+	cfg.CompactionMaxAge = 0
+	cfg.CompactionMaxSize = strategy.levels[0][0].Size + strategy.levels[0][1].Size
+	expectDropped("max size", paths[:2])
+
+	cfg.CompactionMaxSize = 1
+	expectDropped("everything", paths)
+}
+
+func TestFIFOCoordinatorDropsFiles(t *testing.T) {
+	sstDir, cfg, cleanup := setupCompactionTest(t)
+	defer cleanup()
+	cfg.CompactionStyle = config.CompactionStyleFIFO
+	cfg.CompactionMaxAge = int64((90 * time.Minute).Seconds())
+
+	paths := createAgedSSTables(t, sstDir, 3*time.Hour, 2*time.Hour, time.Hour)
+
+	coordinator := NewCompactionCoordinator(cfg, sstDir, CompactionCoordinatorOptions{})
+	if _, ok := coordinator.strategy.(*FIFOCompactionStrategy); !ok {
+		t.Fatalf("Expected the FIFO strategy, got %T", coordinator.strategy)
+	}
+
+	if err := coordinator.TriggerCompaction(); err != nil {
+		t.Fatalf("Failed to trigger compaction: %v", err)
+	}
+
+	for i, path := range paths {
+		_, err := os.Stat(path)
+		if i < 2 && !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be deleted, got %v", path, err)
+		}
+		if i == 2 && err != nil {
+			t.Errorf("Expected %s to be kept, got %v", path, err)
+		}
+	}
+
+	// Dropping files writes nothing
+	if count := coordinator.GetCompactionStats()["last_outputs_count"]; count != 0 {
+		t.Errorf("Expected no compaction outputs, got %v", count)
+	}
+}
@@ -0,0 +1,43 @@
+package compaction
+
+// FilterDecision tells compaction what to do with a value passed to a
+// CompactionFilter
+type FilterDecision int
+
+const (
+	// FilterKeep keeps the value as it is
+	FilterKeep FilterDecision = iota
+	// FilterDrop deletes the key, as if it had been deleted when the value
+	// was written
+	FilterDrop
+	// FilterChangeValue replaces the value with the one returned by the filter
+	FilterChangeValue
+)
+
+// CompactionFilter lets applications drop or rewrite data as compaction
+// rewrites it, for example to remove the keys of a deleted tenant. It is
+// called with the newest value of each key written by a compaction, unless an
+// open snapshot or transaction can still read that value. Deletions and merge
+// operands that can't be folded into a value aren't passed to it.
+//
+// A filter is shared by the compactions of all column families, so it must be
+// safe for concurrent use.
+type CompactionFilter interface {
+	// Filter decides what happens to the value of a key compacted into level.
+	// The returned value is only used with FilterChangeValue.
+	Filter(level int, key, value []byte) (FilterDecision, []byte)
+}
+
+// CompactionFilterFunc adapts a function to the CompactionFilter interface
+type CompactionFilterFunc func(level int, key, value []byte) (FilterDecision, []byte)
+
+// Filter calls f(level, key, value)
+func (f CompactionFilterFunc) Filter(level int, key, value []byte) (FilterDecision, []byte) {
+	return f(level, key, value)
+}
+
+// CompactionFilterStats counts the values a CompactionFilter dropped or changed
+type CompactionFilterStats struct {
+	Dropped uint64
+	Changed uint64
+}
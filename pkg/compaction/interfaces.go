@@ -60,6 +60,19 @@ type TombstoneManager interface {
 	CollectGarbage()
 }
 
+// SnapshotProvider exposes the sequence numbers pinned by open read snapshots
+type SnapshotProvider interface {
+	// LiveSnapshots returns the pinned sequence numbers in ascending order
+	LiveSnapshots() []uint64
+}
+
+// SSTableReloader is the storage that serves reads from the live files
+type SSTableReloader interface {
+	// ReloadSSTables reopens the files recorded in the version log, closing
+	// the readers of files no longer in it
+	ReloadSSTables() error
+}
+
 // CompactionCoordinator defines the interface for coordinating compaction processes
 type CompactionCoordinator interface {
 	// Start begins background compaction
@@ -0,0 +1,305 @@
+package compaction
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/KevoDB/kevo/pkg/config"
+)
+
+// LeveledCompactionStrategy implements a leveled compaction strategy. Level 0
+// holds flushed files whose key ranges may overlap. From level 1 down the
+// files of a level don't overlap, and each level may grow CompactionRatio times
+// larger than the one above it. When a level outgrows its target, one of its
+// files is merged into the files it overlaps in the next level.
+type LeveledCompactionStrategy struct {
+	*BaseCompactionStrategy
+
+	// Executor for compacting files
+	executor CompactionExecutor
+
+	// Last key compacted from each level, the next file picked from a level is
+	// searched for after it so that compactions cycle through the key space
+	cursors map[int][]byte
+}
+
+// NewLeveledCompactionStrategy creates a new leveled compaction strategy
+func NewLeveledCompactionStrategy(cfg *config.Config, sstableDir string, executor CompactionExecutor) *LeveledCompactionStrategy {
+	return &LeveledCompactionStrategy{
+		BaseCompactionStrategy: NewBaseCompactionStrategy(cfg, sstableDir),
+		executor:               executor,
+		cursors:                make(map[int][]byte),
+	}
+}
+
+// lastLevel returns the bottom level, whose files are never compacted further
+func (s *LeveledCompactionStrategy) lastLevel() int {
+	return max(s.cfg.CompactionLevels-1, 1)
+}
+
+// LevelTargetSize returns the size a level from L1 down may grow to before it
+// is compacted. L1 may hold as much as a full set of memtables, and every
+// following level CompactionRatio times more than the one above it.
+func (s *LeveledCompactionStrategy) LevelTargetSize(level int) int64 {
+	target := float64(s.cfg.MemTableSize) * float64(max(s.cfg.MaxMemTables, 1))
+	for l := 1; l < level; l++ {
+		target *= s.cfg.CompactionRatio
+	}
+	return max(int64(target), 1)
+}
+
+// levelScore returns how far a level is over its limit, a level needs
+// compaction once its score reaches 1. L0 is scored by file count since every
+// file in it may have to be read on a lookup.
+func (s *LeveledCompactionStrategy) levelScore(level int) float64 {
+	if level == 0 {
+		return float64(len(s.levels[0])) / float64(max(s.cfg.MaxMemTables, 1))
+	}
+	return float64(s.GetLevelSize(level)) / float64(s.LevelTargetSize(level))
+}
+
+// SelectCompaction selects files for leveled compaction from the level with
+// the highest score
+func (s *LeveledCompactionStrategy) SelectCompaction() (*CompactionTask, error) {
+	bestLevel := -1
+	bestScore := 0.0
+	for level := 0; level < s.lastLevel(); level++ {
+		if score := s.levelScore(level); score >= 1 && score > bestScore {
+			bestLevel = level
+			bestScore = score
+		}
+	}
+
+	switch {
+	case bestLevel < 0:
+		// No compaction needed
+		return nil, nil
+	case bestLevel == 0:
+		return s.selectL0Compaction()
+	default:
+		return s.selectLevelCompaction(bestLevel)
+	}
+}
+
+// selectL0Compaction selects all of L0 along with the L1 files it overlaps.
+// Compacting only part of L0 could leave older versions of a key in L0 above
+// newer ones moved to L1.
+func (s *LeveledCompactionStrategy) selectL0Compaction() (*CompactionTask, error) {
+	if len(s.levels[0]) == 0 {
+		return nil, nil
+	}
+
+	files := make([]*SSTableInfo, len(s.levels[0]))
+	copy(files, s.levels[0])
+
+	minKey, maxKey := keyRange(files)
+	return s.newTask(0, files, minKey, maxKey), nil
+}
+
+// selectLevelCompaction selects a file from a level below L0 along with the
+// files it overlaps in the next level. Starting after the level's cursor, the
+// file that overlaps the fewest bytes in the next level relative to its own
+// size is picked, as merging it rewrites the least data.
+func (s *LeveledCompactionStrategy) selectLevelCompaction(level int) (*CompactionTask, error) {
+	files := make([]*SSTableInfo, 0, len(s.levels[level]))
+	for _, file := range s.levels[level] {
+		if len(file.FirstKey) > 0 {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return bytes.Compare(files[i].FirstKey, files[j].FirstKey) < 0
+	})
+
+	// Visit the files round-robin, starting with the first after the cursor
+	start := 0
+	if cursor := s.cursors[level]; cursor != nil {
+		start = sort.Search(len(files), func(i int) bool {
+			return bytes.Compare(files[i].FirstKey, cursor) > 0
+		})
+	}
+
+	var selected *SSTableInfo
+	var selectedScore float64
+	for i := range files {
+		file := files[(start+i)%len(files)]
+		score := s.overlapScore(level, file)
+		if selected == nil || score < selectedScore {
+			selected = file
+			selectedScore = score
+		}
+	}
+
+	// Files of the level that overlap the selected one have to move with it
+	inputs, minKey, maxKey := s.overlappingFiles(level, selected.FirstKey, selected.LastKey)
+	s.cursors[level] = append([]byte{}, maxKey...)
+
+	return s.newTask(level, inputs, minKey, maxKey), nil
+}
+
+// overlapScore returns the bytes a file overlaps in the next level per byte of
+// the file
+func (s *LeveledCompactionStrategy) overlapScore(level int, file *SSTableInfo) float64 {
+	var overlap int64
+	for _, next := range s.levels[level+1] {
+		if next.Overlaps(file) {
+			overlap += next.Size
+		}
+	}
+	return float64(overlap) / float64(max(file.Size, 1))
+}
+
+// overlappingFiles returns the files of a level that overlap a key range,
+// widening the range until it covers all of them so that no file left in the
+// level overlaps it
+func (s *LeveledCompactionStrategy) overlappingFiles(level int, minKey, maxKey []byte) ([]*SSTableInfo, []byte, []byte) {
+	for {
+		rangeInfo := &SSTableInfo{FirstKey: minKey, LastKey: maxKey}
+
+		var files []*SSTableInfo
+		for _, file := range s.levels[level] {
+			if file.Overlaps(rangeInfo) {
+				files = append(files, file)
+			}
+		}
+
+		fileMin, fileMax := keyRange(files)
+		if !widens(minKey, maxKey, fileMin, fileMax) {
+			return files, minKey, maxKey
+		}
+		minKey, maxKey = widen(minKey, maxKey, fileMin, fileMax)
+	}
+}
+
+// newTask creates a task merging files of a level into the next one
+func (s *LeveledCompactionStrategy) newTask(level int, files []*SSTableInfo, minKey, maxKey []byte) *CompactionTask {
+	nextFiles, minKey, maxKey := s.overlappingFiles(level+1, minKey, maxKey)
+
+	return &CompactionTask{
+		InputFiles: map[int][]*SSTableInfo{
+			level:     files,
+			level + 1: nextFiles,
+		},
+		TargetLevel:        level + 1,
+		OutputPathTemplate: filepath.Join(s.sstableDir, "%d_%06d_%020d.sst"),
+		KeepTombstones:     s.overlapsBelow(level+1, minKey, maxKey),
+	}
+}
+
+// overlapsBelow reports whether any level below the given one has files in a
+// key range
+func (s *LeveledCompactionStrategy) overlapsBelow(level int, minKey, maxKey []byte) bool {
+	rangeInfo := &SSTableInfo{FirstKey: minKey, LastKey: maxKey}
+	for l, files := range s.levels {
+		if l <= level {
+			continue
+		}
+		for _, file := range files {
+			if file.Overlaps(rangeInfo) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CompactRange performs compaction on a specific key range. The range is
+// widened until no file outside of it overlaps it in any level, and the files
+// in it are merged into the deepest level they occupy, L1 at the least.
+func (s *LeveledCompactionStrategy) CompactRange(minKey, maxKey []byte) error {
+	task := &CompactionTask{
+		InputFiles:         make(map[int][]*SSTableInfo),
+		OutputPathTemplate: filepath.Join(s.sstableDir, "%d_%06d_%020d.sst"),
+	}
+
+	for {
+		widened := false
+		for level := range s.levels {
+			files, levelMin, levelMax := s.overlappingFiles(level, minKey, maxKey)
+			if len(files) == 0 {
+				continue
+			}
+			task.InputFiles[level] = files
+			if widens(minKey, maxKey, levelMin, levelMax) {
+				minKey, maxKey = widen(minKey, maxKey, levelMin, levelMax)
+				widened = true
+			}
+		}
+		if !widened {
+			break
+		}
+	}
+
+	// If no files overlap with the range, no compaction needed
+	if len(task.InputFiles) == 0 {
+		return nil
+	}
+
+	task.TargetLevel = 1
+	for level := range task.InputFiles {
+		task.TargetLevel = max(task.TargetLevel, level)
+	}
+
+	// Perform the compaction
+	_, err := s.executor.CompactFiles(task)
+	if err != nil {
+		return fmt.Errorf("compaction failed: %w", err)
+	}
+
+	// Gather all input file paths for cleanup
+	var inputPaths []string
+	for _, files := range task.InputFiles {
+		for _, file := range files {
+			inputPaths = append(inputPaths, file.Path)
+		}
+	}
+
+	// Delete the original files that were compacted
+	if err := s.executor.DeleteCompactedFiles(inputPaths); err != nil {
+		return fmt.Errorf("failed to clean up compacted files: %w", err)
+	}
+
+	// Reload SSTables to refresh our file list
+	if err := s.LoadSSTables(); err != nil {
+		return fmt.Errorf("failed to reload SSTables: %w", err)
+	}
+
+	return nil
+}
+
+// keyRange returns the smallest first key and largest last key of files
+func keyRange(files []*SSTableInfo) ([]byte, []byte) {
+	var minKey, maxKey []byte
+	for _, file := range files {
+		if len(minKey) == 0 || bytes.Compare(file.FirstKey, minKey) < 0 {
+			minKey = file.FirstKey
+		}
+		if len(maxKey) == 0 || bytes.Compare(file.LastKey, maxKey) > 0 {
+			maxKey = file.LastKey
+		}
+	}
+	return minKey, maxKey
+}
+
+// widens reports whether [otherMin, otherMax] reaches outside [minKey, maxKey]
+func widens(minKey, maxKey, otherMin, otherMax []byte) bool {
+	return (len(otherMin) > 0 && bytes.Compare(otherMin, minKey) < 0) ||
+		(len(otherMax) > 0 && bytes.Compare(otherMax, maxKey) > 0)
+}
+
+// widen returns the smallest key range covering both ranges
+func widen(minKey, maxKey, otherMin, otherMax []byte) ([]byte, []byte) {
+	if len(otherMin) > 0 && bytes.Compare(otherMin, minKey) < 0 {
+		minKey = otherMin
+	}
+	if len(otherMax) > 0 && bytes.Compare(otherMax, maxKey) > 0 {
+		maxKey = otherMax
+	}
+	return minKey, maxKey
+}
@@ -0,0 +1,282 @@
+package compaction
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/KevoDB/kevo/pkg/config"
+)
+
+func TestLeveledSelectL0Compaction(t *testing.T) {
+	sstDir, cfg, cleanup := setupCompactionTest(t)
+	defer cleanup()
+	cfg.CompactionStyle = config.CompactionStyleLeveled
+	cfg.MemTableSize = 1024 * 1024
+
+	timestamp := time.Now().UnixNano()
+	createTestSSTable(t, sstDir, 0, 1, timestamp, map[string]string{"a": "1", "d": "1"})
+	createTestSSTable(t, sstDir, 0, 2, timestamp+1, map[string]string{"c": "2", "f": "2"})
+	createTestSSTable(t, sstDir, 1, 1, timestamp+2, map[string]string{"e": "3", "g": "3"})
+	createTestSSTable(t, sstDir, 1, 2, timestamp+3, map[string]string{"x": "4", "z": "4"})
+
+	strategy := NewLeveledCompactionStrategy(cfg, sstDir, NewCompactionExecutor(cfg, sstDir, nil))
+	defer strategy.Close()
+	if err := strategy.LoadSSTables(); err != nil {
+		t.Fatalf("Failed to load SSTables: %v", err)
+	}
+
+	task, err := strategy.SelectCompaction()
+	if err != nil {
+		t.Fatalf("Failed to select compaction: %v", err)
+	}
+	if task == nil {
+		t.Fatal("Expected an L0 compaction, got nil")
+	}
+
+	// All of L0 is compacted, along with the L1 file it overlaps
+	if task.TargetLevel != 1 {
+		t.Errorf("Expected target level 1, got %d", task.TargetLevel)
+	}
+	if len(task.InputFiles[0]) != 2 {
+		t.Errorf("Expected 2 L0 files, got %d", len(task.InputFiles[0]))
+	}
+	if len(task.InputFiles[1]) != 1 || string(task.InputFiles[1][0].FirstKey) != "e" {
+		t.Errorf("Expected only the L1 file starting at e, got %v", task.InputFiles[1])
+	}
+}
+
+func TestLeveledSelectLevelCompaction(t *testing.T) {
+	sstDir, cfg, cleanup := setupCompactionTest(t)
+	defer cleanup()
+	cfg.CompactionStyle = config.CompactionStyleLeveled
+
+	// A tiny L1 target size keeps L1 over its limit
+	cfg.MemTableSize = 1
+	cfg.MaxMemTables = 1
+
+	timestamp := time.Now().UnixNano()
+	createTestSSTable(t, sstDir, 1, 1, timestamp, map[string]string{"a": "1", "c": "1"})
+	createTestSSTable(t, sstDir, 1, 2, timestamp+1, map[string]string{"d": "1", "f": "1"})
+	createTestSSTable(t, sstDir, 1, 3, timestamp+2, map[string]string{"g": "1", "i": "1"})
+
+	// In L2, the range of the second file is overlapped by many more bytes
+	// than the range of the first, and nothing overlaps the third
+	createTestSSTable(t, sstDir, 2, 1, timestamp+3, map[string]string{"b": "2"})
+	bigValues := make(map[string]string)
+	for i := 0; i < 100; i++ {
+		bigValues[fmt.Sprintf("e%03d", i)] = "a value that makes this file larger"
+	}
+	createTestSSTable(t, sstDir, 2, 2, timestamp+4, bigValues)
+
+	// Older data for the first range lies below L2
+	createTestSSTable(t, sstDir, 3, 1, timestamp+5, map[string]string{"a": "3"})
+
+	strategy := NewLeveledCompactionStrategy(cfg, sstDir, NewCompactionExecutor(cfg, sstDir, nil))
+	defer strategy.Close()
+	if err := strategy.LoadSSTables(); err != nil {
+		t.Fatalf("Failed to load SSTables: %v", err)
+	}
+
+	// Files are picked by the fewest bytes they overlap in L2. Each picked
+	// file is dropped from L1 as if it had been compacted.
+	expected := []struct {
+		firstKey       string
+		nextLevelFiles int
+		keepTombstones bool
+	}{
+		{"g", 0, false},
+		{"a", 1, true},
+		{"d", 1, false},
+	}
+
+	for i, exp := range expected {
+		task, err := strategy.SelectCompaction()
+		if err != nil {
+			t.Fatalf("Failed to select compaction %d: %v", i, err)
+		}
+		if task == nil {
+			t.Fatalf("Expected compaction %d, got nil", i)
+		}
+
+		if task.TargetLevel != 2 {
+			t.Errorf("Compaction %d: expected target level 2, got %d", i, task.TargetLevel)
+		}
+		if len(task.InputFiles[1]) != 1 || string(task.InputFiles[1][0].FirstKey) != exp.firstKey {
+			t.Fatalf("Compaction %d: expected the L1 file starting at %s, got %v",
+				i, exp.firstKey, task.InputFiles[1])
+		}
+		if len(task.InputFiles[2]) != exp.nextLevelFiles {
+			t.Errorf("Compaction %d: expected %d L2 files, got %d",
+				i, exp.nextLevelFiles, len(task.InputFiles[2]))
+		}
+		if task.KeepTombstones != exp.keepTombstones {
+			t.Errorf("Compaction %d: expected KeepTombstones %v, got %v",
+				i, exp.keepTombstones, task.KeepTombstones)
+		}
+
+		for j, file := range strategy.levels[1] {
+			if file == task.InputFiles[1][0] {
+				strategy.levels[1] = append(strategy.levels[1][:j], strategy.levels[1][j+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func TestLeveledSelectRoundRobin(t *testing.T) {
+	sstDir, cfg, cleanup := setupCompactionTest(t)
+	defer cleanup()
+	cfg.CompactionStyle = config.CompactionStyleLeveled
+	cfg.MemTableSize = 1
+	cfg.MaxMemTables = 1
+
+	timestamp := time.Now().UnixNano()
+	createTestSSTable(t, sstDir, 1, 1, timestamp, map[string]string{"g": "1", "i": "1"})
+	createTestSSTable(t, sstDir, 1, 2, timestamp+1, map[string]string{"a": "1", "c": "1"})
+	createTestSSTable(t, sstDir, 1, 3, timestamp+2, map[string]string{"d": "1", "f": "1"})
+
+	strategy := NewLeveledCompactionStrategy(cfg, sstDir, NewCompactionExecutor(cfg, sstDir, nil))
+	defer strategy.Close()
+	if err := strategy.LoadSSTables(); err != nil {
+		t.Fatalf("Failed to load SSTables: %v", err)
+	}
+
+	// With nothing in L2, the cursor cycles through the key space, and is
+	// kept when the files are reloaded
+	for i, firstKey := range []string{"a", "d", "g", "a", "d"} {
+		if i == 3 {
+			if err := strategy.LoadSSTables(); err != nil {
+				t.Fatalf("Failed to reload SSTables: %v", err)
+			}
+		}
+
+		task, err := strategy.SelectCompaction()
+		if err != nil {
+			t.Fatalf("Failed to select compaction %d: %v", i, err)
+		}
+		if task == nil || len(task.InputFiles[1]) != 1 {
+			t.Fatalf("Compaction %d: expected a single L1 file, got %v", i, task)
+		}
+		if got := string(task.InputFiles[1][0].FirstKey); got != firstKey {
+			t.Errorf("Compaction %d: expected the file starting at %s, got %s", i, firstKey, got)
+		}
+	}
+}
+
+func TestLeveledCompactionKeepsLevelsSorted(t *testing.T) {
+	sstDir, cfg, cleanup := setupCompactionTest(t)
+	defer cleanup()
+	cfg.CompactionStyle = config.CompactionStyleLeveled
+	cfg.MemTableSize = 64 * 1024
+
+	// Overlapping L0 files, each newer one overwriting part of the last
+	value := bytes.Repeat([]byte("v"), 100)
+	expected := make(map[string]string)
+	timestamp := time.Now().UnixNano()
+	for file := 0; file < 3; file++ {
+		data := make(map[string]string)
+		for i := file * 1000; i < file*1000+2000; i++ {
+			key := fmt.Sprintf("key%05d", i)
+			data[key] = fmt.Sprintf("%d-%s", file, value)
+			expected[key] = data[key]
+		}
+		createTestSSTable(t, sstDir, 0, file+1, timestamp+int64(file), data)
+	}
+
+	executor := NewCompactionExecutor(cfg, sstDir, nil)
+	strategy := NewLeveledCompactionStrategy(cfg, sstDir, executor)
+	defer strategy.Close()
+
+	// Compact until every level is within its limits
+	compactions := 0
+	for ; ; compactions++ {
+		if compactions > 100 {
+			t.Fatal("Compaction did not settle")
+		}
+		if err := strategy.LoadSSTables(); err != nil {
+			t.Fatalf("Failed to load SSTables: %v", err)
+		}
+		task, err := strategy.SelectCompaction()
+		if err != nil {
+			t.Fatalf("Failed to select compaction: %v", err)
+		}
+		if task == nil {
+			break
+		}
+		if _, err := executor.CompactFiles(task); err != nil {
+			t.Fatalf("Failed to compact files: %v", err)
+		}
+		var inputs []string
+		for _, files := range task.InputFiles {
+			for _, file := range files {
+				inputs = append(inputs, file.Path)
+			}
+		}
+		if err := executor.DeleteCompactedFiles(inputs); err != nil {
+			t.Fatalf("Failed to delete compacted files: %v", err)
+		}
+	}
+	if compactions < 2 {
+		t.Errorf("Expected compactions past L1, got %d", compactions)
+	}
+
+	if len(strategy.levels[0]) != 0 {
+		t.Errorf("Expected L0 to be empty, got %d files", len(strategy.levels[0]))
+	}
+
+	found := make(map[string]string)
+	for level, files := range strategy.levels {
+		if level > 0 && strategy.GetLevelSize(level) > strategy.LevelTargetSize(level) && level < cfg.CompactionLevels-1 {
+			t.Errorf("Level %d is over its target size", level)
+		}
+
+		// Files below L0 must not overlap
+		sorted := append([]*SSTableInfo{}, files...)
+		sort.Slice(sorted, func(i, j int) bool {
+			return bytes.Compare(sorted[i].FirstKey, sorted[j].FirstKey) < 0
+		})
+		for i := 1; i < len(sorted); i++ {
+			if sorted[i].Overlaps(sorted[i-1]) {
+				t.Errorf("Level %d: %s overlaps %s", level, sorted[i].KeyRange(), sorted[i-1].KeyRange())
+			}
+		}
+
+		// Every key is stored once, with its newest value
+		for _, file := range files {
+			iter := file.Reader.NewIterator()
+			for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+				key := string(iter.Key())
+				if _, dup := found[key]; dup {
+					t.Fatalf("Key %s is stored more than once", key)
+				}
+				found[key] = string(iter.Value())
+			}
+		}
+	}
+
+	if len(found) != len(expected) {
+		t.Errorf("Expected %d keys, found %d", len(expected), len(found))
+	}
+	for key, value := range expected {
+		if found[key] != value {
+			t.Fatalf("Key %s: expected %.10s..., got %.10s...", key, value, found[key])
+		}
+	}
+}
+
+func TestNewCompactionStrategy(t *testing.T) {
+	sstDir, cfg, cleanup := setupCompactionTest(t)
+	defer cleanup()
+
+	if _, ok := NewCompactionStrategy(cfg, sstDir, nil).(*TieredCompactionStrategy); !ok {
+		t.Error("Expected the tiered strategy by default")
+	}
+
+	cfg.CompactionStyle = config.CompactionStyleLeveled
+	if _, ok := NewCompactionStrategy(cfg, sstDir, nil).(*LeveledCompactionStrategy); !ok {
+		t.Error("Expected the leveled strategy")
+	}
+}
@@ -0,0 +1,141 @@
+package compaction
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/KevoDB/kevo/pkg/config"
+)
+
+// TimeWindowCompactionStrategy implements a compaction strategy for data that
+// is never updated, such as time series. Files are grouped into windows of
+// CompactionTimeWindow by the creation time in their footer. Once a window has
+// passed its files are merged into one, so each piece of data is rewritten
+// only once. The oldest files are deleted like with FIFOCompactionStrategy.
+type TimeWindowCompactionStrategy struct {
+	*BaseCompactionStrategy
+
+	// Executor for compacting files
+	executor CompactionExecutor
+}
+
+// NewTimeWindowCompactionStrategy creates a new time window compaction strategy
+func NewTimeWindowCompactionStrategy(cfg *config.Config, sstableDir string, executor CompactionExecutor) *TimeWindowCompactionStrategy {
+	return &TimeWindowCompactionStrategy{
+		BaseCompactionStrategy: NewBaseCompactionStrategy(cfg, sstableDir),
+		executor:               executor,
+	}
+}
+
+// window returns the time window a creation time falls in
+func (s *TimeWindowCompactionStrategy) window(createdAt int64) int64 {
+	size := max(s.cfg.CompactionTimeWindow, 1) * int64(time.Second)
+	return createdAt / size
+}
+
+// windows returns the files grouped by time window, and the windows in order
+func (s *TimeWindowCompactionStrategy) windows() (map[int64][]*SSTableInfo, []int64) {
+	files := make(map[int64][]*SSTableInfo)
+	var order []int64
+	for _, levelFiles := range s.levels {
+		for _, file := range levelFiles {
+			w := s.window(file.CreatedAt)
+			if _, ok := files[w]; !ok {
+				order = append(order, w)
+			}
+			files[w] = append(files[w], file)
+		}
+	}
+	sort.Slice(order, func(i, j int) bool { return order[i] < order[j] })
+	return files, order
+}
+
+// SelectCompaction selects the files past the retention limits for deletion,
+// or else the files of the oldest past window that has more than one
+func (s *TimeWindowCompactionStrategy) SelectCompaction() (*CompactionTask, error) {
+	now := time.Now()
+	if task := s.dropTask(expiredFiles(s.cfg, s.levels, now)); task != nil {
+		return task, nil
+	}
+
+	// The current window still receives new files
+	current := s.window(now.UnixNano())
+	files, order := s.windows()
+	for _, w := range order {
+		if w < current && len(files[w]) > 1 {
+			return s.windowTask(files[w]), nil
+		}
+	}
+
+	// No compaction needed
+	return nil, nil
+}
+
+// windowTask creates a task merging the files of a window into L1. The output
+// carries the creation time of the newest input so that it stays in the window.
+func (s *TimeWindowCompactionStrategy) windowTask(files []*SSTableInfo) *CompactionTask {
+	task := &CompactionTask{
+		InputFiles:         make(map[int][]*SSTableInfo),
+		TargetLevel:        1,
+		OutputPathTemplate: filepath.Join(s.sstableDir, "%d_%06d_%020d.sst"),
+	}
+	for _, file := range files {
+		task.InputFiles[file.Level] = append(task.InputFiles[file.Level], file)
+		task.TargetLevel = max(task.TargetLevel, file.Level)
+		task.OutputTimestamp = max(task.OutputTimestamp, file.CreatedAt)
+	}
+	return task
+}
+
+// CompactRange merges the files of every window that has files in the key
+// range, the current one included, then deletes the files past the retention
+// limits
+func (s *TimeWindowCompactionStrategy) CompactRange(minKey, maxKey []byte) error {
+	rangeInfo := &SSTableInfo{
+		FirstKey: minKey,
+		LastKey:  maxKey,
+	}
+
+	files, order := s.windows()
+	compacted := false
+	for _, w := range order {
+		windowFiles := files[w]
+		if len(windowFiles) < 2 {
+			continue
+		}
+
+		overlaps := false
+		for _, file := range windowFiles {
+			if file.Overlaps(rangeInfo) {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			continue
+		}
+
+		if _, err := s.executor.CompactFiles(s.windowTask(windowFiles)); err != nil {
+			return fmt.Errorf("compaction failed: %w", err)
+		}
+
+		inputPaths := make([]string, len(windowFiles))
+		for i, file := range windowFiles {
+			inputPaths[i] = file.Path
+		}
+		if err := s.executor.DeleteCompactedFiles(inputPaths); err != nil {
+			return fmt.Errorf("failed to clean up compacted files: %w", err)
+		}
+		compacted = true
+	}
+
+	if compacted {
+		if err := s.LoadSSTables(); err != nil {
+			return fmt.Errorf("failed to reload SSTables: %w", err)
+		}
+	}
+
+	return s.dropFiles(s.executor, expiredFiles(s.cfg, s.levels, time.Now()))
+}
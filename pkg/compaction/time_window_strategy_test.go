@@ -0,0 +1,107 @@
+package compaction
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/KevoDB/kevo/pkg/config"
+	"github.com/KevoDB/kevo/pkg/sstable"
+)
+
+func TestTimeWindowCompaction(t *testing.T) {
+	sstDir, cfg, cleanup := setupCompactionTest(t)
+	defer cleanup()
+	cfg.CompactionStyle = config.CompactionStyleTimeWindow
+	cfg.CompactionTimeWindow = int64(time.Hour.Seconds())
+
+	// Two files in the window three hours ago, one in the window an hour ago
+	// and two in the current window
+	now := time.Now()
+	current := now.Truncate(time.Hour)
+	createdAt := []time.Time{
+		current.Add(-3 * time.Hour).Add(time.Minute),
+		current.Add(-3 * time.Hour).Add(2 * time.Minute),
+		current.Add(-time.Hour),
+		current,
+		now,
+	}
+	keys := []string{"cpu.1", "cpu.2", "cpu.3", "cpu.4", "cpu.5"}
+	for i, created := range createdAt {
+		ts := created.UnixNano()
+		createTestSSTableCreatedAt(t, sstDir, 0, i+1, ts, ts, map[string]string{keys[i]: "sample"})
+	}
+
+	coordinator := NewCompactionCoordinator(cfg, sstDir, CompactionCoordinatorOptions{})
+	strategy, ok := coordinator.strategy.(*TimeWindowCompactionStrategy)
+	if !ok {
+		t.Fatalf("Expected the time window strategy, got %T", coordinator.strategy)
+	}
+	defer strategy.Close()
+
+	// Only the past window with more than one file is compacted
+	if err := strategy.LoadSSTables(); err != nil {
+		t.Fatalf("Failed to load SSTables: %v", err)
+	}
+	task, err := strategy.SelectCompaction()
+	if err != nil {
+		t.Fatalf("Failed to select compaction: %v", err)
+	}
+	if task == nil {
+		t.Fatal("Expected a compaction, got nil")
+	}
+	if task.Drop || task.TargetLevel != 1 || len(task.InputFiles[0]) != 2 {
+		t.Fatalf("Expected the 2 files of the oldest window merged into L1, got %+v", task)
+	}
+	if task.OutputTimestamp != createdAt[1].UnixNano() {
+		t.Errorf("Expected the output to be created at %v, got %v",
+			createdAt[1], time.Unix(0, task.OutputTimestamp))
+	}
+
+	if err := coordinator.TriggerCompaction(); err != nil {
+		t.Fatalf("Failed to trigger compaction: %v", err)
+	}
+
+	// The merged file stays in its window, leaving nothing to compact
+	if err := strategy.LoadSSTables(); err != nil {
+		t.Fatalf("Failed to reload SSTables: %v", err)
+	}
+	if len(strategy.levels[0]) != 3 || len(strategy.levels[1]) != 1 {
+		t.Fatalf("Expected 3 files in L0 and 1 in L1, got %d and %d",
+			len(strategy.levels[0]), len(strategy.levels[1]))
+	}
+	merged := strategy.levels[1][0]
+	if merged.CreatedAt != createdAt[1].UnixNano() {
+		t.Errorf("Expected the merged file to be created at %v, got %v",
+			createdAt[1], time.Unix(0, merged.CreatedAt))
+	}
+	for _, key := range keys[:2] {
+		reader, err := sstable.OpenReader(merged.Path)
+		if err != nil {
+			t.Fatalf("Failed to open merged file: %v", err)
+		}
+		value, err := reader.Get([]byte(key))
+		reader.Close()
+		if err != nil || !bytes.Equal(value, []byte("sample")) {
+			t.Errorf("Expected %s in the merged file, got %q (%v)", key, value, err)
+		}
+	}
+
+	task, err = strategy.SelectCompaction()
+	if err != nil {
+		t.Fatalf("Failed to select compaction: %v", err)
+	}
+	if task != nil {
+		t.Errorf("Expected no compaction, got %v", task.InputFiles)
+	}
+
+	// Windows past the retention limit are dropped whole
+	cfg.CompactionMaxAge = int64((150 * time.Minute).Seconds())
+	task, err = strategy.SelectCompaction()
+	if err != nil {
+		t.Fatalf("Failed to select compaction: %v", err)
+	}
+	if task == nil || !task.Drop || len(task.InputFiles[1]) != 1 || len(task.InputFiles[0]) != 0 {
+		t.Errorf("Expected the merged file to be dropped, got %+v", task)
+	}
+}
@@ -3,6 +3,9 @@ package compaction
 import (
 	"bytes"
 	"time"
+
+	"github.com/KevoDB/kevo/pkg/common/merge"
+	"github.com/KevoDB/kevo/pkg/common/ttl"
 )
 
 // TombstoneTracker implements the TombstoneManager interface
@@ -154,6 +157,31 @@ func (f *TimeBasedTombstoneFilter) ShouldKeep(key, value []byte) bool {
 	return f.now.Sub(deleteTime) <= f.retention
 }
 
+// ExpiredValueFilter turns values whose TTL has passed into deletions, so that
+// compaction drops them along with the versions they shadow
+type ExpiredValueFilter struct {
+	// Current time (for testing)
+	now time.Time
+}
+
+// NewExpiredValueFilter creates a filter for values expired at the current time
+func NewExpiredValueFilter() *ExpiredValueFilter {
+	return &ExpiredValueFilter{
+		now: time.Now(),
+	}
+}
+
+// ShouldKeep determines if a value with the given expiry time is still live
+func (f *ExpiredValueFilter) ShouldKeep(expireAt uint64) bool {
+	return !ttl.Expired(expireAt, f.now)
+}
+
+// Filter replaces the expired values among the versions of a key, ordered
+// newest first, with deletions
+func (f *ExpiredValueFilter) Filter(versions []merge.Version) []merge.Version {
+	return merge.Expire(versions, f.now)
+}
+
 // KeyRangeTombstoneFilter filters tombstones by key range
 type KeyRangeTombstoneFilter struct {
 	// Minimum key in the range (inclusive)
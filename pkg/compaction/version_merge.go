@@ -0,0 +1,121 @@
+package compaction
+
+import (
+	"bytes"
+
+	"github.com/KevoDB/kevo/pkg/sstable"
+)
+
+// versionMergeIterator merges SSTable iterators without collapsing versions.
+// Entries are yielded ordered by key, then by sequence number from newest to
+// oldest. Ties are broken by source order, so sources must be given newest first.
+// A write found in more than one source is only yielded once.
+type versionMergeIterator struct {
+	sources []*sstable.Iterator
+	current int
+}
+
+// newVersionMergeIterator creates a merging iterator over the given sources
+func newVersionMergeIterator(sources []*sstable.Iterator) *versionMergeIterator {
+	return &versionMergeIterator{
+		sources: sources,
+		current: -1,
+	}
+}
+
+// SeekToFirst positions every source at its first entry
+func (m *versionMergeIterator) SeekToFirst() {
+	for _, src := range m.sources {
+		src.SeekToFirst()
+	}
+	m.pick()
+}
+
+// Seek positions every source at its first entry with a key >= target
+func (m *versionMergeIterator) Seek(target []byte) {
+	for _, src := range m.sources {
+		src.Seek(target)
+	}
+	m.pick()
+}
+
+// Next advances past the current entry
+func (m *versionMergeIterator) Next() bool {
+	if m.current < 0 {
+		return false
+	}
+	prev := m.current
+	key := append([]byte(nil), m.Key()...)
+	seq := m.SequenceNumber()
+
+	m.sources[m.current].Next()
+	m.pick()
+
+	// The writes of a batch share a sequence number within one source, so the
+	// same key and sequence number in another source is a duplicate
+	for m.current >= 0 && m.current != prev && m.SequenceNumber() == seq && bytes.Equal(m.Key(), key) {
+		m.sources[m.current].Next()
+		m.pick()
+	}
+	return m.Valid()
+}
+
+// Valid returns true if the iterator is positioned at an entry
+func (m *versionMergeIterator) Valid() bool {
+	return m.current >= 0
+}
+
+// Key returns the current key
+func (m *versionMergeIterator) Key() []byte {
+	return m.sources[m.current].Key()
+}
+
+// Value returns the current value
+func (m *versionMergeIterator) Value() []byte {
+	return m.sources[m.current].Value()
+}
+
+// IsTombstone returns true if the current entry is a deletion marker
+func (m *versionMergeIterator) IsTombstone() bool {
+	return m.sources[m.current].IsTombstone()
+}
+
+// IsMergeOperand returns true if the current entry is a merge operand
+func (m *versionMergeIterator) IsMergeOperand() bool {
+	return m.sources[m.current].IsMergeOperand()
+}
+
+// ExpireAt returns the expiry time of the current entry, or 0 if it never expires
+func (m *versionMergeIterator) ExpireAt() uint64 {
+	return m.sources[m.current].ExpireAt()
+}
+
+// SequenceNumber returns the sequence number of the current entry
+func (m *versionMergeIterator) SequenceNumber() uint64 {
+	return m.sources[m.current].SequenceNumber()
+}
+
+// pick selects the source holding the smallest key with the highest sequence number
+func (m *versionMergeIterator) pick() {
+	m.current = -1
+	var bestKey []byte
+	var bestSeq uint64
+
+	for i, src := range m.sources {
+		if !src.Valid() {
+			continue
+		}
+
+		key := src.Key()
+		seq := src.SequenceNumber()
+		if m.current == -1 {
+			m.current, bestKey, bestSeq = i, key, seq
+			continue
+		}
+
+		cmp := bytes.Compare(key, bestKey)
+		if cmp < 0 || (cmp == 0 && seq > bestSeq) {
+			m.current, bestKey, bestSeq = i, key, seq
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+)
+
+// DefaultColumnFamily is the name of the keyspace used by operations that
+// don't name a column family
+const DefaultColumnFamily = "default"
+
+// MaxColumnFamilyNameLength is the longest allowed column family name
+const MaxColumnFamilyNameLength = 64
+
+var columnFamilyNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ColumnFamilyOptions describes a named keyspace. A column family has its own
+// MemTables, SSTables and compaction, but shares the WAL of the engine so a
+// batch can span several families atomically.
+//
+// Zero values inherit the engine's configuration.
+type ColumnFamilyOptions struct {
+	ID   uint32 `json:"id"`
+	Name string `json:"name"`
+
+	// MemTable configuration
+	MemTableSize int64 `json:"memtable_size,omitempty"`
+
+	// SSTable configuration
+	SSTableBlockSize int   `json:"sstable_block_size,omitempty"`
+	SSTableMaxSize   int64 `json:"sstable_max_size,omitempty"`
+
+	// Compaction configuration
+	CompactionStyle    string  `json:"compaction_style,omitempty"` // "tiered", "leveled", "fifo" or "time_window"
+	CompactionLevels   int     `json:"compaction_levels,omitempty"`
+	CompactionRatio    float64 `json:"compaction_ratio,omitempty"`
+	CompactionInterval int64   `json:"compaction_interval,omitempty"`
+
+	// Retention limits of the fifo and time_window compaction styles
+	CompactionMaxSize    int64 `json:"compaction_max_size,omitempty"`
+	CompactionMaxAge     int64 `json:"compaction_max_age,omitempty"`
+	CompactionTimeWindow int64 `json:"compaction_time_window,omitempty"`
+}
+
+// ValidateColumnFamilyName checks that a name can be used for a new column family
+func ValidateColumnFamilyName(name string) error {
+	if name == DefaultColumnFamily {
+		return fmt.Errorf("%w: column family name %q is reserved", ErrInvalidConfig, name)
+	}
+	if len(name) > MaxColumnFamilyNameLength || !columnFamilyNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: invalid column family name %q", ErrInvalidConfig, name)
+	}
+	return nil
+}
+
+// validate checks the options of a single column family
+func (o *ColumnFamilyOptions) validate() error {
+	if err := ValidateColumnFamilyName(o.Name); err != nil {
+		return err
+	}
+
+	if o.ID == 0 {
+		return fmt.Errorf("%w: column family %q has no ID", ErrInvalidConfig, o.Name)
+	}
+
+	if o.MemTableSize < 0 || o.SSTableBlockSize < 0 || o.SSTableMaxSize < 0 ||
+		o.CompactionLevels < 0 || o.CompactionInterval < 0 ||
+		o.CompactionMaxSize < 0 || o.CompactionMaxAge < 0 || o.CompactionTimeWindow < 0 {
+		return fmt.Errorf("%w: column family %q has negative options", ErrInvalidConfig, o.Name)
+	}
+
+	switch o.CompactionStyle {
+	case "", CompactionStyleTiered, CompactionStyleLeveled, CompactionStyleFIFO, CompactionStyleTimeWindow:
+	default:
+		return fmt.Errorf("%w: column family %q has unknown compaction style %q", ErrInvalidConfig, o.Name, o.CompactionStyle)
+	}
+
+	if o.CompactionRatio != 0 && o.CompactionRatio <= 1.0 {
+		return fmt.Errorf("%w: column family %q compaction ratio must be greater than 1.0", ErrInvalidConfig, o.Name)
+	}
+
+	return nil
+}
+
+// validateColumnFamilies checks that column family names and IDs are unique
+func validateColumnFamilies(families []ColumnFamilyOptions) error {
+	names := make(map[string]bool, len(families))
+	ids := make(map[uint32]bool, len(families))
+
+	for i := range families {
+		if err := families[i].validate(); err != nil {
+			return err
+		}
+		if names[families[i].Name] {
+			return fmt.Errorf("%w: duplicate column family %q", ErrInvalidConfig, families[i].Name)
+		}
+		if ids[families[i].ID] {
+			return fmt.Errorf("%w: duplicate column family ID %d", ErrInvalidConfig, families[i].ID)
+		}
+		names[families[i].Name] = true
+		ids[families[i].ID] = true
+	}
+
+	return nil
+}
+
+// AddColumnFamily registers a new column family and assigns it an ID.
+// The caller is responsible for saving the manifest.
+func (c *Config) AddColumnFamily(opts ColumnFamilyOptions) (ColumnFamilyOptions, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var maxID uint32
+	for _, family := range c.ColumnFamilies {
+		if family.Name == opts.Name {
+			return ColumnFamilyOptions{}, fmt.Errorf("%w: column family %q already exists", ErrInvalidConfig, opts.Name)
+		}
+		if family.ID > maxID {
+			maxID = family.ID
+		}
+	}
+
+	opts.ID = maxID + 1
+	if err := opts.validate(); err != nil {
+		return ColumnFamilyOptions{}, err
+	}
+
+	c.ColumnFamilies = append(c.ColumnFamilies, opts)
+	return opts, nil
+}
+
+// RemoveColumnFamily unregisters a column family
+func (c *Config) RemoveColumnFamily(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, family := range c.ColumnFamilies {
+		if family.Name == name {
+			c.ColumnFamilies = append(c.ColumnFamilies[:i], c.ColumnFamilies[i+1:]...)
+			return
+		}
+	}
+}
+
+// GetColumnFamilies returns a copy of the registered column families
+func (c *Config) GetColumnFamilies() []ColumnFamilyOptions {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]ColumnFamilyOptions(nil), c.ColumnFamilies...)
+}
+
+// ForColumnFamily derives the configuration of a column family. Its SSTables
+// live in a subdirectory of the SSTable directory named after the family.
+func (c *Config) ForColumnFamily(opts ColumnFamilyOptions) (*Config, error) {
+	cfg, err := c.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.ColumnFamilies = nil
+	cfg.SSTDir = filepath.Join(cfg.SSTDir, opts.Name)
+
+	if opts.MemTableSize > 0 {
+		cfg.MemTableSize = opts.MemTableSize
+	}
+	if opts.SSTableBlockSize > 0 {
+		cfg.SSTableBlockSize = opts.SSTableBlockSize
+	}
+	if opts.SSTableMaxSize > 0 {
+		cfg.SSTableMaxSize = opts.SSTableMaxSize
+	}
+	if opts.CompactionStyle != "" {
+		cfg.CompactionStyle = opts.CompactionStyle
+	}
+	if opts.CompactionLevels > 0 {
+		cfg.CompactionLevels = opts.CompactionLevels
+	}
+	if opts.CompactionRatio > 0 {
+		cfg.CompactionRatio = opts.CompactionRatio
+	}
+	if opts.CompactionInterval > 0 {
+		cfg.CompactionInterval = opts.CompactionInterval
+	}
+	if opts.CompactionMaxSize > 0 {
+		cfg.CompactionMaxSize = opts.CompactionMaxSize
+	}
+	if opts.CompactionMaxAge > 0 {
+		cfg.CompactionMaxAge = opts.CompactionMaxAge
+	}
+	if opts.CompactionTimeWindow > 0 {
+		cfg.CompactionTimeWindow = opts.CompactionTimeWindow
+	}
+
+	// Options inherited from the engine may not suit the family, such as a
+	// time_window style without a window
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("column family %q: %w", opts.Name, err)
+	}
+
+	return cfg, nil
+}
@@ -7,6 +7,8 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+
+	"github.com/KevoDB/kevo/pkg/sstable/compression"
 )
 
 const (
@@ -28,6 +30,23 @@ const (
 	SyncImmediate
 )
 
+// Compaction styles
+const (
+	// CompactionStyleTiered merges whole levels into the next once they grow
+	// CompactionRatio times larger than it
+	CompactionStyleTiered = "tiered"
+	// CompactionStyleLeveled keeps the files of each level from L1 down
+	// non-overlapping and merges one file at a time into the next level
+	CompactionStyleLeveled = "leveled"
+	// CompactionStyleFIFO never merges files and deletes the oldest ones once
+	// the data outgrows CompactionMaxSize or CompactionMaxAge
+	CompactionStyleFIFO = "fifo"
+	// CompactionStyleTimeWindow merges the files created in the same
+	// CompactionTimeWindow once it has passed, and deletes the oldest ones
+	// like CompactionStyleFIFO
+	CompactionStyleTimeWindow = "time_window"
+)
+
 type Config struct {
 	Version int `json:"version"`
 
@@ -37,6 +56,10 @@ type Config struct {
 	WALSyncBytes int64    `json:"wal_sync_bytes"`
 	WALMaxSize   int64    `json:"wal_max_size"`
 
+	// Directory WAL retention moves old WAL files to instead of deleting
+	// them, so backups can still archive them. Empty to delete them.
+	WALArchiveDir string `json:"wal_archive_dir,omitempty"`
+
 	// MemTable configuration
 	MemTableSize    int64 `json:"memtable_size"`
 	MaxMemTables    int   `json:"max_memtables"`
@@ -49,14 +72,33 @@ type Config struct {
 	SSTableIndexSize   int    `json:"sstable_index_size"`
 	SSTableMaxSize     int64  `json:"sstable_max_size"`
 	SSTableRestartSize int    `json:"sstable_restart_size"`
+	BlockCacheSize     int64  `json:"block_cache_size"` // Bytes of data blocks cached in memory, 0 disables the cache
+
+	// SSTable block compression: "none", "snappy", "zstd" or "lz4". The level
+	// is only used by zstd, where 0 selects the default level.
+	SSTableCompression      string `json:"sstable_compression"`
+	SSTableCompressionLevel int    `json:"sstable_compression_level"`
 
 	// Compaction configuration
+	CompactionStyle        string  `json:"compaction_style"` // "tiered", "leveled", "fifo" or "time_window"
 	CompactionLevels       int     `json:"compaction_levels"`
 	CompactionRatio        float64 `json:"compaction_ratio"`
 	CompactionThreads      int     `json:"compaction_threads"`
 	CompactionInterval     int64   `json:"compaction_interval"`
 	MaxLevelWithTombstones int     `json:"max_level_with_tombstones"` // Levels higher than this discard tombstones
 
+	// Retention limits of the fifo and time_window compaction styles, which
+	// are meant for data that is never updated. Zero means no limit.
+	CompactionMaxSize    int64 `json:"compaction_max_size"`    // Bytes of SSTables kept
+	CompactionMaxAge     int64 `json:"compaction_max_age"`     // Age in seconds after which SSTables are deleted
+	CompactionTimeWindow int64 `json:"compaction_time_window"` // Seconds of data merged together by time_window
+
+	// Bytes per second flushes and compactions may write, shared by all
+	// column families. Zero means no limit. With auto tuning the limit is
+	// lowered while foreground latency is higher than usual.
+	RateLimitBytesPerSec int64 `json:"rate_limit_bytes_per_sec"`
+	RateLimitAutoTune    bool  `json:"rate_limit_auto_tune"`
+
 	// Transaction configuration
 	ReadOnlyTxTTL       int64 `json:"read_only_tx_ttl"`      // Time-to-live for read-only transactions in seconds (default: 180s)
 	ReadWriteTxTTL      int64 `json:"read_write_tx_ttl"`     // Time-to-live for read-write transactions in seconds (default: 60s)
@@ -65,6 +107,9 @@ type Config struct {
 	TxWarningThreshold  int   `json:"tx_warning_threshold"`  // Percentage of TTL after which to log warnings (default: 75)
 	TxCriticalThreshold int   `json:"tx_critical_threshold"` // Percentage of TTL after which to log critical warnings (default: 90)
 
+	// Column families besides the default one, see ColumnFamilyOptions
+	ColumnFamilies []ColumnFamilyOptions `json:"column_families,omitempty"`
+
 	mu sync.RWMutex
 }
 
@@ -93,13 +138,17 @@ func NewDefaultConfig(dbPath string) *Config {
 		SSTableIndexSize:   64 * 1024,        // 64KB
 		SSTableMaxSize:     64 * 1024 * 1024, // 64MB
 		SSTableRestartSize: 16,               // Restart points every 16 keys
+		BlockCacheSize:     64 * 1024 * 1024, // 64MB
+		SSTableCompression: "none",           // Opt-in, so existing databases keep their format
 
 		// Compaction defaults
+		CompactionStyle:        CompactionStyleTiered,
 		CompactionLevels:       7,
 		CompactionRatio:        10,
 		CompactionThreads:      2,
-		CompactionInterval:     30, // 30 seconds
-		MaxLevelWithTombstones: 1,  // Keep tombstones in levels 0 and 1
+		CompactionInterval:     30,           // 30 seconds
+		MaxLevelWithTombstones: 1,            // Keep tombstones in levels 0 and 1
+		CompactionTimeWindow:   24 * 60 * 60, // 1 day
 
 		// Transaction defaults
 		ReadOnlyTxTTL:       180, // 3 minutes
@@ -144,6 +193,32 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("%w: SSTable index size must be positive", ErrInvalidConfig)
 	}
 
+	if c.BlockCacheSize < 0 {
+		return fmt.Errorf("%w: Block cache size cannot be negative", ErrInvalidConfig)
+	}
+
+	if _, err := compression.ParseType(c.SSTableCompression); err != nil {
+		return fmt.Errorf("%w: SSTable compression: %v", ErrInvalidConfig, err)
+	}
+
+	switch c.CompactionStyle {
+	case "", CompactionStyleTiered, CompactionStyleLeveled, CompactionStyleFIFO, CompactionStyleTimeWindow:
+	default:
+		return fmt.Errorf("%w: Unknown compaction style %q", ErrInvalidConfig, c.CompactionStyle)
+	}
+
+	if c.CompactionMaxSize < 0 || c.CompactionMaxAge < 0 {
+		return fmt.Errorf("%w: Compaction retention limits cannot be negative", ErrInvalidConfig)
+	}
+
+	if c.CompactionStyle == CompactionStyleTimeWindow && c.CompactionTimeWindow <= 0 {
+		return fmt.Errorf("%w: Compaction time window must be positive", ErrInvalidConfig)
+	}
+
+	if c.RateLimitBytesPerSec < 0 {
+		return fmt.Errorf("%w: Rate limit cannot be negative", ErrInvalidConfig)
+	}
+
 	if c.CompactionLevels <= 0 {
 		return fmt.Errorf("%w: Compaction levels must be positive", ErrInvalidConfig)
 	}
@@ -177,7 +252,7 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("%w: Transaction critical threshold must be between warning threshold and 99", ErrInvalidConfig)
 	}
 
-	return nil
+	return validateColumnFamilies(c.ColumnFamilies)
 }
 
 // LoadConfigFromManifest loads just the configuration portion from the manifest file
@@ -191,8 +266,9 @@ func LoadConfigFromManifest(dbPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read manifest: %w", err)
 	}
 
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
+	// Settings missing from manifests written by older versions keep their defaults
+	cfg := NewDefaultConfig(dbPath)
+	if err := json.Unmarshal(data, cfg); err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
 	}
 
@@ -200,7 +276,7 @@ func LoadConfigFromManifest(dbPath string) (*Config, error) {
 		return nil, err
 	}
 
-	return &cfg, nil
+	return cfg, nil
 }
 
 // SaveManifest saves the configuration to the manifest file
@@ -235,6 +311,22 @@ func (c *Config) SaveManifest(dbPath string) error {
 	return nil
 }
 
+// Clone returns a deep copy of the configuration
+func (c *Config) Clone() (*Config, error) {
+	c.mu.RLock()
+	data, err := json.Marshal(c)
+	c.mu.RUnlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return &cfg, nil
+}
+
 // Update applies the given function to modify the configuration
 func (c *Config) Update(fn func(*Config)) {
 	c.mu.Lock()
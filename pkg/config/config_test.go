@@ -1,6 +1,7 @@
 package config
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"testing"
@@ -88,6 +89,50 @@ func TestConfigValidate(t *testing.T) {
 			},
 			expected: "invalid configuration: SSTable block size must be positive",
 		},
+		{
+			name: "negative block cache size",
+			mutate: func(c *Config) {
+				c.BlockCacheSize = -1
+			},
+			expected: "invalid configuration: Block cache size cannot be negative",
+		},
+		{
+			name: "unknown compression",
+			mutate: func(c *Config) {
+				c.SSTableCompression = "brotli"
+			},
+			expected: "invalid configuration: SSTable compression: unknown compression type: \"brotli\"",
+		},
+		{
+			name: "unknown compaction style",
+			mutate: func(c *Config) {
+				c.CompactionStyle = "universal"
+			},
+			expected: "invalid configuration: Unknown compaction style \"universal\"",
+		},
+		{
+			name: "negative compaction max age",
+			mutate: func(c *Config) {
+				c.CompactionStyle = CompactionStyleFIFO
+				c.CompactionMaxAge = -1
+			},
+			expected: "invalid configuration: Compaction retention limits cannot be negative",
+		},
+		{
+			name: "zero compaction time window",
+			mutate: func(c *Config) {
+				c.CompactionStyle = CompactionStyleTimeWindow
+				c.CompactionTimeWindow = 0
+			},
+			expected: "invalid configuration: Compaction time window must be positive",
+		},
+		{
+			name: "negative rate limit",
+			mutate: func(c *Config) {
+				c.RateLimitBytesPerSec = -1
+			},
+			expected: "invalid configuration: Rate limit cannot be negative",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -139,6 +184,30 @@ func TestConfigManifestSaveLoad(t *testing.T) {
 		t.Errorf("expected compaction threads %d, got %d", cfg.CompactionThreads, loadedCfg.CompactionThreads)
 	}
 
+	// Settings missing from older manifests keep their defaults
+	manifestPath := filepath.Join(tempDir, DefaultManifestFileName)
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("failed to read manifest: %v", err)
+	}
+	data = bytes.Replace(data, []byte(`"block_cache_size"`), []byte(`"unknown_setting"`), 1)
+	data = bytes.Replace(data, []byte(`"sstable_compression"`), []byte(`"unknown_compression"`), 1)
+	if err := os.WriteFile(manifestPath, data, 0644); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+	loadedCfg, err = LoadConfigFromManifest(tempDir)
+	if err != nil {
+		t.Fatalf("failed to load manifest: %v", err)
+	}
+	if loadedCfg.BlockCacheSize != NewDefaultConfig(tempDir).BlockCacheSize {
+		t.Errorf("expected default block cache size, got %d", loadedCfg.BlockCacheSize)
+	}
+
+	// Databases written before compression existed don't start compressing
+	if loadedCfg.SSTableCompression != "none" {
+		t.Errorf("expected no compression, got %q", loadedCfg.SSTableCompression)
+	}
+
 	// Test loading non-existent manifest
 	nonExistentDir := filepath.Join(tempDir, "nonexistent")
 	_, err = LoadConfigFromManifest(nonExistentDir)
@@ -165,3 +234,50 @@ func TestConfigUpdate(t *testing.T) {
 		t.Errorf("expected max memtables %d, got %d", 8, cfg.MaxMemTables)
 	}
 }
+
+func TestConfigColumnFamilies(t *testing.T) {
+	cfg := NewDefaultConfig("/tmp/testdb")
+
+	users, err := cfg.AddColumnFamily(ColumnFamilyOptions{Name: "users", MemTableSize: 1024})
+	if err != nil {
+		t.Fatalf("failed to add column family: %v", err)
+	}
+	events, err := cfg.AddColumnFamily(ColumnFamilyOptions{Name: "events"})
+	if err != nil {
+		t.Fatalf("failed to add column family: %v", err)
+	}
+	if users.ID != 1 || events.ID != 2 {
+		t.Errorf("expected IDs 1 and 2, got %d and %d", users.ID, events.ID)
+	}
+
+	for _, name := range []string{"users", DefaultColumnFamily, "", "a/b"} {
+		if _, err := cfg.AddColumnFamily(ColumnFamilyOptions{Name: name}); err == nil {
+			t.Errorf("expected adding column family %q to fail", name)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid config, got error: %v", err)
+	}
+
+	// Options that are not set are inherited
+	familyCfg, err := cfg.ForColumnFamily(users)
+	if err != nil {
+		t.Fatalf("failed to derive column family config: %v", err)
+	}
+	if familyCfg.SSTDir != filepath.Join(cfg.SSTDir, "users") || familyCfg.WALDir != cfg.WALDir {
+		t.Errorf("unexpected directories %s, %s", familyCfg.SSTDir, familyCfg.WALDir)
+	}
+	if familyCfg.MemTableSize != 1024 || familyCfg.CompactionLevels != cfg.CompactionLevels {
+		t.Errorf("unexpected options: memtable size %d, compaction levels %d", familyCfg.MemTableSize, familyCfg.CompactionLevels)
+	}
+	if len(familyCfg.ColumnFamilies) != 0 {
+		t.Errorf("expected no nested column families, got %d", len(familyCfg.ColumnFamilies))
+	}
+
+	// Duplicate IDs are rejected
+	cfg.ColumnFamilies[1].ID = users.ID
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected duplicate column family IDs to be rejected")
+	}
+}
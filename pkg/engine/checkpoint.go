@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/KevoDB/kevo/pkg/engine/storage"
+)
+
+// CreateCheckpoint writes a copy of the database to dir, which must not exist
+// yet, while the database keeps serving reads and writes. The checkpoint is a
+// database of its own that can be opened with NewEngineFacade, holding every
+// write up to the returned sequence number and none after it. SSTables are
+// hard linked rather than copied when dir is on the same file system.
+func (e *EngineFacade) CreateCheckpoint(dir string) (uint64, error) {
+	if e.closed.Load() {
+		return 0, ErrEngineClosed
+	}
+
+	owner, ok := e.storage.(*storage.Manager)
+	if !ok {
+		return 0, fmt.Errorf("storage does not support checkpoints")
+	}
+
+	if _, err := os.Stat(dir); err == nil {
+		return 0, fmt.Errorf("%w: %s", ErrCheckpointExists, dir)
+	} else if !os.IsNotExist(err) {
+		return 0, fmt.Errorf("failed to check checkpoint directory: %w", err)
+	}
+
+	// Keep column families from being created until the manifest is written
+	e.familyMu.RLock()
+	defer e.familyMu.RUnlock()
+
+	cfg, err := e.cfg.Clone()
+	if err != nil {
+		return 0, err
+	}
+	cfg.WALDir = filepath.Join(dir, "wal")
+	cfg.SSTDir = filepath.Join(dir, "sst")
+
+	seqNum, err := owner.Checkpoint(cfg.SSTDir, cfg.WALDir)
+	if err == nil {
+		err = cfg.SaveManifest(dir)
+	}
+	if err != nil {
+		e.stats.TrackError("checkpoint_error")
+		os.RemoveAll(dir)
+		return 0, fmt.Errorf("failed to create checkpoint: %w", err)
+	}
+
+	return seqNum, nil
+}
@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestEngineFacade_Checkpoint(t *testing.T) {
+	dir, err := os.MkdirTemp("", "engine-facade-checkpoint-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	eng, err := NewEngineFacade(filepath.Join(dir, "db"))
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	if err := eng.CreateColumnFamily("users", ColumnFamilyOptions{}); err != nil {
+		t.Fatalf("Failed to create column family: %v", err)
+	}
+
+	// Half the keys in SSTables, half only in the WAL
+	for i := 0; i < 100; i++ {
+		if i == 50 {
+			if err := eng.FlushImMemTables(); err != nil {
+				t.Fatalf("Failed to flush memtables: %v", err)
+			}
+		}
+		if err := eng.Put([]byte(fmt.Sprintf("key-%03d", i)), []byte(fmt.Sprintf("value-%03d", i))); err != nil {
+			t.Fatalf("Failed to put: %v", err)
+		}
+	}
+	if err := eng.PutCF("users", []byte("alice"), []byte("admin")); err != nil {
+		t.Fatalf("Failed to put: %v", err)
+	}
+
+	// Keep writing while the checkpoint is taken
+	var stop atomic.Bool
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; !stop.Load(); i++ {
+			if err := eng.Put([]byte(fmt.Sprintf("live-%06d", i)), []byte("value")); err != nil {
+				t.Errorf("Failed to put: %v", err)
+				return
+			}
+		}
+	}()
+
+	checkpointDir := filepath.Join(dir, "checkpoint")
+	seqNum, err := eng.CreateCheckpoint(checkpointDir)
+	stop.Store(true)
+	wg.Wait()
+	if err != nil {
+		t.Fatalf("Failed to create checkpoint: %v", err)
+	}
+	if seqNum < 101 {
+		t.Errorf("Expected the checkpoint to include the first 101 writes, got sequence number %d", seqNum)
+	}
+	if _, err := eng.CreateCheckpoint(checkpointDir); !errors.Is(err, ErrCheckpointExists) {
+		t.Errorf("Expected ErrCheckpointExists, got %v", err)
+	}
+
+	// SSTables are shared with the database rather than copied
+	ssts, _ := filepath.Glob(filepath.Join(checkpointDir, "sst", "*.sst"))
+	if len(ssts) == 0 {
+		t.Fatal("Expected the checkpoint to have SSTables")
+	}
+	original, err := os.Stat(filepath.Join(dir, "db", "sst", filepath.Base(ssts[0])))
+	if err != nil {
+		t.Fatalf("Failed to stat SSTable: %v", err)
+	}
+	linked, err := os.Stat(ssts[0])
+	if err != nil {
+		t.Fatalf("Failed to stat SSTable: %v", err)
+	}
+	if !os.SameFile(original, linked) {
+		t.Errorf("Expected %s to be a hard link", filepath.Base(ssts[0]))
+	}
+
+	// Writes after the checkpoint don't show up in it
+	if err := eng.Put([]byte("after"), []byte("value")); err != nil {
+		t.Fatalf("Failed to put: %v", err)
+	}
+
+	cp, err := NewEngineFacade(checkpointDir)
+	if err != nil {
+		t.Fatalf("Failed to open checkpoint: %v", err)
+	}
+	defer cp.Close()
+
+	for i := 0; i < 100; i++ {
+		value, err := cp.Get([]byte(fmt.Sprintf("key-%03d", i)))
+		if err != nil || string(value) != fmt.Sprintf("value-%03d", i) {
+			t.Fatalf("Expected value-%03d in the checkpoint, got %q (%v)", i, value, err)
+		}
+	}
+	if value, err := cp.GetCF("users", []byte("alice")); err != nil || string(value) != "admin" {
+		t.Errorf("Expected the column family in the checkpoint, got %q (%v)", value, err)
+	}
+	if _, err := cp.Get([]byte("after")); err == nil {
+		t.Error("Expected a write after the checkpoint to be missing from it")
+	}
+
+	// The concurrent writes it has are a prefix of the ones made
+	missing := false
+	for i := 0; i < 100000; i++ {
+		_, err := cp.Get([]byte(fmt.Sprintf("live-%06d", i)))
+		if err != nil {
+			missing = true
+		} else if missing {
+			t.Fatalf("Checkpoint has live-%06d but not an earlier write", i)
+		}
+	}
+}
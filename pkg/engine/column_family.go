@@ -0,0 +1,329 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/KevoDB/kevo/pkg/common/iterator"
+	coreCompaction "github.com/KevoDB/kevo/pkg/compaction"
+	"github.com/KevoDB/kevo/pkg/config"
+	"github.com/KevoDB/kevo/pkg/engine/compaction"
+	"github.com/KevoDB/kevo/pkg/engine/interfaces"
+	"github.com/KevoDB/kevo/pkg/engine/storage"
+	"github.com/KevoDB/kevo/pkg/stats"
+	"github.com/KevoDB/kevo/pkg/wal"
+)
+
+// ColumnFamilyOptions configures a column family created with CreateColumnFamily
+type ColumnFamilyOptions = config.ColumnFamilyOptions
+
+// DefaultColumnFamily names the keyspace used by the operations that don't
+// take a column family. An empty name refers to it as well.
+const DefaultColumnFamily = config.DefaultColumnFamily
+
+// columnFamily is a named keyspace with its own MemTables, SSTables and
+// compaction. All column families write to the WAL of the default one.
+type columnFamily struct {
+	id         uint32
+	storage    interfaces.StorageManager
+	compaction interfaces.CompactionManager
+}
+
+// CreateColumnFamily adds a keyspace and records it in the manifest. The ID
+// and Name fields of opts are ignored.
+//
+// Column families are numbered in the order they are created and replicated
+// writes name them by number, so replicas must create the same column
+// families in the same order as the primary. This is allowed in read-only mode.
+func (e *EngineFacade) CreateColumnFamily(name string, opts ColumnFamilyOptions) error {
+	if e.closed.Load() {
+		return ErrEngineClosed
+	}
+
+	if err := config.ValidateColumnFamilyName(name); err != nil {
+		return err
+	}
+
+	e.familyMu.Lock()
+	defer e.familyMu.Unlock()
+
+	if _, exists := e.families[name]; exists {
+		return fmt.Errorf("%w: %s", ErrColumnFamilyExists, name)
+	}
+
+	opts.Name = name
+	opts, err := e.cfg.AddColumnFamily(opts)
+	if err != nil {
+		return err
+	}
+
+	if err := e.openColumnFamily(opts); err != nil {
+		e.cfg.RemoveColumnFamily(name)
+		return err
+	}
+
+	return e.cfg.SaveManifest(e.dataDir)
+}
+
+// openColumnFamily starts the storage and compaction of a column family.
+// The caller must hold familyMu.
+func (e *EngineFacade) openColumnFamily(opts ColumnFamilyOptions) error {
+	owner, ok := e.storage.(*storage.Manager)
+	if !ok {
+		return fmt.Errorf("storage does not support column families")
+	}
+
+	cfg, err := e.cfg.ForColumnFamily(opts)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cfg.SSTDir, 0755); err != nil {
+		return fmt.Errorf("failed to create column family directory: %w", err)
+	}
+
+	storageManager, err := owner.NewColumnFamily(cfg, opts.ID)
+	if err != nil {
+		return fmt.Errorf("failed to open column family %s: %w", opts.Name, err)
+	}
+
+	compactionManager, err := compaction.NewManagerWithOptions(cfg, cfg.SSTDir, e.stats, coreCompaction.CompactionCoordinatorOptions{
+		Snapshots:        storageManager,
+		MergeOperator:    e.opts.MergeOperator,
+		CompactionFilter: e.opts.CompactionFilter,
+		RateLimiter:      e.rateLimiter,
+		VersionLog:       storageManager.VersionLog(),
+		Reloader:         storageManager,
+	})
+	if err != nil {
+		storageManager.Close()
+		return fmt.Errorf("failed to create compaction manager for column family %s: %w", opts.Name, err)
+	}
+	if err := compactionManager.Start(); err != nil {
+		// As for the default family, continue without background compaction
+		e.stats.TrackError("compaction_start_error")
+	}
+
+	e.families[opts.Name] = &columnFamily{
+		id:         opts.ID,
+		storage:    storageManager,
+		compaction: compactionManager,
+	}
+	return nil
+}
+
+// ListColumnFamilies returns the names of all column families, including the
+// default one
+func (e *EngineFacade) ListColumnFamilies() []string {
+	e.familyMu.RLock()
+	defer e.familyMu.RUnlock()
+
+	names := make([]string, 0, len(e.families)+1)
+	names = append(names, DefaultColumnFamily)
+	for name := range e.families {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// ColumnFamilyID returns the ID of a column family. Batch entries are applied
+// to the column family whose ID is set in their Family field.
+func (e *EngineFacade) ColumnFamilyID(name string) (uint32, error) {
+	cf, err := e.columnFamily(name)
+	if err != nil {
+		return 0, err
+	}
+	return cf.id, nil
+}
+
+// columnFamily looks up a column family by name
+func (e *EngineFacade) columnFamily(name string) (*columnFamily, error) {
+	if name == "" || name == DefaultColumnFamily {
+		return &columnFamily{
+			id:         wal.DefaultFamily,
+			storage:    e.storage,
+			compaction: e.compaction,
+		}, nil
+	}
+
+	e.familyMu.RLock()
+	defer e.familyMu.RUnlock()
+
+	cf, ok := e.families[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrColumnFamilyNotFound, name)
+	}
+	return cf, nil
+}
+
+// PutCF adds a key-value pair to a column family
+func (e *EngineFacade) PutCF(family string, key, value []byte) error {
+	if e.closed.Load() {
+		return ErrEngineClosed
+	}
+
+	// Reject writes in read-only mode
+	if e.readOnly.Load() {
+		return ErrReadOnlyMode
+	}
+
+	cf, err := e.columnFamily(family)
+	if err != nil {
+		return err
+	}
+
+	// Track the operation start
+	e.stats.TrackOperation(stats.OpPut)
+
+	// Track operation latency
+	start := time.Now()
+	err = cf.storage.Put(key, value)
+	latency := time.Since(start)
+	e.stats.TrackOperationWithLatency(stats.OpPut, uint64(latency.Nanoseconds()))
+	e.rateLimiter.RecordLatency(latency)
+
+	// Track bytes written
+	if err == nil {
+		e.stats.TrackBytes(true, uint64(len(key)+len(value)))
+	} else {
+		e.stats.TrackError("put_error")
+	}
+
+	return err
+}
+
+// GetCF retrieves the value for the given key from a column family
+func (e *EngineFacade) GetCF(family string, key []byte) ([]byte, error) {
+	if e.closed.Load() {
+		return nil, ErrEngineClosed
+	}
+
+	cf, err := e.columnFamily(family)
+	if err != nil {
+		return nil, err
+	}
+
+	// Track the operation start
+	e.stats.TrackOperation(stats.OpGet)
+
+	// Track operation latency
+	start := time.Now()
+	value, err := cf.storage.Get(key)
+	latency := time.Since(start)
+	e.stats.TrackOperationWithLatency(stats.OpGet, uint64(latency.Nanoseconds()))
+	e.rateLimiter.RecordLatency(latency)
+
+	// Track bytes read
+	if err == nil {
+		e.stats.TrackBytes(false, uint64(len(key)+len(value)))
+	}
+
+	return value, err
+}
+
+// DeleteCF removes a key from a column family
+func (e *EngineFacade) DeleteCF(family string, key []byte) error {
+	if e.closed.Load() {
+		return ErrEngineClosed
+	}
+
+	// Reject writes in read-only mode
+	if e.readOnly.Load() {
+		return ErrReadOnlyMode
+	}
+
+	cf, err := e.columnFamily(family)
+	if err != nil {
+		return err
+	}
+
+	// Track the operation start
+	e.stats.TrackOperation(stats.OpDelete)
+
+	// Track operation latency
+	start := time.Now()
+	err = cf.storage.Delete(key)
+	latencyNs := uint64(time.Since(start).Nanoseconds())
+	e.stats.TrackOperationWithLatency(stats.OpDelete, latencyNs)
+
+	// Track bytes written (just key for deletes)
+	if err == nil {
+		e.stats.TrackBytes(true, uint64(len(key)))
+
+		// Track tombstone in the column family's compaction manager
+		if cf.compaction != nil {
+			cf.compaction.TrackTombstone(key)
+		}
+	} else {
+		e.stats.TrackError("delete_error")
+	}
+
+	return err
+}
+
+// GetIteratorCF returns an iterator over a column family
+func (e *EngineFacade) GetIteratorCF(family string) (iterator.Iterator, error) {
+	return e.GetRangeIteratorCF(family, nil, nil)
+}
+
+// GetRangeIteratorCF returns an iterator over [startKey, endKey) of a column
+// family. Nil bounds leave the range open.
+func (e *EngineFacade) GetRangeIteratorCF(family string, startKey, endKey []byte) (iterator.Iterator, error) {
+	if e.closed.Load() {
+		return nil, ErrEngineClosed
+	}
+
+	cf, err := e.columnFamily(family)
+	if err != nil {
+		return nil, err
+	}
+
+	// Track the operation start
+	e.stats.TrackOperation(stats.OpScan)
+
+	// Track operation latency
+	start := time.Now()
+	var iter iterator.Iterator
+	if startKey == nil && endKey == nil {
+		iter, err = cf.storage.GetIterator()
+	} else {
+		iter, err = cf.storage.GetRangeIterator(startKey, endKey)
+	}
+	latencyNs := uint64(time.Since(start).Nanoseconds())
+	e.stats.TrackOperationWithLatency(stats.OpScan, latencyNs)
+
+	return iter, err
+}
+
+// forEachColumnFamily calls fn for every column family other than the
+// default one and returns the first error
+func (e *EngineFacade) forEachColumnFamily(fn func(cf *columnFamily) error) error {
+	e.familyMu.RLock()
+	defer e.familyMu.RUnlock()
+
+	var firstErr error
+	for _, cf := range e.families {
+		if err := fn(cf); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// closeColumnFamilies stops the compaction and closes the storage of every
+// column family other than the default one, which owns the shared WAL
+func (e *EngineFacade) closeColumnFamilies() error {
+	return e.forEachColumnFamily(func(cf *columnFamily) error {
+		compErr := cf.compaction.Stop()
+		if compErr != nil {
+			e.stats.TrackError("close_compaction_error")
+		}
+		if err := cf.storage.Close(); err != nil {
+			e.stats.TrackError("close_storage_error")
+			return err
+		}
+		return compErr
+	})
+}
@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KevoDB/kevo/pkg/config"
+	"github.com/KevoDB/kevo/pkg/wal"
+)
+
+func TestEngineFacade_ColumnFamilies(t *testing.T) {
+	dir, err := os.MkdirTemp("", "engine-facade-cf-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	eng, err := NewEngineFacade(dir)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	if err := eng.CreateColumnFamily("users", ColumnFamilyOptions{}); err != nil {
+		t.Fatalf("Failed to create column family: %v", err)
+	}
+	if err := eng.CreateColumnFamily("users", ColumnFamilyOptions{}); !errors.Is(err, ErrColumnFamilyExists) {
+		t.Errorf("Expected ErrColumnFamilyExists, got %v", err)
+	}
+	if err := eng.PutCF("missing", []byte("key"), []byte("value")); !errors.Is(err, ErrColumnFamilyNotFound) {
+		t.Errorf("Expected ErrColumnFamilyNotFound, got %v", err)
+	}
+
+	// The same key lives independently in each column family
+	if err := eng.Put([]byte("key"), []byte("default")); err != nil {
+		t.Fatalf("Failed to put: %v", err)
+	}
+	if err := eng.PutCF("users", []byte("key"), []byte("users")); err != nil {
+		t.Fatalf("Failed to put: %v", err)
+	}
+	if err := eng.PutCF("users", []byte("flushed"), []byte("value")); err != nil {
+		t.Fatalf("Failed to put: %v", err)
+	}
+	if err := eng.FlushImMemTables(); err != nil {
+		t.Fatalf("Failed to flush memtables: %v", err)
+	}
+
+	// A batch spans both column families
+	usersID, err := eng.ColumnFamilyID("users")
+	if err != nil {
+		t.Fatalf("Failed to get column family ID: %v", err)
+	}
+	err = eng.ApplyBatch([]*wal.Entry{
+		{Type: wal.OpTypePut, Key: []byte("batch"), Value: []byte("default")},
+		{Type: wal.OpTypePut, Key: []byte("batch"), Value: []byte("users"), Family: usersID},
+		{Type: wal.OpTypeDelete, Key: []byte("flushed"), Family: usersID},
+	})
+	if err != nil {
+		t.Fatalf("Failed to apply batch: %v", err)
+	}
+
+	verify := func(stage string) {
+		expected := map[string]map[string]string{
+			DefaultColumnFamily: {"key": "default", "batch": "default"},
+			"users":             {"key": "users", "batch": "users"},
+		}
+		for family, pairs := range expected {
+			for key, want := range pairs {
+				value, err := eng.GetCF(family, []byte(key))
+				if err != nil || string(value) != want {
+					t.Errorf("%s: expected %s/%s=%s, got %q, %v", stage, family, key, want, value, err)
+				}
+			}
+
+			iter, err := eng.GetIteratorCF(family)
+			if err != nil {
+				t.Fatalf("%s: failed to get iterator: %v", stage, err)
+			}
+			seen := 0
+			for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+				if iter.IsTombstone() {
+					continue
+				}
+				if want := pairs[string(iter.Key())]; string(iter.Value()) != want {
+					t.Errorf("%s: %s iterator expected %s=%s, got %q", stage, family, iter.Key(), want, iter.Value())
+				}
+				seen++
+			}
+			if seen != len(pairs) {
+				t.Errorf("%s: %s iterator expected %d keys, got %d", stage, family, len(pairs), seen)
+			}
+		}
+
+		if value, err := eng.GetCF("users", []byte("flushed")); err == nil {
+			t.Errorf("%s: expected deleted key, got %q", stage, value)
+		}
+	}
+	verify("before restart")
+
+	// The layout comes back from the manifest and the data from the shared WAL
+	if err := eng.Close(); err != nil {
+		t.Fatalf("Failed to close engine: %v", err)
+	}
+	eng, err = NewEngineFacade(dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen engine: %v", err)
+	}
+	defer eng.Close()
+
+	if names := eng.ListColumnFamilies(); len(names) != 2 || names[0] != DefaultColumnFamily || names[1] != "users" {
+		t.Errorf("Expected [default users], got %v", names)
+	}
+	verify("after restart")
+}
+
+func TestEngineFacade_ColumnFamilyCompactionStyles(t *testing.T) {
+	dir := t.TempDir()
+
+	// Families inherit the compaction style of the engine unless they set one
+	cfg := config.NewDefaultConfig(dir)
+	cfg.CompactionStyle = config.CompactionStyleFIFO
+	cfg.CompactionMaxSize = 1
+	if err := cfg.SaveManifest(dir); err != nil {
+		t.Fatalf("Failed to save configuration: %v", err)
+	}
+
+	eng, err := NewEngineFacade(dir)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	if err := eng.CreateColumnFamily("events", ColumnFamilyOptions{CompactionStyle: config.CompactionStyleFIFO}); err != nil {
+		t.Fatalf("Failed to create column family: %v", err)
+	}
+	if err := eng.CreateColumnFamily("users", ColumnFamilyOptions{CompactionStyle: config.CompactionStyleLeveled}); err != nil {
+		t.Fatalf("Failed to create column family: %v", err)
+	}
+	if err := eng.CreateColumnFamily("invalid", ColumnFamilyOptions{CompactionStyle: "random"}); !errors.Is(err, config.ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig, got %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		for _, family := range []string{"events", "users"} {
+			key := []byte(fmt.Sprintf("key-%d", i))
+			if err := eng.PutCF(family, key, []byte("value")); err != nil {
+				t.Fatalf("Failed to put: %v", err)
+			}
+		}
+		if err := eng.FlushImMemTables(); err != nil {
+			t.Fatalf("Failed to flush memtables: %v", err)
+		}
+	}
+	if err := eng.TriggerCompaction(); err != nil {
+		t.Fatalf("Failed to trigger compaction: %v", err)
+	}
+
+	sstables := func(family string) []string {
+		files, err := filepath.Glob(filepath.Join(cfg.SSTDir, family, "*.sst"))
+		if err != nil {
+			t.Fatalf("Failed to list SSTables: %v", err)
+		}
+		return files
+	}
+
+	// FIFO drops the files past its size limit
+	if files := sstables("events"); len(files) != 0 {
+		t.Errorf("Expected the events SSTables to be dropped, got %v", files)
+	}
+
+	// Leveled compaction merges L0 into L1 and ignores the size limit
+	files := sstables("users")
+	if len(files) == 0 {
+		t.Fatal("Expected the users SSTables to be kept")
+	}
+	for _, file := range files {
+		if !strings.HasPrefix(filepath.Base(file), "1_") {
+			t.Errorf("Expected only L1 files for users, got %s", filepath.Base(file))
+		}
+	}
+}
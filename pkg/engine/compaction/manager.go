@@ -29,11 +29,24 @@ type Manager struct {
 
 // NewManager creates a new compaction manager
 func NewManager(cfg *config.Config, sstableDir string, statsCollector stats.Collector) (*Manager, error) {
-	// Create compaction coordinator options
-	options := compaction.CompactionCoordinatorOptions{
-		// Use defaults for CompactionStrategy and CompactionExecutor
-		// They will be created by the coordinator
-		CompactionInterval: cfg.CompactionInterval,
+	return NewManagerWithSnapshots(cfg, sstableDir, statsCollector, nil)
+}
+
+// NewManagerWithSnapshots creates a new compaction manager that preserves
+// the versions visible to the live snapshots reported by the provider
+func NewManagerWithSnapshots(cfg *config.Config, sstableDir string, statsCollector stats.Collector, snapshots compaction.SnapshotProvider) (*Manager, error) {
+	// Use defaults for CompactionStrategy and CompactionExecutor
+	// They will be created by the coordinator
+	return NewManagerWithOptions(cfg, sstableDir, statsCollector, compaction.CompactionCoordinatorOptions{
+		Snapshots: snapshots,
+	})
+}
+
+// NewManagerWithOptions creates a new compaction manager with the given
+// coordinator options. The compaction interval defaults to the configured one.
+func NewManagerWithOptions(cfg *config.Config, sstableDir string, statsCollector stats.Collector, options compaction.CompactionCoordinatorOptions) (*Manager, error) {
+	if options.CompactionInterval <= 0 {
+		options.CompactionInterval = cfg.CompactionInterval
 	}
 
 	// Create the compaction coordinator
@@ -164,6 +177,16 @@ func (m *Manager) ForcePreserveTombstone(key []byte) {
 	m.stats.TrackBytes(false, uint64(len(key)))
 }
 
+// RunExclusive calls fn while no compaction is running
+func (m *Manager) RunExclusive(fn func() error) error {
+	if coordinator, ok := m.coordinator.(interface {
+		RunExclusive(fn func() error) error
+	}); ok {
+		return coordinator.RunExclusive(fn)
+	}
+	return fn()
+}
+
 // GetCompactionStats returns statistics about the compaction state
 func (m *Manager) GetCompactionStats() map[string]interface{} {
 	// Get stats from the coordinator
@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/KevoDB/kevo/pkg/engine/interfaces"
+	"github.com/KevoDB/kevo/pkg/stats"
+	"github.com/KevoDB/kevo/pkg/wal"
+)
+
+// Precondition is a check on the current value of a key made by ApplyBatchIf
+type Precondition = interfaces.Precondition
+
+// ConditionResult reports whether a conditional write was applied
+type ConditionResult = interfaces.ConditionResult
+
+// Precondition types
+const (
+	PreconditionEquals = interfaces.PreconditionEquals
+	PreconditionAbsent = interfaces.PreconditionAbsent
+	PreconditionExists = interfaces.PreconditionExists
+
+	PreconditionUnchanged      = interfaces.PreconditionUnchanged
+	PreconditionRangeUnchanged = interfaces.PreconditionRangeUnchanged
+)
+
+// CompareAndSwap sets key to value if its current value is expected. The
+// result holds the value of the key after the call.
+func (e *EngineFacade) CompareAndSwap(key, expected, value []byte) (ConditionResult, error) {
+	return e.conditionalWrite(stats.OpPut,
+		&wal.Entry{Type: wal.OpTypePut, Key: key, Value: value},
+		Precondition{Key: key, Type: PreconditionEquals, Value: expected})
+}
+
+// PutIfAbsent sets key to value if the key doesn't exist. The result holds the
+// value of the key after the call.
+func (e *EngineFacade) PutIfAbsent(key, value []byte) (ConditionResult, error) {
+	return e.conditionalWrite(stats.OpPut,
+		&wal.Entry{Type: wal.OpTypePut, Key: key, Value: value},
+		Precondition{Key: key, Type: PreconditionAbsent})
+}
+
+// DeleteIfEquals removes key if its current value is expected. The result
+// holds the value of the key after the call.
+func (e *EngineFacade) DeleteIfEquals(key, expected []byte) (ConditionResult, error) {
+	return e.conditionalWrite(stats.OpDelete,
+		&wal.Entry{Type: wal.OpTypeDelete, Key: key},
+		Precondition{Key: key, Type: PreconditionEquals, Value: expected})
+}
+
+// conditionalWrite applies a single-key write guarded by one precondition
+func (e *EngineFacade) conditionalWrite(op stats.OperationType, entry *wal.Entry, condition Precondition) (ConditionResult, error) {
+	result, err := e.applyBatchIf(op, []*wal.Entry{entry}, []Precondition{condition})
+	if err != nil || !result.Applied {
+		return result, err
+	}
+
+	// Report the value that was written
+	if entry.Type == wal.OpTypePut {
+		result.Value = entry.Value
+		result.Found = true
+	}
+	return result, nil
+}
+
+// ApplyBatchIf atomically applies a batch of operations if all of the
+// preconditions hold. Preconditions and entries name their column family by
+// ID, as in ApplyBatch. When a precondition fails nothing is written and the
+// result holds its index and the current value of its key.
+func (e *EngineFacade) ApplyBatchIf(entries []*wal.Entry, conditions []Precondition) (ConditionResult, error) {
+	return e.applyBatchIf(stats.OpPut, entries, conditions)
+}
+
+// applyBatchIf applies a conditional batch and tracks it as op
+func (e *EngineFacade) applyBatchIf(op stats.OperationType, entries []*wal.Entry, conditions []Precondition) (ConditionResult, error) {
+	result := ConditionResult{Failed: -1}
+
+	if e.closed.Load() {
+		return result, ErrEngineClosed
+	}
+
+	// Reject writes in read-only mode
+	if e.readOnly.Load() {
+		return result, ErrReadOnlyMode
+	}
+
+	// Track the operation start
+	e.stats.TrackOperation(op)
+
+	// Count bytes for statistics
+	var totalBytes uint64
+	for _, entry := range entries {
+		totalBytes += uint64(len(entry.Key) + len(entry.Value))
+	}
+
+	// Track operation latency
+	start := time.Now()
+	result, err := e.storage.ApplyBatchIf(entries, conditions)
+	latencyNs := uint64(time.Since(start).Nanoseconds())
+	e.stats.TrackOperationWithLatency(op, latencyNs)
+
+	if err != nil {
+		e.stats.TrackError("conditional_write_error")
+		return result, err
+	}
+
+	if result.Applied {
+		e.stats.TrackBytes(true, totalBytes)
+
+		// Track tombstones in compaction manager for delete operations
+		if e.compaction != nil {
+			for _, entry := range entries {
+				if entry.Type == wal.OpTypeDelete && entry.Family == wal.DefaultFamily {
+					e.compaction.TrackTombstone(entry.Key)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
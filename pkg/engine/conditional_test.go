@@ -0,0 +1,214 @@
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/KevoDB/kevo/pkg/transaction"
+	"github.com/KevoDB/kevo/pkg/wal"
+)
+
+func TestEngineFacade_ConditionalWrites(t *testing.T) {
+	dir, err := os.MkdirTemp("", "engine-facade-conditional-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	eng, err := NewEngineFacade(dir)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	key := []byte("counter")
+
+	// PutIfAbsent only writes the first time
+	result, err := eng.PutIfAbsent(key, []byte("1"))
+	if err != nil || !result.Applied || !result.Found || string(result.Value) != "1" {
+		t.Fatalf("Expected first PutIfAbsent to apply, got %+v, %v", result, err)
+	}
+	result, err = eng.PutIfAbsent(key, []byte("2"))
+	if err != nil || result.Applied || result.Failed != 0 || string(result.Value) != "1" {
+		t.Fatalf("Expected second PutIfAbsent to fail with current value 1, got %+v, %v", result, err)
+	}
+
+	// CompareAndSwap reports the conflicting value, also once it is on disk
+	if err := eng.FlushImMemTables(); err != nil {
+		t.Fatalf("Failed to flush memtables: %v", err)
+	}
+	result, err = eng.CompareAndSwap(key, []byte("0"), []byte("2"))
+	if err != nil || result.Applied || string(result.Value) != "1" {
+		t.Fatalf("Expected CompareAndSwap to fail with current value 1, got %+v, %v", result, err)
+	}
+	result, err = eng.CompareAndSwap(key, []byte("1"), []byte("2"))
+	if err != nil || !result.Applied || string(result.Value) != "2" {
+		t.Fatalf("Expected CompareAndSwap to apply, got %+v, %v", result, err)
+	}
+	if value, err := eng.Get(key); err != nil || string(value) != "2" {
+		t.Errorf("Expected counter=2, got %q, %v", value, err)
+	}
+
+	// DeleteIfEquals leaves the key alone unless the value matches
+	result, err = eng.DeleteIfEquals(key, []byte("1"))
+	if err != nil || result.Applied || string(result.Value) != "2" {
+		t.Fatalf("Expected DeleteIfEquals to fail with current value 2, got %+v, %v", result, err)
+	}
+	result, err = eng.DeleteIfEquals(key, []byte("2"))
+	if err != nil || !result.Applied || result.Found {
+		t.Fatalf("Expected DeleteIfEquals to apply, got %+v, %v", result, err)
+	}
+	if _, err := eng.Get(key); err == nil {
+		t.Errorf("Expected counter to be deleted")
+	}
+
+	// A deleted key is absent again
+	result, err = eng.CompareAndSwap(key, nil, []byte("3"))
+	if err != nil || result.Applied || result.Found {
+		t.Fatalf("Expected CompareAndSwap on a deleted key to fail, got %+v, %v", result, err)
+	}
+
+	// A batch is applied only if every precondition holds
+	if err := eng.Put([]byte("a"), []byte("1")); err != nil {
+		t.Fatalf("Failed to put: %v", err)
+	}
+	entries := []*wal.Entry{
+		{Type: wal.OpTypePut, Key: []byte("a"), Value: []byte("2")},
+		{Type: wal.OpTypePut, Key: []byte("b"), Value: []byte("1")},
+	}
+	result, err = eng.ApplyBatchIf(entries, []Precondition{
+		{Key: []byte("a"), Type: PreconditionExists},
+		{Key: []byte("b"), Type: PreconditionEquals, Value: []byte("0")},
+	})
+	if err != nil || result.Applied || result.Failed != 1 || result.Found {
+		t.Fatalf("Expected the second precondition to fail, got %+v, %v", result, err)
+	}
+	if value, err := eng.Get([]byte("a")); err != nil || string(value) != "1" {
+		t.Errorf("Expected a=1 after failed batch, got %q, %v", value, err)
+	}
+
+	result, err = eng.ApplyBatchIf(entries, []Precondition{
+		{Key: []byte("a"), Type: PreconditionEquals, Value: []byte("1")},
+		{Key: []byte("b"), Type: PreconditionAbsent},
+	})
+	if err != nil || !result.Applied || result.Failed != -1 {
+		t.Fatalf("Expected batch to apply, got %+v, %v", result, err)
+	}
+	for key, want := range map[string]string{"a": "2", "b": "1"} {
+		if value, err := eng.Get([]byte(key)); err != nil || string(value) != want {
+			t.Errorf("Expected %s=%s, got %q, %v", key, want, value, err)
+		}
+	}
+
+	// Sequence preconditions hold while the keys aren't written again
+	snap, err := eng.GetSnapshot()
+	if err != nil {
+		t.Fatalf("Failed to get snapshot: %v", err)
+	}
+	seqNum := snap.SequenceNumber()
+	eng.ReleaseSnapshot(snap)
+	if err := eng.Put([]byte("d"), []byte("1")); err != nil {
+		t.Fatalf("Failed to put: %v", err)
+	}
+	result, err = eng.ApplyBatchIf(entries, []Precondition{
+		{Key: []byte("a"), Type: PreconditionUnchanged, Sequence: seqNum},
+		{Key: []byte("a"), End: []byte("c"), Type: PreconditionRangeUnchanged, Sequence: seqNum},
+		{Key: []byte("c"), End: []byte("e"), Type: PreconditionRangeUnchanged, Sequence: seqNum},
+	})
+	if err != nil || result.Applied || result.Failed != 2 {
+		t.Fatalf("Expected the range holding d to fail, got %+v, %v", result, err)
+	}
+	result, err = eng.ApplyBatchIf(entries, []Precondition{
+		{Key: []byte("d"), Type: PreconditionUnchanged, Sequence: seqNum},
+	})
+	if err != nil || result.Applied || result.Failed != 0 {
+		t.Fatalf("Expected the rewritten key to fail, got %+v, %v", result, err)
+	}
+
+	// Conditional writes are rejected on replicas
+	eng.SetReadOnly(true)
+	if _, err := eng.PutIfAbsent([]byte("c"), []byte("1")); err != ErrReadOnlyMode {
+		t.Errorf("Expected ErrReadOnlyMode, got %v", err)
+	}
+}
+
+func TestEngineFacade_TransactionsWithPlainWrites(t *testing.T) {
+	eng, err := NewEngineFacade(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	key := []byte("counter")
+	if err := eng.Put(key, []byte("0")); err != nil {
+		t.Fatalf("Failed to put: %v", err)
+	}
+
+	// Transactions and conditional writes outside of them increment the same
+	// counter. A write coming in between a transaction's checks and its
+	// batch would be lost.
+	const workers, increments = 4, 50
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < increments; {
+				tx, err := eng.BeginTransaction(false)
+				if err != nil {
+					errs <- err
+					return
+				}
+				value, err := tx.Get(key)
+				if err != nil {
+					tx.Rollback()
+					errs <- err
+					return
+				}
+				count, _ := strconv.Atoi(string(value))
+				tx.Put(key, []byte(strconv.Itoa(count+1)))
+				if err := tx.Commit(); errors.Is(err, transaction.ErrConflict) {
+					continue
+				} else if err != nil {
+					errs <- err
+					return
+				}
+				n++
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for n := 0; n < increments; {
+				value, err := eng.Get(key)
+				if err != nil {
+					errs <- err
+					return
+				}
+				count, _ := strconv.Atoi(string(value))
+				result, err := eng.CompareAndSwap(key, value, []byte(strconv.Itoa(count+1)))
+				if err != nil {
+					errs <- err
+					return
+				}
+				if result.Applied {
+					n++
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Failed to increment: %v", err)
+	}
+
+	want := []byte(strconv.Itoa(2 * workers * increments))
+	if value, err := eng.Get(key); err != nil || !bytes.Equal(value, want) {
+		t.Errorf("Expected counter=%s, got %q, %v", want, value, err)
+	}
+}
@@ -0,0 +1,218 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/KevoDB/kevo/pkg/common/merge"
+	"github.com/KevoDB/kevo/pkg/common/rangedel"
+	"github.com/KevoDB/kevo/pkg/wal"
+)
+
+func TestEngineFacade_DeleteRange(t *testing.T) {
+	dir, err := os.MkdirTemp("", "engine-facade-delete-range-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	eng, err := NewEngineFacade(dir)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	if err := eng.DeleteRange([]byte("b"), []byte("a")); !errors.Is(err, rangedel.ErrInvalidRange) {
+		t.Errorf("Expected ErrInvalidRange, got %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("key%d", i)
+		if err := eng.Put([]byte(key), []byte("v1")); err != nil {
+			t.Fatalf("Failed to put key: %v", err)
+		}
+	}
+
+	// Keep the first versions on disk so the range deletion has to hide them
+	if err := eng.FlushImMemTables(); err != nil {
+		t.Fatalf("Failed to flush memtables: %v", err)
+	}
+
+	snap, err := eng.GetSnapshot()
+	if err != nil {
+		t.Fatalf("Failed to get snapshot: %v", err)
+	}
+	defer eng.ReleaseSnapshot(snap)
+
+	if err := eng.DeleteRange([]byte("key3"), []byte("key7")); err != nil {
+		t.Fatalf("Failed to delete range: %v", err)
+	}
+
+	// Keys written after the range deletion are visible again
+	if err := eng.Put([]byte("key5"), []byte("v2")); err != nil {
+		t.Fatalf("Failed to put key: %v", err)
+	}
+
+	expected := map[string]string{
+		"key0": "v1", "key1": "v1", "key2": "v1", "key5": "v2",
+		"key7": "v1", "key8": "v1", "key9": "v1",
+	}
+
+	verify := func(stage string) {
+		for i := 0; i < 10; i++ {
+			key := fmt.Sprintf("key%d", i)
+			value, err := eng.Get([]byte(key))
+			if want, ok := expected[key]; ok {
+				if err != nil || string(value) != want {
+					t.Errorf("%s: expected %s=%s, got %s (err: %v)", stage, key, want, value, err)
+				}
+			} else if err == nil {
+				t.Errorf("%s: expected %s to be deleted, got %s", stage, key, value)
+			}
+		}
+
+		iter, err := eng.GetIterator()
+		if err != nil {
+			t.Fatalf("%s: failed to get iterator: %v", stage, err)
+		}
+		seen := 0
+		for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+			if iter.IsTombstone() {
+				continue
+			}
+			if want := expected[string(iter.Key())]; string(iter.Value()) != want {
+				t.Errorf("%s: iterator expected %s=%s, got %s", stage, iter.Key(), want, iter.Value())
+			}
+			seen++
+		}
+		if seen != len(expected) {
+			t.Errorf("%s: expected %d keys from iterator, got %d", stage, len(expected), seen)
+		}
+
+		// The snapshot was taken before the range deletion
+		if value, err := snap.Get([]byte("key4")); err != nil || string(value) != "v1" {
+			t.Errorf("%s: expected snapshot value v1, got %s (err: %v)", stage, value, err)
+		}
+	}
+
+	verify("memtable")
+
+	if err := eng.FlushImMemTables(); err != nil {
+		t.Fatalf("Failed to flush memtables: %v", err)
+	}
+	verify("after flush")
+
+	// Range deletions survive a restart
+	if err := eng.Close(); err != nil {
+		t.Fatalf("Failed to close engine: %v", err)
+	}
+	eng, err = NewEngineFacade(dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen engine: %v", err)
+	}
+	defer eng.Close()
+
+	for key, want := range expected {
+		if value, err := eng.Get([]byte(key)); err != nil || string(value) != want {
+			t.Errorf("after reopen: expected %s=%s, got %s (err: %v)", key, want, value, err)
+		}
+	}
+	if value, err := eng.Get([]byte("key4")); err == nil {
+		t.Errorf("after reopen: expected key4 to be deleted, got %s", value)
+	}
+}
+
+func TestEngineFacade_DeleteRangeBatch(t *testing.T) {
+	dir, err := os.MkdirTemp("", "engine-facade-delete-range-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	opts := Options{MergeOperator: merge.CounterOperator{}}
+	eng, err := NewEngineFacadeWithOptions(dir, opts)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	if err := eng.Put([]byte("b"), []byte("1")); err != nil {
+		t.Fatalf("Failed to put key: %v", err)
+	}
+
+	// The entries of a batch share a sequence number. Writes to the same key
+	// apply in order, and the range deletion only hides older writes.
+	err = eng.ApplyBatch([]*wal.Entry{
+		{Type: wal.OpTypeDeleteRange, Key: []byte("a"), Value: []byte("z")},
+		{Type: wal.OpTypeMerge, Key: []byte("c"), Value: []byte("1")},
+		{Type: wal.OpTypeMerge, Key: []byte("c"), Value: []byte("1")},
+		{Type: wal.OpTypePut, Key: []byte("d"), Value: []byte("10")},
+		{Type: wal.OpTypeMerge, Key: []byte("d"), Value: []byte("5")},
+		{Type: wal.OpTypePut, Key: []byte("e"), Value: []byte("1")},
+		{Type: wal.OpTypeDelete, Key: []byte("e")},
+		{Type: wal.OpTypeMerge, Key: []byte("f"), Value: []byte("1")},
+		{Type: wal.OpTypeMerge, Key: []byte("f"), Value: []byte("x")},
+	})
+	if err != nil {
+		t.Fatalf("Failed to apply batch: %v", err)
+	}
+
+	expected := map[string]string{"c": "2", "d": "15"}
+	verify := func(stage string) {
+		for key, want := range expected {
+			value, err := eng.Get([]byte(key))
+			if err != nil {
+				t.Fatalf("%s: failed to get %s: %v", stage, key, err)
+			}
+			if string(value) != want {
+				t.Errorf("%s: expected %s=%s, got %s", stage, key, want, value)
+			}
+		}
+		for _, key := range []string{"b", "e"} {
+			if value, err := eng.Get([]byte(key)); err == nil {
+				t.Errorf("%s: expected %s to be deleted, got %s", stage, key, value)
+			}
+		}
+
+		// Operands that can't be combined are kept as they were written
+		if value, err := eng.Get([]byte("f")); err == nil {
+			t.Errorf("%s: expected a merge error for f, got %s", stage, value)
+		}
+
+		iter, err := eng.GetIterator()
+		if err != nil {
+			t.Fatalf("%s: failed to get iterator: %v", stage, err)
+		}
+		seen := 0
+		for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+			if iter.IsTombstone() {
+				continue
+			}
+			if want, ok := expected[string(iter.Key())]; !ok || string(iter.Value()) != want {
+				t.Errorf("%s: iterator expected %s=%s, got %s", stage, iter.Key(), want, iter.Value())
+			}
+			seen++
+		}
+		if seen != len(expected) {
+			t.Errorf("%s: expected %d keys from iterator, got %d", stage, len(expected), seen)
+		}
+	}
+
+	verify("memtable")
+
+	if err := eng.FlushImMemTables(); err != nil {
+		t.Fatalf("Failed to flush memtables: %v", err)
+	}
+	verify("after flush")
+
+	if err := eng.Close(); err != nil {
+		t.Fatalf("Failed to close engine: %v", err)
+	}
+	eng, err = NewEngineFacadeWithOptions(dir, opts)
+	if err != nil {
+		t.Fatalf("Failed to reopen engine: %v", err)
+	}
+	defer eng.Close()
+
+	verify("after reopen")
+}
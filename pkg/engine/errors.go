@@ -1,6 +1,12 @@
 package engine
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/KevoDB/kevo/pkg/common/merge"
+	"github.com/KevoDB/kevo/pkg/common/ttl"
+	"github.com/KevoDB/kevo/pkg/engine/storage"
+)
 
 var (
 	// ErrEngineClosed is returned when operations are performed on a closed engine
@@ -9,4 +15,21 @@ var (
 	ErrKeyNotFound = errors.New("key not found")
 	// ErrReadOnlyMode is returned when write operations are attempted while the engine is in read-only mode
 	ErrReadOnlyMode = errors.New("engine is in read-only mode (replica)")
+	// ErrSnapshotReleased is returned when reading through a snapshot that has been released
+	ErrSnapshotReleased = errors.New("snapshot has been released")
+	// ErrNoMergeOperator is returned by Merge when the engine has no merge operator
+	ErrNoMergeOperator = merge.ErrNoOperator
+	// ErrInvalidTTL is returned by PutWithTTL when the TTL is not positive
+	ErrInvalidTTL = ttl.ErrInvalidTTL
+	// ErrColumnFamilyNotFound is returned when an operation names an unknown column family
+	ErrColumnFamilyNotFound = errors.New("column family not found")
+	// ErrColumnFamilyExists is returned when creating a column family that already exists
+	ErrColumnFamilyExists = errors.New("column family already exists")
+	// ErrCheckpointExists is returned when creating a checkpoint in a directory that already exists
+	ErrCheckpointExists = errors.New("checkpoint directory already exists")
+	// ErrIngestOverlap is returned by IngestFiles when the files overlap each other
+	ErrIngestOverlap = storage.ErrIngestOverlap
+	// ErrIngestMemTableOverlap is returned by IngestFiles when the files overlap
+	// keys still held in memory
+	ErrIngestMemTableOverlap = storage.ErrIngestMemTableOverlap
 )
@@ -4,10 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/KevoDB/kevo/pkg/common/iterator"
+	"github.com/KevoDB/kevo/pkg/common/ratelimit"
+	"github.com/KevoDB/kevo/pkg/common/ttl"
+	coreCompaction "github.com/KevoDB/kevo/pkg/compaction"
 	"github.com/KevoDB/kevo/pkg/config"
 	"github.com/KevoDB/kevo/pkg/engine/compaction"
 	"github.com/KevoDB/kevo/pkg/engine/interfaces"
@@ -27,6 +31,7 @@ type EngineFacade struct {
 	// Configuration
 	cfg     *config.Config
 	dataDir string
+	opts    Options
 
 	// Core components
 	storage    interfaces.StorageManager
@@ -34,6 +39,13 @@ type EngineFacade struct {
 	compaction interfaces.CompactionManager
 	stats      stats.Collector
 
+	// Limits the write rate of flushes and compactions of all column families
+	rateLimiter *ratelimit.Limiter
+
+	// Column families other than the default one, by name
+	familyMu sync.RWMutex
+	families map[string]*columnFamily
+
 	// State
 	closed   atomic.Bool
 	readOnly atomic.Bool // Flag to indicate if the engine is in read-only mode (for replicas)
@@ -51,6 +63,11 @@ func NewEngine(dataDir string) (*EngineFacade, error) {
 // NewEngineFacade creates a new storage engine using the facade pattern
 // This will eventually replace NewEngine once the refactoring is complete
 func NewEngineFacade(dataDir string) (*EngineFacade, error) {
+	return NewEngineFacadeWithOptions(dataDir, Options{})
+}
+
+// NewEngineFacadeWithOptions creates a new storage engine with the given options
+func NewEngineFacadeWithOptions(dataDir string, opts Options) (*EngineFacade, error) {
 	// Create data and component directories
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
@@ -78,12 +95,20 @@ func NewEngineFacade(dataDir string) (*EngineFacade, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create storage manager: %w", err)
 	}
+	storageManager.SetMergeOperator(opts.MergeOperator)
 
 	// Create the transaction manager
 	txManager := transaction.NewManager(storageManager, statsCollector)
 
 	// Create the compaction manager
-	compactionManager, err := compaction.NewManager(cfg, cfg.SSTDir, statsCollector)
+	compactionManager, err := compaction.NewManagerWithOptions(cfg, cfg.SSTDir, statsCollector, coreCompaction.CompactionCoordinatorOptions{
+		Snapshots:        storageManager,
+		MergeOperator:    opts.MergeOperator,
+		CompactionFilter: opts.CompactionFilter,
+		RateLimiter:      storageManager.RateLimiter(),
+		VersionLog:       storageManager.VersionLog(),
+		Reloader:         storageManager,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create compaction manager: %w", err)
 	}
@@ -92,12 +117,24 @@ func NewEngineFacade(dataDir string) (*EngineFacade, error) {
 	facade := &EngineFacade{
 		cfg:     cfg,
 		dataDir: dataDir,
+		opts:    opts,
 
 		// Initialize components
 		storage:    storageManager,
 		txManager:  txManager,
 		compaction: compactionManager,
 		stats:      statsCollector,
+		families:   make(map[string]*columnFamily),
+
+		rateLimiter: storageManager.RateLimiter(),
+	}
+
+	// Open the column families recorded in the manifest
+	for _, familyOpts := range cfg.GetColumnFamilies() {
+		if err := facade.openColumnFamily(familyOpts); err != nil {
+			facade.Close()
+			return nil, err
+		}
 	}
 
 	// Start the compaction manager
@@ -130,8 +167,9 @@ func (e *EngineFacade) Put(key, value []byte) error {
 	// Delegate to storage component
 	err := e.storage.Put(key, value)
 
-	latencyNs := uint64(time.Since(start).Nanoseconds())
-	e.stats.TrackOperationWithLatency(stats.OpPut, latencyNs)
+	latency := time.Since(start)
+	e.stats.TrackOperationWithLatency(stats.OpPut, uint64(latency.Nanoseconds()))
+	e.rateLimiter.RecordLatency(latency)
 
 	// Track bytes written
 	if err == nil {
@@ -159,8 +197,61 @@ func (e *EngineFacade) PutInternal(key, value []byte) error {
 	// Delegate to storage component
 	err := e.storage.Put(key, value)
 
-	latencyNs := uint64(time.Since(start).Nanoseconds())
-	e.stats.TrackOperationWithLatency(stats.OpPut, latencyNs)
+	latency := time.Since(start)
+	e.stats.TrackOperationWithLatency(stats.OpPut, uint64(latency.Nanoseconds()))
+	e.rateLimiter.RecordLatency(latency)
+
+	// Track bytes written
+	if err == nil {
+		e.stats.TrackBytes(true, uint64(len(key)+len(value)))
+	} else {
+		e.stats.TrackError("put_error")
+	}
+
+	return err
+}
+
+// PutWithTTL adds a key-value pair that expires after ttl. Once expired the key
+// is no longer visible to reads, and compaction removes it from disk.
+func (e *EngineFacade) PutWithTTL(key, value []byte, ttlDuration time.Duration) error {
+	if e.closed.Load() {
+		return ErrEngineClosed
+	}
+
+	// Reject writes in read-only mode
+	if e.readOnly.Load() {
+		return ErrReadOnlyMode
+	}
+
+	// The expiry is fixed at write time so that replicas don't depend on their own clock
+	expireAt, err := ttl.ExpireAt(time.Now(), ttlDuration)
+	if err != nil {
+		return err
+	}
+
+	return e.PutWithExpiryInternal(key, value, expireAt)
+}
+
+// PutWithExpiryInternal adds a key-value pair that expires at expireAt, in Unix
+// nanoseconds, bypassing the read-only check.
+// This is used by replication to apply expiring writes with the primary's expiry time
+func (e *EngineFacade) PutWithExpiryInternal(key, value []byte, expireAt uint64) error {
+	if e.closed.Load() {
+		return ErrEngineClosed
+	}
+
+	// Track the operation start
+	e.stats.TrackOperation(stats.OpPut)
+
+	// Track operation latency
+	start := time.Now()
+
+	// Delegate to storage component
+	err := e.storage.PutWithExpiry(key, value, expireAt)
+
+	latency := time.Since(start)
+	e.stats.TrackOperationWithLatency(stats.OpPut, uint64(latency.Nanoseconds()))
+	e.rateLimiter.RecordLatency(latency)
 
 	// Track bytes written
 	if err == nil {
@@ -187,8 +278,9 @@ func (e *EngineFacade) Get(key []byte) ([]byte, error) {
 	// Delegate to storage component
 	value, err := e.storage.Get(key)
 
-	latencyNs := uint64(time.Since(start).Nanoseconds())
-	e.stats.TrackOperationWithLatency(stats.OpGet, latencyNs)
+	latency := time.Since(start)
+	e.stats.TrackOperationWithLatency(stats.OpGet, uint64(latency.Nanoseconds()))
+	e.rateLimiter.RecordLatency(latency)
 
 	// Track bytes read
 	if err == nil {
@@ -274,6 +366,94 @@ func (e *EngineFacade) DeleteInternal(key []byte) error {
 	return err
 }
 
+// DeleteRange removes every key in [startKey, endKey) from the database with a
+// single range tombstone
+func (e *EngineFacade) DeleteRange(startKey, endKey []byte) error {
+	if e.closed.Load() {
+		return ErrEngineClosed
+	}
+
+	// Reject writes in read-only mode
+	if e.readOnly.Load() {
+		return ErrReadOnlyMode
+	}
+
+	return e.DeleteRangeInternal(startKey, endKey)
+}
+
+// DeleteRangeInternal removes every key in [startKey, endKey), bypassing the read-only check
+// This is used by replication to apply range deletions even when in read-only mode
+func (e *EngineFacade) DeleteRangeInternal(startKey, endKey []byte) error {
+	if e.closed.Load() {
+		return ErrEngineClosed
+	}
+
+	// Track the operation start
+	e.stats.TrackOperation(stats.OpDeleteRange)
+
+	// Track operation latency
+	start := time.Now()
+
+	// Delegate to storage component
+	err := e.storage.DeleteRange(startKey, endKey)
+
+	latencyNs := uint64(time.Since(start).Nanoseconds())
+	e.stats.TrackOperationWithLatency(stats.OpDeleteRange, latencyNs)
+
+	// Track bytes written (just the bounds of the range)
+	if err == nil {
+		e.stats.TrackBytes(true, uint64(len(startKey)+len(endKey)))
+	} else {
+		e.stats.TrackError("delete_range_error")
+	}
+
+	return err
+}
+
+// Merge adds a merge operand for a key. The operand is combined with the
+// existing value by the configured merge operator when the key is read.
+func (e *EngineFacade) Merge(key, operand []byte) error {
+	if e.closed.Load() {
+		return ErrEngineClosed
+	}
+
+	// Reject writes in read-only mode
+	if e.readOnly.Load() {
+		return ErrReadOnlyMode
+	}
+
+	return e.MergeInternal(key, operand)
+}
+
+// MergeInternal adds a merge operand for a key, bypassing the read-only check
+// This is used by replication to apply merge operations even when in read-only mode
+func (e *EngineFacade) MergeInternal(key, operand []byte) error {
+	if e.closed.Load() {
+		return ErrEngineClosed
+	}
+
+	// Track the operation start
+	e.stats.TrackOperation(stats.OpMerge)
+
+	// Track operation latency
+	start := time.Now()
+
+	// Delegate to storage component
+	err := e.storage.Merge(key, operand)
+
+	latencyNs := uint64(time.Since(start).Nanoseconds())
+	e.stats.TrackOperationWithLatency(stats.OpMerge, latencyNs)
+
+	// Track bytes written
+	if err == nil {
+		e.stats.TrackBytes(true, uint64(len(key)+len(operand)))
+	} else {
+		e.stats.TrackError("merge_error")
+	}
+
+	return err
+}
+
 // IsDeleted returns true if the key exists and is marked as deleted
 func (e *EngineFacade) IsDeleted(key []byte) (bool, error) {
 	if e.closed.Load() {
@@ -332,15 +512,41 @@ func (e *EngineFacade) GetRangeIterator(startKey, endKey []byte) (iterator.Itera
 	return iter, err
 }
 
+// GetRangeIteratorWithOptions returns an iterator limited to a specific key
+// range that reads SSTable blocks as configured by opts. Nil keys leave the
+// range unbounded on that side.
+func (e *EngineFacade) GetRangeIteratorWithOptions(startKey, endKey []byte, opts ReadOptions) (iterator.Iterator, error) {
+	if e.closed.Load() {
+		return nil, ErrEngineClosed
+	}
+
+	// Track the operation start with the range-specific operation type
+	e.stats.TrackOperation(stats.OpScanRange)
+
+	// Track operation latency
+	start := time.Now()
+	iter, err := e.storage.GetRangeIteratorWithOptions(startKey, endKey, opts)
+	latencyNs := uint64(time.Since(start).Nanoseconds())
+	e.stats.TrackOperationWithLatency(stats.OpScanRange, latencyNs)
+
+	return iter, err
+}
+
 // BeginTransaction starts a new transaction with the given read-only flag
 func (e *EngineFacade) BeginTransaction(readOnly bool) (interfaces.Transaction, error) {
+	return e.BeginTransactionWithOptions(transaction.TxOptions{ReadOnly: readOnly})
+}
+
+// BeginTransactionWithOptions starts a new transaction with the given options,
+// such as the isolation level
+func (e *EngineFacade) BeginTransactionWithOptions(opts transaction.TxOptions) (interfaces.Transaction, error) {
 	if e.closed.Load() {
 		return nil, ErrEngineClosed
 	}
 
 	// Force read-only mode if engine is in read-only mode
 	if e.readOnly.Load() {
-		readOnly = true
+		opts.ReadOnly = true
 	}
 
 	// Track the operation start
@@ -348,7 +554,7 @@ func (e *EngineFacade) BeginTransaction(readOnly bool) (interfaces.Transaction,
 
 	// Track operation latency
 	start := time.Now()
-	tx, err := e.txManager.BeginTransaction(readOnly)
+	tx, err := e.txManager.BeginTransactionWithOptions(opts)
 	latencyNs := uint64(time.Since(start).Nanoseconds())
 	e.stats.TrackOperationWithLatency(stats.OpTxBegin, latencyNs)
 
@@ -391,7 +597,7 @@ func (e *EngineFacade) ApplyBatch(entries []*wal.Entry) error {
 		// Track tombstones in compaction manager for delete operations
 		if e.compaction != nil {
 			for _, entry := range entries {
-				if entry.Type == wal.OpTypeDelete {
+				if entry.Type == wal.OpTypeDelete && entry.Family == wal.DefaultFamily {
 					e.compaction.TrackTombstone(entry.Key)
 				}
 			}
@@ -435,7 +641,7 @@ func (e *EngineFacade) ApplyBatchInternal(entries []*wal.Entry) error {
 		// Track tombstones in compaction manager for delete operations
 		if e.compaction != nil {
 			for _, entry := range entries {
-				if entry.Type == wal.OpTypeDelete {
+				if entry.Type == wal.OpTypeDelete && entry.Family == wal.DefaultFamily {
 					e.compaction.TrackTombstone(entry.Key)
 				}
 			}
@@ -458,7 +664,12 @@ func (e *EngineFacade) FlushImMemTables() error {
 
 	// Track operation latency
 	start := time.Now()
-	err := e.storage.FlushMemTables()
+	err := e.forEachColumnFamily(func(cf *columnFamily) error {
+		return cf.storage.FlushMemTables()
+	})
+	if flushErr := e.storage.FlushMemTables(); err == nil {
+		err = flushErr
+	}
 	latencyNs := uint64(time.Since(start).Nanoseconds())
 	e.stats.TrackOperationWithLatency(stats.OpFlush, latencyNs)
 
@@ -476,7 +687,12 @@ func (e *EngineFacade) TriggerCompaction() error {
 
 	// Track operation latency
 	start := time.Now()
-	err := e.compaction.TriggerCompaction()
+	err := e.forEachColumnFamily(func(cf *columnFamily) error {
+		return cf.compaction.TriggerCompaction()
+	})
+	if compErr := e.compaction.TriggerCompaction(); err == nil {
+		err = compErr
+	}
 	latencyNs := uint64(time.Since(start).Nanoseconds())
 	e.stats.TrackOperationWithLatency(stats.OpCompact, latencyNs)
 
@@ -519,6 +735,24 @@ func (e *EngineFacade) CompactRange(startKey, endKey []byte) error {
 	return err
 }
 
+// SetIORateLimit changes the number of bytes per second flushes and
+// compactions may write, zero for no limit. The new limit is saved in the
+// manifest and applies to writes already waiting.
+func (e *EngineFacade) SetIORateLimit(bytesPerSec int64) error {
+	if e.closed.Load() {
+		return ErrEngineClosed
+	}
+	if bytesPerSec < 0 {
+		return fmt.Errorf("%w: Rate limit cannot be negative", config.ErrInvalidConfig)
+	}
+
+	e.rateLimiter.SetRate(bytesPerSec)
+	e.cfg.Update(func(cfg *config.Config) {
+		cfg.RateLimitBytesPerSec = bytesPerSec
+	})
+	return e.cfg.SaveManifest(e.dataDir)
+}
+
 // GetStats returns the current statistics for the engine
 func (e *EngineFacade) GetStats() map[string]interface{} {
 	// Combine stats from all components
@@ -591,21 +825,25 @@ func (e *EngineFacade) Close() error {
 	// Track operation latency
 	start := time.Now()
 
-	var err error
-
 	// Close components in reverse order of dependency
 
-	// 1. First close compaction manager (to stop background tasks)
+	// 1. Column families write to the WAL of the default family, so they
+	// are closed before it
+	err := e.closeColumnFamilies()
+
+	// 2. Then close compaction manager (to stop background tasks)
 	if e.compaction != nil {
 		e.stats.TrackOperation(stats.OpCompact)
 
 		if compErr := e.compaction.Stop(); compErr != nil {
-			err = compErr
+			if err == nil {
+				err = compErr
+			}
 			e.stats.TrackError("close_compaction_error")
 		}
 	}
 
-	// 2. Close storage (which will close sstables and WAL)
+	// 3. Close storage (which will close sstables and WAL)
 	if e.storage != nil {
 		if storageErr := e.storage.Close(); storageErr != nil {
 			if err == nil {
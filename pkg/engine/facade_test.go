@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/KevoDB/kevo/pkg/common/iterator"
+	"github.com/KevoDB/kevo/pkg/config"
 )
 
 func TestEngineFacade_BasicOperations(t *testing.T) {
@@ -138,6 +142,122 @@ func TestEngineFacade_Iterator(t *testing.T) {
 	}
 }
 
+func TestEngineFacade_ReverseIteration(t *testing.T) {
+	dir, err := os.MkdirTemp("", "engine-facade-reverse-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	eng, err := NewEngineFacade(dir)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	for i := 0; i < 20; i++ {
+		key := []byte(fmt.Sprintf("key%02d", i))
+		if err := eng.Put(key, []byte("v1")); err != nil {
+			t.Fatalf("Failed to put key-value: %v", err)
+		}
+	}
+
+	// Keep the first versions on disk so iterators see several versions per key
+	if err := eng.FlushImMemTables(); err != nil {
+		t.Fatalf("Failed to flush memtables: %v", err)
+	}
+
+	snap, err := eng.GetSnapshot()
+	if err != nil {
+		t.Fatalf("Failed to get snapshot: %v", err)
+	}
+	defer eng.ReleaseSnapshot(snap)
+
+	for i := 0; i < 20; i += 2 {
+		key := []byte(fmt.Sprintf("key%02d", i))
+		if err := eng.Put(key, []byte("v2")); err != nil {
+			t.Fatalf("Failed to put key-value: %v", err)
+		}
+	}
+	if err := eng.Delete([]byte("key11")); err != nil {
+		t.Fatalf("Failed to delete key: %v", err)
+	}
+	if err := eng.DeleteRange([]byte("key05"), []byte("key08")); err != nil {
+		t.Fatalf("Failed to delete range: %v", err)
+	}
+
+	// collect returns the live entries of iter in the order it visits them
+	collect := func(iter iterator.Iterator, reverse bool) []string {
+		var entries []string
+		if reverse {
+			iter.SeekToLast()
+		} else {
+			iter.SeekToFirst()
+		}
+		for iter.Valid() {
+			if !iter.IsTombstone() {
+				entries = append(entries, fmt.Sprintf("%s=%s", iter.Key(), iter.Value()))
+			}
+			if reverse {
+				iter.Prev()
+			} else {
+				iter.Next()
+			}
+		}
+		return entries
+	}
+
+	verify := func(name string, iter iterator.Iterator, expectedCount int) {
+		forward := collect(iter, false)
+		backward := collect(iter, true)
+		if len(forward) != expectedCount {
+			t.Errorf("%s: expected %d entries, got %d: %v", name, expectedCount, len(forward), forward)
+		}
+		if len(backward) != len(forward) {
+			t.Fatalf("%s: reverse iteration returned %d entries, forward returned %d: %v",
+				name, len(backward), len(forward), backward)
+		}
+		for i := range forward {
+			if backward[len(backward)-1-i] != forward[i] {
+				t.Errorf("%s: reverse entry %d is %s, expected %s",
+					name, len(backward)-1-i, backward[len(backward)-1-i], forward[i])
+			}
+		}
+	}
+
+	iter, err := eng.GetIterator()
+	if err != nil {
+		t.Fatalf("Failed to get iterator: %v", err)
+	}
+	verify("iterator", iter, 16)
+
+	// Prev visits the same keys as Next, including deleted ones
+	if !iter.Seek([]byte("key12")) || !iter.Prev() || string(iter.Key()) != "key11" || !iter.IsTombstone() {
+		t.Fatalf("Expected Prev from key12 to land on the deleted key11, got %s", iter.Key())
+	}
+	if !iter.Prev() || string(iter.Key()) != "key10" || string(iter.Value()) != "v2" {
+		t.Errorf("Expected Prev to land on key10=v2, got %s=%s", iter.Key(), iter.Value())
+	}
+	if !iter.Next() || string(iter.Key()) != "key11" {
+		t.Errorf("Expected Next to return to key11, got %s", iter.Key())
+	}
+
+	rangeIter, err := eng.GetRangeIterator([]byte("key03"), []byte("key15"))
+	if err != nil {
+		t.Fatalf("Failed to get range iterator: %v", err)
+	}
+	verify("range iterator", rangeIter, 8)
+
+	snapIter, err := snap.GetIterator()
+	if err != nil {
+		t.Fatalf("Failed to get snapshot iterator: %v", err)
+	}
+	verify("snapshot iterator", snapIter, 20)
+	if snapIter.SeekToLast(); string(snapIter.Value()) != "v1" {
+		t.Errorf("Expected the snapshot to see key19=v1, got %s=%s", snapIter.Key(), snapIter.Value())
+	}
+}
+
 func TestEngineFacade_Transactions(t *testing.T) {
 	// Create a temp directory for the test
 	dir, err := os.MkdirTemp("", "engine-facade-transaction-test-*")
@@ -280,3 +400,123 @@ func TestEngineFacade_Compaction(t *testing.T) {
 		t.Fatalf("Failed to close engine: %v", err)
 	}
 }
+
+func TestEngineFacade_FIFOCompaction(t *testing.T) {
+	dir := t.TempDir()
+
+	// Ingested files are only ever read from the SSTables, never from the
+	// MemTables or the WAL
+	var paths []string
+	var totalSize int64
+	for i := 0; i < 4; i++ {
+		path := writeIngestFile(t, filepath.Join(t.TempDir(), "batch.sst"), fmt.Sprintf("batch-%d", i), 0, 100, "value")
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Failed to stat SSTable: %v", err)
+		}
+		paths = append(paths, path)
+		totalSize += info.Size()
+	}
+
+	// Half of the files are past the size limit
+	cfg := config.NewDefaultConfig(dir)
+	cfg.CompactionStyle = config.CompactionStyleFIFO
+	cfg.CompactionMaxSize = totalSize / 2
+	if err := cfg.SaveManifest(dir); err != nil {
+		t.Fatalf("Failed to save configuration: %v", err)
+	}
+
+	eng, err := NewEngineFacade(dir)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	for _, path := range paths {
+		if _, err := eng.IngestFiles([]string{path}); err != nil {
+			t.Fatalf("Failed to ingest: %v", err)
+		}
+	}
+	if err := eng.TriggerCompaction(); err != nil {
+		t.Fatalf("Failed to trigger compaction: %v", err)
+	}
+
+	// Reads stop seeing the dropped files straight away
+	if _, err := eng.Get([]byte("batch-0-000")); err == nil {
+		t.Error("Expected the oldest file to be dropped")
+	}
+	if _, err := eng.Get([]byte("batch-3-000")); err != nil {
+		t.Errorf("Expected the newest file to be kept, got %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(cfg.SSTDir, "*.sst"))
+	if err != nil {
+		t.Fatalf("Failed to list SSTables: %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("Expected 2 SSTables left, got %v", files)
+	}
+	if count := eng.GetStats()["storage_sstable_count"]; count != len(files) {
+		t.Errorf("Expected %d SSTables to be read, got %v", len(files), count)
+	}
+}
+
+func TestEngineFacade_IORateLimit(t *testing.T) {
+	// Create a temp directory for the test
+	dir, err := os.MkdirTemp("", "engine-facade-ratelimit-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	eng, err := NewEngineFacade(dir)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	if stats := eng.GetStats(); stats["storage_rate_limit_bytes_per_sec"] != int64(0) {
+		t.Errorf("Expected no rate limit by default, got %v", stats["storage_rate_limit_bytes_per_sec"])
+	}
+
+	if err := eng.SetIORateLimit(-1); err == nil {
+		t.Error("Expected a negative rate limit to be rejected")
+	}
+
+	const rate = 64 << 20
+	if err := eng.SetIORateLimit(rate); err != nil {
+		t.Fatalf("Failed to set rate limit: %v", err)
+	}
+	stats := eng.GetStats()
+	if stats["storage_rate_limit_bytes_per_sec"] != int64(rate) ||
+		stats["storage_rate_limit_effective_bytes_per_sec"] != int64(rate) {
+		t.Errorf("Expected a rate limit of %d, got %v and %v", rate,
+			stats["storage_rate_limit_bytes_per_sec"], stats["storage_rate_limit_effective_bytes_per_sec"])
+	}
+
+	// Flushes and compactions work within the limit
+	for i := 0; i < 100; i++ {
+		if err := eng.Put([]byte(fmt.Sprintf("key-%03d", i)), []byte("value")); err != nil {
+			t.Fatalf("Failed to put key-value: %v", err)
+		}
+	}
+	if err := eng.FlushImMemTables(); err != nil {
+		t.Fatalf("Failed to flush memtables: %v", err)
+	}
+	if err := eng.Close(); err != nil {
+		t.Fatalf("Failed to close engine: %v", err)
+	}
+
+	// The limit is kept across restarts
+	eng, err = NewEngineFacade(dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen engine: %v", err)
+	}
+	defer eng.Close()
+
+	if stats := eng.GetStats(); stats["storage_rate_limit_bytes_per_sec"] != int64(rate) {
+		t.Errorf("Expected the rate limit to be kept, got %v", stats["storage_rate_limit_bytes_per_sec"])
+	}
+	if value, err := eng.Get([]byte("key-042")); err != nil || !bytes.Equal(value, []byte("value")) {
+		t.Errorf("Expected the flushed value, got %q (%v)", value, err)
+	}
+}
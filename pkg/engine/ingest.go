@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/KevoDB/kevo/pkg/engine/storage"
+	"github.com/KevoDB/kevo/pkg/stats"
+)
+
+// IngestFiles adds SSTable files written outside the database, usually with
+// sstable.SSTFileWriter, and returns the sequence number given to all of their
+// entries. The files are checked, hard linked or copied into the database and
+// become visible all at once, each at the deepest level it fits in without
+// overlapping newer data. The original files are left as they are.
+//
+// The files must not overlap each other, nor keys written since the database
+// was opened, which are still in the MemTables.
+func (e *EngineFacade) IngestFiles(paths []string) (uint64, error) {
+	return e.IngestFilesCF(DefaultColumnFamily, paths)
+}
+
+// IngestFilesCF adds SSTable files written outside the database to a column
+// family, like IngestFiles
+func (e *EngineFacade) IngestFilesCF(family string, paths []string) (uint64, error) {
+	if e.closed.Load() {
+		return 0, ErrEngineClosed
+	}
+
+	// Reject writes in read-only mode
+	if e.readOnly.Load() {
+		return 0, ErrReadOnlyMode
+	}
+
+	cf, err := e.columnFamily(family)
+	if err != nil {
+		return 0, err
+	}
+	manager, ok := cf.storage.(*storage.Manager)
+	if !ok {
+		return 0, fmt.Errorf("storage does not support ingestion")
+	}
+
+	e.stats.TrackOperation(stats.OpIngest)
+	start := time.Now()
+	seqNum, err := e.ingestFiles(manager, cf, paths)
+	e.stats.TrackOperationWithLatency(stats.OpIngest, uint64(time.Since(start).Nanoseconds()))
+	if err != nil {
+		e.stats.TrackError("ingest_error")
+		return 0, err
+	}
+	return seqNum, nil
+}
+
+// ingestFiles checks and stages the files, then commits them while the
+// compaction of the column family is held off
+func (e *EngineFacade) ingestFiles(manager *storage.Manager, cf *columnFamily, paths []string) (uint64, error) {
+	ing, err := manager.PrepareIngest(paths)
+	if err != nil {
+		return 0, err
+	}
+
+	var seqNum uint64
+	commit := func() error {
+		seqNum, err = ing.Commit()
+		return err
+	}
+	if exclusive, ok := cf.compaction.(interface {
+		RunExclusive(fn func() error) error
+	}); ok {
+		err = exclusive.RunExclusive(commit)
+	} else {
+		err = commit()
+	}
+	if err != nil {
+		ing.Abort()
+		return 0, fmt.Errorf("failed to ingest files: %w", err)
+	}
+	return seqNum, nil
+}
@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/KevoDB/kevo/pkg/sstable"
+)
+
+// writeIngestFile writes keys prefix-from up to prefix-to with the given
+// value to a new SSTable file
+func writeIngestFile(t *testing.T, path, prefix string, from, to int, value string) string {
+	t.Helper()
+	writer, err := sstable.NewSSTFileWriter(path)
+	if err != nil {
+		t.Fatalf("Failed to create file writer: %v", err)
+	}
+	for i := from; i < to; i++ {
+		if err := writer.Put([]byte(fmt.Sprintf("%s-%03d", prefix, i)), []byte(value)); err != nil {
+			t.Fatalf("Failed to put: %v", err)
+		}
+	}
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish file: %v", err)
+	}
+	return path
+}
+
+func TestEngineFacade_IngestFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := t.TempDir()
+
+	eng, err := NewEngineFacade(filepath.Join(dir, "db"))
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		if err := eng.Put([]byte(fmt.Sprintf("a-%03d", i)), []byte("written")); err != nil {
+			t.Fatalf("Failed to put: %v", err)
+		}
+	}
+	snap, err := eng.GetSnapshot()
+	if err != nil {
+		t.Fatalf("Failed to get snapshot: %v", err)
+	}
+
+	// Nothing else in the key range, so the file goes to the bottom level
+	seqNum, err := eng.IngestFiles([]string{writeIngestFile(t, filepath.Join(files, "1.sst"), "b", 0, 100, "v1")})
+	if err != nil {
+		t.Fatalf("Failed to ingest: %v", err)
+	}
+	if seqNum <= snap.SequenceNumber() {
+		t.Errorf("Expected sequence number after %d, got %d", snap.SequenceNumber(), seqNum)
+	}
+	bottom, _ := filepath.Glob(filepath.Join(dir, "db", "sst", "6_*.sst"))
+	if len(bottom) != 1 {
+		t.Errorf("Expected the file in level 6, got %v", bottom)
+	}
+	if value, err := eng.Get([]byte("b-050")); err != nil || string(value) != "v1" {
+		t.Errorf("Expected v1, got %q (%v)", value, err)
+	}
+	if _, err := snap.Get([]byte("b-050")); err == nil {
+		t.Error("Expected the snapshot not to see ingested keys")
+	}
+	eng.ReleaseSnapshot(snap)
+
+	// Newer files overlapping it go to the level above
+	seqNum, err = eng.IngestFiles([]string{writeIngestFile(t, filepath.Join(files, "2.sst"), "b", 50, 60, "v2")})
+	if err != nil {
+		t.Fatalf("Failed to ingest: %v", err)
+	}
+	above, _ := filepath.Glob(filepath.Join(dir, "db", "sst", "5_*.sst"))
+	if len(above) != 1 {
+		t.Errorf("Expected the file in level 5, got %v", above)
+	}
+	for key, expected := range map[string]string{"b-049": "v1", "b-050": "v2", "b-060": "v1"} {
+		if value, err := eng.Get([]byte(key)); err != nil || string(value) != expected {
+			t.Errorf("Expected %s = %s, got %q (%v)", key, expected, value, err)
+		}
+	}
+
+	// Files overlapping each other or the MemTables are rejected whole
+	overlapping := []string{
+		writeIngestFile(t, filepath.Join(files, "3.sst"), "c", 0, 10, "v3"),
+		writeIngestFile(t, filepath.Join(files, "4.sst"), "c", 5, 15, "v3"),
+	}
+	if _, err := eng.IngestFiles(overlapping); !errors.Is(err, ErrIngestOverlap) {
+		t.Errorf("Expected ErrIngestOverlap, got %v", err)
+	}
+	inMemory := []string{
+		writeIngestFile(t, filepath.Join(files, "5.sst"), "c", 20, 30, "v3"),
+		writeIngestFile(t, filepath.Join(files, "6.sst"), "a", 5, 6, "v3"),
+	}
+	if _, err := eng.IngestFiles(inMemory); !errors.Is(err, ErrIngestMemTableOverlap) {
+		t.Errorf("Expected ErrIngestMemTableOverlap, got %v", err)
+	}
+	if _, err := eng.Get([]byte("c-025")); err == nil {
+		t.Error("Expected nothing from a failed ingestion")
+	}
+	staged, _ := filepath.Glob(filepath.Join(dir, "db", "sst", ".ingest-*"))
+	if len(staged) != 0 {
+		t.Errorf("Expected staged files to be removed, got %v", staged)
+	}
+
+	if err := eng.Close(); err != nil {
+		t.Fatalf("Failed to close engine: %v", err)
+	}
+
+	// Ingested files and their sequence numbers survive reopening
+	eng, err = NewEngineFacade(filepath.Join(dir, "db"))
+	if err != nil {
+		t.Fatalf("Failed to reopen engine: %v", err)
+	}
+	defer eng.Close()
+
+	if value, err := eng.Get([]byte("b-055")); err != nil || string(value) != "v2" {
+		t.Errorf("Expected v2 after reopening, got %q (%v)", value, err)
+	}
+	if err := eng.Put([]byte("b-055"), []byte("written")); err != nil {
+		t.Fatalf("Failed to put: %v", err)
+	}
+	snap, err = eng.GetSnapshot()
+	if err != nil {
+		t.Fatalf("Failed to get snapshot: %v", err)
+	}
+	defer eng.ReleaseSnapshot(snap)
+	if snap.SequenceNumber() <= seqNum {
+		t.Errorf("Expected writes after reopening to be newer than sequence %d, got %d", seqNum, snap.SequenceNumber())
+	}
+	if value, err := snap.Get([]byte("b-055")); err != nil || string(value) != "written" {
+		t.Errorf("Expected the write after reopening, got %q (%v)", value, err)
+	}
+}
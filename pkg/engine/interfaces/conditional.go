@@ -0,0 +1,59 @@
+package interfaces
+
+// PreconditionType selects what a precondition checks about the current value
+// of a key
+type PreconditionType uint8
+
+const (
+	// PreconditionEquals holds when the key exists with exactly the given value
+	PreconditionEquals PreconditionType = iota
+	// PreconditionAbsent holds when the key doesn't exist
+	PreconditionAbsent
+	// PreconditionExists holds when the key exists with any value
+	PreconditionExists
+	// PreconditionUnchanged holds when the key wasn't written after Sequence
+	PreconditionUnchanged
+	// PreconditionRangeUnchanged holds when no key in [Key, End) was written
+	// after Sequence. Nil bounds are unbounded.
+	PreconditionRangeUnchanged
+)
+
+// Precondition is a check on the current value of a key, or on when keys were
+// last written, that must hold for a conditional write to be applied. Expired
+// and deleted keys don't exist, but deleting or expiring a key writes it.
+type Precondition struct {
+	Key      []byte
+	Family   uint32 // Column family of the key, as in wal.Entry
+	Type     PreconditionType
+	Value    []byte // Expected value for PreconditionEquals
+	End      []byte // End of the key range for PreconditionRangeUnchanged
+	Sequence uint64 // Last sequence number allowed by the Unchanged types
+}
+
+// ConditionResult reports the outcome of a conditional write
+type ConditionResult struct {
+	// Applied is true when every precondition held and the write was applied
+	Applied bool
+
+	// Failed is the index of the first precondition that didn't hold, or -1
+	Failed int
+
+	// Value and Found describe the key of a single-key operation after the
+	// write when it was applied, or the value that failed the precondition
+	Value []byte
+	Found bool
+}
+
+// Holds reports whether a precondition on the value of a key is satisfied by
+// the current state of the key. The Unchanged types are checked against the
+// sequence numbers of writes instead, by the storage.
+func (p Precondition) Holds(value []byte, found bool) bool {
+	switch p.Type {
+	case PreconditionAbsent:
+		return !found
+	case PreconditionExists:
+		return found
+	default:
+		return found && string(value) == string(p.Value)
+	}
+}
@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"errors"
+	"time"
 
 	"github.com/KevoDB/kevo/pkg/common/iterator"
 	"github.com/KevoDB/kevo/pkg/stats"
@@ -13,8 +14,11 @@ import (
 type Engine interface {
 	// Core operations
 	Put(key, value []byte) error
+	PutWithTTL(key, value []byte, ttl time.Duration) error
 	Get(key []byte) ([]byte, error)
 	Delete(key []byte) error
+	Merge(key, operand []byte) error
+	DeleteRange(startKey, endKey []byte) error
 	IsDeleted(key []byte) (bool, error)
 
 	// Iterator access
@@ -24,6 +28,12 @@ type Engine interface {
 	// Batch operations
 	ApplyBatch(entries []*wal.Entry) error
 
+	// Conditional writes
+	CompareAndSwap(key, expected, value []byte) (ConditionResult, error)
+	PutIfAbsent(key, value []byte) (ConditionResult, error)
+	DeleteIfEquals(key, expected []byte) (ConditionResult, error)
+	ApplyBatchIf(entries []*wal.Entry, conditions []Precondition) (ConditionResult, error)
+
 	// Transaction management
 	BeginTransaction(readOnly bool) (Transaction, error)
 
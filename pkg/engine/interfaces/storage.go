@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"github.com/KevoDB/kevo/pkg/common/iterator"
+	"github.com/KevoDB/kevo/pkg/sstable"
 	"github.com/KevoDB/kevo/pkg/wal"
 )
 
@@ -10,16 +11,21 @@ import (
 type Storage interface {
 	// Core operations
 	Put(key, value []byte) error
+	PutWithExpiry(key, value []byte, expireAt uint64) error
 	Get(key []byte) ([]byte, error)
 	Delete(key []byte) error
+	Merge(key, operand []byte) error
+	DeleteRange(startKey, endKey []byte) error
 	IsDeleted(key []byte) (bool, error)
 
 	// Iterator access
 	GetIterator() (iterator.Iterator, error)
 	GetRangeIterator(startKey, endKey []byte) (iterator.Iterator, error)
+	GetRangeIteratorWithOptions(startKey, endKey []byte, options sstable.ReadOptions) (iterator.Iterator, error)
 
 	// Batch operations
 	ApplyBatch(entries []*wal.Entry) error
+	ApplyBatchIf(entries []*wal.Entry, conditions []Precondition) (ConditionResult, error)
 
 	// Flushing operations
 	FlushMemTables() error
@@ -43,6 +49,14 @@ type StorageManager interface {
 	// WAL management
 	RotateWAL() error
 
+	// Snapshot management
+	AcquireSnapshot() (uint64, error)
+	ReleaseSnapshot(seqNum uint64)
+	LiveSnapshots() []uint64
+	GetWithSnapshot(key []byte, seqNum uint64) ([]byte, error)
+	GetIteratorWithSnapshot(seqNum uint64) (iterator.Iterator, error)
+	GetRangeIteratorWithSnapshot(startKey, endKey []byte, seqNum uint64) (iterator.Iterator, error)
+
 	// Statistics
 	GetStorageStats() map[string]interface{}
 }
@@ -148,6 +148,12 @@ func (m *MergedIterator) Seek(target []byte) bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	return m.seek(target)
+}
+
+// seek positions the iterator at the first key >= target. The caller must hold
+// the lock.
+func (m *MergedIterator) seek(target []byte) bool {
 	// Initialize iterators if needed
 	if len(m.iters) != len(m.sources) {
 		m.initIterators()
@@ -272,6 +278,33 @@ func (m *MergedIterator) Next() bool {
 	return m.current != nil
 }
 
+// Prev moves the iterator back to the previous key
+func (m *MergedIterator) Prev() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.current == nil {
+		return false
+	}
+
+	// Find the largest key before the current one across all sources
+	var prevKey []byte
+	for _, iter := range m.iters {
+		if iterator.SeekBefore(iter, m.current.key) &&
+			(prevKey == nil || bytes.Compare(iter.Key(), prevKey) > 0) {
+			prevKey = append(prevKey[:0], iter.Key()...)
+		}
+	}
+
+	if prevKey == nil {
+		m.current = nil
+		return false
+	}
+
+	// Seek to it so that the newest source wins
+	return m.seek(prevKey)
+}
+
 // Key returns the current key
 func (m *MergedIterator) Key() []byte {
 	m.mu.Lock()
@@ -505,6 +538,32 @@ func (c *chainedIterator) Next() bool {
 	return c.current != -1
 }
 
+func (c *chainedIterator) Prev() bool {
+	if !c.Valid() {
+		return false
+	}
+
+	// Get the current key
+	currentKey := append([]byte(nil), c.iterators[c.current].Key()...)
+
+	// Find the largest key before the current key
+	var prevKey []byte
+	for _, iter := range c.iterators {
+		if iterator.SeekBefore(iter, currentKey) &&
+			(prevKey == nil || bytes.Compare(iter.Key(), prevKey) > 0) {
+			prevKey = append(prevKey[:0], iter.Key()...)
+		}
+	}
+
+	if prevKey == nil {
+		c.current = -1
+		return false
+	}
+
+	// Seek to it so that the newest source wins
+	return c.Seek(prevKey)
+}
+
 func (c *chainedIterator) Key() []byte {
 	if !c.Valid() {
 		return nil
@@ -537,6 +596,7 @@ func (e *emptyIterator) SeekToFirst()            {}
 func (e *emptyIterator) SeekToLast()             {}
 func (e *emptyIterator) Seek(target []byte) bool { return false }
 func (e *emptyIterator) Next() bool              { return false }
+func (e *emptyIterator) Prev() bool              { return false }
 func (e *emptyIterator) Key() []byte             { return nil }
 func (e *emptyIterator) Value() []byte           { return nil }
 func (e *emptyIterator) Valid() bool             { return false }
@@ -588,32 +648,8 @@ func (b *boundedIterator) SeekToFirst() {
 
 func (b *boundedIterator) SeekToLast() {
 	if b.end != nil {
-		// If we have an end bound, seek to it
-		// The current implementation might not be efficient for finding the last
-		// key before the end bound, but it works for now
-		b.Iterator.Seek(b.end)
-
-		// If we landed exactly at the end bound, back up one
-		if b.Iterator.Valid() && bytes.Equal(b.Iterator.Key(), b.end) {
-			// We need to back up because end is exclusive
-			// This is inefficient but correct
-			b.Iterator.SeekToFirst()
-
-			// Scan to find the last key before the end bound
-			var lastKey []byte
-			for b.Iterator.Valid() && bytes.Compare(b.Iterator.Key(), b.end) < 0 {
-				lastKey = b.Iterator.Key()
-				b.Iterator.Next()
-			}
-
-			if lastKey != nil {
-				b.Iterator.Seek(lastKey)
-			} else {
-				// No keys before the end bound
-				b.Iterator.SeekToFirst()
-				// This will be marked invalid by checkBounds
-			}
-		}
+		// The end bound is exclusive, so position at the last key before it
+		iterator.SeekBefore(b.Iterator, b.end)
 	} else {
 		// No end bound, seek to the last key
 		b.Iterator.SeekToLast()
@@ -655,6 +691,21 @@ func (b *boundedIterator) Next() bool {
 	return b.checkBounds()
 }
 
+func (b *boundedIterator) Prev() bool {
+	// First check if we're already before the start boundary
+	if !b.checkBounds() {
+		return false
+	}
+
+	// Then try to move back
+	if !b.Iterator.Prev() {
+		return false
+	}
+
+	// Check if the new position is within bounds
+	return b.checkBounds()
+}
+
 func (b *boundedIterator) Valid() bool {
 	return b.Iterator.Valid() && b.checkBounds()
 }
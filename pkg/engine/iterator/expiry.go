@@ -0,0 +1,40 @@
+package iterator
+
+import (
+	"time"
+
+	"github.com/KevoDB/kevo/pkg/common/ttl"
+)
+
+// expiryIterator reports values that had expired at a fixed point in time as
+// deletion markers, so they shadow older versions of their key like a delete
+type expiryIterator struct {
+	sequencedIterator
+	now time.Time
+}
+
+// newExpiryIterator wraps a source so values expired at now read as deleted
+func newExpiryIterator(iter sequencedIterator, now time.Time) *expiryIterator {
+	return &expiryIterator{
+		sequencedIterator: iter,
+		now:               now,
+	}
+}
+
+// Value returns the current value, or nil if it has expired
+func (e *expiryIterator) Value() []byte {
+	if e.expired() {
+		return nil
+	}
+	return e.sequencedIterator.Value()
+}
+
+// IsTombstone returns true if the current entry is a deletion marker or an expired value
+func (e *expiryIterator) IsTombstone() bool {
+	return e.sequencedIterator.IsTombstone() || e.expired()
+}
+
+// expired reports whether the current entry is a value that has expired
+func (e *expiryIterator) expired() bool {
+	return e.Valid() && ttl.Expired(e.ExpireAt(), e.now)
+}
@@ -1,19 +1,40 @@
 package iterator
 
 import (
+	"time"
+
 	"github.com/KevoDB/kevo/pkg/common/iterator"
 	"github.com/KevoDB/kevo/pkg/common/iterator/bounded"
 	"github.com/KevoDB/kevo/pkg/common/iterator/composite"
+	"github.com/KevoDB/kevo/pkg/common/merge"
+	"github.com/KevoDB/kevo/pkg/common/rangedel"
 	"github.com/KevoDB/kevo/pkg/memtable"
 	"github.com/KevoDB/kevo/pkg/sstable"
 )
 
 // Factory provides methods to create iterators for the storage engine
-type Factory struct{}
+type Factory struct {
+	// Merge operator used to resolve merge operands, if any
+	mergeOperator merge.Operator
+
+	// Options for reading SSTable blocks
+	readOptions sstable.ReadOptions
+}
 
 // NewFactory creates a new iterator factory
 func NewFactory() *Factory {
-	return &Factory{}
+	return &Factory{readOptions: sstable.DefaultReadOptions()}
+}
+
+// NewFactoryWithMergeOperator creates an iterator factory whose iterators
+// resolve merge operands with the given operator
+func NewFactoryWithMergeOperator(op merge.Operator) *Factory {
+	return &Factory{mergeOperator: op, readOptions: sstable.DefaultReadOptions()}
+}
+
+// SetReadOptions sets how the iterators created afterwards read SSTable blocks
+func (f *Factory) SetReadOptions(options sstable.ReadOptions) {
+	f.readOptions = options
 }
 
 // CreateIterator creates a hierarchical iterator that combines
@@ -35,6 +56,58 @@ func (f *Factory) CreateRangeIterator(
 	return bounded.NewBoundedIterator(baseIter, startKey, endKey)
 }
 
+// CreateSnapshotIterator creates an iterator that only sees entries
+// with a sequence number <= snapshotSeq
+func (f *Factory) CreateSnapshotIterator(
+	memTables []*memtable.MemTable,
+	ssTables []*sstable.Reader,
+	snapshotSeq uint64,
+) iterator.Iterator {
+	return f.createSnapshotBaseIterator(memTables, ssTables, snapshotSeq)
+}
+
+// CreateSnapshotRangeIterator creates a snapshot iterator limited to a specific key range
+func (f *Factory) CreateSnapshotRangeIterator(
+	memTables []*memtable.MemTable,
+	ssTables []*sstable.Reader,
+	startKey, endKey []byte,
+	snapshotSeq uint64,
+) iterator.Iterator {
+	baseIter := f.createSnapshotBaseIterator(memTables, ssTables, snapshotSeq)
+	return bounded.NewBoundedIterator(baseIter, startKey, endKey)
+}
+
+// createSnapshotBaseIterator creates a hierarchical iterator whose sources
+// hide entries written after the snapshot
+func (f *Factory) createSnapshotBaseIterator(
+	memTables []*memtable.MemTable,
+	ssTables []*sstable.Reader,
+	snapshotSeq uint64,
+) iterator.Iterator {
+	if len(memTables) == 0 && len(ssTables) == 0 {
+		return newEmptyIterator()
+	}
+
+	sources := make([]sequencedIterator, 0, len(memTables)+len(ssTables))
+	var tombstones []rangedel.Tombstone
+
+	// Add memtable iterators (newest to oldest)
+	for _, mt := range memTables {
+		adapter := memtable.NewIteratorAdapter(mt.NewIteratorWithSnapshot(snapshotSeq))
+		sources = append(sources, newSnapshotIterator(adapter, snapshotSeq))
+		tombstones = append(tombstones, rangedel.Visible(mt.RangeTombstones(), snapshotSeq)...)
+	}
+
+	// Add sstable iterators (newest to oldest)
+	for i := len(ssTables) - 1; i >= 0; i-- {
+		adapter := sstable.NewIteratorAdapter(ssTables[i].NewIteratorWithOptions(f.readOptions))
+		sources = append(sources, newSnapshotIterator(adapter, snapshotSeq))
+		tombstones = append(tombstones, rangedel.Visible(ssTables[i].RangeTombstones(), snapshotSeq)...)
+	}
+
+	return f.combine(sources, tombstones)
+}
+
 // createBaseIterator creates the base hierarchical iterator
 func (f *Factory) createBaseIterator(
 	memTables []*memtable.MemTable,
@@ -46,16 +119,37 @@ func (f *Factory) createBaseIterator(
 	}
 
 	// Create individual iterators in newest-to-oldest order
-	iterators := make([]iterator.Iterator, 0, len(memTables)+len(ssTables))
+	sources := make([]sequencedIterator, 0, len(memTables)+len(ssTables))
+	var tombstones []rangedel.Tombstone
 
 	// Add memtable iterators (newest to oldest)
 	for _, mt := range memTables {
-		iterators = append(iterators, memtable.NewIteratorAdapter(mt.NewIterator()))
+		sources = append(sources, memtable.NewIteratorAdapter(mt.NewIterator()))
+		tombstones = append(tombstones, mt.RangeTombstones()...)
 	}
 
 	// Add sstable iterators (newest to oldest)
 	for i := len(ssTables) - 1; i >= 0; i-- {
-		iterators = append(iterators, sstable.NewIteratorAdapter(ssTables[i].NewIterator()))
+		sources = append(sources, sstable.NewIteratorAdapter(ssTables[i].NewIteratorWithOptions(f.readOptions)))
+		tombstones = append(tombstones, ssTables[i].RangeTombstones()...)
+	}
+
+	return f.combine(sources, tombstones)
+}
+
+// combine merges the sources, given newest to oldest, into a single iterator
+// that hides the versions deleted by the range tombstones
+func (f *Factory) combine(sources []sequencedIterator, tombstones []rangedel.Tombstone) iterator.Iterator {
+	// Merge operands and range deletions need every version of a key, not just the newest one
+	if f.mergeOperator != nil || len(tombstones) > 0 {
+		return newMergeIterator(sources, f.mergeOperator, tombstones)
+	}
+
+	// Only the newest version of each key is needed, expired ones read as deletions
+	now := time.Now()
+	iterators := make([]iterator.Iterator, len(sources))
+	for i, src := range sources {
+		iterators[i] = newExpiryIterator(src, now)
 	}
 
 	// Create hierarchical iterator
@@ -74,6 +168,7 @@ func (e *emptyIterator) SeekToFirst()            {}
 func (e *emptyIterator) SeekToLast()             {}
 func (e *emptyIterator) Seek(target []byte) bool { return false }
 func (e *emptyIterator) Next() bool              { return false }
+func (e *emptyIterator) Prev() bool              { return false }
 func (e *emptyIterator) Key() []byte             { return nil }
 func (e *emptyIterator) Value() []byte           { return nil }
 func (e *emptyIterator) Valid() bool             { return false }
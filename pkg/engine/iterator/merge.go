@@ -0,0 +1,201 @@
+package iterator
+
+import (
+	"bytes"
+	"math"
+	"time"
+
+	"github.com/KevoDB/kevo/pkg/common/iterator"
+	"github.com/KevoDB/kevo/pkg/common/merge"
+	"github.com/KevoDB/kevo/pkg/common/rangedel"
+)
+
+// mergeIterator combines the versions of each key across all sources and
+// applies merge operands to the value they were written on top of. Keys whose
+// operands can't be merged are skipped. Keys covered by a range tombstone newer
+// than their versions, or whose value had expired when the iterator was
+// created, are reported as deleted.
+type mergeIterator struct {
+	sources    []sequencedIterator
+	op         merge.Operator
+	tombstones []rangedel.Tombstone
+	now        time.Time
+	versions   []merge.Version
+	key        []byte
+	value      []byte
+	tombstone  bool
+	valid      bool
+}
+
+// newMergeIterator creates an iterator that resolves merge operands with op
+// and hides the versions deleted by the given range tombstones
+func newMergeIterator(sources []sequencedIterator, op merge.Operator, tombstones []rangedel.Tombstone) *mergeIterator {
+	return &mergeIterator{
+		sources:    sources,
+		op:         op,
+		tombstones: tombstones,
+		now:        time.Now(),
+	}
+}
+
+// SeekToFirst positions at the first key
+func (m *mergeIterator) SeekToFirst() {
+	for _, src := range m.sources {
+		src.SeekToFirst()
+	}
+	m.resolveNext()
+}
+
+// SeekToLast positions at the last key
+func (m *mergeIterator) SeekToLast() {
+	m.resolvePrev(nil)
+}
+
+// Seek positions at the first key >= target
+func (m *mergeIterator) Seek(target []byte) bool {
+	for _, src := range m.sources {
+		src.Seek(target)
+	}
+	return m.resolveNext()
+}
+
+// Next advances to the next key
+func (m *mergeIterator) Next() bool {
+	if !m.valid {
+		return false
+	}
+	return m.resolveNext()
+}
+
+// Prev moves back to the previous key
+func (m *mergeIterator) Prev() bool {
+	if !m.valid {
+		return false
+	}
+	return m.resolvePrev(append([]byte(nil), m.key...))
+}
+
+// Key returns the current key
+func (m *mergeIterator) Key() []byte {
+	if !m.valid {
+		return nil
+	}
+	return m.key
+}
+
+// Value returns the resolved value of the current key
+func (m *mergeIterator) Value() []byte {
+	if !m.valid {
+		return nil
+	}
+	return m.value
+}
+
+// Valid returns true if the iterator is positioned at a key
+func (m *mergeIterator) Valid() bool {
+	return m.valid
+}
+
+// IsTombstone returns true if the current key is deleted
+func (m *mergeIterator) IsTombstone() bool {
+	return m.valid && m.tombstone
+}
+
+// resolvePrev positions at the largest key before target, or at the last key if
+// target is nil. Resolving a key consumes all of its versions, so this finds
+// the key and then seeks forward to it.
+func (m *mergeIterator) resolvePrev(target []byte) bool {
+	for {
+		m.valid = false
+
+		var key []byte
+		for _, src := range m.sources {
+			if target == nil {
+				src.SeekToLast()
+			} else {
+				iterator.SeekBefore(src, target)
+			}
+			if src.Valid() && (key == nil || bytes.Compare(src.Key(), key) > 0) {
+				key = src.Key()
+			}
+		}
+		if key == nil {
+			return false
+		}
+		key = append([]byte(nil), key...)
+
+		// Keys whose operands can't be merged are skipped, in which case the
+		// seek lands past the key and we keep looking before it
+		if m.Seek(key) && bytes.Equal(m.key, key) {
+			return true
+		}
+		target = key
+	}
+}
+
+// resolveNext collects the versions of the smallest key the sources are
+// positioned at, advances the sources past it and resolves its value
+func (m *mergeIterator) resolveNext() bool {
+	for {
+		m.valid = false
+
+		var key []byte
+		for _, src := range m.sources {
+			if src.Valid() && (key == nil || bytes.Compare(src.Key(), key) < 0) {
+				key = src.Key()
+			}
+		}
+		if key == nil {
+			return false
+		}
+		key = append([]byte(nil), key...)
+
+		m.versions = m.versions[:0]
+		for _, src := range m.sources {
+			n := len(m.versions)
+			for src.Valid() && bytes.Equal(src.Key(), key) {
+				kind := merge.KindValue
+				if src.IsTombstone() {
+					kind = merge.KindDeletion
+				} else if src.IsMergeOperand() {
+					kind = merge.KindOperand
+				}
+				m.versions = append(m.versions, merge.Version{
+					SeqNum:   src.SequenceNumber(),
+					Kind:     kind,
+					Value:    src.Value(),
+					ExpireAt: src.ExpireAt(),
+				})
+				src.Next()
+			}
+			m.versions = merge.DropDuplicates(m.versions, n)
+		}
+
+		// Sources aren't necessarily ordered from newest to oldest
+		m.versions = merge.SortVersions(m.versions)
+
+		// Expired values read as deletions
+		m.versions = merge.Expire(m.versions, m.now)
+
+		// A range deletion acts as a deletion of the key at its sequence number
+		if covering := rangedel.MaxCoveringSeq(m.tombstones, key, math.MaxUint64); covering > 0 {
+			m.versions = merge.InsertDeletion(m.versions, covering)
+		}
+
+		value, found, err := merge.Resolve(m.op, key, m.versions)
+		if err != nil {
+			continue
+		}
+
+		if found && value == nil {
+			// A nil value would be reported as a tombstone
+			value = []byte{}
+		}
+
+		m.key = key
+		m.value = value
+		m.tombstone = !found
+		m.valid = true
+		return true
+	}
+}
@@ -0,0 +1,135 @@
+package iterator
+
+import (
+	"github.com/KevoDB/kevo/pkg/common/iterator"
+)
+
+// sequencedIterator is an iterator that exposes every version of a key along
+// with its sequence number
+type sequencedIterator interface {
+	iterator.Iterator
+	SequenceNumber() uint64
+	IsMergeOperand() bool
+	ExpireAt() uint64
+}
+
+// snapshotIterator hides entries written after a snapshot sequence number.
+// Sources yield versions of a key newest first, so after filtering the first
+// entry for a key is the version visible to the snapshot.
+type snapshotIterator struct {
+	iter        sequencedIterator
+	snapshotSeq uint64
+}
+
+// newSnapshotIterator wraps a source so it only yields entries with a
+// sequence number <= snapshotSeq
+func newSnapshotIterator(iter sequencedIterator, snapshotSeq uint64) *snapshotIterator {
+	return &snapshotIterator{
+		iter:        iter,
+		snapshotSeq: snapshotSeq,
+	}
+}
+
+// SeekToFirst positions at the first visible entry
+func (s *snapshotIterator) SeekToFirst() {
+	s.iter.SeekToFirst()
+	s.skipInvisible()
+}
+
+// SeekToLast positions at the newest visible version of the last visible key
+func (s *snapshotIterator) SeekToLast() {
+	// Versions are ordered newest first, so the last entry in the source is
+	// the oldest version of its key. Find the last visible entry and then seek
+	// to the newest visible version of its key.
+	s.iter.SeekToLast()
+	if s.skipInvisibleBackward() {
+		s.Seek(append([]byte(nil), s.iter.Key()...))
+	}
+}
+
+// Seek positions at the first visible entry with a key >= target
+func (s *snapshotIterator) Seek(target []byte) bool {
+	s.iter.Seek(target)
+	return s.skipInvisible()
+}
+
+// Next advances to the next visible entry
+func (s *snapshotIterator) Next() bool {
+	if !s.iter.Next() {
+		return false
+	}
+	return s.skipInvisible()
+}
+
+// Prev moves back to the previous visible entry
+func (s *snapshotIterator) Prev() bool {
+	if !s.iter.Prev() {
+		return false
+	}
+	return s.skipInvisibleBackward()
+}
+
+// Key returns the current key
+func (s *snapshotIterator) Key() []byte {
+	return s.iter.Key()
+}
+
+// Value returns the current value
+func (s *snapshotIterator) Value() []byte {
+	return s.iter.Value()
+}
+
+// Valid returns true if the iterator is positioned at a visible entry
+func (s *snapshotIterator) Valid() bool {
+	return s.iter.Valid() && s.iter.SequenceNumber() <= s.snapshotSeq
+}
+
+// IsTombstone returns true if the current entry is a deletion marker
+func (s *snapshotIterator) IsTombstone() bool {
+	return s.Valid() && s.iter.IsTombstone()
+}
+
+// IsMergeOperand returns true if the current entry is a merge operand
+func (s *snapshotIterator) IsMergeOperand() bool {
+	return s.Valid() && s.iter.IsMergeOperand()
+}
+
+// ExpireAt returns the expiry time of the current entry, or 0 if it never expires
+func (s *snapshotIterator) ExpireAt() uint64 {
+	if !s.Valid() {
+		return 0
+	}
+	return s.iter.ExpireAt()
+}
+
+// SequenceNumber returns the sequence number of the current entry
+func (s *snapshotIterator) SequenceNumber() uint64 {
+	return s.iter.SequenceNumber()
+}
+
+// skipInvisible advances the source past entries newer than the snapshot
+func (s *snapshotIterator) skipInvisible() bool {
+	for s.iter.Valid() {
+		if s.iter.SequenceNumber() <= s.snapshotSeq {
+			return true
+		}
+		if !s.iter.Next() {
+			return false
+		}
+	}
+	return false
+}
+
+// skipInvisibleBackward moves the source back past entries newer than the
+// snapshot
+func (s *snapshotIterator) skipInvisibleBackward() bool {
+	for s.iter.Valid() {
+		if s.iter.SequenceNumber() <= s.snapshotSeq {
+			return true
+		}
+		if !s.iter.Prev() {
+			return false
+		}
+	}
+	return false
+}
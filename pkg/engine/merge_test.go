@@ -0,0 +1,189 @@
+package engine
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/KevoDB/kevo/pkg/common/merge"
+	"github.com/KevoDB/kevo/pkg/wal"
+)
+
+func TestEngineFacade_MergeWithoutOperator(t *testing.T) {
+	dir, err := os.MkdirTemp("", "engine-facade-merge-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	eng, err := NewEngineFacade(dir)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	if err := eng.Merge([]byte("key"), []byte("1")); !errors.Is(err, ErrNoMergeOperator) {
+		t.Errorf("Expected ErrNoMergeOperator, got %v", err)
+	}
+}
+
+func TestEngineFacade_Merge(t *testing.T) {
+	dir, err := os.MkdirTemp("", "engine-facade-merge-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	opts := Options{MergeOperator: merge.CounterOperator{}}
+	eng, err := NewEngineFacadeWithOptions(dir, opts)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	mustMerge := func(key, operand string) {
+		if err := eng.Merge([]byte(key), []byte(operand)); err != nil {
+			t.Fatalf("Failed to merge %s into %s: %v", operand, key, err)
+		}
+	}
+
+	verify := func(stage string, expected map[string]string) {
+		for key, want := range expected {
+			value, err := eng.Get([]byte(key))
+			if err != nil {
+				t.Fatalf("%s: failed to get %s: %v", stage, key, err)
+			}
+			if string(value) != want {
+				t.Errorf("%s: expected %s=%s, got %s", stage, key, want, value)
+			}
+		}
+
+		// Iterators resolve operands the same way
+		iter, err := eng.GetIterator()
+		if err != nil {
+			t.Fatalf("%s: failed to get iterator: %v", stage, err)
+		}
+		seen := 0
+		for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+			if iter.IsTombstone() {
+				continue
+			}
+			if want := expected[string(iter.Key())]; string(iter.Value()) != want {
+				t.Errorf("%s: iterator expected %s=%s, got %s", stage, iter.Key(), want, iter.Value())
+			}
+			seen++
+		}
+		if seen != len(expected) {
+			t.Errorf("%s: expected %d keys from iterator, got %d", stage, len(expected), seen)
+		}
+	}
+
+	// Operands apply on top of an existing value
+	if err := eng.Put([]byte("a"), []byte("10")); err != nil {
+		t.Fatalf("Failed to put key: %v", err)
+	}
+	mustMerge("a", "5")
+
+	snap, err := eng.GetSnapshot()
+	if err != nil {
+		t.Fatalf("Failed to get snapshot: %v", err)
+	}
+	defer eng.ReleaseSnapshot(snap)
+
+	mustMerge("a", "-3")
+
+	// A missing key counts as zero
+	mustMerge("b", "1")
+	mustMerge("b", "1")
+
+	// Operands after a delete start from zero
+	if err := eng.Put([]byte("c"), []byte("100")); err != nil {
+		t.Fatalf("Failed to put key: %v", err)
+	}
+	if err := eng.Delete([]byte("c")); err != nil {
+		t.Fatalf("Failed to delete key: %v", err)
+	}
+	mustMerge("c", "7")
+
+	expected := map[string]string{"a": "12", "b": "2", "c": "7"}
+	verify("memtable", expected)
+
+	// Operands are resolved across memtables and SSTables
+	if err := eng.FlushImMemTables(); err != nil {
+		t.Fatalf("Failed to flush memtables: %v", err)
+	}
+	verify("after flush", expected)
+
+	mustMerge("a", "8")
+	mustMerge("b", "3")
+	expected = map[string]string{"a": "20", "b": "5", "c": "7"}
+	verify("memtable over SSTables", expected)
+
+	// The snapshot still sees the value before the later operands
+	if value, err := snap.Get([]byte("a")); err != nil || string(value) != "15" {
+		t.Errorf("Expected snapshot value 15, got %s (err: %v)", value, err)
+	}
+
+	// Operands survive a restart
+	if err := eng.Close(); err != nil {
+		t.Fatalf("Failed to close engine: %v", err)
+	}
+	eng, err = NewEngineFacadeWithOptions(dir, opts)
+	if err != nil {
+		t.Fatalf("Failed to reopen engine: %v", err)
+	}
+	defer eng.Close()
+
+	verify("after reopen", expected)
+}
+
+func TestEngineFacade_MergeBatch(t *testing.T) {
+	dir, err := os.MkdirTemp("", "engine-facade-merge-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	opts := Options{MergeOperator: merge.CounterOperator{}}
+	eng, err := NewEngineFacadeWithOptions(dir, opts)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	// Every operand of a batch applies, although they share a sequence number
+	err = eng.ApplyBatch([]*wal.Entry{
+		{Type: wal.OpTypeMerge, Key: []byte("a"), Value: []byte("1")},
+		{Type: wal.OpTypeMerge, Key: []byte("a"), Value: []byte("1")},
+		{Type: wal.OpTypeMerge, Key: []byte("b"), Value: []byte("4")},
+	})
+	if err != nil {
+		t.Fatalf("Failed to apply batch: %v", err)
+	}
+	if err := eng.Merge([]byte("b"), []byte("3")); err != nil {
+		t.Fatalf("Failed to merge into b: %v", err)
+	}
+
+	verify := func(stage string) {
+		for key, want := range map[string]string{"a": "2", "b": "7"} {
+			value, err := eng.Get([]byte(key))
+			if err != nil {
+				t.Fatalf("%s: failed to get %s: %v", stage, key, err)
+			}
+			if string(value) != want {
+				t.Errorf("%s: expected %s=%s, got %s", stage, key, want, value)
+			}
+		}
+	}
+	verify("memtable")
+
+	// Operands recovered from the WAL are applied the same way
+	if err := eng.Close(); err != nil {
+		t.Fatalf("Failed to close engine: %v", err)
+	}
+	eng, err = NewEngineFacadeWithOptions(dir, opts)
+	if err != nil {
+		t.Fatalf("Failed to reopen engine: %v", err)
+	}
+	defer eng.Close()
+
+	verify("after reopen")
+}
@@ -0,0 +1,42 @@
+package engine
+
+import (
+	"github.com/KevoDB/kevo/pkg/common/merge"
+	"github.com/KevoDB/kevo/pkg/compaction"
+	"github.com/KevoDB/kevo/pkg/sstable"
+)
+
+// MergeOperator combines the operands written with Merge into a value
+type MergeOperator = merge.Operator
+
+// CompactionFilter drops or rewrites values as compaction rewrites them
+type CompactionFilter = compaction.CompactionFilter
+
+// CompactionFilterFunc adapts a function to the CompactionFilter interface
+type CompactionFilterFunc = compaction.CompactionFilterFunc
+
+// FilterDecision tells compaction what to do with a value passed to a CompactionFilter
+type FilterDecision = compaction.FilterDecision
+
+// Decisions of a CompactionFilter
+const (
+	FilterKeep        = compaction.FilterKeep
+	FilterDrop        = compaction.FilterDrop
+	FilterChangeValue = compaction.FilterChangeValue
+)
+
+// ReadOptions configures how iterators read SSTable blocks. Scans over many
+// keys should turn FillCache off so they don't evict frequently read blocks.
+type ReadOptions = sstable.ReadOptions
+
+// Options holds optional settings for opening an engine
+type Options struct {
+	// MergeOperator resolves merge operands written with Merge. Merge is
+	// rejected with ErrNoMergeOperator if it isn't set.
+	MergeOperator MergeOperator
+
+	// CompactionFilter is called with the values compaction rewrites in every
+	// column family, to drop or change them. Values an open snapshot or
+	// transaction can still read are never filtered.
+	CompactionFilter CompactionFilter
+}
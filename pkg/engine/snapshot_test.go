@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestEngineFacade_Snapshot(t *testing.T) {
+	// Create a temp directory for the test
+	dir, err := os.MkdirTemp("", "engine-facade-snapshot-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	eng, err := NewEngineFacade(dir)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	// Initial state
+	if err := eng.Put([]byte("a"), []byte("a1")); err != nil {
+		t.Fatalf("Failed to put key: %v", err)
+	}
+	if err := eng.Put([]byte("b"), []byte("b1")); err != nil {
+		t.Fatalf("Failed to put key: %v", err)
+	}
+
+	snap, err := eng.GetSnapshot()
+	if err != nil {
+		t.Fatalf("Failed to get snapshot: %v", err)
+	}
+
+	// Changes made after the snapshot
+	if err := eng.Put([]byte("a"), []byte("a2")); err != nil {
+		t.Fatalf("Failed to put key: %v", err)
+	}
+	if err := eng.Delete([]byte("b")); err != nil {
+		t.Fatalf("Failed to delete key: %v", err)
+	}
+	if err := eng.Put([]byte("c"), []byte("c1")); err != nil {
+		t.Fatalf("Failed to put key: %v", err)
+	}
+
+	verify := func(stage string) {
+		// Point reads see the state at snapshot time
+		expected := map[string][]byte{"a": []byte("a1"), "b": []byte("b1")}
+		for key, want := range expected {
+			value, err := snap.Get([]byte(key))
+			if err != nil {
+				t.Fatalf("%s: failed to get %s from snapshot: %v", stage, key, err)
+			}
+			if !bytes.Equal(value, want) {
+				t.Errorf("%s: snapshot value for %s: expected %s, got %s", stage, key, want, value)
+			}
+		}
+		if _, err := snap.Get([]byte("c")); err == nil {
+			t.Errorf("%s: key written after the snapshot should not be visible", stage)
+		}
+
+		// Iteration sees the same state
+		iter, err := snap.GetIterator()
+		if err != nil {
+			t.Fatalf("%s: failed to get snapshot iterator: %v", stage, err)
+		}
+		var keys []string
+		for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+			if iter.IsTombstone() {
+				continue
+			}
+			keys = append(keys, string(iter.Key()))
+			if want := expected[string(iter.Key())]; !bytes.Equal(iter.Value(), want) {
+				t.Errorf("%s: snapshot iterator value for %s: expected %s, got %s", stage, iter.Key(), want, iter.Value())
+			}
+		}
+		if len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+			t.Errorf("%s: expected snapshot iterator keys [a b], got %v", stage, keys)
+		}
+
+		// Range iteration is bounded
+		rangeIter, err := snap.GetRangeIterator([]byte("b"), []byte("z"))
+		if err != nil {
+			t.Fatalf("%s: failed to get snapshot range iterator: %v", stage, err)
+		}
+		rangeIter.SeekToFirst()
+		if !rangeIter.Valid() || string(rangeIter.Key()) != "b" {
+			t.Errorf("%s: expected snapshot range iterator to start at b", stage)
+		}
+	}
+
+	verify("memtable")
+
+	// The live view sees the new state
+	if value, err := eng.Get([]byte("a")); err != nil || !bytes.Equal(value, []byte("a2")) {
+		t.Errorf("Expected live value a2, got %s (err: %v)", value, err)
+	}
+
+	// Versions pinned by the snapshot survive a flush
+	if err := eng.FlushImMemTables(); err != nil {
+		t.Fatalf("Failed to flush memtables: %v", err)
+	}
+	verify("after flush")
+
+	// Released snapshots can no longer be read
+	if err := eng.ReleaseSnapshot(snap); err != nil {
+		t.Fatalf("Failed to release snapshot: %v", err)
+	}
+	if _, err := snap.Get([]byte("a")); !errors.Is(err, ErrSnapshotReleased) {
+		t.Errorf("Expected ErrSnapshotReleased, got %v", err)
+	}
+	if err := eng.ReleaseSnapshot(snap); err != nil {
+		t.Errorf("Releasing a snapshot twice should be a no-op, got %v", err)
+	}
+}
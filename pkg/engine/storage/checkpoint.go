@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/KevoDB/kevo/pkg/common/fsutil"
+	"github.com/KevoDB/kevo/pkg/versionlog"
+	"github.com/KevoDB/kevo/pkg/wal"
+)
+
+// maxCheckpointAttempts bounds how many times the files of a version are
+// linked again when a compaction deletes one of them in the meantime
+const maxCheckpointAttempts = 10
+
+// Checkpoint writes a copy of the database to sstDir and walDir while it
+// keeps serving reads and writes, and returns the sequence number the copy
+// is consistent to. SSTables are immutable and hard linked where possible,
+// along with the WAL files already rotated out; the entries of the live WAL
+// file are copied up to the returned sequence number. Column families are
+// copied to the same subdirectories of sstDir as they have in the SSTable
+// directory. It must be called on the manager of the default family.
+func (m *Manager) Checkpoint(sstDir, walDir string) (uint64, error) {
+	if m.walOwner != nil {
+		return 0, errors.New("checkpoints must be created on the default family")
+	}
+	if m.closed.Load() {
+		return 0, ErrStorageClosed
+	}
+
+	m.mu.RLock()
+	managers := []*Manager{m}
+	for _, cf := range m.families {
+		managers = append(managers, cf)
+	}
+	m.mu.RUnlock()
+
+	// Link the SSTables first, so that the WAL copied afterwards holds
+	// everything they don't
+	for _, manager := range managers {
+		rel, err := filepath.Rel(m.sstableDir, manager.sstableDir)
+		if err != nil {
+			return 0, fmt.Errorf("failed to locate column family directory: %w", err)
+		}
+		if err := manager.checkpointSSTables(filepath.Join(sstDir, rel)); err != nil {
+			return 0, err
+		}
+	}
+
+	return m.checkpointWAL(walDir)
+}
+
+// checkpointSSTables links the files of the live version into dir and writes
+// a version log describing them
+func (m *Manager) checkpointSSTables(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		version := m.versions.Current()
+
+		linked, err := linkVersion(m.sstableDir, dir, version)
+		if err == nil {
+			if err := versionlog.Write(dir, version); err != nil {
+				return err
+			}
+			return fsutil.SyncDir(dir)
+		}
+
+		for _, path := range linked {
+			os.Remove(path)
+		}
+
+		// A compaction committed and deleted a file of the version before it
+		// was linked, the next version no longer has it
+		if !os.IsNotExist(err) || attempt == maxCheckpointAttempts {
+			return fmt.Errorf("failed to link SSTables: %w", err)
+		}
+	}
+}
+
+// linkVersion links the files of a version from src into dst, returning the
+// paths linked so far
+func linkVersion(src, dst string, version *versionlog.Version) ([]string, error) {
+	var linked []string
+	for _, file := range version.Files() {
+		path := filepath.Join(dst, file.Name)
+		if err := fsutil.LinkFile(filepath.Join(src, file.Name), path); err != nil {
+			return linked, err
+		}
+		linked = append(linked, path)
+	}
+	return linked, nil
+}
+
+// checkpointWAL copies the WAL into dir and returns the last sequence number
+// copied. Writes carry on meanwhile, the ones after that sequence number are
+// left out.
+func (m *Manager) checkpointWAL(dir string) (uint64, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	// Holding the lock keeps the WAL from rotating while its sequence number
+	// is read and its buffered entries are written out. Every entry up to the
+	// sequence number is in the buffer by then.
+	m.mu.RLock()
+	currentWAL := m.getWAL()
+	lastSeq := currentWAL.GetNextSequence() - 1
+	err := currentWAL.Sync()
+	liveName := fmt.Sprintf("%020d.wal", currentWAL.LogNumber())
+	m.mu.RUnlock()
+	if err != nil {
+		return 0, fmt.Errorf("failed to sync WAL: %w", err)
+	}
+
+	walFiles, err := wal.FindWALFiles(m.walDir)
+	if err != nil {
+		return 0, err
+	}
+	for _, path := range walFiles {
+		name := filepath.Base(path)
+		switch {
+		case name < liveName:
+			// Rotated out, nothing more is written to it
+			if err := fsutil.LinkFile(path, filepath.Join(dir, name)); err != nil {
+				return 0, fmt.Errorf("failed to link WAL file: %w", err)
+			}
+		case name == liveName:
+			if _, err := wal.CopyFile(path, filepath.Join(dir, name), lastSeq); err != nil {
+				return 0, fmt.Errorf("failed to copy WAL file: %w", err)
+			}
+		}
+		// Files created by later rotations only hold later entries
+	}
+
+	if err := fsutil.SyncDir(dir); err != nil {
+		return 0, err
+	}
+	return lastSeq, nil
+}
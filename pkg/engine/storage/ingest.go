@@ -0,0 +1,285 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/KevoDB/kevo/pkg/common/fsutil"
+	"github.com/KevoDB/kevo/pkg/config"
+	"github.com/KevoDB/kevo/pkg/sstable"
+	"github.com/KevoDB/kevo/pkg/versionlog"
+)
+
+var (
+	// ErrIngestOverlap is returned when files ingested together have
+	// overlapping key ranges
+	ErrIngestOverlap = errors.New("ingested files overlap each other")
+	// ErrIngestMemTableOverlap is returned when the key range of an ingested
+	// file has keys in the MemTables, which would hide the ingested versions
+	ErrIngestMemTableOverlap = errors.New("ingested files overlap keys in the MemTables")
+)
+
+// ingestFile is a file being ingested, staged under a hidden name in the
+// SSTable directory until it's committed
+type ingestFile struct {
+	source   string
+	staged   string
+	smallest []byte
+	largest  []byte
+}
+
+// Ingestion adds externally written SSTable files to the database. The files
+// are checked and staged by PrepareIngest, then made visible all at once by
+// Commit, or discarded by Abort.
+type Ingestion struct {
+	m     *Manager
+	files []*ingestFile
+}
+
+// PrepareIngest checks that the SSTable files at paths can be ingested and
+// stages them in the SSTable directory, hard linked where possible. Every
+// file must hold at least one entry, each key at most once, and no two files
+// may overlap.
+func (m *Manager) PrepareIngest(paths []string) (*Ingestion, error) {
+	if m.closed.Load() {
+		return nil, ErrStorageClosed
+	}
+	if len(paths) == 0 {
+		return nil, errors.New("no files to ingest")
+	}
+
+	ing := &Ingestion{m: m}
+	for _, path := range paths {
+		file, err := checkIngestFile(path)
+		if err != nil {
+			ing.Abort()
+			return nil, err
+		}
+		ing.files = append(ing.files, file)
+	}
+
+	for i, a := range ing.files {
+		for _, b := range ing.files[i+1:] {
+			if rangesOverlap(a.smallest, a.largest, b.smallest, b.largest) {
+				ing.Abort()
+				return nil, fmt.Errorf("%w: %s and %s", ErrIngestOverlap, a.source, b.source)
+			}
+		}
+	}
+
+	// Leftovers of a crash before the commit are removed on recovery along
+	// with the other temporary SSTables
+	for _, file := range ing.files {
+		staged := filepath.Join(m.sstableDir, fmt.Sprintf(".ingest-%d.sst.tmp", atomic.AddUint64(&ingestCounter, 1)))
+		if err := fsutil.LinkFile(file.source, staged); err != nil {
+			ing.Abort()
+			return nil, fmt.Errorf("failed to stage %s: %w", file.source, err)
+		}
+		file.staged = staged
+	}
+
+	return ing, nil
+}
+
+// ingestCounter numbers the staged files of ingestions
+var ingestCounter uint64
+
+// checkIngestFile reads an SSTable file through and returns its key range,
+// including range tombstones
+func checkIngestFile(path string) (*ingestFile, error) {
+	reader, err := sstable.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer reader.Close()
+
+	file := &ingestFile{source: path}
+	var prev []byte
+	count := 0
+	iter := reader.NewIteratorWithOptions(sstable.ReadOptions{})
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		key := iter.Key()
+		if prev != nil && bytes.Compare(key, prev) <= 0 {
+			return nil, fmt.Errorf("%s has key %q after %q: %w", path, key, prev, sstable.ErrKeyOrder)
+		}
+		prev = append(prev[:0], key...)
+		count++
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	tombstones := reader.RangeTombstones()
+	if count+len(tombstones) == 0 {
+		return nil, fmt.Errorf("%s: %w", path, sstable.ErrEmptyFile)
+	}
+
+	file.smallest, file.largest = reader.KeyRange()
+	for _, t := range tombstones {
+		if file.smallest == nil || bytes.Compare(t.Start, file.smallest) < 0 {
+			file.smallest = t.Start
+		}
+		if file.largest == nil || bytes.Compare(t.End, file.largest) > 0 {
+			file.largest = t.End
+		}
+	}
+	file.smallest = append([]byte(nil), file.smallest...)
+	file.largest = append([]byte(nil), file.largest...)
+
+	return file, nil
+}
+
+// Commit adds the staged files to the live version and returns the sequence
+// number given to all of their entries. Each file goes to the deepest level
+// it doesn't overlap any files in or above, or level 0 for compaction styles
+// without non-overlapping levels. The caller must keep compactions from
+// running until it returns.
+func (ing *Ingestion) Commit() (uint64, error) {
+	m := ing.m
+
+	// Flushes take file numbers and record the next one in their version
+	// edits. Holding flushMu keeps those edits and numbers from interleaving
+	// with ours, so a flush can't record a next file number older than one
+	// taken here.
+	m.flushMu.Lock()
+	defer m.flushMu.Unlock()
+
+	// Writes wait until the files are visible
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed.Load() {
+		return 0, ErrStorageClosed
+	}
+
+	if err := ing.checkMemTables(); err != nil {
+		return 0, err
+	}
+
+	version := m.versions.Current()
+	var committed []string
+	removeCommitted := func() {
+		for _, path := range committed {
+			os.Remove(path)
+		}
+	}
+
+	var edit versionlog.Edit
+	timestamp := time.Now().UnixNano()
+	for _, file := range ing.files {
+		level := m.ingestLevel(version, file.smallest, file.largest)
+		fileNum := atomic.AddUint64(&m.nextFileNum, 1) - 1
+		path := filepath.Join(m.sstableDir, fmt.Sprintf(sstableFilenameFormat, level, fileNum, timestamp))
+		if err := os.Rename(file.staged, path); err != nil {
+			removeCommitted()
+			return 0, fmt.Errorf("failed to add %s: %w", file.source, err)
+		}
+		committed = append(committed, path)
+
+		reader, err := sstable.OpenReader(path)
+		if err != nil {
+			removeCommitted()
+			return 0, fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		meta, err := versionlog.Describe(reader)
+		reader.Close()
+		if err != nil {
+			removeCommitted()
+			return 0, err
+		}
+		edit.Added = append(edit.Added, meta)
+	}
+	if err := fsutil.SyncDir(m.sstableDir); err != nil {
+		removeCommitted()
+		return 0, err
+	}
+
+	// The entries are newer than every write so far. The WAL has no entry
+	// for the sequence number, the version log records it instead.
+	seqNum := m.lastSeqNum + 1
+	if currentWAL := m.getWAL(); currentWAL != nil {
+		seqNum = max(seqNum, currentWAL.ReserveSequence())
+	}
+	for i := range edit.Added {
+		edit.Added[i].GlobalSequence = seqNum
+		edit.Added[i].LargestSequence = seqNum
+	}
+	edit.NextFileNumber = atomic.LoadUint64(&m.nextFileNum)
+
+	if err := m.versions.Apply(edit); err != nil {
+		removeCommitted()
+		return 0, fmt.Errorf("failed to commit ingested files: %w", err)
+	}
+	m.lastSeqNum = seqNum
+
+	if err := m.reloadSSTables(); err != nil {
+		return 0, err
+	}
+	return seqNum, nil
+}
+
+// Abort removes the files staged for the ingestion
+func (ing *Ingestion) Abort() {
+	for _, file := range ing.files {
+		if file.staged != "" {
+			os.Remove(file.staged)
+		}
+	}
+}
+
+// checkMemTables returns an error if any key or range tombstone in the
+// MemTables falls in the key range of an ingested file. Lookups check the
+// MemTables first, so such keys would hide the ingested versions.
+func (ing *Ingestion) checkMemTables() error {
+	for _, mem := range ing.m.memTablePool.GetMemTables() {
+		iter := mem.NewIterator()
+		for _, file := range ing.files {
+			iter.Seek(file.smallest)
+			if iter.Valid() && bytes.Compare(iter.Key(), file.largest) <= 0 {
+				return fmt.Errorf("%w: %s has key %q", ErrIngestMemTableOverlap, file.source, iter.Key())
+			}
+			for _, t := range mem.RangeTombstones() {
+				if rangesOverlap(t.Start, t.End, file.smallest, file.largest) {
+					return fmt.Errorf("%w: %s overlaps a range deletion", ErrIngestMemTableOverlap, file.source)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ingestLevel returns the level an ingested file with the given key range
+// goes to
+func (m *Manager) ingestLevel(version *versionlog.Version, smallest, largest []byte) int {
+	// Only these styles keep the files of a level from overlapping
+	switch m.cfg.CompactionStyle {
+	case "", config.CompactionStyleTiered, config.CompactionStyleLeveled:
+	default:
+		return 0
+	}
+
+	overlapping := make(map[int]bool)
+	for _, file := range version.Files() {
+		if rangesOverlap(file.Smallest, file.Largest, smallest, largest) {
+			overlapping[file.Level] = true
+		}
+	}
+
+	bottom := max(m.cfg.CompactionLevels-1, 1)
+	level := 0
+	for l := 0; l <= bottom && !overlapping[l]; l++ {
+		level = l
+	}
+	return level
+}
+
+// rangesOverlap returns whether the inclusive key ranges [aStart, aEnd] and
+// [bStart, bEnd] overlap
+func rangesOverlap(aStart, aEnd, bStart, bEnd []byte) bool {
+	return bytes.Compare(aStart, bEnd) <= 0 && bytes.Compare(bStart, aEnd) <= 0
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/KevoDB/kevo/pkg/sstable"
+)
+
+// sortSSTables orders SSTables from oldest to newest data. Compaction moves
+// data to higher levels, so higher levels hold older data, and within a level
+// files with higher sequence numbers are newer. Files whose names can't be
+// parsed are treated as the oldest.
+func sortSSTables(readers []*sstable.Reader) {
+	type fileOrder struct {
+		level    int
+		sequence uint64
+		ok       bool
+	}
+
+	orders := make(map[*sstable.Reader]fileOrder, len(readers))
+	for _, reader := range readers {
+		var order fileOrder
+		var timestamp int64
+		n, err := fmt.Sscanf(filepath.Base(reader.FilePath()), sstableFilenameFormat,
+			&order.level, &order.sequence, &timestamp)
+		order.ok = n == 3 && err == nil
+		orders[reader] = order
+	}
+
+	sort.SliceStable(readers, func(i, j int) bool {
+		a, b := orders[readers[i]], orders[readers[j]]
+		if a.ok != b.ok {
+			return !a.ok
+		}
+		if a.level != b.level {
+			return a.level > b.level
+		}
+		return a.sequence < b.sequence
+	})
+}
+
+// seekSSTables returns an iterator positioned at the newest version of key
+// visible at seqNum, from the newest SSTable that holds one, or nil if no
+// SSTable does. SSTables are skipped without reading them when the key is
+// outside their key range or ruled out by their bloom filters. The caller must
+// hold the read lock.
+func (m *Manager) seekSSTables(key []byte, seqNum uint64) *sstable.Iterator {
+	for i := len(m.sstables) - 1; i >= 0; i-- {
+		iter := m.seekSSTable(m.sstables[i], key)
+		if iter == nil {
+			continue
+		}
+
+		// Versions of a key are stored newest first, skip those written after the snapshot
+		for iter.Valid() && bytes.Equal(iter.Key(), key) && iter.SequenceNumber() > seqNum {
+			iter.Next()
+		}
+
+		if iter.Valid() && bytes.Equal(iter.Key(), key) {
+			return iter
+		}
+	}
+
+	return nil
+}
+
+// seekSSTable returns an iterator positioned at the newest version of key in
+// an SSTable, or nil if the SSTable doesn't hold the key. The outcome of the
+// bloom filter check is reported to the statistics collector.
+func (m *Manager) seekSSTable(reader *sstable.Reader, key []byte) *sstable.Iterator {
+	if !reader.InKeyRange(key) {
+		return nil
+	}
+
+	if reader.HasBloomFilter() && !reader.MayContain(key) {
+		m.stats.TrackBloomFilter(false, false)
+		return nil
+	}
+
+	iter := reader.NewIterator()
+	found := iter.Seek(key) && bytes.Equal(iter.Key(), key)
+	if reader.HasBloomFilter() {
+		m.stats.TrackBloomFilter(true, found)
+	}
+
+	if !found {
+		return nil
+	}
+	return iter
+}
@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KevoDB/kevo/pkg/config"
+	"github.com/KevoDB/kevo/pkg/sstable"
+	"github.com/KevoDB/kevo/pkg/stats"
+)
+
+// TestLookupSkipsSSTables tests that point lookups search SSTables from the
+// newest level down and skip files that can't hold the key.
+func TestLookupSkipsSSTables(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "storage-lookup-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	sstDir := filepath.Join(tempDir, "sst")
+	walDir := filepath.Join(tempDir, "wal")
+	if err := os.MkdirAll(sstDir, 0755); err != nil {
+		t.Fatalf("Failed to create SSTable directory: %v", err)
+	}
+
+	// writeSSTable writes keys with the given value and sequence number
+	writeSSTable := func(level int, fileNum uint64, keys []string, value string, seqNum uint64) {
+		path := filepath.Join(sstDir, fmt.Sprintf(sstableFilenameFormat, level, fileNum, 0))
+		writer, err := sstable.NewWriter(path)
+		if err != nil {
+			t.Fatalf("Failed to create SSTable writer: %v", err)
+		}
+		for _, key := range keys {
+			if err := writer.AddWithSequence([]byte(key), []byte(value), seqNum); err != nil {
+				t.Fatalf("Failed to add key: %v", err)
+			}
+		}
+		if err := writer.Finish(); err != nil {
+			t.Fatalf("Failed to finish SSTable: %v", err)
+		}
+	}
+
+	// Level 1 holds older data than level 0, even though its file sorts last
+	writeSSTable(1, 1, []string{"a", "c", "e"}, "old", 1)
+	writeSSTable(0, 2, []string{"a", "b"}, "new", 2)
+
+	cfg := &config.Config{
+		Version:         config.CurrentManifestVersion,
+		SSTDir:          sstDir,
+		WALDir:          walDir,
+		MemTableSize:    1024 * 1024,
+		MemTablePoolCap: 2,
+		MaxMemTables:    2,
+	}
+
+	statsCollector := stats.NewAtomicCollector()
+	manager, err := NewManager(cfg, statsCollector)
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+	defer manager.Close()
+
+	for key, want := range map[string]string{"a": "new", "b": "new", "c": "old", "e": "old"} {
+		value, err := manager.Get([]byte(key))
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", key, err)
+		}
+		if string(value) != want {
+			t.Errorf("Expected %s=%s, got %s", key, want, value)
+		}
+	}
+
+	if snapValue, err := manager.GetWithSnapshot([]byte("a"), 1); err != nil || string(snapValue) != "old" {
+		t.Errorf("Expected a=old at sequence 1, got %s, %v", snapValue, err)
+	}
+
+	// "a" and "b" are found in the first file searched
+	found := statsCollector.GetStats()["bloom_filter_true_positive"].(uint64)
+
+	// Keys outside every file's range never reach a bloom filter
+	before := statsCollector.GetStats()
+	if _, err := manager.Get([]byte("z")); err != ErrKeyNotFound {
+		t.Errorf("Expected ErrKeyNotFound for z, got %v", err)
+	}
+	after := statsCollector.GetStats()
+	for _, name := range []string{"bloom_filter_useful", "bloom_filter_true_positive", "bloom_filter_false_positive"} {
+		if before[name] != after[name] {
+			t.Errorf("Expected %s to be unchanged by a lookup outside every key range", name)
+		}
+	}
+
+	// Missing keys inside the key ranges are mostly ruled out by bloom filters
+	for i := 0; i < 20; i++ {
+		if _, err := manager.Get([]byte(fmt.Sprintf("b%02d", i))); err != ErrKeyNotFound {
+			t.Errorf("Expected ErrKeyNotFound for b%02d, got %v", i, err)
+		}
+	}
+	after = statsCollector.GetStats()
+	if after["bloom_filter_useful"].(uint64) == 0 {
+		t.Errorf("Expected bloom filters to rule out missing keys")
+	}
+	if after["bloom_filter_true_positive"].(uint64) != found {
+		t.Errorf("Expected no bloom filter true positives for missing keys")
+	}
+}
+
+// TestLookupUsesBlockCache tests that SSTable blocks are read through the
+// shared block cache and that scans can avoid filling it.
+func TestLookupUsesBlockCache(t *testing.T) {
+	tempDir := t.TempDir()
+	sstDir := filepath.Join(tempDir, "sst")
+	if err := os.MkdirAll(sstDir, 0755); err != nil {
+		t.Fatalf("Failed to create SSTable directory: %v", err)
+	}
+
+	path := filepath.Join(sstDir, fmt.Sprintf(sstableFilenameFormat, 0, 1, 0))
+	writer, err := sstable.NewWriter(path)
+	if err != nil {
+		t.Fatalf("Failed to create SSTable writer: %v", err)
+	}
+	for i := 0; i < 2000; i++ {
+		if err := writer.AddWithSequence([]byte(fmt.Sprintf("key%05d", i)), []byte("value"), 1); err != nil {
+			t.Fatalf("Failed to add key: %v", err)
+		}
+	}
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish SSTable: %v", err)
+	}
+
+	cfg := &config.Config{
+		Version:         config.CurrentManifestVersion,
+		SSTDir:          sstDir,
+		WALDir:          filepath.Join(tempDir, "wal"),
+		MemTableSize:    1024 * 1024,
+		MemTablePoolCap: 2,
+		MaxMemTables:    2,
+		BlockCacheSize:  1024 * 1024,
+	}
+
+	manager, err := NewManager(cfg, stats.NewAtomicCollector())
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+	defer manager.Close()
+
+	// A scan that doesn't fill the cache only leaves the block read at open
+	iter, err := manager.GetRangeIteratorWithOptions(nil, nil, sstable.ReadOptions{FillCache: false})
+	if err != nil {
+		t.Fatalf("Failed to create iterator: %v", err)
+	}
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+	}
+	if entries := manager.GetStorageStats()["block_cache_entries"]; entries != 1 {
+		t.Errorf("Expected 1 cached block after scan, got %v", entries)
+	}
+
+	// The second lookup of a key is served from the cache
+	for i := 0; i < 2; i++ {
+		if _, err := manager.Get([]byte("key00000")); err != nil {
+			t.Fatalf("Failed to get key: %v", err)
+		}
+	}
+	hits := manager.GetStorageStats()["block_cache_hits"].(uint64)
+	if hits == 0 {
+		t.Error("Expected the second lookup to hit the block cache")
+	}
+
+	// Reloading keeps the readers of unchanged files and their cached blocks
+	if err := manager.ReloadSSTables(); err != nil {
+		t.Fatalf("Failed to reload SSTables: %v", err)
+	}
+	if _, err := manager.Get([]byte("key00000")); err != nil {
+		t.Fatalf("Failed to get key: %v", err)
+	}
+	if got := manager.GetStorageStats()["block_cache_hits"].(uint64); got <= hits {
+		t.Errorf("Expected a cache hit after reload, hits went from %d to %d", hits, got)
+	}
+}
@@ -4,20 +4,28 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 	"unsafe"
 
 	"github.com/KevoDB/kevo/pkg/common/iterator"
+	"github.com/KevoDB/kevo/pkg/common/merge"
+	"github.com/KevoDB/kevo/pkg/common/rangedel"
+	"github.com/KevoDB/kevo/pkg/common/ratelimit"
+	"github.com/KevoDB/kevo/pkg/common/snapshot"
+	"github.com/KevoDB/kevo/pkg/common/ttl"
 	"github.com/KevoDB/kevo/pkg/config"
 	"github.com/KevoDB/kevo/pkg/engine/interfaces"
 	engineIterator "github.com/KevoDB/kevo/pkg/engine/iterator"
 	"github.com/KevoDB/kevo/pkg/memtable"
 	"github.com/KevoDB/kevo/pkg/sstable"
 	"github.com/KevoDB/kevo/pkg/stats"
+	"github.com/KevoDB/kevo/pkg/versionlog"
 	"github.com/KevoDB/kevo/pkg/wal"
 )
 
@@ -31,8 +39,9 @@ const (
 
 // Common errors
 var (
-	ErrStorageClosed = errors.New("storage is closed")
-	ErrKeyNotFound   = errors.New("key not found")
+	ErrStorageClosed       = errors.New("storage is closed")
+	ErrKeyNotFound         = errors.New("key not found")
+	ErrUnknownColumnFamily = errors.New("unknown column family")
 )
 
 // Manager implements the interfaces.StorageManager interface
@@ -53,6 +62,28 @@ type Manager struct {
 	// Storage layer
 	sstables []*sstable.Reader
 
+	// Log of the live SSTable files, which flushes and compactions commit to
+	versions *versionlog.Log
+
+	// Data blocks cached for all SSTables, shared with the column families
+	blockCache *sstable.BlockCache
+
+	// Limits the write rate of flushes and compactions, shared with the
+	// column families
+	rateLimiter *ratelimit.Limiter
+
+	// Sequence numbers pinned by open snapshots
+	snapshots *snapshot.Tracker
+
+	// Merge operator used to resolve merge operands
+	mergeOperator merge.Operator
+
+	// Column family stored by this manager. Column families write to the WAL
+	// of the default family's manager, which also routes batches to them.
+	family   uint32
+	walOwner *Manager
+	families map[uint32]*Manager
+
 	// State management
 	nextFileNum uint64
 	lastSeqNum  uint64
@@ -70,6 +101,43 @@ type Manager struct {
 
 // NewManager creates a new storage manager
 func NewManager(cfg *config.Config, statsCollector stats.Collector) (*Manager, error) {
+	return newManager(cfg, statsCollector, nil, wal.DefaultFamily)
+}
+
+// NewColumnFamily creates the storage manager of a column family. The column
+// family has its own MemTables and SSTables in cfg.SSTDir but writes to the
+// WAL of this manager, which must store the default family.
+func (m *Manager) NewColumnFamily(cfg *config.Config, family uint32) (*Manager, error) {
+	if m.walOwner != nil {
+		return nil, errors.New("column families must be created on the default family")
+	}
+	if family == wal.DefaultFamily {
+		return nil, fmt.Errorf("column family ID %d is reserved", family)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed.Load() {
+		return nil, ErrStorageClosed
+	}
+	if _, exists := m.families[family]; exists {
+		return nil, fmt.Errorf("column family %d already exists", family)
+	}
+
+	cf, err := newManager(cfg, m.stats, m, family)
+	if err != nil {
+		return nil, err
+	}
+	cf.mergeOperator = m.mergeOperator
+
+	m.families[family] = cf
+	return cf, nil
+}
+
+// newManager creates a storage manager for the given column family. Managers
+// of column families other than the default one share the WAL of owner.
+func newManager(cfg *config.Config, statsCollector stats.Collector, owner *Manager, family uint32) (*Manager, error) {
 	if cfg == nil {
 		return nil, errors.New("config cannot be nil")
 	}
@@ -92,27 +160,43 @@ func NewManager(cfg *config.Config, statsCollector stats.Collector) (*Manager, e
 		return nil, fmt.Errorf("failed to create wal directory: %w", err)
 	}
 
-	// Create or reuse a WAL
+	// Create or reuse a WAL, unless the column family shares one
 	var walLogger *wal.WAL
 	var err error
 
-	// First try to reuse an existing WAL file
-	walLogger, err = wal.ReuseWAL(cfg, walDir, 1)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check for reusable WAL: %w", err)
-	}
-
-	// If no suitable WAL found, create a new one
-	if walLogger == nil {
-		walLogger, err = wal.NewWAL(cfg, walDir)
+	if owner == nil {
+		// First try to reuse an existing WAL file
+		walLogger, err = wal.ReuseWAL(cfg, walDir, 1)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create WAL: %w", err)
+			return nil, fmt.Errorf("failed to check for reusable WAL: %w", err)
+		}
+
+		// If no suitable WAL found, create a new one
+		if walLogger == nil {
+			walLogger, err = wal.NewWAL(cfg, walDir)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create WAL: %w", err)
+			}
 		}
 	}
 
 	// Create the MemTable pool
 	memTablePool := memtable.NewMemTablePool(cfg)
 
+	// Column families cache their blocks in the cache of the default family
+	blockCache := sstable.NewBlockCache(cfg.BlockCacheSize)
+	if owner != nil {
+		blockCache = owner.blockCache
+	}
+
+	// Likewise, all column families write within the same rate limit
+	var rateLimiter *ratelimit.Limiter
+	if owner != nil {
+		rateLimiter = owner.rateLimiter
+	} else {
+		rateLimiter = ratelimit.NewLimiter(cfg.RateLimitBytesPerSec, cfg.RateLimitAutoTune)
+	}
+
 	m := &Manager{
 		cfg:          cfg,
 		dataDir:      dataDir,
@@ -122,6 +206,12 @@ func NewManager(cfg *config.Config, statsCollector stats.Collector) (*Manager, e
 		memTablePool: memTablePool,
 		immutableMTs: make([]*memtable.MemTable, 0),
 		sstables:     make([]*sstable.Reader, 0),
+		blockCache:   blockCache,
+		rateLimiter:  rateLimiter,
+		snapshots:    snapshot.NewTracker(),
+		family:       family,
+		walOwner:     owner,
+		families:     make(map[uint32]*Manager),
 		bgFlushCh:    make(chan struct{}, 1),
 		nextFileNum:  1,
 		stats:        statsCollector,
@@ -134,6 +224,7 @@ func NewManager(cfg *config.Config, statsCollector stats.Collector) (*Manager, e
 
 	// Recover from WAL if any exist
 	if err := m.recoverFromWAL(); err != nil {
+		m.versions.Close()
 		return nil, fmt.Errorf("failed to recover from WAL: %w", err)
 	}
 
@@ -159,7 +250,7 @@ func (m *Manager) Put(key, value []byte) error {
 		if currentWAL == nil {
 			return ErrStorageClosed
 		}
-		seqNum, err := currentWAL.Append(wal.OpTypePut, key, value)
+		seqNum, err := currentWAL.AppendToFamily(m.family, wal.OpTypePut, key, value)
 		if err != nil {
 			if err != wal.ErrWALRotating {
 				m.stats.TrackError("wal_append_error")
@@ -190,6 +281,55 @@ func (m *Manager) Put(key, value []byte) error {
 	return m.RetryOnWALRotating(operation)
 }
 
+// PutWithExpiry adds a key-value pair that expires at expireAt, in Unix
+// nanoseconds. The expiry time is stored in the WAL so that recovery and
+// replicas agree on it.
+func (m *Manager) PutWithExpiry(key, value []byte, expireAt uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed.Load() {
+		return ErrStorageClosed
+	}
+
+	// Define the operation with retry support
+	operation := func() error {
+		// Append to WAL with retry support using atomic access
+		currentWAL := m.getWAL()
+		if currentWAL == nil {
+			return ErrStorageClosed
+		}
+		seqNum, err := currentWAL.AppendToFamily(m.family, wal.OpTypePutWithTTL, key, ttl.Encode(expireAt, value))
+		if err != nil {
+			if err != wal.ErrWALRotating {
+				m.stats.TrackError("wal_append_error")
+				return fmt.Errorf("failed to append to WAL: %w", err)
+			}
+			return err // Return ErrWALRotating for retry handling
+		}
+
+		// Add to MemTable
+		m.memTablePool.PutWithExpiry(key, value, expireAt, seqNum)
+		m.lastSeqNum = seqNum
+
+		// Update memtable size estimate
+		m.stats.TrackMemTableSize(uint64(m.memTablePool.TotalSize()))
+
+		// Check if MemTable needs to be flushed
+		if m.memTablePool.IsFlushNeeded() {
+			if flushErr := m.scheduleFlush(); flushErr != nil {
+				m.stats.TrackError("flush_schedule_error")
+				return fmt.Errorf("failed to schedule flush: %w", flushErr)
+			}
+		}
+
+		return nil
+	}
+
+	// Execute with retry mechanism
+	return m.RetryOnWALRotating(operation)
+}
+
 // Get retrieves the value for the given key
 func (m *Manager) Get(key []byte) ([]byte, error) {
 	m.mu.RLock()
@@ -199,6 +339,16 @@ func (m *Manager) Get(key []byte) ([]byte, error) {
 		return nil, ErrStorageClosed
 	}
 
+	return m.get(key)
+}
+
+// get retrieves the value for the given key. The caller must hold the lock.
+func (m *Manager) get(key []byte) ([]byte, error) {
+	// Merge operands and range deletions have to be combined with older versions of the key
+	if tombstones := m.rangeTombstones(math.MaxUint64); m.mergeOperator != nil || len(tombstones) > 0 {
+		return m.getMerged(key, math.MaxUint64, tombstones)
+	}
+
 	// Check the MemTablePool (active + immutables)
 	if val, found := m.memTablePool.Get(key); found {
 		// The key was found, but check if it's a deletion marker
@@ -210,38 +360,122 @@ func (m *Manager) Get(key []byte) ([]byte, error) {
 	}
 
 	// Check the SSTables (searching from newest to oldest)
-	for i := len(m.sstables) - 1; i >= 0; i-- {
-		// Create a custom iterator to check for tombstones directly
-		iter := m.sstables[i].NewIterator()
+	iter := m.seekSSTables(key, math.MaxUint64)
+	if iter == nil {
+		return nil, ErrKeyNotFound
+	}
 
-		// Position at the target key
-		if !iter.Seek(key) {
-			// Key not found in this SSTable, continue to the next one
-			continue
+	// Check if this is a tombstone or an expired value
+	if iter.IsTombstone() || ttl.Expired(iter.ExpireAt(), time.Now()) {
+		// Found a tombstone, so this key is definitely deleted
+		return nil, ErrKeyNotFound
+	}
+
+	// Found a non-tombstone value for this key
+	return iter.Value(), nil
+}
+
+// Delete removes a key from the database
+func (m *Manager) Delete(key []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed.Load() {
+		return ErrStorageClosed
+	}
+
+	// Define the operation with retry support
+	operation := func() error {
+		// Append to WAL with retry support using atomic access
+		currentWAL := m.getWAL()
+		if currentWAL == nil {
+			return ErrStorageClosed
+		}
+		seqNum, err := currentWAL.AppendToFamily(m.family, wal.OpTypeDelete, key, nil)
+		if err != nil {
+			if err != wal.ErrWALRotating {
+				m.stats.TrackError("wal_append_error")
+				return fmt.Errorf("failed to append to WAL: %w", err)
+			}
+			return err // Return ErrWALRotating for retry handling
 		}
 
-		// If the keys don't match exactly, continue to the next SSTable
-		if !bytes.Equal(iter.Key(), key) {
-			continue
+		// Add deletion marker to MemTable
+		m.memTablePool.Delete(key, seqNum)
+		m.lastSeqNum = seqNum
+
+		// Update memtable size estimate
+		m.stats.TrackMemTableSize(uint64(m.memTablePool.TotalSize()))
+
+		// Check if MemTable needs to be flushed
+		if m.memTablePool.IsFlushNeeded() {
+			if flushErr := m.scheduleFlush(); flushErr != nil {
+				m.stats.TrackError("flush_schedule_error")
+				return fmt.Errorf("failed to schedule flush: %w", flushErr)
+			}
 		}
 
-		// If we reach here, we found the key in this SSTable
+		return nil
+	}
 
-		// Check if this is a tombstone
-		if iter.IsTombstone() {
-			// Found a tombstone, so this key is definitely deleted
-			return nil, ErrKeyNotFound
+	// Execute with retry mechanism
+	return m.RetryOnWALRotating(operation)
+}
+
+// DeleteRange removes every key in [start, end) from the database
+func (m *Manager) DeleteRange(start, end []byte) error {
+	if err := rangedel.Validate(start, end); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed.Load() {
+		return ErrStorageClosed
+	}
+
+	// Define the operation with retry support
+	operation := func() error {
+		// Append to WAL with retry support using atomic access
+		currentWAL := m.getWAL()
+		if currentWAL == nil {
+			return ErrStorageClosed
+		}
+		seqNum, err := currentWAL.AppendToFamily(m.family, wal.OpTypeDeleteRange, start, end)
+		if err != nil {
+			if err != wal.ErrWALRotating {
+				m.stats.TrackError("wal_append_error")
+				return fmt.Errorf("failed to append to WAL: %w", err)
+			}
+			return err // Return ErrWALRotating for retry handling
 		}
 
-		// Found a non-tombstone value for this key
-		return iter.Value(), nil
+		// Add range tombstone to MemTable
+		m.memTablePool.DeleteRange(start, end, seqNum)
+		m.lastSeqNum = seqNum
+
+		// Update memtable size estimate
+		m.stats.TrackMemTableSize(uint64(m.memTablePool.TotalSize()))
+
+		// Check if MemTable needs to be flushed
+		if m.memTablePool.IsFlushNeeded() {
+			if flushErr := m.scheduleFlush(); flushErr != nil {
+				m.stats.TrackError("flush_schedule_error")
+				return fmt.Errorf("failed to schedule flush: %w", flushErr)
+			}
+		}
+
+		return nil
 	}
 
-	return nil, ErrKeyNotFound
+	// Execute with retry mechanism
+	return m.RetryOnWALRotating(operation)
 }
 
-// Delete removes a key from the database
-func (m *Manager) Delete(key []byte) error {
+// Merge adds a merge operand for a key. The operand is combined with the
+// existing value by the merge operator when the key is read or compacted.
+func (m *Manager) Merge(key, operand []byte) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -249,6 +483,10 @@ func (m *Manager) Delete(key []byte) error {
 		return ErrStorageClosed
 	}
 
+	if m.mergeOperator == nil {
+		return merge.ErrNoOperator
+	}
+
 	// Define the operation with retry support
 	operation := func() error {
 		// Append to WAL with retry support using atomic access
@@ -256,7 +494,7 @@ func (m *Manager) Delete(key []byte) error {
 		if currentWAL == nil {
 			return ErrStorageClosed
 		}
-		seqNum, err := currentWAL.Append(wal.OpTypeDelete, key, nil)
+		seqNum, err := currentWAL.AppendToFamily(m.family, wal.OpTypeMerge, key, operand)
 		if err != nil {
 			if err != wal.ErrWALRotating {
 				m.stats.TrackError("wal_append_error")
@@ -265,8 +503,8 @@ func (m *Manager) Delete(key []byte) error {
 			return err // Return ErrWALRotating for retry handling
 		}
 
-		// Add deletion marker to MemTable
-		m.memTablePool.Delete(key, seqNum)
+		// Add merge operand to MemTable
+		m.memTablePool.Merge(key, operand, seqNum)
 		m.lastSeqNum = seqNum
 
 		// Update memtable size estimate
@@ -287,6 +525,26 @@ func (m *Manager) Delete(key []byte) error {
 	return m.RetryOnWALRotating(operation)
 }
 
+// SetMergeOperator sets the merge operator used to resolve merge operands
+func (m *Manager) SetMergeOperator(op merge.Operator) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.mergeOperator = op
+}
+
+// RateLimiter returns the limiter shared by the flushes and compactions of
+// all column families
+func (m *Manager) RateLimiter() *ratelimit.Limiter {
+	return m.rateLimiter
+}
+
+// VersionLog returns the log of the live SSTable files, which the compactions
+// of the SSTable directory commit to
+func (m *Manager) VersionLog() *versionlog.Log {
+	return m.versions
+}
+
 // IsDeleted returns true if the key exists and is marked as deleted
 func (m *Manager) IsDeleted(key []byte) (bool, error) {
 	m.mu.RLock()
@@ -296,36 +554,238 @@ func (m *Manager) IsDeleted(key []byte) (bool, error) {
 		return false, ErrStorageClosed
 	}
 
+	// A range deletion newer than every version of the key deletes it
+	if covering := rangedel.MaxCoveringSeq(m.rangeTombstones(math.MaxUint64), key, math.MaxUint64); covering > 0 {
+		if seqNum := m.latestSequence(key); seqNum > 0 && covering > seqNum {
+			return true, nil
+		}
+	}
+
 	// Check MemTablePool first
 	if val, found := m.memTablePool.Get(key); found {
 		// If value is nil, it's a deletion marker
 		return val == nil, nil
 	}
 
-	// Check SSTables in order from newest to oldest
-	for i := len(m.sstables) - 1; i >= 0; i-- {
-		iter := m.sstables[i].NewIterator()
+	// Check SSTables in order from newest to oldest
+	if iter := m.seekSSTables(key, math.MaxUint64); iter != nil {
+		// Found the key - check if it's a tombstone or has expired
+		return iter.IsTombstone() || ttl.Expired(iter.ExpireAt(), time.Now()), nil
+	}
+
+	// Key not found at all
+	return false, ErrKeyNotFound
+}
+
+// GetIterator returns an iterator over the entire keyspace
+func (m *Manager) GetIterator() (iterator.Iterator, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed.Load() {
+		return nil, ErrStorageClosed
+	}
+
+	// Get all memtables from the pool
+	memTables := m.memTablePool.GetMemTables()
+
+	// Create iterator using the factory
+	factory := engineIterator.NewFactoryWithMergeOperator(m.mergeOperator)
+	return factory.CreateIterator(memTables, m.sstables), nil
+}
+
+// GetRangeIterator returns an iterator limited to a specific key range
+func (m *Manager) GetRangeIterator(startKey, endKey []byte) (iterator.Iterator, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed.Load() {
+		return nil, ErrStorageClosed
+	}
+
+	// Get all memtables from the pool
+	memTables := m.memTablePool.GetMemTables()
+
+	// Create range-limited iterator using the factory
+	factory := engineIterator.NewFactoryWithMergeOperator(m.mergeOperator)
+	return factory.CreateRangeIterator(memTables, m.sstables, startKey, endKey), nil
+}
+
+// GetRangeIteratorWithOptions returns an iterator limited to a specific key
+// range that reads SSTable blocks as configured by options. Nil keys leave the
+// range unbounded on that side.
+func (m *Manager) GetRangeIteratorWithOptions(startKey, endKey []byte, options sstable.ReadOptions) (iterator.Iterator, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed.Load() {
+		return nil, ErrStorageClosed
+	}
+
+	// Get all memtables from the pool
+	memTables := m.memTablePool.GetMemTables()
+
+	factory := engineIterator.NewFactoryWithMergeOperator(m.mergeOperator)
+	factory.SetReadOptions(options)
+	return factory.CreateRangeIterator(memTables, m.sstables, startKey, endKey), nil
+}
+
+// AcquireSnapshot pins the current sequence number and returns it. Versions
+// visible at that sequence number are kept until the snapshot is released.
+func (m *Manager) AcquireSnapshot() (uint64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed.Load() {
+		return 0, ErrStorageClosed
+	}
+
+	seqNum := m.lastSeqNum
+	m.snapshots.Acquire(seqNum)
+	return seqNum, nil
+}
+
+// ReleaseSnapshot unpins a sequence number returned by AcquireSnapshot
+func (m *Manager) ReleaseSnapshot(seqNum uint64) {
+	m.snapshots.Release(seqNum)
+}
+
+// LiveSnapshots returns the sequence numbers of all open snapshots in ascending order
+func (m *Manager) LiveSnapshots() []uint64 {
+	return m.snapshots.LiveSnapshots()
+}
+
+// GetWithSnapshot retrieves the value for the given key as of a snapshot
+func (m *Manager) GetWithSnapshot(key []byte, seqNum uint64) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed.Load() {
+		return nil, ErrStorageClosed
+	}
+
+	// Merge operands and range deletions have to be combined with older versions of the key
+	if tombstones := m.rangeTombstones(seqNum); m.mergeOperator != nil || len(tombstones) > 0 {
+		return m.getMerged(key, seqNum, tombstones)
+	}
+
+	// Check the MemTablePool (active + immutables)
+	if val, found := m.memTablePool.GetWithSnapshot(key, seqNum); found {
+		if val == nil {
+			// Deleted as of the snapshot
+			return nil, ErrKeyNotFound
+		}
+		return val, nil
+	}
+
+	// Check the SSTables (searching from newest to oldest)
+	iter := m.seekSSTables(key, seqNum)
+	if iter == nil {
+		return nil, ErrKeyNotFound
+	}
+
+	if iter.IsTombstone() || ttl.Expired(iter.ExpireAt(), time.Now()) {
+		return nil, ErrKeyNotFound
+	}
+
+	return iter.Value(), nil
+}
+
+// versionIterator is an iterator over every version of every key
+type versionIterator interface {
+	Seek(target []byte) bool
+	Next() bool
+	Valid() bool
+	Key() []byte
+	Value() []byte
+	IsTombstone() bool
+	IsMergeOperand() bool
+	ExpireAt() uint64
+	SequenceNumber() uint64
+}
 
-		// Look for the key
-		if !iter.Seek(key) {
+// collectVersions appends the versions of a key visible at seqNum, newest first,
+// up to the first value or deletion. It reports whether such a version was found.
+func collectVersions(iter versionIterator, key []byte, seqNum uint64, versions []merge.Version) ([]merge.Version, bool) {
+	for iter.Seek(key); iter.Valid() && bytes.Equal(iter.Key(), key); iter.Next() {
+		if iter.SequenceNumber() > seqNum {
 			continue
 		}
 
-		// Check if it's an exact match
-		if !bytes.Equal(iter.Key(), key) {
-			continue
+		switch {
+		case iter.IsMergeOperand():
+			versions = append(versions, merge.Version{SeqNum: iter.SequenceNumber(), Kind: merge.KindOperand, Value: iter.Value()})
+		case iter.IsTombstone():
+			return append(versions, merge.Version{SeqNum: iter.SequenceNumber(), Kind: merge.KindDeletion}), true
+		default:
+			return append(versions, merge.Version{SeqNum: iter.SequenceNumber(), Kind: merge.KindValue, Value: iter.Value(), ExpireAt: iter.ExpireAt()}), true
 		}
+	}
+	return versions, false
+}
 
-		// Found the key - check if it's a tombstone
-		return iter.IsTombstone(), nil
+// getMerged retrieves the value of a key as of seqNum, applying any merge operands
+// to the value they were written on top of and hiding versions deleted by the
+// given range tombstones. The caller must hold the read lock.
+func (m *Manager) getMerged(key []byte, seqNum uint64, tombstones []rangedel.Tombstone) ([]byte, error) {
+	var versions []merge.Version
+	var found bool
+
+	for _, mem := range m.memTablePool.GetMemTables() {
+		var base bool
+		n := len(versions)
+		versions, base = collectVersions(memtable.NewIteratorAdapter(mem.NewIterator()), key, seqNum, versions)
+		versions = merge.DropDuplicates(versions, n)
+		found = found || base
 	}
 
-	// Key not found at all
-	return false, ErrKeyNotFound
+	// Versions in the MemTables are newer than anything on disk, and SSTables
+	// are searched from newest to oldest until one holds a value or deletion
+	for i := len(m.sstables) - 1; i >= 0 && !found; i-- {
+		if iter := m.seekSSTable(m.sstables[i], key); iter != nil {
+			n := len(versions)
+			versions, found = collectVersions(iter, key, seqNum, versions)
+			versions = merge.DropDuplicates(versions, n)
+		}
+	}
+
+	// Sources aren't necessarily ordered from newest to oldest
+	versions = merge.SortVersions(versions)
+
+	// Expired values read as deletions
+	versions = merge.Expire(versions, time.Now())
+
+	// A range deletion acts as a deletion of the key at its sequence number
+	if covering := rangedel.MaxCoveringSeq(tombstones, key, seqNum); covering > 0 {
+		versions = merge.InsertDeletion(versions, covering)
+	}
+
+	value, ok, err := merge.Resolve(m.mergeOperator, key, versions)
+	if err != nil {
+		m.stats.TrackError("merge_error")
+		return nil, fmt.Errorf("failed to merge operands for key: %w", err)
+	}
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return value, nil
 }
 
-// GetIterator returns an iterator over the entire keyspace
-func (m *Manager) GetIterator() (iterator.Iterator, error) {
+// rangeTombstones returns the range deletions visible at seqNum from the MemTables
+// and SSTables. The caller must hold the read lock.
+func (m *Manager) rangeTombstones(seqNum uint64) []rangedel.Tombstone {
+	var tombstones []rangedel.Tombstone
+	for _, mem := range m.memTablePool.GetMemTables() {
+		tombstones = append(tombstones, rangedel.Visible(mem.RangeTombstones(), seqNum)...)
+	}
+	for _, reader := range m.sstables {
+		tombstones = append(tombstones, rangedel.Visible(reader.RangeTombstones(), seqNum)...)
+	}
+	return tombstones
+}
+
+// GetIteratorWithSnapshot returns an iterator over the entire keyspace as of a snapshot
+func (m *Manager) GetIteratorWithSnapshot(seqNum uint64) (iterator.Iterator, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -333,16 +793,14 @@ func (m *Manager) GetIterator() (iterator.Iterator, error) {
 		return nil, ErrStorageClosed
 	}
 
-	// Get all memtables from the pool
 	memTables := m.memTablePool.GetMemTables()
 
-	// Create iterator using the factory
-	factory := engineIterator.NewFactory()
-	return factory.CreateIterator(memTables, m.sstables), nil
+	factory := engineIterator.NewFactoryWithMergeOperator(m.mergeOperator)
+	return factory.CreateSnapshotIterator(memTables, m.sstables, seqNum), nil
 }
 
-// GetRangeIterator returns an iterator limited to a specific key range
-func (m *Manager) GetRangeIterator(startKey, endKey []byte) (iterator.Iterator, error) {
+// GetRangeIteratorWithSnapshot returns an iterator limited to a key range as of a snapshot
+func (m *Manager) GetRangeIteratorWithSnapshot(startKey, endKey []byte, seqNum uint64) (iterator.Iterator, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -350,21 +808,222 @@ func (m *Manager) GetRangeIterator(startKey, endKey []byte) (iterator.Iterator,
 		return nil, ErrStorageClosed
 	}
 
-	// Get all memtables from the pool
 	memTables := m.memTablePool.GetMemTables()
 
-	// Create range-limited iterator using the factory
-	factory := engineIterator.NewFactory()
-	return factory.CreateRangeIterator(memTables, m.sstables, startKey, endKey), nil
+	factory := engineIterator.NewFactoryWithMergeOperator(m.mergeOperator)
+	return factory.CreateSnapshotRangeIterator(memTables, m.sstables, startKey, endKey, seqNum), nil
+}
+
+// latestWrite returns the sequence number of the newest write to a key,
+// including deletion markers and range deletions covering it. Returns 0 if the
+// key has never been written. The caller must hold the read lock.
+func (m *Manager) latestWrite(key []byte) uint64 {
+	seqNum := m.latestSequence(key)
+
+	// Range deletions covering the key count as writes to it
+	if covering := rangedel.MaxCoveringSeq(m.rangeTombstones(math.MaxUint64), key, math.MaxUint64); covering > seqNum {
+		seqNum = covering
+	}
+
+	return seqNum
+}
+
+// latestSequence returns the sequence number of the newest version of a key
+// stored in the MemTables or SSTables. The caller must hold the read lock.
+func (m *Manager) latestSequence(key []byte) uint64 {
+	// Versions in the MemTables are newer than anything on disk
+	if seqNum, found := m.memTablePool.GetSequence(key); found {
+		return seqNum
+	}
+
+	// Check the SSTables (searching from newest to oldest)
+	if iter := m.seekSSTables(key, math.MaxUint64); iter != nil {
+		// Versions of a key are stored newest first
+		return iter.SequenceNumber()
+	}
+
+	return 0
+}
+
+// writtenInRangeAfter returns whether any key in [startKey, endKey) was
+// written after seqNum, including deletion markers and range deletions. Nil
+// bounds are unbounded. The caller must hold the read lock.
+func (m *Manager) writtenInRangeAfter(startKey, endKey []byte, seqNum uint64) bool {
+	// Range deletions overlapping the range count as writes to it
+	for _, t := range m.rangeTombstones(math.MaxUint64) {
+		if t.SeqNum > seqNum && t.Overlaps(startKey, endKey) {
+			return true
+		}
+	}
+
+	// Only MemTables written to after seqNum are searched
+	for _, mem := range m.memTablePool.GetMemTables() {
+		if mem.GetNextSequenceNumber() <= seqNum {
+			continue
+		}
+		if writtenAfter(memtable.NewIteratorAdapter(mem.NewIterator()), startKey, endKey, seqNum) {
+			return true
+		}
+	}
+
+	// Only SSTables overlapping the range and holding writes newer than
+	// seqNum are searched
+	for _, reader := range m.sstables {
+		smallest, largest := reader.KeyRange()
+		if smallest == nil || (startKey != nil && bytes.Compare(largest, startKey) < 0) ||
+			(endKey != nil && bytes.Compare(smallest, endKey) >= 0) {
+			continue
+		}
+		if file, ok := m.versions.File(filepath.Base(reader.FilePath())); ok &&
+			file.LargestSequence != 0 && file.LargestSequence <= seqNum {
+			continue
+		}
+		if writtenAfter(sstable.NewIteratorAdapter(reader.NewIterator()), startKey, endKey, seqNum) {
+			return true
+		}
+	}
+
+	return false
 }
 
-// ApplyBatch atomically applies a batch of operations
+// writtenAfter returns whether iter has a version of a key in [startKey,
+// endKey) newer than seqNum. A nil start key sorts before every key.
+func writtenAfter(iter versionIterator, startKey, endKey []byte, seqNum uint64) bool {
+	for iter.Seek(startKey); iter.Valid(); iter.Next() {
+		if endKey != nil && bytes.Compare(iter.Key(), endKey) >= 0 {
+			return false
+		}
+		if iter.SequenceNumber() > seqNum {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyBatch atomically applies a batch of operations. On the default family
+// each entry goes to the column family named by its Family field, so a batch
+// can span column families. On any other column family every entry of the
+// batch is applied to that family.
 func (m *Manager) ApplyBatch(entries []*wal.Entry) error {
+	_, err := m.ApplyBatchIf(entries, nil)
+	return err
+}
+
+// ApplyBatchIf atomically applies a batch of operations if all of the
+// preconditions hold. The preconditions are checked under the same locks as
+// the batch is applied, so no other write can come in between. Column
+// families are chosen as in ApplyBatch.
+func (m *Manager) ApplyBatchIf(entries []*wal.Entry, conditions []interfaces.Precondition) (interfaces.ConditionResult, error) {
+	if m.walOwner != nil {
+		tagged := make([]*wal.Entry, len(entries))
+		for i, entry := range entries {
+			copied := *entry
+			copied.Family = m.family
+			tagged[i] = &copied
+		}
+		taggedConditions := make([]interfaces.Precondition, len(conditions))
+		for i, c := range conditions {
+			c.Family = m.family
+			taggedConditions[i] = c
+		}
+		return m.walOwner.ApplyBatchIf(tagged, taggedConditions)
+	}
+
+	result := interfaces.ConditionResult{Failed: -1}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	if m.closed.Load() {
-		return ErrStorageClosed
+		return result, ErrStorageClosed
+	}
+
+	// Find the column families used by the batch and lock them. The lock of
+	// the default family serializes batches, so the order doesn't matter.
+	targets := make(map[uint32]*Manager)
+	lockFamily := func(family uint32) error {
+		if _, ok := targets[family]; ok {
+			return nil
+		}
+		if family == m.family {
+			targets[family] = m
+			return nil
+		}
+		cf, ok := m.families[family]
+		if !ok {
+			return fmt.Errorf("%w: %d", ErrUnknownColumnFamily, family)
+		}
+		cf.mu.Lock()
+		targets[family] = cf
+		if cf.closed.Load() {
+			return ErrStorageClosed
+		}
+		return nil
+	}
+	defer func() {
+		for family, target := range targets {
+			if family != m.family {
+				target.mu.Unlock()
+			}
+		}
+	}()
+	for _, entry := range entries {
+		if err := lockFamily(entry.Family); err != nil {
+			return result, err
+		}
+	}
+	for _, c := range conditions {
+		if err := lockFamily(c.Family); err != nil {
+			return result, err
+		}
+	}
+
+	for _, entry := range entries {
+		switch entry.Type {
+		case wal.OpTypeMerge:
+			if targets[entry.Family].mergeOperator == nil {
+				return result, merge.ErrNoOperator
+			}
+		case wal.OpTypeDeleteRange:
+			if err := rangedel.Validate(entry.Key, entry.Value); err != nil {
+				return result, err
+			}
+		case wal.OpTypePutWithTTL:
+			if _, _, err := ttl.Decode(entry.Value); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	// Check the preconditions against the current state
+	for i, c := range conditions {
+		target := targets[c.Family]
+		switch c.Type {
+		case interfaces.PreconditionUnchanged:
+			if target.latestWrite(c.Key) > c.Sequence {
+				result.Failed = i
+				return result, nil
+			}
+			continue
+		case interfaces.PreconditionRangeUnchanged:
+			if target.writtenInRangeAfter(c.Key, c.End, c.Sequence) {
+				result.Failed = i
+				return result, nil
+			}
+			continue
+		}
+
+		value, err := target.get(c.Key)
+		if err != nil && err != ErrKeyNotFound {
+			return result, err
+		}
+		found := err == nil
+		if !c.Holds(value, found) {
+			result.Failed = i
+			result.Value = value
+			result.Found = found
+			return result, nil
+		}
 	}
 
 	// Define the operation with retry support
@@ -383,28 +1042,38 @@ func (m *Manager) ApplyBatch(entries []*wal.Entry) error {
 			return err // Return ErrWALRotating for retry handling
 		}
 
-		// Apply each entry to the MemTable
-		for i, entry := range entries {
-			seqNum := startSeqNum + uint64(i)
-
+		// Apply each entry to the MemTable of its column family. Like in the
+		// WAL, every entry of the batch shares its sequence number; the
+		// MemTable orders writes to the same key by when they were applied.
+		for _, entry := range entries {
+			target := targets[entry.Family]
 			switch entry.Type {
 			case wal.OpTypePut:
-				m.memTablePool.Put(entry.Key, entry.Value, seqNum)
+				target.memTablePool.Put(entry.Key, entry.Value, startSeqNum)
 			case wal.OpTypeDelete:
-				m.memTablePool.Delete(entry.Key, seqNum)
+				target.memTablePool.Delete(entry.Key, startSeqNum)
+			case wal.OpTypeMerge:
+				target.memTablePool.Merge(entry.Key, entry.Value, startSeqNum)
+			case wal.OpTypeDeleteRange:
+				target.memTablePool.DeleteRange(entry.Key, entry.Value, startSeqNum)
+			case wal.OpTypePutWithTTL:
+				expireAt, value, _ := ttl.Decode(entry.Value)
+				target.memTablePool.PutWithExpiry(entry.Key, value, expireAt, startSeqNum)
 			}
-
-			m.lastSeqNum = seqNum
 		}
 
-		// Update memtable size
-		m.stats.TrackMemTableSize(uint64(m.memTablePool.TotalSize()))
+		for _, target := range targets {
+			target.lastSeqNum = startSeqNum
 
-		// Check if MemTable needs to be flushed
-		if m.memTablePool.IsFlushNeeded() {
-			if flushErr := m.scheduleFlush(); flushErr != nil {
-				m.stats.TrackError("flush_schedule_error")
-				return fmt.Errorf("failed to schedule flush: %w", flushErr)
+			// Update memtable size
+			m.stats.TrackMemTableSize(uint64(target.memTablePool.TotalSize()))
+
+			// Check if MemTable needs to be flushed
+			if target.memTablePool.IsFlushNeeded() {
+				if flushErr := target.scheduleFlush(); flushErr != nil {
+					m.stats.TrackError("flush_schedule_error")
+					return fmt.Errorf("failed to schedule flush: %w", flushErr)
+				}
 			}
 		}
 
@@ -412,7 +1081,12 @@ func (m *Manager) ApplyBatch(entries []*wal.Entry) error {
 	}
 
 	// Execute with retry mechanism
-	return m.RetryOnWALRotating(operation)
+	if err := m.RetryOnWALRotating(operation); err != nil {
+		return result, err
+	}
+
+	result.Applied = true
+	return result, nil
 }
 
 // FlushMemTables flushes all immutable MemTables to disk
@@ -490,44 +1164,57 @@ func (m *Manager) GetSSTables() []string {
 	return sstables
 }
 
-// ReloadSSTables reloads all SSTables from disk
+// ReloadSSTables reloads the live SSTables recorded in the version log
 func (m *Manager) ReloadSSTables() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Close existing SSTable readers
-	for _, reader := range m.sstables {
-		if err := reader.Close(); err != nil {
-			return fmt.Errorf("failed to close SSTable reader: %w", err)
-		}
-	}
+	return m.reloadSSTables()
+}
 
-	// Clear the list
-	m.sstables = m.sstables[:0]
+// reloadSSTables reloads the live SSTables. The caller must hold the lock.
+func (m *Manager) reloadSSTables() error {
+	files := m.versions.Current().Files()
 
-	// Find all SSTable files
-	entries, err := os.ReadDir(m.sstableDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil // Directory doesn't exist yet
-		}
-		return fmt.Errorf("failed to read SSTable directory: %w", err)
+	// Keep the readers of files that are still there, so their cached blocks
+	// stay in the block cache
+	existing := make(map[string]*sstable.Reader, len(m.sstables))
+	for _, reader := range m.sstables {
+		existing[reader.FilePath()] = reader
 	}
 
-	// Open all SSTable files
-	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".sst" {
-			continue // Skip directories and non-SSTable files
+	sstables := make([]*sstable.Reader, 0, len(files))
+	var opened []*sstable.Reader
+	for _, file := range files {
+		path := filepath.Join(m.sstableDir, file.Name)
+		if reader, ok := existing[path]; ok {
+			delete(existing, path)
+			sstables = append(sstables, reader)
+			continue
 		}
 
-		path := filepath.Join(m.sstableDir, entry.Name())
-		reader, err := sstable.OpenReader(path)
+		reader, err := sstable.OpenReaderWithOptions(path, m.readerOptions(file.GlobalSequence))
 		if err != nil {
+			for _, reader := range opened {
+				reader.Close()
+			}
 			return fmt.Errorf("failed to open SSTable %s: %w", path, err)
 		}
 
-		m.sstables = append(m.sstables, reader)
+		opened = append(opened, reader)
+		sstables = append(sstables, reader)
+	}
+
+	// Close the readers of removed files
+	for _, reader := range existing {
+		if err := reader.Close(); err != nil {
+			return fmt.Errorf("failed to close SSTable reader: %w", err)
+		}
 	}
+	m.sstables = sstables
+
+	// Lookups search from the end of the list
+	sortSSTables(m.sstables)
 
 	return nil
 }
@@ -542,6 +1229,11 @@ func (m *Manager) RotateWAL() error {
 
 // rotateWAL is the internal implementation of RotateWAL
 func (m *Manager) rotateWAL() error {
+	// A shared WAL is only rotated by its owner
+	if m.walOwner != nil {
+		return nil
+	}
+
 	// Signal rotation start
 	m.rotating.Store(true)
 
@@ -561,6 +1253,11 @@ func (m *Manager) rotateWAL() error {
 		return fmt.Errorf("failed to create new WAL: %w", err)
 	}
 
+	// Sequence numbers continue across WAL files so versions stay ordered
+	if currentWAL != nil {
+		newWAL.UpdateNextSequence(currentWAL.GetNextSequence())
+	}
+
 	// Store the old WAL for proper closure
 	oldWAL := m.wal
 
@@ -587,6 +1284,9 @@ func (m *Manager) isRotating() bool {
 
 // getWAL returns the current WAL using atomic operations
 func (m *Manager) getWAL() *wal.WAL {
+	if m.walOwner != nil {
+		return m.walOwner.getWAL()
+	}
 	return (*wal.WAL)(atomic.LoadPointer((*unsafe.Pointer)(unsafe.Pointer(&m.wal))))
 }
 
@@ -602,9 +1302,26 @@ func (m *Manager) GetStorageStats() map[string]interface{} {
 	stats["sstable_count"] = len(m.sstables)
 	stats["last_sequence"] = m.lastSeqNum
 
+	cacheStats := m.blockCache.Stats()
+	stats["block_cache_capacity"] = cacheStats.Capacity
+	stats["block_cache_usage"] = cacheStats.Usage
+	stats["block_cache_entries"] = cacheStats.Entries
+	stats["block_cache_hits"] = cacheStats.Hits
+	stats["block_cache_misses"] = cacheStats.Misses
+	stats["block_cache_evictions"] = cacheStats.Evictions
+
+	stats["rate_limit_bytes_per_sec"] = m.rateLimiter.Rate()
+	stats["rate_limit_effective_bytes_per_sec"] = m.rateLimiter.EffectiveRate()
+
 	return stats
 }
 
+// readerOptions returns the options SSTables are opened with, given the
+// global sequence number the version log records for the file
+func (m *Manager) readerOptions(globalSeq uint64) sstable.ReaderOptions {
+	return sstable.ReaderOptions{BlockCache: m.blockCache, GlobalSequence: globalSeq}
+}
+
 // Close closes the storage manager
 func (m *Manager) Close() error {
 	// First set the closed flag - use atomic operation to prevent race conditions
@@ -612,11 +1329,13 @@ func (m *Manager) Close() error {
 		return nil // Already closed
 	}
 
-	// Close the WAL using atomic access
-	currentWAL := m.getWAL()
-	if currentWAL != nil {
-		if err := currentWAL.Close(); err != nil {
-			return fmt.Errorf("failed to close WAL: %w", err)
+	// Close the WAL using atomic access, a shared WAL is closed by its owner
+	if m.walOwner == nil {
+		currentWAL := m.getWAL()
+		if currentWAL != nil {
+			if err := currentWAL.Close(); err != nil {
+				return fmt.Errorf("failed to close WAL: %w", err)
+			}
 		}
 	}
 
@@ -627,6 +1346,10 @@ func (m *Manager) Close() error {
 		}
 	}
 
+	if err := m.versions.Close(); err != nil {
+		return fmt.Errorf("failed to close version log: %w", err)
+	}
+
 	return nil
 }
 
@@ -669,7 +1392,11 @@ func (m *Manager) flushMemTable(mem *memtable.MemTable) error {
 	sstPath := filepath.Join(m.sstableDir, filename)
 
 	// Create a new SSTable writer
-	writer, err := sstable.NewWriter(sstPath)
+	// Flushes free up memory for writes, so they go ahead of compactions
+	options := sstable.WriterOptionsFromConfig(m.cfg)
+	options.RateLimiter = m.rateLimiter
+	options.IOPriority = ratelimit.High
+	writer, err := sstable.NewWriterWithOptions(sstPath, options)
 	if err != nil {
 		return fmt.Errorf("failed to create SSTable writer: %w", err)
 	}
@@ -679,78 +1406,87 @@ func (m *Manager) flushMemTable(mem *memtable.MemTable) error {
 	count := 0
 	var bytesWritten uint64
 
-	// Structure to store information about keys as we iterate
-	type keyEntry struct {
-		key    []byte
-		value  []byte
-		seqNum uint64
+	// Older versions of a key are only written if an open snapshot can still see them
+	liveSnapshots := m.snapshots.LiveSnapshots()
+
+	// Versions of the current key, newest first
+	var key []byte
+	var versions []merge.Version
+
+	// Highest sequence number in the MemTable
+	var lastSeq uint64
+
+	// writeKey writes the versions of the current key that reads can still observe
+	writeKey := func() error {
+		for _, v := range merge.Collapse(m.mergeOperator, key, versions, liveSnapshots) {
+			// Tombstones have no value data (marker is serialization overhead)
+			bytesWritten += uint64(len(key) + len(v.Value))
+
+			var err error
+			switch v.Kind {
+			case merge.KindOperand:
+				err = writer.AddMergeWithSequence(key, v.Value, v.SeqNum)
+			case merge.KindDeletion:
+				// Tombstones must be preserved in Level 0 SSTables for correct deletion semantics
+				err = writer.AddWithSequence(key, nil, v.SeqNum)
+			default:
+				if v.ExpireAt != 0 {
+					err = writer.AddExpiringWithSequence(key, v.Value, v.SeqNum, v.ExpireAt)
+				} else {
+					err = writer.AddWithSequence(key, v.Value, v.SeqNum)
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("failed to add entry with sequence number to SSTable: %w", err)
+			}
+			count++
+		}
+		return nil
 	}
 
-	// Collect keys in sorted order while tracking the newest version of each key
-	var entries []keyEntry
-	var previousKey []byte
-
-	// First iterate through memtable (already in sorted order)
+	// Iterate through the memtable (already in sorted order, newest version first)
 	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
 		currentKey := iter.Key()
-		currentValue := iter.Value()
-		currentSeqNum := iter.SequenceNumber()
-
-		// Handle both regular values and tombstones (deletion markers)
-		// Tombstones are represented by nil values and must be preserved
-		// in Level 0 SSTables for correct deletion semantics
-
-		// If this is the first key or a different key than the previous one
-		if previousKey == nil || !bytes.Equal(currentKey, previousKey) {
-			// Add this as a new entry (includes tombstones)
-			var valueCopy []byte
-			if currentValue != nil {
-				valueCopy = append([]byte(nil), currentValue...)
+		if key != nil && !bytes.Equal(currentKey, key) {
+			if err := writeKey(); err != nil {
+				writer.Abort()
+				return err
 			}
-			// Note: valueCopy remains nil for tombstones
-
-			entries = append(entries, keyEntry{
-				key:    append([]byte(nil), currentKey...),
-				value:  valueCopy,
-				seqNum: currentSeqNum,
-			})
-			previousKey = currentKey
-		} else {
-			// Same key as previous, check sequence number
-			lastIndex := len(entries) - 1
-			if currentSeqNum > entries[lastIndex].seqNum {
-				// This is a newer version of the same key, replace the previous entry
-				var valueCopy []byte
-				if currentValue != nil {
-					valueCopy = append([]byte(nil), currentValue...)
-				}
-				// Note: valueCopy remains nil for tombstones
+			versions = versions[:0]
+		}
 
-				entries[lastIndex] = keyEntry{
-					key:    append([]byte(nil), currentKey...),
-					value:  valueCopy,
-					seqNum: currentSeqNum,
-				}
-			}
+		kind := merge.KindValue
+		if iter.IsTombstone() {
+			kind = merge.KindDeletion
+		} else if iter.IsMergeOperand() {
+			kind = merge.KindOperand
 		}
+
+		key = currentKey
+		lastSeq = max(lastSeq, iter.SequenceNumber())
+		versions = append(versions, merge.Version{
+			SeqNum:   iter.SequenceNumber(),
+			Kind:     kind,
+			Value:    iter.Value(),
+			ExpireAt: iter.ExpireAt(),
+		})
 	}
 
-	// Now write all collected entries to the SSTable
-	for _, entry := range entries {
-		// Calculate bytes written (data payload: keys + values)
-		// Note: This tracks data content, not serialization overhead
-		valueLen := uint64(0)
-		if entry.value != nil {
-			valueLen = uint64(len(entry.value))
+	if key != nil {
+		if err := writeKey(); err != nil {
+			writer.Abort()
+			return err
 		}
-		// Tombstones have no value data (marker is serialization overhead)
-		bytesWritten += uint64(len(entry.key)) + valueLen
+	}
 
-		// Write entry to SSTable - AddWithSequence handles both regular values and tombstones
-		if err := writer.AddWithSequence(entry.key, entry.value, entry.seqNum); err != nil {
+	// Range deletions are written to the SSTable as they are
+	for _, t := range mem.RangeTombstones() {
+		if err := writer.AddRangeTombstone(t.Start, t.End, t.SeqNum); err != nil {
 			writer.Abort()
-			return fmt.Errorf("failed to add entry with sequence number to SSTable: %w", err)
+			return fmt.Errorf("failed to add range tombstone to SSTable: %w", err)
 		}
+		lastSeq = max(lastSeq, t.SeqNum)
+		bytesWritten += uint64(len(t.Start) + len(t.End))
 		count++
 	}
 
@@ -773,11 +1509,19 @@ func (m *Manager) flushMemTable(mem *memtable.MemTable) error {
 	}
 
 	// Open the new SSTable for reading
-	reader, err := sstable.OpenReader(sstPath)
+	reader, err := sstable.OpenReaderWithOptions(sstPath, m.readerOptions(0))
 	if err != nil {
 		return fmt.Errorf("failed to open SSTable: %w", err)
 	}
 
+	// Commit the SSTable to the version log, until then it's left over from
+	// an unfinished flush and recovery deletes it
+	if err := m.commitFlush(reader, fileNum, lastSeq); err != nil {
+		reader.Close()
+		os.Remove(sstPath)
+		return err
+	}
+
 	// Add the SSTable to the list
 	m.mu.Lock()
 	m.sstables = append(m.sstables, reader)
@@ -786,6 +1530,29 @@ func (m *Manager) flushMemTable(mem *memtable.MemTable) error {
 	return nil
 }
 
+// commitFlush records a flushed SSTable in the version log
+func (m *Manager) commitFlush(reader *sstable.Reader, fileNum, lastSeq uint64) error {
+	file, err := versionlog.Describe(reader)
+	if err != nil {
+		return err
+	}
+	file.LargestSequence = lastSeq
+
+	edit := versionlog.Edit{
+		Added:          []versionlog.FileMeta{file},
+		LastSequence:   lastSeq,
+		NextFileNumber: fileNum + 1,
+	}
+	if currentWAL := m.getWAL(); currentWAL != nil {
+		edit.LogNumber = currentWAL.LogNumber()
+	}
+
+	if err := m.versions.Apply(edit); err != nil {
+		return fmt.Errorf("failed to commit flushed SSTable: %w", err)
+	}
+	return nil
+}
+
 // backgroundFlush runs in a goroutine and periodically flushes immutable MemTables
 func (m *Manager) backgroundFlush() {
 	ticker := time.NewTicker(10 * time.Second)
@@ -817,32 +1584,137 @@ func (m *Manager) backgroundFlush() {
 	}
 }
 
-// loadSSTables loads existing SSTable files from disk
+// loadSSTables opens the version log and the live SSTable files it records.
+// The files of a directory without a version log are adopted as they are.
 func (m *Manager) loadSSTables() error {
-	// Get all SSTable files in the directory
-	entries, err := os.ReadDir(m.sstableDir)
+	existed := versionlog.Exists(m.sstableDir)
+	versions, err := versionlog.Open(m.sstableDir)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil // Directory doesn't exist yet
+		return err
+	}
+	m.versions = versions
+
+	if err := m.openVersion(existed); err != nil {
+		for _, reader := range m.sstables {
+			reader.Close()
+		}
+		m.sstables = m.sstables[:0]
+		versions.Close()
+		return err
+	}
+
+	// Lookups search from the end of the list
+	sortSSTables(m.sstables)
+
+	return nil
+}
+
+// openVersion opens the SSTable files of the live version, after adopting
+// the files of the directory if it had no version log
+func (m *Manager) openVersion(existed bool) error {
+	if !existed {
+		if err := m.adoptSSTables(); err != nil {
+			return err
+		}
+	}
+
+	version := m.versions.Current()
+	if err := m.removeOrphans(version); err != nil {
+		return err
+	}
+
+	lastSeq := version.LastSequence
+	for _, file := range version.Files() {
+		path := filepath.Join(m.sstableDir, file.Name)
+		reader, err := sstable.OpenReaderWithOptions(path, m.readerOptions(file.GlobalSequence))
+		if err != nil {
+			return fmt.Errorf("failed to open SSTable %s: %w", path, err)
+		}
+		m.sstables = append(m.sstables, reader)
+		lastSeq = max(lastSeq, file.GlobalSequence)
+	}
+
+	// File and sequence numbers carry on from the last flush or ingestion,
+	// whether or not the WAL still has its entries
+	m.nextFileNum = max(m.nextFileNum, version.NextFileNumber)
+	m.lastSeqNum = lastSeq
+	if lastSeq > 0 {
+		if currentWAL := m.getWAL(); currentWAL != nil {
+			currentWAL.UpdateNextSequence(lastSeq + 1)
 		}
+	}
+
+	return nil
+}
+
+// adoptSSTables records the SSTable files found in the directory as the live
+// version, for directories written before the version log existed
+func (m *Manager) adoptSSTables() error {
+	entries, err := os.ReadDir(m.sstableDir)
+	if err != nil {
 		return fmt.Errorf("failed to read SSTable directory: %w", err)
 	}
 
-	// Loop through all entries
+	var edit versionlog.Edit
 	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".sst" {
+		if entry.IsDir() || !isSSTableFile(entry.Name()) {
 			continue // Skip directories and non-SSTable files
 		}
 
-		// Open the SSTable
 		path := filepath.Join(m.sstableDir, entry.Name())
-		reader, err := sstable.OpenReader(path)
+		reader, err := sstable.OpenReaderWithOptions(path, m.readerOptions(0))
 		if err != nil {
 			return fmt.Errorf("failed to open SSTable %s: %w", path, err)
 		}
+		file, err := versionlog.Describe(reader)
+		reader.Close()
+		if err != nil {
+			return err
+		}
 
-		// Add to the list
-		m.sstables = append(m.sstables, reader)
+		// Flushes are numbered after the files already in level 0
+		edit.Added = append(edit.Added, file)
+		if file.Level == 0 {
+			edit.NextFileNumber = max(edit.NextFileNumber, file.Sequence+1)
+		}
+	}
+
+	if err := m.versions.Apply(edit); err != nil {
+		return fmt.Errorf("failed to adopt SSTables: %w", err)
+	}
+	return nil
+}
+
+// isSSTableFile returns whether name is the name of an SSTable file
+func isSSTableFile(name string) bool {
+	_, _, _, ok := versionlog.ParseFileName(name)
+	return ok && filepath.Ext(name) == ".sst"
+}
+
+// removeOrphans deletes the SSTable files that aren't part of the version,
+// which a flush or compaction wrote but didn't commit before a crash, or
+// a compaction committed but didn't get to delete
+func (m *Manager) removeOrphans(version *versionlog.Version) error {
+	entries, err := os.ReadDir(m.sstableDir)
+	if err != nil {
+		return fmt.Errorf("failed to read SSTable directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || version.Contains(name) {
+			continue
+		}
+
+		// Files being written are renamed into place when finished
+		isTemp := strings.HasPrefix(name, ".") && strings.HasSuffix(name, ".sst.tmp")
+		if !isSSTableFile(name) && !isTemp {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(m.sstableDir, name)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove orphaned SSTable %s: %w", name, err)
+		}
 	}
 
 	return nil
@@ -868,6 +1740,7 @@ func (m *Manager) recoverFromWAL() error {
 
 	// Get recovery options
 	recoveryOpts := memtable.DefaultRecoveryOptions(m.cfg)
+	recoveryOpts.Family = m.family
 
 	// Recover memtables from WAL
 	memTables, maxSeqNum, err := memtable.RecoverFromWAL(m.cfg, recoveryOpts)
@@ -875,6 +1748,11 @@ func (m *Manager) recoverFromWAL() error {
 		// If recovery fails, let's try cleaning up WAL files
 		m.stats.TrackError("wal_recovery_error")
 
+		// The WAL of a column family belongs to the default family
+		if m.walOwner != nil {
+			return fmt.Errorf("failed to recover column family from WAL: %w", err)
+		}
+
 		// Create a backup directory
 		backupDir := filepath.Join(m.walDir, "backup_"+time.Now().Format("20060102_150405"))
 		if err := os.MkdirAll(backupDir, 0755); err != nil {
@@ -922,7 +1800,7 @@ func (m *Manager) recoverFromWAL() error {
 	}
 
 	// Update sequence numbers
-	m.lastSeqNum = maxSeqNum
+	m.lastSeqNum = max(m.lastSeqNum, maxSeqNum)
 
 	// Update WAL sequence number to continue from where we left off
 	if maxSeqNum > 0 {
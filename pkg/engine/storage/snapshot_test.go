@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KevoDB/kevo/pkg/config"
+	"github.com/KevoDB/kevo/pkg/sstable"
+	"github.com/KevoDB/kevo/pkg/stats"
+)
+
+// TestFlushKeepsSnapshotVersions tests that flushing a memtable keeps older
+// versions of a key that are still visible to an open snapshot.
+func TestFlushKeepsSnapshotVersions(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "snapshot-flush-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	cfg := &config.Config{
+		Version:         config.CurrentManifestVersion,
+		SSTDir:          filepath.Join(tempDir, "sst"),
+		WALDir:          filepath.Join(tempDir, "wal"),
+		MemTableSize:    1024 * 1024, // 1MB
+		MemTablePoolCap: 2,
+		MaxMemTables:    2,
+	}
+
+	manager, err := NewManager(cfg, stats.NewAtomicCollector())
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+	defer manager.Close()
+
+	// Two versions of "pinned", with a snapshot in between
+	if err := manager.Put([]byte("pinned"), []byte("v1")); err != nil {
+		t.Fatalf("Failed to put: %v", err)
+	}
+	seqNum, err := manager.AcquireSnapshot()
+	if err != nil {
+		t.Fatalf("Failed to acquire snapshot: %v", err)
+	}
+	if err := manager.Put([]byte("pinned"), []byte("v2")); err != nil {
+		t.Fatalf("Failed to put: %v", err)
+	}
+
+	// Two versions of "unpinned" with no snapshot in between
+	if err := manager.Put([]byte("unpinned"), []byte("v1")); err != nil {
+		t.Fatalf("Failed to put: %v", err)
+	}
+	if err := manager.Put([]byte("unpinned"), []byte("v2")); err != nil {
+		t.Fatalf("Failed to put: %v", err)
+	}
+
+	if err := manager.FlushMemTables(); err != nil {
+		t.Fatalf("Failed to flush memtables: %v", err)
+	}
+
+	sstables := manager.GetSSTables()
+	if len(sstables) != 1 {
+		t.Fatalf("Expected 1 SSTable, got %d", len(sstables))
+	}
+
+	reader, err := sstable.OpenReader(sstables[0])
+	if err != nil {
+		t.Fatalf("Failed to open SSTable: %v", err)
+	}
+	defer reader.Close()
+
+	versions := make(map[string][]string)
+	iter := reader.NewIterator()
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		versions[string(iter.Key())] = append(versions[string(iter.Key())], string(iter.Value()))
+	}
+
+	if got := versions["pinned"]; len(got) != 2 || got[0] != "v2" || got[1] != "v1" {
+		t.Errorf("Expected pinned versions [v2 v1], got %v", got)
+	}
+	if got := versions["unpinned"]; len(got) != 1 || got[0] != "v2" {
+		t.Errorf("Expected unpinned versions [v2], got %v", got)
+	}
+
+	// The snapshot still reads the old version
+	value, err := manager.GetWithSnapshot([]byte("pinned"), seqNum)
+	if err != nil {
+		t.Fatalf("Failed to get with snapshot: %v", err)
+	}
+	if !bytes.Equal(value, []byte("v1")) {
+		t.Errorf("Expected snapshot value v1, got %s", value)
+	}
+
+	manager.ReleaseSnapshot(seqNum)
+	if live := manager.LiveSnapshots(); len(live) != 0 {
+		t.Errorf("Expected no live snapshots after release, got %v", live)
+	}
+}
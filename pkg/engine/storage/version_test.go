@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/KevoDB/kevo/pkg/config"
+	"github.com/KevoDB/kevo/pkg/sstable"
+	"github.com/KevoDB/kevo/pkg/stats"
+	"github.com/KevoDB/kevo/pkg/versionlog"
+)
+
+// TestVersionLogRecovery tests that reopening a manager restores the files of
+// the version log and deletes those left over from unfinished work
+func TestVersionLogRecovery(t *testing.T) {
+	tempDir := t.TempDir()
+	sstDir := filepath.Join(tempDir, "sst")
+	cfg := &config.Config{
+		Version:         config.CurrentManifestVersion,
+		SSTDir:          sstDir,
+		WALDir:          filepath.Join(tempDir, "wal"),
+		MemTableSize:    1024 * 1024,
+		MemTablePoolCap: 2,
+		MaxMemTables:    2,
+	}
+
+	// Flush two SSTables
+	manager, err := NewManager(cfg, stats.NewAtomicCollector())
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+	for _, key := range []string{"key1", "key2"} {
+		if err := manager.Put([]byte(key), []byte("value-"+key)); err != nil {
+			t.Fatalf("Failed to put %s: %v", key, err)
+		}
+		if err := manager.FlushMemTables(); err != nil {
+			t.Fatalf("Failed to flush: %v", err)
+		}
+	}
+	flushed := manager.GetSSTables()
+	sort.Strings(flushed)
+	if len(flushed) != 2 {
+		t.Fatalf("Expected 2 SSTables, got %v", flushed)
+	}
+	version := manager.VersionLog().Current()
+	if len(version.Files()) != 2 || version.LastSequence == 0 || version.NextFileNumber != 3 {
+		t.Fatalf("Unexpected version: files %+v, last sequence %d, next file %d",
+			version.Files(), version.LastSequence, version.NextFileNumber)
+	}
+	for _, file := range version.Files() {
+		if file.LargestSequence == 0 || file.LargestSequence > version.LastSequence {
+			t.Errorf("Expected %s to record its largest sequence number, got %d", file.Name, file.LargestSequence)
+		}
+	}
+	if err := manager.Close(); err != nil {
+		t.Fatalf("Failed to close storage manager: %v", err)
+	}
+
+	copyFile := func(src, name string) string {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", src, err)
+		}
+		dst := filepath.Join(sstDir, name)
+		if err := os.WriteFile(dst, data, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", dst, err)
+		}
+		return dst
+	}
+	now := time.Now().UnixNano()
+
+	// A compaction that crashed before committing leaves its outputs behind
+	uncommitted := copyFile(flushed[0], fmt.Sprintf(sstableFilenameFormat, 1, 1, now))
+	partial := copyFile(flushed[0], fmt.Sprintf(".%s.tmp", filepath.Base(fmt.Sprintf(sstableFilenameFormat, 1, 2, now))))
+
+	// One that committed but crashed before deleting its input leaves the input
+	committed := copyFile(flushed[0], fmt.Sprintf(sstableFilenameFormat, 1, 3, now))
+	versions, err := versionlog.Open(sstDir)
+	if err != nil {
+		t.Fatalf("Failed to open version log: %v", err)
+	}
+	reader, err := sstable.OpenReader(committed)
+	if err != nil {
+		t.Fatalf("Failed to open SSTable: %v", err)
+	}
+	output, err := versionlog.Describe(reader)
+	reader.Close()
+	if err != nil {
+		t.Fatalf("Failed to describe SSTable: %v", err)
+	}
+	if err := versions.Apply(versionlog.Edit{
+		Added:   []versionlog.FileMeta{output},
+		Removed: []string{filepath.Base(flushed[0])},
+	}); err != nil {
+		t.Fatalf("Failed to commit compaction: %v", err)
+	}
+	versions.Close()
+
+	manager, err = NewManager(cfg, stats.NewAtomicCollector())
+	if err != nil {
+		t.Fatalf("Failed to reopen storage manager: %v", err)
+	}
+	defer manager.Close()
+
+	live := manager.GetSSTables()
+	sort.Strings(live)
+	if expected := []string{flushed[1], committed}; fmt.Sprint(live) != fmt.Sprint(expected) {
+		t.Errorf("Expected SSTables %v, got %v", expected, live)
+	}
+	for _, path := range []string{uncommitted, partial, flushed[0]} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be deleted, got %v", filepath.Base(path), err)
+		}
+	}
+	for _, key := range []string{"key1", "key2"} {
+		value, err := manager.Get([]byte(key))
+		if err != nil || !bytes.Equal(value, []byte("value-"+key)) {
+			t.Errorf("Expected value-%s, got %q (%v)", key, value, err)
+		}
+	}
+
+	// Flushes carry on numbering files after the ones before the restart
+	if err := manager.Put([]byte("key3"), []byte("value-key3")); err != nil {
+		t.Fatalf("Failed to put key3: %v", err)
+	}
+	if err := manager.FlushMemTables(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+	files := manager.VersionLog().Current().Files()
+	if len(files) != 3 || files[1].Level != 0 || files[1].Sequence != 3 {
+		t.Errorf("Expected the new flush to be file 3 of level 0, got %+v", files)
+	}
+}
+
+// TestWrittenInRangeAfter tests that flushed SSTables record their highest
+// sequence number, and that range checks find writes newer than a sequence
+// number in the MemTables, the SSTables and range deletions
+func TestWrittenInRangeAfter(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := &config.Config{
+		Version:         config.CurrentManifestVersion,
+		SSTDir:          filepath.Join(tempDir, "sst"),
+		WALDir:          filepath.Join(tempDir, "wal"),
+		MemTableSize:    1024 * 1024,
+		MemTablePoolCap: 2,
+		MaxMemTables:    2,
+	}
+
+	manager, err := NewManager(cfg, stats.NewAtomicCollector())
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+	defer manager.Close()
+
+	for _, key := range []string{"a", "b"} {
+		if err := manager.Put([]byte(key), []byte("value")); err != nil {
+			t.Fatalf("Failed to put %s: %v", key, err)
+		}
+	}
+	if err := manager.FlushMemTables(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+	flushedSeq := manager.lastSeqNum
+
+	files := manager.VersionLog().Current().Files()
+	if len(files) != 1 || files[0].LargestSequence != flushedSeq {
+		t.Fatalf("Expected one file with largest sequence %d, got %+v", flushedSeq, files)
+	}
+
+	if err := manager.Put([]byte("m"), []byte("value")); err != nil {
+		t.Fatalf("Failed to put m: %v", err)
+	}
+	if err := manager.DeleteRange([]byte("x"), []byte("y")); err != nil {
+		t.Fatalf("Failed to delete range: %v", err)
+	}
+	lastSeq := manager.lastSeqNum
+
+	tests := []struct {
+		start, end string
+		seqNum     uint64
+		want       bool
+	}{
+		{"a", "c", flushedSeq, false},
+		{"a", "c", 0, true},
+		{"", "", flushedSeq, true},
+		{"", "", lastSeq, false},
+		{"c", "n", flushedSeq, true},
+		{"n", "x", flushedSeq, false},
+		{"w", "z", flushedSeq, true},
+	}
+
+	manager.mu.RLock()
+	defer manager.mu.RUnlock()
+	for _, tt := range tests {
+		var start, end []byte
+		if tt.start != "" {
+			start = []byte(tt.start)
+		}
+		if tt.end != "" {
+			end = []byte(tt.end)
+		}
+		if got := manager.writtenInRangeAfter(start, end, tt.seqNum); got != tt.want {
+			t.Errorf("writtenInRangeAfter(%q, %q, %d) = %v, want %v", tt.start, tt.end, tt.seqNum, got, tt.want)
+		}
+	}
+}
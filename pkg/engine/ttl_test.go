@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestEngineFacade_PutWithTTL(t *testing.T) {
+	dir, err := os.MkdirTemp("", "engine-facade-ttl-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	eng, err := NewEngineFacade(dir)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	if err := eng.PutWithTTL([]byte("key"), []byte("value"), 0); !errors.Is(err, ErrInvalidTTL) {
+		t.Errorf("Expected ErrInvalidTTL, got %v", err)
+	}
+
+	const shortTTL = 200 * time.Millisecond
+	mustPut := func(key, value string, ttl time.Duration) {
+		var err error
+		if ttl > 0 {
+			err = eng.PutWithTTL([]byte(key), []byte(value), ttl)
+		} else {
+			err = eng.Put([]byte(key), []byte(value))
+		}
+		if err != nil {
+			t.Fatalf("Failed to put %s: %v", key, err)
+		}
+	}
+
+	// An expiring value hides the older one on disk even once it has expired
+	mustPut("a", "old", 0)
+	mustPut("b", "old", 0)
+	if err := eng.FlushImMemTables(); err != nil {
+		t.Fatalf("Failed to flush memtables: %v", err)
+	}
+	mustPut("a", "new", shortTTL)
+	mustPut("c", "value", shortTTL)
+	if err := eng.FlushImMemTables(); err != nil {
+		t.Fatalf("Failed to flush memtables: %v", err)
+	}
+	mustPut("b", "new", shortTTL)
+	mustPut("d", "value", time.Hour)
+	mustPut("e", "value", 0)
+
+	verify := func(stage string, expected map[string]string) {
+		for _, key := range []string{"a", "b", "c", "d", "e"} {
+			want, live := expected[key]
+			value, err := eng.Get([]byte(key))
+			if !live {
+				if err == nil {
+					t.Errorf("%s: expected %s to be expired, got %q", stage, key, value)
+				}
+				continue
+			}
+			if err != nil || string(value) != want {
+				t.Errorf("%s: expected %s=%s, got %q, %v", stage, key, want, value, err)
+			}
+		}
+
+		iter, err := eng.GetIterator()
+		if err != nil {
+			t.Fatalf("%s: failed to get iterator: %v", stage, err)
+		}
+		seen := 0
+		for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+			if iter.IsTombstone() {
+				continue
+			}
+			if want := expected[string(iter.Key())]; string(iter.Value()) != want {
+				t.Errorf("%s: iterator expected %s=%s, got %q", stage, iter.Key(), want, iter.Value())
+			}
+			seen++
+		}
+		if seen != len(expected) {
+			t.Errorf("%s: expected %d keys from iterator, got %d", stage, len(expected), seen)
+		}
+	}
+
+	verify("before expiry", map[string]string{"a": "new", "b": "new", "c": "value", "d": "value", "e": "value"})
+
+	time.Sleep(shortTTL)
+	afterExpiry := map[string]string{"d": "value", "e": "value"}
+	verify("after expiry", afterExpiry)
+
+	// Compaction removes the expired values without bringing back older ones
+	if err := eng.FlushImMemTables(); err != nil {
+		t.Fatalf("Failed to flush memtables: %v", err)
+	}
+	if err := eng.CompactRange(nil, nil); err != nil {
+		t.Fatalf("Failed to compact: %v", err)
+	}
+	verify("after compaction", afterExpiry)
+
+	// The expiry time survives a restart
+	if err := eng.Close(); err != nil {
+		t.Fatalf("Failed to close engine: %v", err)
+	}
+	eng, err = NewEngineFacade(dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen engine: %v", err)
+	}
+	defer eng.Close()
+	verify("after restart", afterExpiry)
+}
@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/KevoDB/kevo/pkg/common/iterator"
 	"github.com/KevoDB/kevo/pkg/common/iterator/filtered"
@@ -12,7 +14,10 @@ import (
 	"github.com/KevoDB/kevo/pkg/replication"
 	"github.com/KevoDB/kevo/pkg/transaction"
 	"github.com/KevoDB/kevo/pkg/version"
+	"github.com/KevoDB/kevo/pkg/wal"
 	pb "github.com/KevoDB/kevo/proto/kevo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Using the transaction registry directly
@@ -34,6 +39,28 @@ type KevoServiceServer struct {
 	replicationManager ReplicationInfoProvider // Interface to the replication manager
 }
 
+// columnFamilyEngine is implemented by engines that support column families
+type columnFamilyEngine interface {
+	ColumnFamilyID(name string) (uint32, error)
+	PutCF(family string, key, value []byte) error
+	GetCF(family string, key []byte) ([]byte, error)
+	DeleteCF(family string, key []byte) error
+	GetRangeIteratorCF(family string, startKey, endKey []byte) (iterator.Iterator, error)
+}
+
+// columnFamilies returns the engine for a request naming a column family,
+// after checking that the column family exists
+func (s *KevoServiceServer) columnFamilies(name string) (columnFamilyEngine, error) {
+	cfEngine, ok := s.engine.(columnFamilyEngine)
+	if !ok {
+		return nil, fmt.Errorf("column families are not supported")
+	}
+	if _, err := cfEngine.ColumnFamilyID(name); err != nil {
+		return nil, err
+	}
+	return cfEngine, nil
+}
+
 // CleanupConnection implements the ConnectionCleanup interface
 func (s *KevoServiceServer) CleanupConnection(connectionID string) {
 	// Forward call to the transaction registry if it supports connection cleanup
@@ -74,7 +101,17 @@ func (s *KevoServiceServer) Get(ctx context.Context, req *pb.GetRequest) (*pb.Ge
 		return nil, fmt.Errorf("invalid key size")
 	}
 
-	value, err := s.engine.Get(req.Key)
+	var value []byte
+	var err error
+	if req.ColumnFamily != "" {
+		cfEngine, cfErr := s.columnFamilies(req.ColumnFamily)
+		if cfErr != nil {
+			return nil, cfErr
+		}
+		value, err = cfEngine.GetCF(req.ColumnFamily, req.Key)
+	} else {
+		value, err = s.engine.Get(req.Key)
+	}
 	if err != nil {
 		// Key not found or other error, return not found
 		return &pb.GetResponse{Found: false}, nil
@@ -96,7 +133,27 @@ func (s *KevoServiceServer) Put(ctx context.Context, req *pb.PutRequest) (*pb.Pu
 		return nil, fmt.Errorf("value too large")
 	}
 
-	if err := s.engine.Put(req.Key, req.Value); err != nil {
+	if req.TtlMs < 0 {
+		return nil, fmt.Errorf("invalid ttl")
+	}
+
+	value := putValue(req.Value)
+	var err error
+	if req.ColumnFamily != "" {
+		if req.TtlMs > 0 {
+			return nil, fmt.Errorf("ttl is not supported for column families")
+		}
+		cfEngine, cfErr := s.columnFamilies(req.ColumnFamily)
+		if cfErr != nil {
+			return nil, cfErr
+		}
+		err = cfEngine.PutCF(req.ColumnFamily, req.Key, value)
+	} else if req.TtlMs > 0 {
+		err = s.engine.PutWithTTL(req.Key, value, time.Duration(req.TtlMs)*time.Millisecond)
+	} else {
+		err = s.engine.Put(req.Key, value)
+	}
+	if err != nil {
 		return &pb.PutResponse{Success: false}, err
 	}
 
@@ -109,13 +166,88 @@ func (s *KevoServiceServer) Delete(ctx context.Context, req *pb.DeleteRequest) (
 		return nil, fmt.Errorf("invalid key size")
 	}
 
-	if err := s.engine.Delete(req.Key); err != nil {
+	var err error
+	if req.ColumnFamily != "" {
+		cfEngine, cfErr := s.columnFamilies(req.ColumnFamily)
+		if cfErr != nil {
+			return nil, cfErr
+		}
+		err = cfEngine.DeleteCF(req.ColumnFamily, req.Key)
+	} else {
+		err = s.engine.Delete(req.Key)
+	}
+	if err != nil {
 		return &pb.DeleteResponse{Success: false}, err
 	}
 
 	return &pb.DeleteResponse{Success: true}, nil
 }
 
+// DeleteRange removes every key in [start_key, end_key)
+func (s *KevoServiceServer) DeleteRange(ctx context.Context, req *pb.DeleteRangeRequest) (*pb.DeleteRangeResponse, error) {
+	if len(req.StartKey) == 0 || len(req.StartKey) > s.maxKeySize ||
+		len(req.EndKey) == 0 || len(req.EndKey) > s.maxKeySize {
+		return nil, fmt.Errorf("invalid key size")
+	}
+
+	if err := s.engine.DeleteRange(req.StartKey, req.EndKey); err != nil {
+		return &pb.DeleteRangeResponse{Success: false}, err
+	}
+
+	return &pb.DeleteRangeResponse{Success: true}, nil
+}
+
+// CompareAndSwap sets a key to a new value if its current value is the expected one
+func (s *KevoServiceServer) CompareAndSwap(ctx context.Context, req *pb.CompareAndSwapRequest) (*pb.ConditionalWriteResponse, error) {
+	if len(req.Key) == 0 || len(req.Key) > s.maxKeySize {
+		return nil, fmt.Errorf("invalid key size")
+	}
+
+	if len(req.NewValue) > s.maxValueSize {
+		return nil, fmt.Errorf("value too large")
+	}
+
+	result, err := s.engine.CompareAndSwap(req.Key, req.ExpectedValue, req.NewValue)
+	return conditionalWriteResponse(result, err)
+}
+
+// PutIfAbsent stores a key-value pair if the key doesn't exist
+func (s *KevoServiceServer) PutIfAbsent(ctx context.Context, req *pb.PutIfAbsentRequest) (*pb.ConditionalWriteResponse, error) {
+	if len(req.Key) == 0 || len(req.Key) > s.maxKeySize {
+		return nil, fmt.Errorf("invalid key size")
+	}
+
+	if len(req.Value) > s.maxValueSize {
+		return nil, fmt.Errorf("value too large")
+	}
+
+	result, err := s.engine.PutIfAbsent(req.Key, putValue(req.Value))
+	return conditionalWriteResponse(result, err)
+}
+
+// DeleteIfEquals removes a key if its current value is the expected one
+func (s *KevoServiceServer) DeleteIfEquals(ctx context.Context, req *pb.DeleteIfEqualsRequest) (*pb.ConditionalWriteResponse, error) {
+	if len(req.Key) == 0 || len(req.Key) > s.maxKeySize {
+		return nil, fmt.Errorf("invalid key size")
+	}
+
+	result, err := s.engine.DeleteIfEquals(req.Key, req.ExpectedValue)
+	return conditionalWriteResponse(result, err)
+}
+
+// conditionalWriteResponse converts the result of a conditional write
+func conditionalWriteResponse(result interfaces.ConditionResult, err error) (*pb.ConditionalWriteResponse, error) {
+	if err != nil {
+		return &pb.ConditionalWriteResponse{Succeeded: false}, err
+	}
+
+	return &pb.ConditionalWriteResponse{
+		Succeeded:    result.Applied,
+		CurrentValue: result.Value,
+		Found:        result.Found,
+	}, nil
+}
+
 // BatchWrite performs multiple operations in a batch
 func (s *KevoServiceServer) BatchWrite(ctx context.Context, req *pb.BatchWriteRequest) (*pb.BatchWriteResponse, error) {
 	if len(req.Operations) == 0 {
@@ -126,49 +258,80 @@ func (s *KevoServiceServer) BatchWrite(ctx context.Context, req *pb.BatchWriteRe
 		return nil, fmt.Errorf("batch size exceeds maximum allowed (%d)", s.maxBatchSize)
 	}
 
-	// Start a transaction for atomic batch operations
-	tx, err := s.engine.BeginTransaction(false) // Read-write transaction
-	if err != nil {
-		return &pb.BatchWriteResponse{Success: false}, fmt.Errorf("failed to start transaction: %w", err)
-	}
-
-	// Ensure we either commit or rollback
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
-	// Process each operation
-	for _, op := range req.Operations {
+	// Convert the operations to a single atomic WAL batch, which may span
+	// column families
+	entries := make([]*wal.Entry, 0, len(req.Operations))
+	var conditions []interfaces.Precondition
+	var conditionOps []int
+	for i, op := range req.Operations {
 		if len(op.Key) == 0 || len(op.Key) > s.maxKeySize {
-			err = fmt.Errorf("invalid key size in batch operation")
-			return &pb.BatchWriteResponse{Success: false}, err
+			return &pb.BatchWriteResponse{Success: false}, fmt.Errorf("invalid key size in batch operation")
 		}
 
+		var entry *wal.Entry
 		switch op.Type {
 		case pb.Operation_PUT:
 			if len(op.Value) > s.maxValueSize {
-				err = fmt.Errorf("value too large in batch operation")
-				return &pb.BatchWriteResponse{Success: false}, err
-			}
-			if err = tx.Put(op.Key, op.Value); err != nil {
-				return &pb.BatchWriteResponse{Success: false}, err
+				return &pb.BatchWriteResponse{Success: false}, fmt.Errorf("value too large in batch operation")
 			}
+			entry = &wal.Entry{Type: wal.OpTypePut, Key: op.Key, Value: putValue(op.Value)}
 		case pb.Operation_DELETE:
-			if err = tx.Delete(op.Key); err != nil {
-				return &pb.BatchWriteResponse{Success: false}, err
+			entry = &wal.Entry{Type: wal.OpTypeDelete, Key: op.Key}
+		case pb.Operation_DELETE_RANGE:
+			if len(op.EndKey) == 0 || len(op.EndKey) > s.maxKeySize {
+				return &pb.BatchWriteResponse{Success: false}, fmt.Errorf("invalid end key size in batch operation")
 			}
+			entry = &wal.Entry{Type: wal.OpTypeDeleteRange, Key: op.Key, Value: op.EndKey}
 		default:
-			err = fmt.Errorf("unknown operation type")
+			return &pb.BatchWriteResponse{Success: false}, fmt.Errorf("unknown operation type")
+		}
+
+		if op.ColumnFamily != "" {
+			cfEngine, err := s.columnFamilies(op.ColumnFamily)
+			if err != nil {
+				return &pb.BatchWriteResponse{Success: false}, err
+			}
+			entry.Family, _ = cfEngine.ColumnFamilyID(op.ColumnFamily)
+		}
+		entries = append(entries, entry)
+
+		if op.Precondition != nil {
+			condition := interfaces.Precondition{Key: op.Key, Family: entry.Family, Value: op.Precondition.Value}
+			switch op.Precondition.Type {
+			case pb.Precondition_EQUALS:
+				condition.Type = interfaces.PreconditionEquals
+			case pb.Precondition_ABSENT:
+				condition.Type = interfaces.PreconditionAbsent
+			case pb.Precondition_EXISTS:
+				condition.Type = interfaces.PreconditionExists
+			default:
+				return &pb.BatchWriteResponse{Success: false}, fmt.Errorf("unknown precondition type")
+			}
+			conditions = append(conditions, condition)
+			conditionOps = append(conditionOps, i)
+		}
+	}
+
+	if len(conditions) == 0 {
+		if err := s.engine.ApplyBatch(entries); err != nil {
 			return &pb.BatchWriteResponse{Success: false}, err
 		}
+		return &pb.BatchWriteResponse{Success: true}, nil
 	}
 
-	// Commit the transaction
-	if err = tx.Commit(); err != nil {
+	result, err := s.engine.ApplyBatchIf(entries, conditions)
+	if err != nil {
 		return &pb.BatchWriteResponse{Success: false}, err
 	}
+	if !result.Applied {
+		return &pb.BatchWriteResponse{
+			Success:            false,
+			PreconditionFailed: true,
+			FailedOperation:    int32(conditionOps[result.Failed]),
+			CurrentValue:       result.Value,
+			Found:              result.Found,
+		}, nil
+	}
 
 	return &pb.BatchWriteResponse{Success: true}, nil
 }
@@ -180,6 +343,34 @@ func (s *KevoServiceServer) Scan(req *pb.ScanRequest, stream pb.KevoService_Scan
 		limit = req.Limit
 	}
 
+	// Column families are scanned directly rather than in a transaction
+	if req.ColumnFamily != "" {
+		cfEngine, err := s.columnFamilies(req.ColumnFamily)
+		if err != nil {
+			return err
+		}
+
+		var startKey, endKey []byte
+		if len(req.StartKey) > 0 {
+			startKey = req.StartKey
+		}
+		if len(req.EndKey) > 0 {
+			endKey = req.EndKey
+		}
+		iter, err := cfEngine.GetRangeIteratorCF(req.ColumnFamily, startKey, endKey)
+		if err != nil {
+			return err
+		}
+		if len(req.Prefix) > 0 {
+			iter = filtered.NewPrefixIterator(iter, req.Prefix)
+		}
+		if len(req.Suffix) > 0 {
+			iter = filtered.NewSuffixIterator(iter, req.Suffix)
+		}
+
+		return sendScan(iter, limit, req.Reverse, stream)
+	}
+
 	// Use a longer timeout for scan operations
 	// We create a timeout context but don't need to use it explicitly as the gRPC context
 	// will handle timeouts at the transport level
@@ -213,9 +404,14 @@ func (s *KevoServiceServer) Scan(req *pb.ScanRequest, stream pb.KevoService_Scan
 		iter = tx.NewIterator()
 	}
 
+	return sendScan(iter, limit, req.Reverse, stream)
+}
+
+// sendScan streams up to limit live entries of iter, without a limit if it is 0
+func sendScan(iter iterator.Iterator, limit int32, reverse bool, stream pb.KevoService_ScanServer) error {
 	count := int32(0)
-	// Position iterator at the first entry
-	iter.SeekToFirst()
+	// Position iterator at the first entry in scan order
+	seekScanStart(iter, reverse)
 
 	// Iterate through all valid entries
 	for iter.Valid() {
@@ -235,12 +431,30 @@ func (s *KevoServiceServer) Scan(req *pb.ScanRequest, stream pb.KevoService_Scan
 		}
 
 		// Move to the next entry
-		iter.Next()
+		advanceScan(iter, reverse)
 	}
 
 	return nil
 }
 
+// seekScanStart positions iter at the first entry of a scan, which is its last
+// key when the scan is reversed
+func seekScanStart(iter iterator.Iterator, reverse bool) {
+	if reverse {
+		iter.SeekToLast()
+	} else {
+		iter.SeekToFirst()
+	}
+}
+
+// advanceScan moves iter to the next entry of a scan in scan order
+func advanceScan(iter iterator.Iterator, reverse bool) bool {
+	if reverse {
+		return iter.Prev()
+	}
+	return iter.Next()
+}
+
 // BeginTransaction starts a new transaction
 func (s *KevoServiceServer) BeginTransaction(ctx context.Context, req *pb.BeginTransactionRequest) (*pb.BeginTransactionResponse, error) {
 	// Force clean up of old transactions before creating new ones
@@ -248,7 +462,17 @@ func (s *KevoServiceServer) BeginTransaction(ctx context.Context, req *pb.BeginT
 		cleaner.CleanupStaleTransactions()
 	}
 
-	txID, err := s.txRegistry.Begin(ctx, s.engine, req.ReadOnly)
+	opts := transaction.TxOptions{ReadOnly: req.ReadOnly}
+	switch req.Isolation {
+	case pb.BeginTransactionRequest_SNAPSHOT:
+		opts.Isolation = transaction.SnapshotIsolation
+	case pb.BeginTransactionRequest_SERIALIZABLE:
+		opts.Isolation = transaction.Serializable
+	default:
+		return nil, fmt.Errorf("unknown isolation level: %v", req.Isolation)
+	}
+
+	txID, err := s.txRegistry.BeginWithOptions(ctx, s.engine, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -273,6 +497,10 @@ func (s *KevoServiceServer) CommitTransaction(ctx context.Context, req *pb.Commi
 
 	if err := tx.Commit(); err != nil {
 		log.Error("Failed to commit transaction %s: %v", req.TransactionId, err)
+		if errors.Is(err, transaction.ErrConflict) {
+			// The client can retry the whole transaction
+			return &pb.CommitTransactionResponse{Success: false}, status.Error(codes.Aborted, err.Error())
+		}
 		return &pb.CommitTransactionResponse{Success: false}, err
 	}
 
@@ -360,7 +588,7 @@ func (s *KevoServiceServer) TxPut(ctx context.Context, req *pb.TxPutRequest) (*p
 		return nil, fmt.Errorf("value too large")
 	}
 
-	if err := tx.Put(req.Key, req.Value); err != nil {
+	if err := tx.Put(req.Key, putValue(req.Value)); err != nil {
 		return &pb.TxPutResponse{Success: false}, err
 	}
 
@@ -407,13 +635,14 @@ func (s *KevoServiceServer) TxScan(req *pb.TxScanRequest, stream pb.KevoService_
 	// Create appropriate iterator based on request parameters
 	var iter iterator.Iterator
 	if len(req.Prefix) > 0 && len(req.Suffix) > 0 {
-		// Create a combined prefix-suffix iterator
-		baseIter := tx.NewIterator()
+		// Create a combined prefix-suffix iterator. The base iterator is limited to
+		// the prefix range so serializable transactions only track that range.
+		baseIter := tx.NewRangeIterator(req.Prefix, prefixEnd(req.Prefix))
 		prefixIter := filtered.NewPrefixIterator(baseIter, req.Prefix)
 		iter = filtered.NewSuffixIterator(prefixIter, req.Suffix)
 	} else if len(req.Prefix) > 0 {
 		// Create a prefix iterator
-		baseIter := tx.NewIterator()
+		baseIter := tx.NewRangeIterator(req.Prefix, prefixEnd(req.Prefix))
 		iter = filtered.NewPrefixIterator(baseIter, req.Prefix)
 	} else if len(req.Suffix) > 0 {
 		// Create a suffix iterator
@@ -428,8 +657,8 @@ func (s *KevoServiceServer) TxScan(req *pb.TxScanRequest, stream pb.KevoService_
 	}
 
 	count := int32(0)
-	// Position iterator at the first entry
-	iter.SeekToFirst()
+	// Position iterator at the first entry in scan order
+	seekScanStart(iter, req.Reverse)
 
 	// Iterate through all valid entries
 	for iter.Valid() {
@@ -449,12 +678,34 @@ func (s *KevoServiceServer) TxScan(req *pb.TxScanRequest, stream pb.KevoService_
 		}
 
 		// Move to the next entry
-		iter.Next()
+		advanceScan(iter, req.Reverse)
 	}
 
 	return nil
 }
 
+// prefixEnd returns the smallest key greater than every key with the given
+// prefix, or nil if there is no such key
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xFF {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// putValue returns the value to store for a put. Protobuf decodes an empty
+// value as nil, which the storage layer would take for a deletion.
+func putValue(value []byte) []byte {
+	if value == nil {
+		return []byte{}
+	}
+	return value
+}
+
 // GetStats retrieves database statistics
 func (s *KevoServiceServer) GetStats(ctx context.Context, req *pb.GetStatsRequest) (*pb.GetStatsResponse, error) {
 	// Collect basic stats that we know are available
@@ -654,6 +905,54 @@ func (s *KevoServiceServer) Compact(ctx context.Context, req *pb.CompactRequest)
 	return &pb.CompactResponse{Success: true}, nil
 }
 
+// checkpointEngine is implemented by engines that can write checkpoints
+type checkpointEngine interface {
+	CreateCheckpoint(dir string) (uint64, error)
+}
+
+// Checkpoint writes a copy of the database to a directory on the server
+func (s *KevoServiceServer) Checkpoint(ctx context.Context, req *pb.CheckpointRequest) (*pb.CheckpointResponse, error) {
+	if req.Dir == "" {
+		return &pb.CheckpointResponse{Success: false}, fmt.Errorf("checkpoint directory cannot be empty")
+	}
+
+	cpEngine, ok := s.engine.(checkpointEngine)
+	if !ok {
+		return &pb.CheckpointResponse{Success: false}, fmt.Errorf("checkpoints are not supported")
+	}
+
+	seqNum, err := cpEngine.CreateCheckpoint(req.Dir)
+	if err != nil {
+		return &pb.CheckpointResponse{Success: false}, err
+	}
+
+	return &pb.CheckpointResponse{Success: true, Sequence: seqNum}, nil
+}
+
+// ingestEngine is implemented by engines that can ingest SSTable files
+type ingestEngine interface {
+	IngestFilesCF(family string, paths []string) (uint64, error)
+}
+
+// IngestSSTables adds SSTable files on the server to the database
+func (s *KevoServiceServer) IngestSSTables(ctx context.Context, req *pb.IngestSSTablesRequest) (*pb.IngestSSTablesResponse, error) {
+	if len(req.Paths) == 0 {
+		return &pb.IngestSSTablesResponse{Success: false}, fmt.Errorf("no files to ingest")
+	}
+
+	ingEngine, ok := s.engine.(ingestEngine)
+	if !ok {
+		return &pb.IngestSSTablesResponse{Success: false}, fmt.Errorf("ingestion is not supported")
+	}
+
+	seqNum, err := ingEngine.IngestFilesCF(req.ColumnFamily, req.Paths)
+	if err != nil {
+		return &pb.IngestSSTablesResponse{Success: false}, err
+	}
+
+	return &pb.IngestSSTablesResponse{Success: true, Sequence: seqNum}, nil
+}
+
 // GetNodeInfo returns information about this node and the replication topology
 func (s *KevoServiceServer) GetNodeInfo(ctx context.Context, req *pb.GetNodeInfoRequest) (*pb.GetNodeInfoResponse, error) {
 	// Create default response for standalone mode
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/KevoDB/kevo/pkg/engine"
+	"github.com/KevoDB/kevo/pkg/transaction"
+	pb "github.com/KevoDB/kevo/proto/kevo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCommitTransactionConflict(t *testing.T) {
+	eng, err := engine.NewEngineFacade(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	s := NewKevoServiceServer(eng, transaction.NewRegistry(), nil)
+	ctx := context.Background()
+
+	if err := eng.Put([]byte("a"), []byte("1")); err != nil {
+		t.Fatalf("Failed to put key: %v", err)
+	}
+
+	begin, err := s.BeginTransaction(ctx, &pb.BeginTransactionRequest{})
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	txID := begin.TransactionId
+
+	if _, err := s.TxGet(ctx, &pb.TxGetRequest{TransactionId: txID, Key: []byte("a")}); err != nil {
+		t.Fatalf("Failed to read in transaction: %v", err)
+	}
+	if _, err := s.TxPut(ctx, &pb.TxPutRequest{TransactionId: txID, Key: []byte("b"), Value: []byte("2")}); err != nil {
+		t.Fatalf("Failed to write in transaction: %v", err)
+	}
+
+	// The key the transaction read changes before it commits
+	if err := eng.Put([]byte("a"), []byte("3")); err != nil {
+		t.Fatalf("Failed to put key: %v", err)
+	}
+
+	resp, err := s.CommitTransaction(ctx, &pb.CommitTransactionRequest{TransactionId: txID})
+	if status.Code(err) != codes.Aborted {
+		t.Fatalf("Expected Aborted, got %v", err)
+	}
+	if resp.GetSuccess() {
+		t.Errorf("Expected the commit to fail")
+	}
+
+	// Nothing the transaction wrote was applied
+	if _, err := eng.Get([]byte("b")); err == nil {
+		t.Errorf("Expected b to be missing")
+	}
+}
@@ -0,0 +1,387 @@
+// Package transport implements the "grpc" client transport used by package
+// client. Importing it registers the transport.
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/KevoDB/kevo/pkg/transport"
+	pb "github.com/KevoDB/kevo/proto/kevo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+func init() {
+	transport.RegisterClientTransport("grpc", NewClient)
+}
+
+// Client carries the JSON encoded requests of package client as calls to the
+// KevoService. The JSON field names are those of the protobuf messages.
+type Client struct {
+	endpoint string
+	options  transport.TransportOptions
+
+	mu     sync.RWMutex
+	conn   *grpc.ClientConn
+	client pb.KevoServiceClient
+	status transport.TransportStatus
+}
+
+// NewClient creates a gRPC client transport for the server at endpoint
+func NewClient(endpoint string, options transport.TransportOptions) (transport.Client, error) {
+	switch options.Compression {
+	case "", transport.CompressionNone, transport.CompressionGzip:
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", options.Compression)
+	}
+	return &Client{endpoint: endpoint, options: options}, nil
+}
+
+// Connect establishes a connection to the server
+func (c *Client) Connect(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		return nil
+	}
+
+	opts := []grpc.DialOption{}
+	if c.options.TLSEnabled {
+		tlsConfig, err := c.tlsConfig()
+		if err != nil {
+			return err
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+	if c.options.KeepaliveParams != nil {
+		opts = append(opts, grpc.WithKeepaliveParams(*c.options.KeepaliveParams))
+	}
+	var callOpts []grpc.CallOption
+	if c.options.MaxMessageSize > 0 {
+		callOpts = append(callOpts,
+			grpc.MaxCallRecvMsgSize(c.options.MaxMessageSize),
+			grpc.MaxCallSendMsgSize(c.options.MaxMessageSize))
+	}
+	if c.options.Compression == transport.CompressionGzip {
+		callOpts = append(callOpts, grpc.UseCompressor(gzip.Name))
+	}
+	if len(callOpts) > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(callOpts...))
+	}
+
+	conn, err := grpc.NewClient(c.endpoint, opts...)
+	if err != nil {
+		c.status.LastError = err
+		return fmt.Errorf("failed to connect to %s: %w", c.endpoint, err)
+	}
+
+	// Fail now rather than on the first request if the server isn't there
+	if c.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.options.Timeout)
+		defer cancel()
+	}
+	conn.Connect()
+	for state := conn.GetState(); state != connectivity.Ready; state = conn.GetState() {
+		if !conn.WaitForStateChange(ctx, state) {
+			conn.Close()
+			c.status.LastError = ctx.Err()
+			return fmt.Errorf("failed to connect to %s: %w", c.endpoint, ctx.Err())
+		}
+	}
+
+	c.conn = conn
+	c.client = pb.NewKevoServiceClient(conn)
+	c.status.Connected = true
+	c.status.LastConnected = time.Now()
+	return nil
+}
+
+// tlsConfig builds the TLS configuration from the transport options
+func (c *Client) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.options.CertFile != "" && c.options.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.options.CertFile, c.options.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if c.options.CAFile != "" {
+		ca, err := os.ReadFile(c.options.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates in %s", c.options.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	c.client = nil
+	c.status.Connected = false
+	return err
+}
+
+// IsConnected returns whether the client is connected
+func (c *Client) IsConnected() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.conn != nil
+}
+
+// Status returns the current status of the connection
+func (c *Client) Status() transport.TransportStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.status
+}
+
+// service returns the service client, or an error if not connected
+func (c *Client) service() (pb.KevoServiceClient, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.client == nil {
+		return nil, transport.ErrNotConnected
+	}
+	return c.client, nil
+}
+
+// Send sends a request and waits for the response
+func (c *Client) Send(ctx context.Context, request transport.Request) (transport.Response, error) {
+	svc, err := c.service()
+	if err != nil {
+		return nil, err
+	}
+
+	payload := request.Payload()
+	var resp any
+	switch request.Type() {
+	case transport.TypeGet:
+		resp, err = invoke(ctx, payload, svc.Get)
+	case transport.TypePut:
+		resp, err = invoke(ctx, payload, svc.Put)
+	case transport.TypeDelete:
+		resp, err = invoke(ctx, payload, svc.Delete)
+	case transport.TypeDeleteRange:
+		resp, err = invoke(ctx, payload, svc.DeleteRange)
+	case transport.TypeCompareAndSwap:
+		resp, err = invoke(ctx, payload, svc.CompareAndSwap)
+	case transport.TypePutIfAbsent:
+		resp, err = invoke(ctx, payload, svc.PutIfAbsent)
+	case transport.TypeDeleteIfEquals:
+		resp, err = invoke(ctx, payload, svc.DeleteIfEquals)
+	case transport.TypeBatchWrite:
+		resp, err = batchWrite(ctx, payload, svc)
+	case transport.TypeBeginTx:
+		resp, err = invoke(ctx, payload, svc.BeginTransaction)
+	case transport.TypeCommitTx:
+		resp, err = invoke(ctx, payload, svc.CommitTransaction)
+	case transport.TypeRollbackTx:
+		resp, err = invoke(ctx, payload, svc.RollbackTransaction)
+	case transport.TypeTxGet:
+		resp, err = invoke(ctx, payload, svc.TxGet)
+	case transport.TypeTxPut:
+		resp, err = invoke(ctx, payload, svc.TxPut)
+	case transport.TypeTxDelete:
+		resp, err = invoke(ctx, payload, svc.TxDelete)
+	case transport.TypeGetStats:
+		resp, err = invoke(ctx, payload, svc.GetStats)
+	case transport.TypeCompact:
+		resp, err = invoke(ctx, payload, svc.Compact)
+	case transport.TypeCheckpoint:
+		resp, err = invoke(ctx, payload, svc.Checkpoint)
+	case transport.TypeIngestSSTables:
+		resp, err = invoke(ctx, payload, svc.IngestSSTables)
+	case "GetNodeInfo":
+		resp, err = invoke(ctx, payload, svc.GetNodeInfo)
+	default:
+		return nil, fmt.Errorf("%w: unknown request type %q", transport.ErrInvalidRequest, request.Type())
+	}
+	if err != nil {
+		c.setError(err)
+		return nil, err
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	return transport.NewResponse(request.Type(), data, nil), nil
+}
+
+// setError records the last error of the connection
+func (c *Client) setError(err error) {
+	c.mu.Lock()
+	c.status.LastError = err
+	c.mu.Unlock()
+}
+
+// invoke decodes the JSON payload into the request message of a unary call
+// and makes the call
+func invoke[Req, Resp any](ctx context.Context, payload []byte,
+	method func(context.Context, *Req, ...grpc.CallOption) (*Resp, error)) (any, error) {
+	req := new(Req)
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, req); err != nil {
+			return nil, fmt.Errorf("%w: %v", transport.ErrInvalidPayload, err)
+		}
+	}
+	resp, err := method(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// batchWrite makes a BatchWrite call, whose operation and precondition
+// types are names in the JSON payload rather than enum values
+func batchWrite(ctx context.Context, payload []byte, svc pb.KevoServiceClient) (any, error) {
+	var req struct {
+		Operations []struct {
+			Type         string `json:"type"`
+			Key          []byte `json:"key"`
+			Value        []byte `json:"value"`
+			EndKey       []byte `json:"end_key"`
+			ColumnFamily string `json:"column_family"`
+			Precondition *struct {
+				Type  string `json:"type"`
+				Value []byte `json:"value"`
+			} `json:"precondition"`
+		} `json:"operations"`
+		Sync bool `json:"sync"`
+	}
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, fmt.Errorf("%w: %v", transport.ErrInvalidPayload, err)
+	}
+
+	batch := &pb.BatchWriteRequest{Sync: req.Sync}
+	for _, op := range req.Operations {
+		operation := &pb.Operation{Key: op.Key, Value: op.Value, EndKey: op.EndKey, ColumnFamily: op.ColumnFamily}
+		switch op.Type {
+		case "put":
+			operation.Type = pb.Operation_PUT
+		case "delete":
+			operation.Type = pb.Operation_DELETE
+		case "delete_range":
+			operation.Type = pb.Operation_DELETE_RANGE
+		default:
+			return nil, fmt.Errorf("%w: unknown operation type %q", transport.ErrInvalidPayload, op.Type)
+		}
+
+		if p := op.Precondition; p != nil {
+			operation.Precondition = &pb.Precondition{Value: p.Value}
+			switch p.Type {
+			case "equals":
+				operation.Precondition.Type = pb.Precondition_EQUALS
+			case "absent":
+				operation.Precondition.Type = pb.Precondition_ABSENT
+			case "exists":
+				operation.Precondition.Type = pb.Precondition_EXISTS
+			default:
+				return nil, fmt.Errorf("%w: unknown precondition type %q", transport.ErrInvalidPayload, p.Type)
+			}
+		}
+		batch.Operations = append(batch.Operations, operation)
+	}
+	return svc.BatchWrite(ctx, batch)
+}
+
+// Stream opens a stream for a scan, started by sending the scan request
+func (c *Client) Stream(ctx context.Context) (transport.Stream, error) {
+	svc, err := c.service()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &stream{ctx: ctx, cancel: cancel, svc: svc}, nil
+}
+
+// stream carries a server streaming scan
+type stream struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	svc    pb.KevoServiceClient
+	recv   func() (any, error)
+}
+
+// Send starts the scan described by the request. A stream carries one scan.
+func (s *stream) Send(request transport.Request) error {
+	if s.recv != nil {
+		return fmt.Errorf("%w: the scan has already started", transport.ErrInvalidRequest)
+	}
+
+	switch request.Type() {
+	case transport.TypeScan:
+		req := &pb.ScanRequest{}
+		if err := json.Unmarshal(request.Payload(), req); err != nil {
+			return fmt.Errorf("%w: %v", transport.ErrInvalidPayload, err)
+		}
+		scan, err := s.svc.Scan(s.ctx, req)
+		if err != nil {
+			return err
+		}
+		s.recv = func() (any, error) { return scan.Recv() }
+	case transport.TypeTxScan:
+		req := &pb.TxScanRequest{}
+		if err := json.Unmarshal(request.Payload(), req); err != nil {
+			return fmt.Errorf("%w: %v", transport.ErrInvalidPayload, err)
+		}
+		scan, err := s.svc.TxScan(s.ctx, req)
+		if err != nil {
+			return err
+		}
+		s.recv = func() (any, error) { return scan.Recv() }
+	default:
+		return fmt.Errorf("%w: cannot stream request type %q", transport.ErrInvalidRequest, request.Type())
+	}
+	return nil
+}
+
+// Recv receives the next key-value pair of the scan, or io.EOF after the last
+func (s *stream) Recv() (transport.Response, error) {
+	if s.recv == nil {
+		return nil, fmt.Errorf("%w: no scan has been started", transport.ErrInvalidRequest)
+	}
+	msg, err := s.recv()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	return transport.NewResponse(transport.TypeScan, data, nil), nil
+}
+
+// Close ends the stream
+func (s *stream) Close() error {
+	s.cancel()
+	return nil
+}
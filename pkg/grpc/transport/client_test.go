@@ -0,0 +1,252 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KevoDB/kevo/pkg/engine"
+	"github.com/KevoDB/kevo/pkg/grpc/service"
+	"github.com/KevoDB/kevo/pkg/sstable"
+	"github.com/KevoDB/kevo/pkg/transaction"
+	"github.com/KevoDB/kevo/pkg/transport"
+	pb "github.com/KevoDB/kevo/proto/kevo"
+	"google.golang.org/grpc"
+)
+
+func TestClient(t *testing.T) {
+	eng, err := engine.NewEngineFacade(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := grpc.NewServer()
+	pb.RegisterKevoServiceServer(server, service.NewKevoServiceServer(eng, transaction.NewRegistry(), nil))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	c, err := transport.GetClient("grpc", lis.Addr().String(), transport.TransportOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+	if err := c.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer c.Close()
+
+	send := func(requestType string, request, response any) {
+		t.Helper()
+		payload, _ := json.Marshal(request)
+		resp, err := c.Send(ctx, transport.NewRequest(requestType, payload))
+		if err != nil {
+			t.Fatalf("Failed to send %s: %v", requestType, err)
+		}
+		if err := json.Unmarshal(resp.Payload(), response); err != nil {
+			t.Fatalf("Failed to unmarshal %s response: %v", requestType, err)
+		}
+	}
+
+	var written struct {
+		Success bool `json:"success"`
+	}
+	send(transport.TypePut, map[string]any{"key": []byte("a"), "value": []byte("1")}, &written)
+	if !written.Success {
+		t.Fatal("Expected the put to succeed")
+	}
+	send(transport.TypeBatchWrite, map[string]any{"operations": []map[string]any{
+		{"type": "put", "key": []byte("b"), "value": []byte{}},
+		{"type": "put", "key": []byte("c"), "value": []byte("3")},
+		{"type": "delete", "key": []byte("a")},
+	}}, &written)
+	if !written.Success {
+		t.Fatal("Expected the batch to succeed")
+	}
+
+	var got struct {
+		Value []byte `json:"value"`
+		Found bool   `json:"found"`
+	}
+	send(transport.TypeGet, map[string]any{"key": []byte("b")}, &got)
+	if !got.Found || len(got.Value) != 0 {
+		t.Errorf("Expected an empty value for b, got %q (found %v)", got.Value, got.Found)
+	}
+
+	stream, err := c.Stream(ctx)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer stream.Close()
+	payload, _ := json.Marshal(map[string]any{"start_key": []byte("a"), "end_key": []byte("z")})
+	if err := stream.Send(transport.NewRequest(transport.TypeScan, payload)); err != nil {
+		t.Fatalf("Failed to start scan: %v", err)
+	}
+	var keys []string
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Failed to receive: %v", err)
+		}
+		var kv struct {
+			Key []byte `json:"key"`
+		}
+		if err := json.Unmarshal(resp.Payload(), &kv); err != nil {
+			t.Fatalf("Failed to unmarshal scan response: %v", err)
+		}
+		keys = append(keys, string(kv.Key))
+	}
+	if len(keys) != 2 || keys[0] != "b" || keys[1] != "c" {
+		t.Errorf("Expected keys b and c, got %v", keys)
+	}
+
+	if _, err := c.Send(ctx, transport.NewRequest("unknown", nil)); err == nil {
+		t.Error("Expected an error for an unknown request type")
+	}
+}
+
+func TestClientWritesAndAdmin(t *testing.T) {
+	dir := t.TempDir()
+	eng, err := engine.NewEngineFacade(filepath.Join(dir, "db"))
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := grpc.NewServer()
+	pb.RegisterKevoServiceServer(server, service.NewKevoServiceServer(eng, transaction.NewRegistry(), nil))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	c, err := transport.GetClient("grpc", lis.Addr().String(), transport.TransportOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+	if err := c.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer c.Close()
+
+	send := func(requestType string, request, response any) {
+		t.Helper()
+		payload, _ := json.Marshal(request)
+		resp, err := c.Send(ctx, transport.NewRequest(requestType, payload))
+		if err != nil {
+			t.Fatalf("Failed to send %s: %v", requestType, err)
+		}
+		if err := json.Unmarshal(resp.Payload(), response); err != nil {
+			t.Fatalf("Failed to unmarshal %s response: %v", requestType, err)
+		}
+	}
+	found := func(key string) bool {
+		t.Helper()
+		var got struct {
+			Found bool `json:"found"`
+		}
+		send(transport.TypeGet, map[string]any{"key": []byte(key)}, &got)
+		return got.Found
+	}
+
+	// Conditional writes report whether they were applied and the current value
+	type conditionalResult struct {
+		Succeeded    bool   `json:"succeeded"`
+		CurrentValue []byte `json:"current_value"`
+		Found        bool   `json:"found"`
+	}
+	conditional := func(requestType string, request any) conditionalResult {
+		t.Helper()
+		var result conditionalResult
+		send(requestType, request, &result)
+		return result
+	}
+	if r := conditional(transport.TypePutIfAbsent, map[string]any{"key": []byte("a"), "value": []byte("1")}); !r.Succeeded {
+		t.Error("Expected PutIfAbsent of a new key to succeed")
+	}
+	r := conditional(transport.TypeCompareAndSwap, map[string]any{"key": []byte("a"), "expected_value": []byte("0"), "new_value": []byte("2")})
+	if r.Succeeded || string(r.CurrentValue) != "1" {
+		t.Errorf("Expected CompareAndSwap to fail with the current value 1, got %+v", r)
+	}
+	if r := conditional(transport.TypeCompareAndSwap, map[string]any{"key": []byte("a"), "expected_value": []byte("1"), "new_value": []byte("2")}); !r.Succeeded {
+		t.Error("Expected CompareAndSwap to succeed")
+	}
+	if r := conditional(transport.TypeDeleteIfEquals, map[string]any{"key": []byte("a"), "expected_value": []byte("2")}); !r.Succeeded || r.Found {
+		t.Errorf("Expected DeleteIfEquals to delete the key, got %+v", r)
+	}
+
+	// Batch preconditions are sent by name
+	var batch struct {
+		Success            bool `json:"success"`
+		PreconditionFailed bool `json:"precondition_failed"`
+		FailedOperation    int  `json:"failed_operation"`
+	}
+	send(transport.TypeBatchWrite, map[string]any{"operations": []map[string]any{
+		{"type": "put", "key": []byte("b"), "value": []byte("1"), "precondition": map[string]any{"type": "absent"}},
+		{"type": "put", "key": []byte("c"), "value": []byte("1"), "precondition": map[string]any{"type": "exists"}},
+	}}, &batch)
+	if batch.Success || !batch.PreconditionFailed || batch.FailedOperation != 1 {
+		t.Errorf("Expected the second precondition to fail, got %+v", batch)
+	}
+	if found("b") {
+		t.Error("Expected nothing to be written by a failed batch")
+	}
+
+	var written struct {
+		Success bool `json:"success"`
+	}
+	for _, key := range []string{"d1", "d2", "e"} {
+		send(transport.TypePut, map[string]any{"key": []byte(key), "value": []byte("1")}, &written)
+	}
+	var deleted struct {
+		Success bool `json:"success"`
+	}
+	send(transport.TypeDeleteRange, map[string]any{"start_key": []byte("d"), "end_key": []byte("e")}, &deleted)
+	if !deleted.Success || found("d1") || found("d2") || !found("e") {
+		t.Error("Expected DeleteRange to delete d1 and d2 only")
+	}
+
+	// Ingested files and checkpoints are paths on the server
+	sstPath := filepath.Join(dir, "ingest.sst")
+	writer, err := sstable.NewSSTFileWriter(sstPath)
+	if err != nil {
+		t.Fatalf("Failed to create SSTable writer: %v", err)
+	}
+	if err := writer.Put([]byte("f"), []byte("1")); err != nil {
+		t.Fatalf("Failed to add key: %v", err)
+	}
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish SSTable: %v", err)
+	}
+	type sequenced struct {
+		Success  bool   `json:"success"`
+		Sequence uint64 `json:"sequence"`
+	}
+	var ingested sequenced
+	send(transport.TypeIngestSSTables, map[string]any{"paths": []string{sstPath}}, &ingested)
+	if !ingested.Success || ingested.Sequence == 0 || !found("f") {
+		t.Errorf("Expected the file to be ingested, got %+v", ingested)
+	}
+
+	var checkpoint sequenced
+	send(transport.TypeCheckpoint, map[string]any{"dir": filepath.Join(dir, "checkpoint")}, &checkpoint)
+	if !checkpoint.Success || checkpoint.Sequence == 0 {
+		t.Errorf("Expected a checkpoint, got %+v", checkpoint)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "checkpoint")); err != nil {
+		t.Errorf("Expected the checkpoint directory to exist: %v", err)
+	}
+}
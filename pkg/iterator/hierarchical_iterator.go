@@ -157,6 +157,19 @@ func (h *HierarchicalIterator) Next() bool {
 	return h.findNextUniqueKey(currentKey)
 }
 
+// Prev moves the iterator back to the previous key
+func (h *HierarchicalIterator) Prev() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.valid {
+		return false
+	}
+
+	// Find the previous unique key before the current key
+	return h.findPrevUniqueKey(h.key)
+}
+
 // Key returns the current key
 func (h *HierarchicalIterator) Key() []byte {
 	h.mu.Lock()
@@ -272,3 +285,46 @@ func (h *HierarchicalIterator) findNextUniqueKey(prevKey []byte) bool {
 
 	return false
 }
+
+// findPrevUniqueKey finds the largest key before nextKey, taking its value
+// from the newest source that contains it
+// Returns true if a valid key was found
+func (h *HierarchicalIterator) findPrevUniqueKey(nextKey []byte) bool {
+	var maxKey []byte
+	h.valid = false
+
+	// First pass: move all iterators before nextKey and find the max key
+	for _, iter := range h.iterators {
+		if !iterator.SeekBefore(iter, nextKey) {
+			continue
+		}
+
+		if maxKey == nil || bytes.Compare(iter.Key(), maxKey) > 0 {
+			maxKey = append(maxKey[:0], iter.Key()...)
+		}
+	}
+
+	if maxKey == nil {
+		return false
+	}
+
+	// Second pass: seek the sources holding the key back to its newest version
+	var maxValue []byte
+	seen := false
+	for _, iter := range h.iterators {
+		if !iter.Valid() || !bytes.Equal(iter.Key(), maxKey) {
+			continue
+		}
+
+		iter.Seek(maxKey)
+		if !seen {
+			maxValue = iter.Value()
+			seen = true
+		}
+	}
+
+	h.key = maxKey
+	h.value = maxValue
+	h.valid = true
+	return true
+}
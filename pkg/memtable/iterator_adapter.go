@@ -17,27 +17,14 @@ func (a *IteratorAdapter) SeekToFirst() {
 	a.iter.SeekToFirst()
 }
 
-// SeekToLast positions the iterator at the last key
+// SeekToLast positions the iterator at the newest version of the last key
 func (a *IteratorAdapter) SeekToLast() {
-	a.iter.SeekToFirst()
+	a.iter.SeekToLast()
 
-	// If no items, return early
-	if !a.iter.Valid() {
-		return
-	}
-
-	// Store the last key we've seen
-	var lastKey []byte
-
-	// Scan to find the last element
-	for a.iter.Valid() {
-		lastKey = a.iter.Key()
-		a.iter.Next()
-	}
-
-	// Re-position at the last key we found
-	if lastKey != nil {
-		a.iter.Seek(lastKey)
+	// Versions of a key are ordered newest first, so the last entry is the
+	// oldest version of the last key
+	if a.iter.Valid() {
+		a.iter.Seek(a.iter.Key())
 	}
 }
 
@@ -56,6 +43,16 @@ func (a *IteratorAdapter) Next() bool {
 	return a.iter.Valid()
 }
 
+// Prev moves the iterator back to the previous entry. Like Next, it steps
+// through every version of a key.
+func (a *IteratorAdapter) Prev() bool {
+	if !a.Valid() {
+		return false
+	}
+	a.iter.Prev()
+	return a.iter.Valid()
+}
+
 // Key returns the current key
 func (a *IteratorAdapter) Key() []byte {
 	if !a.Valid() {
@@ -89,6 +86,19 @@ func (a *IteratorAdapter) IsTombstone() bool {
 	return a.iter != nil && a.iter.IsTombstone()
 }
 
+// IsMergeOperand returns true if the current entry is a merge operand
+func (a *IteratorAdapter) IsMergeOperand() bool {
+	return a.iter != nil && a.iter.IsMergeOperand()
+}
+
+// ExpireAt returns the expiry time of the current entry, or 0 if it never expires
+func (a *IteratorAdapter) ExpireAt() uint64 {
+	if a.iter == nil {
+		return 0
+	}
+	return a.iter.ExpireAt()
+}
+
 // SequenceNumber returns the sequence number of the current entry
 func (a *IteratorAdapter) SequenceNumber() uint64 {
 	if !a.Valid() || a.iter.Entry() == nil {
@@ -44,6 +44,30 @@ func (p *MemTablePool) Put(key, value []byte, seqNum uint64) {
 	p.checkFlushConditionsLocked()
 }
 
+// Merge adds a merge operand for a key to the active MemTable
+func (p *MemTablePool) Merge(key, operand []byte, seqNum uint64) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	p.active.Merge(key, operand, seqNum)
+
+	// Check if we need to flush after this write
+	// Use the lock-free version since we already hold the read lock
+	p.checkFlushConditionsLocked()
+}
+
+// PutWithExpiry adds a key-value pair that expires at expireAt to the active MemTable
+func (p *MemTablePool) PutWithExpiry(key, value []byte, expireAt, seqNum uint64) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	p.active.PutWithExpiry(key, value, expireAt, seqNum)
+
+	// Check if we need to flush after this write
+	// Use the lock-free version since we already hold the read lock
+	p.checkFlushConditionsLocked()
+}
+
 // Delete marks a key as deleted in the active MemTable
 func (p *MemTablePool) Delete(key []byte, seqNum uint64) {
 	p.mu.RLock()
@@ -56,6 +80,18 @@ func (p *MemTablePool) Delete(key []byte, seqNum uint64) {
 	p.checkFlushConditionsLocked()
 }
 
+// DeleteRange marks every key in [start, end) as deleted in the active MemTable
+func (p *MemTablePool) DeleteRange(start, end []byte, seqNum uint64) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	p.active.DeleteRange(start, end, seqNum)
+
+	// Check if we need to flush after this write
+	// Use the lock-free version since we already hold the read lock
+	p.checkFlushConditionsLocked()
+}
+
 // Get retrieves the value for a key from all MemTables
 // Checks the active MemTable first, then the immutables in reverse order
 func (p *MemTablePool) Get(key []byte) ([]byte, bool) {
@@ -77,6 +113,46 @@ func (p *MemTablePool) Get(key []byte) ([]byte, bool) {
 	return nil, false
 }
 
+// GetWithSnapshot retrieves the value of a key as of the given sequence number
+func (p *MemTablePool) GetWithSnapshot(key []byte, snapshotSeq uint64) ([]byte, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	// Check active table first
+	if value, found := p.active.GetWithSnapshot(key, snapshotSeq); found {
+		return value, true
+	}
+
+	// Check immutable tables in reverse order (newest first)
+	for i := len(p.immutables) - 1; i >= 0; i-- {
+		if value, found := p.immutables[i].GetWithSnapshot(key, snapshotSeq); found {
+			return value, true
+		}
+	}
+
+	return nil, false
+}
+
+// GetSequence returns the sequence number of the newest version of a key in any MemTable
+func (p *MemTablePool) GetSequence(key []byte) (uint64, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	// Check active table first
+	if seqNum, found := p.active.GetSequence(key); found {
+		return seqNum, true
+	}
+
+	// Check immutable tables in reverse order (newest first)
+	for i := len(p.immutables) - 1; i >= 0; i-- {
+		if seqNum, found := p.immutables[i].GetSequence(key); found {
+			return seqNum, true
+		}
+	}
+
+	return 0, false
+}
+
 // ImmutableCount returns the number of immutable MemTables
 func (p *MemTablePool) ImmutableCount() int {
 	p.mu.RLock()
@@ -5,6 +5,8 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/KevoDB/kevo/pkg/common/rangedel"
+	"github.com/KevoDB/kevo/pkg/common/ttl"
 	"github.com/KevoDB/kevo/pkg/wal"
 )
 
@@ -16,6 +18,8 @@ type MemTable struct {
 	creationTime time.Time
 	immutable    atomic.Bool
 	size         int64
+	rangeDels    []rangedel.Tombstone
+	rangeDelSize int64
 	mu           sync.RWMutex
 }
 
@@ -47,6 +51,28 @@ func (m *MemTable) Put(key, value []byte, seqNum uint64) {
 	}
 }
 
+// PutWithExpiry adds a key-value pair that expires at expireAt, in Unix
+// nanoseconds, to the MemTable. Once expired it reads as deleted.
+func (m *MemTable) PutWithExpiry(key, value []byte, expireAt, seqNum uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.IsImmutable() {
+		// Don't modify immutable memtables
+		return
+	}
+
+	e := newEntry(key, value, TypeValue, seqNum)
+	e.expireAt = expireAt
+	m.skipList.Insert(e)
+
+	// Update maximum sequence number
+	nextSeqNum := m.nextSeqNum.Load()
+	if seqNum > nextSeqNum {
+		m.nextSeqNum.Store(seqNum + 1)
+	}
+}
+
 // Delete marks a key as deleted in the MemTable
 func (m *MemTable) Delete(key []byte, seqNum uint64) {
 	m.mu.Lock()
@@ -67,6 +93,58 @@ func (m *MemTable) Delete(key []byte, seqNum uint64) {
 	}
 }
 
+// Merge adds a merge operand for a key to the MemTable
+func (m *MemTable) Merge(key, operand []byte, seqNum uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.IsImmutable() {
+		// Don't modify immutable memtables
+		return
+	}
+
+	e := newEntry(key, operand, TypeMerge, seqNum)
+	m.skipList.Insert(e)
+
+	// Update maximum sequence number
+	nextSeqNum := m.nextSeqNum.Load()
+	if seqNum > nextSeqNum {
+		m.nextSeqNum.Store(seqNum + 1)
+	}
+}
+
+// DeleteRange marks every key in [start, end) as deleted in the MemTable
+func (m *MemTable) DeleteRange(start, end []byte, seqNum uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.IsImmutable() {
+		// Don't modify immutable memtables
+		return
+	}
+
+	m.rangeDels = append(m.rangeDels, rangedel.Tombstone{
+		Start:  append([]byte(nil), start...),
+		End:    append([]byte(nil), end...),
+		SeqNum: seqNum,
+	})
+	m.rangeDelSize += int64(len(start) + len(end) + 8)
+
+	// Update maximum sequence number
+	nextSeqNum := m.nextSeqNum.Load()
+	if seqNum > nextSeqNum {
+		m.nextSeqNum.Store(seqNum + 1)
+	}
+}
+
+// RangeTombstones returns the range deletions written to the MemTable
+func (m *MemTable) RangeTombstones() []rangedel.Tombstone {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]rangedel.Tombstone(nil), m.rangeDels...)
+}
+
 // Get retrieves the value associated with the given key
 // Returns (nil, true) if the key exists but has been deleted
 // Returns (nil, false) if the key does not exist
@@ -80,8 +158,8 @@ func (m *MemTable) Get(key []byte) ([]byte, bool) {
 			return nil, false
 		}
 
-		// Check if this is a deletion marker
-		if e.valueType == TypeDeletion {
+		// Check if this is a deletion marker or an expired value
+		if e.valueType == TypeDeletion || ttl.Expired(e.expireAt, time.Now()) {
 			return nil, true // Key exists but was deleted
 		}
 
@@ -97,8 +175,8 @@ func (m *MemTable) Get(key []byte) ([]byte, bool) {
 			return nil, false
 		}
 
-		// Check if this is a deletion marker
-		if e.valueType == TypeDeletion {
+		// Check if this is a deletion marker or an expired value
+		if e.valueType == TypeDeletion || ttl.Expired(e.expireAt, time.Now()) {
 			return nil, true // Key exists but was deleted
 		}
 
@@ -106,6 +184,42 @@ func (m *MemTable) Get(key []byte) ([]byte, bool) {
 	}
 }
 
+// GetWithSnapshot retrieves the value of a key as of the given sequence number.
+// Like Get, it returns (nil, true) if the visible version is a deletion marker.
+func (m *MemTable) GetWithSnapshot(key []byte, snapshotSeq uint64) ([]byte, bool) {
+	if !m.IsImmutable() {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+	}
+
+	e := m.skipList.FindWithSnapshot(key, snapshotSeq)
+	if e == nil {
+		return nil, false
+	}
+
+	if e.valueType == TypeDeletion || ttl.Expired(e.expireAt, time.Now()) {
+		return nil, true // Key exists but was deleted
+	}
+
+	return e.value, true
+}
+
+// GetSequence returns the sequence number of the newest version of the key,
+// including deletion markers. Returns false if the key is not in the MemTable.
+func (m *MemTable) GetSequence(key []byte) (uint64, bool) {
+	if !m.IsImmutable() {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+	}
+
+	e := m.skipList.Find(key)
+	if e == nil {
+		return 0, false
+	}
+
+	return e.seqNum, true
+}
+
 // Contains checks if the key exists in the MemTable
 func (m *MemTable) Contains(key []byte) bool {
 	// For immutable memtables, we can bypass the RWLock completely
@@ -122,7 +236,11 @@ func (m *MemTable) Contains(key []byte) bool {
 
 // ApproximateSize returns the approximate size of the MemTable in bytes
 func (m *MemTable) ApproximateSize() int64 {
-	return m.skipList.ApproximateSize()
+	m.mu.RLock()
+	rangeDelSize := m.rangeDelSize
+	m.mu.RUnlock()
+
+	return m.skipList.ApproximateSize() + rangeDelSize
 }
 
 // SetImmutable marks the MemTable as immutable
@@ -156,6 +274,12 @@ func (m *MemTable) NewIterator() *Iterator {
 	}
 }
 
+// NewIteratorWithSnapshot returns an iterator that only sees entries with a
+// sequence number <= snapshotSeq
+func (m *MemTable) NewIteratorWithSnapshot(snapshotSeq uint64) *Iterator {
+	return m.skipList.NewIteratorWithSnapshot(snapshotSeq)
+}
+
 // GetNextSequenceNumber returns the next sequence number to use
 func (m *MemTable) GetNextSequenceNumber() uint64 {
 	// For immutable memtables, nextSeqNum won't change
@@ -176,6 +300,16 @@ func (m *MemTable) ProcessWALEntry(entry *wal.Entry) error {
 		m.Put(entry.Key, entry.Value, entry.SequenceNumber)
 	case wal.OpTypeDelete:
 		m.Delete(entry.Key, entry.SequenceNumber)
+	case wal.OpTypeMerge:
+		m.Merge(entry.Key, entry.Value, entry.SequenceNumber)
+	case wal.OpTypeDeleteRange:
+		m.DeleteRange(entry.Key, entry.Value, entry.SequenceNumber)
+	case wal.OpTypePutWithTTL:
+		expireAt, value, err := ttl.Decode(entry.Value)
+		if err != nil {
+			return err
+		}
+		m.PutWithExpiry(entry.Key, value, expireAt, entry.SequenceNumber)
 	}
 	return nil
 }
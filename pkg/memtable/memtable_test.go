@@ -1,6 +1,7 @@
 package memtable
 
 import (
+	"bytes"
 	"fmt"
 	"math/rand"
 	"strings"
@@ -8,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/KevoDB/kevo/pkg/common/ttl"
 	"github.com/KevoDB/kevo/pkg/wal"
 )
 
@@ -405,3 +407,71 @@ func TestMemTableIteratorSnapshot(t *testing.T) {
 		t.Errorf("iter3 expected 6 keys, got %d: %v", len(iter3Keys), iter3Keys)
 	}
 }
+
+func TestMemTableGetWithSnapshot(t *testing.T) {
+	mt := NewMemTable()
+
+	mt.Put([]byte("key1"), []byte("value1"), 1)
+	mt.Put([]byte("key1"), []byte("value2"), 3)
+	mt.Delete([]byte("key1"), 5)
+
+	tests := []struct {
+		snapshotSeq uint64
+		value       []byte
+		found       bool
+	}{
+		{0, nil, false},             // Before the key was written
+		{1, []byte("value1"), true}, // First version
+		{2, []byte("value1"), true}, // Between versions
+		{4, []byte("value2"), true}, // Second version
+		{5, nil, true},              // Deletion marker
+		{10, nil, true},             // Still deleted
+	}
+
+	for _, tt := range tests {
+		value, found := mt.GetWithSnapshot([]byte("key1"), tt.snapshotSeq)
+		if found != tt.found {
+			t.Errorf("snapshot %d: expected found=%v, got %v", tt.snapshotSeq, tt.found, found)
+		}
+		if !bytes.Equal(value, tt.value) {
+			t.Errorf("snapshot %d: expected value %q, got %q", tt.snapshotSeq, tt.value, value)
+		}
+	}
+}
+
+func TestMemTablePutWithExpiry(t *testing.T) {
+	mt := NewMemTable()
+
+	past := uint64(time.Now().Add(-time.Second).UnixNano())
+	future := uint64(time.Now().Add(time.Hour).UnixNano())
+
+	mt.Put([]byte("a"), []byte("old"), 1)
+	mt.PutWithExpiry([]byte("a"), []byte("new"), past, 2)
+	mt.PutWithExpiry([]byte("b"), []byte("live"), future, 3)
+
+	// Expiring writes replayed from the WAL carry their expiry time
+	entry := &wal.Entry{
+		SequenceNumber: 4,
+		Type:           wal.OpTypePutWithTTL,
+		Key:            []byte("c"),
+		Value:          ttl.Encode(past, []byte("replayed")),
+	}
+	if err := mt.ProcessWALEntry(entry); err != nil {
+		t.Fatalf("failed to process WAL entry: %v", err)
+	}
+
+	// An expired value reads as deleted, hiding older versions
+	for _, key := range []string{"a", "c"} {
+		if value, found := mt.Get([]byte(key)); !found || value != nil {
+			t.Errorf("key %s: expected expired value to read as deleted, got %q, %v", key, value, found)
+		}
+	}
+	if value, found := mt.Get([]byte("b")); !found || string(value) != "live" {
+		t.Errorf("key b: expected live, got %q, %v", value, found)
+	}
+
+	iter := mt.NewIterator()
+	if iter.Seek([]byte("b")); iter.ExpireAt() != future {
+		t.Errorf("expected iterator to report expiry %d, got %d", future, iter.ExpireAt())
+	}
+}
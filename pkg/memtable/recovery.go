@@ -19,6 +19,10 @@ type RecoveryOptions struct {
 
 	// MemTableSize is the maximum size of each MemTable
 	MemTableSize int64
+
+	// Family is the column family to recover. Entries of other families
+	// sharing the WAL only advance the maximum sequence number.
+	Family uint32
 }
 
 // DefaultRecoveryOptions returns the default recovery options
@@ -53,6 +57,11 @@ func RecoverFromWAL(cfg *config.Config, opts *RecoveryOptions) ([]*MemTable, uin
 			maxSeqNum = entry.SequenceNumber
 		}
 
+		// Skip entries of other column families
+		if entry.Family != opts.Family {
+			return nil
+		}
+
 		// Get the current memtable
 		current := memTables[len(memTables)-1]
 
@@ -28,6 +28,9 @@ const (
 
 	// TypeDeletion indicates the entry is a tombstone (deletion marker)
 	TypeDeletion
+
+	// TypeMerge indicates the entry is a merge operand
+	TypeMerge
 )
 
 // entry represents a key-value pair with additional metadata
@@ -36,6 +39,7 @@ type entry struct {
 	value     []byte
 	valueType ValueType
 	seqNum    uint64
+	expireAt  uint64 // Expiry time of a value in Unix nanoseconds, 0 if it never expires
 }
 
 // newEntry creates a new entry
@@ -150,7 +154,8 @@ func (s *SkipList) getCurrentHeight() int {
 	return int(atomic.LoadInt32(&s.maxHeight))
 }
 
-// Insert adds a new entry to the skip list
+// Insert adds a new entry to the skip list. It goes ahead of any entry with the
+// same key and sequence number, so the writes of a batch are ordered newest first.
 func (s *SkipList) Insert(e *entry) {
 	height := s.randomHeight()
 	prev := [MaxHeight]*node{}
@@ -229,6 +234,64 @@ func (s *SkipList) Find(key []byte) *entry {
 	return result
 }
 
+// FindWithSnapshot looks for the newest entry for a key whose sequence number
+// is <= snapshotSeq. Returns nil if no such version exists.
+func (s *SkipList) FindWithSnapshot(key []byte, snapshotSeq uint64) *entry {
+	current := s.head
+	height := s.getCurrentHeight()
+
+	// Move to the node just before the first entry for this key
+	for level := height - 1; level >= 0; level-- {
+		next := current.getNext(level)
+		for next != nil && next.entry.compare(key) < 0 {
+			current = next
+			next = current.getNext(level)
+		}
+	}
+
+	// Entries for the same key are ordered newest first, so the first
+	// one inside the snapshot is the visible version
+	for candidate := current.getNext(0); candidate != nil && candidate.entry.compare(key) == 0; candidate = candidate.getNext(0) {
+		if candidate.entry.seqNum <= snapshotSeq {
+			return candidate.entry
+		}
+	}
+
+	return nil
+}
+
+// findLessThan returns the last node ordered before e, or nil if there is none
+func (s *SkipList) findLessThan(e *entry) *node {
+	current := s.head
+	for level := s.getCurrentHeight() - 1; level >= 0; level-- {
+		next := current.getNext(level)
+		for next != nil && next.entry.compareWithEntry(e) < 0 {
+			current = next
+			next = current.getNext(level)
+		}
+	}
+
+	if current == s.head {
+		return nil
+	}
+	return current
+}
+
+// findLast returns the last node of the list, or nil if it is empty
+func (s *SkipList) findLast() *node {
+	current := s.head
+	for level := s.getCurrentHeight() - 1; level >= 0; level-- {
+		for next := current.getNext(level); next != nil; next = current.getNext(level) {
+			current = next
+		}
+	}
+
+	if current == s.head {
+		return nil
+	}
+	return current
+}
+
 // ApproximateSize returns the approximate size of the skip list in bytes
 func (s *SkipList) ApproximateSize() int64 {
 	return atomic.LoadInt64(&s.size)
@@ -289,6 +352,31 @@ func (it *Iterator) Next() {
 	}
 }
 
+// Prev moves the iterator back to the previous entry
+func (it *Iterator) Prev() {
+	if it.current == nil || it.current == it.list.head {
+		return
+	}
+
+	// Nodes only link forward, so search for the last node before the current one
+	it.current = it.list.findLessThan(it.current.entry)
+
+	// Skip nodes that are not visible in our snapshot
+	for it.current != nil && !it.isVisible(it.current) {
+		it.current = it.list.findLessThan(it.current.entry)
+	}
+}
+
+// SeekToLast positions the iterator at the last entry
+func (it *Iterator) SeekToLast() {
+	it.current = it.list.findLast()
+
+	// Skip nodes that are not visible in our snapshot
+	for it.current != nil && !it.isVisible(it.current) {
+		it.current = it.list.findLessThan(it.current.entry)
+	}
+}
+
 // SeekToFirst positions the iterator at the first entry
 func (it *Iterator) SeekToFirst() {
 	it.current = it.list.head.getNext(0)
@@ -354,7 +442,7 @@ func (it *Iterator) Value() []byte {
 	return valueCopy
 }
 
-// ValueType returns the type of the current entry (TypeValue or TypeDeletion)
+// ValueType returns the type of the current entry (TypeValue, TypeDeletion or TypeMerge)
 func (it *Iterator) ValueType() ValueType {
 	if !it.Valid() {
 		return 0 // Invalid type
@@ -367,6 +455,19 @@ func (it *Iterator) IsTombstone() bool {
 	return it.Valid() && it.current.entry.valueType == TypeDeletion
 }
 
+// IsMergeOperand returns true if the current entry is a merge operand
+func (it *Iterator) IsMergeOperand() bool {
+	return it.Valid() && it.current.entry.valueType == TypeMerge
+}
+
+// ExpireAt returns the expiry time of the current entry, or 0 if it never expires
+func (it *Iterator) ExpireAt() uint64 {
+	if !it.Valid() {
+		return 0
+	}
+	return it.current.entry.expireAt
+}
+
 // Entry returns the current entry
 func (it *Iterator) Entry() *entry {
 	if !it.Valid() {
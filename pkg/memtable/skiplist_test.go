@@ -108,6 +108,51 @@ func TestSkipListIterator(t *testing.T) {
 	}
 }
 
+func TestSkipListIteratorPrev(t *testing.T) {
+	sl := NewSkipList()
+
+	// Insert entries, with two versions of banana
+	sl.Insert(newEntry([]byte("apple"), []byte("red"), TypeValue, 1))
+	sl.Insert(newEntry([]byte("banana"), []byte("green"), TypeValue, 2))
+	sl.Insert(newEntry([]byte("cherry"), []byte("red"), TypeValue, 3))
+	sl.Insert(newEntry([]byte("banana"), []byte("yellow"), TypeValue, 4))
+
+	// Iterating backwards visits every version, oldest first within a key
+	expected := []struct {
+		key string
+		seq uint64
+	}{
+		{"cherry", 3},
+		{"banana", 2},
+		{"banana", 4},
+		{"apple", 1},
+	}
+
+	it := sl.NewIterator()
+	count := 0
+	for it.SeekToLast(); it.Valid(); it.Prev() {
+		if count >= len(expected) {
+			t.Fatalf("iterator returned more entries than expected")
+		}
+		if string(it.Key()) != expected[count].key || it.SequenceNumber() != expected[count].seq {
+			t.Errorf("at position %d, expected %s@%d, got %s@%d",
+				count, expected[count].key, expected[count].seq, it.Key(), it.SequenceNumber())
+		}
+		count++
+	}
+
+	if count != len(expected) {
+		t.Errorf("expected to iterate through %d entries, but got %d", len(expected), count)
+	}
+
+	// Prev from a seek lands on the oldest version of the previous key
+	it.Seek([]byte("cherry"))
+	it.Prev()
+	if !it.Valid() || string(it.Key()) != "banana" || string(it.Value()) != "green" {
+		t.Errorf("expected banana=green, got %s=%s", it.Key(), it.Value())
+	}
+}
+
 func TestSkipListSeek(t *testing.T) {
 	sl := NewSkipList()
 
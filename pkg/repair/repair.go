@@ -0,0 +1,547 @@
+// Package repair checks the files of a database that isn't open and repairs
+// the damage it can. Check reads every SSTable and WAL file and reports what
+// the engine would trip over: unreadable or corrupt files, version logs that
+// don't match their files, overlapping files within a level and writes that
+// are missing from both the SSTables and the WAL. Repair sets the files that
+// can't be read aside in a lost directory and rebuilds the version logs from
+// the files that are left, so that the database opens again.
+package repair
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/KevoDB/kevo/pkg/common/fsutil"
+	"github.com/KevoDB/kevo/pkg/config"
+	"github.com/KevoDB/kevo/pkg/sstable"
+	"github.com/KevoDB/kevo/pkg/versionlog"
+	"github.com/KevoDB/kevo/pkg/wal"
+)
+
+// LostDirName is the subdirectory of the database directory that Repair
+// moves the files it can't use to
+const LostDirName = "lost"
+
+// Report is the outcome of checking a database
+type Report struct {
+	// Number of SSTable and WAL files checked
+	SSTables int
+	WALFiles int
+
+	// Problems found, each naming the file it's in
+	Problems []error
+}
+
+// Err returns the problems joined together, or nil if there are none
+func (r *Report) Err() error {
+	return errors.Join(r.Problems...)
+}
+
+// Repaired lists what Repair changed
+type Repaired struct {
+	// Files moved to the lost directory, by their new path
+	Quarantined []string
+
+	// WAL files cut back to their last readable entry, a copy of each is
+	// kept in the lost directory
+	Truncated []string
+
+	// SSTable directories whose version log was rebuilt
+	Rebuilt []string
+}
+
+// database is the layout of a database directory
+type database struct {
+	dir     string
+	cfg     *config.Config
+	sstDirs []string
+}
+
+// openDatabase reads the manifest of the database at dir to find its
+// SSTable directories: the default family's and those of column families
+func openDatabase(dir string) (*database, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("no database at %s", dir)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.LoadConfigFromManifest(dir)
+	if errors.Is(err, config.ErrManifestNotFound) {
+		return nil, fmt.Errorf("no database at %s: %w", dir, err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	db := &database{dir: dir, cfg: cfg, sstDirs: []string{cfg.SSTDir}}
+	for _, opts := range cfg.GetColumnFamilies() {
+		familyCfg, err := cfg.ForColumnFamily(opts)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(familyCfg.SSTDir); err == nil {
+			db.sstDirs = append(db.sstDirs, familyCfg.SSTDir)
+		}
+	}
+	return db, nil
+}
+
+// levelsDisjoint returns whether the compaction style keeps the files of the
+// levels below L0 from overlapping
+func (db *database) levelsDisjoint() bool {
+	switch db.cfg.CompactionStyle {
+	case "", config.CompactionStyleTiered, config.CompactionStyleLeveled:
+		return true
+	}
+	return false
+}
+
+// fileCheck is the outcome of checking an SSTable file
+type fileCheck struct {
+	path string
+	meta versionlog.FileMeta
+
+	// Why the file can't be used, nil if it can
+	err error
+
+	// Metadata described from the file itself, if it can be used
+	actual versionlog.FileMeta
+}
+
+// dirCheck is the outcome of checking an SSTable directory
+type dirCheck struct {
+	dir string
+
+	// Live version, nil if the directory has no version log or it can't be
+	// read, in which case the engine would adopt every file
+	version *versionlog.Version
+	logErr  error
+
+	// Highest sequence number written to the files, from the version log or
+	// else from the usable files themselves
+	lastSeq uint64
+
+	files []fileCheck
+
+	// Problems that don't make a file unusable
+	problems []error
+}
+
+// checkSSTDir checks the version log and SSTable files of a directory
+func (db *database) checkSSTDir(dir string) (*dirCheck, error) {
+	dc := &dirCheck{dir: dir}
+
+	var files []versionlog.FileMeta
+	if versionlog.Exists(dir) {
+		dc.version, dc.logErr = versionlog.Read(dir)
+	}
+	if dc.version != nil {
+		files = dc.version.Files()
+	} else {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SSTable directory: %w", err)
+		}
+		for _, entry := range entries {
+			level, sequence, _, ok := versionlog.ParseFileName(entry.Name())
+			if entry.IsDir() || !ok || filepath.Ext(entry.Name()) != ".sst" {
+				continue
+			}
+			files = append(files, versionlog.FileMeta{Name: entry.Name(), Level: level, Sequence: sequence})
+		}
+	}
+
+	for _, meta := range files {
+		fc := fileCheck{path: filepath.Join(dir, meta.Name), meta: meta}
+		fc.actual, fc.err = checkSSTable(fc.path, meta, dc.version != nil)
+		if fc.err == nil && dc.version != nil &&
+			(!bytes.Equal(fc.actual.Smallest, meta.Smallest) || !bytes.Equal(fc.actual.Largest, meta.Largest)) {
+			dc.problems = append(dc.problems, fmt.Errorf("%s: key range [%q, %q] differs from [%q, %q] in the version log",
+				fc.path, fc.actual.Smallest, fc.actual.Largest, meta.Smallest, meta.Largest))
+		}
+		dc.files = append(dc.files, fc)
+	}
+
+	if dc.version != nil {
+		dc.lastSeq = dc.version.LastSequence
+	} else {
+		for _, fc := range dc.files {
+			if fc.err != nil {
+				continue
+			}
+			seqNum, err := lastSequence(fc.path)
+			if err != nil {
+				return nil, err
+			}
+			dc.lastSeq = max(dc.lastSeq, seqNum)
+		}
+	}
+
+	if db.levelsDisjoint() {
+		dc.problems = append(dc.problems, checkOverlaps(dir, dc.files)...)
+	}
+	return dc, nil
+}
+
+// checkSSTable opens and verifies an SSTable file, returning its metadata as
+// described from the file. If the file's entry in the version log is known,
+// the size must match it.
+func checkSSTable(path string, meta versionlog.FileMeta, known bool) (versionlog.FileMeta, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return versionlog.FileMeta{}, err
+	}
+	if known && stat.Size() != meta.Size {
+		return versionlog.FileMeta{}, fmt.Errorf("size is %d, the version log has %d", stat.Size(), meta.Size)
+	}
+
+	reader, err := sstable.OpenReaderWithOptions(path, sstable.ReaderOptions{GlobalSequence: meta.GlobalSequence})
+	if err != nil {
+		return versionlog.FileMeta{}, err
+	}
+	defer reader.Close()
+
+	if err := reader.Verify(); err != nil {
+		return versionlog.FileMeta{}, err
+	}
+	actual, err := versionlog.Describe(reader)
+	if err != nil {
+		return versionlog.FileMeta{}, err
+	}
+	actual.GlobalSequence = meta.GlobalSequence
+	actual.LargestSequence = meta.LargestSequence
+	return actual, nil
+}
+
+// checkOverlaps reports the usable files of a level below L0 whose key
+// ranges overlap
+func checkOverlaps(dir string, files []fileCheck) []error {
+	levels := make(map[int][]versionlog.FileMeta)
+	for _, fc := range files {
+		if fc.err == nil && fc.meta.Level > 0 && len(fc.actual.Smallest) > 0 {
+			levels[fc.meta.Level] = append(levels[fc.meta.Level], fc.actual)
+		}
+	}
+
+	var problems []error
+	for level := 1; len(levels) > 0; level++ {
+		metas := levels[level]
+		delete(levels, level)
+		sort.Slice(metas, func(i, j int) bool { return bytes.Compare(metas[i].Smallest, metas[j].Smallest) < 0 })
+		for i := 1; i < len(metas); i++ {
+			prev, next := metas[i-1], metas[i]
+			if bytes.Compare(prev.Largest, next.Smallest) >= 0 {
+				problems = append(problems, fmt.Errorf("%s: %s [%q, %q] and %s [%q, %q] overlap in level %d",
+					dir, prev.Name, prev.Smallest, prev.Largest, next.Name, next.Smallest, next.Largest, level))
+			}
+		}
+	}
+	return problems
+}
+
+// walCheck is the outcome of reading a WAL file
+type walCheck struct {
+	path string
+	size int64
+
+	// End of the last entry that could be read, and the error that stopped
+	// reading before the end of the file
+	valid int64
+	err   error
+}
+
+// checkWAL reads the WAL files and reports the sequence numbers after
+// flushed that are neither in the WAL nor the global sequence number of an
+// ingested file. If flushed is zero, the SSTables hold nothing and the WAL
+// may start anywhere.
+func (db *database) checkWAL(flushed uint64, ingested map[uint64]bool) ([]walCheck, []error, error) {
+	files, err := wal.FindWALFiles(db.cfg.WALDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var checks []walCheck
+	var problems []error
+	last, started := flushed, flushed > 0
+	for _, path := range files {
+		stat, err := os.Stat(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		reader, err := wal.OpenReader(path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		wc := walCheck{path: path, size: stat.Size()}
+		for {
+			entry, err := reader.ReadEntry()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				wc.err = err
+				break
+			}
+
+			seqNum := entry.SequenceNumber
+			if !started {
+				last, started = seqNum-1, true
+			}
+			if seqNum > last+1 {
+				problems = append(problems, missingSequences(path, last+1, seqNum-1, ingested)...)
+			}
+			last = max(last, seqNum)
+		}
+		wc.valid = reader.Offset()
+		reader.Close()
+
+		if wc.err != nil {
+			problems = append(problems, fmt.Errorf("%s: %d bytes from offset %d can't be read: %w",
+				path, wc.size-wc.valid, wc.valid, wc.err))
+		}
+		checks = append(checks, wc)
+	}
+	return checks, problems, nil
+}
+
+// missingSequences reports the runs of sequence numbers from first to last
+// that aren't ingested, found missing before an entry of the WAL file at path
+func missingSequences(path string, first, last uint64, ingested map[uint64]bool) []error {
+	var skipped []uint64
+	for seqNum := range ingested {
+		if seqNum >= first && seqNum <= last {
+			skipped = append(skipped, seqNum)
+		}
+	}
+	sort.Slice(skipped, func(i, j int) bool { return skipped[i] < skipped[j] })
+
+	var problems []error
+	report := func(from, to uint64) {
+		if from <= to {
+			problems = append(problems, fmt.Errorf("%s: sequence numbers %d to %d are in neither the WAL nor an SSTable",
+				path, from, to))
+		}
+	}
+	from := first
+	for _, seqNum := range skipped {
+		report(from, seqNum-1)
+		from = seqNum + 1
+	}
+	report(from, last)
+	return problems
+}
+
+// check checks the SSTable directories and the WAL
+func (db *database) check() ([]*dirCheck, []walCheck, *Report, error) {
+	report := &Report{}
+	var dirs []*dirCheck
+	var flushed uint64
+	ingested := make(map[uint64]bool)
+	for _, dir := range db.sstDirs {
+		dc, err := db.checkSSTDir(dir)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		dirs = append(dirs, dc)
+
+		if dc.logErr != nil {
+			report.Problems = append(report.Problems, fmt.Errorf("%s: %w", filepath.Join(dir, versionlog.FileName), dc.logErr))
+		}
+		for _, fc := range dc.files {
+			if fc.err != nil {
+				report.Problems = append(report.Problems, fmt.Errorf("%s: %w", fc.path, fc.err))
+			}
+			if fc.meta.GlobalSequence != 0 {
+				ingested[fc.meta.GlobalSequence] = true
+			}
+		}
+		report.Problems = append(report.Problems, dc.problems...)
+		report.SSTables += len(dc.files)
+		flushed = max(flushed, dc.lastSeq)
+	}
+
+	walChecks, problems, err := db.checkWAL(flushed, ingested)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	report.WALFiles = len(walChecks)
+	report.Problems = append(report.Problems, problems...)
+	return dirs, walChecks, report, nil
+}
+
+// Check reads every SSTable and WAL file of the database at dir, which must
+// not be open, and reports the problems found. It returns an error if the
+// database can't be checked at all.
+func Check(dir string) (*Report, error) {
+	db, err := openDatabase(dir)
+	if err != nil {
+		return nil, err
+	}
+	_, _, report, err := db.check()
+	return report, err
+}
+
+// Repair fixes what Check finds in the database at dir, which must not be
+// open, as far as it can without the data that's lost. SSTable files that
+// can't be read are moved to the lost directory and dropped from the version
+// log, which is rebuilt from the files left if it can't be read itself. WAL
+// files are cut back to their last readable entry. Overlapping files and
+// sequence numbers missing from the WAL are left as they are.
+func Repair(dir string) (*Repaired, error) {
+	db, err := openDatabase(dir)
+	if err != nil {
+		return nil, err
+	}
+	dirs, walChecks, _, err := db.check()
+	if err != nil {
+		return nil, err
+	}
+
+	repaired := &Repaired{}
+	for _, dc := range dirs {
+		if err := db.repairSSTDir(dc, repaired); err != nil {
+			return repaired, err
+		}
+	}
+	for _, wc := range walChecks {
+		if err := db.repairWALFile(wc, repaired); err != nil {
+			return repaired, err
+		}
+	}
+	return repaired, nil
+}
+
+// repairSSTDir sets aside the unusable files of a directory and rebuilds its
+// version log if it changed
+func (db *database) repairSSTDir(dc *dirCheck, repaired *Repaired) error {
+	changed := dc.logErr != nil
+	edit := versionlog.Edit{LastSequence: dc.lastSeq}
+	if dc.version != nil {
+		edit.LogNumber = dc.version.LogNumber
+		edit.NextFileNumber = dc.version.NextFileNumber
+	}
+
+	for _, fc := range dc.files {
+		if fc.err == nil {
+			edit.Added = append(edit.Added, fc.actual)
+			if fc.meta.Level == 0 {
+				edit.NextFileNumber = max(edit.NextFileNumber, fc.meta.Sequence+1)
+			}
+			if !bytes.Equal(fc.actual.Smallest, fc.meta.Smallest) || !bytes.Equal(fc.actual.Largest, fc.meta.Largest) {
+				changed = true
+			}
+			continue
+		}
+
+		changed = true
+		if _, err := os.Stat(fc.path); os.IsNotExist(err) {
+			continue
+		}
+		if err := db.quarantine(fc.path, repaired); err != nil {
+			return err
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	if dc.logErr != nil {
+		if err := db.quarantine(filepath.Join(dc.dir, versionlog.FileName), repaired); err != nil {
+			return err
+		}
+	}
+
+	if err := versionlog.Reset(dc.dir, edit); err != nil {
+		return err
+	}
+	repaired.Rebuilt = append(repaired.Rebuilt, dc.dir)
+	return nil
+}
+
+// lastSequence returns the highest sequence number in an SSTable file
+func lastSequence(path string) (uint64, error) {
+	reader, err := sstable.OpenReader(path)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	var seqNum uint64
+	for _, t := range reader.RangeTombstones() {
+		seqNum = max(seqNum, t.SeqNum)
+	}
+	iter := reader.NewIteratorWithOptions(sstable.ReadOptions{})
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		seqNum = max(seqNum, iter.SequenceNumber())
+	}
+	return seqNum, iter.Error()
+}
+
+// repairWALFile cuts a WAL file back to its last readable entry, keeping a
+// copy of it in the lost directory. A file with no readable entry is moved
+// there instead.
+func (db *database) repairWALFile(wc walCheck, repaired *Repaired) error {
+	if wc.err == nil {
+		return nil
+	}
+	if wc.valid == 0 {
+		return db.quarantine(wc.path, repaired)
+	}
+
+	lostPath, err := db.lostPath(wc.path)
+	if err != nil {
+		return err
+	}
+	if err := fsutil.CopyFile(wc.path, lostPath); err != nil {
+		return err
+	}
+	repaired.Quarantined = append(repaired.Quarantined, lostPath)
+
+	if err := os.Truncate(wc.path, wc.valid); err != nil {
+		return fmt.Errorf("failed to truncate %s: %w", wc.path, err)
+	}
+	repaired.Truncated = append(repaired.Truncated, wc.path)
+	return nil
+}
+
+// quarantine moves a file to the lost directory
+func (db *database) quarantine(path string, repaired *Repaired) error {
+	lostPath, err := db.lostPath(path)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(path, lostPath); err != nil {
+		return fmt.Errorf("failed to move %s to %s: %w", path, lostPath, err)
+	}
+	repaired.Quarantined = append(repaired.Quarantined, lostPath)
+	return nil
+}
+
+// lostPath returns an unused path in the lost directory for a file, at the
+// same place relative to it as the file is to the database directory
+func (db *database) lostPath(path string) (string, error) {
+	rel, err := filepath.Rel(db.dir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(path)
+	}
+	lostPath := filepath.Join(db.dir, LostDirName, rel)
+	if err := os.MkdirAll(filepath.Dir(lostPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create lost directory: %w", err)
+	}
+
+	// Files set aside by an earlier repair are kept
+	candidate := lostPath
+	for i := 1; ; i++ {
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s.%d", lostPath, i)
+	}
+}
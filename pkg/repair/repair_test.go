@@ -0,0 +1,225 @@
+package repair
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KevoDB/kevo/pkg/engine"
+	"github.com/KevoDB/kevo/pkg/sstable"
+	"github.com/KevoDB/kevo/pkg/versionlog"
+	"github.com/KevoDB/kevo/pkg/wal"
+)
+
+// putKeys writes keys prefix-from up to prefix-to
+func putKeys(t *testing.T, eng *engine.EngineFacade, prefix string, from, to int) {
+	t.Helper()
+	for i := from; i < to; i++ {
+		if err := eng.Put([]byte(fmt.Sprintf("%s-%03d", prefix, i)), []byte("value")); err != nil {
+			t.Fatalf("Failed to put: %v", err)
+		}
+	}
+}
+
+// expectProblems checks that the report has a problem containing each of
+// the given strings, and no others
+func expectProblems(t *testing.T, report *Report, want ...string) {
+	t.Helper()
+	if len(report.Problems) != len(want) {
+		t.Fatalf("Expected %d problems, got %v", len(want), report.Problems)
+	}
+	for _, s := range want {
+		if !strings.Contains(report.Err().Error(), s) {
+			t.Errorf("Expected a problem with %s, got %v", s, report.Problems)
+		}
+	}
+}
+
+func TestCheckAndRepair(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "db")
+	eng, err := engine.NewEngineFacade(dir)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	putKeys(t, eng, "a", 0, 100)
+	if err := eng.FlushImMemTables(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+	putKeys(t, eng, "b", 0, 10)
+
+	// An ingested file takes a sequence number the WAL doesn't have
+	ingestPath := filepath.Join(t.TempDir(), "c.sst")
+	writer, err := sstable.NewSSTFileWriter(ingestPath)
+	if err != nil {
+		t.Fatalf("Failed to create file writer: %v", err)
+	}
+	writer.Put([]byte("c-000"), []byte("ingested"))
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish file: %v", err)
+	}
+	if _, err := eng.IngestFiles([]string{ingestPath}); err != nil {
+		t.Fatalf("Failed to ingest: %v", err)
+	}
+	putKeys(t, eng, "d", 0, 10)
+	if err := eng.Close(); err != nil {
+		t.Fatalf("Failed to close engine: %v", err)
+	}
+
+	report, err := Check(dir)
+	if err != nil {
+		t.Fatalf("Failed to check: %v", err)
+	}
+	expectProblems(t, report)
+	if report.SSTables != 2 || report.WALFiles == 0 {
+		t.Errorf("Expected 2 SSTables and some WAL files, got %d and %d", report.SSTables, report.WALFiles)
+	}
+
+	// Damage the flushed file and tear the end of the WAL
+	flushed, _ := filepath.Glob(filepath.Join(dir, "sst", "0_*.sst"))
+	if len(flushed) != 1 {
+		t.Fatalf("Expected one flushed file, got %v", flushed)
+	}
+	data, err := os.ReadFile(flushed[0])
+	if err != nil {
+		t.Fatalf("Failed to read SSTable: %v", err)
+	}
+	data[10] ^= 0xff
+	if err := os.WriteFile(flushed[0], data, 0644); err != nil {
+		t.Fatalf("Failed to write SSTable: %v", err)
+	}
+	walFiles, err := wal.FindWALFiles(filepath.Join(dir, "wal"))
+	if err != nil || len(walFiles) == 0 {
+		t.Fatalf("Expected WAL files, got %v (%v)", walFiles, err)
+	}
+	lastWAL := walFiles[len(walFiles)-1]
+	f, err := os.OpenFile(lastWAL, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open WAL file: %v", err)
+	}
+	f.Write([]byte("torn"))
+	f.Close()
+
+	report, err = Check(dir)
+	if err != nil {
+		t.Fatalf("Failed to check: %v", err)
+	}
+	expectProblems(t, report, filepath.Base(flushed[0]), filepath.Base(lastWAL))
+
+	repaired, err := Repair(dir)
+	if err != nil {
+		t.Fatalf("Failed to repair: %v", err)
+	}
+	if len(repaired.Quarantined) != 2 || len(repaired.Truncated) != 1 || len(repaired.Rebuilt) != 1 {
+		t.Errorf("Unexpected repair %+v", repaired)
+	}
+	if _, err := os.Stat(filepath.Join(dir, LostDirName, "sst", filepath.Base(flushed[0]))); err != nil {
+		t.Errorf("Expected the damaged SSTable in the lost directory: %v", err)
+	}
+	report, err = Check(dir)
+	if err != nil {
+		t.Fatalf("Failed to check: %v", err)
+	}
+	expectProblems(t, report)
+
+	eng, err = engine.NewEngineFacade(dir)
+	if err != nil {
+		t.Fatalf("Failed to open repaired database: %v", err)
+	}
+	for _, key := range []string{"b-000", "c-000", "d-009"} {
+		if _, err := eng.Get([]byte(key)); err != nil {
+			t.Errorf("Expected %s after repair, got %v", key, err)
+		}
+	}
+	putKeys(t, eng, "e", 0, 10)
+	if err := eng.FlushImMemTables(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+	if err := eng.Close(); err != nil {
+		t.Fatalf("Failed to close engine: %v", err)
+	}
+
+	// A version log that can't be read is rebuilt from the files
+	payload := []byte("{not json")
+	record := make([]byte, 8+len(payload))
+	binary.LittleEndian.PutUint32(record[0:4], crc32.ChecksumIEEE(payload))
+	binary.LittleEndian.PutUint32(record[4:8], uint32(len(payload)))
+	copy(record[8:], payload)
+	versionsPath := filepath.Join(dir, "sst", versionlog.FileName)
+	if err := os.WriteFile(versionsPath, record, 0644); err != nil {
+		t.Fatalf("Failed to write version log: %v", err)
+	}
+
+	report, err = Check(dir)
+	if err != nil {
+		t.Fatalf("Failed to check: %v", err)
+	}
+	expectProblems(t, report, versionlog.FileName)
+	if _, err := Repair(dir); err != nil {
+		t.Fatalf("Failed to repair: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, LostDirName, "sst", versionlog.FileName)); err != nil {
+		t.Errorf("Expected the damaged version log in the lost directory: %v", err)
+	}
+	version, err := versionlog.Read(filepath.Join(dir, "sst"))
+	if err != nil {
+		t.Fatalf("Failed to read rebuilt version log: %v", err)
+	}
+	if len(version.Files()) != 2 || version.LastSequence == 0 {
+		t.Errorf("Expected the files left and their last sequence number, got %+v up to %d",
+			version.Files(), version.LastSequence)
+	}
+}
+
+func TestCheckOverlap(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "db")
+	eng, err := engine.NewEngineFacade(dir)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	if err := eng.Close(); err != nil {
+		t.Fatalf("Failed to close engine: %v", err)
+	}
+
+	// Two files of level 1 sharing key m, as no compaction would leave them
+	sstDir := filepath.Join(dir, "sst")
+	log, err := versionlog.Open(sstDir)
+	if err != nil {
+		t.Fatalf("Failed to open version log: %v", err)
+	}
+	for i, keys := range [][]string{{"a", "m"}, {"m", "z"}} {
+		path := filepath.Join(sstDir, fmt.Sprintf("1_%06d_%020d.sst", i+1, i+1))
+		writer, err := sstable.NewWriter(path)
+		if err != nil {
+			t.Fatalf("Failed to create writer: %v", err)
+		}
+		for _, key := range keys {
+			writer.AddWithSequence([]byte(key), []byte("value"), uint64(i+1))
+		}
+		if err := writer.Finish(); err != nil {
+			t.Fatalf("Failed to finish: %v", err)
+		}
+		reader, err := sstable.OpenReader(path)
+		if err != nil {
+			t.Fatalf("Failed to open reader: %v", err)
+		}
+		meta, err := versionlog.Describe(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatalf("Failed to describe file: %v", err)
+		}
+		if err := log.Apply(versionlog.Edit{Added: []versionlog.FileMeta{meta}, LastSequence: uint64(i + 1)}); err != nil {
+			t.Fatalf("Failed to apply edit: %v", err)
+		}
+	}
+	log.Close()
+
+	report, err := Check(dir)
+	if err != nil {
+		t.Fatalf("Failed to check: %v", err)
+	}
+	expectProblems(t, report, "overlap in level 1")
+}
@@ -98,8 +98,11 @@ func SerializeWALEntry(entry *wal.Entry) ([]byte, error) {
 	fmt.Printf("Serializing WAL entry: seq=%d, type=%d, key=%v\n",
 		entry.SequenceNumber, entry.Type, string(entry.Key))
 
+	// Entries of column families carry the family in front of the key, as in the WAL
+	entryType, key := wal.EncodeFamily(entry.Type, entry.Family, entry.Key)
+
 	// Create a buffer with appropriate size
-	entrySize := 1 + 8 + 4 + len(entry.Key) // type + seq + keylen + key
+	entrySize := 1 + 8 + 4 + len(key) // type + seq + keylen + key
 
 	// Include value for Put, Merge, and Batch operations (but not Delete)
 	if entry.Type != wal.OpTypeDelete {
@@ -110,7 +113,7 @@ func SerializeWALEntry(entry *wal.Entry) ([]byte, error) {
 	offset := 0
 
 	// Write operation type
-	payload[offset] = entry.Type
+	payload[offset] = entryType
 	offset++
 
 	// Write sequence number (8 bytes)
@@ -120,15 +123,15 @@ func SerializeWALEntry(entry *wal.Entry) ([]byte, error) {
 	offset += 8
 
 	// Write key length (4 bytes)
-	keyLen := uint32(len(entry.Key))
+	keyLen := uint32(len(key))
 	for i := 0; i < 4; i++ {
 		payload[offset+i] = byte(keyLen >> (i * 8))
 	}
 	offset += 4
 
 	// Write key
-	copy(payload[offset:], entry.Key)
-	offset += len(entry.Key)
+	copy(payload[offset:], key)
+	offset += len(key)
 
 	// Write value length and value (for all types except delete)
 	if entry.Type != wal.OpTypeDelete {
@@ -174,14 +177,16 @@ func DeserializeWALEntry(payload []byte) (*wal.Entry, error) {
 
 	offset := 0
 
-	// Read operation type
-	opType := payload[offset]
+	// Read operation type, the column family flag is handled once the key is read
+	encodedType := payload[offset]
+	opType := encodedType &^ wal.OpTypeFamilyFlag
 	fmt.Printf("Entry operation type: %d\n", opType)
 	offset++
 
 	// Validate operation type
 	if opType != wal.OpTypePut && opType != wal.OpTypeDelete &&
-		opType != wal.OpTypeMerge {
+		opType != wal.OpTypeMerge && opType != wal.OpTypeDeleteRange &&
+		opType != wal.OpTypePutWithTTL {
 		return nil, fmt.Errorf("invalid operation type: %d", opType)
 	}
 
@@ -215,12 +220,19 @@ func DeserializeWALEntry(payload []byte) (*wal.Entry, error) {
 	copy(key, payload[offset:offset+int(keyLen)])
 	offset += int(keyLen)
 
+	// Split off the column family
+	_, family, key, err := wal.DecodeFamily(encodedType, key)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create entry with default nil value
 	entry := &wal.Entry{
 		SequenceNumber: seqNum,
 		Type:           opType,
 		Key:            key,
 		Value:          nil,
+		Family:         family,
 	}
 
 	// Show key as string if it's likely printable
@@ -2,8 +2,10 @@ package replication
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/KevoDB/kevo/pkg/common/log"
+	"github.com/KevoDB/kevo/pkg/common/ttl"
 	"github.com/KevoDB/kevo/pkg/engine/interfaces"
 	"github.com/KevoDB/kevo/pkg/wal"
 )
@@ -27,6 +29,12 @@ func (e *EngineApplier) Apply(entry *wal.Entry) error {
 	log.Info("Replica applying WAL entry through engine API: seq=%d, type=%d, key=%s",
 		entry.SequenceNumber, entry.Type, string(entry.Key))
 
+	// Entries of other column families are applied as single-entry batches,
+	// which route them by their family
+	if entry.Family != wal.DefaultFamily {
+		return e.applyToColumnFamily(entry)
+	}
+
 	// Check if engine is in read-only mode
 	isReadOnly := false
 	if checker, ok := e.engine.(interface{ IsReadOnly() bool }); ok {
@@ -81,14 +89,45 @@ func (e *EngineApplier) applyInReadOnlyMode(entry *wal.Entry) error {
 		return e.engine.Delete(entry.Key)
 
 	case wal.OpTypeMerge:
-		// Handle merge as a put operation for compatibility
+		// Try internal interface first
+		if merger, ok := e.engine.(interface {
+			MergeInternal(key, operand []byte) error
+		}); ok {
+			return merger.MergeInternal(entry.Key, entry.Value)
+		}
+
+		// Try temporarily disabling read-only mode
 		if setter, ok := e.engine.(interface{ SetReadOnly(bool) }); ok {
 			setter.SetReadOnly(false)
-			err := e.engine.Put(entry.Key, entry.Value)
+			err := e.engine.Merge(entry.Key, entry.Value)
 			setter.SetReadOnly(true)
 			return err
 		}
-		return e.engine.Put(entry.Key, entry.Value)
+
+		// Fall back to normal operation which may fail
+		return e.engine.Merge(entry.Key, entry.Value)
+
+	case wal.OpTypeDeleteRange:
+		// Try internal interface first
+		if deleter, ok := e.engine.(interface {
+			DeleteRangeInternal(startKey, endKey []byte) error
+		}); ok {
+			return deleter.DeleteRangeInternal(entry.Key, entry.Value)
+		}
+
+		// Try temporarily disabling read-only mode
+		if setter, ok := e.engine.(interface{ SetReadOnly(bool) }); ok {
+			setter.SetReadOnly(false)
+			err := e.engine.DeleteRange(entry.Key, entry.Value)
+			setter.SetReadOnly(true)
+			return err
+		}
+
+		// Fall back to normal operation which may fail
+		return e.engine.DeleteRange(entry.Key, entry.Value)
+
+	case wal.OpTypePutWithTTL:
+		return e.applyPutWithTTL(entry, true)
 
 	default:
 		return fmt.Errorf("unsupported WAL entry type: %d", entry.Type)
@@ -107,14 +146,70 @@ func (e *EngineApplier) applyInNormalMode(entry *wal.Entry) error {
 		return e.engine.Delete(entry.Key)
 
 	case wal.OpTypeMerge:
-		// Handle merge as a put operation for compatibility
-		return e.engine.Put(entry.Key, entry.Value)
+		return e.engine.Merge(entry.Key, entry.Value)
+
+	case wal.OpTypeDeleteRange:
+		return e.engine.DeleteRange(entry.Key, entry.Value)
+
+	case wal.OpTypePutWithTTL:
+		return e.applyPutWithTTL(entry, false)
 
 	default:
 		return fmt.Errorf("unsupported WAL entry type: %d", entry.Type)
 	}
 }
 
+// applyPutWithTTL applies an expiring write with the expiry time chosen by the
+// primary, so that every replica expires the key at the same moment whatever
+// its own clock says
+func (e *EngineApplier) applyPutWithTTL(entry *wal.Entry, readOnly bool) error {
+	expireAt, value, err := ttl.Decode(entry.Value)
+	if err != nil {
+		return fmt.Errorf("invalid expiring WAL entry: %w", err)
+	}
+
+	// Try internal interface first
+	if putter, ok := e.engine.(interface {
+		PutWithExpiryInternal(key, value []byte, expireAt uint64) error
+	}); ok {
+		return putter.PutWithExpiryInternal(entry.Key, value, expireAt)
+	}
+
+	// Otherwise convert the expiry time back into a TTL. A key that has
+	// already expired still has to hide its older versions.
+	put := func() error {
+		remaining := time.Duration(int64(expireAt) - time.Now().UnixNano())
+		if remaining <= 0 {
+			return e.engine.Delete(entry.Key)
+		}
+		return e.engine.PutWithTTL(entry.Key, value, remaining)
+	}
+
+	// Try temporarily disabling read-only mode
+	if setter, ok := e.engine.(interface{ SetReadOnly(bool) }); ok && readOnly {
+		setter.SetReadOnly(false)
+		err := put()
+		setter.SetReadOnly(true)
+		return err
+	}
+
+	// Fall back to normal operation which may fail
+	return put()
+}
+
+// applyToColumnFamily applies an entry of a column family other than the
+// default one. The replica must have created the same column families as the
+// primary.
+func (e *EngineApplier) applyToColumnFamily(entry *wal.Entry) error {
+	if applier, ok := e.engine.(interface {
+		ApplyBatchInternal(entries []*wal.Entry) error
+	}); ok {
+		return applier.ApplyBatchInternal([]*wal.Entry{entry})
+	}
+
+	return fmt.Errorf("engine does not support column families, cannot apply entry of column family %d", entry.Family)
+}
+
 // Sync ensures all applied entries are persisted
 func (e *EngineApplier) Sync() error {
 	// Force a flush of in-memory tables to ensure durability
@@ -2,6 +2,7 @@ package replication
 
 import (
 	"testing"
+	"time"
 
 	"github.com/KevoDB/kevo/pkg/common/iterator"
 	"github.com/KevoDB/kevo/pkg/engine/interfaces"
@@ -36,6 +37,10 @@ func (m *MockEngine) Put(key, value []byte) error {
 	return nil
 }
 
+func (m *MockEngine) PutWithTTL(key, value []byte, ttl time.Duration) error {
+	return nil
+}
+
 func (m *MockEngine) Get(key []byte) ([]byte, error) {
 	return nil, nil
 }
@@ -44,6 +49,14 @@ func (m *MockEngine) Delete(key []byte) error {
 	return nil
 }
 
+func (m *MockEngine) Merge(key, operand []byte) error {
+	return nil
+}
+
+func (m *MockEngine) DeleteRange(startKey, endKey []byte) error {
+	return nil
+}
+
 func (m *MockEngine) IsDeleted(key []byte) (bool, error) {
 	return false, nil
 }
@@ -60,6 +73,22 @@ func (m *MockEngine) ApplyBatch(entries []*wal.Entry) error {
 	return nil
 }
 
+func (m *MockEngine) CompareAndSwap(key, expected, value []byte) (interfaces.ConditionResult, error) {
+	return interfaces.ConditionResult{Applied: true, Failed: -1}, nil
+}
+
+func (m *MockEngine) PutIfAbsent(key, value []byte) (interfaces.ConditionResult, error) {
+	return interfaces.ConditionResult{Applied: true, Failed: -1}, nil
+}
+
+func (m *MockEngine) DeleteIfEquals(key, expected []byte) (interfaces.ConditionResult, error) {
+	return interfaces.ConditionResult{Applied: true, Failed: -1}, nil
+}
+
+func (m *MockEngine) ApplyBatchIf(entries []*wal.Entry, conditions []interfaces.Precondition) (interfaces.ConditionResult, error) {
+	return interfaces.ConditionResult{Applied: true, Failed: -1}, nil
+}
+
 func (m *MockEngine) BeginTransaction(readOnly bool) (interfaces.Transaction, error) {
 	return nil, nil
 }
@@ -16,6 +16,7 @@ type Builder struct {
 	restartCount  uint32
 	currentSize   uint32
 	lastKey       []byte
+	lastSeq       uint64
 	restartIdx    int
 }
 
@@ -36,19 +37,57 @@ func (b *Builder) Add(key, value []byte) error {
 }
 
 // AddWithSequence adds a key-value pair to the block with a sequence number
-// Keys must be added in sorted order
+// Keys must be added in sorted order. Multiple versions of the same key may be
+// added as long as they are ordered by decreasing sequence number. The writes of
+// a batch share a sequence number and are added newest first.
 func (b *Builder) AddWithSequence(key, value []byte, seqNum uint64) error {
-	// Ensure keys are added in sorted order
-	if len(b.entries) > 0 && bytes.Compare(key, b.lastKey) <= 0 {
-		return fmt.Errorf("keys must be added in strictly increasing order, got %s after %s",
-			string(key), string(b.lastKey))
+	return b.add(key, value, seqNum, false, 0)
+}
+
+// AddMergeWithSequence adds a merge operand for a key to the block with a sequence number.
+// The same ordering rules as AddWithSequence apply.
+func (b *Builder) AddMergeWithSequence(key, operand []byte, seqNum uint64) error {
+	if operand == nil {
+		// A nil value would be encoded as a tombstone
+		operand = []byte{}
+	}
+	return b.add(key, operand, seqNum, true, 0)
+}
+
+// AddExpiringWithSequence adds a key-value pair that expires at expireAt to the
+// block with a sequence number. The same ordering rules as AddWithSequence apply.
+func (b *Builder) AddExpiringWithSequence(key, value []byte, seqNum, expireAt uint64) error {
+	if value == nil {
+		// A nil value would be encoded as a tombstone
+		value = []byte{}
+	}
+	return b.add(key, value, seqNum, false, expireAt)
+}
+
+// add appends an entry to the block
+func (b *Builder) add(key, value []byte, seqNum uint64, mergeOperand bool, expireAt uint64) error {
+	// Ensure keys are added in sorted order. Entries without a sequence number
+	// can't repeat a key.
+	if len(b.entries) > 0 {
+		cmp := bytes.Compare(key, b.lastKey)
+		if cmp < 0 || (cmp == 0 && (seqNum > b.lastSeq || seqNum == 0)) {
+			return fmt.Errorf("keys must be added in strictly increasing order, got %s after %s",
+				string(key), string(b.lastKey))
+		}
 	}
 
-	b.entries = append(b.entries, Entry{
-		Key:         append([]byte(nil), key...),   // Make copies to avoid references
-		Value:       append([]byte(nil), value...), // to external data
-		SequenceNum: seqNum,
-	})
+	entry := Entry{
+		Key:          append([]byte(nil), key...),   // Make copies to avoid references
+		Value:        append([]byte(nil), value...), // to external data
+		SequenceNum:  seqNum,
+		MergeOperand: mergeOperand,
+		ExpireAt:     expireAt,
+	}
+	if value != nil && entry.Value == nil {
+		// Only a nil value is a tombstone, not an empty one
+		entry.Value = []byte{}
+	}
+	b.entries = append(b.entries, entry)
 
 	// Add restart point if needed
 	if b.restartIdx == 0 || b.restartIdx >= RestartInterval {
@@ -59,7 +98,11 @@ func (b *Builder) AddWithSequence(key, value []byte, seqNum uint64) error {
 
 	// Track the size
 	b.currentSize += uint32(len(key) + len(value) + 16) // 16 bytes for metadata (including sequence number)
+	if expireAt != 0 {
+		b.currentSize += 8
+	}
 	b.lastKey = append([]byte(nil), key...)
+	b.lastSeq = seqNum
 
 	return nil
 }
@@ -76,6 +119,7 @@ func (b *Builder) Reset() {
 	b.restartCount = 0
 	b.currentSize = 0
 	b.lastKey = nil
+	b.lastSeq = 0
 	b.restartIdx = 0
 }
 
@@ -93,38 +137,22 @@ func (b *Builder) Entries() int {
 	return len(b.entries)
 }
 
+// EmptyBlock returns the serialized form of a block with no entries
+func EmptyBlock() []byte {
+	data := make([]byte, BlockFooterSize)
+	// No restart points, just the checksum of the restart count
+	binary.LittleEndian.PutUint64(data[4:], xxhash.Sum64(data[:4]))
+	return data
+}
+
 // Finish serializes the block to a writer
 func (b *Builder) Finish(w io.Writer) (uint64, error) {
 	if len(b.entries) == 0 {
 		return 0, fmt.Errorf("cannot finish empty block")
 	}
 
-	// Keys are already sorted by the Add method's requirement
-
-	// Remove any duplicate keys (keeping the one with the highest sequence number)
-	if len(b.entries) > 1 {
-		// Group entries by key and find entry with highest sequence number
-		keyMap := make(map[string]Entry)
-		for _, entry := range b.entries {
-			keyStr := string(entry.Key)
-			if existing, exists := keyMap[keyStr]; !exists || entry.SequenceNum > existing.SequenceNum {
-				keyMap[keyStr] = entry
-			}
-		}
-
-		// Rebuild sorted entries from the map
-		uniqueEntries := make([]Entry, 0, len(keyMap))
-		for _, entry := range b.entries {
-			keyStr := string(entry.Key)
-			if best, exists := keyMap[keyStr]; exists {
-				if bytes.Equal(entry.Key, best.Key) && entry.SequenceNum == best.SequenceNum {
-					uniqueEntries = append(uniqueEntries, best)
-					delete(keyMap, keyStr) // Delete to avoid duplicates
-				}
-			}
-		}
-		b.entries = uniqueEntries
-	}
+	// Keys are already sorted by the Add method's requirement, with any
+	// older versions of a key following the newest one
 
 	// Reset restart points
 	b.restartPoints = b.restartPoints[:0]
@@ -210,11 +238,25 @@ func (b *Builder) Finish(w io.Writer) (uint64, error) {
 		} else {
 			// Regular value - write length followed by value
 			valueLen := uint32(len(entry.Value))
+			if entry.MergeOperand {
+				valueLen |= MergeOperandFlag
+			}
+			if entry.ExpireAt != 0 {
+				// The expiry time is stored as part of the value
+				valueLen = (valueLen + 8) | ExpiringValueFlag
+			}
 			err = binary.Write(buffer, binary.LittleEndian, valueLen)
 			if err != nil {
 				return 0, fmt.Errorf("failed to write value length: %w", err)
 			}
 
+			if entry.ExpireAt != 0 {
+				err = binary.Write(buffer, binary.LittleEndian, entry.ExpireAt)
+				if err != nil {
+					return 0, fmt.Errorf("failed to write expiry time: %w", err)
+				}
+			}
+
 			n, err := buffer.Write(entry.Value)
 			if err != nil {
 				return 0, fmt.Errorf("failed to write value: %w", err)
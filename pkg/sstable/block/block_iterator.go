@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"sort"
 )
 
 // validateDeltaEncoding validates delta encoding parameters to prevent buffer overruns
@@ -42,9 +43,12 @@ func validateDeltaEncoding(sharedLen, unsharedLen uint16, currentKey, data []byt
 type Iterator struct {
 	reader        *Reader
 	currentPos    uint32
+	currentStart  uint32 // Position where the current entry starts
 	currentKey    []byte
 	currentVal    []byte
 	currentSeqNum uint64 // Sequence number of the current entry
+	currentMerge  bool   // Whether the current entry is a merge operand
+	currentExpiry uint64 // Expiry time of the current entry, 0 if it never expires
 	restartIdx    int
 	initialized   bool
 	dataEnd       uint32 // Position where the actual entries data ends (before restart points)
@@ -124,10 +128,12 @@ func (it *Iterator) Seek(target []byte) bool {
 		return false
 	}
 
-	// Binary search through restart points
+	// Binary search for the last restart point whose key is < target. Entries
+	// before it are all < target, and the first key >= target must be at or
+	// after it (multiple versions of a key may straddle a restart point).
 	left, right := 0, len(it.reader.restartPoints)-1
 	for left < right {
-		mid := (left + right) / 2
+		mid := (left + right + 1) / 2
 		it.restartIdx = mid
 		it.currentPos = it.reader.restartPoints[mid]
 
@@ -137,9 +143,9 @@ func (it *Iterator) Seek(target []byte) bool {
 		}
 
 		if bytes.Compare(key, target) < 0 {
-			left = mid + 1
+			left = mid
 		} else {
-			right = mid
+			right = mid - 1
 		}
 	}
 
@@ -151,42 +157,28 @@ func (it *Iterator) Seek(target []byte) bool {
 	// First check the current position
 	key, val, ok := it.decodeCurrent()
 	if !ok {
+		it.currentKey = nil
+		it.currentVal = nil
 		return false
 	}
 
-	// If the key at this position is already >= target, we're done
-	if bytes.Compare(key, target) >= 0 {
-		it.currentKey = key
-		it.currentVal = val
-		return true
-	}
-
-	// Otherwise, scan forward until we find the first key >= target
-	for {
-		savePos := it.currentPos
+	// Scan forward until we find the first key >= target
+	for bytes.Compare(key, target) < 0 {
 		key, val, ok = it.decodeNext()
 		if !ok {
-			// Restore position to the last valid entry
-			it.currentPos = savePos
-			key, val, ok = it.decodeCurrent()
-			if ok {
-				it.currentKey = key
-				it.currentVal = val
-				return true
-			}
+			// Every key in the block is < target
+			it.currentKey = nil
+			it.currentVal = nil
 			return false
 		}
 
-		if bytes.Compare(key, target) >= 0 {
-			it.currentKey = key
-			it.currentVal = val
-			return true
-		}
-
-		// Update current key/value for the next iteration
 		it.currentKey = key
 		it.currentVal = val
 	}
+
+	it.currentKey = key
+	it.currentVal = val
+	return true
 }
 
 // Next advances the iterator to the next entry
@@ -212,6 +204,45 @@ func (it *Iterator) Next() bool {
 	return true
 }
 
+// Prev moves the iterator back to the previous entry
+func (it *Iterator) Prev() bool {
+	if !it.Valid() {
+		return false
+	}
+
+	// Keys are delta encoded from their restart point, so decode forward from
+	// the last restart point before the current entry
+	target := it.currentStart
+	idx := sort.Search(len(it.reader.restartPoints), func(i int) bool {
+		return it.reader.restartPoints[i] >= target
+	}) - 1
+	if idx < 0 {
+		// The current entry is the first one in the block
+		it.currentKey = nil
+		it.currentVal = nil
+		return false
+	}
+
+	it.restartIdx = idx
+	it.currentPos = it.reader.restartPoints[idx]
+
+	key, val, ok := it.decodeCurrent()
+	for ok && it.currentPos < target {
+		key, val, ok = it.decodeNext()
+		it.currentKey = key
+		it.currentVal = val
+	}
+	if !ok {
+		it.currentKey = nil
+		it.currentVal = nil
+		return false
+	}
+
+	it.currentKey = key
+	it.currentVal = val
+	return true
+}
+
 // Key returns the current key
 func (it *Iterator) Key() []byte {
 	return it.currentKey
@@ -233,6 +264,19 @@ func (it *Iterator) IsTombstone() bool {
 	return it.Valid() && it.currentVal == nil
 }
 
+// IsMergeOperand returns true if the current entry is a merge operand
+func (it *Iterator) IsMergeOperand() bool {
+	return it.Valid() && it.currentMerge
+}
+
+// ExpireAt returns the expiry time of the current entry, or 0 if it never expires
+func (it *Iterator) ExpireAt() uint64 {
+	if !it.Valid() {
+		return 0
+	}
+	return it.currentExpiry
+}
+
 // SequenceNumber returns the sequence number of the current entry
 func (it *Iterator) SequenceNumber() uint64 {
 	if !it.Valid() {
@@ -241,7 +285,8 @@ func (it *Iterator) SequenceNumber() uint64 {
 	return it.currentSeqNum
 }
 
-// decodeCurrent decodes the entry at the current position
+// decodeCurrent decodes the full-key entry at the current position (a restart point)
+// and advances the position past it
 func (it *Iterator) decodeCurrent() ([]byte, []byte, bool) {
 	if it.currentPos >= it.dataEnd {
 		return nil, nil, false
@@ -262,12 +307,14 @@ func (it *Iterator) decodeCurrent() ([]byte, []byte, bool) {
 	key := make([]byte, keyLen)
 	copy(key, data[:keyLen])
 	data = data[keyLen:]
+	entrySize := 2 + uint32(keyLen)
 
 	// Read sequence number if format includes it (check if enough data for both seq num and value len)
 	seqNum := uint64(0)
 	if len(data) >= 12 { // 8 for seq num + 4 for value len
 		seqNum = binary.LittleEndian.Uint64(data)
 		data = data[8:]
+		entrySize += 8
 	}
 
 	// Read value
@@ -278,7 +325,20 @@ func (it *Iterator) decodeCurrent() ([]byte, []byte, bool) {
 	valueLen := binary.LittleEndian.Uint32(data)
 	data = data[4:]
 
+	mergeOperand := valueLen != TombstoneValueLengthMarker && valueLen&MergeOperandFlag != 0
+	if mergeOperand {
+		valueLen &^= MergeOperandFlag
+	}
+	expiring := valueLen != TombstoneValueLengthMarker && valueLen&ExpiringValueFlag != 0
+	if expiring {
+		valueLen &^= ExpiringValueFlag
+		if valueLen < 8 {
+			return nil, nil, false
+		}
+	}
+
 	var value []byte
+	var expireAt uint64
 	if valueLen == TombstoneValueLengthMarker {
 		// This is a tombstone - value remains nil
 		value = nil
@@ -290,11 +350,24 @@ func (it *Iterator) decodeCurrent() ([]byte, []byte, bool) {
 
 		value = make([]byte, valueLen)
 		copy(value, data[:valueLen])
+		if expiring {
+			expireAt = binary.LittleEndian.Uint64(value)
+			value = value[8:]
+		}
+		entrySize += valueLen
 	}
+	entrySize += 4
 
 	it.currentKey = key
 	it.currentVal = value
+	it.currentStart = it.currentPos
 	it.currentSeqNum = seqNum
+	it.currentMerge = mergeOperand
+	it.currentExpiry = expireAt
+
+	// Leave the position just past this entry so that decodeNext continues
+	// with the following one
+	it.currentPos += entrySize
 
 	return key, value, true
 }
@@ -305,6 +378,7 @@ func (it *Iterator) decodeNext() ([]byte, []byte, bool) {
 		return nil, nil, false
 	}
 
+	start := it.currentPos
 	data := it.reader.data[it.currentPos:]
 	var key []byte
 
@@ -376,7 +450,20 @@ func (it *Iterator) decodeNext() ([]byte, []byte, bool) {
 	valueLen := binary.LittleEndian.Uint32(data)
 	data = data[4:]
 
+	mergeOperand := valueLen != TombstoneValueLengthMarker && valueLen&MergeOperandFlag != 0
+	if mergeOperand {
+		valueLen &^= MergeOperandFlag
+	}
+	expiring := valueLen != TombstoneValueLengthMarker && valueLen&ExpiringValueFlag != 0
+	if expiring {
+		valueLen &^= ExpiringValueFlag
+		if valueLen < 8 {
+			return nil, nil, false
+		}
+	}
+
 	var value []byte
+	var expireAt uint64
 	if valueLen == TombstoneValueLengthMarker {
 		// This is a tombstone - value remains nil
 		value = nil
@@ -388,9 +475,16 @@ func (it *Iterator) decodeNext() ([]byte, []byte, bool) {
 
 		value = make([]byte, valueLen)
 		copy(value, data[:valueLen])
+		if expiring {
+			expireAt = binary.LittleEndian.Uint64(value)
+			value = value[8:]
+		}
 	}
 
+	it.currentStart = start
 	it.currentSeqNum = seqNum
+	it.currentMerge = mergeOperand
+	it.currentExpiry = expireAt
 
 	// Update position - tombstones only advance by 4 bytes (value length marker)
 	if valueLen == TombstoneValueLengthMarker {
@@ -55,6 +55,11 @@ func NewReader(data []byte) (*Reader, error) {
 	return reader, nil
 }
 
+// Size returns the size of the serialized block in bytes
+func (r *Reader) Size() int {
+	return len(r.data)
+}
+
 // Iterator returns an iterator for the block
 func (r *Reader) Iterator() *Iterator {
 	// Calculate the data end position (everything before the restart points array)
@@ -368,3 +368,344 @@ func TestBlockReset(t *testing.T) {
 		t.Errorf("Expected 0 size after reset, got %d", builder.EstimatedSize())
 	}
 }
+
+func TestBlockSeekEveryKey(t *testing.T) {
+	builder := NewBuilder()
+
+	numEntries := 100
+	for i := 0; i < numEntries; i++ {
+		key := []byte(fmt.Sprintf("key%03d", i*2))
+		if err := builder.Add(key, []byte(fmt.Sprintf("value%03d", i*2))); err != nil {
+			t.Fatalf("Failed to add entry: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := builder.Finish(&buf); err != nil {
+		t.Fatalf("Failed to finish block: %v", err)
+	}
+
+	reader, err := NewReader(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to create block reader: %v", err)
+	}
+
+	iter := reader.Iterator()
+	for i := 0; i < numEntries*2; i++ {
+		target := fmt.Sprintf("key%03d", i)
+
+		// Existing keys are found exactly, missing ones land on the next key
+		expected := fmt.Sprintf("key%03d", i+i%2)
+		if !iter.Seek([]byte(target)) {
+			if i+i%2 < numEntries*2 {
+				t.Errorf("Seek(%s) failed, expected %s", target, expected)
+			}
+			continue
+		}
+		if string(iter.Key()) != expected {
+			t.Errorf("Seek(%s): expected %s, got %s", target, expected, iter.Key())
+		}
+	}
+
+	// Seeking past the last key finds nothing
+	if iter.Seek([]byte("key999")) {
+		t.Errorf("Expected Seek past the last key to fail, got %s", iter.Key())
+	}
+
+	// Iteration visits every entry exactly once
+	count := 0
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		count++
+	}
+	if count != numEntries {
+		t.Errorf("Expected %d entries during iteration, got %d", numEntries, count)
+	}
+}
+
+func TestBlockPrev(t *testing.T) {
+	builder := NewBuilder()
+
+	// Enough entries to span several restart points
+	numEntries := 100
+	for i := 0; i < numEntries; i++ {
+		key := []byte(fmt.Sprintf("key%03d", i))
+		if err := builder.Add(key, []byte(fmt.Sprintf("value%03d", i))); err != nil {
+			t.Fatalf("Failed to add entry: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := builder.Finish(&buf); err != nil {
+		t.Fatalf("Failed to finish block: %v", err)
+	}
+
+	reader, err := NewReader(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to create block reader: %v", err)
+	}
+
+	// Iterating backwards visits every entry in reverse order
+	iter := reader.Iterator()
+	i := numEntries - 1
+	for iter.SeekToLast(); iter.Valid(); iter.Prev() {
+		expected := fmt.Sprintf("key%03d", i)
+		if string(iter.Key()) != expected {
+			t.Fatalf("Expected %s, got %s", expected, iter.Key())
+		}
+		if string(iter.Value()) != fmt.Sprintf("value%03d", i) {
+			t.Errorf("Expected value%03d for %s, got %s", i, expected, iter.Value())
+		}
+		i--
+	}
+	if i != -1 {
+		t.Errorf("Expected to visit %d entries, stopped at %d", numEntries, i)
+	}
+
+	// Prev and Next can be mixed
+	if !iter.Seek([]byte("key050")) || !iter.Prev() || string(iter.Key()) != "key049" {
+		t.Fatalf("Expected Prev from key050 to land on key049, got %s", iter.Key())
+	}
+	if !iter.Next() || string(iter.Key()) != "key050" {
+		t.Errorf("Expected Next to return to key050, got %s", iter.Key())
+	}
+
+	// Prev from the first entry invalidates the iterator
+	iter.SeekToFirst()
+	if iter.Prev() || iter.Valid() {
+		t.Errorf("Expected Prev from the first entry to fail")
+	}
+}
+
+func TestBlockBuilderMultipleVersions(t *testing.T) {
+	builder := NewBuilder()
+
+	// Versions of the same key must be added newest first
+	if err := builder.AddWithSequence([]byte("a"), []byte("a3"), 3); err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+	if err := builder.AddWithSequence([]byte("a"), []byte("a1"), 1); err != nil {
+		t.Fatalf("Failed to add older version: %v", err)
+	}
+	if err := builder.AddWithSequence([]byte("a"), []byte("a2"), 2); err == nil {
+		t.Fatalf("Expected error when adding versions out of order, but got none")
+	}
+	if err := builder.AddWithSequence([]byte("b"), nil, 4); err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := builder.Finish(&buf); err != nil {
+		t.Fatalf("Failed to finish block: %v", err)
+	}
+
+	reader, err := NewReader(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to create block reader: %v", err)
+	}
+
+	expected := []struct {
+		key   string
+		seq   uint64
+		value []byte
+	}{
+		{"a", 3, []byte("a3")},
+		{"a", 1, []byte("a1")},
+		{"b", 4, nil},
+	}
+
+	iter := reader.Iterator()
+	i := 0
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		if i >= len(expected) {
+			t.Fatalf("Unexpected extra entry %s", iter.Key())
+		}
+		if string(iter.Key()) != expected[i].key || iter.SequenceNumber() != expected[i].seq {
+			t.Errorf("Entry %d: expected %s@%d, got %s@%d",
+				i, expected[i].key, expected[i].seq, iter.Key(), iter.SequenceNumber())
+		}
+		if !bytes.Equal(iter.Value(), expected[i].value) {
+			t.Errorf("Entry %d: expected value %q, got %q", i, expected[i].value, iter.Value())
+		}
+		i++
+	}
+	if i != len(expected) {
+		t.Errorf("Expected %d entries, got %d", len(expected), i)
+	}
+
+	// Seek lands on the newest version
+	if !iter.Seek([]byte("a")) || iter.SequenceNumber() != 3 {
+		t.Errorf("Expected Seek to land on the newest version of a")
+	}
+
+	// Prev steps back through the versions in the opposite order
+	if !iter.Seek([]byte("b")) || !iter.Prev() || iter.SequenceNumber() != 1 {
+		t.Errorf("Expected Prev from b to land on the oldest version of a")
+	}
+	if !iter.Prev() || iter.SequenceNumber() != 3 {
+		t.Errorf("Expected Prev to land on the newest version of a")
+	}
+}
+
+func TestBlockBuilderBatchVersions(t *testing.T) {
+	builder := NewBuilder()
+
+	// The writes of a batch share a sequence number and are added newest first
+	if err := builder.AddMergeWithSequence([]byte("a"), []byte("+5"), 2); err != nil {
+		t.Fatalf("Failed to add merge operand: %v", err)
+	}
+	if err := builder.AddWithSequence([]byte("a"), []byte("10"), 2); err != nil {
+		t.Fatalf("Failed to add entry with the same sequence number: %v", err)
+	}
+
+	// Entries without a sequence number can't repeat a key
+	if err := builder.Add([]byte("b"), []byte("1")); err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+	if err := builder.Add([]byte("b"), []byte("2")); err == nil {
+		t.Fatalf("Expected error when adding a duplicate key, but got none")
+	}
+
+	var buf bytes.Buffer
+	if _, err := builder.Finish(&buf); err != nil {
+		t.Fatalf("Failed to finish block: %v", err)
+	}
+
+	reader, err := NewReader(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to create block reader: %v", err)
+	}
+
+	iter := reader.Iterator()
+	if !iter.Seek([]byte("a")) || !iter.IsMergeOperand() || string(iter.Value()) != "+5" {
+		t.Fatalf("Expected Seek to land on the newest write of the batch")
+	}
+	if !iter.Next() || iter.IsMergeOperand() || string(iter.Value()) != "10" || iter.SequenceNumber() != 2 {
+		t.Errorf("Expected the older write of the batch next")
+	}
+}
+
+func TestBlockBuilderMergeOperands(t *testing.T) {
+	builder := NewBuilder()
+
+	if err := builder.AddMergeWithSequence([]byte("a"), []byte("+2"), 3); err != nil {
+		t.Fatalf("Failed to add merge operand: %v", err)
+	}
+	if err := builder.AddWithSequence([]byte("a"), []byte("1"), 2); err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+	if err := builder.AddMergeWithSequence([]byte("b"), nil, 4); err != nil {
+		t.Fatalf("Failed to add empty merge operand: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := builder.Finish(&buf); err != nil {
+		t.Fatalf("Failed to finish block: %v", err)
+	}
+
+	reader, err := NewReader(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to create block reader: %v", err)
+	}
+
+	expected := []struct {
+		key     string
+		value   []byte
+		operand bool
+	}{
+		{"a", []byte("+2"), true},
+		{"a", []byte("1"), false},
+		{"b", []byte{}, true},
+	}
+
+	iter := reader.Iterator()
+	i := 0
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		if i >= len(expected) {
+			t.Fatalf("Unexpected extra entry %s", iter.Key())
+		}
+		want := expected[i]
+		if string(iter.Key()) != want.key || !bytes.Equal(iter.Value(), want.value) {
+			t.Errorf("Entry %d: expected %s=%q, got %s=%q", i, want.key, want.value, iter.Key(), iter.Value())
+		}
+		if iter.IsMergeOperand() != want.operand {
+			t.Errorf("Entry %d: expected merge operand %v, got %v", i, want.operand, iter.IsMergeOperand())
+		}
+		if iter.IsTombstone() {
+			t.Errorf("Entry %d: merge operand reported as a tombstone", i)
+		}
+		i++
+	}
+	if i != len(expected) {
+		t.Errorf("Expected %d entries, got %d", len(expected), i)
+	}
+
+	// The flag is decoded when seeking as well
+	if !iter.Seek([]byte("b")) || !iter.IsMergeOperand() {
+		t.Errorf("Expected Seek to land on the merge operand for b")
+	}
+}
+
+func TestBlockBuilderExpiringValues(t *testing.T) {
+	builder := NewBuilder()
+
+	if err := builder.AddExpiringWithSequence([]byte("a"), []byte("v2"), 3, 1000); err != nil {
+		t.Fatalf("Failed to add expiring entry: %v", err)
+	}
+	if err := builder.AddWithSequence([]byte("a"), []byte("v1"), 2); err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+	if err := builder.AddExpiringWithSequence([]byte("b"), nil, 4, 2000); err != nil {
+		t.Fatalf("Failed to add empty expiring entry: %v", err)
+	}
+	if err := builder.AddWithSequence([]byte("c"), nil, 5); err != nil {
+		t.Fatalf("Failed to add tombstone: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := builder.Finish(&buf); err != nil {
+		t.Fatalf("Failed to finish block: %v", err)
+	}
+
+	reader, err := NewReader(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to create block reader: %v", err)
+	}
+
+	expected := []struct {
+		key      string
+		value    []byte
+		expireAt uint64
+	}{
+		{"a", []byte("v2"), 1000},
+		{"a", []byte("v1"), 0},
+		{"b", []byte{}, 2000},
+		{"c", nil, 0},
+	}
+
+	iter := reader.Iterator()
+	i := 0
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		if i >= len(expected) {
+			t.Fatalf("Unexpected extra entry %s", iter.Key())
+		}
+		want := expected[i]
+		if string(iter.Key()) != want.key || !bytes.Equal(iter.Value(), want.value) {
+			t.Errorf("Entry %d: expected %s=%q, got %s=%q", i, want.key, want.value, iter.Key(), iter.Value())
+		}
+		if iter.ExpireAt() != want.expireAt {
+			t.Errorf("Entry %d: expected expiry %d, got %d", i, want.expireAt, iter.ExpireAt())
+		}
+		if iter.IsTombstone() != (want.value == nil) {
+			t.Errorf("Entry %d: expected tombstone %v, got %v", i, want.value == nil, iter.IsTombstone())
+		}
+		i++
+	}
+	if i != len(expected) {
+		t.Errorf("Expected %d entries, got %d", len(expected), i)
+	}
+
+	// The expiry time is decoded when seeking as well
+	if !iter.Seek([]byte("b")) || iter.ExpireAt() != 2000 {
+		t.Errorf("Expected Seek to land on the expiring entry for b")
+	}
+}
@@ -2,9 +2,11 @@ package block
 
 // Entry represents a key-value pair within the block
 type Entry struct {
-	Key         []byte
-	Value       []byte
-	SequenceNum uint64 // Sequence number for versioning
+	Key          []byte
+	Value        []byte
+	SequenceNum  uint64 // Sequence number for versioning
+	MergeOperand bool   // Whether the value is a merge operand
+	ExpireAt     uint64 // Expiry time of the value in Unix nanoseconds, 0 if it never expires
 }
 
 const (
@@ -18,4 +20,9 @@ const (
 	BlockFooterSize = 8 + 4 // 8 bytes for checksum, 4 for restart count
 	// TombstoneValueLengthMarker is used to mark tombstones in serialized blocks
 	TombstoneValueLengthMarker = uint32(0xFFFFFFFF)
+	// MergeOperandFlag is set in the serialized value length of merge operands
+	MergeOperandFlag = uint32(1 << 31)
+	// ExpiringValueFlag is set in the serialized value length of values with an
+	// expiry time, which is stored in the first 8 bytes of the value
+	ExpiringValueFlag = uint32(1 << 30)
 )
@@ -0,0 +1,194 @@
+package sstable
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+
+	"github.com/KevoDB/kevo/pkg/sstable/block"
+)
+
+// blockCacheShards is the number of independently locked parts of a BlockCache
+const blockCacheShards = 16
+
+// BlockCache is an LRU cache of data blocks shared by SSTable readers. Its
+// capacity is a budget in bytes that is split evenly between shards, each with
+// its own lock, so concurrent lookups rarely contend. Blocks are cached
+// uncompressed and charged their uncompressed size. A nil *BlockCache caches
+// nothing.
+type BlockCache struct {
+	capacity int64
+	shards   [blockCacheShards]blockCacheShard
+
+	// Source of the IDs that tell the blocks of different files apart
+	nextFileID atomic.Uint64
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+// BlockCacheStats describes the state of a BlockCache
+type BlockCacheStats struct {
+	Capacity  int64  // Budget in bytes
+	Usage     int64  // Bytes held by cached blocks
+	Entries   int    // Number of cached blocks
+	Hits      uint64 // Lookups that found the block
+	Misses    uint64 // Lookups that had to read the block from disk
+	Evictions uint64 // Blocks dropped to make room for others
+}
+
+type blockCacheKey struct {
+	fileID uint64
+	offset uint64
+}
+
+type blockCacheEntry struct {
+	key   blockCacheKey
+	block *block.Reader
+	size  int64
+}
+
+type blockCacheShard struct {
+	mu       sync.Mutex
+	capacity int64
+	usage    int64
+	entries  map[blockCacheKey]*list.Element
+	lru      *list.List // Most recently used at the front
+}
+
+// NewBlockCache creates a block cache holding up to capacity bytes of blocks
+func NewBlockCache(capacity int64) *BlockCache {
+	if capacity < 0 {
+		capacity = 0
+	}
+
+	c := &BlockCache{capacity: capacity}
+	for i := range c.shards {
+		c.shards[i].capacity = capacity / blockCacheShards
+		c.shards[i].entries = make(map[blockCacheKey]*list.Element)
+		c.shards[i].lru = list.New()
+	}
+	return c
+}
+
+// newFileID returns an ID that no other file using the cache has
+func (c *BlockCache) newFileID() uint64 {
+	if c == nil {
+		return 0
+	}
+	return c.nextFileID.Add(1)
+}
+
+// shard returns the shard holding key
+func (c *BlockCache) shard(key blockCacheKey) *blockCacheShard {
+	h := key.fileID*0x9E3779B97F4A7C15 ^ key.offset
+	h ^= h >> 29
+	return &c.shards[h%blockCacheShards]
+}
+
+// Get returns the block at offset in the file, marking it as recently used
+func (c *BlockCache) Get(fileID, offset uint64) (*block.Reader, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	key := blockCacheKey{fileID: fileID, offset: offset}
+	s := c.shard(key)
+
+	s.mu.Lock()
+	elem, found := s.entries[key]
+	if found {
+		s.lru.MoveToFront(elem)
+	}
+	s.mu.Unlock()
+
+	if !found {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return elem.Value.(*blockCacheEntry).block, true
+}
+
+// Put adds the block at offset in the file, evicting the least recently used
+// blocks of its shard until it fits. Blocks larger than a shard aren't cached.
+func (c *BlockCache) Put(fileID, offset uint64, blk *block.Reader, size int64) {
+	if c == nil {
+		return
+	}
+
+	key := blockCacheKey{fileID: fileID, offset: offset}
+	s := c.shard(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if size > s.capacity {
+		return
+	}
+
+	if elem, found := s.entries[key]; found {
+		entry := elem.Value.(*blockCacheEntry)
+		s.usage += size - entry.size
+		entry.block = blk
+		entry.size = size
+		s.lru.MoveToFront(elem)
+	} else {
+		s.entries[key] = s.lru.PushFront(&blockCacheEntry{key: key, block: blk, size: size})
+		s.usage += size
+	}
+
+	for s.usage > s.capacity {
+		s.remove(s.lru.Back())
+		c.evictions.Add(1)
+	}
+}
+
+// removeFile drops every block of a file, which is done when the file is
+// closed since its blocks can't be looked up any more
+func (c *BlockCache) removeFile(fileID uint64) {
+	if c == nil {
+		return
+	}
+
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		for key, elem := range s.entries {
+			if key.fileID == fileID {
+				s.remove(elem)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// remove drops an entry from the shard. The caller must hold the shard lock.
+func (s *blockCacheShard) remove(elem *list.Element) {
+	entry := s.lru.Remove(elem).(*blockCacheEntry)
+	delete(s.entries, entry.key)
+	s.usage -= entry.size
+}
+
+// Stats returns the usage and hit, miss and eviction counts of the cache
+func (c *BlockCache) Stats() BlockCacheStats {
+	if c == nil {
+		return BlockCacheStats{}
+	}
+
+	stats := BlockCacheStats{
+		Capacity:  c.capacity,
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		stats.Usage += s.usage
+		stats.Entries += len(s.entries)
+		s.mu.Unlock()
+	}
+	return stats
+}
@@ -0,0 +1,116 @@
+package sstable
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestBlockCacheEviction(t *testing.T) {
+	cache := NewBlockCache(blockCacheShards * 100)
+	fileID := cache.newFileID()
+
+	// Keep block 0 recently used while the cache overflows
+	cache.Put(fileID, 0, nil, 40)
+	for offset := uint64(1); offset <= 100; offset++ {
+		cache.Put(fileID, offset, nil, 40)
+		if _, found := cache.Get(fileID, 0); !found {
+			t.Fatalf("Recently used block was evicted after adding block %d", offset)
+		}
+	}
+
+	stats := cache.Stats()
+	if stats.Usage > stats.Capacity {
+		t.Errorf("Usage %d exceeds capacity %d", stats.Usage, stats.Capacity)
+	}
+	if stats.Usage != int64(stats.Entries)*40 {
+		t.Errorf("Expected usage %d for %d entries, got %d", stats.Entries*40, stats.Entries, stats.Usage)
+	}
+	if stats.Evictions != uint64(101-stats.Entries) {
+		t.Errorf("Expected %d evictions, got %d", 101-stats.Entries, stats.Evictions)
+	}
+
+	// Blocks larger than a shard are never cached
+	cache.Put(fileID, 1000, nil, 101)
+	if _, found := cache.Get(fileID, 1000); found {
+		t.Error("Expected oversized block not to be cached")
+	}
+
+	// Other files don't see the blocks of this one
+	if _, found := cache.Get(cache.newFileID(), 0); found {
+		t.Error("Expected blocks to be cached per file")
+	}
+}
+
+func TestReaderBlockCache(t *testing.T) {
+	tempDir := t.TempDir()
+	sstablePath := filepath.Join(tempDir, "test.sst")
+
+	writer, err := NewWriter(sstablePath)
+	if err != nil {
+		t.Fatalf("Failed to create SSTable writer: %v", err)
+	}
+	for i := 0; i < 2000; i++ {
+		key := fmt.Sprintf("key%05d", i)
+		value := fmt.Sprintf("value%05d", i)
+		if err := writer.Add([]byte(key), []byte(value)); err != nil {
+			t.Fatalf("Failed to add entry: %v", err)
+		}
+	}
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish SSTable: %v", err)
+	}
+
+	cache := NewBlockCache(16 * 1024 * 1024)
+	reader, err := OpenReaderWithOptions(sstablePath, ReaderOptions{BlockCache: cache})
+	if err != nil {
+		t.Fatalf("Failed to open SSTable: %v", err)
+	}
+
+	numBlocks := 0
+	indexIter := reader.indexBlock.Iterator()
+	for indexIter.SeekToFirst(); indexIter.Valid(); indexIter.Next() {
+		numBlocks++
+	}
+	if numBlocks < 2 {
+		t.Fatalf("Expected several blocks, got %d", numBlocks)
+	}
+
+	// Opening the reader caches the last block to find the largest key
+	if entries := cache.Stats().Entries; entries != 1 {
+		t.Fatalf("Expected 1 cached block after opening, got %d", entries)
+	}
+
+	// A scan that doesn't fill the cache leaves it as it was
+	iter := reader.NewIteratorWithOptions(ReadOptions{FillCache: false})
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+	}
+	if entries := cache.Stats().Entries; entries != 1 {
+		t.Errorf("Expected scan without fill cache to cache nothing, got %d blocks", entries)
+	}
+
+	// A default scan caches every block, and a second one reads them all from the cache
+	for pass := 0; pass < 2; pass++ {
+		iter = reader.NewIterator()
+		for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		}
+	}
+	stats := cache.Stats()
+	if stats.Entries != numBlocks {
+		t.Errorf("Expected %d cached blocks, got %d", numBlocks, stats.Entries)
+	}
+	if stats.Hits < uint64(numBlocks) {
+		t.Errorf("Expected at least %d hits, got %d", numBlocks, stats.Hits)
+	}
+
+	value, err := reader.Get([]byte("key01000"))
+	if err != nil || string(value) != "value01000" {
+		t.Errorf("Expected value01000, got %q (%v)", value, err)
+	}
+
+	// Closing the reader drops its blocks
+	reader.Close()
+	if stats := cache.Stats(); stats.Entries != 0 || stats.Usage != 0 {
+		t.Errorf("Expected empty cache after close, got %d blocks using %d bytes", stats.Entries, stats.Usage)
+	}
+}
@@ -0,0 +1,45 @@
+package sstable
+
+import (
+	"fmt"
+
+	"github.com/KevoDB/kevo/pkg/sstable/compression"
+)
+
+// minCompressionSavings is the fraction of a block compression has to save,
+// as a divisor, for the block to be stored compressed
+const minCompressionSavings = 8
+
+// encodeBlock compresses a serialized block and appends the compression type
+// byte. Blocks that don't shrink by at least 1/8 are stored uncompressed, as
+// decompressing them would cost more than it saves.
+func encodeBlock(data []byte, codec compression.Type, level int) ([]byte, error) {
+	if codec != compression.None {
+		compressed, err := compression.Compress(codec, level, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compress block: %w", err)
+		}
+		if len(compressed) < len(data)-len(data)/minCompressionSavings {
+			return append(compressed, byte(codec)), nil
+		}
+	}
+
+	encoded := make([]byte, len(data)+1)
+	copy(encoded, data)
+	encoded[len(data)] = byte(compression.None)
+	return encoded, nil
+}
+
+// decodeBlock reverses encodeBlock
+func decodeBlock(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("block has no compression type: %w", ErrCorruption)
+	}
+
+	codec := compression.Type(data[len(data)-1])
+	decoded, err := compression.Decompress(codec, data[:len(data)-1])
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrCorruption)
+	}
+	return decoded, nil
+}
@@ -0,0 +1,163 @@
+// Package compression implements the codecs used to compress SSTable blocks.
+package compression
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Type identifies the codec a block is compressed with. The values are stored
+// on disk and must not change.
+type Type uint8
+
+const (
+	// None stores blocks uncompressed
+	None Type = 0
+	// Snappy favors speed over compression ratio
+	Snappy Type = 1
+	// Zstd gives the best compression ratio at a higher CPU cost
+	Zstd Type = 2
+	// LZ4 decompresses fastest, with a ratio close to Snappy
+	LZ4 Type = 3
+)
+
+var (
+	// ErrUnknownType is returned for a compression type that isn't supported
+	ErrUnknownType = errors.New("unknown compression type")
+
+	// ErrCorrupt is returned when compressed data can't be decompressed
+	ErrCorrupt = errors.New("corrupt compressed data")
+)
+
+// String returns the name of the compression type
+func (t Type) String() string {
+	switch t {
+	case None:
+		return "none"
+	case Snappy:
+		return "snappy"
+	case Zstd:
+		return "zstd"
+	case LZ4:
+		return "lz4"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(t))
+	}
+}
+
+// ParseType returns the compression type with the given name. An empty name
+// means no compression.
+func ParseType(name string) (Type, error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return None, nil
+	case "snappy":
+		return Snappy, nil
+	case "zstd":
+		return Zstd, nil
+	case "lz4":
+		return LZ4, nil
+	default:
+		return None, fmt.Errorf("%w: %q", ErrUnknownType, name)
+	}
+}
+
+var (
+	// Zstd encoders by level, they are safe for concurrent use with EncodeAll
+	zstdEncoders   = make(map[int]*zstd.Encoder)
+	zstdEncodersMu sync.Mutex
+
+	zstdDecoder     *zstd.Decoder
+	zstdDecoderErr  error
+	zstdDecoderOnce sync.Once
+)
+
+// zstdEncoder returns the shared encoder for a zstd level, 0 being the default level
+func zstdEncoder(level int) (*zstd.Encoder, error) {
+	zstdEncodersMu.Lock()
+	defer zstdEncodersMu.Unlock()
+
+	if encoder, ok := zstdEncoders[level]; ok {
+		return encoder, nil
+	}
+
+	encoderLevel := zstd.SpeedDefault
+	if level != 0 {
+		encoderLevel = zstd.EncoderLevelFromZstd(level)
+	}
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(encoderLevel), zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+	}
+	zstdEncoders[level] = encoder
+	return encoder, nil
+}
+
+// sharedZstdDecoder returns the decoder shared by all zstd decompressions
+func sharedZstdDecoder() (*zstd.Decoder, error) {
+	zstdDecoderOnce.Do(func() {
+		zstdDecoder, zstdDecoderErr = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+	})
+	return zstdDecoder, zstdDecoderErr
+}
+
+// Compress compresses data with the given codec. The level is only used by
+// zstd, where 0 selects the default level.
+func Compress(t Type, level int, data []byte) ([]byte, error) {
+	switch t {
+	case None:
+		return data, nil
+	case Snappy:
+		return snappy.Encode(nil, data), nil
+	case Zstd:
+		encoder, err := zstdEncoder(level)
+		if err != nil {
+			return nil, err
+		}
+		return encoder.EncodeAll(data, nil), nil
+	case LZ4:
+		// The block format doesn't record the decompressed size
+		out := binary.AppendUvarint(nil, uint64(len(data)))
+		return lz4Compress(out, data), nil
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnknownType, uint8(t))
+	}
+}
+
+// Decompress reverses Compress
+func Decompress(t Type, data []byte) ([]byte, error) {
+	switch t {
+	case None:
+		return data, nil
+	case Snappy:
+		decoded, err := snappy.Decode(nil, data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+		}
+		return decoded, nil
+	case Zstd:
+		decoder, err := sharedZstdDecoder()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+		}
+		decoded, err := decoder.DecodeAll(data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+		}
+		return decoded, nil
+	case LZ4:
+		size, n := binary.Uvarint(data)
+		if n <= 0 || size > uint64(maxLZ4Size(len(data)-n)) {
+			return nil, fmt.Errorf("%w: invalid lz4 size header", ErrCorrupt)
+		}
+		return lz4Decompress(data[n:], int(size))
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnknownType, uint8(t))
+	}
+}
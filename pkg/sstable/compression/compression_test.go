@@ -0,0 +1,80 @@
+package compression
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestCompressRoundTrip(t *testing.T) {
+	var jsonData bytes.Buffer
+	for i := 0; i < 500; i++ {
+		fmt.Fprintf(&jsonData, `{"id":%d,"name":"user%d","active":true,"tags":["a","b"]}`, i, i%7)
+	}
+
+	random := make([]byte, 4096)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	inputs := map[string][]byte{
+		"empty":    {},
+		"short":    []byte("hello"),
+		"repeated": bytes.Repeat([]byte("a"), 70000),
+		"json":     jsonData.Bytes(),
+		"random":   random,
+	}
+
+	for _, codec := range []Type{None, Snappy, Zstd, LZ4} {
+		for name, input := range inputs {
+			compressed, err := Compress(codec, 0, input)
+			if err != nil {
+				t.Fatalf("%s/%s: failed to compress: %v", codec, name, err)
+			}
+			decompressed, err := Decompress(codec, compressed)
+			if err != nil {
+				t.Fatalf("%s/%s: failed to decompress: %v", codec, name, err)
+			}
+			if !bytes.Equal(decompressed, input) {
+				t.Errorf("%s/%s: round trip changed the data", codec, name)
+			}
+			if codec != None && name == "json" && len(compressed) >= len(input)/2 {
+				t.Errorf("%s: expected JSON to compress to less than half, got %d of %d bytes",
+					codec, len(compressed), len(input))
+			}
+		}
+	}
+}
+
+func TestDecompressCorrupt(t *testing.T) {
+	input := bytes.Repeat([]byte("kevo compression "), 100)
+
+	for _, codec := range []Type{Snappy, Zstd, LZ4} {
+		compressed, err := Compress(codec, 0, input)
+		if err != nil {
+			t.Fatalf("%s: failed to compress: %v", codec, err)
+		}
+
+		truncated := compressed[:len(compressed)/2]
+		if _, err := Decompress(codec, truncated); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("%s: expected ErrCorrupt for truncated data, got %v", codec, err)
+		}
+	}
+
+	if _, err := Decompress(Type(42), input); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("Expected ErrUnknownType, got %v", err)
+	}
+}
+
+func TestParseType(t *testing.T) {
+	for _, codec := range []Type{None, Snappy, Zstd, LZ4} {
+		parsed, err := ParseType(codec.String())
+		if err != nil || parsed != codec {
+			t.Errorf("Expected %s to parse to itself, got %s (%v)", codec, parsed, err)
+		}
+	}
+
+	if _, err := ParseType("brotli"); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("Expected ErrUnknownType, got %v", err)
+	}
+}
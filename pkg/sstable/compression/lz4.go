@@ -0,0 +1,169 @@
+package compression
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// This file implements the LZ4 block format. A block is a series of
+// sequences, each made of a token byte, literals copied as they are and a
+// match copied from earlier output. The high and low halves of the token hold
+// the literal length and the match length minus 4, with 15 meaning that more
+// length bytes follow. The last sequence only has literals.
+
+const (
+	lz4MinMatch     = 4
+	lz4HashLog      = 12
+	lz4MaxOffset    = 65535
+	lz4LastLiterals = 5  // The last bytes are always literals
+	lz4MatchLimit   = 12 // No match may start in the last bytes
+)
+
+// maxLZ4Size returns an upper bound of the size n bytes of LZ4 data decompress to
+func maxLZ4Size(n int) int {
+	return n*255 + lz4MinMatch
+}
+
+// lz4Compress appends the LZ4 block encoding of src to dst
+func lz4Compress(dst, src []byte) []byte {
+	// Positions of recently seen 4 byte sequences, plus one so zero means none
+	var table [1 << lz4HashLog]int32
+
+	anchor := 0
+	limit := len(src) - lz4MatchLimit
+	for i := 0; i < limit; {
+		seq := binary.LittleEndian.Uint32(src[i:])
+		h := (seq * 2654435761) >> (32 - lz4HashLog)
+		ref := int(table[h]) - 1
+		table[h] = int32(i + 1)
+
+		if ref < 0 || i-ref > lz4MaxOffset || binary.LittleEndian.Uint32(src[ref:]) != seq {
+			i++
+			continue
+		}
+
+		// Extend the match forwards, then backwards over pending literals
+		end := i + lz4MinMatch
+		for end < len(src)-lz4LastLiterals && src[end] == src[ref+end-i] {
+			end++
+		}
+		for i > anchor && ref > 0 && src[i-1] == src[ref-1] {
+			i--
+			ref--
+		}
+
+		dst = lz4AppendSequence(dst, src[anchor:i], i-ref, end-i)
+		i = end
+		anchor = end
+	}
+
+	return lz4AppendSequence(dst, src[anchor:], 0, 0)
+}
+
+// lz4AppendSequence appends a sequence to dst. A zero matchLen appends the
+// final sequence, which only has literals.
+func lz4AppendSequence(dst, literals []byte, offset, matchLen int) []byte {
+	litLen := len(literals)
+	matchCode := max(matchLen-lz4MinMatch, 0)
+
+	dst = append(dst, byte(min(litLen, 15))<<4|byte(min(matchCode, 15)))
+	if litLen >= 15 {
+		dst = lz4AppendLength(dst, litLen-15)
+	}
+	dst = append(dst, literals...)
+
+	if matchLen == 0 {
+		return dst
+	}
+	dst = append(dst, byte(offset), byte(offset>>8))
+	if matchCode >= 15 {
+		dst = lz4AppendLength(dst, matchCode-15)
+	}
+	return dst
+}
+
+// lz4AppendLength appends the extra bytes of a length of at least 15
+func lz4AppendLength(dst []byte, n int) []byte {
+	for n >= 255 {
+		dst = append(dst, 255)
+		n -= 255
+	}
+	return append(dst, byte(n))
+}
+
+// lz4ReadLength reads the extra bytes of a length, returning the length and
+// the number of bytes read
+func lz4ReadLength(src []byte) (int, int, error) {
+	n := 0
+	for i, b := range src {
+		n += int(b)
+		if b != 255 {
+			return n, i + 1, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("%w: truncated lz4 length", ErrCorrupt)
+}
+
+// lz4Decompress decodes an LZ4 block that decompresses to size bytes
+func lz4Decompress(src []byte, size int) ([]byte, error) {
+	dst := make([]byte, 0, size)
+
+	for i := 0; i < len(src); {
+		token := src[i]
+		i++
+
+		litLen := int(token >> 4)
+		if litLen == 15 {
+			extra, n, err := lz4ReadLength(src[i:])
+			if err != nil {
+				return nil, err
+			}
+			litLen += extra
+			i += n
+		}
+		if litLen > len(src)-i || litLen > size-len(dst) {
+			return nil, fmt.Errorf("%w: lz4 literals out of bounds", ErrCorrupt)
+		}
+		dst = append(dst, src[i:i+litLen]...)
+		i += litLen
+
+		// The last sequence ends after its literals
+		if i == len(src) {
+			break
+		}
+
+		if len(src)-i < 2 {
+			return nil, fmt.Errorf("%w: truncated lz4 match offset", ErrCorrupt)
+		}
+		offset := int(src[i]) | int(src[i+1])<<8
+		i += 2
+		if offset == 0 || offset > len(dst) {
+			return nil, fmt.Errorf("%w: invalid lz4 match offset %d", ErrCorrupt, offset)
+		}
+
+		matchLen := int(token & 15)
+		if matchLen == 15 {
+			extra, n, err := lz4ReadLength(src[i:])
+			if err != nil {
+				return nil, err
+			}
+			matchLen += extra
+			i += n
+		}
+		matchLen += lz4MinMatch
+		if matchLen > size-len(dst) {
+			return nil, fmt.Errorf("%w: lz4 match out of bounds", ErrCorrupt)
+		}
+
+		// Matches may overlap the bytes they produce, so copy one at a time
+		start := len(dst) - offset
+		for k := 0; k < matchLen; k++ {
+			dst = append(dst, dst[start+k])
+		}
+	}
+
+	if len(dst) != size {
+		return nil, fmt.Errorf("%w: lz4 data decompressed to %d bytes, expected %d", ErrCorrupt, len(dst), size)
+	}
+	return dst, nil
+}
@@ -0,0 +1,146 @@
+package sstable
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrKeyOrder is returned when a key isn't greater than the one before it
+	ErrKeyOrder = errors.New("keys must be added in strictly increasing order")
+	// ErrEmptyKey is returned when adding an empty key
+	ErrEmptyKey = errors.New("key cannot be empty")
+	// ErrEmptyFile is returned when finishing a file without any entries
+	ErrEmptyFile = errors.New("no entries were added to the file")
+	// ErrWriterClosed is returned when using a writer after Finish or Abort
+	ErrWriterClosed = errors.New("writer is already finished")
+)
+
+// SSTFileWriter builds an SSTable file outside of a database, to be added to
+// one with EngineFacade.IngestFiles. Each key is added at most once and in
+// strictly increasing order; range deletions may be added in any order.
+//
+// Entries are written without sequence numbers. The database ingesting the
+// file gives all of them the same one, newer than anything already in it, so
+// deletions and range deletions only remove data written before the file was
+// ingested, not the keys added to the file itself.
+type SSTFileWriter struct {
+	writer   *Writer
+	lastKey  []byte
+	entries  int
+	finished bool
+}
+
+// NewSSTFileWriter creates a file writer at path with the default options
+func NewSSTFileWriter(path string) (*SSTFileWriter, error) {
+	return NewSSTFileWriterWithOptions(path, DefaultWriterOptions())
+}
+
+// NewSSTFileWriterWithOptions creates a file writer at path with custom
+// options. The file only appears at path once Finish succeeds.
+func NewSSTFileWriterWithOptions(path string, options WriterOptions) (*SSTFileWriter, error) {
+	writer, err := NewWriterWithOptions(path, options)
+	if err != nil {
+		return nil, err
+	}
+	return &SSTFileWriter{writer: writer}, nil
+}
+
+// Put adds a key-value pair
+func (w *SSTFileWriter) Put(key, value []byte) error {
+	if err := w.checkKey(key); err != nil {
+		return err
+	}
+	// A nil value is how deletions are stored
+	if value == nil {
+		value = []byte{}
+	}
+	return w.added(w.writer.AddWithSequence(key, value, 0), key)
+}
+
+// Delete adds a deletion of key
+func (w *SSTFileWriter) Delete(key []byte) error {
+	if err := w.checkKey(key); err != nil {
+		return err
+	}
+	return w.added(w.writer.AddWithSequence(key, nil, 0), key)
+}
+
+// Merge adds a merge operand for key, combined with the older versions of
+// the key by the merge operator of the database it's ingested into
+func (w *SSTFileWriter) Merge(key, operand []byte) error {
+	if err := w.checkKey(key); err != nil {
+		return err
+	}
+	return w.added(w.writer.AddMergeWithSequence(key, operand, 0), key)
+}
+
+// DeleteRange adds a deletion of every key in [start, end)
+func (w *SSTFileWriter) DeleteRange(start, end []byte) error {
+	if w.finished {
+		return ErrWriterClosed
+	}
+	if err := w.writer.AddRangeTombstone(start, end, 0); err != nil {
+		return err
+	}
+	w.entries++
+	return nil
+}
+
+// EntryCount returns the number of keys and range deletions added so far
+func (w *SSTFileWriter) EntryCount() int {
+	return w.entries
+}
+
+// Finish writes out the file. It fails with ErrEmptyFile, leaving no file
+// behind, if nothing was added.
+func (w *SSTFileWriter) Finish() error {
+	if w.finished {
+		return ErrWriterClosed
+	}
+	w.finished = true
+
+	if w.entries == 0 {
+		w.writer.Abort()
+		return ErrEmptyFile
+	}
+	if err := w.writer.Finish(); err != nil {
+		w.writer.Abort()
+		return fmt.Errorf("failed to finish SSTable: %w", err)
+	}
+	return nil
+}
+
+// Abort discards the file
+func (w *SSTFileWriter) Abort() error {
+	if w.finished {
+		return nil
+	}
+	w.finished = true
+	return w.writer.Abort()
+}
+
+// checkKey returns an error unless key can be added next
+func (w *SSTFileWriter) checkKey(key []byte) error {
+	if w.finished {
+		return ErrWriterClosed
+	}
+	if len(key) == 0 {
+		return ErrEmptyKey
+	}
+	if w.lastKey != nil && bytes.Compare(key, w.lastKey) <= 0 {
+		return fmt.Errorf("%w: %q after %q", ErrKeyOrder, key, w.lastKey)
+	}
+	return nil
+}
+
+// added records key as the last one added if err is nil
+func (w *SSTFileWriter) added(err error, key []byte) error {
+	if err != nil {
+		return err
+	}
+	w.lastKey = append(w.lastKey[:0], key...)
+	w.entries++
+	return nil
+}
@@ -0,0 +1,89 @@
+package sstable
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSSTFileWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ingest.sst")
+
+	writer, err := NewSSTFileWriter(path)
+	if err != nil {
+		t.Fatalf("Failed to create file writer: %v", err)
+	}
+	if err := writer.Put([]byte("a"), []byte("1")); err != nil {
+		t.Fatalf("Failed to put: %v", err)
+	}
+	if err := writer.Put([]byte("b"), nil); err != nil {
+		t.Fatalf("Failed to put: %v", err)
+	}
+	if err := writer.Delete([]byte("c")); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if err := writer.DeleteRange([]byte("x"), []byte("z")); err != nil {
+		t.Fatalf("Failed to delete range: %v", err)
+	}
+
+	// Keys only go forward
+	if err := writer.Put([]byte("c"), []byte("2")); !errors.Is(err, ErrKeyOrder) {
+		t.Errorf("Expected ErrKeyOrder for a repeated key, got %v", err)
+	}
+	if err := writer.Put([]byte("b"), []byte("2")); !errors.Is(err, ErrKeyOrder) {
+		t.Errorf("Expected ErrKeyOrder for an earlier key, got %v", err)
+	}
+	if err := writer.Put(nil, []byte("2")); !errors.Is(err, ErrEmptyKey) {
+		t.Errorf("Expected ErrEmptyKey, got %v", err)
+	}
+	if writer.EntryCount() != 4 {
+		t.Errorf("Expected 4 entries, got %d", writer.EntryCount())
+	}
+
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish: %v", err)
+	}
+	if err := writer.Put([]byte("d"), []byte("3")); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("Expected ErrWriterClosed, got %v", err)
+	}
+
+	// The global sequence number replaces the stored ones
+	reader, err := OpenReaderWithOptions(path, ReaderOptions{GlobalSequence: 42})
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer reader.Close()
+
+	iter := reader.NewIterator()
+	var keys []string
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		keys = append(keys, string(iter.Key()))
+		if iter.SequenceNumber() != 42 {
+			t.Errorf("Expected sequence number 42 for %q, got %d", iter.Key(), iter.SequenceNumber())
+		}
+		if tombstone := string(iter.Key()) == "c"; iter.IsTombstone() != tombstone {
+			t.Errorf("Expected %q to be a tombstone: %v", iter.Key(), tombstone)
+		}
+	}
+	if len(keys) != 3 {
+		t.Errorf("Expected 3 keys, got %v", keys)
+	}
+	tombstones := reader.RangeTombstones()
+	if len(tombstones) != 1 || tombstones[0].SeqNum != 42 {
+		t.Errorf("Expected one range tombstone at sequence 42, got %+v", tombstones)
+	}
+
+	// An empty file isn't written
+	emptyPath := filepath.Join(t.TempDir(), "empty.sst")
+	empty, err := NewSSTFileWriter(emptyPath)
+	if err != nil {
+		t.Fatalf("Failed to create file writer: %v", err)
+	}
+	if err := empty.Finish(); !errors.Is(err, ErrEmptyFile) {
+		t.Errorf("Expected ErrEmptyFile, got %v", err)
+	}
+	if _, err := os.Stat(emptyPath); !os.IsNotExist(err) {
+		t.Errorf("Expected no file for an empty writer, got %v", err)
+	}
+}
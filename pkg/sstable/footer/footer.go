@@ -14,8 +14,11 @@ const (
 	FooterSize = 68
 	// FooterMagic is a magic number to verify we're reading a valid footer
 	FooterMagic = uint64(0xFACEFEEDFACEFEED)
+	// VersionBlockCompression is the first version whose blocks end with a
+	// compression type byte
+	VersionBlockCompression = uint32(4)
 	// CurrentVersion is the current file format version
-	CurrentVersion = uint32(2) // Updated for bloom filter support
+	CurrentVersion = VersionBlockCompression
 )
 
 // Footer contains metadata for an SSTable file
@@ -40,6 +43,8 @@ type Footer struct {
 	BloomFilterOffset uint64
 	// Bloom filter size (0 if no bloom filter)
 	BloomFilterSize uint32
+	// Size of the range tombstone block stored right before the index (0 if none)
+	RangeTombstoneSize uint32
 	// Checksum of all footer fields excluding the checksum itself
 	Checksum uint64
 }
@@ -78,7 +83,7 @@ func (f *Footer) Encode() []byte {
 	binary.LittleEndian.PutUint32(result[40:44], f.MaxKeyOffset)
 	binary.LittleEndian.PutUint64(result[44:52], f.BloomFilterOffset)
 	binary.LittleEndian.PutUint32(result[52:56], f.BloomFilterSize)
-	// 4 bytes of padding (56:60)
+	binary.LittleEndian.PutUint32(result[56:60], f.RangeTombstoneSize)
 
 	// Calculate checksum of all fields excluding the checksum itself
 	f.Checksum = xxhash.Sum64(result[:60])
@@ -115,10 +120,14 @@ func Decode(data []byte) (*Footer, error) {
 	// Check version to determine how to decode the rest
 	// Version 1: Original format without bloom filters
 	// Version 2+: Format with bloom filters
+	// Version 3+: Format with range tombstones
+	// Version 4+: Blocks end with a compression type byte
 	if footer.Version >= 2 {
 		footer.BloomFilterOffset = binary.LittleEndian.Uint64(data[44:52])
 		footer.BloomFilterSize = binary.LittleEndian.Uint32(data[52:56])
-		// 4 bytes of padding (56:60)
+		if footer.Version >= 3 {
+			footer.RangeTombstoneSize = binary.LittleEndian.Uint32(data[56:60])
+		}
 		footer.Checksum = binary.LittleEndian.Uint64(data[60:])
 	} else {
 		// Legacy format without bloom filters
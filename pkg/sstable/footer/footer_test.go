@@ -17,6 +17,7 @@ func TestFooterEncodeDecode(t *testing.T) {
 		5000, // bloomFilterOffset
 		300,  // bloomFilterSize
 	)
+	f.RangeTombstoneSize = 42
 
 	// Encode the footer
 	encoded := f.Encode()
@@ -65,6 +66,10 @@ func TestFooterEncodeDecode(t *testing.T) {
 		t.Errorf("MaxKeyOffset mismatch: got %d, expected %d", decoded.MaxKeyOffset, f.MaxKeyOffset)
 	}
 
+	if decoded.RangeTombstoneSize != f.RangeTombstoneSize {
+		t.Errorf("RangeTombstoneSize mismatch: got %d, expected %d", decoded.RangeTombstoneSize, f.RangeTombstoneSize)
+	}
+
 	if decoded.Checksum != f.Checksum {
 		t.Errorf("Checksum mismatch: got %d, expected %d", decoded.Checksum, f.Checksum)
 	}
@@ -0,0 +1,161 @@
+package sstable
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/KevoDB/kevo/pkg/sstable/footer"
+)
+
+// Footer returns a copy of the decoded footer of the file
+func (r *Reader) Footer() footer.Footer {
+	return *r.ft
+}
+
+// IndexEntries returns the locators of the data blocks, in index order
+func (r *Reader) IndexEntries() ([]BlockLocator, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var locators []BlockLocator
+	indexIter := r.indexBlock.Iterator()
+	for indexIter.SeekToFirst(); indexIter.Valid(); indexIter.Next() {
+		locator, err := ParseBlockLocator(append([]byte(nil), indexIter.Key()...), indexIter.Value())
+		if err != nil {
+			return nil, fmt.Errorf("index entry %d: %w", len(locators), err)
+		}
+		locators = append(locators, locator)
+	}
+	return locators, nil
+}
+
+// BloomFilterStats describes the bloom filter of a data block
+type BloomFilterStats struct {
+	// Offset of the block the filter covers
+	BlockOffset uint64 `json:"block_offset"`
+	// Size of the filter in bits
+	Bits uint64 `json:"bits"`
+	// Number of hash functions
+	HashFunctions uint64 `json:"hash_functions"`
+	// Number of keys added, counting every version of a key
+	Keys uint64 `json:"keys"`
+	// False positive rate expected for the number of keys added
+	EstimatedFPR float64 `json:"estimated_fpr"`
+}
+
+// BloomFilterStats returns the bloom filters of the file by block offset
+func (r *Reader) BloomFilterStats() []BloomFilterStats {
+	stats := make([]BloomFilterStats, 0, len(r.bloomFilters))
+	for offset, filter := range r.bloomFilters {
+		stats = append(stats, BloomFilterStats{
+			BlockOffset:   offset,
+			Bits:          filter.Size(),
+			HashFunctions: filter.HashFunctions(),
+			Keys:          filter.Insertions(),
+			EstimatedFPR:  filter.EstimatedFalsePositiveRate(),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].BlockOffset < stats[j].BlockOffset })
+	return stats
+}
+
+// Verify reads every data block of the file from disk and checks its
+// checksum, that the blocks are laid out as the index says, and that the
+// entries are in order: keys increasing, the versions of a key in one block
+// by decreasing sequence number, which the writes of a batch may share. It
+// also checks the entry count in the footer, that bloom filters hold the keys
+// of their blocks and that range tombstones aren't empty. It returns nil or the problems found joined
+// together, each wrapping ErrCorruption.
+func (r *Reader) Verify() error {
+	var problems []error
+	report := func(format string, args ...any) {
+		problems = append(problems, fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), ErrCorruption))
+	}
+
+	locators, err := r.IndexEntries()
+	if err != nil {
+		return err
+	}
+
+	// Data blocks are written back to back from the start of the file, and
+	// are followed by the bloom filters or the range tombstone block
+	dataEnd := r.ft.IndexOffset - uint64(r.ft.RangeTombstoneSize)
+	if r.hasBloomFilter {
+		dataEnd = r.ft.BloomFilterOffset
+	}
+
+	var prevKey []byte
+	var prevSeq uint64
+	var nextOffset uint64
+	var entries uint32
+	unreadable := false
+	for i, locator := range locators {
+		if locator.Offset != nextOffset {
+			report("block %d is at offset %d, expected %d", i, locator.Offset, nextOffset)
+		}
+		nextOffset = locator.Offset + uint64(locator.Size)
+
+		blockReader, err := r.blockFetcher.FetchBlock(locator.Offset, locator.Size)
+		if err != nil {
+			report("block %d: %v", i, err)
+			unreadable = true
+			prevKey = nil
+			continue
+		}
+
+		filter := r.bloomFilters[locator.Offset]
+		if r.hasBloomFilter && filter == nil {
+			report("block %d at offset %d has no bloom filter", i, locator.Offset)
+		}
+
+		blockIter := blockReader.Iterator()
+		first := true
+		missing := 0
+		for blockIter.SeekToFirst(); blockIter.Valid(); blockIter.Next() {
+			key, seqNum := blockIter.Key(), blockIter.SequenceNumber()
+			if first && !bytes.Equal(key, locator.Key) {
+				report("block %d starts with key %q, the index has %q", i, key, locator.Key)
+			}
+			if prevKey != nil {
+				cmp := bytes.Compare(key, prevKey)
+				switch {
+				case cmp < 0:
+					report("block %d has key %q after %q", i, key, prevKey)
+				case cmp == 0 && first:
+					report("key %q is split between blocks %d and %d", key, i-1, i)
+				case cmp == 0 && (seqNum > prevSeq || seqNum == 0):
+					report("block %d has sequence number %d of key %q after %d", i, seqNum, key, prevSeq)
+				}
+			}
+			if filter != nil && !filter.Contains(key) {
+				missing++
+			}
+			prevKey = append(prevKey[:0], key...)
+			prevSeq = seqNum
+			first = false
+			entries++
+		}
+		if first {
+			report("block %d at offset %d is empty", i, locator.Offset)
+		}
+		if missing > 0 {
+			report("the bloom filter of block %d is missing %d keys", i, missing)
+		}
+	}
+	if len(locators) > 0 && nextOffset != dataEnd {
+		report("data blocks end at offset %d, expected %d", nextOffset, dataEnd)
+	}
+
+	if !unreadable && entries != r.ft.NumEntries {
+		report("found %d entries, the footer has %d", entries, r.ft.NumEntries)
+	}
+	for _, t := range r.rangeTombstones {
+		if bytes.Compare(t.Start, t.End) >= 0 {
+			report("range tombstone [%q, %q) is empty", t.Start, t.End)
+		}
+	}
+
+	return errors.Join(problems...)
+}
@@ -0,0 +1,147 @@
+package sstable
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KevoDB/kevo/pkg/sstable/block"
+	"github.com/KevoDB/kevo/pkg/sstable/footer"
+)
+
+// writeVersion3File writes a single block SSTable in format version 3, whose
+// blocks have no compression type byte
+func writeVersion3File(t *testing.T, path string, keys []string) {
+	t.Helper()
+
+	var file bytes.Buffer
+	builder := block.NewBuilder()
+	for i, key := range keys {
+		if err := builder.AddWithSequence([]byte(key), []byte("value"), uint64(i+1)); err != nil {
+			t.Fatalf("Failed to add entry: %v", err)
+		}
+	}
+	if _, err := builder.Finish(&file); err != nil {
+		t.Fatalf("Failed to finish block: %v", err)
+	}
+
+	indexOffset := uint64(file.Len())
+	locator := make([]byte, 12)
+	binary.LittleEndian.PutUint64(locator, 0)
+	binary.LittleEndian.PutUint32(locator[8:], uint32(indexOffset))
+	index := block.NewBuilder()
+	if err := index.Add([]byte(keys[0]), locator); err != nil {
+		t.Fatalf("Failed to add index entry: %v", err)
+	}
+	if _, err := index.Finish(&file); err != nil {
+		t.Fatalf("Failed to finish index: %v", err)
+	}
+
+	ft := footer.NewFooter(indexOffset, uint32(uint64(file.Len())-indexOffset), uint32(len(keys)), 0, 0, 0, 0)
+	ft.Version = 3
+	file.Write(ft.Encode())
+	if err := os.WriteFile(path, file.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+}
+
+func TestReaderVerify(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.sst")
+
+	writer, err := NewWriter(path)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	for i := 0; i < 5000; i++ {
+		key := []byte(fmt.Sprintf("key-%05d", i))
+		// Several versions of some keys, newest first
+		for seq := uint64(3); seq > 0; seq-- {
+			if err := writer.AddWithSequence(key, bytes.Repeat([]byte("v"), 50), uint64(i)*10+seq); err != nil {
+				t.Fatalf("Failed to add: %v", err)
+			}
+			if i%7 != 0 {
+				break
+			}
+		}
+		// Some written by a batch along with the oldest one
+		if i%11 == 0 {
+			if err := writer.AddMergeWithSequence(key, []byte("m"), uint64(i)*10+1); err != nil {
+				t.Fatalf("Failed to add merge operand: %v", err)
+			}
+		}
+	}
+	if err := writer.AddRangeTombstone([]byte("a"), []byte("b"), 1); err != nil {
+		t.Fatalf("Failed to add range tombstone: %v", err)
+	}
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish: %v", err)
+	}
+
+	reader, err := OpenReader(path)
+	if err != nil {
+		t.Fatalf("Failed to open reader: %v", err)
+	}
+	if err := reader.Verify(); err != nil {
+		t.Errorf("Expected a valid file, got %v", err)
+	}
+	locators, err := reader.IndexEntries()
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	if len(locators) < 2 {
+		t.Fatalf("Expected several blocks, got %d", len(locators))
+	}
+	for _, stats := range reader.BloomFilterStats()[:len(locators)] {
+		if stats.Keys == 0 || stats.EstimatedFPR <= 0 || stats.EstimatedFPR > 0.05 {
+			t.Errorf("Unexpected bloom filter stats %+v", stats)
+		}
+	}
+	if reader.Footer().Version != footer.CurrentVersion {
+		t.Errorf("Expected version %d, got %d", footer.CurrentVersion, reader.Footer().Version)
+	}
+	reader.Close()
+
+	// Damage the second data block
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	data[locators[1].Offset+10] ^= 0xff
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	reader, err = OpenReader(path)
+	if err != nil {
+		t.Fatalf("Failed to open reader: %v", err)
+	}
+	err = reader.Verify()
+	if !errors.Is(err, ErrCorruption) {
+		t.Errorf("Expected ErrCorruption, got %v", err)
+	} else if !bytes.Contains([]byte(err.Error()), []byte("block 1:")) {
+		t.Errorf("Expected a problem with block 1, got %v", err)
+	}
+	reader.Close()
+
+	// Files in older formats can be read and verified
+	oldPath := filepath.Join(dir, "v3.sst")
+	writeVersion3File(t, oldPath, []string{"a", "b", "c"})
+	reader, err = OpenReader(oldPath)
+	if err != nil {
+		t.Fatalf("Failed to open version 3 file: %v", err)
+	}
+	defer reader.Close()
+	if err := reader.Verify(); err != nil {
+		t.Errorf("Expected a valid version 3 file, got %v", err)
+	}
+	if reader.Footer().Version != 3 {
+		t.Errorf("Expected version 3, got %d", reader.Footer().Version)
+	}
+	if value, err := reader.Get([]byte("b")); err != nil || string(value) != "value" {
+		t.Errorf("Expected to read b, got %q (%v)", value, err)
+	}
+}
@@ -1,6 +1,7 @@
 package sstable
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"sync"
@@ -16,6 +17,7 @@ type Iterator struct {
 	currentBlock  *block.Reader
 	err           error
 	initialized   bool
+	fillCache     bool
 	mu            sync.Mutex
 }
 
@@ -105,10 +107,11 @@ func (it *Iterator) Seek(target []byte) bool {
 	it.initialized = true
 
 	// Find the block that might contain the key
-	// The index contains the first key of each block
-	if !it.indexIterator.Seek(target) {
-		// If seeking in the index fails, try the last block
-		it.indexIterator.SeekToLast()
+	// The index contains the first key of each block, so the target can only
+	// live in the last block whose first key is <= target
+	if !it.seekIndexFloor(target) {
+		// The target sorts before every block, start from the first one
+		it.indexIterator.SeekToFirst()
 		if !it.indexIterator.Valid() {
 			// No blocks in the SSTable
 			it.resetBlockIterator()
@@ -164,6 +167,24 @@ func (it *Iterator) Next() bool {
 	return it.advanceToNextBlock()
 }
 
+// Prev moves the iterator back to the previous key
+func (it *Iterator) Prev() bool {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+
+	if !it.initialized || it.dataBlockIter == nil || !it.dataBlockIter.Valid() {
+		return false
+	}
+
+	// Try to move back within current block
+	if it.dataBlockIter.Prev() {
+		return true
+	}
+
+	// We've reached the start of the current block, so try to move to the previous block
+	return it.retreatToPrevBlock()
+}
+
 // Key returns the current key
 func (it *Iterator) Key() []byte {
 	it.mu.Lock()
@@ -209,6 +230,30 @@ func (it *Iterator) IsTombstone() bool {
 	return it.dataBlockIter.Value() == nil
 }
 
+// IsMergeOperand returns true if the current entry is a merge operand
+func (it *Iterator) IsMergeOperand() bool {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+
+	if !it.initialized || it.dataBlockIter == nil || !it.dataBlockIter.Valid() {
+		return false
+	}
+
+	return it.dataBlockIter.IsMergeOperand()
+}
+
+// ExpireAt returns the expiry time of the current entry, or 0 if it never expires
+func (it *Iterator) ExpireAt() uint64 {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+
+	if !it.initialized || it.dataBlockIter == nil || !it.dataBlockIter.Valid() {
+		return 0
+	}
+
+	return it.dataBlockIter.ExpireAt()
+}
+
 // SequenceNumber returns the sequence number of the current entry
 func (it *Iterator) SequenceNumber() uint64 {
 	it.mu.Lock()
@@ -219,7 +264,7 @@ func (it *Iterator) SequenceNumber() uint64 {
 		return 0
 	}
 
-	return it.dataBlockIter.SequenceNumber()
+	return it.reader.sequenceNumber(it.dataBlockIter.SequenceNumber())
 }
 
 // Error returns any error encountered during iteration
@@ -272,6 +317,27 @@ func (it *Iterator) findLastUniqueBlockOffset() (uint64, bool) {
 	return lastBlockOffset, lastBlockValid
 }
 
+// seekIndexFloor positions the index iterator at the last entry whose key is <= target.
+// Returns false if every index key is greater than target.
+func (it *Iterator) seekIndexFloor(target []byte) bool {
+	return seekFloor(it.indexIterator, target)
+}
+
+// seekFloor positions an index iterator at the last entry whose key is <= target.
+// Returns false if every index key is greater than target.
+func seekFloor(indexIter *block.Iterator, target []byte) bool {
+	if indexIter.Seek(target) && bytes.Equal(indexIter.Key(), target) {
+		return true
+	}
+
+	// Seek landed on the first key > target, so the floor is the entry before it
+	if indexIter.Valid() {
+		return indexIter.Prev()
+	}
+	indexIter.SeekToLast()
+	return indexIter.Valid()
+}
+
 // seekInNextBlocks attempts to find the target key in subsequent blocks
 func (it *Iterator) seekInNextBlocks() bool {
 	var foundValidKey bool
@@ -342,6 +408,37 @@ func (it *Iterator) advanceToNextBlock() bool {
 	return it.dataBlockIter.Valid()
 }
 
+// retreatToPrevBlock moves to the last key of the previous unique block
+func (it *Iterator) retreatToPrevBlock() bool {
+	var currentBlockOffset uint64
+	if len(it.indexIterator.Value()) >= 8 {
+		currentBlockOffset = binary.LittleEndian.Uint64(it.indexIterator.Value()[:8])
+	}
+
+	// Find the previous block with a different offset
+	for it.indexIterator.Prev() {
+		if len(it.indexIterator.Value()) < 8 {
+			continue
+		}
+		if binary.LittleEndian.Uint64(it.indexIterator.Value()[:8]) == currentBlockOffset {
+			continue
+		}
+
+		it.loadCurrentDataBlock()
+		if it.dataBlockIter == nil {
+			return false
+		}
+
+		// Start at the end of the previous block
+		it.dataBlockIter.SeekToLast()
+		return it.dataBlockIter.Valid()
+	}
+
+	// No more blocks before this one
+	it.resetBlockIterator()
+	return false
+}
+
 // findNextUniqueBlock advances the index iterator to find a block with a different offset
 func (it *Iterator) findNextUniqueBlock(currentBlockOffset uint64) bool {
 	for it.indexIterator.Next() {
@@ -376,8 +473,8 @@ func (it *Iterator) loadCurrentDataBlock() {
 		return
 	}
 
-	// Fetch the block using the reader's block fetcher
-	blockReader, err := it.reader.blockFetcher.FetchBlock(locator.Offset, locator.Size)
+	// Fetch the block through the reader's block cache
+	blockReader, err := it.reader.readBlock(locator, it.fillCache)
 	if err != nil {
 		it.err = fmt.Errorf("failed to fetch block: %w", err)
 		it.resetBlockIterator()
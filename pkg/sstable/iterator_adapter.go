@@ -32,6 +32,11 @@ func (a *IteratorAdapter) Next() bool {
 	return a.iter.Next()
 }
 
+// Prev moves the iterator back to the previous key
+func (a *IteratorAdapter) Prev() bool {
+	return a.iter.Prev()
+}
+
 // Key returns the current key
 func (a *IteratorAdapter) Key() []byte {
 	if !a.Valid() {
@@ -58,6 +63,19 @@ func (a *IteratorAdapter) IsTombstone() bool {
 	return a.Valid() && a.iter.IsTombstone()
 }
 
+// IsMergeOperand returns true if the current entry is a merge operand
+func (a *IteratorAdapter) IsMergeOperand() bool {
+	return a.Valid() && a.iter.IsMergeOperand()
+}
+
+// ExpireAt returns the expiry time of the current entry, or 0 if it never expires
+func (a *IteratorAdapter) ExpireAt() uint64 {
+	if !a.Valid() {
+		return 0
+	}
+	return a.iter.ExpireAt()
+}
+
 // SequenceNumber returns the sequence number of the current entry
 func (a *IteratorAdapter) SequenceNumber() uint64 {
 	if !a.Valid() {
@@ -318,3 +318,77 @@ func TestIteratorSeekToFirst(t *testing.T) {
 		t.Errorf("Last key mismatch: expected %s, got %s", expectedLastKey, actualLastKey)
 	}
 }
+
+func TestIteratorSeekAcrossBlocks(t *testing.T) {
+	tempDir := t.TempDir()
+	sstablePath := filepath.Join(tempDir, "test-seek-blocks.sst")
+
+	writer, err := NewWriter(sstablePath)
+	if err != nil {
+		t.Fatalf("Failed to create SSTable writer: %v", err)
+	}
+
+	// Enough data to span several blocks, with gaps between keys
+	numEntries := 5000
+	for i := 0; i < numEntries; i++ {
+		key := fmt.Sprintf("key%06d", i*2)
+		value := fmt.Sprintf("value%06d-padding-to-fill-blocks", i*2)
+		if err := writer.Add([]byte(key), []byte(value)); err != nil {
+			t.Fatalf("Failed to add entry: %v", err)
+		}
+	}
+
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish SSTable: %v", err)
+	}
+
+	reader, err := OpenReader(sstablePath)
+	if err != nil {
+		t.Fatalf("Failed to open SSTable: %v", err)
+	}
+	defer reader.Close()
+
+	iter := reader.NewIterator()
+	for i := 0; i < numEntries*2-1; i += 7 {
+		target := fmt.Sprintf("key%06d", i)
+		expected := fmt.Sprintf("key%06d", i+i%2)
+
+		if !iter.Seek([]byte(target)) {
+			t.Errorf("Seek(%s) failed, expected %s", target, expected)
+			continue
+		}
+		if string(iter.Key()) != expected {
+			t.Errorf("Seek(%s): expected %s, got %s", target, expected, iter.Key())
+		}
+	}
+
+	// Every key is found by Get
+	for i := 0; i < numEntries; i += 13 {
+		key := fmt.Sprintf("key%06d", i*2)
+		if _, err := reader.Get([]byte(key)); err != nil {
+			t.Errorf("Get(%s) failed: %v", key, err)
+		}
+	}
+
+	// Iteration visits every entry exactly once
+	count := 0
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		count++
+	}
+	if count != numEntries {
+		t.Errorf("Expected %d entries during iteration, got %d", numEntries, count)
+	}
+
+	// Reverse iteration crosses block boundaries in the other direction
+	i := numEntries - 1
+	for iter.SeekToLast(); iter.Valid(); iter.Prev() {
+		expected := fmt.Sprintf("key%06d", i*2)
+		if string(iter.Key()) != expected {
+			t.Fatalf("Expected %s during reverse iteration, got %s", expected, iter.Key())
+		}
+		i--
+	}
+	if i != -1 {
+		t.Errorf("Expected %d entries during reverse iteration, stopped at %d", numEntries, i)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"sync"
 
 	bloomfilter "github.com/KevoDB/kevo/pkg/bloom_filter"
+	"github.com/KevoDB/kevo/pkg/common/rangedel"
 	"github.com/KevoDB/kevo/pkg/sstable/block"
 	"github.com/KevoDB/kevo/pkg/sstable/footer"
 )
@@ -62,8 +63,14 @@ func validateHeaderStructure(ft *footer.Footer, fileSize int64) error {
 		}
 	}
 
-	// Basic sanity check on number of entries
-	if ft.NumEntries == 0 {
+	// Validate the range tombstone block, which sits right before the index
+	if uint64(ft.RangeTombstoneSize) > ft.IndexOffset {
+		return fmt.Errorf("range tombstone block size %d exceeds index offset %d",
+			ft.RangeTombstoneSize, ft.IndexOffset)
+	}
+
+	// Basic sanity check on number of entries. A table may hold only range tombstones.
+	if ft.NumEntries == 0 && ft.RangeTombstoneSize == 0 {
 		return fmt.Errorf("SSTable cannot have zero entries")
 	}
 
@@ -162,14 +169,19 @@ func (io *IOManager) Close() error {
 // BlockFetcher abstracts the fetching of data blocks
 type BlockFetcher struct {
 	io *IOManager
+	// Whether blocks end with a compression type byte
+	compressed bool
 }
 
-// NewBlockFetcher creates a new BlockFetcher
-func NewBlockFetcher(io *IOManager) *BlockFetcher {
-	return &BlockFetcher{io: io}
+// NewBlockFetcher creates a new BlockFetcher for a file of the given format version
+func NewBlockFetcher(io *IOManager, formatVersion uint32) *BlockFetcher {
+	return &BlockFetcher{
+		io:         io,
+		compressed: formatVersion >= footer.VersionBlockCompression,
+	}
 }
 
-// FetchBlock reads and parses a data block at the given offset and size
+// FetchBlock reads, decompresses and parses a data block at the given offset and size
 func (bf *BlockFetcher) FetchBlock(offset uint64, size uint32) (*block.Reader, error) {
 	// Read the data block
 	blockData := make([]byte, size)
@@ -183,6 +195,13 @@ func (bf *BlockFetcher) FetchBlock(offset uint64, size uint32) (*block.Reader, e
 			n, size, ErrCorruption)
 	}
 
+	if bf.compressed {
+		blockData, err = decodeBlock(blockData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress block at offset %d: %w", offset, err)
+		}
+	}
+
 	// Parse the block
 	blockReader, err := block.NewReader(blockData)
 	if err != nil {
@@ -217,53 +236,34 @@ func ParseBlockLocator(key, value []byte) (BlockLocator, error) {
 	}, nil
 }
 
-// BlockCache is a simple LRU cache for data blocks
-type BlockCache struct {
-	blocks    map[uint64]*block.Reader
-	maxBlocks int
-	// Using a simple approach for now - more sophisticated LRU could be implemented
-	// with a linked list or other data structure for better eviction
-	mu sync.RWMutex
+// ReaderOptions configures how an SSTable is read
+type ReaderOptions struct {
+	// BlockCache holds recently read data blocks, usually shared by all the
+	// readers of a database. Blocks are read from disk every time if it's nil.
+	BlockCache *BlockCache
+	// GlobalSequence, if not zero, is reported as the sequence number of every
+	// entry and range tombstone in the file. Ingested files are written with
+	// sequence number zero and given one when they're added to a database.
+	GlobalSequence uint64
 }
 
-// NewBlockCache creates a new block cache with the specified capacity
-func NewBlockCache(capacity int) *BlockCache {
-	return &BlockCache{
-		blocks:    make(map[uint64]*block.Reader),
-		maxBlocks: capacity,
-	}
+// DefaultReaderOptions returns the default options for the reader
+func DefaultReaderOptions() ReaderOptions {
+	return ReaderOptions{}
 }
 
-// Get retrieves a block from the cache
-func (c *BlockCache) Get(offset uint64) (*block.Reader, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	block, found := c.blocks[offset]
-	return block, found
+// ReadOptions configures the reads made by an iterator
+type ReadOptions struct {
+	// FillCache adds the blocks read from disk to the block cache. Large scans
+	// turn it off so they don't evict the blocks of frequent point lookups.
+	FillCache bool
 }
 
-// Put adds a block to the cache
-func (c *BlockCache) Put(offset uint64, block *block.Reader) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	// If cache is full, evict a random block (simple strategy for now)
-	if len(c.blocks) >= c.maxBlocks {
-		// Pick a random offset to evict
-		for k := range c.blocks {
-			delete(c.blocks, k)
-			break
-		}
+// DefaultReadOptions returns the default options for iterators
+func DefaultReadOptions() ReadOptions {
+	return ReadOptions{
+		FillCache: true,
 	}
-
-	c.blocks[offset] = block
-}
-
-// BlockBloomFilter associates a bloom filter with a block offset
-type BlockBloomFilter struct {
-	blockOffset uint64
-	filter      *bloomfilter.BloomFilter
 }
 
 // Reader reads an SSTable file
@@ -276,15 +276,28 @@ type Reader struct {
 	indexBlock   *block.Reader
 	ft           *footer.Footer
 	mu           sync.RWMutex
-	// Add block cache
+	// Shared cache of data blocks and the ID of this file in it
 	blockCache *BlockCache
-	// Add bloom filters
-	bloomFilters   []BlockBloomFilter
+	cacheID    uint64
+	// Bloom filters by the offset of the block they cover
+	bloomFilters   map[uint64]*bloomfilter.BloomFilter
 	hasBloomFilter bool
+	// Range deletions stored in the file
+	rangeTombstones []rangedel.Tombstone
+	// Smallest and largest keys in the file, nil if it has no entries
+	firstKey []byte
+	lastKey  []byte
+	// Sequence number overriding the ones stored in the file, or zero
+	globalSeq uint64
 }
 
-// OpenReader opens an SSTable file for reading
+// OpenReader opens an SSTable file for reading with default options
 func OpenReader(path string) (*Reader, error) {
+	return OpenReaderWithOptions(path, DefaultReaderOptions())
+}
+
+// OpenReaderWithOptions opens an SSTable file for reading with custom options
+func OpenReaderWithOptions(path string, options ReaderOptions) (*Reader, error) {
 	ioManager, err := NewIOManager(path)
 	if err != nil {
 		return nil, err
@@ -318,7 +331,7 @@ func OpenReader(path string) (*Reader, error) {
 		return nil, fmt.Errorf("invalid SSTable structure: %w", err)
 	}
 
-	blockFetcher := NewBlockFetcher(ioManager)
+	blockFetcher := NewBlockFetcher(ioManager, ft.Version)
 
 	// Read index block
 	indexData := make([]byte, ft.IndexSize)
@@ -343,9 +356,11 @@ func OpenReader(path string) (*Reader, error) {
 		numEntries:     ft.NumEntries,
 		indexBlock:     indexBlock,
 		ft:             ft,
-		blockCache:     NewBlockCache(100), // Cache up to 100 blocks by default
-		bloomFilters:   make([]BlockBloomFilter, 0),
+		blockCache:     options.BlockCache,
+		cacheID:        options.BlockCache.newFileID(),
+		bloomFilters:   make(map[uint64]*bloomfilter.BloomFilter),
 		hasBloomFilter: ft.BloomFilterOffset > 0 && ft.BloomFilterSize > 0,
+		globalSeq:      options.GlobalSequence,
 	}
 
 	// Load bloom filters if they exist
@@ -405,19 +420,141 @@ func OpenReader(path string) (*Reader, error) {
 			}
 
 			// Add the bloom filter to our list
-			reader.bloomFilters = append(reader.bloomFilters, BlockBloomFilter{
-				blockOffset: blockOffset,
-				filter:      filter,
-			})
+			reader.bloomFilters[blockOffset] = filter
 
 			// Move to the next filter
 			pos += filterSize
 		}
 	}
 
+	// Load range tombstones if they exist
+	if ft.RangeTombstoneSize > 0 {
+		offset := ft.IndexOffset - uint64(ft.RangeTombstoneSize)
+		rangeReader, err := blockFetcher.FetchBlock(offset, ft.RangeTombstoneSize)
+		if err != nil {
+			ioManager.Close()
+			return nil, fmt.Errorf("failed to read range tombstones: %w", err)
+		}
+
+		iter := rangeReader.Iterator()
+		for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+			reader.rangeTombstones = append(reader.rangeTombstones, rangedel.Tombstone{
+				Start:  append([]byte(nil), iter.Key()...),
+				End:    append([]byte(nil), iter.Value()...),
+				SeqNum: reader.sequenceNumber(iter.SequenceNumber()),
+			})
+		}
+	}
+
+	// Remember the key range so lookups can skip the file
+	if err := reader.loadKeyRange(); err != nil {
+		ioManager.Close()
+		return nil, err
+	}
+
 	return reader, nil
 }
 
+// loadKeyRange reads the smallest and largest keys in the file. The index holds
+// the first key of each block, so only the last block has to be read.
+func (r *Reader) loadKeyRange() error {
+	indexIter := r.indexBlock.Iterator()
+	indexIter.SeekToFirst()
+	if !indexIter.Valid() {
+		return nil
+	}
+	r.firstKey = append([]byte(nil), indexIter.Key()...)
+
+	indexIter.SeekToLast()
+	locator, err := ParseBlockLocator(indexIter.Key(), indexIter.Value())
+	if err != nil {
+		return err
+	}
+
+	blockReader, err := r.readBlock(locator, true)
+	if err != nil {
+		return fmt.Errorf("failed to read last block: %w", err)
+	}
+
+	blockIter := blockReader.Iterator()
+	blockIter.SeekToLast()
+	if !blockIter.Valid() {
+		return fmt.Errorf("last block is empty: %w", ErrCorruption)
+	}
+	r.lastKey = append([]byte(nil), blockIter.Key()...)
+
+	return nil
+}
+
+// KeyRange returns the smallest and largest keys in the SSTable, or nil if it
+// has no entries
+func (r *Reader) KeyRange() (first, last []byte) {
+	return r.firstKey, r.lastKey
+}
+
+// BlockStartKeys returns the first key of each data block, in order. All
+// versions of a key are in the same block, and blocks hold about
+// IndexKeyInterval bytes, so the keys split the file into parts of about the
+// same size.
+func (r *Reader) BlockStartKeys() [][]byte {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var keys [][]byte
+	indexIter := r.indexBlock.Iterator()
+	for indexIter.SeekToFirst(); indexIter.Valid(); indexIter.Next() {
+		keys = append(keys, append([]byte(nil), indexIter.Key()...))
+	}
+	return keys
+}
+
+// InKeyRange returns true if key is between the smallest and largest keys in
+// the SSTable
+func (r *Reader) InKeyRange(key []byte) bool {
+	return r.firstKey != nil &&
+		bytes.Compare(key, r.firstKey) >= 0 &&
+		bytes.Compare(key, r.lastKey) <= 0
+}
+
+// HasBloomFilter returns true if the SSTable has bloom filters for its blocks
+func (r *Reader) HasBloomFilter() bool {
+	return r.hasBloomFilter
+}
+
+// MayContain returns false if the bloom filter of the block that would hold
+// key rules it out. It returns true if the SSTable has no bloom filters.
+func (r *Reader) MayContain(key []byte) bool {
+	if !r.hasBloomFilter {
+		return true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// All versions of a key are in the last block whose first key is <= key
+	indexIter := r.indexBlock.Iterator()
+	if !seekFloor(indexIter, key) {
+		// The key sorts before the first block
+		return false
+	}
+
+	locator, err := ParseBlockLocator(indexIter.Key(), indexIter.Value())
+	if err != nil {
+		return true
+	}
+
+	filter, ok := r.bloomFilters[locator.Offset]
+	if !ok {
+		return true
+	}
+	return filter.Contains(key)
+}
+
+// RangeTombstones returns the range deletions stored in the SSTable
+func (r *Reader) RangeTombstones() []rangedel.Tombstone {
+	return r.rangeTombstones
+}
+
 // FindBlockForKey finds the block that might contain the given key
 func (r *Reader) FindBlockForKey(key []byte) ([]BlockLocator, error) {
 	r.mu.RLock()
@@ -426,13 +563,11 @@ func (r *Reader) FindBlockForKey(key []byte) ([]BlockLocator, error) {
 	var blocks []BlockLocator
 	seenBlocks := make(map[uint64]bool)
 
-	// First try binary search for efficiency - find the first block
-	// where the first key is >= our target key
+	// The index holds the first key of each block, so the key can only be in
+	// the last block whose first key is <= key, or in a later one. If every
+	// block starts after the key, start from the beginning.
 	indexIter := r.indexBlock.Iterator()
-	indexIter.Seek(key)
-
-	// If the seek fails, start from beginning to check all blocks
-	if !indexIter.Valid() {
+	if !seekFloor(indexIter, key) {
 		indexIter.SeekToFirst()
 	}
 
@@ -484,43 +619,14 @@ func (r *Reader) Get(key []byte) ([]byte, error) {
 
 	// Search through each block
 	for _, locator := range blocks {
-		// Check bloom filter first if available
-		if r.hasBloomFilter {
-			// Find the bloom filter for this block
-			var shouldSkip = true
-			for _, bf := range r.bloomFilters {
-				if bf.blockOffset == locator.Offset {
-					// Found a bloom filter for this block
-					// If the key might be in this block, we'll check it
-					if bf.filter.Contains(key) {
-						shouldSkip = false
-					}
-					break
-				}
-			}
-
-			// If the bloom filter says the key definitely isn't in this block, skip it
-			if shouldSkip {
-				continue
-			}
+		// If the bloom filter says the key definitely isn't in this block, skip it
+		if filter, ok := r.bloomFilters[locator.Offset]; ok && !filter.Contains(key) {
+			continue
 		}
 
-		var blockReader *block.Reader
-
-		// Try to get the block from cache first
-		cachedBlock, found := r.blockCache.Get(locator.Offset)
-		if found {
-			// Use cached block
-			blockReader = cachedBlock
-		} else {
-			// Block not in cache, fetch from disk
-			blockReader, err = r.blockFetcher.FetchBlock(locator.Offset, locator.Size)
-			if err != nil {
-				return nil, err
-			}
-
-			// Add to cache for future use
-			r.blockCache.Put(locator.Offset, blockReader)
+		blockReader, err := r.readBlock(locator, true)
+		if err != nil {
+			return nil, err
 		}
 
 		// Search for the key in this block
@@ -532,8 +638,32 @@ func (r *Reader) Get(key []byte) ([]byte, error) {
 	return nil, ErrNotFound
 }
 
+// readBlock returns the data block at locator from the block cache, or reads
+// it from disk and adds it to the cache if fillCache is set
+func (r *Reader) readBlock(locator BlockLocator, fillCache bool) (*block.Reader, error) {
+	if cached, found := r.blockCache.Get(r.cacheID, locator.Offset); found {
+		return cached, nil
+	}
+
+	blockReader, err := r.blockFetcher.FetchBlock(locator.Offset, locator.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	if fillCache {
+		r.blockCache.Put(r.cacheID, locator.Offset, blockReader, int64(blockReader.Size()))
+	}
+	return blockReader, nil
+}
+
 // NewIterator returns an iterator over the entire SSTable
 func (r *Reader) NewIterator() *Iterator {
+	return r.NewIteratorWithOptions(DefaultReadOptions())
+}
+
+// NewIteratorWithOptions returns an iterator over the entire SSTable that
+// reads blocks as configured by options
+func (r *Reader) NewIteratorWithOptions(options ReadOptions) *Iterator {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -549,6 +679,7 @@ func (r *Reader) NewIterator() *Iterator {
 		dataBlockIter: nil,
 		currentBlock:  nil,
 		initialized:   false,
+		fillCache:     options.FillCache,
 	}
 }
 
@@ -557,6 +688,7 @@ func (r *Reader) Close() error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	r.blockCache.removeFile(r.cacheID)
 	return r.ioManager.Close()
 }
 
@@ -568,6 +700,15 @@ func (r *Reader) GetKeyCount() int {
 	return int(r.numEntries)
 }
 
+// Timestamp returns the creation time recorded in the footer, in nanoseconds
+// since the Unix epoch
+func (r *Reader) Timestamp() int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.ft.Timestamp
+}
+
 // FilePath returns the file path of this SSTable
 func (r *Reader) FilePath() string {
 	r.mu.RLock()
@@ -575,3 +716,17 @@ func (r *Reader) FilePath() string {
 
 	return r.ioManager.path
 }
+
+// GlobalSequence returns the sequence number given to every entry in the
+// file, or zero if the file's own sequence numbers are used
+func (r *Reader) GlobalSequence() uint64 {
+	return r.globalSeq
+}
+
+// sequenceNumber returns the sequence number of an entry stored with seqNum
+func (r *Reader) sequenceNumber(seqNum uint64) uint64 {
+	if r.globalSeq != 0 {
+		return r.globalSeq
+	}
+	return seqNum
+}
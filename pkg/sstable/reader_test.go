@@ -117,6 +117,69 @@ func TestReaderBasics(t *testing.T) {
 	}
 }
 
+func TestReaderKeyRange(t *testing.T) {
+	tempDir := t.TempDir()
+	sstablePath := filepath.Join(tempDir, "test-key-range.sst")
+
+	writer, err := NewWriter(sstablePath)
+	if err != nil {
+		t.Fatalf("Failed to create SSTable writer: %v", err)
+	}
+
+	// Enough data to span several blocks, with gaps between keys
+	numEntries := 2000
+	for i := 0; i < numEntries; i++ {
+		key := fmt.Sprintf("key%05d", i*2+1)
+		value := fmt.Sprintf("value%05d-padding-to-fill-blocks", i*2+1)
+		if err := writer.Add([]byte(key), []byte(value)); err != nil {
+			t.Fatalf("Failed to add entry: %v", err)
+		}
+	}
+
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish SSTable: %v", err)
+	}
+
+	reader, err := OpenReader(sstablePath)
+	if err != nil {
+		t.Fatalf("Failed to open SSTable: %v", err)
+	}
+	defer reader.Close()
+
+	first, last := reader.KeyRange()
+	if string(first) != "key00001" || string(last) != fmt.Sprintf("key%05d", numEntries*2-1) {
+		t.Errorf("Expected key range key00001-key%05d, got %s-%s", numEntries*2-1, first, last)
+	}
+
+	for key, want := range map[string]bool{
+		"key00000": false, "key00001": true, "key02000": true,
+		fmt.Sprintf("key%05d", numEntries*2-1): true, "key99999": false,
+	} {
+		if got := reader.InKeyRange([]byte(key)); got != want {
+			t.Errorf("InKeyRange(%s): expected %v, got %v", key, want, got)
+		}
+	}
+
+	// Bloom filters never rule out a key that is present
+	if !reader.HasBloomFilter() {
+		t.Fatalf("Expected the SSTable to have bloom filters")
+	}
+	ruledOut := 0
+	for i := 0; i < numEntries; i++ {
+		if !reader.MayContain([]byte(fmt.Sprintf("key%05d", i*2+1))) {
+			t.Fatalf("MayContain(key%05d) returned false for a present key", i*2+1)
+		}
+		if !reader.MayContain([]byte(fmt.Sprintf("key%05d", i*2))) {
+			ruledOut++
+		}
+	}
+
+	// With a 1% false positive rate nearly every missing key is ruled out
+	if ruledOut < numEntries*9/10 {
+		t.Errorf("Expected bloom filters to rule out most missing keys, ruled out %d of %d", ruledOut, numEntries)
+	}
+}
+
 func TestReaderCorruption(t *testing.T) {
 	// Create a temporary directory for the test
 	tempDir := t.TempDir()
@@ -7,9 +7,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	bloomfilter "github.com/KevoDB/kevo/pkg/bloom_filter"
+	"github.com/KevoDB/kevo/pkg/common/rangedel"
+	"github.com/KevoDB/kevo/pkg/common/ratelimit"
+	"github.com/KevoDB/kevo/pkg/config"
 	"github.com/KevoDB/kevo/pkg/sstable/block"
+	"github.com/KevoDB/kevo/pkg/sstable/compression"
 	"github.com/KevoDB/kevo/pkg/sstable/footer"
 )
 
@@ -18,6 +23,9 @@ type FileManager struct {
 	path    string
 	tmpPath string
 	file    *os.File
+	// Limits the write rate, nil for no limit
+	limiter  *ratelimit.Limiter
+	priority ratelimit.Priority
 }
 
 // NewFileManager creates a new FileManager for the given file path
@@ -38,8 +46,15 @@ func NewFileManager(path string) (*FileManager, error) {
 	}, nil
 }
 
+// SetRateLimiter makes writes wait for limiter with the given priority
+func (fm *FileManager) SetRateLimiter(limiter *ratelimit.Limiter, priority ratelimit.Priority) {
+	fm.limiter = limiter
+	fm.priority = priority
+}
+
 // Write writes data to the file at the current position
 func (fm *FileManager) Write(data []byte) (int, error) {
+	fm.limiter.Wait(len(data), fm.priority)
 	return fm.file.Write(data)
 }
 
@@ -114,6 +129,16 @@ func (bm *BlockManager) AddWithSequence(key, value []byte, seqNum uint64) error
 	return bm.builder.AddWithSequence(key, value, seqNum)
 }
 
+// AddMergeWithSequence adds a merge operand with a sequence number to the current block
+func (bm *BlockManager) AddMergeWithSequence(key, operand []byte, seqNum uint64) error {
+	return bm.builder.AddMergeWithSequence(key, operand, seqNum)
+}
+
+// AddExpiringWithSequence adds an expiring key-value pair with a sequence number to the current block
+func (bm *BlockManager) AddExpiringWithSequence(key, value []byte, seqNum, expireAt uint64) error {
+	return bm.builder.AddExpiringWithSequence(key, value, seqNum, expireAt)
+}
+
 // EstimatedSize returns the estimated size of the current block
 func (bm *BlockManager) EstimatedSize() uint32 {
 	return bm.builder.EstimatedSize()
@@ -249,6 +274,13 @@ type Writer struct {
 	bloomFilterEnabled bool
 	bloomFilters       []*BlockBloomFilterBuilder
 	currentBloomFilter *BlockBloomFilterBuilder
+	// Range deletions, written to their own block
+	rangeTombstones []rangedel.Tombstone
+	// Codec blocks are compressed with
+	compression      compression.Type
+	compressionLevel int
+	// Creation time recorded in the footer, zero for the time of Finish
+	timestamp int64
 }
 
 // Options for configuring the SSTable writer
@@ -257,6 +289,17 @@ type WriterOptions struct {
 	EnableBloomFilter bool
 	// Expected entries per block (helps size bloom filters appropriately)
 	ExpectedEntriesPerBlock uint64
+	// Codec data blocks are compressed with
+	Compression compression.Type
+	// Compression level, only used by zstd where 0 selects the default level
+	CompressionLevel int
+	// Creation time recorded in the footer in Unix nanoseconds. Zero records
+	// the time the file is finished.
+	Timestamp int64
+	// Limiter the writes wait for, nil for no limit, and the priority they
+	// wait with
+	RateLimiter *ratelimit.Limiter
+	IOPriority  ratelimit.Priority
 }
 
 // DefaultWriterOptions returns the default options for the writer
@@ -264,9 +307,21 @@ func DefaultWriterOptions() WriterOptions {
 	return WriterOptions{
 		EnableBloomFilter:       true,
 		ExpectedEntriesPerBlock: 1000, // Reasonable default for many workloads
+		Compression:             compression.Snappy,
 	}
 }
 
+// WriterOptionsFromConfig returns the default writer options with the
+// compression settings of cfg, which must be valid
+func WriterOptionsFromConfig(cfg *config.Config) WriterOptions {
+	options := DefaultWriterOptions()
+	if codec, err := compression.ParseType(cfg.SSTableCompression); err == nil {
+		options.Compression = codec
+	}
+	options.CompressionLevel = cfg.SSTableCompressionLevel
+	return options
+}
+
 // NewWriter creates a new SSTable writer with default options
 func NewWriter(path string) (*Writer, error) {
 	return NewWriterWithOptions(path, DefaultWriterOptions())
@@ -278,6 +333,7 @@ func NewWriterWithOptions(path string, options WriterOptions) (*Writer, error) {
 	if err != nil {
 		return nil, err
 	}
+	fileManager.SetRateLimiter(options.RateLimiter, options.IOPriority)
 
 	w := &Writer{
 		fileManager:        fileManager,
@@ -287,6 +343,9 @@ func NewWriterWithOptions(path string, options WriterOptions) (*Writer, error) {
 		entriesAdded:       0,
 		bloomFilterEnabled: options.EnableBloomFilter,
 		bloomFilters:       make([]*BlockBloomFilterBuilder, 0),
+		compression:        options.Compression,
+		compressionLevel:   options.CompressionLevel,
+		timestamp:          options.Timestamp,
 	}
 
 	// Initialize the first bloom filter if enabled
@@ -306,6 +365,33 @@ func (w *Writer) Add(key, value []byte) error {
 // AddWithSequence adds a key-value pair with a sequence number to the SSTable
 // Keys must be added in sorted order
 func (w *Writer) AddWithSequence(key, value []byte, seqNum uint64) error {
+	return w.add(key, value, seqNum, false, 0)
+}
+
+// AddMergeWithSequence adds a merge operand with a sequence number to the SSTable
+// Keys must be added in sorted order
+func (w *Writer) AddMergeWithSequence(key, operand []byte, seqNum uint64) error {
+	return w.add(key, operand, seqNum, true, 0)
+}
+
+// AddExpiringWithSequence adds a key-value pair with a sequence number that
+// expires at expireAt, in Unix nanoseconds, to the SSTable.
+// Keys must be added in sorted order
+func (w *Writer) AddExpiringWithSequence(key, value []byte, seqNum, expireAt uint64) error {
+	return w.add(key, value, seqNum, false, expireAt)
+}
+
+// add adds an entry to the SSTable
+func (w *Writer) add(key, value []byte, seqNum uint64, mergeOperand bool, expireAt uint64) error {
+	// Flush the block if it's getting too large
+	// Use IndexKeyInterval to determine when to flush based on accumulated data size.
+	// All versions of a key are kept in the same block, so only flush on a key change.
+	if w.blockManager.EstimatedSize() >= IndexKeyInterval && !bytes.Equal(key, w.lastKey) {
+		if err := w.flushBlock(); err != nil {
+			return err
+		}
+	}
+
 	// Keep track of first and last keys
 	if w.entriesAdded == 0 {
 		w.firstKey = append([]byte(nil), key...)
@@ -318,20 +404,20 @@ func (w *Writer) AddWithSequence(key, value []byte, seqNum uint64) error {
 	}
 
 	// Add to block with sequence number
-	if err := w.blockManager.AddWithSequence(key, value, seqNum); err != nil {
+	var err error
+	if mergeOperand {
+		err = w.blockManager.AddMergeWithSequence(key, value, seqNum)
+	} else if expireAt != 0 {
+		err = w.blockManager.AddExpiringWithSequence(key, value, seqNum, expireAt)
+	} else {
+		err = w.blockManager.AddWithSequence(key, value, seqNum)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to add to block: %w", err)
 	}
 
 	w.entriesAdded++
 
-	// Flush the block if it's getting too large
-	// Use IndexKeyInterval to determine when to flush based on accumulated data size
-	if w.blockManager.EstimatedSize() >= IndexKeyInterval {
-		if err := w.flushBlock(); err != nil {
-			return err
-		}
-	}
-
 	return nil
 }
 
@@ -341,6 +427,43 @@ func (w *Writer) AddTombstone(key []byte) error {
 	return w.Add(key, nil)
 }
 
+// AddRangeTombstone adds a deletion of every key in [start, end) written before seqNum.
+// Range tombstones may be added in any order.
+func (w *Writer) AddRangeTombstone(start, end []byte, seqNum uint64) error {
+	if err := rangedel.Validate(start, end); err != nil {
+		return err
+	}
+
+	w.rangeTombstones = append(w.rangeTombstones, rangedel.Tombstone{
+		Start:  append([]byte(nil), start...),
+		End:    append([]byte(nil), end...),
+		SeqNum: seqNum,
+	})
+	return nil
+}
+
+// serializeRangeTombstones encodes the range tombstones as a block keyed by
+// start key, newest first, with the end key as the value
+func (w *Writer) serializeRangeTombstones() ([]byte, error) {
+	sort.SliceStable(w.rangeTombstones, func(i, j int) bool {
+		cmp := bytes.Compare(w.rangeTombstones[i].Start, w.rangeTombstones[j].Start)
+		return cmp < 0 || (cmp == 0 && w.rangeTombstones[i].SeqNum > w.rangeTombstones[j].SeqNum)
+	})
+
+	builder := block.NewBuilder()
+	for _, t := range w.rangeTombstones {
+		if err := builder.AddWithSequence(t.Start, t.End, t.SeqNum); err != nil {
+			return nil, fmt.Errorf("failed to add range tombstone: %w", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := builder.Finish(&buf); err != nil {
+		return nil, fmt.Errorf("failed to finish range tombstone block: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
 // flushBlock writes the current block to the file and adds an index entry
 func (w *Writer) flushBlock() error {
 	// Skip if the block is empty
@@ -358,11 +481,15 @@ func (w *Writer) flushBlock() error {
 	}
 	firstKey := entries[0].Key
 
-	// Serialize the block
+	// Serialize and compress the block
 	blockData, err := w.blockManager.Serialize()
 	if err != nil {
 		return err
 	}
+	blockData, err = encodeBlock(blockData, w.compression, w.compressionLevel)
+	if err != nil {
+		return err
+	}
 
 	blockSize := uint32(len(blockData))
 
@@ -382,18 +509,18 @@ func (w *Writer) flushBlock() error {
 		FirstKey:    firstKey,
 	})
 
+	// Update offset for next block
+	w.dataOffset += uint64(n)
+
 	// Finalize the current bloom filter for this block
 	if w.bloomFilterEnabled && w.currentBloomFilter != nil {
 		// Store the bloom filter for this block
 		w.bloomFilters = append(w.bloomFilters, w.currentBloomFilter)
 
-		// Create a new bloom filter for the next block
+		// Create a new bloom filter for the next block, which starts at the updated offset
 		w.currentBloomFilter = NewBlockBloomFilterBuilder(w.dataOffset, DefaultWriterOptions().ExpectedEntriesPerBlock)
 	}
 
-	// Update offset for next block
-	w.dataOffset += uint64(n)
-
 	// Reset the block builder for next block
 	w.blockManager.Reset()
 
@@ -461,6 +588,30 @@ func (w *Writer) Finish() (err error) {
 		}
 	}
 
+	// Write range tombstones right before the index
+	var rangeTombstoneSize uint32
+	if len(w.rangeTombstones) > 0 {
+		rangeData, err := w.serializeRangeTombstones()
+		if err != nil {
+			return err
+		}
+		rangeData, err = encodeBlock(rangeData, w.compression, w.compressionLevel)
+		if err != nil {
+			return err
+		}
+
+		n, err := w.fileManager.Write(rangeData)
+		if err != nil {
+			return fmt.Errorf("failed to write range tombstone block: %w", err)
+		}
+		if n != len(rangeData) {
+			return fmt.Errorf("wrote incomplete range tombstone block: %d of %d bytes", n, len(rangeData))
+		}
+
+		w.dataOffset += uint64(n)
+		rangeTombstoneSize = uint32(n)
+	}
+
 	// Create index block
 	indexOffset := w.dataOffset
 
@@ -469,10 +620,16 @@ func (w *Writer) Finish() (err error) {
 		return err
 	}
 
-	// Serialize and write the index block
-	indexData, err := w.indexBuilder.Serialize()
-	if err != nil {
-		return err
+	// Serialize and write the index block. A table holding only range
+	// tombstones has no data blocks to index.
+	var indexData []byte
+	if len(w.indexBuilder.entries) == 0 && rangeTombstoneSize > 0 {
+		indexData = block.EmptyBlock()
+	} else {
+		indexData, err = w.indexBuilder.Serialize()
+		if err != nil {
+			return err
+		}
 	}
 
 	indexSize := uint32(len(indexData))
@@ -499,6 +656,10 @@ func (w *Writer) Finish() (err error) {
 		bloomFilterOffset,
 		bloomFilterSize,
 	)
+	ft.RangeTombstoneSize = rangeTombstoneSize
+	if w.timestamp != 0 {
+		ft.Timestamp = w.timestamp
+	}
 
 	// Serialize footer
 	footerData := ft.Encode()
@@ -521,6 +682,11 @@ func (w *Writer) Finish() (err error) {
 	return w.fileManager.FinalizeFile()
 }
 
+// DataSize returns the number of bytes of data blocks written so far
+func (w *Writer) DataSize() uint64 {
+	return w.dataOffset
+}
+
 // Abort cancels the SSTable writing process
 func (w *Writer) Abort() error {
 	return w.fileManager.Cleanup()
@@ -1,10 +1,13 @@
 package sstable
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/KevoDB/kevo/pkg/sstable/compression"
 )
 
 func TestWriterBasics(t *testing.T) {
@@ -190,3 +193,157 @@ func TestWriterTombstone(t *testing.T) {
 		}
 	}
 }
+
+func TestWriterRangeTombstones(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// A table with point entries and range tombstones
+	sstablePath := filepath.Join(tempDir, "ranges.sst")
+	writer, err := NewWriter(sstablePath)
+	if err != nil {
+		t.Fatalf("Failed to create SSTable writer: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("key%05d", i)
+		if err := writer.AddWithSequence([]byte(key), []byte("value"), uint64(i+1)); err != nil {
+			t.Fatalf("Failed to add entry: %v", err)
+		}
+	}
+	if err := writer.AddRangeTombstone([]byte("key00005"), []byte("key00008"), 20); err != nil {
+		t.Fatalf("Failed to add range tombstone: %v", err)
+	}
+	if err := writer.AddRangeTombstone([]byte("key00002"), []byte("key00004"), 15); err != nil {
+		t.Fatalf("Failed to add range tombstone: %v", err)
+	}
+	if err := writer.AddRangeTombstone([]byte("b"), []byte("a"), 16); err == nil {
+		t.Error("Expected an error for an empty range")
+	}
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish SSTable: %v", err)
+	}
+
+	reader, err := OpenReader(sstablePath)
+	if err != nil {
+		t.Fatalf("Failed to open SSTable: %v", err)
+	}
+	defer reader.Close()
+
+	tombstones := reader.RangeTombstones()
+	if len(tombstones) != 2 {
+		t.Fatalf("Expected 2 range tombstones, got %d", len(tombstones))
+	}
+	if string(tombstones[0].Start) != "key00002" || string(tombstones[0].End) != "key00004" || tombstones[0].SeqNum != 15 {
+		t.Errorf("Unexpected first range tombstone: %+v", tombstones[0])
+	}
+	if string(tombstones[1].Start) != "key00005" || string(tombstones[1].End) != "key00008" || tombstones[1].SeqNum != 20 {
+		t.Errorf("Unexpected second range tombstone: %+v", tombstones[1])
+	}
+
+	// Point entries are unaffected by the range tombstone block
+	count := 0
+	iter := reader.NewIterator()
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		count++
+	}
+	if count != 10 {
+		t.Errorf("Expected 10 entries, got %d", count)
+	}
+
+	// A table holding only range tombstones
+	onlyPath := filepath.Join(tempDir, "only-ranges.sst")
+	writer, err = NewWriter(onlyPath)
+	if err != nil {
+		t.Fatalf("Failed to create SSTable writer: %v", err)
+	}
+	if err := writer.AddRangeTombstone([]byte("a"), []byte("z"), 7); err != nil {
+		t.Fatalf("Failed to add range tombstone: %v", err)
+	}
+	if err := writer.Finish(); err != nil {
+		t.Fatalf("Failed to finish SSTable: %v", err)
+	}
+
+	onlyReader, err := OpenReader(onlyPath)
+	if err != nil {
+		t.Fatalf("Failed to open SSTable: %v", err)
+	}
+	defer onlyReader.Close()
+
+	if len(onlyReader.RangeTombstones()) != 1 {
+		t.Errorf("Expected 1 range tombstone, got %d", len(onlyReader.RangeTombstones()))
+	}
+	onlyIter := onlyReader.NewIterator()
+	if onlyIter.SeekToFirst(); onlyIter.Valid() {
+		t.Error("Expected no point entries")
+	}
+	if _, err := onlyReader.Get([]byte("m")); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestWriterCompression(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// writeTable writes JSON values with the given codec and returns the file size
+	writeTable := func(codec compression.Type) (string, int64) {
+		path := filepath.Join(tempDir, codec.String()+".sst")
+		options := DefaultWriterOptions()
+		options.Compression = codec
+
+		writer, err := NewWriterWithOptions(path, options)
+		if err != nil {
+			t.Fatalf("Failed to create SSTable writer: %v", err)
+		}
+		for i := 0; i < 2000; i++ {
+			key := fmt.Sprintf("user:%05d", i)
+			value := fmt.Sprintf(`{"id":%d,"name":"user %d","email":"user%d@example.com","active":true}`, i, i, i)
+			if err := writer.Add([]byte(key), []byte(value)); err != nil {
+				t.Fatalf("Failed to add entry: %v", err)
+			}
+		}
+		if err := writer.AddRangeTombstone([]byte("user:00100"), []byte("user:00200"), 1); err != nil {
+			t.Fatalf("Failed to add range tombstone: %v", err)
+		}
+		if err := writer.Finish(); err != nil {
+			t.Fatalf("Failed to finish SSTable: %v", err)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Failed to stat SSTable: %v", err)
+		}
+		return path, info.Size()
+	}
+
+	_, uncompressedSize := writeTable(compression.None)
+
+	for _, codec := range []compression.Type{compression.Snappy, compression.Zstd, compression.LZ4} {
+		path, size := writeTable(codec)
+		if size >= uncompressedSize {
+			t.Errorf("%s: expected compressed file to be smaller than %d bytes, got %d", codec, uncompressedSize, size)
+		}
+
+		reader, err := OpenReader(path)
+		if err != nil {
+			t.Fatalf("%s: failed to open SSTable: %v", codec, err)
+		}
+
+		value, err := reader.Get([]byte("user:01234"))
+		if err != nil || !bytes.Contains(value, []byte(`"id":1234`)) {
+			t.Errorf("%s: unexpected value %q (%v)", codec, value, err)
+		}
+
+		count := 0
+		iter := reader.NewIterator()
+		for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+			count++
+		}
+		if count != 2000 {
+			t.Errorf("%s: expected 2000 entries, got %d", codec, count)
+		}
+
+		if tombstones := reader.RangeTombstones(); len(tombstones) != 1 {
+			t.Errorf("%s: expected 1 range tombstone, got %d", codec, len(tombstones))
+		}
+		reader.Close()
+	}
+}
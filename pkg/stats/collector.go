@@ -11,17 +11,20 @@ type OperationType string
 
 // Common operation types
 const (
-	OpPut        OperationType = "put"
-	OpGet        OperationType = "get"
-	OpDelete     OperationType = "delete"
-	OpTxBegin    OperationType = "tx_begin"
-	OpTxCommit   OperationType = "tx_commit"
-	OpTxRollback OperationType = "tx_rollback"
-	OpFlush      OperationType = "flush"
-	OpCompact    OperationType = "compact"
-	OpSeek       OperationType = "seek"
-	OpScan       OperationType = "scan"
-	OpScanRange  OperationType = "scan_range"
+	OpPut         OperationType = "put"
+	OpGet         OperationType = "get"
+	OpDelete      OperationType = "delete"
+	OpDeleteRange OperationType = "delete_range"
+	OpMerge       OperationType = "merge"
+	OpTxBegin     OperationType = "tx_begin"
+	OpTxCommit    OperationType = "tx_commit"
+	OpTxRollback  OperationType = "tx_rollback"
+	OpFlush       OperationType = "flush"
+	OpCompact     OperationType = "compact"
+	OpSeek        OperationType = "seek"
+	OpScan        OperationType = "scan"
+	OpScanRange   OperationType = "scan_range"
+	OpIngest      OperationType = "ingest"
 )
 
 // AtomicCollector provides centralized statistics collection with minimal contention
@@ -48,6 +51,11 @@ type AtomicCollector struct {
 	flushCount      atomic.Uint64
 	compactionCount atomic.Uint64
 
+	// Bloom filter effectiveness
+	bloomUseful        atomic.Uint64 // Lookups the filter ruled out
+	bloomTruePositive  atomic.Uint64 // Lookups the filter passed that found the key
+	bloomFalsePositive atomic.Uint64 // Lookups the filter passed that didn't find the key
+
 	// Recovery statistics
 	recoveryStats RecoveryStats
 
@@ -184,6 +192,18 @@ func (c *AtomicCollector) TrackCompaction() {
 	c.compactionCount.Add(1)
 }
 
+// TrackBloomFilter records the outcome of a bloom filter check
+func (c *AtomicCollector) TrackBloomFilter(mayContain, found bool) {
+	switch {
+	case !mayContain:
+		c.bloomUseful.Add(1)
+	case found:
+		c.bloomTruePositive.Add(1)
+	default:
+		c.bloomFalsePositive.Add(1)
+	}
+}
+
 // StartRecovery initializes recovery statistics
 func (c *AtomicCollector) StartRecovery() time.Time {
 	// Reset recovery stats
@@ -227,6 +247,9 @@ func (c *AtomicCollector) GetStats() map[string]interface{} {
 	stats["total_bytes_written"] = c.totalBytesWritten.Load()
 	stats["flush_count"] = c.flushCount.Load()
 	stats["compaction_count"] = c.compactionCount.Load()
+	stats["bloom_filter_useful"] = c.bloomUseful.Load()
+	stats["bloom_filter_true_positive"] = c.bloomTruePositive.Load()
+	stats["bloom_filter_false_positive"] = c.bloomFalsePositive.Load()
 
 	// Add error statistics
 	c.errorsMu.RLock()
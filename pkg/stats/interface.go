@@ -36,6 +36,11 @@ type Collector interface {
 	// TrackCompaction increments the compaction counter
 	TrackCompaction()
 
+	// TrackBloomFilter records the outcome of a bloom filter check on a point
+	// lookup. mayContain is the answer of the filter and found reports whether
+	// the key was then found.
+	TrackBloomFilter(mayContain, found bool)
+
 	// StartRecovery initializes recovery statistics
 	StartRecovery() time.Time
 
@@ -184,6 +184,17 @@ func (it *BufferIterator) Next() bool {
 	return true
 }
 
+// Prev moves back to the previous key
+func (it *BufferIterator) Prev() bool {
+	if it.position <= 0 {
+		it.position = -1
+		return false
+	}
+
+	it.position--
+	return true
+}
+
 // Key returns the current key
 func (it *BufferIterator) Key() []byte {
 	if !it.Valid() {
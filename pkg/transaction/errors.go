@@ -13,6 +13,10 @@ var (
 	// ErrKeyNotFound is returned when a key doesn't exist
 	ErrKeyNotFound = errors.New("key not found")
 
+	// ErrConflict is returned by Commit when data read by the transaction was
+	// modified by another transaction that committed first
+	ErrConflict = errors.New("transaction conflict: data read by the transaction was modified")
+
 	// ErrInvalidEngine is returned when an incompatible engine type is provided
 	ErrInvalidEngine = errors.New("invalid engine type")
 )
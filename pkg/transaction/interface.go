@@ -18,6 +18,40 @@ const (
 	ReadWrite
 )
 
+// IsolationLevel defines the isolation guarantees of a read-write transaction
+type IsolationLevel int
+
+const (
+	// SnapshotIsolation reads from a snapshot and aborts on commit if a key read
+	// with Get was modified by another transaction
+	SnapshotIsolation IsolationLevel = iota
+
+	// Serializable additionally aborts on commit if another transaction wrote
+	// into a key range read by an iterator, preventing phantoms and write skew
+	Serializable
+)
+
+// String returns the name of the isolation level
+func (l IsolationLevel) String() string {
+	switch l {
+	case SnapshotIsolation:
+		return "snapshot"
+	case Serializable:
+		return "serializable"
+	default:
+		return "unknown"
+	}
+}
+
+// TxOptions configures a new transaction
+type TxOptions struct {
+	// ReadOnly transactions can't write and never conflict
+	ReadOnly bool
+
+	// Isolation selects the conflict checks performed on commit
+	Isolation IsolationLevel
+}
+
 // Transaction represents a database transaction that provides ACID guarantees
 // This matches the interfaces.Transaction interface from pkg/engine/interfaces/transaction.go
 type Transaction interface {
@@ -57,6 +91,9 @@ type Registry interface {
 	// Begin starts a new transaction
 	Begin(ctx context.Context, eng interface{}, readOnly bool) (string, error)
 
+	// BeginWithOptions starts a new transaction with the given options
+	BeginWithOptions(ctx context.Context, eng interface{}, opts TxOptions) (string, error)
+
 	// Get retrieves a transaction by ID
 	Get(txID string) (Transaction, bool)
 
@@ -1,6 +1,7 @@
 package transaction
 
 import (
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -16,7 +17,8 @@ type Manager struct {
 	// Statistics collector
 	stats stats.Collector
 
-	// Transaction isolation lock
+	// Transaction isolation lock. Transactions on versioned storage don't
+	// take it, the storage checks their reads as it applies their writes.
 	txLock sync.RWMutex
 
 	// Transaction counters
@@ -52,8 +54,23 @@ func NewManagerWithTTL(storage StorageBackend, stats stats.Collector, readOnlyTT
 	}
 }
 
-// BeginTransaction starts a new transaction
+// BeginTransaction starts a new transaction with snapshot isolation
 func (m *Manager) BeginTransaction(readOnly bool) (Transaction, error) {
+	return m.BeginTransactionWithOptions(TxOptions{ReadOnly: readOnly})
+}
+
+// BeginTransactionWithOptions starts a new transaction with the given options
+func (m *Manager) BeginTransactionWithOptions(opts TxOptions) (Transaction, error) {
+	// Versioned storage lets transactions read from a snapshot instead of locking
+	versioned, optimistic := m.storage.(VersionedStorage)
+	var readSeq uint64
+	if optimistic {
+		var err error
+		if readSeq, err = versioned.AcquireSnapshot(); err != nil {
+			return nil, fmt.Errorf("failed to acquire snapshot: %w", err)
+		}
+	}
+
 	// Track transaction start
 	if m.stats != nil {
 		m.stats.TrackOperation(stats.OpTxBegin)
@@ -62,7 +79,7 @@ func (m *Manager) BeginTransaction(readOnly bool) (Transaction, error) {
 
 	// Convert to transaction mode
 	mode := ReadWrite
-	if readOnly {
+	if opts.ReadOnly {
 		mode = ReadOnly
 	}
 
@@ -80,6 +97,7 @@ func (m *Manager) BeginTransaction(readOnly bool) (Transaction, error) {
 	tx := &TransactionImpl{
 		storage:        m.storage,
 		mode:           mode,
+		isolation:      opts.Isolation,
 		buffer:         NewBuffer(),
 		rwLock:         &m.txLock,
 		stats:          m,
@@ -91,6 +109,15 @@ func (m *Manager) BeginTransaction(readOnly bool) (Transaction, error) {
 	// Set transaction as active
 	tx.active.Store(true)
 
+	// Optimistic transactions don't hold the lock, conflicts are checked on commit
+	if optimistic {
+		tx.versioned = versioned
+		tx.readSeq = readSeq
+		tx.readSet = make(map[string]struct{})
+		tx.hasSnapshot.Store(true)
+		return tx, nil
+	}
+
 	// Acquire appropriate lock
 	if mode == ReadOnly {
 		m.txLock.RLock()
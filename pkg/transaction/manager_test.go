@@ -1,9 +1,15 @@
 package transaction
 
 import (
+	"fmt"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/KevoDB/kevo/pkg/config"
+	"github.com/KevoDB/kevo/pkg/engine/storage"
+	"github.com/KevoDB/kevo/pkg/stats"
 )
 
 func TestManagerBasics(t *testing.T) {
@@ -248,3 +254,253 @@ func TestReadOnlyConcurrency(t *testing.T) {
 		t.Errorf("Expected 0 active transactions, got %v", stats["tx_active"])
 	}
 }
+
+// newVersionedStorage creates a storage manager that supports optimistic transactions
+func newVersionedStorage(t *testing.T) *storage.Manager {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	cfg := config.NewDefaultConfig(tempDir)
+	cfg.SSTDir = filepath.Join(tempDir, "sst")
+	cfg.WALDir = filepath.Join(tempDir, "wal")
+
+	manager, err := storage.NewManager(cfg, stats.NewAtomicCollector())
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+	t.Cleanup(func() { manager.Close() })
+
+	return manager
+}
+
+func TestOptimisticTransactionConflict(t *testing.T) {
+	backend := newVersionedStorage(t)
+	manager := NewManager(backend, &StatsCollectorMock{})
+
+	if err := backend.Put([]byte("counter"), []byte{0}); err != nil {
+		t.Fatalf("Failed to initialize counter: %v", err)
+	}
+
+	// Both read-write transactions can be open at the same time
+	tx1, err := manager.BeginTransaction(false)
+	if err != nil {
+		t.Fatalf("Failed to begin transaction 1: %v", err)
+	}
+	tx2, err := manager.BeginTransaction(false)
+	if err != nil {
+		t.Fatalf("Failed to begin transaction 2: %v", err)
+	}
+
+	for i, tx := range []Transaction{tx1, tx2} {
+		value, err := tx.Get([]byte("counter"))
+		if err != nil {
+			t.Fatalf("Failed to read counter in transaction %d: %v", i+1, err)
+		}
+		if err := tx.Put([]byte("counter"), []byte{value[0] + 1}); err != nil {
+			t.Fatalf("Failed to write counter in transaction %d: %v", i+1, err)
+		}
+	}
+
+	// The first commit wins, the second read a value that has since changed
+	if err := tx1.Commit(); err != nil {
+		t.Fatalf("Failed to commit transaction 1: %v", err)
+	}
+	if err := tx2.Commit(); err != ErrConflict {
+		t.Fatalf("Expected ErrConflict committing transaction 2, got %v", err)
+	}
+
+	value, err := backend.Get([]byte("counter"))
+	if err != nil {
+		t.Fatalf("Failed to read counter: %v", err)
+	}
+	if value[0] != 1 {
+		t.Errorf("Expected counter value 1, got %d", value[0])
+	}
+
+	stats := manager.GetTransactionStats()
+	if stats["tx_completed"] != uint64(1) {
+		t.Errorf("Expected 1 transaction completed, got %v", stats["tx_completed"])
+	}
+	if stats["tx_aborted"] != uint64(1) {
+		t.Errorf("Expected 1 transaction aborted, got %v", stats["tx_aborted"])
+	}
+
+	// Keys that were never read don't cause conflicts, even if absent
+	tx3, _ := manager.BeginTransaction(false)
+	tx4, _ := manager.BeginTransaction(false)
+	if _, err := tx3.Get([]byte("missing")); err == nil {
+		t.Error("Expected missing key to be absent")
+	}
+	tx3.Put([]byte("a"), []byte("a1"))
+	tx4.Put([]byte("missing"), []byte("now present"))
+	if err := tx4.Commit(); err != nil {
+		t.Fatalf("Failed to commit transaction 4: %v", err)
+	}
+	if err := tx3.Commit(); err != ErrConflict {
+		t.Errorf("Expected ErrConflict after a read key was created, got %v", err)
+	}
+
+	// All snapshots are released once the transactions finish
+	if live := backend.LiveSnapshots(); len(live) != 0 {
+		t.Errorf("Expected no live snapshots, got %v", live)
+	}
+}
+
+func TestOptimisticTransactionSnapshotReads(t *testing.T) {
+	backend := newVersionedStorage(t)
+	manager := NewManager(backend, &StatsCollectorMock{})
+
+	if err := backend.Put([]byte("key1"), []byte("before")); err != nil {
+		t.Fatalf("Failed to put key: %v", err)
+	}
+
+	tx, err := manager.BeginTransaction(true)
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Writes committed after the transaction began are not visible
+	if err := backend.Put([]byte("key1"), []byte("after")); err != nil {
+		t.Fatalf("Failed to put key: %v", err)
+	}
+	if err := backend.Put([]byte("key2"), []byte("after")); err != nil {
+		t.Fatalf("Failed to put key: %v", err)
+	}
+
+	value, err := tx.Get([]byte("key1"))
+	if err != nil || string(value) != "before" {
+		t.Errorf("Expected snapshot value 'before', got %q (err: %v)", value, err)
+	}
+
+	iter := tx.NewIterator()
+	count := 0
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		count++
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 key visible to the transaction, got %d", count)
+	}
+}
+
+func TestOptimisticTransactionsDisjointKeys(t *testing.T) {
+	backend := newVersionedStorage(t)
+	manager := NewManager(backend, &StatsCollectorMock{})
+
+	numTransactions := 20
+	var started, wg sync.WaitGroup
+	started.Add(numTransactions)
+	wg.Add(numTransactions)
+	errs := make(chan error, numTransactions)
+
+	for i := 0; i < numTransactions; i++ {
+		go func(i int) {
+			defer wg.Done()
+
+			tx, err := manager.BeginTransaction(false)
+			started.Done()
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			// Wait until every transaction is open, which deadlocks if
+			// read-write transactions are serialized
+			started.Wait()
+
+			key := []byte(fmt.Sprintf("key%02d", i))
+			if _, err := tx.Get(key); err == nil {
+				errs <- fmt.Errorf("key %s should not exist yet", key)
+				return
+			}
+			tx.Put(key, []byte("value"))
+			errs <- tx.Commit()
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Unexpected transaction error: %v", err)
+		}
+	}
+}
+
+func TestSerializableTransactionRangeConflict(t *testing.T) {
+	// reserve runs the "at most one reservation under res/" invariant check
+	reserve := func(tx Transaction, key string) error {
+		iter := tx.NewRangeIterator([]byte("res/"), []byte("res0"))
+		count := 0
+		for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+			if !iter.IsTombstone() {
+				count++
+			}
+		}
+		if count >= 1 {
+			return fmt.Errorf("reservation limit reached")
+		}
+		return tx.Put([]byte(key), []byte("reserved"))
+	}
+
+	tests := []struct {
+		isolation   IsolationLevel
+		expectError error
+	}{
+		{SnapshotIsolation, nil},
+		{Serializable, ErrConflict},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.isolation.String(), func(t *testing.T) {
+			backend := newVersionedStorage(t)
+			manager := NewManager(backend, &StatsCollectorMock{})
+
+			opts := TxOptions{Isolation: tc.isolation}
+			tx1, err := manager.BeginTransactionWithOptions(opts)
+			if err != nil {
+				t.Fatalf("Failed to begin transaction 1: %v", err)
+			}
+			tx2, err := manager.BeginTransactionWithOptions(opts)
+			if err != nil {
+				t.Fatalf("Failed to begin transaction 2: %v", err)
+			}
+
+			// Both transactions see no reservations and add one
+			if err := reserve(tx1, "res/alice"); err != nil {
+				t.Fatalf("Transaction 1 failed to reserve: %v", err)
+			}
+			if err := reserve(tx2, "res/bob"); err != nil {
+				t.Fatalf("Transaction 2 failed to reserve: %v", err)
+			}
+
+			if err := tx1.Commit(); err != nil {
+				t.Fatalf("Failed to commit transaction 1: %v", err)
+			}
+
+			// Only serializable transactions detect the write into the scanned range
+			if err := tx2.Commit(); err != tc.expectError {
+				t.Errorf("Expected %v committing transaction 2, got %v", tc.expectError, err)
+			}
+		})
+	}
+
+	// Writes outside of the scanned range don't conflict
+	backend := newVersionedStorage(t)
+	manager := NewManager(backend, &StatsCollectorMock{})
+
+	tx, err := manager.BeginTransactionWithOptions(TxOptions{Isolation: Serializable})
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	if err := reserve(tx, "res/carol"); err != nil {
+		t.Fatalf("Failed to reserve: %v", err)
+	}
+	if err := backend.Put([]byte("other/key"), []byte("value")); err != nil {
+		t.Fatalf("Failed to put key: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Errorf("Expected write outside the range not to conflict, got %v", err)
+	}
+}
@@ -172,6 +172,17 @@ func (it *MemoryIterator) Next() bool {
 	return true
 }
 
+// Prev moves to the previous key
+func (it *MemoryIterator) Prev() bool {
+	if it.position <= 0 {
+		it.position = -1
+		return false
+	}
+
+	it.position--
+	return true
+}
+
 // Key returns the current key
 func (it *MemoryIterator) Key() []byte {
 	if !it.Valid() {
@@ -61,6 +61,11 @@ func (s *StatsCollectorMock) TrackCompaction() {
 	// No-op for the mock
 }
 
+// TrackBloomFilter records the outcome of a bloom filter check
+func (s *StatsCollectorMock) TrackBloomFilter(mayContain, found bool) {
+	// No-op for the mock
+}
+
 // StartRecovery initializes recovery statistics
 func (s *StatsCollectorMock) StartRecovery() time.Time {
 	return time.Now()
@@ -175,6 +175,11 @@ func (r *RegistryImpl) CleanupStaleTransactions() {
 
 // Begin starts a new transaction
 func (r *RegistryImpl) Begin(ctx context.Context, engine interface{}, readOnly bool) (string, error) {
+	return r.BeginWithOptions(ctx, engine, TxOptions{ReadOnly: readOnly})
+}
+
+// BeginWithOptions starts a new transaction with the given options
+func (r *RegistryImpl) BeginWithOptions(ctx context.Context, engine interface{}, opts TxOptions) (string, error) {
 	// Extract connection ID from context
 	connectionID := "unknown"
 	if p, ok := ctx.Value("peer").(string); ok {
@@ -203,18 +208,24 @@ func (r *RegistryImpl) Begin(ctx context.Context, engine interface{}, readOnly b
 			// The only real requirement is that the engine has a BeginTransaction method
 			// that returns a transaction that matches our Transaction interface
 
-			// Get the method using reflection to avoid type compatibility issues
+			// Get the method using reflection to avoid type compatibility issues.
+			// Engines only need to support options if a non-default isolation is requested.
 			val := reflect.ValueOf(engine)
-			method := val.MethodByName("BeginTransaction")
+			methodName := "BeginTransaction"
+			args := []reflect.Value{reflect.ValueOf(opts.ReadOnly)}
+			if opts.Isolation != SnapshotIsolation {
+				methodName = "BeginTransactionWithOptions"
+				args = []reflect.Value{reflect.ValueOf(opts)}
+			}
+			method := val.MethodByName(methodName)
 
 			if !method.IsValid() {
-				err = fmt.Errorf("engine does not have BeginTransaction method")
+				err = fmt.Errorf("engine does not have %s method", methodName)
 				return
 			}
 
 			// Call the method
-			log.Debug("Calling BeginTransaction via reflection")
-			args := []reflect.Value{reflect.ValueOf(readOnly)}
+			log.Debug("Calling %s via reflection", methodName)
 			results := method.Call(args)
 
 			// Check for errors
@@ -57,6 +57,30 @@ func TestRegistryBasicOperations(t *testing.T) {
 	}
 }
 
+func TestRegistryBeginWithOptions(t *testing.T) {
+	manager := NewManager(NewMemoryStorage(), &StatsCollectorMock{})
+	registry := NewRegistry()
+
+	txID, err := registry.BeginWithOptions(context.Background(), manager, TxOptions{Isolation: Serializable})
+	if err != nil {
+		t.Fatalf("Unexpected error beginning transaction: %v", err)
+	}
+	defer registry.Remove(txID)
+
+	tx, exists := registry.Get(txID)
+	if !exists {
+		t.Fatalf("Expected to find transaction %s", txID)
+	}
+	defer tx.Rollback()
+
+	if tx.IsReadOnly() {
+		t.Error("Expected read-write transaction")
+	}
+	if txImpl, ok := tx.(*TransactionImpl); !ok || txImpl.isolation != Serializable {
+		t.Errorf("Expected a serializable transaction, got %#v", tx)
+	}
+}
+
 func TestRegistryConnectionCleanup(t *testing.T) {
 	storage := NewMemoryStorage()
 	statsCollector := &StatsCollectorMock{}
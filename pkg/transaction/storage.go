@@ -2,6 +2,7 @@ package transaction
 
 import (
 	"github.com/KevoDB/kevo/pkg/common/iterator"
+	"github.com/KevoDB/kevo/pkg/engine/interfaces"
 	"github.com/KevoDB/kevo/pkg/wal"
 )
 
@@ -20,3 +21,30 @@ type StorageBackend interface {
 	// GetRangeIterator returns an iterator limited to a specific key range
 	GetRangeIterator(startKey, endKey []byte) (iterator.Iterator, error)
 }
+
+// VersionedStorage is implemented by storage backends that assign sequence numbers
+// to writes and can serve reads as of a snapshot. Transactions on such a backend
+// run optimistically instead of holding the isolation lock for their lifetime.
+type VersionedStorage interface {
+	StorageBackend
+
+	// AcquireSnapshot pins the current sequence number and returns it
+	AcquireSnapshot() (uint64, error)
+
+	// ReleaseSnapshot unpins a sequence number returned by AcquireSnapshot
+	ReleaseSnapshot(seqNum uint64)
+
+	// GetWithSnapshot retrieves the value for the given key as of a snapshot
+	GetWithSnapshot(key []byte, seqNum uint64) ([]byte, error)
+
+	// GetIteratorWithSnapshot returns an iterator over all keys as of a snapshot
+	GetIteratorWithSnapshot(seqNum uint64) (iterator.Iterator, error)
+
+	// GetRangeIteratorWithSnapshot returns an iterator limited to a key range as of a snapshot
+	GetRangeIteratorWithSnapshot(startKey, endKey []byte, seqNum uint64) (iterator.Iterator, error)
+
+	// ApplyBatchIf atomically applies a batch of operations if all of the
+	// preconditions hold, checking them under the same lock as it applies the
+	// batch
+	ApplyBatchIf(entries []*wal.Entry, conditions []interfaces.Precondition) (interfaces.ConditionResult, error)
+}
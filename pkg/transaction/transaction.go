@@ -8,6 +8,7 @@ import (
 	"github.com/KevoDB/kevo/pkg/common/iterator"
 	"github.com/KevoDB/kevo/pkg/common/iterator/bounded"
 	"github.com/KevoDB/kevo/pkg/common/iterator/composite"
+	"github.com/KevoDB/kevo/pkg/engine/interfaces"
 	"github.com/KevoDB/kevo/pkg/wal"
 )
 
@@ -19,6 +20,9 @@ type TransactionImpl struct {
 	// Transaction mode (ReadOnly or ReadWrite)
 	mode TransactionMode
 
+	// Conflict checks performed on commit
+	isolation IsolationLevel
+
 	// Buffer for transaction operations
 	buffer *Buffer
 
@@ -31,6 +35,22 @@ type TransactionImpl struct {
 	// For read-write transactions, tracks if we have the write lock
 	hasWriteLock atomic.Bool
 
+	// Versioned storage for optimistic transactions, nil if the transaction
+	// is isolated by holding rwLock
+	versioned VersionedStorage
+
+	// Sequence number of the snapshot the transaction reads from
+	readSeq uint64
+
+	// Keys read from storage by a read-write transaction, validated on commit
+	readSet map[string]struct{}
+
+	// Key ranges scanned by a serializable transaction, validated on commit
+	readRanges []keyRange
+
+	// Tracks if the transaction still pins its snapshot
+	hasSnapshot atomic.Bool
+
 	// Lock for transaction-level synchronization
 	mu sync.Mutex
 
@@ -46,6 +66,12 @@ type TransactionImpl struct {
 	ttl            time.Duration
 }
 
+// keyRange is a key range [start, end) read by an iterator, nil bounds are unbounded
+type keyRange struct {
+	start []byte
+	end   []byte
+}
+
 // StatsCollector defines the interface for collecting transaction statistics
 type StatsCollector interface {
 	IncrementTxCompleted()
@@ -76,6 +102,13 @@ func (tx *TransactionImpl) Get(key []byte) ([]byte, error) {
 	}
 
 	// Not in the buffer, get from the underlying storage
+	if tx.versioned != nil {
+		// Remember the key so commit can check it wasn't changed since the snapshot
+		if tx.mode == ReadWrite {
+			tx.readSet[string(key)] = struct{}{}
+		}
+		return tx.versioned.GetWithSnapshot(key, tx.readSeq)
+	}
 	return tx.storage.Get(key)
 }
 
@@ -142,8 +175,11 @@ func (tx *TransactionImpl) NewIterator() iterator.Iterator {
 	// Update last active time
 	tx.lastActiveTime = time.Now()
 
+	// A full scan reads the whole keyspace
+	tx.trackRange(nil, nil)
+
 	// Get the storage iterator
-	storageIter, err := tx.storage.GetIterator()
+	storageIter, err := tx.storageIterator()
 	if err != nil {
 		// If we can't get a storage iterator, return a buffer-only iterator
 		return tx.buffer.NewIterator()
@@ -176,8 +212,11 @@ func (tx *TransactionImpl) NewRangeIterator(startKey, endKey []byte) iterator.It
 	// Update last active time
 	tx.lastActiveTime = time.Now()
 
+	// Remember the range so commit can check nothing was written into it
+	tx.trackRange(startKey, endKey)
+
 	// Get the storage iterator for the range
-	storageIter, err := tx.storage.GetRangeIterator(startKey, endKey)
+	storageIter, err := tx.storageRangeIterator(startKey, endKey)
 	if err != nil {
 		// If we can't get a storage iterator, use a bounded buffer iterator
 		bufferIter := tx.buffer.NewIterator()
@@ -197,6 +236,34 @@ func (tx *TransactionImpl) NewRangeIterator(startKey, endKey []byte) iterator.It
 	return composite.NewHierarchicalIterator([]iterator.Iterator{boundedBufferIter, storageIter})
 }
 
+// trackRange records a key range read by a serializable read-write transaction
+func (tx *TransactionImpl) trackRange(startKey, endKey []byte) {
+	if tx.versioned == nil || tx.mode == ReadOnly || tx.isolation != Serializable {
+		return
+	}
+
+	tx.readRanges = append(tx.readRanges, keyRange{
+		start: append([]byte(nil), startKey...),
+		end:   append([]byte(nil), endKey...),
+	})
+}
+
+// storageIterator returns an iterator over the storage as seen by the transaction
+func (tx *TransactionImpl) storageIterator() (iterator.Iterator, error) {
+	if tx.versioned != nil {
+		return tx.versioned.GetIteratorWithSnapshot(tx.readSeq)
+	}
+	return tx.storage.GetIterator()
+}
+
+// storageRangeIterator returns a range iterator over the storage as seen by the transaction
+func (tx *TransactionImpl) storageRangeIterator(startKey, endKey []byte) (iterator.Iterator, error) {
+	if tx.versioned != nil {
+		return tx.versioned.GetRangeIteratorWithSnapshot(startKey, endKey, tx.readSeq)
+	}
+	return tx.storage.GetRangeIterator(startKey, endKey)
+}
+
 // emptyIterator is a simple iterator implementation that returns no results
 type emptyIterator struct{}
 
@@ -204,6 +271,7 @@ func (it *emptyIterator) SeekToFirst()      {}
 func (it *emptyIterator) SeekToLast()       {}
 func (it *emptyIterator) Seek([]byte) bool  { return false }
 func (it *emptyIterator) Next() bool        { return false }
+func (it *emptyIterator) Prev() bool        { return false }
 func (it *emptyIterator) Key() []byte       { return nil }
 func (it *emptyIterator) Value() []byte     { return nil }
 func (it *emptyIterator) Valid() bool       { return false }
@@ -225,6 +293,7 @@ func (tx *TransactionImpl) Commit() error {
 	// For read-only transactions, just release the read lock
 	if tx.mode == ReadOnly {
 		tx.releaseReadLock()
+		tx.releaseSnapshot()
 
 		// Track transaction completion
 		if tx.stats != nil {
@@ -261,11 +330,24 @@ func (tx *TransactionImpl) Commit() error {
 		}
 
 		// Apply the batch atomically
-		err = tx.storage.ApplyBatch(walBatch)
+		if tx.versioned != nil {
+			err = tx.commitOptimistic(walBatch)
+		} else {
+			err = tx.storage.ApplyBatch(walBatch)
+		}
 	}
 
 	// Release the write lock
 	tx.releaseWriteLock()
+	tx.releaseSnapshot()
+
+	// A conflicting transaction is aborted, nothing was written
+	if err == ErrConflict {
+		if tx.stats != nil {
+			tx.stats.IncrementTxAborted()
+		}
+		return err
+	}
 
 	// Track transaction completion
 	if tx.stats != nil {
@@ -275,6 +357,39 @@ func (tx *TransactionImpl) Commit() error {
 	return err
 }
 
+// commitOptimistic applies the batch if none of the keys or, for serializable
+// transactions, key ranges read by the transaction were written after its snapshot.
+// The reads are checked as preconditions of the batch, under the same storage
+// lock as it's applied, so no other write, in a transaction or not, can come in
+// between.
+func (tx *TransactionImpl) commitOptimistic(walBatch []*wal.Entry) error {
+	conditions := make([]interfaces.Precondition, 0, len(tx.readSet)+len(tx.readRanges))
+	for key := range tx.readSet {
+		conditions = append(conditions, interfaces.Precondition{
+			Key:      []byte(key),
+			Type:     interfaces.PreconditionUnchanged,
+			Sequence: tx.readSeq,
+		})
+	}
+	for _, r := range tx.readRanges {
+		conditions = append(conditions, interfaces.Precondition{
+			Key:      r.start,
+			End:      r.end,
+			Type:     interfaces.PreconditionRangeUnchanged,
+			Sequence: tx.readSeq,
+		})
+	}
+
+	result, err := tx.versioned.ApplyBatchIf(walBatch, conditions)
+	if err != nil {
+		return err
+	}
+	if !result.Applied {
+		return ErrConflict
+	}
+	return nil
+}
+
 // Rollback discards all transaction changes
 func (tx *TransactionImpl) Rollback() error {
 	// Use transaction lock for consistent view
@@ -295,6 +410,7 @@ func (tx *TransactionImpl) Rollback() error {
 	} else {
 		tx.releaseWriteLock()
 	}
+	tx.releaseSnapshot()
 
 	// Track transaction abort
 	if tx.stats != nil {
@@ -322,3 +438,10 @@ func (tx *TransactionImpl) releaseWriteLock() {
 		tx.rwLock.Unlock()
 	}
 }
+
+// releaseSnapshot safely releases the snapshot pinned by optimistic transactions
+func (tx *TransactionImpl) releaseSnapshot() {
+	if tx.hasSnapshot.CompareAndSwap(true, false) {
+		tx.versioned.ReleaseSnapshot(tx.readSeq)
+	}
+}
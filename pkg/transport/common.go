@@ -6,21 +6,27 @@ import (
 
 // Standard request/response type constants
 const (
-	TypeGet        = "get"
-	TypePut        = "put"
-	TypeDelete     = "delete"
-	TypeBatchWrite = "batch_write"
-	TypeScan       = "scan"
-	TypeBeginTx    = "begin_tx"
-	TypeCommitTx   = "commit_tx"
-	TypeRollbackTx = "rollback_tx"
-	TypeTxGet      = "tx_get"
-	TypeTxPut      = "tx_put"
-	TypeTxDelete   = "tx_delete"
-	TypeTxScan     = "tx_scan"
-	TypeGetStats   = "get_stats"
-	TypeCompact    = "compact"
-	TypeError      = "error"
+	TypeGet            = "get"
+	TypePut            = "put"
+	TypeDelete         = "delete"
+	TypeDeleteRange    = "delete_range"
+	TypeCompareAndSwap = "compare_and_swap"
+	TypePutIfAbsent    = "put_if_absent"
+	TypeDeleteIfEquals = "delete_if_equals"
+	TypeBatchWrite     = "batch_write"
+	TypeScan           = "scan"
+	TypeBeginTx        = "begin_tx"
+	TypeCommitTx       = "commit_tx"
+	TypeRollbackTx     = "rollback_tx"
+	TypeTxGet          = "tx_get"
+	TypeTxPut          = "tx_put"
+	TypeTxDelete       = "tx_delete"
+	TypeTxScan         = "tx_scan"
+	TypeGetStats       = "get_stats"
+	TypeCompact        = "compact"
+	TypeCheckpoint     = "checkpoint"
+	TypeIngestSSTables = "ingest_sstables"
+	TypeError          = "error"
 )
 
 // Common errors
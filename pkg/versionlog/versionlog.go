@@ -0,0 +1,512 @@
+// Package versionlog records which SSTable files make up the live state of
+// an SSTable directory. Flushes and compactions append version edits to an
+// append-only log, so that a crash never leaves a half-applied change: on
+// recovery, the live file set is rebuilt by replaying the edits, and the files
+// the log doesn't know about are left over from unfinished work.
+package versionlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/KevoDB/kevo/pkg/common/fsutil"
+	"github.com/KevoDB/kevo/pkg/sstable"
+)
+
+const (
+	// FileName is the name of the version log in an SSTable directory
+	FileName = "VERSIONS"
+
+	// sstableFilenameFormat is the name of SSTable files: level_sequence_timestamp.sst
+	sstableFilenameFormat = "%d_%06d_%020d.sst"
+
+	// headerSize is the size of the checksum and length preceding each edit
+	headerSize = 8
+
+	// minRewriteSize is the smallest log rewritten as a single snapshot edit
+	minRewriteSize = 1 << 20
+)
+
+// Common errors
+var (
+	ErrClosed = errors.New("version log is closed")
+)
+
+// FileMeta describes an SSTable file of the live version
+type FileMeta struct {
+	// Name of the file in the SSTable directory
+	Name string `json:"name"`
+
+	// Level the file belongs to
+	Level int `json:"level"`
+
+	// Sequence number of the file within its level
+	Sequence uint64 `json:"sequence"`
+
+	// Size of the file in bytes
+	Size int64 `json:"size"`
+
+	// Smallest and largest keys of the file, including range tombstones
+	Smallest []byte `json:"smallest,omitempty"`
+	Largest  []byte `json:"largest,omitempty"`
+
+	// Sequence number of every entry in an ingested file, zero for files
+	// written by the database
+	GlobalSequence uint64 `json:"global_sequence,omitempty"`
+
+	// Highest sequence number of an entry or range tombstone in the file,
+	// zero if unknown
+	LargestSequence uint64 `json:"largest_sequence,omitempty"`
+}
+
+// ParseFileName returns the level, sequence number and timestamp in the name
+// of an SSTable file, and false if it isn't named like one
+func ParseFileName(name string) (level int, sequence uint64, timestamp int64, ok bool) {
+	n, err := fmt.Sscanf(name, sstableFilenameFormat, &level, &sequence, &timestamp)
+	return level, sequence, timestamp, n == 3 && err == nil
+}
+
+// Describe returns the metadata of an open SSTable file. Range tombstones
+// extend the key range of the file.
+func Describe(reader *sstable.Reader) (FileMeta, error) {
+	path := reader.FilePath()
+	name := filepath.Base(path)
+	level, sequence, _, ok := ParseFileName(name)
+	if !ok {
+		return FileMeta{}, fmt.Errorf("unexpected SSTable file name %s", name)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileMeta{}, fmt.Errorf("failed to stat SSTable %s: %w", path, err)
+	}
+
+	smallest, largest := reader.KeyRange()
+	for _, t := range reader.RangeTombstones() {
+		if smallest == nil || bytes.Compare(t.Start, smallest) < 0 {
+			smallest = t.Start
+		}
+		if largest == nil || bytes.Compare(t.End, largest) > 0 {
+			largest = t.End
+		}
+	}
+
+	return FileMeta{
+		Name:     name,
+		Level:    level,
+		Sequence: sequence,
+		Size:     info.Size(),
+		Smallest: append([]byte(nil), smallest...),
+		Largest:  append([]byte(nil), largest...),
+	}, nil
+}
+
+// Edit is a change to the live version, committed all at once. Counters that
+// are zero are left as they are, and counters never go backwards.
+type Edit struct {
+	// Files added and removed, by name
+	Added   []FileMeta `json:"added,omitempty"`
+	Removed []string   `json:"removed,omitempty"`
+
+	// Highest sequence number written to the files of the version
+	LastSequence uint64 `json:"last_sequence,omitempty"`
+
+	// Number of the WAL file written to when the edit was committed
+	LogNumber uint64 `json:"log_number,omitempty"`
+
+	// Next number to give a flushed file
+	NextFileNumber uint64 `json:"next_file_number,omitempty"`
+}
+
+// Version is the live file set of an SSTable directory
+type Version struct {
+	files map[string]FileMeta
+
+	LastSequence   uint64
+	LogNumber      uint64
+	NextFileNumber uint64
+}
+
+// newVersion creates an empty version
+func newVersion() *Version {
+	return &Version{files: make(map[string]FileMeta)}
+}
+
+// Files returns the live files, by level and then by sequence number
+func (v *Version) Files() []FileMeta {
+	files := make([]FileMeta, 0, len(v.files))
+	for _, file := range v.files {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Level != files[j].Level {
+			return files[i].Level < files[j].Level
+		}
+		if files[i].Sequence != files[j].Sequence {
+			return files[i].Sequence < files[j].Sequence
+		}
+		return files[i].Name < files[j].Name
+	})
+	return files
+}
+
+// Contains returns whether the named file is live
+func (v *Version) Contains(name string) bool {
+	_, ok := v.files[name]
+	return ok
+}
+
+// apply applies an edit to the version
+func (v *Version) apply(edit *Edit) {
+	for _, name := range edit.Removed {
+		delete(v.files, name)
+	}
+	for _, file := range edit.Added {
+		v.files[file.Name] = file
+	}
+	v.LastSequence = max(v.LastSequence, edit.LastSequence)
+	v.LogNumber = max(v.LogNumber, edit.LogNumber)
+	v.NextFileNumber = max(v.NextFileNumber, edit.NextFileNumber)
+}
+
+// clone returns a copy of the version
+func (v *Version) clone() *Version {
+	c := *v
+	c.files = make(map[string]FileMeta, len(v.files))
+	for name, file := range v.files {
+		c.files[name] = file
+	}
+	return &c
+}
+
+// snapshot returns the edit that rebuilds the version from an empty one
+func (v *Version) snapshot() *Edit {
+	return &Edit{
+		Added:          v.Files(),
+		LastSequence:   v.LastSequence,
+		LogNumber:      v.LogNumber,
+		NextFileNumber: v.NextFileNumber,
+	}
+}
+
+// Log is the version log of an SSTable directory. It is safe for concurrent
+// use. A nil *Log records nothing.
+type Log struct {
+	mu sync.Mutex
+
+	dir  string
+	file *os.File
+
+	// Bytes in the log, the size at which it's rewritten, and the smallest
+	// size it's rewritten at
+	size           int64
+	rewriteSize    int64
+	minRewriteSize int64
+
+	current *Version
+	closed  bool
+}
+
+// Exists returns whether dir has a version log
+func Exists(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, FileName))
+	return err == nil
+}
+
+// Open opens the version log of dir, creating an empty one if there is none.
+// Edits torn by a crash at the end of the log are discarded.
+func Open(dir string) (*Log, error) {
+	path := filepath.Join(dir, FileName)
+
+	// A rewrite interrupted before its rename leaves the old log in place
+	os.Remove(path + ".tmp")
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open version log: %w", err)
+	}
+
+	current, size, err := replay(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	// Drop the torn tail, so that new edits follow the last complete one
+	if err := file.Truncate(size); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to truncate version log: %w", err)
+	}
+	if _, err := file.Seek(size, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to seek version log: %w", err)
+	}
+	if err := fsutil.SyncDir(dir); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &Log{
+		dir:            dir,
+		file:           file,
+		size:           size,
+		rewriteSize:    max(minRewriteSize, 2*size),
+		minRewriteSize: minRewriteSize,
+		current:        current,
+	}, nil
+}
+
+// Write creates the version log of dir holding the given version, replacing
+// any log already there. It's used to describe copies of the files of a
+// version, such as checkpoints.
+func Write(dir string, version *Version) error {
+	record, err := encodeRecord(version.snapshot())
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, FileName)
+	tmpPath := path + ".tmp"
+	if err := fsutil.WriteFileSync(tmpPath, record); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write version log: %w", err)
+	}
+	return fsutil.SyncDir(dir)
+}
+
+// Reset replaces the version log of dir with one holding the files and
+// counters of edit alone. It's used to rebuild the file set of a directory
+// whose log or files were damaged.
+func Reset(dir string, edit Edit) error {
+	version := newVersion()
+	version.apply(&edit)
+	return Write(dir, version)
+}
+
+// Read returns the live version recorded by the version log of dir without
+// opening the log for writing, for tools that look at a database that isn't
+// open. Edits torn by a crash at the end of the log are ignored.
+func Read(dir string) (*Version, error) {
+	file, err := os.Open(filepath.Join(dir, FileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open version log: %w", err)
+	}
+	defer file.Close()
+
+	version, _, err := replay(file)
+	return version, err
+}
+
+// replay rebuilds the version from the edits of a log, returning it with the
+// size of the complete edits
+func replay(file *os.File) (*Version, int64, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read version log: %w", err)
+	}
+
+	version := newVersion()
+	var offset int64
+	for {
+		payload, n := decodeRecord(data[offset:])
+		if n == 0 {
+			break
+		}
+
+		var edit Edit
+		if err := json.Unmarshal(payload, &edit); err != nil {
+			// The checksum matched, so this isn't a torn write
+			return nil, 0, fmt.Errorf("corrupt version edit at offset %d: %w", offset, err)
+		}
+		version.apply(&edit)
+		offset += int64(n)
+	}
+
+	return version, offset, nil
+}
+
+// encodeRecord frames an edit with its checksum and length
+func encodeRecord(edit *Edit) ([]byte, error) {
+	payload, err := json.Marshal(edit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode version edit: %w", err)
+	}
+
+	record := make([]byte, headerSize+len(payload))
+	binary.LittleEndian.PutUint32(record[0:4], crc32.ChecksumIEEE(payload))
+	binary.LittleEndian.PutUint32(record[4:8], uint32(len(payload)))
+	copy(record[headerSize:], payload)
+	return record, nil
+}
+
+// decodeRecord returns the payload of the record at the start of data and the
+// size of the record, or a size of zero if it's incomplete or corrupt
+func decodeRecord(data []byte) ([]byte, int) {
+	if len(data) < headerSize {
+		return nil, 0
+	}
+	checksum := binary.LittleEndian.Uint32(data[0:4])
+	length := int(binary.LittleEndian.Uint32(data[4:8]))
+	if length == 0 || length > len(data)-headerSize {
+		return nil, 0
+	}
+
+	payload := data[headerSize : headerSize+length]
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return nil, 0
+	}
+	return payload, headerSize + length
+}
+
+// Dir returns the directory of the log
+func (l *Log) Dir() string {
+	return l.dir
+}
+
+// Current returns a copy of the live version, or nil for a nil log
+func (l *Log) Current() *Version {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.current.clone()
+}
+
+// File returns the metadata of a live file, and false if the file isn't live
+// or the log is nil
+func (l *Log) File(name string) (FileMeta, bool) {
+	if l == nil {
+		return FileMeta{}, false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, ok := l.current.files[name]
+	return file, ok
+}
+
+// Apply durably appends an edit to the log, then applies it to the live
+// version. Removing files that aren't live does nothing, and an edit that
+// changes nothing isn't written.
+func (l *Log) Apply(edit Edit) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrClosed
+	}
+
+	removed := make([]string, 0, len(edit.Removed))
+	for _, name := range edit.Removed {
+		if l.current.Contains(name) {
+			removed = append(removed, name)
+		}
+	}
+	edit.Removed = removed
+
+	if len(edit.Added) == 0 && len(edit.Removed) == 0 &&
+		edit.LastSequence <= l.current.LastSequence &&
+		edit.LogNumber <= l.current.LogNumber &&
+		edit.NextFileNumber <= l.current.NextFileNumber {
+		return nil
+	}
+
+	record, err := encodeRecord(&edit)
+	if err != nil {
+		return err
+	}
+	if _, err := l.file.Write(record); err != nil {
+		// Leave no partial edit for the next one to follow
+		l.file.Truncate(l.size)
+		l.file.Seek(l.size, io.SeekStart)
+		return fmt.Errorf("failed to write version edit: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync version log: %w", err)
+	}
+	l.size += int64(len(record))
+	l.current.apply(&edit)
+
+	// The edit is committed, a failed rewrite leaves the log as it is
+	if l.size >= l.rewriteSize {
+		l.rewrite()
+	}
+
+	return nil
+}
+
+// rewrite replaces the log with a single edit holding the live version. The
+// caller must hold the lock.
+func (l *Log) rewrite() error {
+	record, err := encodeRecord(l.current.snapshot())
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(l.dir, FileName)
+	tmpPath := path + ".tmp"
+	if err := fsutil.WriteFileSync(tmpPath, record); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	file, err := os.OpenFile(tmpPath, os.O_RDWR, 0644)
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to open rewritten version log: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekEnd); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to seek rewritten version log: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace version log: %w", err)
+	}
+	fsutil.SyncDir(l.dir)
+
+	l.file.Close()
+	l.file = file
+	l.size = int64(len(record))
+	l.rewriteSize = max(l.minRewriteSize, 2*l.size)
+	return nil
+}
+
+// Close closes the log
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	return l.file.Close()
+}
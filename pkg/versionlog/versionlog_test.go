@@ -0,0 +1,205 @@
+package versionlog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLogReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	if Exists(dir) {
+		t.Fatal("Expected no version log in a new directory")
+	}
+	log, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Failed to open version log: %v", err)
+	}
+
+	// Two flushes, then a compaction replacing their files
+	edits := []Edit{
+		{Added: []FileMeta{{Name: "0_000001.sst", Level: 0, Sequence: 1, Smallest: []byte("a"), Largest: []byte("m")}}, LastSequence: 10, NextFileNumber: 2},
+		{Added: []FileMeta{{Name: "0_000002.sst", Level: 0, Sequence: 2, Smallest: []byte("c"), Largest: []byte("z")}}, LastSequence: 20, NextFileNumber: 3, LogNumber: 7},
+		{Added: []FileMeta{{Name: "1_000001.sst", Level: 1, Sequence: 1, Smallest: []byte("a"), Largest: []byte("z")}}, Removed: []string{"0_000001.sst", "0_000002.sst"}},
+	}
+	for _, edit := range edits {
+		if err := log.Apply(edit); err != nil {
+			t.Fatalf("Failed to apply edit: %v", err)
+		}
+	}
+	if err := log.Close(); err != nil {
+		t.Fatalf("Failed to close version log: %v", err)
+	}
+	if err := log.Apply(Edit{LastSequence: 30}); err != ErrClosed {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+
+	log, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen version log: %v", err)
+	}
+	defer log.Close()
+
+	version := log.Current()
+	files := version.Files()
+	if len(files) != 1 || files[0].Name != "1_000001.sst" || files[0].Level != 1 {
+		t.Fatalf("Expected only the compaction output, got %+v", files)
+	}
+	if !bytes.Equal(files[0].Smallest, []byte("a")) || !bytes.Equal(files[0].Largest, []byte("z")) {
+		t.Errorf("Unexpected key range [%s, %s]", files[0].Smallest, files[0].Largest)
+	}
+	if version.LastSequence != 20 || version.NextFileNumber != 3 || version.LogNumber != 7 {
+		t.Errorf("Unexpected counters %d, %d, %d", version.LastSequence, version.NextFileNumber, version.LogNumber)
+	}
+
+	// Edits that change nothing aren't written
+	info, _ := os.Stat(filepath.Join(dir, FileName))
+	if err := log.Apply(Edit{Removed: []string{"0_000001.sst"}, LastSequence: 5}); err != nil {
+		t.Fatalf("Failed to apply edit: %v", err)
+	}
+	if after, _ := os.Stat(filepath.Join(dir, FileName)); after.Size() != info.Size() {
+		t.Errorf("Expected an empty edit not to grow the log from %d bytes, got %d", info.Size(), after.Size())
+	}
+}
+
+func TestLogTornTail(t *testing.T) {
+	dir := t.TempDir()
+
+	log, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Failed to open version log: %v", err)
+	}
+	for i := 1; i <= 3; i++ {
+		name := fmt.Sprintf("0_%06d.sst", i)
+		if err := log.Apply(Edit{Added: []FileMeta{{Name: name, Sequence: uint64(i)}}}); err != nil {
+			t.Fatalf("Failed to apply edit: %v", err)
+		}
+	}
+	log.Close()
+
+	// Cut the last edit short, as a crash while appending it would
+	path := filepath.Join(dir, FileName)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat version log: %v", err)
+	}
+	if err := os.Truncate(path, info.Size()-3); err != nil {
+		t.Fatalf("Failed to truncate version log: %v", err)
+	}
+
+	log, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen version log: %v", err)
+	}
+	if files := log.Current().Files(); len(files) != 2 {
+		t.Fatalf("Expected the 2 complete edits, got %+v", files)
+	}
+
+	// New edits follow the last complete one
+	if err := log.Apply(Edit{Added: []FileMeta{{Name: "0_000004.sst", Sequence: 4}}}); err != nil {
+		t.Fatalf("Failed to apply edit: %v", err)
+	}
+	log.Close()
+
+	log, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen version log: %v", err)
+	}
+	defer log.Close()
+	version := log.Current()
+	if len(version.Files()) != 3 || !version.Contains("0_000004.sst") || version.Contains("0_000003.sst") {
+		t.Errorf("Unexpected files after recovery: %+v", version.Files())
+	}
+}
+
+func TestLogRewrite(t *testing.T) {
+	dir := t.TempDir()
+
+	log, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Failed to open version log: %v", err)
+	}
+	log.rewriteSize = 4096
+	log.minRewriteSize = 4096
+
+	// Replace the same file over and over, until the log is rewritten
+	path := filepath.Join(dir, FileName)
+	for i := 1; i <= 100; i++ {
+		edit := Edit{
+			Added:          []FileMeta{{Name: fmt.Sprintf("1_%06d.sst", i), Level: 1, Sequence: uint64(i)}},
+			Removed:        []string{fmt.Sprintf("1_%06d.sst", i-1)},
+			LastSequence:   uint64(i * 10),
+			NextFileNumber: uint64(i + 1),
+		}
+		if err := log.Apply(edit); err != nil {
+			t.Fatalf("Failed to apply edit: %v", err)
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat version log: %v", err)
+	}
+	if info.Size() >= 4096 {
+		t.Errorf("Expected the log to be rewritten, it has %d bytes", info.Size())
+	}
+	log.Close()
+
+	log, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen version log: %v", err)
+	}
+	defer log.Close()
+	version := log.Current()
+	if files := version.Files(); len(files) != 1 || files[0].Name != "1_000100.sst" {
+		t.Errorf("Expected only the last file, got %+v", files)
+	}
+	if version.LastSequence != 1000 || version.NextFileNumber != 101 {
+		t.Errorf("Unexpected counters %d, %d", version.LastSequence, version.NextFileNumber)
+	}
+}
+
+func TestReadAndReset(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := Read(dir); err == nil {
+		t.Error("Expected an error reading a missing version log")
+	}
+
+	log, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Failed to open version log: %v", err)
+	}
+	edit := Edit{
+		Added:          []FileMeta{{Name: "0_000001.sst", Sequence: 1}, {Name: "0_000002.sst", Sequence: 2}},
+		LastSequence:   10,
+		NextFileNumber: 3,
+	}
+	if err := log.Apply(edit); err != nil {
+		t.Fatalf("Failed to apply edit: %v", err)
+	}
+	log.Close()
+
+	version, err := Read(dir)
+	if err != nil {
+		t.Fatalf("Failed to read version log: %v", err)
+	}
+	if len(version.Files()) != 2 || version.LastSequence != 10 {
+		t.Errorf("Expected 2 files up to sequence 10, got %d up to %d", len(version.Files()), version.LastSequence)
+	}
+
+	// Reset keeps only what the edit holds
+	if err := Reset(dir, Edit{Added: edit.Added[1:], LastSequence: 10, NextFileNumber: 3}); err != nil {
+		t.Fatalf("Failed to reset version log: %v", err)
+	}
+	version, err = Read(dir)
+	if err != nil {
+		t.Fatalf("Failed to read version log: %v", err)
+	}
+	if version.Contains("0_000001.sst") || !version.Contains("0_000002.sst") ||
+		version.LastSequence != 10 || version.NextFileNumber != 3 {
+		t.Errorf("Unexpected version after reset: %+v", version.Files())
+	}
+}
@@ -0,0 +1,108 @@
+package wal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/KevoDB/kevo/pkg/config"
+)
+
+// CopyFile writes the entries of the WAL file at src with sequence numbers up
+// to maxSeq to a new WAL file at dst, and returns how many it copied. Entries
+// sharing a sequence number are written as one batch. Copying stops at the
+// first entry past maxSeq or that can't be read, such as one still being
+// written to a live WAL.
+func CopyFile(src, dst string, maxSeq uint64) (int, error) {
+	reader, err := OpenReader(src)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	file, err := os.OpenFile(dst, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create WAL file: %w", err)
+	}
+
+	// The copy is synced once, when it's closed
+	w := &WAL{
+		cfg:          &config.Config{WALSyncMode: config.SyncNone},
+		dir:          filepath.Dir(dst),
+		file:         file,
+		writer:       bufio.NewWriterSize(file, 64*1024),
+		nextSequence: 1,
+		lastSync:     time.Now(),
+		status:       WALStatusActive,
+		observers:    make(map[string]WALEntryObserver),
+	}
+
+	copied := 0
+	var batch []*Entry
+	writeBatch := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := w.copyEntries(batch); err != nil {
+			return err
+		}
+		copied += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		entry, err := reader.ReadEntry()
+		if err != nil || entry.SequenceNumber > maxSeq {
+			if err != nil && err != io.EOF && !DisableRecoveryLogs {
+				fmt.Printf("Stopped copying WAL file %s: %v\n", src, err)
+			}
+			break
+		}
+
+		if len(batch) > 0 && batch[0].SequenceNumber != entry.SequenceNumber {
+			if err := writeBatch(); err != nil {
+				w.Close()
+				return copied, err
+			}
+		}
+		batch = append(batch, entry)
+	}
+
+	if err := writeBatch(); err != nil {
+		w.Close()
+		return copied, err
+	}
+	return copied, w.Close()
+}
+
+// copyEntries writes entries sharing a sequence number as they were written
+// to the original WAL: a batch as a run of full records, and a single entry
+// split into fragments if it's too large for one record
+func (w *WAL) copyEntries(entries []*Entry) error {
+	if len(entries) > 1 {
+		_, err := w.AppendBatchWithSequence(entries, entries[0].SequenceNumber)
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	entry := entries[0]
+	encodedType, encodedKey := EncodeFamily(entry.Type, entry.Family, entry.Key)
+	entrySize := 1 + 8 + 4 + len(encodedKey)
+	if entry.Type != OpTypeDelete {
+		entrySize += 4 + len(entry.Value)
+	}
+
+	if entry.SequenceNumber >= w.nextSequence {
+		w.nextSequence = entry.SequenceNumber + 1
+	}
+	if entrySize <= MaxRecordSize {
+		return w.writeRecord(RecordTypeFull, encodedType, entry.SequenceNumber, encodedKey, entry.Value)
+	}
+	return w.writeFragmentedRecord(encodedType, entry.SequenceNumber, encodedKey, entry.Value)
+}
@@ -0,0 +1,84 @@
+package wal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyFile(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	w, err := NewWAL(createTestConfig(), dir)
+	if err != nil {
+		t.Fatalf("Failed to create WAL: %v", err)
+	}
+
+	// Entries 1 and 2, a batch at 3 spanning two column families, a fragmented
+	// entry at 4, then 5
+	for i := 1; i <= 2; i++ {
+		if _, err := w.Append(OpTypePut, []byte(fmt.Sprintf("key%d", i)), []byte("value")); err != nil {
+			t.Fatalf("Failed to append entry: %v", err)
+		}
+	}
+	if _, err := w.AppendBatch([]*Entry{
+		{Type: OpTypePut, Key: []byte("batch"), Value: []byte("value")},
+		{Type: OpTypeDelete, Key: []byte("batch"), Family: 7},
+	}); err != nil {
+		t.Fatalf("Failed to append batch: %v", err)
+	}
+	large := bytes.Repeat([]byte("x"), 3*MaxRecordSize)
+	if _, err := w.AppendToFamily(7, OpTypePut, []byte("large"), large); err != nil {
+		t.Fatalf("Failed to append entry: %v", err)
+	}
+	if _, err := w.Append(OpTypePut, []byte("key5"), []byte("value")); err != nil {
+		t.Fatalf("Failed to append entry: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close WAL: %v", err)
+	}
+
+	files, err := FindWALFiles(dir)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Expected one WAL file, got %v (%v)", files, err)
+	}
+
+	dst := filepath.Join(t.TempDir(), filepath.Base(files[0]))
+	copied, err := CopyFile(files[0], dst, 4)
+	if err != nil {
+		t.Fatalf("Failed to copy WAL file: %v", err)
+	}
+	if copied != 5 {
+		t.Errorf("Expected 5 entries copied, got %d", copied)
+	}
+
+	var entries []*Entry
+	if _, err := ReplayWALFile(dst, func(entry *Entry) error {
+		entries = append(entries, entry)
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to replay copy: %v", err)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("Expected 5 entries in the copy, got %d", len(entries))
+	}
+	for i, expected := range []uint64{1, 2, 3, 3, 4} {
+		if entries[i].SequenceNumber != expected {
+			t.Errorf("Expected entry %d to have sequence %d, got %d", i, expected, entries[i].SequenceNumber)
+		}
+	}
+	if entry := entries[3]; entry.Type != OpTypeDelete || entry.Family != 7 || string(entry.Key) != "batch" {
+		t.Errorf("Unexpected batch entry %+v", entry)
+	}
+	if entry := entries[4]; entry.Family != 7 || !bytes.Equal(entry.Value, large) {
+		t.Errorf("Expected the fragmented entry to be copied whole, got %d bytes", len(entry.Value))
+	}
+
+	// The destination isn't overwritten
+	if _, err := CopyFile(files[0], dst, 4); err == nil {
+		t.Error("Expected copying over an existing file to fail")
+	}
+}
@@ -19,6 +19,16 @@ type Reader struct {
 	buffer    []byte
 	fragments [][]byte
 	currType  uint8
+	records   []RecordInfo // Physical records of the entry being read
+	pos       int64        // Offset of the next record
+	entryEnd  int64        // Offset just past the last entry read
+}
+
+// RecordInfo describes a physical record of the WAL
+type RecordInfo struct {
+	Offset int64 // Offset of the record header in the file
+	Type   uint8 // RecordTypeFull, RecordTypeFirst, etc.
+	Length int   // Length of the payload
 }
 
 // OpenReader creates a new Reader for the given WAL file
@@ -38,6 +48,10 @@ func OpenReader(path string) (*Reader, error) {
 
 // ReadEntry reads the next entry from the WAL
 func (r *Reader) ReadEntry() (*Entry, error) {
+	if len(r.fragments) == 0 {
+		r.records = r.records[:0]
+	}
+
 	// Loop until we have a complete entry
 	for {
 		// Read a record
@@ -57,7 +71,12 @@ func (r *Reader) ReadEntry() (*Entry, error) {
 		switch record.recordType {
 		case RecordTypeFull:
 			// Single record, parse directly
-			return r.parseEntryData(record.data)
+			entry, err := r.parseEntryData(record.data)
+			if err != nil {
+				return nil, err
+			}
+			r.entryEnd = r.pos
+			return entry, nil
 
 		case RecordTypeFirst:
 			// Start of a fragmented entry
@@ -83,6 +102,7 @@ func (r *Reader) ReadEntry() (*Entry, error) {
 			if err != nil {
 				return nil, err
 			}
+			r.entryEnd = r.pos
 			return entry, nil
 
 		default:
@@ -121,12 +141,17 @@ func (r *Reader) readRecord() (*record, error) {
 		return nil, err
 	}
 
+	offset := r.pos
+	r.pos += HeaderSize + int64(length)
+
 	// Verify CRC
 	computedCRC := crc32.ChecksumIEEE(data)
 	if computedCRC != crc {
 		return nil, fmt.Errorf("%w: expected CRC %d, got %d", ErrCorruptRecord, crc, computedCRC)
 	}
 
+	r.records = append(r.records, RecordInfo{Offset: offset, Type: recordType, Length: int(length)})
+
 	return &record{
 		recordType: recordType,
 		data:       data,
@@ -164,12 +189,14 @@ func (r *Reader) parseEntryData(data []byte) (*Entry, error) {
 
 	offset := 0
 
-	// Read entry type
-	entryType := data[offset]
+	// Read entry type, the column family flag is handled once the key is read
+	encodedType := data[offset]
+	entryType := encodedType &^ OpTypeFamilyFlag
 	offset++
 
 	// Validate entry type
-	if entryType != OpTypePut && entryType != OpTypeDelete && entryType != OpTypeMerge {
+	if entryType != OpTypePut && entryType != OpTypeDelete && entryType != OpTypeMerge &&
+		entryType != OpTypeDeleteRange && entryType != OpTypePutWithTTL {
 		return nil, fmt.Errorf("%w: %d", ErrInvalidOpType, entryType)
 	}
 
@@ -191,6 +218,12 @@ func (r *Reader) parseEntryData(data []byte) (*Entry, error) {
 	copy(key, data[offset:offset+int(keyLen)])
 	offset += int(keyLen)
 
+	// Split off the column family
+	_, family, key, err := DecodeFamily(encodedType, key)
+	if err != nil {
+		return nil, err
+	}
+
 	// Read value if applicable
 	var value []byte
 	if entryType != OpTypeDelete {
@@ -218,9 +251,22 @@ func (r *Reader) parseEntryData(data []byte) (*Entry, error) {
 		Type:           entryType,
 		Key:            key,
 		Value:          value,
+		Family:         family,
 	}, nil
 }
 
+// Records returns the physical records of the entry last returned by
+// ReadEntry: one full record, or the fragments of a large entry
+func (r *Reader) Records() []RecordInfo {
+	return r.records
+}
+
+// Offset returns the offset just past the last entry returned by ReadEntry.
+// After a read error, the file can be truncated to it to drop a corrupt tail.
+func (r *Reader) Offset() int64 {
+	return r.entryEnd
+}
+
 // Close closes the reader
 func (r *Reader) Close() error {
 	return r.file.Close()
@@ -350,6 +396,7 @@ func recoverFromCorruption(reader *Reader) error {
 		if err != nil {
 			return err
 		}
+		reader.pos++
 	}
 
 	// At this point, either we're at a valid position or we've skipped ahead
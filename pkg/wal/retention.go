@@ -9,6 +9,8 @@ import (
 	"strings"
 	"sync/atomic"
 	"time"
+
+	"github.com/KevoDB/kevo/pkg/common/fsutil"
 )
 
 // WALRetentionConfig defines the configuration for WAL file retention.
@@ -148,11 +150,24 @@ func (w *WAL) ManageRetention(config WALRetentionConfig) (int, error) {
 		}
 	}
 
-	// Delete the files marked for deletion
+	// Delete the files marked for deletion, or move them to the archive
+	archiveDir := w.cfg.WALArchiveDir
+	if archiveDir != "" {
+		if err := os.MkdirAll(archiveDir, 0755); err != nil {
+			return 0, fmt.Errorf("failed to create WAL archive directory: %w", err)
+		}
+	}
+
 	deleted := 0
 	for _, fi := range fileInfos {
 		if toDelete[fi.Path] {
-			if err := os.Remove(fi.Path); err != nil {
+			var err error
+			if archiveDir != "" {
+				err = fsutil.MoveFile(fi.Path, filepath.Join(archiveDir, filepath.Base(fi.Path)))
+			} else {
+				err = os.Remove(fi.Path)
+			}
+			if err != nil {
 				// Log the error but continue with other files
 				continue
 			}
@@ -2,6 +2,7 @@ package wal
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -557,3 +558,55 @@ func TestWALRetentionEdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestWALRetentionArchive(t *testing.T) {
+	tempDir := t.TempDir()
+	walDir := filepath.Join(tempDir, "wal")
+
+	cfg := config.NewDefaultConfig(tempDir)
+	cfg.WALArchiveDir = filepath.Join(tempDir, "archive")
+
+	// Three WAL files, the last one current
+	var w *WAL
+	var names []string
+	for i := 0; i < 3; i++ {
+		if w != nil {
+			if err := w.Close(); err != nil {
+				t.Fatalf("Failed to close WAL: %v", err)
+			}
+		}
+		var err error
+		w, err = NewWAL(cfg, walDir)
+		if err != nil {
+			t.Fatalf("Failed to create WAL: %v", err)
+		}
+		w.UpdateNextSequence(uint64(i*10 + 1))
+		if _, err := w.Append(OpTypePut, []byte("key"), []byte("value")); err != nil {
+			t.Fatalf("Failed to append entry: %v", err)
+		}
+		names = append(names, filepath.Base(w.file.Name()))
+	}
+	defer w.Close()
+
+	deleted, err := w.ManageRetention(WALRetentionConfig{MaxFileCount: 1})
+	if err != nil {
+		t.Fatalf("Failed to manage retention: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected 2 files retired, got %d", deleted)
+	}
+
+	// The old files moved to the archive, intact
+	files, _ := FindWALFiles(walDir)
+	if len(files) != 1 || filepath.Base(files[0]) != names[2] {
+		t.Errorf("Expected only the current WAL file to remain, got %v", files)
+	}
+	archived, _ := FindWALFiles(cfg.WALArchiveDir)
+	if len(archived) != 2 || filepath.Base(archived[0]) != names[0] || filepath.Base(archived[1]) != names[1] {
+		t.Fatalf("Expected the old WAL files in the archive, got %v", archived)
+	}
+	minSeq, maxSeq, err := getSequenceBounds(archived[1])
+	if err != nil || minSeq != 11 || maxSeq != 11 {
+		t.Errorf("Expected the archived file to hold sequence 11, got %d-%d (%v)", minSeq, maxSeq, err)
+	}
+}
@@ -27,9 +27,19 @@ const (
 	RecordTypeLast   = 4
 
 	// Operation types
-	OpTypePut    = 1
-	OpTypeDelete = 2
-	OpTypeMerge  = 3
+	OpTypePut         = 1
+	OpTypeDelete      = 2
+	OpTypeMerge       = 3
+	OpTypeDeleteRange = 4 // Key is the start of the range, Value the exclusive end
+	OpTypePutWithTTL  = 5 // Value is the expiry time followed by the value, see package ttl
+
+	// OpTypeFamilyFlag is set on the type of entries that belong to a column
+	// family other than the default one. The family ID is stored in front of
+	// the key so the record layout stays the same, see EncodeFamily.
+	OpTypeFamilyFlag = 0x80
+
+	// DefaultFamily is the column family of entries written without one
+	DefaultFamily = 0
 
 	// Header layout
 	// - CRC (4 bytes)
@@ -60,9 +70,35 @@ type Entry struct {
 	Type           uint8 // OpTypePut, OpTypeDelete, etc.
 	Key            []byte
 	Value          []byte
+	Family         uint32 // Column family, DefaultFamily unless set
 	rawBytes       []byte // Used for exact replication
 }
 
+// EncodeFamily returns the entry type and key as they are written to the log
+// for an entry of the given column family
+func EncodeFamily(entryType uint8, family uint32, key []byte) (uint8, []byte) {
+	if family == DefaultFamily {
+		return entryType, key
+	}
+
+	encoded := make([]byte, 4+len(key))
+	binary.LittleEndian.PutUint32(encoded[0:4], family)
+	copy(encoded[4:], key)
+	return entryType | OpTypeFamilyFlag, encoded
+}
+
+// DecodeFamily reverses EncodeFamily, returning the plain entry type, the
+// column family and the user key
+func DecodeFamily(entryType uint8, key []byte) (uint8, uint32, []byte, error) {
+	if entryType&OpTypeFamilyFlag == 0 {
+		return entryType, DefaultFamily, key, nil
+	}
+	if len(key) < 4 {
+		return 0, 0, nil, fmt.Errorf("%w: missing column family", ErrCorruptRecord)
+	}
+	return entryType &^ OpTypeFamilyFlag, binary.LittleEndian.Uint32(key[0:4]), key[4:], nil
+}
+
 // SetRawBytes sets the raw bytes for this entry
 // This is used for replication to ensure exact byte-for-byte compatibility
 func (e *Entry) SetRawBytes(bytes []byte) {
@@ -225,6 +261,11 @@ func ReuseWAL(cfg *config.Config, dir string, nextSeq uint64) (*WAL, error) {
 
 // Append adds an entry to the WAL
 func (w *WAL) Append(entryType uint8, key, value []byte) (uint64, error) {
+	return w.AppendToFamily(DefaultFamily, entryType, key, value)
+}
+
+// AppendToFamily adds an entry of the given column family to the WAL
+func (w *WAL) AppendToFamily(family uint32, entryType uint8, key, value []byte) (uint64, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
@@ -235,7 +276,8 @@ func (w *WAL) Append(entryType uint8, key, value []byte) (uint64, error) {
 		return 0, ErrWALRotating
 	}
 
-	if entryType != OpTypePut && entryType != OpTypeDelete && entryType != OpTypeMerge {
+	if entryType != OpTypePut && entryType != OpTypeDelete && entryType != OpTypeMerge &&
+		entryType != OpTypeDeleteRange && entryType != OpTypePutWithTTL {
 		return 0, ErrInvalidOpType
 	}
 
@@ -256,7 +298,8 @@ func (w *WAL) Append(entryType uint8, key, value []byte) (uint64, error) {
 
 	// Encode the entry
 	// Format: type(1) + seq(8) + keylen(4) + key + vallen(4) + val
-	entrySize := 1 + 8 + 4 + len(key)
+	encodedType, encodedKey := EncodeFamily(entryType, family, key)
+	entrySize := 1 + 8 + 4 + len(encodedKey)
 	if entryType != OpTypeDelete {
 		entrySize += 4 + len(value)
 	}
@@ -265,12 +308,12 @@ func (w *WAL) Append(entryType uint8, key, value []byte) (uint64, error) {
 	if entrySize <= MaxRecordSize {
 		// Single record case
 		recordType := uint8(RecordTypeFull)
-		if err := w.writeRecord(recordType, entryType, seqNum, key, value); err != nil {
+		if err := w.writeRecord(recordType, encodedType, seqNum, encodedKey, value); err != nil {
 			return 0, err
 		}
 	} else {
 		// Split into multiple records
-		if err := w.writeFragmentedRecord(entryType, seqNum, key, value); err != nil {
+		if err := w.writeFragmentedRecord(encodedType, seqNum, encodedKey, value); err != nil {
 			return 0, err
 		}
 	}
@@ -281,6 +324,7 @@ func (w *WAL) Append(entryType uint8, key, value []byte) (uint64, error) {
 		Type:           entryType,
 		Key:            key,
 		Value:          value,
+		Family:         family,
 	}
 
 	// Notify observers of the new entry
@@ -308,7 +352,8 @@ func (w *WAL) AppendWithSequence(entryType uint8, key, value []byte, sequenceNum
 		return 0, ErrWALRotating
 	}
 
-	if entryType != OpTypePut && entryType != OpTypeDelete && entryType != OpTypeMerge {
+	if entryType != OpTypePut && entryType != OpTypeDelete && entryType != OpTypeMerge &&
+		entryType != OpTypeDeleteRange && entryType != OpTypePutWithTTL {
 		return 0, ErrInvalidOpType
 	}
 
@@ -376,7 +421,7 @@ func (w *WAL) AppendWithSequence(entryType uint8, key, value []byte, sequenceNum
 func (w *WAL) writeRecord(recordType uint8, entryType uint8, seqNum uint64, key, value []byte) error {
 	// Calculate the record size
 	payloadSize := 1 + 8 + 4 + len(key) // type + seq + keylen + key
-	if entryType != OpTypeDelete {
+	if entryType&^OpTypeFamilyFlag != OpTypeDelete {
 		payloadSize += 4 + len(value) // vallen + value
 	}
 
@@ -403,7 +448,7 @@ func (w *WAL) writeRecord(recordType uint8, entryType uint8, seqNum uint64, key,
 	offset += len(key)
 
 	// Write value length and value (if applicable)
-	if entryType != OpTypeDelete {
+	if entryType&^OpTypeFamilyFlag != OpTypeDelete {
 		binary.LittleEndian.PutUint32(payload[offset:offset+4], uint32(len(value)))
 		offset += 4
 		copy(payload[offset:], value)
@@ -548,7 +593,7 @@ func (w *WAL) writeFragmentedRecord(entryType uint8, seqNum uint64, key, value [
 	}
 
 	// Add value data if this isn't a delete operation
-	if entryType != OpTypeDelete {
+	if entryType&^OpTypeFamilyFlag != OpTypeDelete {
 		// Add value length
 		valueLenBuf := make([]byte, 4)
 		binary.LittleEndian.PutUint32(valueLenBuf, uint32(len(value)))
@@ -675,7 +720,8 @@ func (w *WAL) AppendBatch(entries []*Entry) (uint64, error) {
 		entryType := entry.Type
 
 		// Payload size: type(1) + seq(8) + keylen(4) + key + [valuelen(4) + value]
-		payloadSize := 1 + 8 + 4 + len(entry.Key)
+		_, encodedKey := EncodeFamily(entryType, entry.Family, entry.Key)
+		payloadSize := 1 + 8 + 4 + len(encodedKey)
 		if entryType != OpTypeDelete {
 			payloadSize += 4 + len(entry.Value)
 		}
@@ -704,8 +750,10 @@ func (w *WAL) AppendBatch(entries []*Entry) (uint64, error) {
 	// Now write all entries atomically (no intermediate flushes)
 	// All entries in the batch share the same sequence number
 	for i, entry := range entries {
-		// Write the entry using its original type and the same sequence number
-		if err := w.writeRecord(RecordTypeFull, entry.Type, startSeqNum, entry.Key, entry.Value); err != nil {
+		// Write the entry using its original type and the same sequence number.
+		// Entries may belong to different column families.
+		encodedType, encodedKey := EncodeFamily(entry.Type, entry.Family, entry.Key)
+		if err := w.writeRecord(RecordTypeFull, encodedType, startSeqNum, encodedKey, entry.Value); err != nil {
 			return 0, fmt.Errorf("failed to write entry %d: %w", i, err)
 		}
 	}
@@ -763,7 +811,8 @@ func (w *WAL) AppendBatchWithSequence(entries []*Entry, startSequence uint64) (u
 		entryType := entry.Type
 
 		// Payload size: type(1) + seq(8) + keylen(4) + key + [valuelen(4) + value]
-		payloadSize := 1 + 8 + 4 + len(entry.Key)
+		_, encodedKey := EncodeFamily(entryType, entry.Family, entry.Key)
+		payloadSize := 1 + 8 + 4 + len(encodedKey)
 		if entryType != OpTypeDelete {
 			payloadSize += 4 + len(entry.Value)
 		}
@@ -792,8 +841,10 @@ func (w *WAL) AppendBatchWithSequence(entries []*Entry, startSequence uint64) (u
 	// Now write all entries atomically (no intermediate flushes)
 	// All entries in the batch share the same sequence number
 	for i, entry := range entries {
-		// Write the entry using its original type and the same sequence number
-		if err := w.writeRecord(RecordTypeFull, entry.Type, startSeqNum, entry.Key, entry.Value); err != nil {
+		// Write the entry using its original type and the same sequence number.
+		// Entries may belong to different column families.
+		encodedType, encodedKey := EncodeFamily(entry.Type, entry.Family, entry.Key)
+		if err := w.writeRecord(RecordTypeFull, encodedType, startSeqNum, encodedKey, entry.Value); err != nil {
 			return 0, fmt.Errorf("failed to write entry %d: %w", i, err)
 		}
 	}
@@ -879,6 +930,14 @@ func min(a, b int) int {
 	return b
 }
 
+// LogNumber returns the number in the name of the WAL file, the time it was
+// created at in Unix nanoseconds
+func (w *WAL) LogNumber() uint64 {
+	var number uint64
+	fmt.Sscanf(filepath.Base(w.file.Name()), "%020d.wal", &number)
+	return number
+}
+
 // RegisterObserver adds an observer to be notified of WAL operations
 func (w *WAL) RegisterObserver(id string, observer WALEntryObserver) {
 	if observer == nil {
@@ -899,6 +958,18 @@ func (w *WAL) UnregisterObserver(id string) {
 	delete(w.observers, id)
 }
 
+// ReserveSequence assigns the next sequence number without writing an entry
+// for it, for writes that reach the database some other way, such as ingested
+// SSTables. Readers of the log see a gap at the number.
+func (w *WAL) ReserveSequence() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	seqNum := w.nextSequence
+	w.nextSequence++
+	return seqNum
+}
+
 // GetNextSequence returns the next sequence number that will be assigned
 func (w *WAL) GetNextSequence() uint64 {
 	w.mu.Lock()
@@ -3,6 +3,7 @@ package wal
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
 	"path/filepath"
@@ -135,6 +136,61 @@ func TestWALDelete(t *testing.T) {
 	}
 }
 
+func TestWALColumnFamilies(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	cfg := createTestConfig()
+	wal, err := NewWAL(cfg, dir)
+	if err != nil {
+		t.Fatalf("Failed to create WAL: %v", err)
+	}
+
+	if _, err := wal.Append(OpTypePut, []byte("key"), []byte("default")); err != nil {
+		t.Fatalf("Failed to append entry: %v", err)
+	}
+	if _, err := wal.AppendToFamily(7, OpTypeDelete, []byte("key"), nil); err != nil {
+		t.Fatalf("Failed to append entry: %v", err)
+	}
+	// A batch can span column families
+	batch := []*Entry{
+		{Type: OpTypePut, Key: []byte("a"), Value: []byte("1")},
+		{Type: OpTypePut, Key: []byte("b"), Value: []byte("2"), Family: 3},
+	}
+	if _, err := wal.AppendBatch(batch); err != nil {
+		t.Fatalf("Failed to append batch: %v", err)
+	}
+
+	if err := wal.Close(); err != nil {
+		t.Fatalf("Failed to close WAL: %v", err)
+	}
+
+	expected := []Entry{
+		{Type: OpTypePut, Key: []byte("key"), Value: []byte("default")},
+		{Type: OpTypeDelete, Key: []byte("key"), Family: 7},
+		{Type: OpTypePut, Key: []byte("a"), Value: []byte("1")},
+		{Type: OpTypePut, Key: []byte("b"), Value: []byte("2"), Family: 3},
+	}
+	var entries []*Entry
+	_, err = ReplayWALDir(dir, func(entry *Entry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to replay WAL: %v", err)
+	}
+
+	if len(entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d", len(expected), len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Type != expected[i].Type || entry.Family != expected[i].Family ||
+			!bytes.Equal(entry.Key, expected[i].Key) || !bytes.Equal(entry.Value, expected[i].Value) {
+			t.Errorf("Entry %d: expected %+v, got %+v", i, expected[i], *entry)
+		}
+	}
+}
+
 func TestWALLargeEntry(t *testing.T) {
 	dir := createTempDir(t)
 	defer os.RemoveAll(dir)
@@ -521,6 +577,77 @@ func TestWALFragmentation(t *testing.T) {
 	}
 }
 
+func TestReaderRecords(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	cfg := createTestConfig()
+	wal, err := NewWAL(cfg, dir)
+	if err != nil {
+		t.Fatalf("Failed to create WAL: %v", err)
+	}
+	if _, err := wal.Append(OpTypePut, []byte("small"), []byte("value")); err != nil {
+		t.Fatalf("Failed to append entry: %v", err)
+	}
+	if _, err := wal.Append(OpTypePut, []byte("large"), make([]byte, MaxRecordSize*2)); err != nil {
+		t.Fatalf("Failed to append fragmented entry: %v", err)
+	}
+	if err := wal.Close(); err != nil {
+		t.Fatalf("Failed to close WAL: %v", err)
+	}
+
+	files, err := FindWALFiles(dir)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Expected one WAL file, got %v (%v)", files, err)
+	}
+	info, err := os.Stat(files[0])
+	if err != nil {
+		t.Fatalf("Failed to stat WAL file: %v", err)
+	}
+
+	// Leave a torn record at the end of the file
+	f, err := os.OpenFile(files[0], os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open WAL file: %v", err)
+	}
+	f.Write([]byte{1, 2, 3, 4, 100, 0, RecordTypeFull, 1, 2})
+	f.Close()
+
+	reader, err := OpenReader(files[0])
+	if err != nil {
+		t.Fatalf("Failed to open reader: %v", err)
+	}
+	defer reader.Close()
+
+	if _, err := reader.ReadEntry(); err != nil {
+		t.Fatalf("Failed to read entry: %v", err)
+	}
+	records := reader.Records()
+	if len(records) != 1 || records[0].Type != RecordTypeFull || records[0].Offset != 0 {
+		t.Errorf("Expected one full record at offset 0, got %+v", records)
+	}
+	firstEnd := reader.Offset()
+	if firstEnd != HeaderSize+int64(records[0].Length) {
+		t.Errorf("Expected offset %d after the first entry, got %d", HeaderSize+records[0].Length, firstEnd)
+	}
+
+	if _, err := reader.ReadEntry(); err != nil {
+		t.Fatalf("Failed to read fragmented entry: %v", err)
+	}
+	records = reader.Records()
+	if len(records) < 3 || records[0].Type != RecordTypeFirst || records[0].Offset != firstEnd ||
+		records[1].Type != RecordTypeMiddle || records[len(records)-1].Type != RecordTypeLast {
+		t.Errorf("Expected first, middle and last fragments, got %+v", records)
+	}
+
+	if _, err := reader.ReadEntry(); err == nil || err == io.EOF {
+		t.Errorf("Expected an error reading the torn record, got %v", err)
+	}
+	if reader.Offset() != info.Size() {
+		t.Errorf("Expected the valid data to end at %d, got %d", info.Size(), reader.Offset())
+	}
+}
+
 func TestWALErrorHandling(t *testing.T) {
 	dir := createTempDir(t)
 	defer os.RemoveAll(dir)
@@ -24,8 +24,9 @@ const (
 type Operation_Type int32
 
 const (
-	Operation_PUT    Operation_Type = 0
-	Operation_DELETE Operation_Type = 1
+	Operation_PUT          Operation_Type = 0
+	Operation_DELETE       Operation_Type = 1
+	Operation_DELETE_RANGE Operation_Type = 2
 )
 
 // Enum value maps for Operation_Type.
@@ -33,10 +34,12 @@ var (
 	Operation_Type_name = map[int32]string{
 		0: "PUT",
 		1: "DELETE",
+		2: "DELETE_RANGE",
 	}
 	Operation_Type_value = map[string]int32{
-		"PUT":    0,
-		"DELETE": 1,
+		"PUT":          0,
+		"DELETE":       1,
+		"DELETE_RANGE": 2,
 	}
 )
 
@@ -64,7 +67,102 @@ func (x Operation_Type) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use Operation_Type.Descriptor instead.
 func (Operation_Type) EnumDescriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{7, 0}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{13, 0}
+}
+
+type Precondition_Type int32
+
+const (
+	Precondition_EQUALS Precondition_Type = 0 // The key exists with the given value
+	Precondition_ABSENT Precondition_Type = 1 // The key doesn't exist
+	Precondition_EXISTS Precondition_Type = 2 // The key exists with any value
+)
+
+// Enum value maps for Precondition_Type.
+var (
+	Precondition_Type_name = map[int32]string{
+		0: "EQUALS",
+		1: "ABSENT",
+		2: "EXISTS",
+	}
+	Precondition_Type_value = map[string]int32{
+		"EQUALS": 0,
+		"ABSENT": 1,
+		"EXISTS": 2,
+	}
+)
+
+func (x Precondition_Type) Enum() *Precondition_Type {
+	p := new(Precondition_Type)
+	*p = x
+	return p
+}
+
+func (x Precondition_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Precondition_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_kevo_service_proto_enumTypes[1].Descriptor()
+}
+
+func (Precondition_Type) Type() protoreflect.EnumType {
+	return &file_proto_kevo_service_proto_enumTypes[1]
+}
+
+func (x Precondition_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Precondition_Type.Descriptor instead.
+func (Precondition_Type) EnumDescriptor() ([]byte, []int) {
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{14, 0}
+}
+
+type BeginTransactionRequest_IsolationLevel int32
+
+const (
+	BeginTransactionRequest_SNAPSHOT     BeginTransactionRequest_IsolationLevel = 0 // Abort on commit if a key read with TxGet changed
+	BeginTransactionRequest_SERIALIZABLE BeginTransactionRequest_IsolationLevel = 1 // Also abort if a key range read with TxScan changed
+)
+
+// Enum value maps for BeginTransactionRequest_IsolationLevel.
+var (
+	BeginTransactionRequest_IsolationLevel_name = map[int32]string{
+		0: "SNAPSHOT",
+		1: "SERIALIZABLE",
+	}
+	BeginTransactionRequest_IsolationLevel_value = map[string]int32{
+		"SNAPSHOT":     0,
+		"SERIALIZABLE": 1,
+	}
+)
+
+func (x BeginTransactionRequest_IsolationLevel) Enum() *BeginTransactionRequest_IsolationLevel {
+	p := new(BeginTransactionRequest_IsolationLevel)
+	*p = x
+	return p
+}
+
+func (x BeginTransactionRequest_IsolationLevel) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BeginTransactionRequest_IsolationLevel) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_kevo_service_proto_enumTypes[2].Descriptor()
+}
+
+func (BeginTransactionRequest_IsolationLevel) Type() protoreflect.EnumType {
+	return &file_proto_kevo_service_proto_enumTypes[2]
+}
+
+func (x BeginTransactionRequest_IsolationLevel) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BeginTransactionRequest_IsolationLevel.Descriptor instead.
+func (BeginTransactionRequest_IsolationLevel) EnumDescriptor() ([]byte, []int) {
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{18, 0}
 }
 
 // Node role information
@@ -101,11 +199,11 @@ func (x GetNodeInfoResponse_NodeRole) String() string {
 }
 
 func (GetNodeInfoResponse_NodeRole) Descriptor() protoreflect.EnumDescriptor {
-	return file_proto_kevo_service_proto_enumTypes[1].Descriptor()
+	return file_proto_kevo_service_proto_enumTypes[3].Descriptor()
 }
 
 func (GetNodeInfoResponse_NodeRole) Type() protoreflect.EnumType {
-	return &file_proto_kevo_service_proto_enumTypes[1]
+	return &file_proto_kevo_service_proto_enumTypes[3]
 }
 
 func (x GetNodeInfoResponse_NodeRole) Number() protoreflect.EnumNumber {
@@ -114,13 +212,14 @@ func (x GetNodeInfoResponse_NodeRole) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use GetNodeInfoResponse_NodeRole.Descriptor instead.
 func (GetNodeInfoResponse_NodeRole) EnumDescriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{32, 0}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{43, 0}
 }
 
 // Basic message types
 type GetRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Key           []byte                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	ColumnFamily  string                 `protobuf:"bytes,2,opt,name=column_family,json=columnFamily,proto3" json:"column_family,omitempty"` // Empty for the default column family
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -162,6 +261,13 @@ func (x *GetRequest) GetKey() []byte {
 	return nil
 }
 
+func (x *GetRequest) GetColumnFamily() string {
+	if x != nil {
+		return x.ColumnFamily
+	}
+	return ""
+}
+
 type GetResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Value         []byte                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
@@ -219,6 +325,8 @@ type PutRequest struct {
 	Key           []byte                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 	Value         []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
 	Sync          bool                   `protobuf:"varint,3,opt,name=sync,proto3" json:"sync,omitempty"`
+	TtlMs         int64                  `protobuf:"varint,4,opt,name=ttl_ms,json=ttlMs,proto3" json:"ttl_ms,omitempty"`                     // Expire the key after this many milliseconds, 0 to never expire
+	ColumnFamily  string                 `protobuf:"bytes,5,opt,name=column_family,json=columnFamily,proto3" json:"column_family,omitempty"` // Empty for the default column family
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -274,6 +382,20 @@ func (x *PutRequest) GetSync() bool {
 	return false
 }
 
+func (x *PutRequest) GetTtlMs() int64 {
+	if x != nil {
+		return x.TtlMs
+	}
+	return 0
+}
+
+func (x *PutRequest) GetColumnFamily() string {
+	if x != nil {
+		return x.ColumnFamily
+	}
+	return ""
+}
+
 type PutResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -322,6 +444,7 @@ type DeleteRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Key           []byte                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 	Sync          bool                   `protobuf:"varint,2,opt,name=sync,proto3" json:"sync,omitempty"`
+	ColumnFamily  string                 `protobuf:"bytes,3,opt,name=column_family,json=columnFamily,proto3" json:"column_family,omitempty"` // Empty for the default column family
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -370,6 +493,13 @@ func (x *DeleteRequest) GetSync() bool {
 	return false
 }
 
+func (x *DeleteRequest) GetColumnFamily() string {
+	if x != nil {
+		return x.ColumnFamily
+	}
+	return ""
+}
+
 type DeleteResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -414,7 +544,360 @@ func (x *DeleteResponse) GetSuccess() bool {
 	return false
 }
 
+// Deletes every key in [start_key, end_key)
+type DeleteRangeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StartKey      []byte                 `protobuf:"bytes,1,opt,name=start_key,json=startKey,proto3" json:"start_key,omitempty"`
+	EndKey        []byte                 `protobuf:"bytes,2,opt,name=end_key,json=endKey,proto3" json:"end_key,omitempty"`
+	Sync          bool                   `protobuf:"varint,3,opt,name=sync,proto3" json:"sync,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRangeRequest) Reset() {
+	*x = DeleteRangeRequest{}
+	mi := &file_proto_kevo_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRangeRequest) ProtoMessage() {}
+
+func (x *DeleteRangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_kevo_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRangeRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRangeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeleteRangeRequest) GetStartKey() []byte {
+	if x != nil {
+		return x.StartKey
+	}
+	return nil
+}
+
+func (x *DeleteRangeRequest) GetEndKey() []byte {
+	if x != nil {
+		return x.EndKey
+	}
+	return nil
+}
+
+func (x *DeleteRangeRequest) GetSync() bool {
+	if x != nil {
+		return x.Sync
+	}
+	return false
+}
+
+type DeleteRangeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRangeResponse) Reset() {
+	*x = DeleteRangeResponse{}
+	mi := &file_proto_kevo_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRangeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRangeResponse) ProtoMessage() {}
+
+func (x *DeleteRangeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_kevo_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRangeResponse.ProtoReflect.Descriptor instead.
+func (*DeleteRangeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteRangeResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
 // Batch operations
+type CompareAndSwapRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           []byte                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	ExpectedValue []byte                 `protobuf:"bytes,2,opt,name=expected_value,json=expectedValue,proto3" json:"expected_value,omitempty"`
+	NewValue      []byte                 `protobuf:"bytes,3,opt,name=new_value,json=newValue,proto3" json:"new_value,omitempty"`
+	Sync          bool                   `protobuf:"varint,4,opt,name=sync,proto3" json:"sync,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompareAndSwapRequest) Reset() {
+	*x = CompareAndSwapRequest{}
+	mi := &file_proto_kevo_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompareAndSwapRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompareAndSwapRequest) ProtoMessage() {}
+
+func (x *CompareAndSwapRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_kevo_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompareAndSwapRequest.ProtoReflect.Descriptor instead.
+func (*CompareAndSwapRequest) Descriptor() ([]byte, []int) {
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CompareAndSwapRequest) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *CompareAndSwapRequest) GetExpectedValue() []byte {
+	if x != nil {
+		return x.ExpectedValue
+	}
+	return nil
+}
+
+func (x *CompareAndSwapRequest) GetNewValue() []byte {
+	if x != nil {
+		return x.NewValue
+	}
+	return nil
+}
+
+func (x *CompareAndSwapRequest) GetSync() bool {
+	if x != nil {
+		return x.Sync
+	}
+	return false
+}
+
+type PutIfAbsentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           []byte                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Sync          bool                   `protobuf:"varint,3,opt,name=sync,proto3" json:"sync,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutIfAbsentRequest) Reset() {
+	*x = PutIfAbsentRequest{}
+	mi := &file_proto_kevo_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutIfAbsentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutIfAbsentRequest) ProtoMessage() {}
+
+func (x *PutIfAbsentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_kevo_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutIfAbsentRequest.ProtoReflect.Descriptor instead.
+func (*PutIfAbsentRequest) Descriptor() ([]byte, []int) {
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PutIfAbsentRequest) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *PutIfAbsentRequest) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *PutIfAbsentRequest) GetSync() bool {
+	if x != nil {
+		return x.Sync
+	}
+	return false
+}
+
+type DeleteIfEqualsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           []byte                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	ExpectedValue []byte                 `protobuf:"bytes,2,opt,name=expected_value,json=expectedValue,proto3" json:"expected_value,omitempty"`
+	Sync          bool                   `protobuf:"varint,3,opt,name=sync,proto3" json:"sync,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteIfEqualsRequest) Reset() {
+	*x = DeleteIfEqualsRequest{}
+	mi := &file_proto_kevo_service_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteIfEqualsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteIfEqualsRequest) ProtoMessage() {}
+
+func (x *DeleteIfEqualsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_kevo_service_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteIfEqualsRequest.ProtoReflect.Descriptor instead.
+func (*DeleteIfEqualsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeleteIfEqualsRequest) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *DeleteIfEqualsRequest) GetExpectedValue() []byte {
+	if x != nil {
+		return x.ExpectedValue
+	}
+	return nil
+}
+
+func (x *DeleteIfEqualsRequest) GetSync() bool {
+	if x != nil {
+		return x.Sync
+	}
+	return false
+}
+
+type ConditionalWriteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Succeeded     bool                   `protobuf:"varint,1,opt,name=succeeded,proto3" json:"succeeded,omitempty"`                          // Whether the condition held and the write was applied
+	CurrentValue  []byte                 `protobuf:"bytes,2,opt,name=current_value,json=currentValue,proto3" json:"current_value,omitempty"` // Value of the key after the call
+	Found         bool                   `protobuf:"varint,3,opt,name=found,proto3" json:"found,omitempty"`                                  // Whether the key exists after the call
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConditionalWriteResponse) Reset() {
+	*x = ConditionalWriteResponse{}
+	mi := &file_proto_kevo_service_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConditionalWriteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConditionalWriteResponse) ProtoMessage() {}
+
+func (x *ConditionalWriteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_kevo_service_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConditionalWriteResponse.ProtoReflect.Descriptor instead.
+func (*ConditionalWriteResponse) Descriptor() ([]byte, []int) {
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ConditionalWriteResponse) GetSucceeded() bool {
+	if x != nil {
+		return x.Succeeded
+	}
+	return false
+}
+
+func (x *ConditionalWriteResponse) GetCurrentValue() []byte {
+	if x != nil {
+		return x.CurrentValue
+	}
+	return nil
+}
+
+func (x *ConditionalWriteResponse) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
 type BatchWriteRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Operations    []*Operation           `protobuf:"bytes,1,rep,name=operations,proto3" json:"operations,omitempty"`
@@ -425,7 +908,7 @@ type BatchWriteRequest struct {
 
 func (x *BatchWriteRequest) Reset() {
 	*x = BatchWriteRequest{}
-	mi := &file_proto_kevo_service_proto_msgTypes[6]
+	mi := &file_proto_kevo_service_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -437,7 +920,7 @@ func (x *BatchWriteRequest) String() string {
 func (*BatchWriteRequest) ProtoMessage() {}
 
 func (x *BatchWriteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[6]
+	mi := &file_proto_kevo_service_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -450,7 +933,7 @@ func (x *BatchWriteRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BatchWriteRequest.ProtoReflect.Descriptor instead.
 func (*BatchWriteRequest) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{6}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *BatchWriteRequest) GetOperations() []*Operation {
@@ -470,15 +953,18 @@ func (x *BatchWriteRequest) GetSync() bool {
 type Operation struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Type          Operation_Type         `protobuf:"varint,1,opt,name=type,proto3,enum=kevo.Operation_Type" json:"type,omitempty"`
-	Key           []byte                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
-	Value         []byte                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"` // Only used for PUT
+	Key           []byte                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`                                       // Start of the range for DELETE_RANGE
+	Value         []byte                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`                                   // Only used for PUT
+	EndKey        []byte                 `protobuf:"bytes,4,opt,name=end_key,json=endKey,proto3" json:"end_key,omitempty"`                   // Only used for DELETE_RANGE
+	ColumnFamily  string                 `protobuf:"bytes,5,opt,name=column_family,json=columnFamily,proto3" json:"column_family,omitempty"` // Empty for the default column family
+	Precondition  *Precondition          `protobuf:"bytes,6,opt,name=precondition,proto3" json:"precondition,omitempty"`                     // Checked against key before the batch is applied
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Operation) Reset() {
 	*x = Operation{}
-	mi := &file_proto_kevo_service_proto_msgTypes[7]
+	mi := &file_proto_kevo_service_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -490,7 +976,7 @@ func (x *Operation) String() string {
 func (*Operation) ProtoMessage() {}
 
 func (x *Operation) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[7]
+	mi := &file_proto_kevo_service_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -503,7 +989,7 @@ func (x *Operation) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Operation.ProtoReflect.Descriptor instead.
 func (*Operation) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{7}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *Operation) GetType() Operation_Type {
@@ -527,16 +1013,93 @@ func (x *Operation) GetValue() []byte {
 	return nil
 }
 
-type BatchWriteResponse struct {
+func (x *Operation) GetEndKey() []byte {
+	if x != nil {
+		return x.EndKey
+	}
+	return nil
+}
+
+func (x *Operation) GetColumnFamily() string {
+	if x != nil {
+		return x.ColumnFamily
+	}
+	return ""
+}
+
+func (x *Operation) GetPrecondition() *Precondition {
+	if x != nil {
+		return x.Precondition
+	}
+	return nil
+}
+
+type Precondition struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Type          Precondition_Type      `protobuf:"varint,1,opt,name=type,proto3,enum=kevo.Precondition_Type" json:"type,omitempty"`
+	Value         []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"` // Only used for EQUALS
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *Precondition) Reset() {
+	*x = Precondition{}
+	mi := &file_proto_kevo_service_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Precondition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Precondition) ProtoMessage() {}
+
+func (x *Precondition) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_kevo_service_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Precondition.ProtoReflect.Descriptor instead.
+func (*Precondition) Descriptor() ([]byte, []int) {
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Precondition) GetType() Precondition_Type {
+	if x != nil {
+		return x.Type
+	}
+	return Precondition_EQUALS
+}
+
+func (x *Precondition) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type BatchWriteResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Success            bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	PreconditionFailed bool                   `protobuf:"varint,2,opt,name=precondition_failed,json=preconditionFailed,proto3" json:"precondition_failed,omitempty"` // Nothing was written because a precondition failed
+	FailedOperation    int32                  `protobuf:"varint,3,opt,name=failed_operation,json=failedOperation,proto3" json:"failed_operation,omitempty"`          // Index of the operation whose precondition failed
+	CurrentValue       []byte                 `protobuf:"bytes,4,opt,name=current_value,json=currentValue,proto3" json:"current_value,omitempty"`                    // Current value of the failed operation's key
+	Found              bool                   `protobuf:"varint,5,opt,name=found,proto3" json:"found,omitempty"`                                                     // Whether the failed operation's key exists
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
 func (x *BatchWriteResponse) Reset() {
 	*x = BatchWriteResponse{}
-	mi := &file_proto_kevo_service_proto_msgTypes[8]
+	mi := &file_proto_kevo_service_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -548,7 +1111,7 @@ func (x *BatchWriteResponse) String() string {
 func (*BatchWriteResponse) ProtoMessage() {}
 
 func (x *BatchWriteResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[8]
+	mi := &file_proto_kevo_service_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -561,7 +1124,7 @@ func (x *BatchWriteResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BatchWriteResponse.ProtoReflect.Descriptor instead.
 func (*BatchWriteResponse) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{8}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *BatchWriteResponse) GetSuccess() bool {
@@ -572,6 +1135,34 @@ func (x *BatchWriteResponse) GetSuccess() bool {
 }
 
 // Iterator operations
+func (x *BatchWriteResponse) GetPreconditionFailed() bool {
+	if x != nil {
+		return x.PreconditionFailed
+	}
+	return false
+}
+
+func (x *BatchWriteResponse) GetFailedOperation() int32 {
+	if x != nil {
+		return x.FailedOperation
+	}
+	return 0
+}
+
+func (x *BatchWriteResponse) GetCurrentValue() []byte {
+	if x != nil {
+		return x.CurrentValue
+	}
+	return nil
+}
+
+func (x *BatchWriteResponse) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
 type ScanRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Prefix        []byte                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
@@ -579,13 +1170,15 @@ type ScanRequest struct {
 	StartKey      []byte                 `protobuf:"bytes,2,opt,name=start_key,json=startKey,proto3" json:"start_key,omitempty"`
 	EndKey        []byte                 `protobuf:"bytes,3,opt,name=end_key,json=endKey,proto3" json:"end_key,omitempty"`
 	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	ColumnFamily  string                 `protobuf:"bytes,6,opt,name=column_family,json=columnFamily,proto3" json:"column_family,omitempty"` // Empty for the default column family
+	Reverse       bool                   `protobuf:"varint,7,opt,name=reverse,proto3" json:"reverse,omitempty"`                              // Iterate from the last key to the first
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ScanRequest) Reset() {
 	*x = ScanRequest{}
-	mi := &file_proto_kevo_service_proto_msgTypes[9]
+	mi := &file_proto_kevo_service_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -597,7 +1190,7 @@ func (x *ScanRequest) String() string {
 func (*ScanRequest) ProtoMessage() {}
 
 func (x *ScanRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[9]
+	mi := &file_proto_kevo_service_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -610,7 +1203,7 @@ func (x *ScanRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ScanRequest.ProtoReflect.Descriptor instead.
 func (*ScanRequest) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{9}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *ScanRequest) GetPrefix() []byte {
@@ -648,6 +1241,20 @@ func (x *ScanRequest) GetLimit() int32 {
 	return 0
 }
 
+func (x *ScanRequest) GetColumnFamily() string {
+	if x != nil {
+		return x.ColumnFamily
+	}
+	return ""
+}
+
+func (x *ScanRequest) GetReverse() bool {
+	if x != nil {
+		return x.Reverse
+	}
+	return false
+}
+
 type ScanResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Key           []byte                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
@@ -658,7 +1265,7 @@ type ScanResponse struct {
 
 func (x *ScanResponse) Reset() {
 	*x = ScanResponse{}
-	mi := &file_proto_kevo_service_proto_msgTypes[10]
+	mi := &file_proto_kevo_service_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -670,7 +1277,7 @@ func (x *ScanResponse) String() string {
 func (*ScanResponse) ProtoMessage() {}
 
 func (x *ScanResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[10]
+	mi := &file_proto_kevo_service_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -683,7 +1290,7 @@ func (x *ScanResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ScanResponse.ProtoReflect.Descriptor instead.
 func (*ScanResponse) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{10}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *ScanResponse) GetKey() []byte {
@@ -702,15 +1309,16 @@ func (x *ScanResponse) GetValue() []byte {
 
 // Transaction operations
 type BeginTransactionRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ReadOnly      bool                   `protobuf:"varint,1,opt,name=read_only,json=readOnly,proto3" json:"read_only,omitempty"`
+	state         protoimpl.MessageState                 `protogen:"open.v1"`
+	ReadOnly      bool                                   `protobuf:"varint,1,opt,name=read_only,json=readOnly,proto3" json:"read_only,omitempty"`
+	Isolation     BeginTransactionRequest_IsolationLevel `protobuf:"varint,2,opt,name=isolation,proto3,enum=kevo.BeginTransactionRequest_IsolationLevel" json:"isolation,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *BeginTransactionRequest) Reset() {
 	*x = BeginTransactionRequest{}
-	mi := &file_proto_kevo_service_proto_msgTypes[11]
+	mi := &file_proto_kevo_service_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -722,7 +1330,7 @@ func (x *BeginTransactionRequest) String() string {
 func (*BeginTransactionRequest) ProtoMessage() {}
 
 func (x *BeginTransactionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[11]
+	mi := &file_proto_kevo_service_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -735,7 +1343,7 @@ func (x *BeginTransactionRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BeginTransactionRequest.ProtoReflect.Descriptor instead.
 func (*BeginTransactionRequest) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{11}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *BeginTransactionRequest) GetReadOnly() bool {
@@ -745,6 +1353,13 @@ func (x *BeginTransactionRequest) GetReadOnly() bool {
 	return false
 }
 
+func (x *BeginTransactionRequest) GetIsolation() BeginTransactionRequest_IsolationLevel {
+	if x != nil {
+		return x.Isolation
+	}
+	return BeginTransactionRequest_SNAPSHOT
+}
+
 type BeginTransactionResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
@@ -754,7 +1369,7 @@ type BeginTransactionResponse struct {
 
 func (x *BeginTransactionResponse) Reset() {
 	*x = BeginTransactionResponse{}
-	mi := &file_proto_kevo_service_proto_msgTypes[12]
+	mi := &file_proto_kevo_service_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -766,7 +1381,7 @@ func (x *BeginTransactionResponse) String() string {
 func (*BeginTransactionResponse) ProtoMessage() {}
 
 func (x *BeginTransactionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[12]
+	mi := &file_proto_kevo_service_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -779,7 +1394,7 @@ func (x *BeginTransactionResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BeginTransactionResponse.ProtoReflect.Descriptor instead.
 func (*BeginTransactionResponse) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{12}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *BeginTransactionResponse) GetTransactionId() string {
@@ -798,7 +1413,7 @@ type CommitTransactionRequest struct {
 
 func (x *CommitTransactionRequest) Reset() {
 	*x = CommitTransactionRequest{}
-	mi := &file_proto_kevo_service_proto_msgTypes[13]
+	mi := &file_proto_kevo_service_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -810,7 +1425,7 @@ func (x *CommitTransactionRequest) String() string {
 func (*CommitTransactionRequest) ProtoMessage() {}
 
 func (x *CommitTransactionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[13]
+	mi := &file_proto_kevo_service_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -823,7 +1438,7 @@ func (x *CommitTransactionRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CommitTransactionRequest.ProtoReflect.Descriptor instead.
 func (*CommitTransactionRequest) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{13}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *CommitTransactionRequest) GetTransactionId() string {
@@ -842,7 +1457,7 @@ type CommitTransactionResponse struct {
 
 func (x *CommitTransactionResponse) Reset() {
 	*x = CommitTransactionResponse{}
-	mi := &file_proto_kevo_service_proto_msgTypes[14]
+	mi := &file_proto_kevo_service_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -854,7 +1469,7 @@ func (x *CommitTransactionResponse) String() string {
 func (*CommitTransactionResponse) ProtoMessage() {}
 
 func (x *CommitTransactionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[14]
+	mi := &file_proto_kevo_service_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -867,7 +1482,7 @@ func (x *CommitTransactionResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CommitTransactionResponse.ProtoReflect.Descriptor instead.
 func (*CommitTransactionResponse) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{14}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *CommitTransactionResponse) GetSuccess() bool {
@@ -886,7 +1501,7 @@ type RollbackTransactionRequest struct {
 
 func (x *RollbackTransactionRequest) Reset() {
 	*x = RollbackTransactionRequest{}
-	mi := &file_proto_kevo_service_proto_msgTypes[15]
+	mi := &file_proto_kevo_service_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -898,7 +1513,7 @@ func (x *RollbackTransactionRequest) String() string {
 func (*RollbackTransactionRequest) ProtoMessage() {}
 
 func (x *RollbackTransactionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[15]
+	mi := &file_proto_kevo_service_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -911,7 +1526,7 @@ func (x *RollbackTransactionRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RollbackTransactionRequest.ProtoReflect.Descriptor instead.
 func (*RollbackTransactionRequest) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{15}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *RollbackTransactionRequest) GetTransactionId() string {
@@ -930,7 +1545,7 @@ type RollbackTransactionResponse struct {
 
 func (x *RollbackTransactionResponse) Reset() {
 	*x = RollbackTransactionResponse{}
-	mi := &file_proto_kevo_service_proto_msgTypes[16]
+	mi := &file_proto_kevo_service_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -942,7 +1557,7 @@ func (x *RollbackTransactionResponse) String() string {
 func (*RollbackTransactionResponse) ProtoMessage() {}
 
 func (x *RollbackTransactionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[16]
+	mi := &file_proto_kevo_service_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -955,7 +1570,7 @@ func (x *RollbackTransactionResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RollbackTransactionResponse.ProtoReflect.Descriptor instead.
 func (*RollbackTransactionResponse) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{16}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *RollbackTransactionResponse) GetSuccess() bool {
@@ -975,7 +1590,7 @@ type TxGetRequest struct {
 
 func (x *TxGetRequest) Reset() {
 	*x = TxGetRequest{}
-	mi := &file_proto_kevo_service_proto_msgTypes[17]
+	mi := &file_proto_kevo_service_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -987,7 +1602,7 @@ func (x *TxGetRequest) String() string {
 func (*TxGetRequest) ProtoMessage() {}
 
 func (x *TxGetRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[17]
+	mi := &file_proto_kevo_service_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1000,7 +1615,7 @@ func (x *TxGetRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TxGetRequest.ProtoReflect.Descriptor instead.
 func (*TxGetRequest) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{17}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *TxGetRequest) GetTransactionId() string {
@@ -1027,7 +1642,7 @@ type TxGetResponse struct {
 
 func (x *TxGetResponse) Reset() {
 	*x = TxGetResponse{}
-	mi := &file_proto_kevo_service_proto_msgTypes[18]
+	mi := &file_proto_kevo_service_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1039,7 +1654,7 @@ func (x *TxGetResponse) String() string {
 func (*TxGetResponse) ProtoMessage() {}
 
 func (x *TxGetResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[18]
+	mi := &file_proto_kevo_service_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1052,7 +1667,7 @@ func (x *TxGetResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TxGetResponse.ProtoReflect.Descriptor instead.
 func (*TxGetResponse) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{18}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *TxGetResponse) GetValue() []byte {
@@ -1080,7 +1695,7 @@ type TxPutRequest struct {
 
 func (x *TxPutRequest) Reset() {
 	*x = TxPutRequest{}
-	mi := &file_proto_kevo_service_proto_msgTypes[19]
+	mi := &file_proto_kevo_service_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1092,7 +1707,7 @@ func (x *TxPutRequest) String() string {
 func (*TxPutRequest) ProtoMessage() {}
 
 func (x *TxPutRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[19]
+	mi := &file_proto_kevo_service_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1105,7 +1720,7 @@ func (x *TxPutRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TxPutRequest.ProtoReflect.Descriptor instead.
 func (*TxPutRequest) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{19}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *TxPutRequest) GetTransactionId() string {
@@ -1138,7 +1753,7 @@ type TxPutResponse struct {
 
 func (x *TxPutResponse) Reset() {
 	*x = TxPutResponse{}
-	mi := &file_proto_kevo_service_proto_msgTypes[20]
+	mi := &file_proto_kevo_service_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1150,7 +1765,7 @@ func (x *TxPutResponse) String() string {
 func (*TxPutResponse) ProtoMessage() {}
 
 func (x *TxPutResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[20]
+	mi := &file_proto_kevo_service_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1163,7 +1778,7 @@ func (x *TxPutResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TxPutResponse.ProtoReflect.Descriptor instead.
 func (*TxPutResponse) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{20}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *TxPutResponse) GetSuccess() bool {
@@ -1183,7 +1798,7 @@ type TxDeleteRequest struct {
 
 func (x *TxDeleteRequest) Reset() {
 	*x = TxDeleteRequest{}
-	mi := &file_proto_kevo_service_proto_msgTypes[21]
+	mi := &file_proto_kevo_service_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1195,7 +1810,7 @@ func (x *TxDeleteRequest) String() string {
 func (*TxDeleteRequest) ProtoMessage() {}
 
 func (x *TxDeleteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[21]
+	mi := &file_proto_kevo_service_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1208,7 +1823,7 @@ func (x *TxDeleteRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TxDeleteRequest.ProtoReflect.Descriptor instead.
 func (*TxDeleteRequest) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{21}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *TxDeleteRequest) GetTransactionId() string {
@@ -1234,7 +1849,7 @@ type TxDeleteResponse struct {
 
 func (x *TxDeleteResponse) Reset() {
 	*x = TxDeleteResponse{}
-	mi := &file_proto_kevo_service_proto_msgTypes[22]
+	mi := &file_proto_kevo_service_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1246,7 +1861,7 @@ func (x *TxDeleteResponse) String() string {
 func (*TxDeleteResponse) ProtoMessage() {}
 
 func (x *TxDeleteResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[22]
+	mi := &file_proto_kevo_service_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1259,7 +1874,7 @@ func (x *TxDeleteResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TxDeleteResponse.ProtoReflect.Descriptor instead.
 func (*TxDeleteResponse) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{22}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *TxDeleteResponse) GetSuccess() bool {
@@ -1277,13 +1892,14 @@ type TxScanRequest struct {
 	StartKey      []byte                 `protobuf:"bytes,3,opt,name=start_key,json=startKey,proto3" json:"start_key,omitempty"`
 	EndKey        []byte                 `protobuf:"bytes,4,opt,name=end_key,json=endKey,proto3" json:"end_key,omitempty"`
 	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	Reverse       bool                   `protobuf:"varint,7,opt,name=reverse,proto3" json:"reverse,omitempty"` // Iterate from the last key to the first
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *TxScanRequest) Reset() {
 	*x = TxScanRequest{}
-	mi := &file_proto_kevo_service_proto_msgTypes[23]
+	mi := &file_proto_kevo_service_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1295,7 +1911,7 @@ func (x *TxScanRequest) String() string {
 func (*TxScanRequest) ProtoMessage() {}
 
 func (x *TxScanRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[23]
+	mi := &file_proto_kevo_service_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1308,7 +1924,7 @@ func (x *TxScanRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TxScanRequest.ProtoReflect.Descriptor instead.
 func (*TxScanRequest) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{23}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *TxScanRequest) GetTransactionId() string {
@@ -1353,6 +1969,13 @@ func (x *TxScanRequest) GetLimit() int32 {
 	return 0
 }
 
+func (x *TxScanRequest) GetReverse() bool {
+	if x != nil {
+		return x.Reverse
+	}
+	return false
+}
+
 type TxScanResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Key           []byte                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
@@ -1363,7 +1986,7 @@ type TxScanResponse struct {
 
 func (x *TxScanResponse) Reset() {
 	*x = TxScanResponse{}
-	mi := &file_proto_kevo_service_proto_msgTypes[24]
+	mi := &file_proto_kevo_service_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1375,7 +1998,7 @@ func (x *TxScanResponse) String() string {
 func (*TxScanResponse) ProtoMessage() {}
 
 func (x *TxScanResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[24]
+	mi := &file_proto_kevo_service_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1388,7 +2011,7 @@ func (x *TxScanResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TxScanResponse.ProtoReflect.Descriptor instead.
 func (*TxScanResponse) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{24}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *TxScanResponse) GetKey() []byte {
@@ -1414,7 +2037,7 @@ type GetStatsRequest struct {
 
 func (x *GetStatsRequest) Reset() {
 	*x = GetStatsRequest{}
-	mi := &file_proto_kevo_service_proto_msgTypes[25]
+	mi := &file_proto_kevo_service_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1426,7 +2049,7 @@ func (x *GetStatsRequest) String() string {
 func (*GetStatsRequest) ProtoMessage() {}
 
 func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[25]
+	mi := &file_proto_kevo_service_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1439,7 +2062,7 @@ func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
 func (*GetStatsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{25}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{32}
 }
 
 type GetStatsResponse struct {
@@ -1469,7 +2092,7 @@ type GetStatsResponse struct {
 
 func (x *GetStatsResponse) Reset() {
 	*x = GetStatsResponse{}
-	mi := &file_proto_kevo_service_proto_msgTypes[26]
+	mi := &file_proto_kevo_service_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1481,7 +2104,7 @@ func (x *GetStatsResponse) String() string {
 func (*GetStatsResponse) ProtoMessage() {}
 
 func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[26]
+	mi := &file_proto_kevo_service_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1494,7 +2117,7 @@ func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetStatsResponse.ProtoReflect.Descriptor instead.
 func (*GetStatsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{26}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *GetStatsResponse) GetKeyCount() int64 {
@@ -1607,7 +2230,7 @@ type LatencyStats struct {
 
 func (x *LatencyStats) Reset() {
 	*x = LatencyStats{}
-	mi := &file_proto_kevo_service_proto_msgTypes[27]
+	mi := &file_proto_kevo_service_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1619,7 +2242,7 @@ func (x *LatencyStats) String() string {
 func (*LatencyStats) ProtoMessage() {}
 
 func (x *LatencyStats) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[27]
+	mi := &file_proto_kevo_service_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1632,7 +2255,7 @@ func (x *LatencyStats) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LatencyStats.ProtoReflect.Descriptor instead.
 func (*LatencyStats) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{27}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *LatencyStats) GetCount() uint64 {
@@ -1675,7 +2298,7 @@ type RecoveryStats struct {
 
 func (x *RecoveryStats) Reset() {
 	*x = RecoveryStats{}
-	mi := &file_proto_kevo_service_proto_msgTypes[28]
+	mi := &file_proto_kevo_service_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1687,7 +2310,7 @@ func (x *RecoveryStats) String() string {
 func (*RecoveryStats) ProtoMessage() {}
 
 func (x *RecoveryStats) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[28]
+	mi := &file_proto_kevo_service_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1700,7 +2323,7 @@ func (x *RecoveryStats) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RecoveryStats.ProtoReflect.Descriptor instead.
 func (*RecoveryStats) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{28}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *RecoveryStats) GetWalFilesRecovered() uint64 {
@@ -1740,7 +2363,7 @@ type CompactRequest struct {
 
 func (x *CompactRequest) Reset() {
 	*x = CompactRequest{}
-	mi := &file_proto_kevo_service_proto_msgTypes[29]
+	mi := &file_proto_kevo_service_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1752,7 +2375,7 @@ func (x *CompactRequest) String() string {
 func (*CompactRequest) ProtoMessage() {}
 
 func (x *CompactRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[29]
+	mi := &file_proto_kevo_service_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1765,7 +2388,7 @@ func (x *CompactRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CompactRequest.ProtoReflect.Descriptor instead.
 func (*CompactRequest) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{29}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{36}
 }
 
 func (x *CompactRequest) GetForce() bool {
@@ -1784,7 +2407,7 @@ type CompactResponse struct {
 
 func (x *CompactResponse) Reset() {
 	*x = CompactResponse{}
-	mi := &file_proto_kevo_service_proto_msgTypes[30]
+	mi := &file_proto_kevo_service_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1796,7 +2419,7 @@ func (x *CompactResponse) String() string {
 func (*CompactResponse) ProtoMessage() {}
 
 func (x *CompactResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[30]
+	mi := &file_proto_kevo_service_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1809,7 +2432,7 @@ func (x *CompactResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CompactResponse.ProtoReflect.Descriptor instead.
 func (*CompactResponse) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{30}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{37}
 }
 
 func (x *CompactResponse) GetSuccess() bool {
@@ -1819,6 +2442,206 @@ func (x *CompactResponse) GetSuccess() bool {
 	return false
 }
 
+type CheckpointRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Dir           string                 `protobuf:"bytes,1,opt,name=dir,proto3" json:"dir,omitempty"` // Directory on the server, which must not exist yet
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckpointRequest) Reset() {
+	*x = CheckpointRequest{}
+	mi := &file_proto_kevo_service_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckpointRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckpointRequest) ProtoMessage() {}
+
+func (x *CheckpointRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_kevo_service_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckpointRequest.ProtoReflect.Descriptor instead.
+func (*CheckpointRequest) Descriptor() ([]byte, []int) {
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *CheckpointRequest) GetDir() string {
+	if x != nil {
+		return x.Dir
+	}
+	return ""
+}
+
+type CheckpointResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Sequence      uint64                 `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"` // Last sequence number included in the checkpoint
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckpointResponse) Reset() {
+	*x = CheckpointResponse{}
+	mi := &file_proto_kevo_service_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckpointResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckpointResponse) ProtoMessage() {}
+
+func (x *CheckpointResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_kevo_service_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckpointResponse.ProtoReflect.Descriptor instead.
+func (*CheckpointResponse) Descriptor() ([]byte, []int) {
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *CheckpointResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CheckpointResponse) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+type IngestSSTablesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Paths         []string               `protobuf:"bytes,1,rep,name=paths,proto3" json:"paths,omitempty"`                                   // SSTable files on the server
+	ColumnFamily  string                 `protobuf:"bytes,2,opt,name=column_family,json=columnFamily,proto3" json:"column_family,omitempty"` // Empty for the default column family
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IngestSSTablesRequest) Reset() {
+	*x = IngestSSTablesRequest{}
+	mi := &file_proto_kevo_service_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IngestSSTablesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestSSTablesRequest) ProtoMessage() {}
+
+func (x *IngestSSTablesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_kevo_service_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestSSTablesRequest.ProtoReflect.Descriptor instead.
+func (*IngestSSTablesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *IngestSSTablesRequest) GetPaths() []string {
+	if x != nil {
+		return x.Paths
+	}
+	return nil
+}
+
+func (x *IngestSSTablesRequest) GetColumnFamily() string {
+	if x != nil {
+		return x.ColumnFamily
+	}
+	return ""
+}
+
+type IngestSSTablesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Sequence      uint64                 `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"` // Sequence number given to the ingested entries
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IngestSSTablesResponse) Reset() {
+	*x = IngestSSTablesResponse{}
+	mi := &file_proto_kevo_service_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IngestSSTablesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestSSTablesResponse) ProtoMessage() {}
+
+func (x *IngestSSTablesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_kevo_service_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestSSTablesResponse.ProtoReflect.Descriptor instead.
+func (*IngestSSTablesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *IngestSSTablesResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *IngestSSTablesResponse) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
 // Node information and topology
 type GetNodeInfoRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1828,7 +2651,7 @@ type GetNodeInfoRequest struct {
 
 func (x *GetNodeInfoRequest) Reset() {
 	*x = GetNodeInfoRequest{}
-	mi := &file_proto_kevo_service_proto_msgTypes[31]
+	mi := &file_proto_kevo_service_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1840,7 +2663,7 @@ func (x *GetNodeInfoRequest) String() string {
 func (*GetNodeInfoRequest) ProtoMessage() {}
 
 func (x *GetNodeInfoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[31]
+	mi := &file_proto_kevo_service_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1853,7 +2676,7 @@ func (x *GetNodeInfoRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetNodeInfoRequest.ProtoReflect.Descriptor instead.
 func (*GetNodeInfoRequest) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{31}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{42}
 }
 
 type GetNodeInfoResponse struct {
@@ -1873,7 +2696,7 @@ type GetNodeInfoResponse struct {
 
 func (x *GetNodeInfoResponse) Reset() {
 	*x = GetNodeInfoResponse{}
-	mi := &file_proto_kevo_service_proto_msgTypes[32]
+	mi := &file_proto_kevo_service_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1885,7 +2708,7 @@ func (x *GetNodeInfoResponse) String() string {
 func (*GetNodeInfoResponse) ProtoMessage() {}
 
 func (x *GetNodeInfoResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[32]
+	mi := &file_proto_kevo_service_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1898,7 +2721,7 @@ func (x *GetNodeInfoResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetNodeInfoResponse.ProtoReflect.Descriptor instead.
 func (*GetNodeInfoResponse) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{32}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{43}
 }
 
 func (x *GetNodeInfoResponse) GetNodeRole() GetNodeInfoResponse_NodeRole {
@@ -1956,7 +2779,7 @@ type ReplicaInfo struct {
 
 func (x *ReplicaInfo) Reset() {
 	*x = ReplicaInfo{}
-	mi := &file_proto_kevo_service_proto_msgTypes[33]
+	mi := &file_proto_kevo_service_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1968,7 +2791,7 @@ func (x *ReplicaInfo) String() string {
 func (*ReplicaInfo) ProtoMessage() {}
 
 func (x *ReplicaInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_kevo_service_proto_msgTypes[33]
+	mi := &file_proto_kevo_service_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1981,7 +2804,7 @@ func (x *ReplicaInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReplicaInfo.ProtoReflect.Descriptor instead.
 func (*ReplicaInfo) Descriptor() ([]byte, []int) {
-	return file_proto_kevo_service_proto_rawDescGZIP(), []int{33}
+	return file_proto_kevo_service_proto_rawDescGZIP(), []int{44}
 }
 
 func (x *ReplicaInfo) GetAddress() string {
@@ -2023,51 +2846,102 @@ var File_proto_kevo_service_proto protoreflect.FileDescriptor
 
 const file_proto_kevo_service_proto_rawDesc = "" +
 	"\n" +
-	"\x18proto/kevo/service.proto\x12\x04kevo\"\x1e\n" +
+	"\x18proto/kevo/service.proto\x12\x04kevo\"C\n" +
 	"\n" +
 	"GetRequest\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\fR\x03key\"9\n" +
+	"\x03key\x18\x01 \x01(\fR\x03key\x12#\n" +
+	"\rcolumn_family\x18\x02 \x01(\tR\fcolumnFamily\"9\n" +
 	"\vGetResponse\x12\x14\n" +
 	"\x05value\x18\x01 \x01(\fR\x05value\x12\x14\n" +
-	"\x05found\x18\x02 \x01(\bR\x05found\"H\n" +
+	"\x05found\x18\x02 \x01(\bR\x05found\"\x84\x01\n" +
 	"\n" +
 	"PutRequest\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\fR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\fR\x05value\x12\x12\n" +
-	"\x04sync\x18\x03 \x01(\bR\x04sync\"'\n" +
+	"\x04sync\x18\x03 \x01(\bR\x04sync\x12\x15\n" +
+	"\x06ttl_ms\x18\x04 \x01(\x03R\x05ttlMs\x12#\n" +
+	"\rcolumn_family\x18\x05 \x01(\tR\fcolumnFamily\"'\n" +
 	"\vPutResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"5\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"Z\n" +
 	"\rDeleteRequest\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\fR\x03key\x12\x12\n" +
-	"\x04sync\x18\x02 \x01(\bR\x04sync\"*\n" +
+	"\x04sync\x18\x02 \x01(\bR\x04sync\x12#\n" +
+	"\rcolumn_family\x18\x03 \x01(\tR\fcolumnFamily\"*\n" +
 	"\x0eDeleteResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"X\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"^\n" +
+	"\x12DeleteRangeRequest\x12\x1b\n" +
+	"\tstart_key\x18\x01 \x01(\fR\bstartKey\x12\x17\n" +
+	"\aend_key\x18\x02 \x01(\fR\x06endKey\x12\x12\n" +
+	"\x04sync\x18\x03 \x01(\bR\x04sync\"/\n" +
+	"\x13DeleteRangeResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x81\x01\n" +
+	"\x15CompareAndSwapRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\fR\x03key\x12%\n" +
+	"\x0eexpected_value\x18\x02 \x01(\fR\rexpectedValue\x12\x1b\n" +
+	"\tnew_value\x18\x03 \x01(\fR\bnewValue\x12\x12\n" +
+	"\x04sync\x18\x04 \x01(\bR\x04sync\"P\n" +
+	"\x12PutIfAbsentRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\fR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\x12\x12\n" +
+	"\x04sync\x18\x03 \x01(\bR\x04sync\"d\n" +
+	"\x15DeleteIfEqualsRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\fR\x03key\x12%\n" +
+	"\x0eexpected_value\x18\x02 \x01(\fR\rexpectedValue\x12\x12\n" +
+	"\x04sync\x18\x03 \x01(\bR\x04sync\"s\n" +
+	"\x18ConditionalWriteResponse\x12\x1c\n" +
+	"\tsucceeded\x18\x01 \x01(\bR\tsucceeded\x12#\n" +
+	"\rcurrent_value\x18\x02 \x01(\fR\fcurrentValue\x12\x14\n" +
+	"\x05found\x18\x03 \x01(\bR\x05found\"X\n" +
 	"\x11BatchWriteRequest\x12/\n" +
 	"\n" +
 	"operations\x18\x01 \x03(\v2\x0f.kevo.OperationR\n" +
 	"operations\x12\x12\n" +
-	"\x04sync\x18\x02 \x01(\bR\x04sync\"z\n" +
+	"\x04sync\x18\x02 \x01(\bR\x04sync\"\x82\x02\n" +
 	"\tOperation\x12(\n" +
 	"\x04type\x18\x01 \x01(\x0e2\x14.kevo.Operation.TypeR\x04type\x12\x10\n" +
 	"\x03key\x18\x02 \x01(\fR\x03key\x12\x14\n" +
-	"\x05value\x18\x03 \x01(\fR\x05value\"\x1b\n" +
+	"\x05value\x18\x03 \x01(\fR\x05value\x12\x17\n" +
+	"\aend_key\x18\x04 \x01(\fR\x06endKey\x12#\n" +
+	"\rcolumn_family\x18\x05 \x01(\tR\fcolumnFamily\x126\n" +
+	"\fprecondition\x18\x06 \x01(\v2\x12.kevo.PreconditionR\fprecondition\"-\n" +
 	"\x04Type\x12\a\n" +
 	"\x03PUT\x10\x00\x12\n" +
 	"\n" +
-	"\x06DELETE\x10\x01\".\n" +
+	"\x06DELETE\x10\x01\x12\x10\n" +
+	"\fDELETE_RANGE\x10\x02\"}\n" +
+	"\fPrecondition\x12+\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x17.kevo.Precondition.TypeR\x04type\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\"*\n" +
+	"\x04Type\x12\n" +
+	"\n" +
+	"\x06EQUALS\x10\x00\x12\n" +
+	"\n" +
+	"\x06ABSENT\x10\x01\x12\n" +
+	"\n" +
+	"\x06EXISTS\x10\x02\"\xc5\x01\n" +
 	"\x12BatchWriteResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x89\x01\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12/\n" +
+	"\x13precondition_failed\x18\x02 \x01(\bR\x12preconditionFailed\x12)\n" +
+	"\x10failed_operation\x18\x03 \x01(\x05R\x0ffailedOperation\x12#\n" +
+	"\rcurrent_value\x18\x04 \x01(\fR\fcurrentValue\x12\x14\n" +
+	"\x05found\x18\x05 \x01(\bR\x05found\"\xc8\x01\n" +
 	"\vScanRequest\x12\x16\n" +
 	"\x06prefix\x18\x01 \x01(\fR\x06prefix\x12\x16\n" +
 	"\x06suffix\x18\x05 \x01(\fR\x06suffix\x12\x1b\n" +
 	"\tstart_key\x18\x02 \x01(\fR\bstartKey\x12\x17\n" +
 	"\aend_key\x18\x03 \x01(\fR\x06endKey\x12\x14\n" +
-	"\x05limit\x18\x04 \x01(\x05R\x05limit\"6\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\x12#\n" +
+	"\rcolumn_family\x18\x06 \x01(\tR\fcolumnFamily\x12\x18\n" +
+	"\areverse\x18\a \x01(\bR\areverse\"6\n" +
 	"\fScanResponse\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\fR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\fR\x05value\"6\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\"\xb4\x01\n" +
 	"\x17BeginTransactionRequest\x12\x1b\n" +
-	"\tread_only\x18\x01 \x01(\bR\breadOnly\"A\n" +
+	"\tread_only\x18\x01 \x01(\bR\breadOnly\x12J\n" +
+	"\tisolation\x18\x02 \x01(\x0e2,.kevo.BeginTransactionRequest.IsolationLevelR\tisolation\"0\n" +
+	"\x0eIsolationLevel\x12\f\n" +
+	"\bSNAPSHOT\x10\x00\x12\x10\n" +
+	"\fSERIALIZABLE\x10\x01\"A\n" +
 	"\x18BeginTransactionResponse\x12%\n" +
 	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\"A\n" +
 	"\x18CommitTransactionRequest\x12%\n" +
@@ -2094,14 +2968,15 @@ const file_proto_kevo_service_proto_rawDesc = "" +
 	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x10\n" +
 	"\x03key\x18\x02 \x01(\fR\x03key\",\n" +
 	"\x10TxDeleteResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"\xb2\x01\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\xcc\x01\n" +
 	"\rTxScanRequest\x12%\n" +
 	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x16\n" +
 	"\x06prefix\x18\x02 \x01(\fR\x06prefix\x12\x16\n" +
 	"\x06suffix\x18\x06 \x01(\fR\x06suffix\x12\x1b\n" +
 	"\tstart_key\x18\x03 \x01(\fR\bstartKey\x12\x17\n" +
 	"\aend_key\x18\x04 \x01(\fR\x06endKey\x12\x14\n" +
-	"\x05limit\x18\x05 \x01(\x05R\x05limit\"8\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12\x18\n" +
+	"\areverse\x18\a \x01(\bR\areverse\"8\n" +
 	"\x0eTxScanResponse\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\fR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\fR\x05value\"\x11\n" +
@@ -2145,7 +3020,18 @@ const file_proto_kevo_service_proto_rawDesc = "" +
 	"\x0eCompactRequest\x12\x14\n" +
 	"\x05force\x18\x01 \x01(\bR\x05force\"+\n" +
 	"\x0fCompactResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x14\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"%\n" +
+	"\x11CheckpointRequest\x12\x10\n" +
+	"\x03dir\x18\x01 \x01(\tR\x03dir\"J\n" +
+	"\x12CheckpointResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1a\n" +
+	"\bsequence\x18\x02 \x01(\x04R\bsequence\"R\n" +
+	"\x15IngestSSTablesRequest\x12\x14\n" +
+	"\x05paths\x18\x01 \x03(\tR\x05paths\x12#\n" +
+	"\rcolumn_family\x18\x02 \x01(\tR\fcolumnFamily\"N\n" +
+	"\x16IngestSSTablesResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1a\n" +
+	"\bsequence\x18\x02 \x01(\x04R\bsequence\"\x14\n" +
 	"\x12GetNodeInfoRequest\"\xc0\x02\n" +
 	"\x13GetNodeInfoResponse\x12?\n" +
 	"\tnode_role\x18\x01 \x01(\x0e2\".kevo.GetNodeInfoResponse.NodeRoleR\bnodeRole\x12'\n" +
@@ -2167,11 +3053,16 @@ const file_proto_kevo_service_proto_rawDesc = "" +
 	"\x04meta\x18\x05 \x03(\v2\x1b.kevo.ReplicaInfo.MetaEntryR\x04meta\x1a7\n" +
 	"\tMetaEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x012\x9e\a\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x012\xd7\n" +
+	"\n" +
 	"\vKevoService\x12*\n" +
 	"\x03Get\x12\x10.kevo.GetRequest\x1a\x11.kevo.GetResponse\x12*\n" +
 	"\x03Put\x12\x10.kevo.PutRequest\x1a\x11.kevo.PutResponse\x123\n" +
-	"\x06Delete\x12\x13.kevo.DeleteRequest\x1a\x14.kevo.DeleteResponse\x12?\n" +
+	"\x06Delete\x12\x13.kevo.DeleteRequest\x1a\x14.kevo.DeleteResponse\x12B\n" +
+	"\vDeleteRange\x12\x18.kevo.DeleteRangeRequest\x1a\x19.kevo.DeleteRangeResponse\x12M\n" +
+	"\x0eCompareAndSwap\x12\x1b.kevo.CompareAndSwapRequest\x1a\x1e.kevo.ConditionalWriteResponse\x12G\n" +
+	"\vPutIfAbsent\x12\x18.kevo.PutIfAbsentRequest\x1a\x1e.kevo.ConditionalWriteResponse\x12M\n" +
+	"\x0eDeleteIfEquals\x12\x1b.kevo.DeleteIfEqualsRequest\x1a\x1e.kevo.ConditionalWriteResponse\x12?\n" +
 	"\n" +
 	"BatchWrite\x12\x17.kevo.BatchWriteRequest\x1a\x18.kevo.BatchWriteResponse\x12/\n" +
 	"\x04Scan\x12\x11.kevo.ScanRequest\x1a\x12.kevo.ScanResponse0\x01\x12Q\n" +
@@ -2183,7 +3074,10 @@ const file_proto_kevo_service_proto_rawDesc = "" +
 	"\bTxDelete\x12\x15.kevo.TxDeleteRequest\x1a\x16.kevo.TxDeleteResponse\x125\n" +
 	"\x06TxScan\x12\x13.kevo.TxScanRequest\x1a\x14.kevo.TxScanResponse0\x01\x129\n" +
 	"\bGetStats\x12\x15.kevo.GetStatsRequest\x1a\x16.kevo.GetStatsResponse\x126\n" +
-	"\aCompact\x12\x14.kevo.CompactRequest\x1a\x15.kevo.CompactResponse\x12B\n" +
+	"\aCompact\x12\x14.kevo.CompactRequest\x1a\x15.kevo.CompactResponse\x12?\n" +
+	"\n" +
+	"Checkpoint\x12\x17.kevo.CheckpointRequest\x1a\x18.kevo.CheckpointResponse\x12K\n" +
+	"\x0eIngestSSTables\x12\x1b.kevo.IngestSSTablesRequest\x1a\x1c.kevo.IngestSSTablesResponse\x12B\n" +
 	"\vGetNodeInfo\x12\x18.kevo.GetNodeInfoRequest\x1a\x19.kevo.GetNodeInfoResponseB-Z+github.com/KevoDB/kevo/pkg/grpc/proto;protob\x06proto3"
 
 var (
@@ -2198,96 +3092,124 @@ func file_proto_kevo_service_proto_rawDescGZIP() []byte {
 	return file_proto_kevo_service_proto_rawDescData
 }
 
-var file_proto_kevo_service_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_proto_kevo_service_proto_msgTypes = make([]protoimpl.MessageInfo, 38)
+var file_proto_kevo_service_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_proto_kevo_service_proto_msgTypes = make([]protoimpl.MessageInfo, 49)
 var file_proto_kevo_service_proto_goTypes = []any{
-	(Operation_Type)(0),                 // 0: kevo.Operation.Type
-	(GetNodeInfoResponse_NodeRole)(0),   // 1: kevo.GetNodeInfoResponse.NodeRole
-	(*GetRequest)(nil),                  // 2: kevo.GetRequest
-	(*GetResponse)(nil),                 // 3: kevo.GetResponse
-	(*PutRequest)(nil),                  // 4: kevo.PutRequest
-	(*PutResponse)(nil),                 // 5: kevo.PutResponse
-	(*DeleteRequest)(nil),               // 6: kevo.DeleteRequest
-	(*DeleteResponse)(nil),              // 7: kevo.DeleteResponse
-	(*BatchWriteRequest)(nil),           // 8: kevo.BatchWriteRequest
-	(*Operation)(nil),                   // 9: kevo.Operation
-	(*BatchWriteResponse)(nil),          // 10: kevo.BatchWriteResponse
-	(*ScanRequest)(nil),                 // 11: kevo.ScanRequest
-	(*ScanResponse)(nil),                // 12: kevo.ScanResponse
-	(*BeginTransactionRequest)(nil),     // 13: kevo.BeginTransactionRequest
-	(*BeginTransactionResponse)(nil),    // 14: kevo.BeginTransactionResponse
-	(*CommitTransactionRequest)(nil),    // 15: kevo.CommitTransactionRequest
-	(*CommitTransactionResponse)(nil),   // 16: kevo.CommitTransactionResponse
-	(*RollbackTransactionRequest)(nil),  // 17: kevo.RollbackTransactionRequest
-	(*RollbackTransactionResponse)(nil), // 18: kevo.RollbackTransactionResponse
-	(*TxGetRequest)(nil),                // 19: kevo.TxGetRequest
-	(*TxGetResponse)(nil),               // 20: kevo.TxGetResponse
-	(*TxPutRequest)(nil),                // 21: kevo.TxPutRequest
-	(*TxPutResponse)(nil),               // 22: kevo.TxPutResponse
-	(*TxDeleteRequest)(nil),             // 23: kevo.TxDeleteRequest
-	(*TxDeleteResponse)(nil),            // 24: kevo.TxDeleteResponse
-	(*TxScanRequest)(nil),               // 25: kevo.TxScanRequest
-	(*TxScanResponse)(nil),              // 26: kevo.TxScanResponse
-	(*GetStatsRequest)(nil),             // 27: kevo.GetStatsRequest
-	(*GetStatsResponse)(nil),            // 28: kevo.GetStatsResponse
-	(*LatencyStats)(nil),                // 29: kevo.LatencyStats
-	(*RecoveryStats)(nil),               // 30: kevo.RecoveryStats
-	(*CompactRequest)(nil),              // 31: kevo.CompactRequest
-	(*CompactResponse)(nil),             // 32: kevo.CompactResponse
-	(*GetNodeInfoRequest)(nil),          // 33: kevo.GetNodeInfoRequest
-	(*GetNodeInfoResponse)(nil),         // 34: kevo.GetNodeInfoResponse
-	(*ReplicaInfo)(nil),                 // 35: kevo.ReplicaInfo
-	nil,                                 // 36: kevo.GetStatsResponse.OperationCountsEntry
-	nil,                                 // 37: kevo.GetStatsResponse.LatencyStatsEntry
-	nil,                                 // 38: kevo.GetStatsResponse.ErrorCountsEntry
-	nil,                                 // 39: kevo.ReplicaInfo.MetaEntry
+	(Operation_Type)(0),                         // 0: kevo.Operation.Type
+	(Precondition_Type)(0),                      // 1: kevo.Precondition.Type
+	(BeginTransactionRequest_IsolationLevel)(0), // 2: kevo.BeginTransactionRequest.IsolationLevel
+	(GetNodeInfoResponse_NodeRole)(0),           // 3: kevo.GetNodeInfoResponse.NodeRole
+	(*GetRequest)(nil),                          // 4: kevo.GetRequest
+	(*GetResponse)(nil),                         // 5: kevo.GetResponse
+	(*PutRequest)(nil),                          // 6: kevo.PutRequest
+	(*PutResponse)(nil),                         // 7: kevo.PutResponse
+	(*DeleteRequest)(nil),                       // 8: kevo.DeleteRequest
+	(*DeleteResponse)(nil),                      // 9: kevo.DeleteResponse
+	(*DeleteRangeRequest)(nil),                  // 10: kevo.DeleteRangeRequest
+	(*DeleteRangeResponse)(nil),                 // 11: kevo.DeleteRangeResponse
+	(*CompareAndSwapRequest)(nil),               // 12: kevo.CompareAndSwapRequest
+	(*PutIfAbsentRequest)(nil),                  // 13: kevo.PutIfAbsentRequest
+	(*DeleteIfEqualsRequest)(nil),               // 14: kevo.DeleteIfEqualsRequest
+	(*ConditionalWriteResponse)(nil),            // 15: kevo.ConditionalWriteResponse
+	(*BatchWriteRequest)(nil),                   // 16: kevo.BatchWriteRequest
+	(*Operation)(nil),                           // 17: kevo.Operation
+	(*Precondition)(nil),                        // 18: kevo.Precondition
+	(*BatchWriteResponse)(nil),                  // 19: kevo.BatchWriteResponse
+	(*ScanRequest)(nil),                         // 20: kevo.ScanRequest
+	(*ScanResponse)(nil),                        // 21: kevo.ScanResponse
+	(*BeginTransactionRequest)(nil),             // 22: kevo.BeginTransactionRequest
+	(*BeginTransactionResponse)(nil),            // 23: kevo.BeginTransactionResponse
+	(*CommitTransactionRequest)(nil),            // 24: kevo.CommitTransactionRequest
+	(*CommitTransactionResponse)(nil),           // 25: kevo.CommitTransactionResponse
+	(*RollbackTransactionRequest)(nil),          // 26: kevo.RollbackTransactionRequest
+	(*RollbackTransactionResponse)(nil),         // 27: kevo.RollbackTransactionResponse
+	(*TxGetRequest)(nil),                        // 28: kevo.TxGetRequest
+	(*TxGetResponse)(nil),                       // 29: kevo.TxGetResponse
+	(*TxPutRequest)(nil),                        // 30: kevo.TxPutRequest
+	(*TxPutResponse)(nil),                       // 31: kevo.TxPutResponse
+	(*TxDeleteRequest)(nil),                     // 32: kevo.TxDeleteRequest
+	(*TxDeleteResponse)(nil),                    // 33: kevo.TxDeleteResponse
+	(*TxScanRequest)(nil),                       // 34: kevo.TxScanRequest
+	(*TxScanResponse)(nil),                      // 35: kevo.TxScanResponse
+	(*GetStatsRequest)(nil),                     // 36: kevo.GetStatsRequest
+	(*GetStatsResponse)(nil),                    // 37: kevo.GetStatsResponse
+	(*LatencyStats)(nil),                        // 38: kevo.LatencyStats
+	(*RecoveryStats)(nil),                       // 39: kevo.RecoveryStats
+	(*CompactRequest)(nil),                      // 40: kevo.CompactRequest
+	(*CompactResponse)(nil),                     // 41: kevo.CompactResponse
+	(*CheckpointRequest)(nil),                   // 42: kevo.CheckpointRequest
+	(*CheckpointResponse)(nil),                  // 43: kevo.CheckpointResponse
+	(*IngestSSTablesRequest)(nil),               // 44: kevo.IngestSSTablesRequest
+	(*IngestSSTablesResponse)(nil),              // 45: kevo.IngestSSTablesResponse
+	(*GetNodeInfoRequest)(nil),                  // 46: kevo.GetNodeInfoRequest
+	(*GetNodeInfoResponse)(nil),                 // 47: kevo.GetNodeInfoResponse
+	(*ReplicaInfo)(nil),                         // 48: kevo.ReplicaInfo
+	nil,                                         // 49: kevo.GetStatsResponse.OperationCountsEntry
+	nil,                                         // 50: kevo.GetStatsResponse.LatencyStatsEntry
+	nil,                                         // 51: kevo.GetStatsResponse.ErrorCountsEntry
+	nil,                                         // 52: kevo.ReplicaInfo.MetaEntry
 }
 var file_proto_kevo_service_proto_depIdxs = []int32{
-	9,  // 0: kevo.BatchWriteRequest.operations:type_name -> kevo.Operation
+	17, // 0: kevo.BatchWriteRequest.operations:type_name -> kevo.Operation
 	0,  // 1: kevo.Operation.type:type_name -> kevo.Operation.Type
-	36, // 2: kevo.GetStatsResponse.operation_counts:type_name -> kevo.GetStatsResponse.OperationCountsEntry
-	37, // 3: kevo.GetStatsResponse.latency_stats:type_name -> kevo.GetStatsResponse.LatencyStatsEntry
-	38, // 4: kevo.GetStatsResponse.error_counts:type_name -> kevo.GetStatsResponse.ErrorCountsEntry
-	30, // 5: kevo.GetStatsResponse.recovery_stats:type_name -> kevo.RecoveryStats
-	1,  // 6: kevo.GetNodeInfoResponse.node_role:type_name -> kevo.GetNodeInfoResponse.NodeRole
-	35, // 7: kevo.GetNodeInfoResponse.replicas:type_name -> kevo.ReplicaInfo
-	39, // 8: kevo.ReplicaInfo.meta:type_name -> kevo.ReplicaInfo.MetaEntry
-	29, // 9: kevo.GetStatsResponse.LatencyStatsEntry.value:type_name -> kevo.LatencyStats
-	2,  // 10: kevo.KevoService.Get:input_type -> kevo.GetRequest
-	4,  // 11: kevo.KevoService.Put:input_type -> kevo.PutRequest
-	6,  // 12: kevo.KevoService.Delete:input_type -> kevo.DeleteRequest
-	8,  // 13: kevo.KevoService.BatchWrite:input_type -> kevo.BatchWriteRequest
-	11, // 14: kevo.KevoService.Scan:input_type -> kevo.ScanRequest
-	13, // 15: kevo.KevoService.BeginTransaction:input_type -> kevo.BeginTransactionRequest
-	15, // 16: kevo.KevoService.CommitTransaction:input_type -> kevo.CommitTransactionRequest
-	17, // 17: kevo.KevoService.RollbackTransaction:input_type -> kevo.RollbackTransactionRequest
-	19, // 18: kevo.KevoService.TxGet:input_type -> kevo.TxGetRequest
-	21, // 19: kevo.KevoService.TxPut:input_type -> kevo.TxPutRequest
-	23, // 20: kevo.KevoService.TxDelete:input_type -> kevo.TxDeleteRequest
-	25, // 21: kevo.KevoService.TxScan:input_type -> kevo.TxScanRequest
-	27, // 22: kevo.KevoService.GetStats:input_type -> kevo.GetStatsRequest
-	31, // 23: kevo.KevoService.Compact:input_type -> kevo.CompactRequest
-	33, // 24: kevo.KevoService.GetNodeInfo:input_type -> kevo.GetNodeInfoRequest
-	3,  // 25: kevo.KevoService.Get:output_type -> kevo.GetResponse
-	5,  // 26: kevo.KevoService.Put:output_type -> kevo.PutResponse
-	7,  // 27: kevo.KevoService.Delete:output_type -> kevo.DeleteResponse
-	10, // 28: kevo.KevoService.BatchWrite:output_type -> kevo.BatchWriteResponse
-	12, // 29: kevo.KevoService.Scan:output_type -> kevo.ScanResponse
-	14, // 30: kevo.KevoService.BeginTransaction:output_type -> kevo.BeginTransactionResponse
-	16, // 31: kevo.KevoService.CommitTransaction:output_type -> kevo.CommitTransactionResponse
-	18, // 32: kevo.KevoService.RollbackTransaction:output_type -> kevo.RollbackTransactionResponse
-	20, // 33: kevo.KevoService.TxGet:output_type -> kevo.TxGetResponse
-	22, // 34: kevo.KevoService.TxPut:output_type -> kevo.TxPutResponse
-	24, // 35: kevo.KevoService.TxDelete:output_type -> kevo.TxDeleteResponse
-	26, // 36: kevo.KevoService.TxScan:output_type -> kevo.TxScanResponse
-	28, // 37: kevo.KevoService.GetStats:output_type -> kevo.GetStatsResponse
-	32, // 38: kevo.KevoService.Compact:output_type -> kevo.CompactResponse
-	34, // 39: kevo.KevoService.GetNodeInfo:output_type -> kevo.GetNodeInfoResponse
-	25, // [25:40] is the sub-list for method output_type
-	10, // [10:25] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	18, // 2: kevo.Operation.precondition:type_name -> kevo.Precondition
+	1,  // 3: kevo.Precondition.type:type_name -> kevo.Precondition.Type
+	2,  // 4: kevo.BeginTransactionRequest.isolation:type_name -> kevo.BeginTransactionRequest.IsolationLevel
+	49, // 5: kevo.GetStatsResponse.operation_counts:type_name -> kevo.GetStatsResponse.OperationCountsEntry
+	50, // 6: kevo.GetStatsResponse.latency_stats:type_name -> kevo.GetStatsResponse.LatencyStatsEntry
+	51, // 7: kevo.GetStatsResponse.error_counts:type_name -> kevo.GetStatsResponse.ErrorCountsEntry
+	39, // 8: kevo.GetStatsResponse.recovery_stats:type_name -> kevo.RecoveryStats
+	3,  // 9: kevo.GetNodeInfoResponse.node_role:type_name -> kevo.GetNodeInfoResponse.NodeRole
+	48, // 10: kevo.GetNodeInfoResponse.replicas:type_name -> kevo.ReplicaInfo
+	52, // 11: kevo.ReplicaInfo.meta:type_name -> kevo.ReplicaInfo.MetaEntry
+	38, // 12: kevo.GetStatsResponse.LatencyStatsEntry.value:type_name -> kevo.LatencyStats
+	4,  // 13: kevo.KevoService.Get:input_type -> kevo.GetRequest
+	6,  // 14: kevo.KevoService.Put:input_type -> kevo.PutRequest
+	8,  // 15: kevo.KevoService.Delete:input_type -> kevo.DeleteRequest
+	10, // 16: kevo.KevoService.DeleteRange:input_type -> kevo.DeleteRangeRequest
+	12, // 17: kevo.KevoService.CompareAndSwap:input_type -> kevo.CompareAndSwapRequest
+	13, // 18: kevo.KevoService.PutIfAbsent:input_type -> kevo.PutIfAbsentRequest
+	14, // 19: kevo.KevoService.DeleteIfEquals:input_type -> kevo.DeleteIfEqualsRequest
+	16, // 20: kevo.KevoService.BatchWrite:input_type -> kevo.BatchWriteRequest
+	20, // 21: kevo.KevoService.Scan:input_type -> kevo.ScanRequest
+	22, // 22: kevo.KevoService.BeginTransaction:input_type -> kevo.BeginTransactionRequest
+	24, // 23: kevo.KevoService.CommitTransaction:input_type -> kevo.CommitTransactionRequest
+	26, // 24: kevo.KevoService.RollbackTransaction:input_type -> kevo.RollbackTransactionRequest
+	28, // 25: kevo.KevoService.TxGet:input_type -> kevo.TxGetRequest
+	30, // 26: kevo.KevoService.TxPut:input_type -> kevo.TxPutRequest
+	32, // 27: kevo.KevoService.TxDelete:input_type -> kevo.TxDeleteRequest
+	34, // 28: kevo.KevoService.TxScan:input_type -> kevo.TxScanRequest
+	36, // 29: kevo.KevoService.GetStats:input_type -> kevo.GetStatsRequest
+	40, // 30: kevo.KevoService.Compact:input_type -> kevo.CompactRequest
+	42, // 31: kevo.KevoService.Checkpoint:input_type -> kevo.CheckpointRequest
+	44, // 32: kevo.KevoService.IngestSSTables:input_type -> kevo.IngestSSTablesRequest
+	46, // 33: kevo.KevoService.GetNodeInfo:input_type -> kevo.GetNodeInfoRequest
+	5,  // 34: kevo.KevoService.Get:output_type -> kevo.GetResponse
+	7,  // 35: kevo.KevoService.Put:output_type -> kevo.PutResponse
+	9,  // 36: kevo.KevoService.Delete:output_type -> kevo.DeleteResponse
+	11, // 37: kevo.KevoService.DeleteRange:output_type -> kevo.DeleteRangeResponse
+	15, // 38: kevo.KevoService.CompareAndSwap:output_type -> kevo.ConditionalWriteResponse
+	15, // 39: kevo.KevoService.PutIfAbsent:output_type -> kevo.ConditionalWriteResponse
+	15, // 40: kevo.KevoService.DeleteIfEquals:output_type -> kevo.ConditionalWriteResponse
+	19, // 41: kevo.KevoService.BatchWrite:output_type -> kevo.BatchWriteResponse
+	21, // 42: kevo.KevoService.Scan:output_type -> kevo.ScanResponse
+	23, // 43: kevo.KevoService.BeginTransaction:output_type -> kevo.BeginTransactionResponse
+	25, // 44: kevo.KevoService.CommitTransaction:output_type -> kevo.CommitTransactionResponse
+	27, // 45: kevo.KevoService.RollbackTransaction:output_type -> kevo.RollbackTransactionResponse
+	29, // 46: kevo.KevoService.TxGet:output_type -> kevo.TxGetResponse
+	31, // 47: kevo.KevoService.TxPut:output_type -> kevo.TxPutResponse
+	33, // 48: kevo.KevoService.TxDelete:output_type -> kevo.TxDeleteResponse
+	35, // 49: kevo.KevoService.TxScan:output_type -> kevo.TxScanResponse
+	37, // 50: kevo.KevoService.GetStats:output_type -> kevo.GetStatsResponse
+	41, // 51: kevo.KevoService.Compact:output_type -> kevo.CompactResponse
+	43, // 52: kevo.KevoService.Checkpoint:output_type -> kevo.CheckpointResponse
+	45, // 53: kevo.KevoService.IngestSSTables:output_type -> kevo.IngestSSTablesResponse
+	47, // 54: kevo.KevoService.GetNodeInfo:output_type -> kevo.GetNodeInfoResponse
+	34, // [34:55] is the sub-list for method output_type
+	13, // [13:34] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_proto_kevo_service_proto_init() }
@@ -2300,8 +3222,8 @@ func file_proto_kevo_service_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_kevo_service_proto_rawDesc), len(file_proto_kevo_service_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   38,
+			NumEnums:      4,
+			NumMessages:   49,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
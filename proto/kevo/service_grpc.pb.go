@@ -22,6 +22,10 @@ const (
 	KevoService_Get_FullMethodName                 = "/kevo.KevoService/Get"
 	KevoService_Put_FullMethodName                 = "/kevo.KevoService/Put"
 	KevoService_Delete_FullMethodName              = "/kevo.KevoService/Delete"
+	KevoService_DeleteRange_FullMethodName         = "/kevo.KevoService/DeleteRange"
+	KevoService_CompareAndSwap_FullMethodName      = "/kevo.KevoService/CompareAndSwap"
+	KevoService_PutIfAbsent_FullMethodName         = "/kevo.KevoService/PutIfAbsent"
+	KevoService_DeleteIfEquals_FullMethodName      = "/kevo.KevoService/DeleteIfEquals"
 	KevoService_BatchWrite_FullMethodName          = "/kevo.KevoService/BatchWrite"
 	KevoService_Scan_FullMethodName                = "/kevo.KevoService/Scan"
 	KevoService_BeginTransaction_FullMethodName    = "/kevo.KevoService/BeginTransaction"
@@ -33,6 +37,8 @@ const (
 	KevoService_TxScan_FullMethodName              = "/kevo.KevoService/TxScan"
 	KevoService_GetStats_FullMethodName            = "/kevo.KevoService/GetStats"
 	KevoService_Compact_FullMethodName             = "/kevo.KevoService/Compact"
+	KevoService_Checkpoint_FullMethodName          = "/kevo.KevoService/Checkpoint"
+	KevoService_IngestSSTables_FullMethodName      = "/kevo.KevoService/IngestSSTables"
 	KevoService_GetNodeInfo_FullMethodName         = "/kevo.KevoService/GetNodeInfo"
 )
 
@@ -44,6 +50,11 @@ type KevoServiceClient interface {
 	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
 	Put(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*PutResponse, error)
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	DeleteRange(ctx context.Context, in *DeleteRangeRequest, opts ...grpc.CallOption) (*DeleteRangeResponse, error)
+	// Conditional Operations
+	CompareAndSwap(ctx context.Context, in *CompareAndSwapRequest, opts ...grpc.CallOption) (*ConditionalWriteResponse, error)
+	PutIfAbsent(ctx context.Context, in *PutIfAbsentRequest, opts ...grpc.CallOption) (*ConditionalWriteResponse, error)
+	DeleteIfEquals(ctx context.Context, in *DeleteIfEqualsRequest, opts ...grpc.CallOption) (*ConditionalWriteResponse, error)
 	// Batch Operations
 	BatchWrite(ctx context.Context, in *BatchWriteRequest, opts ...grpc.CallOption) (*BatchWriteResponse, error)
 	// Iterator Operations
@@ -60,6 +71,8 @@ type KevoServiceClient interface {
 	// Administrative Operations
 	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
 	Compact(ctx context.Context, in *CompactRequest, opts ...grpc.CallOption) (*CompactResponse, error)
+	Checkpoint(ctx context.Context, in *CheckpointRequest, opts ...grpc.CallOption) (*CheckpointResponse, error)
+	IngestSSTables(ctx context.Context, in *IngestSSTablesRequest, opts ...grpc.CallOption) (*IngestSSTablesResponse, error)
 	// Replication and Topology Operations
 	GetNodeInfo(ctx context.Context, in *GetNodeInfoRequest, opts ...grpc.CallOption) (*GetNodeInfoResponse, error)
 }
@@ -102,6 +115,46 @@ func (c *kevoServiceClient) Delete(ctx context.Context, in *DeleteRequest, opts
 	return out, nil
 }
 
+func (c *kevoServiceClient) DeleteRange(ctx context.Context, in *DeleteRangeRequest, opts ...grpc.CallOption) (*DeleteRangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteRangeResponse)
+	err := c.cc.Invoke(ctx, KevoService_DeleteRange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kevoServiceClient) CompareAndSwap(ctx context.Context, in *CompareAndSwapRequest, opts ...grpc.CallOption) (*ConditionalWriteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConditionalWriteResponse)
+	err := c.cc.Invoke(ctx, KevoService_CompareAndSwap_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kevoServiceClient) PutIfAbsent(ctx context.Context, in *PutIfAbsentRequest, opts ...grpc.CallOption) (*ConditionalWriteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConditionalWriteResponse)
+	err := c.cc.Invoke(ctx, KevoService_PutIfAbsent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kevoServiceClient) DeleteIfEquals(ctx context.Context, in *DeleteIfEqualsRequest, opts ...grpc.CallOption) (*ConditionalWriteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConditionalWriteResponse)
+	err := c.cc.Invoke(ctx, KevoService_DeleteIfEquals_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *kevoServiceClient) BatchWrite(ctx context.Context, in *BatchWriteRequest, opts ...grpc.CallOption) (*BatchWriteResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(BatchWriteResponse)
@@ -230,6 +283,26 @@ func (c *kevoServiceClient) Compact(ctx context.Context, in *CompactRequest, opt
 	return out, nil
 }
 
+func (c *kevoServiceClient) Checkpoint(ctx context.Context, in *CheckpointRequest, opts ...grpc.CallOption) (*CheckpointResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckpointResponse)
+	err := c.cc.Invoke(ctx, KevoService_Checkpoint_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kevoServiceClient) IngestSSTables(ctx context.Context, in *IngestSSTablesRequest, opts ...grpc.CallOption) (*IngestSSTablesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IngestSSTablesResponse)
+	err := c.cc.Invoke(ctx, KevoService_IngestSSTables_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *kevoServiceClient) GetNodeInfo(ctx context.Context, in *GetNodeInfoRequest, opts ...grpc.CallOption) (*GetNodeInfoResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetNodeInfoResponse)
@@ -248,6 +321,11 @@ type KevoServiceServer interface {
 	Get(context.Context, *GetRequest) (*GetResponse, error)
 	Put(context.Context, *PutRequest) (*PutResponse, error)
 	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	DeleteRange(context.Context, *DeleteRangeRequest) (*DeleteRangeResponse, error)
+	// Conditional Operations
+	CompareAndSwap(context.Context, *CompareAndSwapRequest) (*ConditionalWriteResponse, error)
+	PutIfAbsent(context.Context, *PutIfAbsentRequest) (*ConditionalWriteResponse, error)
+	DeleteIfEquals(context.Context, *DeleteIfEqualsRequest) (*ConditionalWriteResponse, error)
 	// Batch Operations
 	BatchWrite(context.Context, *BatchWriteRequest) (*BatchWriteResponse, error)
 	// Iterator Operations
@@ -264,6 +342,8 @@ type KevoServiceServer interface {
 	// Administrative Operations
 	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
 	Compact(context.Context, *CompactRequest) (*CompactResponse, error)
+	Checkpoint(context.Context, *CheckpointRequest) (*CheckpointResponse, error)
+	IngestSSTables(context.Context, *IngestSSTablesRequest) (*IngestSSTablesResponse, error)
 	// Replication and Topology Operations
 	GetNodeInfo(context.Context, *GetNodeInfoRequest) (*GetNodeInfoResponse, error)
 	mustEmbedUnimplementedKevoServiceServer()
@@ -285,6 +365,18 @@ func (UnimplementedKevoServiceServer) Put(context.Context, *PutRequest) (*PutRes
 func (UnimplementedKevoServiceServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
+func (UnimplementedKevoServiceServer) DeleteRange(context.Context, *DeleteRangeRequest) (*DeleteRangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRange not implemented")
+}
+func (UnimplementedKevoServiceServer) CompareAndSwap(context.Context, *CompareAndSwapRequest) (*ConditionalWriteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompareAndSwap not implemented")
+}
+func (UnimplementedKevoServiceServer) PutIfAbsent(context.Context, *PutIfAbsentRequest) (*ConditionalWriteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutIfAbsent not implemented")
+}
+func (UnimplementedKevoServiceServer) DeleteIfEquals(context.Context, *DeleteIfEqualsRequest) (*ConditionalWriteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteIfEquals not implemented")
+}
 func (UnimplementedKevoServiceServer) BatchWrite(context.Context, *BatchWriteRequest) (*BatchWriteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BatchWrite not implemented")
 }
@@ -318,6 +410,12 @@ func (UnimplementedKevoServiceServer) GetStats(context.Context, *GetStatsRequest
 func (UnimplementedKevoServiceServer) Compact(context.Context, *CompactRequest) (*CompactResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Compact not implemented")
 }
+func (UnimplementedKevoServiceServer) Checkpoint(context.Context, *CheckpointRequest) (*CheckpointResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Checkpoint not implemented")
+}
+func (UnimplementedKevoServiceServer) IngestSSTables(context.Context, *IngestSSTablesRequest) (*IngestSSTablesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IngestSSTables not implemented")
+}
 func (UnimplementedKevoServiceServer) GetNodeInfo(context.Context, *GetNodeInfoRequest) (*GetNodeInfoResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetNodeInfo not implemented")
 }
@@ -396,6 +494,78 @@ func _KevoService_Delete_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _KevoService_DeleteRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KevoServiceServer).DeleteRange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KevoService_DeleteRange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KevoServiceServer).DeleteRange(ctx, req.(*DeleteRangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KevoService_CompareAndSwap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompareAndSwapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KevoServiceServer).CompareAndSwap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KevoService_CompareAndSwap_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KevoServiceServer).CompareAndSwap(ctx, req.(*CompareAndSwapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KevoService_PutIfAbsent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutIfAbsentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KevoServiceServer).PutIfAbsent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KevoService_PutIfAbsent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KevoServiceServer).PutIfAbsent(ctx, req.(*PutIfAbsentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KevoService_DeleteIfEquals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteIfEqualsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KevoServiceServer).DeleteIfEquals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KevoService_DeleteIfEquals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KevoServiceServer).DeleteIfEquals(ctx, req.(*DeleteIfEqualsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _KevoService_BatchWrite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(BatchWriteRequest)
 	if err := dec(in); err != nil {
@@ -580,6 +750,42 @@ func _KevoService_Compact_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _KevoService_Checkpoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckpointRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KevoServiceServer).Checkpoint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KevoService_Checkpoint_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KevoServiceServer).Checkpoint(ctx, req.(*CheckpointRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KevoService_IngestSSTables_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IngestSSTablesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KevoServiceServer).IngestSSTables(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KevoService_IngestSSTables_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KevoServiceServer).IngestSSTables(ctx, req.(*IngestSSTablesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _KevoService_GetNodeInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetNodeInfoRequest)
 	if err := dec(in); err != nil {
@@ -617,6 +823,22 @@ var KevoService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _KevoService_Delete_Handler,
 		},
+		{
+			MethodName: "DeleteRange",
+			Handler:    _KevoService_DeleteRange_Handler,
+		},
+		{
+			MethodName: "CompareAndSwap",
+			Handler:    _KevoService_CompareAndSwap_Handler,
+		},
+		{
+			MethodName: "PutIfAbsent",
+			Handler:    _KevoService_PutIfAbsent_Handler,
+		},
+		{
+			MethodName: "DeleteIfEquals",
+			Handler:    _KevoService_DeleteIfEquals_Handler,
+		},
 		{
 			MethodName: "BatchWrite",
 			Handler:    _KevoService_BatchWrite_Handler,
@@ -653,6 +875,14 @@ var KevoService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Compact",
 			Handler:    _KevoService_Compact_Handler,
 		},
+		{
+			MethodName: "Checkpoint",
+			Handler:    _KevoService_Checkpoint_Handler,
+		},
+		{
+			MethodName: "IngestSSTables",
+			Handler:    _KevoService_IngestSSTables_Handler,
+		},
 		{
 			MethodName: "GetNodeInfo",
 			Handler:    _KevoService_GetNodeInfo_Handler,